@@ -183,6 +183,7 @@ type InstanceServer interface {
 	ConsoleInstanceDynamic(instanceName string, console api.InstanceConsolePost, args *InstanceConsoleArgs) (Operation, func(io.ReadWriteCloser) error, error)
 
 	GetInstanceConsoleLog(instanceName string, args *InstanceConsoleLogArgs) (content io.ReadCloser, err error)
+	GetInstanceConsoleScreenshot(instanceName string) (content io.ReadCloser, err error)
 	DeleteInstanceConsoleLog(instanceName string, args *InstanceConsoleLogArgs) (err error)
 
 	GetInstanceFile(instanceName string, path string) (content io.ReadCloser, resp *InstanceFileResponse, err error)
@@ -249,6 +250,7 @@ type InstanceServer interface {
 	GetNetwork(name string) (network *api.Network, ETag string, err error)
 	GetNetworkLeases(name string) (leases []api.NetworkLease, err error)
 	GetNetworkState(name string) (state *api.NetworkState, err error)
+	GetNetworkBGPState(name string) (state *api.NetworkBGPState, err error)
 	CreateNetwork(network api.NetworksPost) (err error)
 	UpdateNetwork(name string, network api.NetworkPut, ETag string) (err error)
 	RenameNetwork(name string, network api.NetworkPost) (err error)
@@ -262,6 +264,18 @@ type InstanceServer interface {
 	UpdateNetworkForward(networkName string, listenAddress string, forward api.NetworkForwardPut, ETag string) (err error)
 	DeleteNetworkForward(networkName string, listenAddress string) (err error)
 
+	// Network IPAM functions ("network_ipam" API extension)
+	GetNetworkIPRangeNames(networkName string) ([]string, error)
+	GetNetworkIPRanges(networkName string) ([]api.NetworkIPRange, error)
+	GetNetworkIPRange(networkName string, rangeName string) (ipRange *api.NetworkIPRange, ETag string, err error)
+	CreateNetworkIPRange(networkName string, ipRange api.NetworkIPRangesPost) error
+	UpdateNetworkIPRange(networkName string, rangeName string, ipRange api.NetworkIPRangePut, ETag string) (err error)
+	DeleteNetworkIPRange(networkName string, rangeName string) (err error)
+	GetNetworkIPAllocations(networkName string) ([]api.NetworkIPAllocation, error)
+	GetNetworkIPAllocation(networkName string, address string) (allocation *api.NetworkIPAllocation, err error)
+	CreateNetworkIPAllocation(networkName string, allocation api.NetworkIPAllocationsPost) (allocated *api.NetworkIPAllocation, err error)
+	DeleteNetworkIPAllocation(networkName string, address string) (err error)
+
 	// Network load balancer functions ("network_load_balancer" API extension)
 	GetNetworkLoadBalancerAddresses(networkName string) ([]string, error)
 	GetNetworkLoadBalancers(networkName string) ([]api.NetworkLoadBalancer, error)
@@ -283,11 +297,21 @@ type InstanceServer interface {
 	GetNetworkACLs() (acls []api.NetworkACL, err error)
 	GetNetworkACL(name string) (acl *api.NetworkACL, ETag string, err error)
 	GetNetworkACLLogfile(name string) (log io.ReadCloser, err error)
+	GetNetworkACLState(name string) (state *api.NetworkACLState, err error)
 	CreateNetworkACL(acl api.NetworkACLsPost) (err error)
 	UpdateNetworkACL(name string, acl api.NetworkACLPut, ETag string) (err error)
 	RenameNetworkACL(name string, acl api.NetworkACLPost) (err error)
 	DeleteNetworkACL(name string) (err error)
 
+	// Network address group functions ("network_address_groups" API extension)
+	GetNetworkAddressGroupNames() (names []string, err error)
+	GetNetworkAddressGroups() (groups []api.NetworkAddressGroup, err error)
+	GetNetworkAddressGroup(name string) (group *api.NetworkAddressGroup, ETag string, err error)
+	CreateNetworkAddressGroup(group api.NetworkAddressGroupsPost) (err error)
+	UpdateNetworkAddressGroup(name string, group api.NetworkAddressGroupPut, ETag string) (err error)
+	RenameNetworkAddressGroup(name string, group api.NetworkAddressGroupPost) (err error)
+	DeleteNetworkAddressGroup(name string) (err error)
+
 	// Network allocations functions ("network_allocations" API extension)
 	GetNetworkAllocations(allProjects bool) (allocations []api.NetworkAllocations, err error)
 
@@ -371,6 +395,7 @@ type InstanceServer interface {
 	GetStoragePoolVolumesWithFilterAllProjects(pool string, filters []string) (volumes []api.StorageVolume, err error)
 	GetStoragePoolVolume(pool string, volType string, name string) (volume *api.StorageVolume, ETag string, err error)
 	GetStoragePoolVolumeState(pool string, volType string, name string) (state *api.StorageVolumeState, err error)
+	VerifyStoragePoolVolume(pool string, volType string, name string) (op Operation, err error)
 	CreateStoragePoolVolume(pool string, volume api.StorageVolumesPost) (err error)
 	UpdateStoragePoolVolume(pool string, volType string, name string, volume api.StorageVolumePut, ETag string) (err error)
 	DeleteStoragePoolVolume(pool string, volType string, name string) (err error)
@@ -700,6 +700,13 @@ func (r *ProtocolLXD) CreateInstance(instance api.InstancesPost) (Operation, err
 		}
 	}
 
+	if instance.Source.Type == "backup" {
+		err := r.CheckExtension("backup_s3")
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Send the request
 	op, _, err := r.queryOperation("POST", path, instance, "", true)
 	if err != nil {
@@ -2669,6 +2676,13 @@ func (r *ProtocolLXD) ConsoleInstance(instanceName string, console api.InstanceC
 		}
 	}
 
+	if console.Protocol != "" {
+		err = r.CheckExtension("instance_console_vnc")
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Send the request
 	useEventListener := r.CheckExtension("operation_wait") != nil
 	op, _, err := r.queryOperation("POST", fmt.Sprintf("%s/%s/console", path, url.PathEscape(instanceName)), console, "", useEventListener)
@@ -2769,6 +2783,13 @@ func (r *ProtocolLXD) ConsoleInstanceDynamic(instanceName string, console api.In
 		}
 	}
 
+	if console.Protocol != "" {
+		err = r.CheckExtension("instance_console_vnc")
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
 	// Send the request.
 	op, _, err := r.queryOperation("POST", fmt.Sprintf("%s/%s/console", path, url.PathEscape(instanceName)), console, "", true)
 	if err != nil {
@@ -2886,6 +2907,48 @@ func (r *ProtocolLXD) GetInstanceConsoleLog(instanceName string, args *InstanceC
 	return resp.Body, err
 }
 
+// GetInstanceConsoleScreenshot returns a PNG image of the display of the requested virtual machine.
+func (r *ProtocolLXD) GetInstanceConsoleScreenshot(instanceName string) (io.ReadCloser, error) {
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.CheckExtension("instance_console_vnc")
+	if err != nil {
+		return nil, err
+	}
+
+	// Prepare the HTTP request
+	url := fmt.Sprintf("%s/1.0%s/%s/console/screenshot", r.httpBaseURL.String(), path, url.PathEscape(instanceName))
+
+	url, err = r.setQueryAttributes(url)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send the request
+	resp, err := r.DoHTTP(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the return value for a cleaner error
+	if resp.StatusCode != http.StatusOK {
+		_, _, err := lxdParseResponse(resp)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return resp.Body, err
+}
+
 // DeleteInstanceConsoleLog deletes the requested instance's console log.
 func (r *ProtocolLXD) DeleteInstanceConsoleLog(instanceName string, args *InstanceConsoleLogArgs) error {
 	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
@@ -2988,6 +3051,20 @@ func (r *ProtocolLXD) CreateInstanceBackup(instanceName string, backup api.Insta
 		return nil, err
 	}
 
+	if backup.IncrementalFrom != "" {
+		err = r.CheckExtension("backup_incremental")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if backup.Target != nil {
+		err = r.CheckExtension("backup_s3")
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Send the request
 	op, _, err := r.queryOperation("POST", fmt.Sprintf("%s/%s/backups", path, url.PathEscape(instanceName)), backup, "", true)
 	if err != nil {
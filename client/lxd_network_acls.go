@@ -102,6 +102,24 @@ func (r *ProtocolLXD) GetNetworkACLLogfile(name string) (io.ReadCloser, error) {
 	return resp.Body, err
 }
 
+// GetNetworkACLState returns the counters of the rules of a Network ACL.
+func (r *ProtocolLXD) GetNetworkACLState(name string) (*api.NetworkACLState, error) {
+	err := r.CheckExtension("network_acl_state")
+	if err != nil {
+		return nil, err
+	}
+
+	state := api.NetworkACLState{}
+
+	// Fetch the raw value.
+	_, err = r.queryStruct("GET", fmt.Sprintf("/network-acls/%s/state", url.PathEscape(name)), nil, "", &state)
+	if err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
 // CreateNetworkACL defines a new network ACL using the provided struct.
 func (r *ProtocolLXD) CreateNetworkACL(acl api.NetworkACLsPost) error {
 	err := r.CheckExtension("network_acl")
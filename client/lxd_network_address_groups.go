@@ -0,0 +1,127 @@
+package lxd
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// GetNetworkAddressGroupNames returns a list of network address group names.
+func (r *ProtocolLXD) GetNetworkAddressGroupNames() ([]string, error) {
+	err := r.CheckExtension("network_address_groups")
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the raw URL values.
+	urls := []string{}
+	baseURL := "/network-address-groups"
+	_, err = r.queryStruct("GET", baseURL, nil, "", &urls)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse it.
+	return urlsToResourceNames(baseURL, urls...)
+}
+
+// GetNetworkAddressGroups returns a list of network address group structs.
+func (r *ProtocolLXD) GetNetworkAddressGroups() ([]api.NetworkAddressGroup, error) {
+	err := r.CheckExtension("network_address_groups")
+	if err != nil {
+		return nil, err
+	}
+
+	groups := []api.NetworkAddressGroup{}
+
+	// Fetch the raw value.
+	_, err = r.queryStruct("GET", "/network-address-groups?recursion=1", nil, "", &groups)
+	if err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// GetNetworkAddressGroup returns a network address group entry for the provided name.
+func (r *ProtocolLXD) GetNetworkAddressGroup(name string) (*api.NetworkAddressGroup, string, error) {
+	err := r.CheckExtension("network_address_groups")
+	if err != nil {
+		return nil, "", err
+	}
+
+	group := api.NetworkAddressGroup{}
+
+	// Fetch the raw value.
+	etag, err := r.queryStruct("GET", fmt.Sprintf("/network-address-groups/%s", url.PathEscape(name)), nil, "", &group)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &group, etag, nil
+}
+
+// CreateNetworkAddressGroup defines a new network address group using the provided struct.
+func (r *ProtocolLXD) CreateNetworkAddressGroup(group api.NetworkAddressGroupsPost) error {
+	err := r.CheckExtension("network_address_groups")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("POST", "/network-address-groups", group, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateNetworkAddressGroup updates the network address group to match the provided struct.
+func (r *ProtocolLXD) UpdateNetworkAddressGroup(name string, group api.NetworkAddressGroupPut, ETag string) error {
+	err := r.CheckExtension("network_address_groups")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("PUT", fmt.Sprintf("/network-address-groups/%s", url.PathEscape(name)), group, ETag)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RenameNetworkAddressGroup renames an existing network address group entry.
+func (r *ProtocolLXD) RenameNetworkAddressGroup(name string, group api.NetworkAddressGroupPost) error {
+	err := r.CheckExtension("network_address_groups")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("POST", fmt.Sprintf("/network-address-groups/%s", url.PathEscape(name)), group, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteNetworkAddressGroup deletes an existing network address group.
+func (r *ProtocolLXD) DeleteNetworkAddressGroup(name string) error {
+	err := r.CheckExtension("network_address_groups")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("DELETE", fmt.Sprintf("/network-address-groups/%s", url.PathEscape(name)), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
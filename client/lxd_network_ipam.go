@@ -0,0 +1,181 @@
+package lxd
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// GetNetworkIPRangeNames returns a list of network IP range names.
+func (r *ProtocolLXD) GetNetworkIPRangeNames(networkName string) ([]string, error) {
+	err := r.CheckExtension("network_ipam")
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the raw URL values.
+	urls := []string{}
+	baseURL := fmt.Sprintf("/networks/%s/ip-ranges", url.PathEscape(networkName))
+	_, err = r.queryStruct("GET", baseURL, nil, "", &urls)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse it.
+	return urlsToResourceNames(baseURL, urls...)
+}
+
+// GetNetworkIPRanges returns a list of network IP range structs.
+func (r *ProtocolLXD) GetNetworkIPRanges(networkName string) ([]api.NetworkIPRange, error) {
+	err := r.CheckExtension("network_ipam")
+	if err != nil {
+		return nil, err
+	}
+
+	ipRanges := []api.NetworkIPRange{}
+
+	// Fetch the raw value.
+	_, err = r.queryStruct("GET", fmt.Sprintf("/networks/%s/ip-ranges?recursion=1", url.PathEscape(networkName)), nil, "", &ipRanges)
+	if err != nil {
+		return nil, err
+	}
+
+	return ipRanges, nil
+}
+
+// GetNetworkIPRange returns a network IP range entry for the provided network and range name.
+func (r *ProtocolLXD) GetNetworkIPRange(networkName string, rangeName string) (*api.NetworkIPRange, string, error) {
+	err := r.CheckExtension("network_ipam")
+	if err != nil {
+		return nil, "", err
+	}
+
+	ipRange := api.NetworkIPRange{}
+
+	// Fetch the raw value.
+	etag, err := r.queryStruct("GET", fmt.Sprintf("/networks/%s/ip-ranges/%s", url.PathEscape(networkName), url.PathEscape(rangeName)), nil, "", &ipRange)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &ipRange, etag, nil
+}
+
+// CreateNetworkIPRange defines a new network IP range using the provided struct.
+func (r *ProtocolLXD) CreateNetworkIPRange(networkName string, ipRange api.NetworkIPRangesPost) error {
+	err := r.CheckExtension("network_ipam")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("POST", fmt.Sprintf("/networks/%s/ip-ranges", url.PathEscape(networkName)), ipRange, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateNetworkIPRange updates the network IP range to match the provided struct.
+func (r *ProtocolLXD) UpdateNetworkIPRange(networkName string, rangeName string, ipRange api.NetworkIPRangePut, ETag string) error {
+	err := r.CheckExtension("network_ipam")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("PUT", fmt.Sprintf("/networks/%s/ip-ranges/%s", url.PathEscape(networkName), url.PathEscape(rangeName)), ipRange, ETag)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteNetworkIPRange deletes an existing network IP range.
+func (r *ProtocolLXD) DeleteNetworkIPRange(networkName string, rangeName string) error {
+	err := r.CheckExtension("network_ipam")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("DELETE", fmt.Sprintf("/networks/%s/ip-ranges/%s", url.PathEscape(networkName), url.PathEscape(rangeName)), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetNetworkIPAllocations returns the addresses allocated to the project in the network.
+func (r *ProtocolLXD) GetNetworkIPAllocations(networkName string) ([]api.NetworkIPAllocation, error) {
+	err := r.CheckExtension("network_ipam")
+	if err != nil {
+		return nil, err
+	}
+
+	allocations := []api.NetworkIPAllocation{}
+
+	// Fetch the raw value.
+	_, err = r.queryStruct("GET", fmt.Sprintf("/networks/%s/ip-allocations?recursion=1", url.PathEscape(networkName)), nil, "", &allocations)
+	if err != nil {
+		return nil, err
+	}
+
+	return allocations, nil
+}
+
+// GetNetworkIPAllocation returns the allocation of the provided address in the network.
+func (r *ProtocolLXD) GetNetworkIPAllocation(networkName string, address string) (*api.NetworkIPAllocation, error) {
+	err := r.CheckExtension("network_ipam")
+	if err != nil {
+		return nil, err
+	}
+
+	allocation := api.NetworkIPAllocation{}
+
+	// Fetch the raw value.
+	_, err = r.queryStruct("GET", fmt.Sprintf("/networks/%s/ip-allocations/%s", url.PathEscape(networkName), url.PathEscape(address)), nil, "", &allocation)
+	if err != nil {
+		return nil, err
+	}
+
+	return &allocation, nil
+}
+
+// CreateNetworkIPAllocation allocates an address in the network and returns the allocation.
+func (r *ProtocolLXD) CreateNetworkIPAllocation(networkName string, allocation api.NetworkIPAllocationsPost) (*api.NetworkIPAllocation, error) {
+	err := r.CheckExtension("network_ipam")
+	if err != nil {
+		return nil, err
+	}
+
+	allocated := api.NetworkIPAllocation{}
+
+	// Send the request.
+	_, err = r.queryStruct("POST", fmt.Sprintf("/networks/%s/ip-allocations", url.PathEscape(networkName)), allocation, "", &allocated)
+	if err != nil {
+		return nil, err
+	}
+
+	return &allocated, nil
+}
+
+// DeleteNetworkIPAllocation releases an address allocated in the network.
+func (r *ProtocolLXD) DeleteNetworkIPAllocation(networkName string, address string) error {
+	err := r.CheckExtension("network_ipam")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("DELETE", fmt.Sprintf("/networks/%s/ip-allocations/%s", url.PathEscape(networkName), url.PathEscape(address)), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -98,6 +98,24 @@ func (r *ProtocolLXD) GetNetworkState(name string) (*api.NetworkState, error) {
 	return &state, nil
 }
 
+// GetNetworkBGPState returns the BGP state of the network on the cluster member.
+func (r *ProtocolLXD) GetNetworkBGPState(name string) (*api.NetworkBGPState, error) {
+	err := r.CheckExtension("network_bgp_policy")
+	if err != nil {
+		return nil, err
+	}
+
+	state := api.NetworkBGPState{}
+
+	// Fetch the raw value
+	_, err = r.queryStruct("GET", fmt.Sprintf("/networks/%s/bgp", url.PathEscape(name)), nil, "", &state)
+	if err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
 // CreateNetwork defines a new network using the provided Network struct.
 func (r *ProtocolLXD) CreateNetwork(network api.NetworksPost) error {
 	err := r.CheckExtension("network")
@@ -269,6 +269,25 @@ func (r *ProtocolLXD) GetStoragePoolVolumeState(pool string, volType string, nam
 	return &state, nil
 }
 
+// VerifyStoragePoolVolume checks the integrity of a storage volume, its snapshots and its backups.
+// The problems found are returned in the "problems" key of the operation metadata and the parts of the volume
+// that couldn't be checked in the "unverified" key.
+func (r *ProtocolLXD) VerifyStoragePoolVolume(pool string, volType string, name string) (Operation, error) {
+	err := r.CheckExtension("storage_volume_verify")
+	if err != nil {
+		return nil, err
+	}
+
+	// Send the request
+	path := fmt.Sprintf("/storage-pools/%s/volumes/%s/%s/verify", url.PathEscape(pool), url.PathEscape(volType), url.PathEscape(name))
+	op, _, err := r.queryOperation("POST", path, nil, "", true)
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
 // CreateStoragePoolVolume defines a new storage volume.
 func (r *ProtocolLXD) CreateStoragePoolVolume(pool string, volume api.StorageVolumesPost) error {
 	err := r.CheckExtension("storage")
@@ -960,6 +979,20 @@ func (r *ProtocolLXD) CreateStoragePoolVolumeBackup(pool string, volName string,
 		return nil, err
 	}
 
+	if backup.IncrementalFrom != "" {
+		err = r.CheckExtension("backup_incremental")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if backup.Target != nil {
+		err = r.CheckExtension("backup_s3")
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Send the request
 	op, _, err := r.queryOperation("POST", fmt.Sprintf("/storage-pools/%s/volumes/custom/%s/backups", url.PathEscape(pool), url.PathEscape(volName)), backup, "", true)
 	if err != nil {
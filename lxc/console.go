@@ -26,8 +26,10 @@ import (
 type cmdConsole struct {
 	global *cmdGlobal
 
-	flagShowLog bool
-	flagType    string
+	flagShowLog    bool
+	flagType       string
+	flagProtocol   string
+	flagScreenshot string
 }
 
 func (c *cmdConsole) command() *cobra.Command {
@@ -38,11 +40,22 @@ func (c *cmdConsole) command() *cobra.Command {
 		`Attach to instance consoles
 
 This command allows you to interact with the boot console of an instance
-as well as retrieve past log entries from it.`))
+as well as retrieve past log entries from it.
+
+The graphical output of virtual machines can be accessed over SPICE (default)
+or VNC, or captured into a PNG image with --screenshot.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc console v1 --type=vga --protocol=vnc
+    Attach to the graphical output of virtual machine "v1" over VNC.
+
+lxc console v1 --screenshot=v1.png
+    Save a screenshot of the display of virtual machine "v1" into "v1.png".`))
 
 	cmd.RunE = c.run
 	cmd.Flags().BoolVar(&c.flagShowLog, "show-log", false, i18n.G("Retrieve the container's console log"))
-	cmd.Flags().StringVarP(&c.flagType, "type", "t", "console", i18n.G("Type of connection to establish: 'console' for serial console, 'vga' for SPICE graphical output")+"``")
+	cmd.Flags().StringVarP(&c.flagType, "type", "t", "console", i18n.G("Type of connection to establish: 'console' for serial console, 'vga' for SPICE or VNC graphical output")+"``")
+	cmd.Flags().StringVar(&c.flagProtocol, "protocol", "spice", i18n.G("Protocol of the graphical output: 'spice' or 'vnc'")+"``")
+	cmd.Flags().StringVar(&c.flagScreenshot, "screenshot", "", i18n.G("Save a PNG screenshot of the virtual machine's display into the given file")+"``")
 
 	return cmd
 }
@@ -109,6 +122,18 @@ func (c *cmdConsole) run(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf(i18n.G("Unknown output type %q"), c.flagType)
 	}
 
+	if !shared.ValueInSlice(c.flagProtocol, []string{"spice", "vnc"}) {
+		return fmt.Errorf(i18n.G("Unknown protocol %q"), c.flagProtocol)
+	}
+
+	if c.flagProtocol != "spice" && c.flagType != "vga" {
+		return errors.New(i18n.G("The --protocol flag is only supported by the 'vga' output type"))
+	}
+
+	if c.flagShowLog && c.flagScreenshot != "" {
+		return errors.New(i18n.G("The --show-log and --screenshot flags are mutually exclusive"))
+	}
+
 	// Connect to LXD
 	remote, name, err := conf.ParseRemote(args[0])
 	if err != nil {
@@ -146,14 +171,46 @@ func (c *cmdConsole) run(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	// Save a screenshot if requested
+	if c.flagScreenshot != "" {
+		return c.screenshot(d, name)
+	}
+
 	return c.runConsole(d, name)
 }
 
+func (c *cmdConsole) screenshot(d lxd.InstanceServer, name string) error {
+	image, err := d.GetInstanceConsoleScreenshot(name)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = image.Close() }()
+
+	target, err := os.Create(c.flagScreenshot)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = target.Close() }()
+
+	_, err = io.Copy(target, image)
+	if err != nil {
+		return err
+	}
+
+	return target.Close()
+}
+
 func (c *cmdConsole) runConsole(d lxd.InstanceServer, name string) error {
 	if c.flagType == "" {
 		c.flagType = "console"
 	}
 
+	if c.flagProtocol == "" {
+		c.flagProtocol = "spice"
+	}
+
 	switch c.flagType {
 	case "console":
 		return c.console(d, name)
@@ -246,6 +303,11 @@ func (c *cmdConsole) vga(d lxd.InstanceServer, name string) error {
 		Type: "vga",
 	}
 
+	// SPICE is the default protocol and older servers don't know about the field.
+	if c.flagProtocol != "spice" {
+		req.Protocol = c.flagProtocol
+	}
+
 	chDisconnect := make(chan bool)
 	chViewer := make(chan struct{})
 
@@ -258,7 +320,7 @@ func (c *cmdConsole) vga(d lxd.InstanceServer, name string) error {
 	var socket string
 	var listener net.Listener
 	if runtime.GOOS != "windows" {
-		// Create a temporary unix socket mirroring the instance's spice socket.
+		// Create a temporary unix socket mirroring the instance's graphical console socket.
 		if !shared.PathExists(conf.ConfigPath("sockets")) {
 			err := os.MkdirAll(conf.ConfigPath("sockets"), 0700)
 			if err != nil {
@@ -267,7 +329,7 @@ func (c *cmdConsole) vga(d lxd.InstanceServer, name string) error {
 		}
 
 		// Generate a random file name.
-		path, err := os.CreateTemp(conf.ConfigPath("sockets"), "*.spice")
+		path, err := os.CreateTemp(conf.ConfigPath("sockets"), "*."+c.flagProtocol)
 		if err != nil {
 			return err
 		}
@@ -287,7 +349,7 @@ func (c *cmdConsole) vga(d lxd.InstanceServer, name string) error {
 
 		defer func() { _ = os.Remove(path.Name()) }()
 
-		socket = fmt.Sprintf("spice+unix://%s", path.Name())
+		socket = fmt.Sprintf("%s+unix://%s", c.flagProtocol, path.Name())
 	} else {
 		listener, err = net.Listen("tcp", "127.0.0.1:0")
 		if err != nil {
@@ -299,7 +361,7 @@ func (c *cmdConsole) vga(d lxd.InstanceServer, name string) error {
 			return errors.New("Failed to get TCP listen address")
 		}
 
-		socket = fmt.Sprintf("spice://127.0.0.1:%d", addr.Port)
+		socket = fmt.Sprintf("%s://127.0.0.1:%d", c.flagProtocol, addr.Port)
 	}
 
 	// Clean everything up when the viewer is done.
@@ -346,9 +408,12 @@ func (c *cmdConsole) vga(d lxd.InstanceServer, name string) error {
 		}
 	}()
 
-	// Use either spicy or remote-viewer if available.
+	// Use either spicy or remote-viewer if available (spicy only supports SPICE).
 	remoteViewer := c.findCommand("remote-viewer")
-	spicy := c.findCommand("spicy")
+	spicy := ""
+	if c.flagProtocol == "spice" {
+		spicy = c.findCommand("spicy")
+	}
 
 	if remoteViewer != "" || spicy != "" {
 		var cmd *exec.Cmd
@@ -385,7 +450,12 @@ func (c *cmdConsole) vga(d lxd.InstanceServer, name string) error {
 		}()
 	} else {
 		fmt.Println(i18n.G("LXD automatically uses either spicy or remote-viewer when present."))
-		fmt.Println(i18n.G("As neither could be found, the raw SPICE socket can be found at:"))
+		if c.flagProtocol == "vnc" {
+			fmt.Println(i18n.G("As neither could be found, the raw VNC socket can be found at:"))
+		} else {
+			fmt.Println(i18n.G("As neither could be found, the raw SPICE socket can be found at:"))
+		}
+
 		fmt.Printf("  %s\n", socket)
 
 		// Wait for all connections to complete.
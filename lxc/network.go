@@ -101,10 +101,22 @@ func (c *cmdNetwork) command() *cobra.Command {
 	networkACLCmd := cmdNetworkACL{global: c.global}
 	cmd.AddCommand(networkACLCmd.command())
 
+	// Address group
+	networkAddressGroupCmd := cmdNetworkAddressGroup{global: c.global}
+	cmd.AddCommand(networkAddressGroupCmd.command())
+
 	// Forward
 	networkForwardCmd := cmdNetworkForward{global: c.global}
 	cmd.AddCommand(networkForwardCmd.command())
 
+	// IP allocation
+	networkIPAllocationCmd := cmdNetworkIPAllocation{global: c.global}
+	cmd.AddCommand(networkIPAllocationCmd.command())
+
+	// IP range
+	networkIPRangeCmd := cmdNetworkIPRange{global: c.global}
+	cmd.AddCommand(networkIPRangeCmd.command())
+
 	// Load Balancer
 	networkLoadBalancerCmd := cmdNetworkLoadBalancer{global: c.global}
 	cmd.AddCommand(networkLoadBalancerCmd.command())
@@ -0,0 +1,911 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	cli "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/lxd/shared/i18n"
+	"github.com/canonical/lxd/shared/termios"
+)
+
+type cmdNetworkAddressGroup struct {
+	global *cmdGlobal
+}
+
+func (c *cmdNetworkAddressGroup) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("address-group")
+	cmd.Short = i18n.G("Manage network address groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Manage network address groups
+
+Address groups are named sets of addresses and ports that can be referenced
+in network ACL rules using the "$<group>" format.`))
+
+	// List.
+	networkAddressGroupListCmd := cmdNetworkAddressGroupList{global: c.global, networkAddressGroup: c}
+	cmd.AddCommand(networkAddressGroupListCmd.command())
+
+	// Show.
+	networkAddressGroupShowCmd := cmdNetworkAddressGroupShow{global: c.global, networkAddressGroup: c}
+	cmd.AddCommand(networkAddressGroupShowCmd.command())
+
+	// Get.
+	networkAddressGroupGetCmd := cmdNetworkAddressGroupGet{global: c.global, networkAddressGroup: c}
+	cmd.AddCommand(networkAddressGroupGetCmd.command())
+
+	// Create.
+	networkAddressGroupCreateCmd := cmdNetworkAddressGroupCreate{global: c.global, networkAddressGroup: c}
+	cmd.AddCommand(networkAddressGroupCreateCmd.command())
+
+	// Set.
+	networkAddressGroupSetCmd := cmdNetworkAddressGroupSet{global: c.global, networkAddressGroup: c}
+	cmd.AddCommand(networkAddressGroupSetCmd.command())
+
+	// Unset.
+	networkAddressGroupUnsetCmd := cmdNetworkAddressGroupUnset{global: c.global, networkAddressGroup: c, networkAddressGroupSet: &networkAddressGroupSetCmd}
+	cmd.AddCommand(networkAddressGroupUnsetCmd.command())
+
+	// Edit.
+	networkAddressGroupEditCmd := cmdNetworkAddressGroupEdit{global: c.global, networkAddressGroup: c}
+	cmd.AddCommand(networkAddressGroupEditCmd.command())
+
+	// Rename.
+	networkAddressGroupRenameCmd := cmdNetworkAddressGroupRename{global: c.global, networkAddressGroup: c}
+	cmd.AddCommand(networkAddressGroupRenameCmd.command())
+
+	// Delete.
+	networkAddressGroupDeleteCmd := cmdNetworkAddressGroupDelete{global: c.global, networkAddressGroup: c}
+	cmd.AddCommand(networkAddressGroupDeleteCmd.command())
+
+	// Add/Remove members.
+	networkAddressGroupMemberCmd := cmdNetworkAddressGroupMember{global: c.global, networkAddressGroup: c}
+	cmd.AddCommand(networkAddressGroupMemberCmd.commandAdd())
+	cmd.AddCommand(networkAddressGroupMemberCmd.commandRemove())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+// List.
+type cmdNetworkAddressGroupList struct {
+	global              *cmdGlobal
+	networkAddressGroup *cmdNetworkAddressGroup
+
+	flagFormat string
+}
+
+func (c *cmdNetworkAddressGroupList) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list", i18n.G("[<remote>:]"))
+	cmd.Aliases = []string{"ls"}
+	cmd.Short = i18n.G("List available network address groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("List available network address groups"))
+
+	cmd.RunE = c.run
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpRemotes(false)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkAddressGroupList) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	remote := ""
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	// List the address groups.
+	if resource.name != "" {
+		return errors.New(i18n.G("Filtering isn't supported yet"))
+	}
+
+	groups, err := resource.server.GetNetworkAddressGroups()
+	if err != nil {
+		return err
+	}
+
+	data := [][]string{}
+	for _, group := range groups {
+		strUsedBy := fmt.Sprintf("%d", len(group.UsedBy))
+		details := []string{
+			group.Name,
+			group.Description,
+			strings.Join(group.Addresses, "\n"),
+			strings.Join(group.Ports, "\n"),
+			strUsedBy,
+		}
+
+		data = append(data, details)
+	}
+
+	sort.Sort(cli.SortColumnsNaturally(data))
+
+	header := []string{
+		i18n.G("NAME"),
+		i18n.G("DESCRIPTION"),
+		i18n.G("ADDRESSES"),
+		i18n.G("PORTS"),
+		i18n.G("USED BY"),
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, groups)
+}
+
+// Show.
+type cmdNetworkAddressGroupShow struct {
+	global              *cmdGlobal
+	networkAddressGroup *cmdNetworkAddressGroup
+}
+
+func (c *cmdNetworkAddressGroupShow) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("show", i18n.G("[<remote>:]<group>"))
+	cmd.Short = i18n.G("Show network address group configurations")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Show network address group configurations"))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworkAddressGroups(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkAddressGroupShow) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network address group name"))
+	}
+
+	// Show the network address group config.
+	group, _, err := resource.server.GetNetworkAddressGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	sort.Strings(group.UsedBy)
+
+	data, err := yaml.Marshal(&group)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
+
+// Get.
+type cmdNetworkAddressGroupGet struct {
+	global              *cmdGlobal
+	networkAddressGroup *cmdNetworkAddressGroup
+
+	flagIsProperty bool
+}
+
+func (c *cmdNetworkAddressGroupGet) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("get", i18n.G("[<remote>:]<group> <key>"))
+	cmd.Short = i18n.G("Get values for network address group configuration keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Get values for network address group configuration keys"))
+
+	cmd.Flags().BoolVarP(&c.flagIsProperty, "property", "p", false, i18n.G("Get the key as a network address group property"))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworkAddressGroups(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkAddressGroupGet) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network address group name"))
+	}
+
+	resp, _, err := resource.server.GetNetworkAddressGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	if c.flagIsProperty {
+		w := resp.Writable()
+		res, err := getFieldByJsonTag(&w, args[1])
+		if err != nil {
+			return fmt.Errorf(i18n.G("The property %q does not exist on the network address group %q: %v"), args[1], resource.name, err)
+		}
+
+		fmt.Printf("%v\n", res)
+	} else {
+		for k, v := range resp.Config {
+			if k == args[1] {
+				fmt.Printf("%s\n", v)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Create.
+type cmdNetworkAddressGroupCreate struct {
+	global              *cmdGlobal
+	networkAddressGroup *cmdNetworkAddressGroup
+
+	flagAddresses []string
+	flagPorts     []string
+}
+
+func (c *cmdNetworkAddressGroupCreate) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("create", i18n.G("[<remote>:]<group> [key=value...]"))
+	cmd.Short = i18n.G("Create new network address groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Create new network address groups"))
+	cmd.Example = cli.FormatSection("", i18n.G(`lxc network address-group create web --address 192.0.2.10,2001:db8::10 --port 80,443
+
+lxc network address-group create web < config.yaml
+    Create network address group with configuration from config.yaml`))
+
+	cmd.Flags().StringSliceVar(&c.flagAddresses, "address", nil, i18n.G("Addresses or subnets of the group")+"``")
+	cmd.Flags().StringSliceVar(&c.flagPorts, "port", nil, i18n.G("Ports or port ranges of the group")+"``")
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpRemotes(false)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkAddressGroupCreate) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network address group name"))
+	}
+
+	// If stdin isn't a terminal, read yaml from it.
+	var groupPut api.NetworkAddressGroupPut
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		err = yaml.UnmarshalStrict(contents, &groupPut)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Create the network address group.
+	group := api.NetworkAddressGroupsPost{
+		NetworkAddressGroupPost: api.NetworkAddressGroupPost{
+			Name: resource.name,
+		},
+		NetworkAddressGroupPut: groupPut,
+	}
+
+	group.Addresses = append(group.Addresses, c.flagAddresses...)
+	group.Ports = append(group.Ports, c.flagPorts...)
+
+	if group.Config == nil {
+		group.Config = map[string]string{}
+	}
+
+	for i := 1; i < len(args); i++ {
+		entry := strings.SplitN(args[i], "=", 2)
+		if len(entry) < 2 {
+			return fmt.Errorf(i18n.G("Bad key/value pair: %s"), args[i])
+		}
+
+		group.Config[entry[0]] = entry[1]
+	}
+
+	err = resource.server.CreateNetworkAddressGroup(group)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Network address group %s created")+"\n", resource.name)
+	}
+
+	return nil
+}
+
+// Set.
+type cmdNetworkAddressGroupSet struct {
+	global              *cmdGlobal
+	networkAddressGroup *cmdNetworkAddressGroup
+
+	flagIsProperty bool
+}
+
+func (c *cmdNetworkAddressGroupSet) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("set", i18n.G("[<remote>:]<group> <key>=<value>..."))
+	cmd.Short = i18n.G("Set network address group configuration keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Set network address group configuration keys"))
+
+	cmd.Flags().BoolVarP(&c.flagIsProperty, "property", "p", false, i18n.G("Set the key as a network address group property"))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworkAddressGroups(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkAddressGroupSet) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network address group name"))
+	}
+
+	// Get the network address group.
+	group, etag, err := resource.server.GetNetworkAddressGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	// Set the keys.
+	keys, err := getConfig(args[1:]...)
+	if err != nil {
+		return err
+	}
+
+	writable := group.Writable()
+	if c.flagIsProperty {
+		if cmd.Name() == "unset" {
+			for k := range keys {
+				err := unsetFieldByJsonTag(&writable, k)
+				if err != nil {
+					return fmt.Errorf(i18n.G("Error unsetting property: %v"), err)
+				}
+			}
+		} else {
+			err := unpackKVToWritable(&writable, keys)
+			if err != nil {
+				return fmt.Errorf(i18n.G("Error setting properties: %v"), err)
+			}
+		}
+	} else {
+		for k, v := range keys {
+			writable.Config[k] = v
+		}
+	}
+
+	return resource.server.UpdateNetworkAddressGroup(resource.name, writable, etag)
+}
+
+// Unset.
+type cmdNetworkAddressGroupUnset struct {
+	global                 *cmdGlobal
+	networkAddressGroup    *cmdNetworkAddressGroup
+	networkAddressGroupSet *cmdNetworkAddressGroupSet
+
+	flagIsProperty bool
+}
+
+func (c *cmdNetworkAddressGroupUnset) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("unset", i18n.G("[<remote>:]<group> <key>"))
+	cmd.Short = i18n.G("Unset network address group configuration keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Unset network address group configuration keys"))
+	cmd.RunE = c.run
+
+	cmd.Flags().BoolVarP(&c.flagIsProperty, "property", "p", false, i18n.G("Unset the key as a network address group property"))
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworkAddressGroups(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkAddressGroupUnset) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	c.networkAddressGroupSet.flagIsProperty = c.flagIsProperty
+
+	args = append(args, "")
+	return c.networkAddressGroupSet.run(cmd, args)
+}
+
+// Edit.
+type cmdNetworkAddressGroupEdit struct {
+	global              *cmdGlobal
+	networkAddressGroup *cmdNetworkAddressGroup
+}
+
+func (c *cmdNetworkAddressGroupEdit) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("edit", i18n.G("[<remote>:]<group>"))
+	cmd.Short = i18n.G("Edit network address group configurations as YAML")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Edit network address group configurations as YAML"))
+
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworkAddressGroups(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkAddressGroupEdit) helpTemplate() string {
+	return i18n.G(
+		`### This is a YAML representation of the network address group.
+### Any line starting with a '# will be ignored.
+###
+### A network address group consists of a set of addresses, ports and configuration items.
+###
+### An example would look like:
+### name: web
+### description: Web servers
+### addresses:
+### - 192.0.2.10
+### - 2001:db8::/64
+### ports:
+### - "80"
+### - "8000-8080"
+### config:
+###  user.foo: bah
+###
+### Note that only the addresses, ports, description and configuration keys can be changed.`)
+}
+
+func (c *cmdNetworkAddressGroupEdit) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network address group name"))
+	}
+
+	// If stdin isn't a terminal, read text from it
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		// Allow output of `lxc network address-group show` command to be passed in here, but only take the
+		// contents of the NetworkAddressGroupPut fields when updating. The other fields are silently discarded.
+		newdata := api.NetworkAddressGroup{}
+		err = yaml.UnmarshalStrict(contents, &newdata)
+		if err != nil {
+			return err
+		}
+
+		return resource.server.UpdateNetworkAddressGroup(resource.name, newdata.Writable(), "")
+	}
+
+	// Get the current config.
+	group, etag, err := resource.server.GetNetworkAddressGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&group)
+	if err != nil {
+		return err
+	}
+
+	// Spawn the editor.
+	content, err := shared.TextEditor("", []byte(c.helpTemplate()+"\n\n"+string(data)))
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Parse the text received from the editor.
+		newdata := api.NetworkAddressGroup{} // We show the full info, but only send the writable fields.
+		err = yaml.UnmarshalStrict(content, &newdata)
+		if err == nil {
+			err = resource.server.UpdateNetworkAddressGroup(resource.name, newdata.Writable(), etag)
+		}
+
+		// Respawn the editor.
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.G("Config parsing error: %s")+"\n", err)
+			fmt.Println(i18n.G("Press enter to open the editor again or ctrl+c to abort change"))
+
+			_, err := os.Stdin.Read(make([]byte, 1))
+			if err != nil {
+				return err
+			}
+
+			content, err = shared.TextEditor("", content)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		break
+	}
+
+	return nil
+}
+
+// Rename.
+type cmdNetworkAddressGroupRename struct {
+	global              *cmdGlobal
+	networkAddressGroup *cmdNetworkAddressGroup
+}
+
+func (c *cmdNetworkAddressGroupRename) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("rename", i18n.G("[<remote>:]<group> <new-name>"))
+	cmd.Aliases = []string{"mv"}
+	cmd.Short = i18n.G("Rename network address groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Rename network address groups"))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworkAddressGroups(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkAddressGroupRename) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network address group name"))
+	}
+
+	// Rename the address group.
+	err = resource.server.RenameNetworkAddressGroup(resource.name, api.NetworkAddressGroupPost{Name: args[1]})
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Network address group %s renamed to %s")+"\n", resource.name, args[1])
+	}
+
+	return nil
+}
+
+// Delete.
+type cmdNetworkAddressGroupDelete struct {
+	global              *cmdGlobal
+	networkAddressGroup *cmdNetworkAddressGroup
+}
+
+func (c *cmdNetworkAddressGroupDelete) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("delete", i18n.G("[<remote>:]<group>"))
+	cmd.Aliases = []string{"rm"}
+	cmd.Short = i18n.G("Delete network address groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Delete network address groups"))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworkAddressGroups(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkAddressGroupDelete) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network address group name"))
+	}
+
+	// Delete the network address group.
+	err = resource.server.DeleteNetworkAddressGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Network address group %s deleted")+"\n", resource.name)
+	}
+
+	return nil
+}
+
+// Add/Remove members.
+type cmdNetworkAddressGroupMember struct {
+	global              *cmdGlobal
+	networkAddressGroup *cmdNetworkAddressGroup
+
+	flagPort bool
+}
+
+func (c *cmdNetworkAddressGroupMember) commandAdd() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("add", i18n.G("[<remote>:]<group> <address>..."))
+	cmd.Short = i18n.G("Add addresses or ports to network address groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Add addresses or ports to network address groups"))
+	cmd.Example = cli.FormatSection("", i18n.G(`lxc network address-group add web 192.0.2.11 198.51.100.0/24
+
+lxc network address-group add web --port 8443`))
+
+	cmd.Flags().BoolVar(&c.flagPort, "port", false, i18n.G("Add ports or port ranges instead of addresses"))
+	cmd.RunE = c.runAdd
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworkAddressGroups(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkAddressGroupMember) runAdd(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network address group name"))
+	}
+
+	// Get the address group.
+	group, etag, err := resource.server.GetNetworkAddressGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	members := &group.Addresses
+	if c.flagPort {
+		members = &group.Ports
+	}
+
+	for _, member := range args[1:] {
+		if slices.Contains(*members, member) {
+			return fmt.Errorf(i18n.G("%q is already in the network address group"), member)
+		}
+
+		*members = append(*members, member)
+	}
+
+	return resource.server.UpdateNetworkAddressGroup(resource.name, group.Writable(), etag)
+}
+
+func (c *cmdNetworkAddressGroupMember) commandRemove() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("remove", i18n.G("[<remote>:]<group> <address>..."))
+	cmd.Short = i18n.G("Remove addresses or ports from network address groups")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Remove addresses or ports from network address groups"))
+	cmd.Example = cli.FormatSection("", i18n.G(`lxc network address-group remove web 192.0.2.11
+
+lxc network address-group remove web --port 8443`))
+
+	cmd.Flags().BoolVar(&c.flagPort, "port", false, i18n.G("Remove ports or port ranges instead of addresses"))
+	cmd.RunE = c.runRemove
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworkAddressGroups(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkAddressGroupMember) runRemove(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network address group name"))
+	}
+
+	// Get the address group.
+	group, etag, err := resource.server.GetNetworkAddressGroup(resource.name)
+	if err != nil {
+		return err
+	}
+
+	members := &group.Addresses
+	if c.flagPort {
+		members = &group.Ports
+	}
+
+	for _, member := range args[1:] {
+		index := slices.Index(*members, member)
+		if index < 0 {
+			return fmt.Errorf(i18n.G("%q is not in the network address group"), member)
+		}
+
+		*members = slices.Delete(*members, index, index+1)
+	}
+
+	return resource.server.UpdateNetworkAddressGroup(resource.name, group.Writable(), etag)
+}
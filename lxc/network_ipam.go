@@ -0,0 +1,913 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	cli "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/lxd/shared/i18n"
+	"github.com/canonical/lxd/shared/termios"
+)
+
+type cmdNetworkIPRange struct {
+	global *cmdGlobal
+}
+
+func (c *cmdNetworkIPRange) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("ip-range")
+	cmd.Short = i18n.G("Manage network IP ranges")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Manage network IP ranges
+
+IP ranges either reserve addresses of a network (so that they are never allocated)
+or delegate them to a project (so that its instances get their addresses from them).`))
+
+	// List.
+	networkIPRangeListCmd := cmdNetworkIPRangeList{global: c.global, networkIPRange: c}
+	cmd.AddCommand(networkIPRangeListCmd.command())
+
+	// Show.
+	networkIPRangeShowCmd := cmdNetworkIPRangeShow{global: c.global, networkIPRange: c}
+	cmd.AddCommand(networkIPRangeShowCmd.command())
+
+	// Create.
+	networkIPRangeCreateCmd := cmdNetworkIPRangeCreate{global: c.global, networkIPRange: c}
+	cmd.AddCommand(networkIPRangeCreateCmd.command())
+
+	// Set.
+	networkIPRangeSetCmd := cmdNetworkIPRangeSet{global: c.global, networkIPRange: c}
+	cmd.AddCommand(networkIPRangeSetCmd.command())
+
+	// Edit.
+	networkIPRangeEditCmd := cmdNetworkIPRangeEdit{global: c.global, networkIPRange: c}
+	cmd.AddCommand(networkIPRangeEditCmd.command())
+
+	// Delete.
+	networkIPRangeDeleteCmd := cmdNetworkIPRangeDelete{global: c.global, networkIPRange: c}
+	cmd.AddCommand(networkIPRangeDeleteCmd.command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+// List.
+type cmdNetworkIPRangeList struct {
+	global         *cmdGlobal
+	networkIPRange *cmdNetworkIPRange
+
+	flagFormat string
+}
+
+func (c *cmdNetworkIPRangeList) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list", i18n.G("[<remote>:]<network>"))
+	cmd.Aliases = []string{"ls"}
+	cmd.Short = i18n.G("List available network IP ranges")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("List available network IP ranges"))
+
+	cmd.RunE = c.run
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkIPRangeList) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network name"))
+	}
+
+	ipRanges, err := resource.server.GetNetworkIPRanges(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data := make([][]string, 0, len(ipRanges))
+	for _, ipRange := range ipRanges {
+		project := ipRange.Project
+		if project == "" {
+			project = i18n.G("(reserved)")
+		}
+
+		details := []string{
+			ipRange.Name,
+			ipRange.Description,
+			ipRange.Start,
+			ipRange.End,
+			project,
+		}
+
+		data = append(data, details)
+	}
+
+	sort.Sort(cli.SortColumnsNaturally(data))
+
+	header := []string{
+		i18n.G("NAME"),
+		i18n.G("DESCRIPTION"),
+		i18n.G("START"),
+		i18n.G("END"),
+		i18n.G("PROJECT"),
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, ipRanges)
+}
+
+// Show.
+type cmdNetworkIPRangeShow struct {
+	global         *cmdGlobal
+	networkIPRange *cmdNetworkIPRange
+}
+
+func (c *cmdNetworkIPRangeShow) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("show", i18n.G("[<remote>:]<network> <range>"))
+	cmd.Short = i18n.G("Show network IP range configurations")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Show network IP range configurations"))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		if len(args) == 1 {
+			return c.global.cmpNetworkIPRanges(args[0])
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkIPRangeShow) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network name"))
+	}
+
+	if args[1] == "" {
+		return errors.New(i18n.G("Missing IP range name"))
+	}
+
+	// Show the network IP range.
+	ipRange, _, err := resource.server.GetNetworkIPRange(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&ipRange)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
+
+// Create.
+type cmdNetworkIPRangeCreate struct {
+	global         *cmdGlobal
+	networkIPRange *cmdNetworkIPRange
+
+	flagDelegate    string
+	flagDescription string
+}
+
+func (c *cmdNetworkIPRangeCreate) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("create", i18n.G("[<remote>:]<network> <range> [<start> <end>]"))
+	cmd.Short = i18n.G("Create new network IP ranges")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Create new network IP ranges"))
+	cmd.Example = cli.FormatSection("", i18n.G(`lxc network ip-range create lxdbr0 routers 10.0.0.2 10.0.0.9
+    Reserve addresses 10.0.0.2 to 10.0.0.9 of network lxdbr0
+
+lxc network ip-range create lxdbr0 web 10.0.0.100 10.0.0.149 --delegate web
+    Delegate addresses 10.0.0.100 to 10.0.0.149 of network lxdbr0 to project web
+
+lxc network ip-range create lxdbr0 web < range.yaml
+    Create a new network IP range for network lxdbr0 from range.yaml`))
+
+	cmd.RunE = c.run
+
+	cmd.Flags().StringVar(&c.flagDelegate, "delegate", "", i18n.G("Project to delegate the range to")+"``")
+	cmd.Flags().StringVar(&c.flagDescription, "description", "", i18n.G("Range description")+"``")
+
+	return cmd
+}
+
+func (c *cmdNetworkIPRangeCreate) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 4)
+	if exit {
+		return err
+	}
+
+	if len(args) == 3 {
+		return errors.New(i18n.G("Both the first and last addresses of the range must be provided"))
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network name"))
+	}
+
+	if args[1] == "" {
+		return errors.New(i18n.G("Missing IP range name"))
+	}
+
+	// If stdin isn't a terminal, read yaml from it.
+	var ipRangePut api.NetworkIPRangePut
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		err = yaml.UnmarshalStrict(contents, &ipRangePut)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(args) == 4 {
+		ipRangePut.Start = args[2]
+		ipRangePut.End = args[3]
+	}
+
+	if c.flagDelegate != "" {
+		ipRangePut.Project = c.flagDelegate
+	}
+
+	if c.flagDescription != "" {
+		ipRangePut.Description = c.flagDescription
+	}
+
+	// Create the network IP range.
+	ipRange := api.NetworkIPRangesPost{
+		Name:              args[1],
+		NetworkIPRangePut: ipRangePut,
+	}
+
+	ipRange.Normalise()
+
+	err = resource.server.CreateNetworkIPRange(resource.name, ipRange)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Network IP range %s created")+"\n", ipRange.Name)
+	}
+
+	return nil
+}
+
+// Set.
+type cmdNetworkIPRangeSet struct {
+	global         *cmdGlobal
+	networkIPRange *cmdNetworkIPRange
+}
+
+func (c *cmdNetworkIPRangeSet) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("set", i18n.G("[<remote>:]<network> <range> <key>=<value>..."))
+	cmd.Short = i18n.G("Set network IP range properties")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Set network IP range properties
+
+The properties of a range are its description, start, end and project.`))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		if len(args) == 1 {
+			return c.global.cmpNetworkIPRanges(args[0])
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkIPRangeSet) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 3, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network name"))
+	}
+
+	if args[1] == "" {
+		return errors.New(i18n.G("Missing IP range name"))
+	}
+
+	// Get the current range.
+	ipRange, etag, err := resource.server.GetNetworkIPRange(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	// Set the properties.
+	keys, err := getConfig(args[2:]...)
+	if err != nil {
+		return err
+	}
+
+	writable := ipRange.Writable()
+	err = unpackKVToWritable(&writable, keys)
+	if err != nil {
+		return fmt.Errorf(i18n.G("Error setting properties: %v"), err)
+	}
+
+	writable.Normalise()
+
+	return resource.server.UpdateNetworkIPRange(resource.name, ipRange.Name, writable, etag)
+}
+
+// Edit.
+type cmdNetworkIPRangeEdit struct {
+	global         *cmdGlobal
+	networkIPRange *cmdNetworkIPRange
+}
+
+func (c *cmdNetworkIPRangeEdit) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("edit", i18n.G("[<remote>:]<network> <range>"))
+	cmd.Short = i18n.G("Edit network IP range configurations as YAML")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Edit network IP range configurations as YAML"))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		if len(args) == 1 {
+			return c.global.cmpNetworkIPRanges(args[0])
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkIPRangeEdit) helpTemplate() string {
+	return i18n.G(
+		`### This is a YAML representation of the network IP range.
+### Any line starting with a '# will be ignored.
+###
+### A network IP range is reserved if it isn't delegated to a project.
+###
+### An example would look like:
+### name: web
+### description: Addresses of the web servers
+### start: 10.0.0.100
+### end: 10.0.0.149
+### project: web
+###
+### Note that the name cannot be changed.`)
+}
+
+func (c *cmdNetworkIPRangeEdit) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network name"))
+	}
+
+	if args[1] == "" {
+		return errors.New(i18n.G("Missing IP range name"))
+	}
+
+	// If stdin isn't a terminal, read text from it
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		// Allow output of `lxc network ip-range show` command to be passed in here, but only take the
+		// contents of the NetworkIPRangePut fields when updating. The other fields are silently discarded.
+		newData := api.NetworkIPRange{}
+		err = yaml.UnmarshalStrict(contents, &newData)
+		if err != nil {
+			return err
+		}
+
+		writable := newData.Writable()
+		writable.Normalise()
+
+		return resource.server.UpdateNetworkIPRange(resource.name, args[1], writable, "")
+	}
+
+	// Get the current config.
+	ipRange, etag, err := resource.server.GetNetworkIPRange(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&ipRange)
+	if err != nil {
+		return err
+	}
+
+	// Spawn the editor.
+	content, err := shared.TextEditor("", []byte(c.helpTemplate()+"\n\n"+string(data)))
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Parse the text received from the editor.
+		newData := api.NetworkIPRange{} // We show the full info, but only send the writable fields.
+		err = yaml.UnmarshalStrict(content, &newData)
+		if err == nil {
+			writable := newData.Writable()
+			writable.Normalise()
+			err = resource.server.UpdateNetworkIPRange(resource.name, args[1], writable, etag)
+		}
+
+		// Respawn the editor.
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.G("Config parsing error: %s")+"\n", err)
+			fmt.Println(i18n.G("Press enter to open the editor again or ctrl+c to abort change"))
+
+			_, err := os.Stdin.Read(make([]byte, 1))
+			if err != nil {
+				return err
+			}
+
+			content, err = shared.TextEditor("", content)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		break
+	}
+
+	return nil
+}
+
+// Delete.
+type cmdNetworkIPRangeDelete struct {
+	global         *cmdGlobal
+	networkIPRange *cmdNetworkIPRange
+}
+
+func (c *cmdNetworkIPRangeDelete) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("delete", i18n.G("[<remote>:]<network> <range>"))
+	cmd.Aliases = []string{"rm"}
+	cmd.Short = i18n.G("Delete network IP ranges")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Delete network IP ranges"))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		if len(args) == 1 {
+			return c.global.cmpNetworkIPRanges(args[0])
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkIPRangeDelete) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network name"))
+	}
+
+	if args[1] == "" {
+		return errors.New(i18n.G("Missing IP range name"))
+	}
+
+	// Delete the network IP range.
+	err = resource.server.DeleteNetworkIPRange(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Network IP range %s deleted")+"\n", args[1])
+	}
+
+	return nil
+}
+
+type cmdNetworkIPAllocation struct {
+	global *cmdGlobal
+}
+
+func (c *cmdNetworkIPAllocation) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("ip-allocation")
+	cmd.Short = i18n.G("Manage network IP allocations")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Manage network IP allocations
+
+Addresses are allocated to the current project. Addresses allocated to instance NICs
+are managed by LXD and can't be released manually.`))
+
+	// List.
+	networkIPAllocationListCmd := cmdNetworkIPAllocationList{global: c.global, networkIPAllocation: c}
+	cmd.AddCommand(networkIPAllocationListCmd.command())
+
+	// Show.
+	networkIPAllocationShowCmd := cmdNetworkIPAllocationShow{global: c.global, networkIPAllocation: c}
+	cmd.AddCommand(networkIPAllocationShowCmd.command())
+
+	// Create.
+	networkIPAllocationCreateCmd := cmdNetworkIPAllocationCreate{global: c.global, networkIPAllocation: c}
+	cmd.AddCommand(networkIPAllocationCreateCmd.command())
+
+	// Delete.
+	networkIPAllocationDeleteCmd := cmdNetworkIPAllocationDelete{global: c.global, networkIPAllocation: c}
+	cmd.AddCommand(networkIPAllocationDeleteCmd.command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+// List.
+type cmdNetworkIPAllocationList struct {
+	global              *cmdGlobal
+	networkIPAllocation *cmdNetworkIPAllocation
+
+	flagFormat string
+}
+
+func (c *cmdNetworkIPAllocationList) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list", i18n.G("[<remote>:]<network>"))
+	cmd.Aliases = []string{"ls"}
+	cmd.Short = i18n.G("List the addresses allocated in a network")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("List the addresses allocated in a network"))
+
+	cmd.RunE = c.run
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkIPAllocationList) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network name"))
+	}
+
+	allocations, err := resource.server.GetNetworkIPAllocations(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data := make([][]string, 0, len(allocations))
+	for _, allocation := range allocations {
+		details := []string{
+			allocation.Address,
+			allocation.Description,
+			allocation.Project,
+			allocation.Instance,
+			allocation.Device,
+		}
+
+		data = append(data, details)
+	}
+
+	sort.Sort(cli.SortColumnsNaturally(data))
+
+	header := []string{
+		i18n.G("ADDRESS"),
+		i18n.G("DESCRIPTION"),
+		i18n.G("PROJECT"),
+		i18n.G("INSTANCE"),
+		i18n.G("DEVICE"),
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, allocations)
+}
+
+// Show.
+type cmdNetworkIPAllocationShow struct {
+	global              *cmdGlobal
+	networkIPAllocation *cmdNetworkIPAllocation
+}
+
+func (c *cmdNetworkIPAllocationShow) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("show", i18n.G("[<remote>:]<network> <address>"))
+	cmd.Short = i18n.G("Show network IP allocations")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Show network IP allocations"))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkIPAllocationShow) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network name"))
+	}
+
+	if args[1] == "" {
+		return errors.New(i18n.G("Missing address"))
+	}
+
+	// Show the network IP allocation.
+	allocation, err := resource.server.GetNetworkIPAllocation(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&allocation)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
+
+// Create.
+type cmdNetworkIPAllocationCreate struct {
+	global              *cmdGlobal
+	networkIPAllocation *cmdNetworkIPAllocation
+
+	flagFamily      string
+	flagDescription string
+}
+
+func (c *cmdNetworkIPAllocationCreate) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("create", i18n.G("[<remote>:]<network> [<address>]"))
+	cmd.Short = i18n.G("Allocate addresses in a network")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Allocate addresses in a network
+
+If no address is specified, the first free address of the ranges delegated to the project is allocated.`))
+	cmd.Example = cli.FormatSection("", i18n.G(`lxc network ip-allocation create lxdbr0 10.0.0.100
+    Allocate address 10.0.0.100 of network lxdbr0
+
+lxc network ip-allocation create lxdbr0 --family inet6
+    Allocate a free IPv6 address of network lxdbr0`))
+
+	cmd.RunE = c.run
+
+	cmd.Flags().StringVar(&c.flagFamily, "family", "inet", i18n.G("Family of the address to allocate when none is specified (inet or inet6)")+"``")
+	cmd.Flags().StringVar(&c.flagDescription, "description", "", i18n.G("Allocation description")+"``")
+
+	return cmd
+}
+
+func (c *cmdNetworkIPAllocationCreate) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network name"))
+	}
+
+	req := api.NetworkIPAllocationsPost{
+		Description: c.flagDescription,
+	}
+
+	if len(args) > 1 {
+		req.Address = args[1]
+	} else {
+		req.Family = c.flagFamily
+	}
+
+	req.Normalise()
+
+	allocation, err := resource.server.CreateNetworkIPAllocation(resource.name, req)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Address %s allocated")+"\n", allocation.Address)
+	}
+
+	return nil
+}
+
+// Delete.
+type cmdNetworkIPAllocationDelete struct {
+	global              *cmdGlobal
+	networkIPAllocation *cmdNetworkIPAllocation
+}
+
+func (c *cmdNetworkIPAllocationDelete) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("delete", i18n.G("[<remote>:]<network> <address>"))
+	cmd.Aliases = []string{"rm"}
+	cmd.Short = i18n.G("Release addresses allocated in a network")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Release addresses allocated in a network"))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpNetworks(toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkIPAllocationDelete) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing network name"))
+	}
+
+	if args[1] == "" {
+		return errors.New(i18n.G("Missing address"))
+	}
+
+	// Release the address.
+	err = resource.server.DeleteNetworkIPAllocation(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Address %s released")+"\n", args[1])
+	}
+
+	return nil
+}
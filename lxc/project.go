@@ -957,7 +957,7 @@ func (c *cmdProjectInfo) run(cmd *cobra.Command, args []string) error {
 	}
 
 	// Render the output
-	byteLimits := []string{"disk", "memory"}
+	byteLimits := []string{"disk", "disk-bandwidth", "memory"}
 	data := [][]string{}
 	for k, v := range projectState.Resources {
 		shortKey := strings.SplitN(k, ".", 2)[0]
@@ -541,6 +541,7 @@ func (c *cmdStorageInfo) run(cmd *cobra.Command, args []string) error {
 	descriptionstring := i18n.G("description")
 	totalspacestring := i18n.G("total space")
 	spaceusedstring := i18n.G("space used")
+	spaceallocatedstring := i18n.G("space allocated")
 
 	// Initialize the usedby map
 	poolusedby[usedbystring] = make(map[string][]string)
@@ -623,6 +624,14 @@ func (c *cmdStorageInfo) run(cmd *cobra.Command, args []string) error {
 		poolinfo[infostring][spaceusedstring] = units.GetByteSizeStringIEC(int64(res.Space.Used), 2)
 	}
 
+	if res.Space.Allocated > 0 {
+		if c.flagBytes {
+			poolinfo[infostring][spaceallocatedstring] = strconv.FormatUint(res.Space.Allocated, 10)
+		} else {
+			poolinfo[infostring][spaceallocatedstring] = units.GetByteSizeStringIEC(int64(res.Space.Allocated), 2)
+		}
+	}
+
 	poolinfodata, err := yaml.Marshal(poolinfo)
 	if err != nil {
 		return err
@@ -68,6 +68,10 @@ Unless specified through a prefix, all volume operations affect "custom" (user c
 	storageVolumeAttachProfileCmd := cmdStorageVolumeAttachProfile{global: c.global, storage: c.storage, storageVolume: c}
 	cmd.AddCommand(storageVolumeAttachProfileCmd.command())
 
+	// Check
+	storageVolumeCheckCmd := cmdStorageVolumeCheck{global: c.global, storage: c.storage, storageVolume: c}
+	cmd.AddCommand(storageVolumeCheckCmd.command())
+
 	// Copy
 	storageVolumeCopyCmd := cmdStorageVolumeCopy{global: c.global, storage: c.storage, storageVolume: c}
 	cmd.AddCommand(storageVolumeCopyCmd.command())
@@ -1334,6 +1338,127 @@ func (c *cmdStorageVolumeGet) run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// Check.
+type cmdStorageVolumeCheck struct {
+	global        *cmdGlobal
+	storage       *cmdStorage
+	storageVolume *cmdStorageVolume
+}
+
+func (c *cmdStorageVolumeCheck) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("check", i18n.G("[<remote>:]<pool> [<type>/]<volume>"))
+	cmd.Short = i18n.G("Check the integrity of storage volumes")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Check the integrity of storage volumes
+
+The volume, its snapshots and its backups are checked using the tools of the storage driver.
+Problems found are reported and also recorded as a warning on the volume.
+The parts of the volume that the storage driver can't check are reported separately.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc storage volume check default data
+    Checks the custom volume "data" in pool "default".
+
+lxc storage volume check default virtual-machine/data
+    Checks the volume of virtual machine "data" in pool "default".`))
+
+	cmd.Flags().StringVar(&c.storage.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpStoragePools(toComplete)
+		}
+
+		if len(args) == 1 {
+			return c.global.cmpStoragePoolVolumes(args[0])
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdStorageVolumeCheck) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New(i18n.G("Missing pool name"))
+	}
+
+	client := resource.server
+
+	// Parse the input
+	volName, volType := parseVolume("custom", args[1])
+
+	// Use the provided target.
+	if c.storage.flagTarget != "" {
+		client = client.UseTarget(c.storage.flagTarget)
+	}
+
+	op, err := client.VerifyStoragePoolVolume(resource.name, volType, volName)
+	if err != nil {
+		return err
+	}
+
+	// Watch the background operation
+	progress := cli.ProgressRenderer{
+		Format: i18n.G("Checking storage volume: %s"),
+		Quiet:  c.global.flagQuiet,
+	}
+
+	_, err = op.AddHandler(progress.UpdateOp)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	err = cli.CancelableWait(op, &progress)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	progress.Done("")
+
+	unverified, _ := op.Get().Metadata["unverified"].([]any)
+	if len(unverified) > 0 && !c.global.flagQuiet {
+		fmt.Println(i18n.G("Not checked:"))
+		for _, part := range unverified {
+			fmt.Printf("  - %v\n", part)
+		}
+	}
+
+	problems, _ := op.Get().Metadata["problems"].([]any)
+	if len(problems) == 0 {
+		if !c.global.flagQuiet {
+			fmt.Println(i18n.G("No problems found"))
+		}
+
+		return nil
+	}
+
+	fmt.Println(i18n.G("Problems:"))
+	for _, problem := range problems {
+		fmt.Printf("  - %v\n", problem)
+	}
+
+	return fmt.Errorf(i18n.G("Found %d problem(s) with storage volume %q"), len(problems), volName)
+}
+
 // Info.
 type cmdStorageVolumeInfo struct {
 	global        *cmdGlobal
@@ -1475,6 +1600,13 @@ func (c *cmdStorageVolumeInfo) run(cmd *cobra.Command, args []string) error {
 		fmt.Printf(i18n.G("Created: %s")+"\n", vol.CreatedAt.Local().Format(layout))
 	}
 
+	if volState != nil && len(volState.Problems) > 0 {
+		fmt.Println("\n" + i18n.G("Problems:"))
+		for _, problem := range volState.Problems {
+			fmt.Printf("  - %s\n", problem)
+		}
+	}
+
 	// List snapshots
 	firstSnapshot := true
 	if len(volSnapshots) > 0 {
@@ -2615,6 +2747,7 @@ type cmdStorageVolumeExport struct {
 	flagVolumeOnly           bool
 	flagOptimizedStorage     bool
 	flagCompressionAlgorithm string
+	flagIncrementalFrom      string
 }
 
 func (c *cmdStorageVolumeExport) command() *cobra.Command {
@@ -2628,6 +2761,7 @@ func (c *cmdStorageVolumeExport) command() *cobra.Command {
 	cmd.Flags().BoolVar(&c.flagOptimizedStorage, "optimized-storage", false,
 		i18n.G("Use storage driver optimized format (can only be restored on a similar pool)"))
 	cmd.Flags().StringVar(&c.flagCompressionAlgorithm, "compression", "", i18n.G("Define a compression algorithm: for backup or none")+"``")
+	cmd.Flags().StringVar(&c.flagIncrementalFrom, "incremental-from", "", i18n.G("Only export the changes since the given backup")+"``")
 	cmd.Flags().StringVar(&c.storage.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
 	cmd.RunE = c.run
 
@@ -2684,6 +2818,7 @@ func (c *cmdStorageVolumeExport) run(cmd *cobra.Command, args []string) error {
 		VolumeOnly:           volumeOnly,
 		OptimizedStorage:     c.flagOptimizedStorage,
 		CompressionAlgorithm: c.flagCompressionAlgorithm,
+		IncrementalFrom:      c.flagIncrementalFrom,
 	}
 
 	op, err := d.CreateStoragePoolVolumeBackup(name, volName, req)
@@ -18,3 +18,14 @@ type API10Put struct {
 	// Example: true
 	Devlxd bool `json:"devlxd" yaml:"devlxd"`
 }
+
+// FSFreezePut contains the fields which are needed to freeze or thaw the guest filesystems.
+type FSFreezePut struct {
+	// Whether the filesystems should be frozen
+	// Example: true
+	Frozen bool `json:"frozen" yaml:"frozen"`
+
+	// Number of seconds after which frozen filesystems are thawed automatically (0 to disable)
+	// Example: 30
+	Timeout int `json:"timeout" yaml:"timeout"`
+}
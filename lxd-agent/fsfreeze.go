@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	agentAPI "github.com/canonical/lxd/lxd-agent/api"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// Filesystem freeze ioctls from linux/fs.h (_IOWR('X', 119, int) and _IOWR('X', 120, int)).
+const ioctlFIFreeze = 0xC0045877
+const ioctlFIThaw = 0xC0045878
+
+var fsfreezeCmd = APIEndpoint{
+	Name: "fsfreeze",
+	Path: "fsfreeze",
+
+	Put: APIEndpointAction{Handler: fsfreezePut},
+}
+
+// fsfreezeMu protects fsfreezeFrozen and fsfreezeTimer.
+var fsfreezeMu sync.Mutex
+
+// fsfreezeFrozen contains the mount points of the currently frozen filesystems.
+var fsfreezeFrozen []string
+
+// fsfreezeTimer thaws the frozen filesystems if LXD doesn't do so in time.
+var fsfreezeTimer *time.Timer
+
+func fsfreezePut(d *Daemon, r *http.Request) response.Response {
+	req := agentAPI.FSFreezePut{}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if req.Frozen {
+		err = fsFreeze(time.Duration(req.Timeout) * time.Second)
+	} else {
+		err = fsThaw()
+	}
+
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return response.EmptySyncResponse
+}
+
+// fsFreeze freezes all the block device backed filesystems.
+// If timeout is set, the filesystems are thawed automatically once it expires.
+func fsFreeze(timeout time.Duration) error {
+	fsfreezeMu.Lock()
+	defer fsfreezeMu.Unlock()
+
+	if len(fsfreezeFrozen) > 0 {
+		return fmt.Errorf("Filesystems are already frozen")
+	}
+
+	mountPoints, err := fsfreezeMountPoints()
+	if err != nil {
+		return err
+	}
+
+	// Freeze in reverse mount order so that nested filesystems are frozen before their parents.
+	for i := len(mountPoints) - 1; i >= 0; i-- {
+		err = fsfreezeIoctl(mountPoints[i], ioctlFIFreeze)
+		if err != nil {
+			// Skip filesystems which don't support freezing.
+			if errors.Is(err, unix.EOPNOTSUPP) {
+				continue
+			}
+
+			thawErr := fsThawFrozen()
+			if thawErr != nil {
+				logger.Error("Failed thawing filesystems", logger.Ctx{"err": thawErr})
+			}
+
+			return fmt.Errorf("Failed freezing filesystem %q: %w", mountPoints[i], err)
+		}
+
+		fsfreezeFrozen = append(fsfreezeFrozen, mountPoints[i])
+	}
+
+	if timeout > 0 {
+		var timer *time.Timer
+		timer = time.AfterFunc(timeout, func() {
+			fsfreezeMu.Lock()
+			defer fsfreezeMu.Unlock()
+
+			// Ignore the timer if the filesystems have been thawed in the meantime.
+			if fsfreezeTimer != timer {
+				return
+			}
+
+			err := fsThawFrozen()
+			if err != nil {
+				logger.Error("Failed thawing filesystems after freeze timeout", logger.Ctx{"err": err})
+				return
+			}
+
+			logger.Warn("Thawed filesystems after freeze timeout", logger.Ctx{"timeout": timeout})
+		})
+
+		fsfreezeTimer = timer
+	}
+
+	return nil
+}
+
+// fsThaw thaws the filesystems frozen by fsFreeze.
+func fsThaw() error {
+	fsfreezeMu.Lock()
+	defer fsfreezeMu.Unlock()
+
+	return fsThawFrozen()
+}
+
+// fsThawFrozen thaws the frozen filesystems. The caller must hold fsfreezeMu.
+func fsThawFrozen() error {
+	if fsfreezeTimer != nil {
+		fsfreezeTimer.Stop()
+		fsfreezeTimer = nil
+	}
+
+	var errs []error
+
+	// Thaw in reverse freeze order so that parent filesystems are thawed before their nested filesystems.
+	for i := len(fsfreezeFrozen) - 1; i >= 0; i-- {
+		err := fsfreezeIoctl(fsfreezeFrozen[i], ioctlFIThaw)
+		if err != nil && !errors.Is(err, unix.EINVAL) {
+			errs = append(errs, fmt.Errorf("Failed thawing filesystem %q: %w", fsfreezeFrozen[i], err))
+		}
+	}
+
+	fsfreezeFrozen = nil
+
+	return errors.Join(errs...)
+}
+
+// fsfreezeMountPoints returns the mount points of the block device backed filesystems in mount order.
+// Each filesystem is only returned once, even if it's mounted in multiple places.
+func fsfreezeMountPoints() ([]string, error) {
+	f, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	unescape := strings.NewReplacer(
+		"\\040", " ",
+		"\\011", "\t",
+		"\\012", "\n",
+		"\\134", "\\")
+
+	mountPoints := []string{}
+	sources := map[string]bool{}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// The mount point is the 5th field and the mount source follows the filesystem type after the separator.
+		fields := strings.Fields(scanner.Text())
+		separator := -1
+		for i, field := range fields {
+			if field == "-" {
+				separator = i
+				break
+			}
+		}
+
+		if separator < 5 || len(fields) < separator+3 {
+			continue
+		}
+
+		// Skip the filesystems which aren't backed by a block device, such as proc, tmpfs or the agent config share.
+		source := fields[separator+2]
+		if !strings.HasPrefix(source, "/dev/") || sources[source] {
+			continue
+		}
+
+		sources[source] = true
+		mountPoints = append(mountPoints, unescape.Replace(fields[4]))
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return mountPoints, nil
+}
+
+// fsfreezeIoctl runs a filesystem freeze ioctl on the filesystem mounted on the given path.
+func fsfreezeIoctl(mountPoint string, ioctl uint) error {
+	f, err := os.Open(mountPoint)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	_, err = unix.IoctlRetInt(int(f.Fd()), ioctl)
+	return err
+}
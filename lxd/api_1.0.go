@@ -57,6 +57,7 @@ var api10 = []APIEndpoint{
 	instanceBackupsCmd,
 	instanceCmd,
 	instanceConsoleCmd,
+	instanceConsoleScreenshotCmd,
 	instanceExecCmd,
 	instanceFileCmd,
 	instanceExecOutputCmd,
@@ -85,12 +86,20 @@ var api10 = []APIEndpoint{
 	networkLeasesCmd,
 	networksCmd,
 	networkStateCmd,
+	networkBGPCmd,
 	networkACLCmd,
 	networkACLsCmd,
 	networkACLLogCmd,
+	networkACLStateCmd,
+	networkAddressGroupCmd,
+	networkAddressGroupsCmd,
 	networkAllocationsCmd,
 	networkForwardCmd,
 	networkForwardsCmd,
+	networkIPAllocationCmd,
+	networkIPAllocationsCmd,
+	networkIPRangeCmd,
+	networkIPRangesCmd,
 	networkLoadBalancerCmd,
 	networkLoadBalancersCmd,
 	networkPeerCmd,
@@ -124,6 +133,7 @@ var api10 = []APIEndpoint{
 	storagePoolVolumeTypeCustomBackupCmd,
 	storagePoolVolumeTypeCustomBackupExportCmd,
 	storagePoolVolumeTypeStateCmd,
+	storagePoolVolumeTypeVerifyCmd,
 	warningsCmd,
 	warningCmd,
 	metricsCmd,
@@ -993,6 +993,9 @@ func clusterInitMember(d lxd.InstanceServer, client lxd.InstanceServer, memberCo
 		// Delete config keys that are automatically populated by LXD
 		delete(post.Config, "volatile.initial_source")
 		delete(post.Config, "zfs.pool_name")
+		for _, key := range db.InternalStorageConfig {
+			delete(post.Config, key)
+		}
 
 		// Apply the node-specific config supplied by the user.
 		for _, config := range memberConfig {
@@ -2819,8 +2822,10 @@ func clusterCheckStoragePoolsMatch(cluster *db.Cluster, reqPools []api.StoragePo
 				if pool.Driver != reqPool.Driver {
 					return fmt.Errorf("Mismatching driver for storage pool %s", name)
 				}
-				// Exclude the keys which are node-specific.
-				exclude := db.NodeSpecificStorageConfig
+				// Exclude the keys which are node-specific or internal.
+				exclude := make([]string, 0, len(db.NodeSpecificStorageConfig)+len(db.InternalStorageConfig))
+				exclude = append(exclude, db.NodeSpecificStorageConfig...)
+				exclude = append(exclude, db.InternalStorageConfig...)
 				err = util.CompareConfigs(pool.Config, reqPool.Config, exclude)
 				if err != nil {
 					return fmt.Errorf("Mismatching config for storage pool %s: %w", name, err)
@@ -52,6 +52,7 @@ var apiInternal = []APIEndpoint{
 	internalContainerOnStartCmd,
 	internalContainerOnStopCmd,
 	internalContainerOnStopNSCmd,
+	internalFirewallCheckCmd,
 	internalGarbageCollectorCmd,
 	internalImageOptimizeCmd,
 	internalImageRefreshCmd,
@@ -59,6 +60,7 @@ var apiInternal = []APIEndpoint{
 	internalReadyCmd,
 	internalShutdownCmd,
 	internalSQLCmd,
+	internalStoragePoolVolumeLimitsCmd,
 	internalWarningCreateCmd,
 	internalIdentityCacheRefreshCmd,
 }
@@ -106,6 +108,13 @@ var internalGarbageCollectorCmd = APIEndpoint{
 	Get: APIEndpointAction{Handler: internalGC, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
 }
 
+var internalFirewallCheckCmd = APIEndpoint{
+	Path: "firewall/check",
+
+	Get:  APIEndpointAction{Handler: internalFirewallCheckGet, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+	Post: APIEndpointAction{Handler: internalFirewallCheckPost, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+}
+
 var internalRAFTSnapshotCmd = APIEndpoint{
 	Path: "raft-snapshot",
 
@@ -142,6 +151,12 @@ var internalIdentityCacheRefreshCmd = APIEndpoint{
 	Post: APIEndpointAction{Handler: internalIdentityCacheRefresh, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
 }
 
+var internalStoragePoolVolumeLimitsCmd = APIEndpoint{
+	Path: "storage-pools/{poolName}/volumes/custom/{volumeName}/limits",
+
+	Post: APIEndpointAction{Handler: internalStoragePoolVolumeLimits, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+}
+
 type internalImageOptimizePost struct {
 	Image api.Image `json:"image" yaml:"image"`
 	Pool  string    `json:"pool"  yaml:"pool"`
@@ -1031,12 +1046,72 @@ func internalRAFTSnapshot(d *Daemon, r *http.Request) response.Response {
 	return response.InternalError(fmt.Errorf("Not supported"))
 }
 
+// internalFirewallCheckGet returns the firewall rules that are missing from the live firewall state.
+func internalFirewallCheckGet(d *Daemon, r *http.Request) response.Response {
+	drifts, err := d.State().Firewall.Check()
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, drifts)
+}
+
+// internalFirewallCheckPost reapplies the firewall rules that are missing from the live firewall state and returns
+// them.
+func internalFirewallCheckPost(d *Daemon, r *http.Request) response.Response {
+	drifts, err := firewallReconcile(r.Context(), d.State())
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, drifts)
+}
+
 func internalBGPState(d *Daemon, r *http.Request) response.Response {
 	s := d.State()
 
 	return response.SyncResponse(true, s.BGP.Debug())
 }
 
+// internalStoragePoolVolumeLimits applies the I/O limits of a custom volume to the running instances on this
+// cluster member that use it.
+func internalStoragePoolVolumeLimits(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	poolName, err := url.PathUnescape(mux.Vars(r)["poolName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	volumeName, err := url.PathUnescape(mux.Vars(r)["volumeName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName := request.ProjectParam(r)
+
+	pool, err := storagePools.LoadByName(s, poolName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var dbVolume *db.StorageVolume
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		dbVolume, err = tx.GetStoragePoolVolume(ctx, pool.ID(), projectName, cluster.StoragePoolVolumeTypeCustom, volumeName, true)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	_, err = storagePoolVolumeApplyLimits(s, projectName, pool.Name(), &dbVolume.StorageVolume)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
+
 func internalIdentityCacheRefresh(d *Daemon, r *http.Request) response.Response {
 	logger.Debug("Received identity cache update notification - refreshing cache")
 	d.State().UpdateIdentityCache()
@@ -7,6 +7,7 @@ import (
 	"net"
 	"net/http"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -19,6 +20,7 @@ import (
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/locking"
 	"github.com/canonical/lxd/lxd/metrics"
+	"github.com/canonical/lxd/lxd/network/acl"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
 	"github.com/canonical/lxd/lxd/state"
@@ -135,6 +137,9 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	// Register network ACL metrics.
+	intMetrics.Merge(networkACLMetrics(s))
+
 	// invalidProjectFilters returns project filters which are either not in cache or have expired.
 	invalidProjectFilters := func(projectNames []string) []dbCluster.InstanceFilter {
 		metricsCacheLock.Lock()
@@ -377,6 +382,32 @@ func getFilteredMetrics(s *state.State, r *http.Request, compress bool, metricSe
 	return response.SyncResponsePlain(true, compress, metricSet.String())
 }
 
+func networkACLMetrics(s *state.State) *metrics.MetricSet {
+	out := metrics.NewMetricSet(nil)
+
+	ruleCounters, err := acl.FirewallACLRuleCounters(s)
+	if err != nil {
+		logger.Warn("Failed to get network ACL rule counters", logger.Ctx{"err": err})
+		return out
+	}
+
+	for _, ruleCounter := range ruleCounters {
+		labels := map[string]string{
+			"project":   ruleCounter.Project,
+			"name":      ruleCounter.ACL,
+			"network":   ruleCounter.Network,
+			"direction": ruleCounter.Direction,
+			"rule":      strconv.Itoa(ruleCounter.Index),
+			"action":    ruleCounter.Action,
+		}
+
+		out.AddSamples(metrics.NetworkACLRuleBytesTotal, metrics.Sample{Labels: labels, Value: float64(ruleCounter.Bytes)})
+		out.AddSamples(metrics.NetworkACLRulePacketsTotal, metrics.Sample{Labels: labels, Value: float64(ruleCounter.Packets)})
+	}
+
+	return out
+}
+
 func internalMetrics(ctx context.Context, daemonStartTime time.Time, tx *db.ClusterTx) *metrics.MetricSet {
 	out := metrics.NewMetricSet(nil)
 
@@ -417,6 +448,15 @@ func internalMetrics(ctx context.Context, daemonStartTime time.Time, tx *db.Clus
 		}
 	}
 
+	// Storage pool space
+	for poolName, space := range readStoragePoolSpaceCache() {
+		labels := map[string]string{"pool": poolName}
+
+		out.AddSamples(metrics.StoragePoolAllocatedBytes, metrics.Sample{Labels: labels, Value: float64(space.Allocated)})
+		out.AddSamples(metrics.StoragePoolTotalBytes, metrics.Sample{Labels: labels, Value: float64(space.Total)})
+		out.AddSamples(metrics.StoragePoolUsedBytes, metrics.Sample{Labels: labels, Value: float64(space.Used)})
+	}
+
 	// Daemon uptime
 	out.AddSamples(metrics.UptimeSeconds, metrics.Sample{Value: time.Since(daemonStartTime).Seconds()})
 
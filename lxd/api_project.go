@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -13,10 +14,14 @@ import (
 
 	"github.com/gorilla/mux"
 
+	"github.com/canonical/lxd/client"
 	"github.com/canonical/lxd/lxd/auth"
+	lxdCluster "github.com/canonical/lxd/lxd/cluster"
 	"github.com/canonical/lxd/lxd/db"
 	"github.com/canonical/lxd/lxd/db/cluster"
 	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/device"
+	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/lifecycle"
 	"github.com/canonical/lxd/lxd/network"
 	"github.com/canonical/lxd/lxd/operations"
@@ -503,6 +508,16 @@ func projectPut(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
+	// The database was already updated by the notifying member, only apply the changes to the local instances.
+	if isClusterNotification(r) {
+		err = projectNetworkShapingUpdate(s, project.Name)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		return response.EmptySyncResponse
+	}
+
 	requestor := request.CreateRequestor(r)
 	s.Events.SendLifecycle(project.Name, lifecycle.ProjectUpdated.Event(project.Name, requestor, nil))
 
@@ -725,9 +740,80 @@ func projectChange(s *state.State, project *api.Project, req api.ProjectPut) res
 		return response.SmartError(err)
 	}
 
+	// Re-apply the traffic shaping of the running instances of the project on all members when its aggregate
+	// bandwidth limits changed.
+	if shared.ValueInSlice("limits.network.ingress", configChanged) || shared.ValueInSlice("limits.network.egress", configChanged) {
+		err = projectNetworkShapingUpdate(s, project.Name)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		notifier, err := lxdCluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), lxdCluster.NotifyAlive)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		err = notifier(func(client lxd.InstanceServer) error {
+			return client.UpdateProject(project.Name, req, "")
+		})
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	return response.EmptySyncResponse
 }
 
+// projectNetworkShapingUpdate applies the aggregate bandwidth limits of a project to the NICs of its running
+// instances on this member.
+func projectNetworkShapingUpdate(s *state.State, projectName string) error {
+	var insts []instance.Instance
+
+	filter := cluster.InstanceFilter{Project: &projectName}
+	if s.ServerName != "" {
+		filter.Node = &s.ServerName
+	}
+
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.InstanceList(ctx, func(dbInst db.InstanceArgs, p api.Project) error {
+			inst, err := instance.Load(s, dbInst, p)
+			if err != nil {
+				return fmt.Errorf("Failed loading instance %q in project %q: %w", dbInst.Name, dbInst.Project, err)
+			}
+
+			insts = append(insts, inst)
+
+			return nil
+		}, filter)
+	})
+	if err != nil {
+		return err
+	}
+
+	failures := map[string]error{}
+	for _, inst := range insts {
+		if !inst.IsRunning() {
+			continue
+		}
+
+		err := device.NetworkUpdateProjectShaping(s, inst)
+		if err != nil {
+			failures[inst.Name()] = err
+		}
+	}
+
+	if len(failures) != 0 {
+		msg := "The following instances failed to update (project change still saved):\n"
+		for instName, err := range failures {
+			msg += fmt.Sprintf(" - Project: %s, Instance: %s: %v\n", projectName, instName, err)
+		}
+
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
 // swagger:operation POST /1.0/projects/{name} projects project_post
 //
 //	Rename the project
@@ -1010,6 +1096,15 @@ func projectIsEmpty(ctx context.Context, project *cluster.Project, tx *db.Cluste
 		return false, nil
 	}
 
+	addressGroups, err := tx.GetNetworkAddressGroupURIs(ctx, project.ID, project.Name)
+	if err != nil {
+		return false, err
+	}
+
+	if len(addressGroups) > 0 {
+		return false, nil
+	}
+
 	return true, nil
 }
 
@@ -1152,12 +1247,40 @@ func projectValidateConfig(s *state.State, config map[string]string) error {
 		//  type: string
 		//  shortdesc: Maximum disk space used by the project
 		"limits.disk": validate.Optional(validate.IsSize),
+		// lxdmeta:generate(entities=project; group=limits; key=limits.disk.iops)
+		// This value is the maximum value for the sum of the individual `limits.iops` configurations set on the custom volumes of the project.
+		// ---
+		//  type: integer
+		//  shortdesc: Maximum I/O operations per second of the project's custom volumes
+		"limits.disk.iops": validate.Optional(validate.IsUint32),
+		// lxdmeta:generate(entities=project; group=limits; key=limits.disk.bandwidth)
+		// This value is the maximum value for the sum of the individual `limits.bandwidth` configurations set on the custom volumes of the project.
+		// ---
+		//  type: string
+		//  shortdesc: Maximum I/O bandwidth of the project's custom volumes
+		"limits.disk.bandwidth": validate.Optional(validate.IsSize),
 		// lxdmeta:generate(entities=project; group=limits; key=limits.networks)
 		//
 		// ---
 		//  type: integer
 		//  shortdesc: Maximum number of networks that the project can have
 		"limits.networks": validate.Optional(validate.IsUint32),
+		// lxdmeta:generate(entities=project; group=limits; key=limits.network.ingress)
+		// The incoming traffic of all the NICs of the project's instances on each cluster member is shaped together against this limit.
+		// Specify the limit in bit/s. Various suffixes are supported (see {ref}`instances-limit-units`).
+		// See {ref}`devices-nic-limits` for more information.
+		// ---
+		//  type: string
+		//  shortdesc: Aggregate bandwidth limit for the incoming traffic of the project's NICs
+		"limits.network.ingress": validate.Optional(validate.IsBitSize),
+		// lxdmeta:generate(entities=project; group=limits; key=limits.network.egress)
+		// The outgoing traffic of all the NICs of the project's instances on each cluster member is shaped together against this limit.
+		// Specify the limit in bit/s. Various suffixes are supported (see {ref}`instances-limit-units`).
+		// See {ref}`devices-nic-limits` for more information.
+		// ---
+		//  type: string
+		//  shortdesc: Aggregate bandwidth limit for the outgoing traffic of the project's NICs
+		"limits.network.egress": validate.Optional(validate.IsBitSize),
 		// lxdmeta:generate(entities=project; group=restricted; key=restricted)
 		// This option must be enabled to allow the `restricted.*` keys to take effect.
 		// To temporarily remove the restrictions, you can disable this option instead of clearing the related keys.
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"time"
 
@@ -21,8 +22,10 @@ import (
 	"github.com/canonical/lxd/lxd/lifecycle"
 	"github.com/canonical/lxd/lxd/operations"
 	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/response"
 	"github.com/canonical/lxd/lxd/state"
 	storagePools "github.com/canonical/lxd/lxd/storage"
+	storageDrivers "github.com/canonical/lxd/lxd/storage/drivers"
 	"github.com/canonical/lxd/lxd/task"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
@@ -33,7 +36,8 @@ import (
 )
 
 // Create a new backup.
-func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.Instance, op *operations.Operation) error {
+// If target is set, the backup is streamed to that S3 object instead of being stored on the server.
+func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.Instance, target *api.BackupS3, op *operations.Operation) error {
 	l := logger.AddContext(logger.Ctx{"project": sourceInst.Project().Name, "instance": sourceInst.Name(), "name": args.Name})
 	l.Debug("Instance backup started")
 	defer l.Debug("Instance backup finished")
@@ -52,24 +56,64 @@ func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.In
 		args.OptimizedStorage = false
 	}
 
-	// Create the database entry.
-	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-		return tx.CreateInstanceBackup(ctx, args)
-	})
-	if err != nil {
-		return fmt.Errorf("Failed creating instance backup record: %w", err)
+	// Encrypted volumes are always backed up decrypted, as optimized backups can only be opened with the keys
+	// of this storage pool.
+	if args.OptimizedStorage {
+		volType, err := storagePools.InstanceTypeToVolumeType(sourceInst.Type())
+		if err != nil {
+			return err
+		}
+
+		dbVol, err := storagePools.VolumeDBGet(pool, sourceInst.Project().Name, sourceInst.Name(), volType)
+		if err != nil {
+			return fmt.Errorf("Failed loading instance storage volume: %w", err)
+		}
+
+		if shared.IsTrue(dbVol.Config["encryption"]) {
+			args.OptimizedStorage = false
+		}
+	}
+
+	// Incremental backups only contain the changes since the most recent snapshot of their parent backup.
+	var incrementalBase *api.StorageVolumeSnapshot
+	if args.IncrementalFrom != "" {
+		volType, err := storagePools.InstanceTypeToVolumeType(sourceInst.Type())
+		if err != nil {
+			return err
+		}
+
+		parentPath := shared.VarPath("backups", "instances", project.Instance(sourceInst.Project().Name, sourceInst.Name()+shared.SnapshotDelimiter+args.IncrementalFrom))
+		incrementalBase, err = backupIncrementalBase(s, pool, sourceInst.Project().Name, sourceInst.Name(), volType, args.IncrementalFrom, parentPath)
+		if err != nil {
+			return err
+		}
 	}
 
-	revert.Add(func() {
-		_ = s.DB.Cluster.Transaction(context.Background(), func(ctx context.Context, tx *db.ClusterTx) error {
-			return tx.DeleteInstanceBackup(ctx, args.Name)
+	var b *backup.InstanceBackup
+
+	if target != nil {
+		// Backups streamed to S3 aren't tracked by the server.
+		b = backup.NewInstanceBackup(s, sourceInst, -1, args.Name, args.CreationDate, args.ExpiryDate, args.InstanceOnly, args.OptimizedStorage, args.IncrementalFrom)
+	} else {
+		// Create the database entry.
+		err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.CreateInstanceBackup(ctx, args)
 		})
-	})
+		if err != nil {
+			return fmt.Errorf("Failed creating instance backup record: %w", err)
+		}
 
-	// Get the backup struct.
-	b, err := instance.BackupLoadByName(s, sourceInst.Project().Name, args.Name)
-	if err != nil {
-		return fmt.Errorf("Load backup object: %w", err)
+		revert.Add(func() {
+			_ = s.DB.Cluster.Transaction(context.Background(), func(ctx context.Context, tx *db.ClusterTx) error {
+				return tx.DeleteInstanceBackup(ctx, args.Name)
+			})
+		})
+
+		// Get the backup struct.
+		b, err = instance.BackupLoadByName(s, sourceInst.Project().Name, args.Name)
+		if err != nil {
+			return fmt.Errorf("Load backup object: %w", err)
+		}
 	}
 
 	// Detect compression method.
@@ -100,28 +144,47 @@ func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.In
 		}
 	}
 
-	// Create the target path if needed.
-	backupsPath := shared.VarPath("backups", "instances", project.Instance(sourceInst.Project().Name, sourceInst.Name()))
-	if !shared.PathExists(backupsPath) {
-		err := os.MkdirAll(backupsPath, 0700)
+	var tarFileWriter io.WriteCloser
+	if target != nil {
+		// Setup the S3 upload.
+		l.Debug("Opening S3 upload for writing", logger.Ctx{"url": target.URL})
+		s3Writer, err := backupS3Upload(s, target)
 		if err != nil {
 			return err
 		}
 
-		revert.Add(func() { _ = os.Remove(backupsPath) })
-	}
+		// Abort the upload on failure so that no partial object gets created.
+		revert.Add(func() { _ = s3Writer.CloseWithError(fmt.Errorf("Backup failed")) })
+		tarFileWriter = s3Writer
+	} else {
+		// Create the target path if needed.
+		backupsPath := shared.VarPath("backups", "instances", project.Instance(sourceInst.Project().Name, sourceInst.Name()))
+		if !shared.PathExists(backupsPath) {
+			err := os.MkdirAll(backupsPath, 0700)
+			if err != nil {
+				return err
+			}
 
-	target := shared.VarPath("backups", "instances", project.Instance(sourceInst.Project().Name, b.Name()))
+			revert.Add(func() { _ = os.Remove(backupsPath) })
+		}
 
-	// Setup the tarball writer.
-	l.Debug("Opening backup tarball for writing", logger.Ctx{"path": target})
-	tarFileWriter, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY, 0600)
-	if err != nil {
-		return fmt.Errorf("Error opening backup tarball for writing %q: %w", target, err)
-	}
+		target := shared.VarPath("backups", "instances", project.Instance(sourceInst.Project().Name, b.Name()))
 
-	defer func() { _ = tarFileWriter.Close() }()
-	revert.Add(func() { _ = os.Remove(target) })
+		// Setup the tarball writer.
+		l.Debug("Opening backup tarball for writing", logger.Ctx{"path": target})
+		tarFile, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return fmt.Errorf("Error opening backup tarball for writing %q: %w", target, err)
+		}
+
+		defer func() { _ = tarFile.Close() }()
+		revert.Add(func() {
+			_ = os.Remove(target)
+			_ = os.Remove(backup.ChecksumPath(target))
+		})
+
+		tarFileWriter = backup.NewChecksumWriter(tarFile)
+	}
 
 	// Get IDMap to unshift container as the tarball is created.
 	var idmap *idmap.IdmapSet
@@ -182,7 +245,7 @@ func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.In
 
 	// Write index file.
 	l.Debug("Adding backup index file")
-	err = backupWriteIndex(sourceInst, pool, b.OptimizedStorage(), !b.InstanceOnly(), tarWriter)
+	err = backupWriteIndex(sourceInst, pool, b.OptimizedStorage(), !b.InstanceOnly(), b.IncrementalFrom(), incrementalBase, tarWriter)
 
 	// Check compression errors.
 	if compressErr != nil {
@@ -194,7 +257,12 @@ func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.In
 		return fmt.Errorf("Error writing backup index file: %w", err)
 	}
 
-	err = pool.BackupInstance(sourceInst, tarWriter, b.OptimizedStorage(), !b.InstanceOnly(), nil)
+	var incrementalFrom string
+	if incrementalBase != nil {
+		incrementalFrom = incrementalBase.Name
+	}
+
+	err = pool.BackupInstance(sourceInst, tarWriter, b.OptimizedStorage(), !b.InstanceOnly(), incrementalFrom, nil)
 	if err != nil {
 		return fmt.Errorf("Backup create: %w", err)
 	}
@@ -222,13 +290,18 @@ func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.In
 	}
 
 	revert.Success()
-	s.Events.SendLifecycle(sourceInst.Project().Name, lifecycle.InstanceBackupCreated.Event(args.Name, b.Instance(), nil))
+
+	if target == nil {
+		s.Events.SendLifecycle(sourceInst.Project().Name, lifecycle.InstanceBackupCreated.Event(args.Name, b.Instance(), nil))
+	}
 
 	return nil
 }
 
 // backupWriteIndex generates an index.yaml file and then writes it to the root of the backup tarball.
-func backupWriteIndex(sourceInst instance.Instance, pool storagePools.Pool, optimized bool, snapshots bool, tarWriter *instancewriter.InstanceTarWriter) error {
+// If incrementalBase is set, the index describes an incremental backup of the changes since that snapshot of the
+// incrementalParent backup.
+func backupWriteIndex(sourceInst instance.Instance, pool storagePools.Pool, optimized bool, snapshots bool, incrementalParent string, incrementalBase *api.StorageVolumeSnapshot, tarWriter *instancewriter.InstanceTarWriter) error {
 	// Indicate whether the driver will include a driver-specific optimized header.
 	poolDriverOptimizedHeader := false
 	if optimized {
@@ -265,6 +338,13 @@ func backupWriteIndex(sourceInst instance.Instance, pool storagePools.Pool, opti
 		Config:           config,
 	}
 
+	if incrementalBase != nil {
+		err = backupIndexIncremental(&indexInfo, snapshots, incrementalParent, incrementalBase)
+		if err != nil {
+			return err
+		}
+	}
+
 	if snapshots {
 		indexInfo.Snapshots = make([]string, 0, len(config.Snapshots))
 		for _, s := range config.Snapshots {
@@ -296,6 +376,86 @@ func backupWriteIndex(sourceInst instance.Instance, pool storagePools.Pool, opti
 	return nil
 }
 
+// backupIncrementalBase returns the most recent snapshot contained in the parent backup stored at parentPath.
+// Incremental backups created from the parent backup only contain the changes since this snapshot, so it must
+// still exist on the source volume.
+func backupIncrementalBase(s *state.State, pool storagePools.Pool, projectName string, volumeName string, volType storageDrivers.VolumeType, parentName string, parentPath string) (*api.StorageVolumeSnapshot, error) {
+	f, err := os.Open(parentPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, api.StatusErrorf(http.StatusNotFound, "Parent backup %q not found", parentName)
+		}
+
+		return nil, fmt.Errorf("Failed opening parent backup %q: %w", parentName, err)
+	}
+
+	defer func() { _ = f.Close() }()
+
+	parentInfo, err := backup.GetInfo(f, s.OS, parentPath)
+	if err != nil {
+		return nil, fmt.Errorf("Failed reading parent backup %q: %w", parentName, err)
+	}
+
+	var base *api.StorageVolumeSnapshot
+	if parentInfo.Config != nil && len(parentInfo.Snapshots) > 0 && len(parentInfo.Config.VolumeSnapshots) > 0 {
+		base = parentInfo.Config.VolumeSnapshots[len(parentInfo.Config.VolumeSnapshots)-1]
+	} else if parentInfo.IncrementalFrom != "" && parentInfo.IncrementalFromCreatedAt != nil {
+		// An incremental parent backup without new snapshots has the same base as its own parent.
+		base = &api.StorageVolumeSnapshot{Name: parentInfo.IncrementalFrom, CreatedAt: *parentInfo.IncrementalFromCreatedAt}
+	} else {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "Parent backup %q doesn't contain any snapshots", parentName)
+	}
+
+	// Check the snapshot on the source volume is the one contained in the parent backup.
+	snapVol, err := storagePools.VolumeDBGet(pool, projectName, volumeName+shared.SnapshotDelimiter+base.Name, volType)
+	if err != nil && !response.IsNotFoundError(err) {
+		return nil, err
+	}
+
+	if err != nil || snapVol.CreatedAt.Unix() != base.CreatedAt.Unix() {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "Snapshot %q of parent backup %q no longer exists", base.Name, parentName)
+	}
+
+	return base, nil
+}
+
+// backupIndexIncremental turns the index of a full backup into the index of an incremental backup of the changes
+// since the base snapshot of the parent backup. If snapshots is set, the snapshots up to and including the base
+// snapshot are removed, as incremental backups only contain the snapshots taken after it.
+func backupIndexIncremental(indexInfo *backup.Info, snapshots bool, parentName string, base *api.StorageVolumeSnapshot) error {
+	indexInfo.IncrementalFrom = base.Name
+	indexInfo.IncrementalFromCreatedAt = &base.CreatedAt
+	indexInfo.IncrementalParent = parentName
+
+	if !snapshots {
+		return nil
+	}
+
+	config := indexInfo.Config
+
+	found := false
+	for i, snap := range config.VolumeSnapshots {
+		if snap.Name == base.Name {
+			config.VolumeSnapshots = config.VolumeSnapshots[i+1:]
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return api.StatusErrorf(http.StatusNotFound, "Snapshot %q to create incremental backup from not found", base.Name)
+	}
+
+	for i, snap := range config.Snapshots {
+		if snap.Name == base.Name {
+			config.Snapshots = config.Snapshots[i+1:]
+			break
+		}
+	}
+
+	return nil
+}
+
 func pruneExpiredBackupsTask(d *Daemon) (task.Func, task.Schedule) {
 	f := func(ctx context.Context) {
 		s := d.State()
@@ -372,7 +532,7 @@ func pruneExpiredInstanceBackups(ctx context.Context, s *state.State) error {
 			return fmt.Errorf("Error loading instance for deleting backup %q: %w", b.Name, err)
 		}
 
-		instBackup := backup.NewInstanceBackup(s, inst, b.ID, b.Name, b.CreationDate, b.ExpiryDate, b.InstanceOnly, b.OptimizedStorage)
+		instBackup := backup.NewInstanceBackup(s, inst, b.ID, b.Name, b.CreationDate, b.ExpiryDate, b.InstanceOnly, b.OptimizedStorage, b.IncrementalFrom)
 		err = instBackup.Delete()
 		if err != nil {
 			return fmt.Errorf("Error deleting instance backup %q: %w", b.Name, err)
@@ -382,7 +542,7 @@ func pruneExpiredInstanceBackups(ctx context.Context, s *state.State) error {
 	return nil
 }
 
-func volumeBackupCreate(s *state.State, args db.StoragePoolVolumeBackup, projectName string, poolName string, volumeName string) error {
+func volumeBackupCreate(s *state.State, args db.StoragePoolVolumeBackup, projectName string, poolName string, volumeName string, target *api.BackupS3, op *operations.Operation) error {
 	l := logger.AddContext(logger.Ctx{"project": projectName, "storage_volume": volumeName, "name": args.Name})
 	l.Debug("Volume backup started")
 	defer l.Debug("Volume backup finished")
@@ -401,28 +561,56 @@ func volumeBackupCreate(s *state.State, args db.StoragePoolVolumeBackup, project
 		args.OptimizedStorage = false
 	}
 
-	// Create the database entry.
-	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-		return tx.CreateStoragePoolVolumeBackup(ctx, args)
-	})
-	if err != nil {
-		return fmt.Errorf("Failed creating storage volume backup record: %w", err)
+	// Encrypted volumes are always backed up decrypted, as optimized backups can only be opened with the keys
+	// of this storage pool.
+	if args.OptimizedStorage {
+		dbVol, err := storagePools.VolumeDBGet(pool, projectName, volumeName, storageDrivers.VolumeTypeCustom)
+		if err != nil {
+			return fmt.Errorf("Failed loading storage volume: %w", err)
+		}
+
+		if shared.IsTrue(dbVol.Config["encryption"]) {
+			args.OptimizedStorage = false
+		}
 	}
 
-	revert.Add(func() {
-		_ = s.DB.Cluster.Transaction(context.Background(), func(ctx context.Context, tx *db.ClusterTx) error {
-			return tx.DeleteStoragePoolVolumeBackup(ctx, args.Name)
-		})
-	})
+	// Incremental backups only contain the changes since the most recent snapshot of their parent backup.
+	var incrementalBase *api.StorageVolumeSnapshot
+	if args.IncrementalFrom != "" {
+		parentPath := shared.VarPath("backups", "custom", pool.Name(), project.StorageVolume(projectName, volumeName+shared.SnapshotDelimiter+args.IncrementalFrom))
+		incrementalBase, err = backupIncrementalBase(s, pool, projectName, volumeName, storageDrivers.VolumeTypeCustom, args.IncrementalFrom, parentPath)
+		if err != nil {
+			return err
+		}
+	}
 
 	var backupRow db.StoragePoolVolumeBackup
 
-	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-		backupRow, err = tx.GetStoragePoolVolumeBackup(ctx, projectName, poolName, args.Name)
-		return err
-	})
-	if err != nil {
-		return fmt.Errorf("Failed getting backup record: %w", err)
+	if target != nil {
+		// Backups streamed to S3 aren't tracked by the server.
+		backupRow = args
+	} else {
+		// Create the database entry.
+		err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.CreateStoragePoolVolumeBackup(ctx, args)
+		})
+		if err != nil {
+			return fmt.Errorf("Failed creating storage volume backup record: %w", err)
+		}
+
+		revert.Add(func() {
+			_ = s.DB.Cluster.Transaction(context.Background(), func(ctx context.Context, tx *db.ClusterTx) error {
+				return tx.DeleteStoragePoolVolumeBackup(ctx, args.Name)
+			})
+		})
+
+		err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			backupRow, err = tx.GetStoragePoolVolumeBackup(ctx, projectName, poolName, args.Name)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("Failed getting backup record: %w", err)
+		}
 	}
 
 	// Detect compression method.
@@ -436,28 +624,47 @@ func volumeBackupCreate(s *state.State, args db.StoragePoolVolumeBackup, project
 		compress = s.GlobalConfig.BackupsCompressionAlgorithm()
 	}
 
-	// Create the target path if needed.
-	backupsPath := shared.VarPath("backups", "custom", pool.Name(), project.StorageVolume(projectName, volumeName))
-	if !shared.PathExists(backupsPath) {
-		err := os.MkdirAll(backupsPath, 0700)
+	var tarFileWriter io.WriteCloser
+	if target != nil {
+		// Setup the S3 upload.
+		l.Debug("Opening S3 upload for writing", logger.Ctx{"url": target.URL})
+		s3Writer, err := backupS3Upload(s, target)
 		if err != nil {
 			return err
 		}
 
-		revert.Add(func() { _ = os.Remove(backupsPath) })
-	}
+		// Abort the upload on failure so that no partial object gets created.
+		revert.Add(func() { _ = s3Writer.CloseWithError(fmt.Errorf("Backup failed")) })
+		tarFileWriter = s3Writer
+	} else {
+		// Create the target path if needed.
+		backupsPath := shared.VarPath("backups", "custom", pool.Name(), project.StorageVolume(projectName, volumeName))
+		if !shared.PathExists(backupsPath) {
+			err := os.MkdirAll(backupsPath, 0700)
+			if err != nil {
+				return err
+			}
 
-	target := shared.VarPath("backups", "custom", pool.Name(), project.StorageVolume(projectName, backupRow.Name))
+			revert.Add(func() { _ = os.Remove(backupsPath) })
+		}
 
-	// Setup the tarball writer.
-	l.Debug("Opening backup tarball for writing", logger.Ctx{"path": target})
-	tarFileWriter, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY, 0600)
-	if err != nil {
-		return fmt.Errorf("Error opening backup tarball for writing %q: %w", target, err)
-	}
+		target := shared.VarPath("backups", "custom", pool.Name(), project.StorageVolume(projectName, backupRow.Name))
 
-	defer func() { _ = tarFileWriter.Close() }()
-	revert.Add(func() { _ = os.Remove(target) })
+		// Setup the tarball writer.
+		l.Debug("Opening backup tarball for writing", logger.Ctx{"path": target})
+		tarFile, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return fmt.Errorf("Error opening backup tarball for writing %q: %w", target, err)
+		}
+
+		defer func() { _ = tarFile.Close() }()
+		revert.Add(func() {
+			_ = os.Remove(target)
+			_ = os.Remove(backup.ChecksumPath(target))
+		})
+
+		tarFileWriter = backup.NewChecksumWriter(tarFile)
+	}
 
 	// Create the tarball.
 	tarPipeReader, tarPipeWriter := io.Pipe()
@@ -487,7 +694,7 @@ func volumeBackupCreate(s *state.State, args db.StoragePoolVolumeBackup, project
 
 	// Write index file.
 	l.Debug("Adding backup index file")
-	err = volumeBackupWriteIndex(s, projectName, volumeName, pool, backupRow.OptimizedStorage, !backupRow.VolumeOnly, tarWriter)
+	err = volumeBackupWriteIndex(s, projectName, volumeName, pool, backupRow.OptimizedStorage, !backupRow.VolumeOnly, backupRow.IncrementalFrom, incrementalBase, tarWriter)
 
 	// Check compression errors.
 	if compressErr != nil {
@@ -499,7 +706,12 @@ func volumeBackupCreate(s *state.State, args db.StoragePoolVolumeBackup, project
 		return fmt.Errorf("Error writing backup index file: %w", err)
 	}
 
-	err = pool.BackupCustomVolume(projectName, volumeName, tarWriter, backupRow.OptimizedStorage, !backupRow.VolumeOnly, nil)
+	var incrementalFrom string
+	if incrementalBase != nil {
+		incrementalFrom = incrementalBase.Name
+	}
+
+	err = pool.BackupCustomVolume(projectName, volumeName, tarWriter, backupRow.OptimizedStorage, !backupRow.VolumeOnly, incrementalFrom, nil)
 	if err != nil {
 		return fmt.Errorf("Backup create: %w", err)
 	}
@@ -527,11 +739,23 @@ func volumeBackupCreate(s *state.State, args db.StoragePoolVolumeBackup, project
 	}
 
 	revert.Success()
+
+	if target == nil {
+		var requestor *api.EventLifecycleRequestor
+		if op != nil {
+			requestor = op.Requestor()
+		}
+
+		s.Events.SendLifecycle(projectName, lifecycle.StorageVolumeBackupCreated.Event(poolName, dbCluster.StoragePoolVolumeTypeNameCustom, args.Name, projectName, requestor, logger.Ctx{"type": dbCluster.StoragePoolVolumeTypeNameCustom}))
+	}
+
 	return nil
 }
 
 // volumeBackupWriteIndex generates an index.yaml file and then writes it to the root of the backup tarball.
-func volumeBackupWriteIndex(s *state.State, projectName string, volumeName string, pool storagePools.Pool, optimized bool, snapshots bool, tarWriter *instancewriter.InstanceTarWriter) error {
+// If incrementalBase is set, the index describes an incremental backup of the changes since that snapshot of the
+// incrementalParent backup.
+func volumeBackupWriteIndex(s *state.State, projectName string, volumeName string, pool storagePools.Pool, optimized bool, snapshots bool, incrementalParent string, incrementalBase *api.StorageVolumeSnapshot, tarWriter *instancewriter.InstanceTarWriter) error {
 	// Indicate whether the driver will include a driver-specific optimized header.
 	poolDriverOptimizedHeader := false
 	if optimized {
@@ -553,6 +777,13 @@ func volumeBackupWriteIndex(s *state.State, projectName string, volumeName strin
 		Config:           config,
 	}
 
+	if incrementalBase != nil {
+		err = backupIndexIncremental(&indexInfo, snapshots, incrementalParent, incrementalBase)
+		if err != nil {
+			return err
+		}
+	}
+
 	if snapshots {
 		indexInfo.Snapshots = make([]string, 0, len(config.VolumeSnapshots))
 		for _, s := range config.VolumeSnapshots {
@@ -608,7 +839,7 @@ func pruneExpiredStorageVolumeBackups(ctx context.Context, s *state.State) error
 				continue
 			}
 
-			volBackup := backup.NewVolumeBackup(s, vol.ProjectName, vol.PoolName, vol.Name, b.ID, b.Name, b.CreationDate, b.ExpiryDate, b.VolumeOnly, b.OptimizedStorage)
+			volBackup := backup.NewVolumeBackup(s, vol.ProjectName, vol.PoolName, vol.Name, b.ID, b.Name, b.CreationDate, b.ExpiryDate, b.VolumeOnly, b.OptimizedStorage, b.IncrementalFrom)
 
 			volumeBackups = append(volumeBackups, volBackup)
 		}
@@ -18,6 +18,7 @@ type CommonBackup struct {
 	expiryDate           time.Time
 	optimizedStorage     bool
 	compressionAlgorithm string
+	incrementalFrom      string
 }
 
 // Name returns the name of the backup.
@@ -40,3 +41,9 @@ func (b *CommonBackup) SetCompressionAlgorithm(compression string) {
 func (b *CommonBackup) OptimizedStorage() bool {
 	return b.optimizedStorage
 }
+
+// IncrementalFrom returns the name of the parent backup the backup only contains the changes since.
+// An empty string is returned for full backups.
+func (b *CommonBackup) IncrementalFrom() string {
+	return b.incrementalFrom
+}
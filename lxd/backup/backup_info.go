@@ -3,6 +3,7 @@ package backup
 import (
 	"fmt"
 	"io"
+	"time"
 
 	"gopkg.in/yaml.v2"
 
@@ -51,6 +52,10 @@ type Info struct {
 	OptimizedHeader  *bool          `json:"optimized_header,omitempty" yaml:"optimized_header,omitempty"` // Optional field to handle older optimized backups that don't have this field.
 	Type             Type           `json:"type,omitempty" yaml:"type,omitempty"`                         // Type of backup.
 	Config           *config.Config `json:"config,omitempty" yaml:"config,omitempty"`                     // Equivalent of backup.yaml but embedded in index for quick retrieval.
+	IncrementalFrom  string         `json:"incremental_from,omitempty" yaml:"incremental_from,omitempty"` // Snapshot the backup only contains the changes since (empty for full backups).
+
+	IncrementalFromCreatedAt *time.Time `json:"incremental_from_created_at,omitempty" yaml:"incremental_from_created_at,omitempty"` // Creation date of the snapshot the backup only contains the changes since.
+	IncrementalParent        string     `json:"incremental_parent,omitempty" yaml:"incremental_parent,omitempty"`                   // Name of the parent backup the snapshot the backup only contains the changes since was taken from.
 }
 
 // GetInfo extracts backup information from a given ReadSeeker.
@@ -32,7 +32,7 @@ type InstanceBackup struct {
 }
 
 // NewInstanceBackup instantiates a new InstanceBackup struct.
-func NewInstanceBackup(state *state.State, inst Instance, ID int, name string, creationDate time.Time, expiryDate time.Time, instanceOnly bool, optimizedStorage bool) *InstanceBackup {
+func NewInstanceBackup(state *state.State, inst Instance, ID int, name string, creationDate time.Time, expiryDate time.Time, instanceOnly bool, optimizedStorage bool, incrementalFrom string) *InstanceBackup {
 	return &InstanceBackup{
 		CommonBackup: CommonBackup{
 			state:            state,
@@ -41,6 +41,7 @@ func NewInstanceBackup(state *state.State, inst Instance, ID int, name string, c
 			creationDate:     creationDate,
 			expiryDate:       expiryDate,
 			optimizedStorage: optimizedStorage,
+			incrementalFrom:  incrementalFrom,
 		},
 		instance:     inst,
 		instanceOnly: instanceOnly,
@@ -84,6 +85,14 @@ func (b *InstanceBackup) Rename(newName string) error {
 		return err
 	}
 
+	// Rename the checksum file if the backup has one.
+	if shared.PathExists(ChecksumPath(oldBackupPath)) {
+		err = os.Rename(ChecksumPath(oldBackupPath), ChecksumPath(newBackupPath))
+		if err != nil {
+			return err
+		}
+	}
+
 	// Check if we can remove the old parent directory.
 	empty, _ := shared.PathIsEmpty(oldParentBackupsPath)
 	if empty {
@@ -119,6 +128,12 @@ func (b *InstanceBackup) Delete() error {
 		}
 	}
 
+	// Delete the checksum file.
+	err := os.Remove(ChecksumPath(backupPath))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
 	// Check if we can remove the instance directory.
 	backupsPath := shared.VarPath("backups", "instances", project.Instance(b.instance.Project().Name, b.instance.Name()))
 	empty, _ := shared.PathIsEmpty(backupsPath)
@@ -130,7 +145,7 @@ func (b *InstanceBackup) Delete() error {
 	}
 
 	// Remove the database record.
-	err := b.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+	err = b.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 		return tx.DeleteInstanceBackup(ctx, b.name)
 	})
 	if err != nil {
@@ -151,5 +166,6 @@ func (b *InstanceBackup) Render() *api.InstanceBackup {
 		InstanceOnly:     b.instanceOnly,
 		ContainerOnly:    b.instanceOnly,
 		OptimizedStorage: b.optimizedStorage,
+		IncrementalFrom:  b.incrementalFrom,
 	}
 }
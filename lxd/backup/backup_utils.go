@@ -3,8 +3,13 @@ package backup
 import (
 	"archive/tar"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"hash"
 	"io"
+	"os"
+	"strings"
 
 	"github.com/canonical/lxd/lxd/archive"
 	"github.com/canonical/lxd/lxd/sys"
@@ -34,3 +39,69 @@ func TarReader(r io.ReadSeeker, sysOS *sys.OS, outputPath string) (*tar.Reader,
 
 	return tr, cancelFunc, nil
 }
+
+// ChecksumPath returns the path of the file holding the SHA256 checksum of the backup tarball at path.
+func ChecksumPath(path string) string {
+	return path + ".sha256"
+}
+
+// ChecksumWriter writes to a backup tarball and records its SHA256 checksum next to it when closed.
+type ChecksumWriter struct {
+	file   *os.File
+	hasher hash.Hash
+}
+
+// NewChecksumWriter returns a ChecksumWriter for the given backup tarball.
+func NewChecksumWriter(file *os.File) *ChecksumWriter {
+	return &ChecksumWriter{file: file, hasher: sha256.New()}
+}
+
+// Write writes to the backup tarball.
+func (w *ChecksumWriter) Write(p []byte) (int, error) {
+	n, err := w.file.Write(p)
+	_, _ = w.hasher.Write(p[:n])
+
+	return n, err
+}
+
+// Close closes the backup tarball and writes its checksum file.
+func (w *ChecksumWriter) Close() error {
+	err := w.file.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(ChecksumPath(w.file.Name()), []byte(hex.EncodeToString(w.hasher.Sum(nil))+"\n"), 0600)
+}
+
+// VerifyChecksum compares the backup tarball at path against its recorded checksum.
+// Backups without a checksum file are skipped.
+func VerifyChecksum(path string) error {
+	expected, err := os.ReadFile(ChecksumPath(path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	hasher := sha256.New()
+	_, err = io.Copy(hasher, f)
+	if err != nil {
+		return err
+	}
+
+	if hex.EncodeToString(hasher.Sum(nil)) != strings.TrimSpace(string(expected)) {
+		return fmt.Errorf("Checksum mismatch")
+	}
+
+	return nil
+}
@@ -25,7 +25,7 @@ type VolumeBackup struct {
 }
 
 // NewVolumeBackup instantiates a new VolumeBackup struct.
-func NewVolumeBackup(state *state.State, projectName, poolName, volumeName string, ID int, name string, creationDate, expiryDate time.Time, volumeOnly, optimizedStorage bool) *VolumeBackup {
+func NewVolumeBackup(state *state.State, projectName, poolName, volumeName string, ID int, name string, creationDate, expiryDate time.Time, volumeOnly, optimizedStorage bool, incrementalFrom string) *VolumeBackup {
 	return &VolumeBackup{
 		CommonBackup: CommonBackup{
 			state:            state,
@@ -34,6 +34,7 @@ func NewVolumeBackup(state *state.State, projectName, poolName, volumeName strin
 			creationDate:     creationDate,
 			expiryDate:       expiryDate,
 			optimizedStorage: optimizedStorage,
+			incrementalFrom:  incrementalFrom,
 		},
 		projectName: projectName,
 		poolName:    poolName,
@@ -84,6 +85,16 @@ func (b *VolumeBackup) Rename(newName string) error {
 
 	revert.Add(func() { _ = os.Rename(newBackupPath, oldBackupPath) })
 
+	// Rename the checksum file if the backup has one.
+	if shared.PathExists(ChecksumPath(oldBackupPath)) {
+		err = os.Rename(ChecksumPath(oldBackupPath), ChecksumPath(newBackupPath))
+		if err != nil {
+			return err
+		}
+
+		revert.Add(func() { _ = os.Rename(ChecksumPath(newBackupPath), ChecksumPath(oldBackupPath)) })
+	}
+
 	// Check if we can remove the old parent directory.
 	empty, _ := shared.PathIsEmpty(oldParentBackupsPath)
 	if empty {
@@ -116,6 +127,12 @@ func (b *VolumeBackup) Delete() error {
 		}
 	}
 
+	// Delete the checksum file.
+	err := os.Remove(ChecksumPath(backupPath))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
 	// Check if we can remove the volume directory.
 	backupsPath := shared.VarPath("backups", "custom", b.poolName, project.StorageVolume(b.projectName, b.volumeName))
 	empty, _ := shared.PathIsEmpty(backupsPath)
@@ -127,7 +144,7 @@ func (b *VolumeBackup) Delete() error {
 	}
 
 	// Remove the database record.
-	err := b.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+	err = b.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 		return tx.DeleteStoragePoolVolumeBackup(ctx, b.name)
 	})
 	if err != nil {
@@ -145,5 +162,6 @@ func (b *VolumeBackup) Render() *api.StoragePoolVolumeBackup {
 		ExpiresAt:        b.expiryDate,
 		VolumeOnly:       b.volumeOnly,
 		OptimizedStorage: b.optimizedStorage,
+		IncrementalFrom:  b.incrementalFrom,
 	}
 }
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Types of locations scheduled backups can be written to.
+const (
+	TargetTypeVolume = "volume"
+	TargetTypeBucket = "bucket"
+)
+
+// Target represents the location scheduled backups are written to, as set in the backups.target key.
+type Target struct {
+	Type string
+	Pool string
+	Name string
+}
+
+// ParseTarget parses a backups.target value of the form "<type>:<pool>/<name>".
+// Returns nil if the value is empty, meaning that backups are kept in the backups directory of the server.
+func ParseTarget(value string) (*Target, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	targetType, location, found := strings.Cut(value, ":")
+	if !found {
+		return nil, fmt.Errorf("Backup target must be of the form <type>:<pool>/<name>")
+	}
+
+	if targetType != TargetTypeVolume && targetType != TargetTypeBucket {
+		return nil, fmt.Errorf("Invalid backup target type %q", targetType)
+	}
+
+	poolName, name, found := strings.Cut(location, "/")
+	if !found || poolName == "" || name == "" || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("Backup target must be of the form <type>:<pool>/<name>")
+	}
+
+	return &Target{Type: targetType, Pool: poolName, Name: name}, nil
+}
+
+// ValidateTarget validates a backups.target value.
+func ValidateTarget(value string) error {
+	_, err := ParseTarget(value)
+	return err
+}
+
+// String returns the backups.target representation of the target.
+func (t Target) String() string {
+	return fmt.Sprintf("%s:%s/%s", t.Type, t.Pool, t.Name)
+}
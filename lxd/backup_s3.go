@@ -0,0 +1,330 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// backupS3PartSize is the size of the parts backups are uploaded in. As the size of a backup isn't known in
+// advance, this also limits the size of backups to 10000 parts.
+const backupS3PartSize = 64 * 1024 * 1024
+
+// backupS3Parse returns the endpoint, bucket and object names of an S3 backup URL.
+func backupS3Parse(location *api.BackupS3) (*url.URL, string, string, error) {
+	u, err := url.Parse(location.URL)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("Invalid S3 URL %q: %w", location.URL, err)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, "", "", fmt.Errorf("Invalid S3 URL %q: Scheme must be http or https", location.URL)
+	}
+
+	bucketName, objectName, _ := strings.Cut(strings.TrimPrefix(u.Path, "/"), "/")
+	if u.Host == "" || bucketName == "" || objectName == "" || strings.HasSuffix(objectName, "/") {
+		return nil, "", "", fmt.Errorf("Invalid S3 URL %q: Must be of the form <scheme>://<host>/<bucket>/<object>", location.URL)
+	}
+
+	return u, bucketName, objectName, nil
+}
+
+// backupS3CheckAddress checks whether the server may connect to an S3 endpoint at the given address.
+// Loopback, link-local, multicast and unspecified addresses as well as the addresses of this server are rejected,
+// so that S3 URLs can't be used to reach services that are otherwise only accessible from the server itself.
+// Private addresses are rejected unless they are within one of the allowed subnets.
+func backupS3CheckAddress(ip net.IP, allowedSubnets []*net.IPNet) error {
+	if ip == nil {
+		return fmt.Errorf("Invalid S3 endpoint address")
+	}
+
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return api.StatusErrorf(http.StatusForbidden, "S3 endpoint address %q isn't allowed", ip.String())
+	}
+
+	if ip.IsPrivate() && !backupS3SubnetsContain(allowedSubnets, ip) {
+		return api.StatusErrorf(http.StatusForbidden, "S3 endpoint address %q is a private address that isn't within %q", ip.String(), "backups.s3.allowed_subnets")
+	}
+
+	isServerAddress, err := backupS3IsServerAddress(ip)
+	if err != nil {
+		return err
+	}
+
+	if isServerAddress {
+		return api.StatusErrorf(http.StatusForbidden, "S3 endpoint address %q is an address of the server", ip.String())
+	}
+
+	return nil
+}
+
+// backupS3SubnetsContain returns whether the given address is within one of the subnets.
+func backupS3SubnetsContain(subnets []*net.IPNet, ip net.IP) bool {
+	for _, subnet := range subnets {
+		if subnet.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// backupS3AllowedSubnets returns the private subnets that S3 endpoints may be reached on.
+func backupS3AllowedSubnets(s *state.State) ([]*net.IPNet, error) {
+	var subnets []*net.IPNet
+
+	for _, value := range shared.SplitNTrimSpace(s.GlobalConfig.BackupsS3AllowedSubnets(), ",", -1, true) {
+		_, subnet, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid subnet %q in %q: %w", value, "backups.s3.allowed_subnets", err)
+		}
+
+		subnets = append(subnets, subnet)
+	}
+
+	return subnets, nil
+}
+
+// backupS3IsServerAddress returns whether the given address is an address of this server.
+func backupS3IsServerAddress(ip net.IP) (bool, error) {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return false, fmt.Errorf("Failed getting the addresses of the server: %w", err)
+	}
+
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if ok && ipNet.IP.Equal(ip) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// backupS3IsServerHost returns whether all the addresses the given host resolves to are addresses of this server.
+func backupS3IsServerHost(host string) (bool, error) {
+	ips, err := net.LookupIP(host)
+	if err != nil || len(ips) == 0 {
+		return false, nil
+	}
+
+	for _, ip := range ips {
+		isServerAddress, err := backupS3IsServerAddress(ip)
+		if err != nil {
+			return false, err
+		}
+
+		if !isServerAddress {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// backupS3Validate validates an S3 backup location.
+func backupS3Validate(location *api.BackupS3) error {
+	_, _, _, err := backupS3Parse(location)
+	if err != nil {
+		return err
+	}
+
+	if location.AccessKey == "" || location.SecretKey == "" {
+		return fmt.Errorf("S3 access key and secret key are required")
+	}
+
+	return nil
+}
+
+// backupS3Client returns a client for the given S3 backup location along with the bucket and object names.
+func backupS3Client(s *state.State, location *api.BackupS3) (*minio.Client, string, string, error) {
+	u, bucketName, objectName, err := backupS3Parse(location)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	// The storage buckets endpoint of this server (which serves the local MinIO buckets) uses the server
+	// certificate, so trust it when connecting to it. The endpoint is only considered when the host resolves
+	// to addresses of this server, as a wildcard listen address covers any host.
+	isStorageBucketsEndpoint := false
+	storageBucketsAddress := s.Endpoints.StorageBucketsAddress()
+	if u.Scheme == "https" && storageBucketsAddress != "" && util.IsAddressCovered(util.CanonicalNetworkAddress(u.Host, shared.HTTPSStorageBucketsDefaultPort), storageBucketsAddress) {
+		isStorageBucketsEndpoint, err = backupS3IsServerHost(u.Hostname())
+		if err != nil {
+			return nil, "", "", err
+		}
+	}
+
+	var transport *http.Transport
+	var checkAddress func(ip net.IP) error
+
+	if isStorageBucketsEndpoint {
+		tlsConfig, err := shared.GetTLSConfigMem("", "", "", string(s.ServerCert().PublicKey()), false)
+		if err != nil {
+			return nil, "", "", err
+		}
+
+		transport = &http.Transport{TLSClientConfig: tlsConfig}
+
+		// Only ever connect to this server, even if the host name resolves to other addresses by then.
+		checkAddress = func(ip net.IP) error {
+			isServerAddress, err := backupS3IsServerAddress(ip)
+			if err != nil {
+				return err
+			}
+
+			if !isServerAddress {
+				return api.StatusErrorf(http.StatusForbidden, "S3 endpoint address %q isn't an address of the server", ip.String())
+			}
+
+			return nil
+		}
+	} else {
+		transport, err = minio.DefaultTransport(u.Scheme == "https")
+		if err != nil {
+			return nil, "", "", err
+		}
+
+		allowedSubnets, err := backupS3AllowedSubnets(s)
+		if err != nil {
+			return nil, "", "", err
+		}
+
+		// Any other endpoint is only connected to if its address is allowed.
+		checkAddress = func(ip net.IP) error {
+			return backupS3CheckAddress(ip, allowedSubnets)
+		}
+	}
+
+	// The check is done when connecting, so that it also applies to the addresses the host name resolves to
+	// at that time and to the targets of redirects.
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(network string, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			return checkAddress(net.ParseIP(host))
+		},
+	}
+
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	client, err := minio.New(u.Host, &minio.Options{
+		Creds:        credentials.NewStaticV4(location.AccessKey, location.SecretKey, ""),
+		Secure:       u.Scheme == "https",
+		Transport:    transport,
+		BucketLookup: minio.BucketLookupPath,
+	})
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	return client, bucketName, objectName, nil
+}
+
+// backupS3Writer uploads the data written to it to an S3 object.
+// The object is only created once Close is called, calling CloseWithError aborts the upload.
+type backupS3Writer struct {
+	pipeWriter *io.PipeWriter
+	done       chan error
+
+	closeOnce sync.Once
+	err       error
+}
+
+// backupS3Upload starts uploading to the given S3 backup location.
+func backupS3Upload(s *state.State, location *api.BackupS3) (*backupS3Writer, error) {
+	client, bucketName, objectName, err := backupS3Client(s, location)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeReader, pipeWriter := io.Pipe()
+	w := &backupS3Writer{
+		pipeWriter: pipeWriter,
+		done:       make(chan error, 1),
+	}
+
+	go func() {
+		_, err := client.PutObject(s.ShutdownCtx, bucketName, objectName, pipeReader, -1, minio.PutObjectOptions{
+			ContentType: "application/octet-stream",
+			PartSize:    backupS3PartSize,
+		})
+		if err != nil {
+			err = fmt.Errorf("Failed uploading backup to %q: %w", location.URL, err)
+		}
+
+		// Unblock any pending writes if the upload failed.
+		_ = pipeReader.CloseWithError(err)
+		w.done <- err
+	}()
+
+	return w, nil
+}
+
+// Write writes to the S3 object.
+func (w *backupS3Writer) Write(p []byte) (int, error) {
+	return w.pipeWriter.Write(p)
+}
+
+// Close completes the upload and returns its result.
+func (w *backupS3Writer) Close() error {
+	return w.CloseWithError(nil)
+}
+
+// CloseWithError aborts the upload with the given error, or completes it if err is nil.
+func (w *backupS3Writer) CloseWithError(err error) error {
+	w.closeOnce.Do(func() {
+		_ = w.pipeWriter.CloseWithError(err)
+		w.err = <-w.done
+	})
+
+	return w.err
+}
+
+// backupS3Open opens the backup at the given S3 backup location for reading.
+func backupS3Open(s *state.State, location *api.BackupS3) (*minio.Object, error) {
+	client, bucketName, objectName, err := backupS3Client(s, location)
+	if err != nil {
+		return nil, err
+	}
+
+	object, err := client.GetObject(s.ShutdownCtx, bucketName, objectName, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	// Check that the object exists and can be accessed.
+	_, err = object.Stat()
+	if err != nil {
+		_ = object.Close()
+
+		if minio.ToErrorResponse(err).StatusCode == http.StatusNotFound {
+			return nil, api.StatusErrorf(http.StatusNotFound, "Backup %q not found", location.URL)
+		}
+
+		return nil, fmt.Errorf("Failed accessing backup %q: %w", location.URL, err)
+	}
+
+	return object, nil
+}
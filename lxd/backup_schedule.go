@@ -0,0 +1,732 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+
+	"github.com/canonical/lxd/lxd/backup"
+	backupConfig "github.com/canonical/lxd/lxd/backup/config"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/db/warningtype"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/project/limits"
+	"github.com/canonical/lxd/lxd/state"
+	storagePools "github.com/canonical/lxd/lxd/storage"
+	storageDrivers "github.com/canonical/lxd/lxd/storage/drivers"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/lxd/warnings"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// scheduledBackupName returns the name of a scheduled backup created at the given time.
+func scheduledBackupName(t time.Time) string {
+	return "scheduled-" + t.UTC().Format("20060102-150405")
+}
+
+// backupTargetObject is a backup file stored in a backup target.
+type backupTargetObject struct {
+	name    string
+	modTime time.Time
+}
+
+// backupTargetStore is a location outside of the backups directory that scheduled backups are written to.
+type backupTargetStore interface {
+	// put writes the backup file name with the given size from r.
+	put(name string, r io.Reader, size int64) error
+
+	// list returns the backup files under the given prefix.
+	list(prefix string) ([]backupTargetObject, error)
+
+	// remove deletes the backup file name.
+	remove(name string) error
+
+	// close releases any resources held by the store.
+	close()
+}
+
+// backupTargetVolume stores backups in a mounted custom filesystem volume.
+type backupTargetVolume struct {
+	path    string
+	unmount func()
+}
+
+func (t *backupTargetVolume) put(name string, r io.Reader, size int64) error {
+	target := filepath.Join(t.path, name)
+
+	err := os.MkdirAll(filepath.Dir(target), 0700)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so that partial backups are never picked up.
+	f, err := os.CreateTemp(filepath.Dir(target), ".partial-")
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = os.Remove(f.Name()) }()
+
+	_, err = io.Copy(f, r)
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), target)
+}
+
+func (t *backupTargetVolume) list(prefix string) ([]backupTargetObject, error) {
+	entries, err := os.ReadDir(filepath.Join(t.path, prefix))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	objects := make([]backupTargetObject, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || strings.HasPrefix(entry.Name(), ".partial-") {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+
+		objects = append(objects, backupTargetObject{name: path.Join(prefix, entry.Name()), modTime: info.ModTime()})
+	}
+
+	return objects, nil
+}
+
+func (t *backupTargetVolume) remove(name string) error {
+	return os.Remove(filepath.Join(t.path, name))
+}
+
+func (t *backupTargetVolume) close() {
+	t.unmount()
+}
+
+// backupTargetBucket stores backups in an S3 bucket.
+type backupTargetBucket struct {
+	ctx    context.Context
+	client *minio.Client
+	bucket string
+}
+
+func (t *backupTargetBucket) put(name string, r io.Reader, size int64) error {
+	_, err := t.client.PutObject(t.ctx, t.bucket, name, r, size, minio.PutObjectOptions{ContentType: "application/octet-stream"})
+	return err
+}
+
+func (t *backupTargetBucket) list(prefix string) ([]backupTargetObject, error) {
+	var objects []backupTargetObject
+
+	for object := range t.client.ListObjects(t.ctx, t.bucket, minio.ListObjectsOptions{Prefix: prefix + "/", Recursive: true}) {
+		if object.Err != nil {
+			return nil, object.Err
+		}
+
+		objects = append(objects, backupTargetObject{name: object.Key, modTime: object.LastModified})
+	}
+
+	return objects, nil
+}
+
+func (t *backupTargetBucket) remove(name string) error {
+	return t.client.RemoveObject(t.ctx, t.bucket, name, minio.RemoveObjectOptions{})
+}
+
+func (t *backupTargetBucket) close() {}
+
+// backupTargetLoad returns the store for the given backups.target value, as seen from the given project.
+func backupTargetLoad(s *state.State, projectName string, target *backupConfig.Target) (backupTargetStore, error) {
+	pool, err := storagePools.LoadByName(s, target.Pool)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading storage pool %q: %w", target.Pool, err)
+	}
+
+	switch target.Type {
+	case backupConfig.TargetTypeVolume:
+		volProjectName, err := project.StorageVolumeProject(s.DB.Cluster, projectName, dbCluster.StoragePoolVolumeTypeCustom)
+		if err != nil {
+			return nil, err
+		}
+
+		dbVol, err := storagePools.VolumeDBGet(pool, volProjectName, target.Name, storageDrivers.VolumeTypeCustom)
+		if err != nil {
+			return nil, fmt.Errorf("Failed loading storage volume %q: %w", target, err)
+		}
+
+		if dbVol.ContentType != dbCluster.StoragePoolVolumeContentTypeNameFS {
+			return nil, fmt.Errorf("Storage volume %q is not filesystem content type", target)
+		}
+
+		_, err = pool.MountCustomVolume(volProjectName, target.Name, nil)
+		if err != nil {
+			return nil, fmt.Errorf("Failed mounting storage volume %q: %w", target, err)
+		}
+
+		volStorageName := project.StorageVolume(volProjectName, target.Name)
+
+		return &backupTargetVolume{
+			path:    storageDrivers.GetVolumeMountPath(pool.Name(), storageDrivers.VolumeTypeCustom, volStorageName),
+			unmount: func() { _, _ = pool.UnmountCustomVolume(volProjectName, target.Name, nil) },
+		}, nil
+
+	case backupConfig.TargetTypeBucket:
+		bucketProjectName, err := project.StorageBucketProject(s.ShutdownCtx, s.DB.Cluster, projectName)
+		if err != nil {
+			return nil, err
+		}
+
+		// Local buckets are served by a MinIO process that can be accessed directly.
+		if !pool.Driver().Info().Remote {
+			minioProc, err := pool.ActivateBucket(bucketProjectName, target.Name, nil)
+			if err != nil {
+				return nil, fmt.Errorf("Failed activating storage bucket %q: %w", target, err)
+			}
+
+			client, err := minioProc.S3Client()
+			if err != nil {
+				return nil, err
+			}
+
+			return &backupTargetBucket{ctx: s.ShutdownCtx, client: client, bucket: target.Name}, nil
+		}
+
+		// Remote buckets are accessed through their S3 URL using the admin key of the bucket.
+		var creds *api.StorageBucketKey
+		err = s.DB.Cluster.Transaction(s.ShutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
+			bucket, err := tx.GetStoragePoolBucket(ctx, pool.ID(), bucketProjectName, false, target.Name)
+			if err != nil {
+				return err
+			}
+
+			keys, err := tx.GetStoragePoolBucketKeys(ctx, bucket.ID)
+			if err != nil {
+				return err
+			}
+
+			for _, key := range keys {
+				if key.Role == "admin" {
+					creds = &key.StorageBucketKey
+					break
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("Failed loading storage bucket %q: %w", target, err)
+		}
+
+		if creds == nil {
+			return nil, fmt.Errorf("Storage bucket %q has no admin key", target)
+		}
+
+		u := pool.GetBucketURL(target.Name)
+		if u == nil {
+			return nil, fmt.Errorf("Failed getting URL of storage bucket %q", target)
+		}
+
+		var transport http.RoundTripper
+		certFilePath := pool.Driver().Config()["cephobject.radosgw.endpoint_cert_file"]
+		if u.Scheme == "https" && certFilePath != "" {
+			certs, err := os.ReadFile(shared.HostPath(certFilePath))
+			if err != nil {
+				return nil, fmt.Errorf("Failed reading %q: %w", certFilePath, err)
+			}
+
+			rootCAs := x509.NewCertPool()
+			if !rootCAs.AppendCertsFromPEM(certs) {
+				return nil, fmt.Errorf("Failed adding S3 client certificates")
+			}
+
+			transport = &http.Transport{TLSClientConfig: &tls.Config{RootCAs: rootCAs}}
+		}
+
+		bucketName, err := url.PathUnescape(path.Base(u.Path))
+		if err != nil {
+			return nil, err
+		}
+
+		client, err := minio.New(path.Join(u.Host, path.Dir(u.Path)), &minio.Options{
+			Creds:     credentials.NewStaticV4(creds.AccessKey, creds.SecretKey, ""),
+			Secure:    u.Scheme == "https",
+			Transport: transport,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return &backupTargetBucket{ctx: s.ShutdownCtx, client: client, bucket: bucketName}, nil
+	}
+
+	return nil, fmt.Errorf("Unsupported backup target type %q", target.Type)
+}
+
+// backupTargetUpload copies the backup file at srcPath to the backups.target location as prefix/name and then
+// deletes the backup files under prefix that are past their expiry.
+func backupTargetUpload(s *state.State, projectName string, targetValue string, expiry string, prefix string, name string, srcPath string) error {
+	target, err := backupConfig.ParseTarget(targetValue)
+	if err != nil {
+		return err
+	}
+
+	store, err := backupTargetLoad(s, projectName, target)
+	if err != nil {
+		return err
+	}
+
+	defer store.close()
+
+	f, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	err = store.put(path.Join(prefix, name+".backup"), f, fi.Size())
+	if err != nil {
+		return fmt.Errorf("Failed writing backup to %q: %w", target, err)
+	}
+
+	if expiry == "" {
+		return nil
+	}
+
+	// Remove expired backups.
+	objects, err := store.list(prefix)
+	if err != nil {
+		return fmt.Errorf("Failed listing backups in %q: %w", target, err)
+	}
+
+	now := time.Now()
+	for _, object := range objects {
+		expiryDate, err := shared.GetExpiry(object.modTime, expiry)
+		if err != nil {
+			return err
+		}
+
+		if expiryDate.After(now) {
+			continue
+		}
+
+		err = store.remove(object.name)
+		if err != nil {
+			return fmt.Errorf("Failed removing expired backup %q from %q: %w", object.name, target, err)
+		}
+	}
+
+	return nil
+}
+
+// autoCreateInstanceBackup creates a scheduled backup of an instance and writes it to its backups.target.
+func autoCreateInstanceBackup(s *state.State, inst instance.Instance, now time.Time, op *operations.Operation) error {
+	config := inst.ExpandedConfig()
+	backupName := scheduledBackupName(now)
+
+	args := db.InstanceBackup{
+		Name:         inst.Name() + shared.SnapshotDelimiter + backupName,
+		InstanceID:   inst.ID(),
+		CreationDate: now,
+	}
+
+	// Backups kept in the backups directory expire like any other backup.
+	// Backups written to a target are pruned from the target directly.
+	if config["backups.target"] == "" {
+		expiry, err := shared.GetExpiry(now, config["backups.expiry"])
+		if err != nil {
+			return err
+		}
+
+		args.ExpiryDate = expiry
+	}
+
+	err := backupCreate(s, args, inst, nil, op)
+	if err != nil {
+		return err
+	}
+
+	if config["backups.target"] == "" {
+		return nil
+	}
+
+	b, err := instance.BackupLoadByName(s, inst.Project().Name, args.Name)
+	if err != nil {
+		return err
+	}
+
+	// The local copy is only needed until it has been written to the target.
+	defer func() { _ = b.Delete() }()
+
+	backupPath := shared.VarPath("backups", "instances", project.Instance(inst.Project().Name, args.Name))
+	prefix := path.Join("instances", project.Instance(inst.Project().Name, inst.Name()))
+
+	return backupTargetUpload(s, inst.Project().Name, config["backups.target"], config["backups.expiry"], prefix, backupName, backupPath)
+}
+
+// autoCreateCustomVolumeBackup creates a scheduled backup of a custom volume and writes it to its backups.target.
+func autoCreateCustomVolumeBackup(s *state.State, v db.StorageVolumeArgs, now time.Time, op *operations.Operation) error {
+	backupName := scheduledBackupName(now)
+
+	args := db.StoragePoolVolumeBackup{
+		Name:         v.Name + shared.SnapshotDelimiter + backupName,
+		VolumeID:     v.ID,
+		CreationDate: now,
+	}
+
+	// Backups kept in the backups directory expire like any other backup.
+	// Backups written to a target are pruned from the target directly.
+	if v.Config["backups.target"] == "" {
+		expiry, err := shared.GetExpiry(now, v.Config["backups.expiry"])
+		if err != nil {
+			return err
+		}
+
+		args.ExpiryDate = expiry
+	}
+
+	err := volumeBackupCreate(s, args, v.ProjectName, v.PoolName, v.Name, nil, op)
+	if err != nil {
+		return err
+	}
+
+	if v.Config["backups.target"] == "" {
+		return nil
+	}
+
+	var backupRow db.StoragePoolVolumeBackup
+	err = s.DB.Cluster.Transaction(s.ShutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
+		backupRow, err = tx.GetStoragePoolVolumeBackup(ctx, v.ProjectName, v.PoolName, args.Name)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// The local copy is only needed until it has been written to the target.
+	b := backup.NewVolumeBackup(s, v.ProjectName, v.PoolName, v.Name, backupRow.ID, backupRow.Name, backupRow.CreationDate, backupRow.ExpiryDate, backupRow.VolumeOnly, backupRow.OptimizedStorage, backupRow.IncrementalFrom)
+	defer func() { _ = b.Delete() }()
+
+	backupPath := shared.VarPath("backups", "custom", v.PoolName, project.StorageVolume(v.ProjectName, args.Name))
+	prefix := path.Join("custom", v.PoolName, project.StorageVolume(v.ProjectName, v.Name))
+
+	return backupTargetUpload(s, v.ProjectName, v.Config["backups.target"], v.Config["backups.expiry"], prefix, backupName, backupPath)
+}
+
+// autoCreateInstanceBackups creates the scheduled backups of the given instances.
+// Failures are reported through a lifecycle event and a warning rather than stopping the other backups.
+func autoCreateInstanceBackups(ctx context.Context, s *state.State, instances []instance.Instance, op *operations.Operation) error {
+	for _, inst := range instances {
+		err := ctx.Err()
+		if err != nil {
+			return err
+		}
+
+		l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+
+		now := time.Now()
+		err = autoCreateInstanceBackup(s, inst, now, op)
+		if err != nil {
+			l.Error("Failed creating scheduled instance backup", logger.Ctx{"err": err})
+
+			s.Events.SendLifecycle(inst.Project().Name, lifecycle.InstanceBackupFailed.Event(inst.Name()+shared.SnapshotDelimiter+scheduledBackupName(now), inst, map[string]any{"error": err.Error()}))
+
+			warnErr := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+				return tx.UpsertWarningLocalNode(ctx, inst.Project().Name, entity.TypeInstance, inst.ID(), warningtype.ScheduledBackupFailure, err.Error())
+			})
+			if warnErr != nil {
+				l.Warn("Failed to create scheduled backup failure warning", logger.Ctx{"err": warnErr})
+			}
+
+			continue
+		}
+
+		// Resolve any previous warning.
+		warnErr := warnings.ResolveWarningsByLocalNodeAndProjectAndTypeAndEntity(s.DB.Cluster, inst.Project().Name, warningtype.ScheduledBackupFailure, entity.TypeInstance, inst.ID())
+		if warnErr != nil {
+			l.Warn("Failed to resolve scheduled backup failure warning", logger.Ctx{"err": warnErr})
+		}
+	}
+
+	return nil
+}
+
+// autoCreateCustomVolumeBackups creates the scheduled backups of the given custom volumes.
+// Failures are reported through a lifecycle event and a warning rather than stopping the other backups.
+func autoCreateCustomVolumeBackups(ctx context.Context, s *state.State, volumes []db.StorageVolumeArgs, op *operations.Operation) error {
+	for _, v := range volumes {
+		err := ctx.Err()
+		if err != nil {
+			return err
+		}
+
+		l := logger.AddContext(logger.Ctx{"project": v.ProjectName, "pool": v.PoolName, "volName": v.Name})
+
+		now := time.Now()
+		err = autoCreateCustomVolumeBackup(s, v, now, op)
+		if err != nil {
+			l.Error("Failed creating scheduled custom volume backup", logger.Ctx{"err": err})
+
+			s.Events.SendLifecycle(v.ProjectName, lifecycle.StorageVolumeBackupFailed.Event(v.PoolName, dbCluster.StoragePoolVolumeTypeNameCustom, v.Name+shared.SnapshotDelimiter+scheduledBackupName(now), v.ProjectName, nil, map[string]any{"error": err.Error()}))
+
+			warnErr := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+				return tx.UpsertWarningLocalNode(ctx, v.ProjectName, entity.TypeStorageVolume, int(v.ID), warningtype.ScheduledBackupFailure, err.Error())
+			})
+			if warnErr != nil {
+				l.Warn("Failed to create scheduled backup failure warning", logger.Ctx{"err": warnErr})
+			}
+
+			continue
+		}
+
+		// Resolve any previous warning.
+		warnErr := warnings.ResolveWarningsByLocalNodeAndProjectAndTypeAndEntity(s.DB.Cluster, v.ProjectName, warningtype.ScheduledBackupFailure, entity.TypeStorageVolume, int(v.ID))
+		if warnErr != nil {
+			l.Warn("Failed to resolve scheduled backup failure warning", logger.Ctx{"err": warnErr})
+		}
+	}
+
+	return nil
+}
+
+func autoCreateScheduledBackupsTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+		var instances []instance.Instance
+		var volumes, remoteVolumes []db.StorageVolumeArgs
+		var memberCount int
+		var onlineMemberIDs []int64
+
+		// Get list of instances on the local member that are due to be backed up.
+		filter := dbCluster.InstanceFilter{Node: &s.ServerName}
+
+		err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.InstanceList(ctx, func(dbInst db.InstanceArgs, p api.Project) error {
+				inst, err := instance.Load(s, dbInst, p)
+				if err != nil {
+					return fmt.Errorf("Failed loading instance %q (project %q) for backup task: %w", dbInst.Name, dbInst.Project, err)
+				}
+
+				// Check if instance has backup schedule enabled.
+				schedule := inst.ExpandedConfig()["backups.schedule"]
+				if schedule == "" {
+					return nil
+				}
+
+				// Check if backup is scheduled.
+				if !snapshotIsScheduledNow(schedule, int64(inst.ID())) {
+					return nil
+				}
+
+				err = limits.AllowBackupCreation(tx, dbInst.Project)
+				if err != nil {
+					return nil
+				}
+
+				logger.Debug("Scheduling auto instance backup", logger.Ctx{"instance": inst.Name(), "project": inst.Project().Name})
+				instances = append(instances, inst)
+
+				return nil
+			}, filter)
+		})
+		if err != nil {
+			logger.Error("Failed getting instance backup schedule info", logger.Ctx{"err": err})
+			return
+		}
+
+		// Get list of custom volumes that are due to be backed up.
+		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			allVolumes, err := tx.GetStoragePoolVolumesWithType(ctx, dbCluster.StoragePoolVolumeTypeCustom, true)
+			if err != nil {
+				return fmt.Errorf("Failed getting volumes for auto custom volume backup task: %w", err)
+			}
+
+			for _, v := range allVolumes {
+				schedule := v.Config["backups.schedule"]
+				if schedule == "" {
+					continue
+				}
+
+				// Check if backup is scheduled.
+				if !snapshotIsScheduledNow(schedule, v.ID) {
+					continue
+				}
+
+				err = limits.AllowBackupCreation(tx, v.ProjectName)
+				if err != nil {
+					continue
+				}
+
+				if v.NodeID < 0 {
+					// Keep a separate list of remote volumes in order to select a member to
+					// perform the backup later.
+					remoteVolumes = append(remoteVolumes, v)
+				} else {
+					logger.Debug("Scheduling local auto custom volume backup", logger.Ctx{"volName": v.Name, "project": v.ProjectName, "pool": v.PoolName})
+					volumes = append(volumes, v) // Always include local volumes.
+				}
+			}
+
+			if len(remoteVolumes) > 0 {
+				// Get list of cluster members.
+				members, err := tx.GetNodes(ctx)
+				if err != nil {
+					return fmt.Errorf("Failed getting cluster members: %w", err)
+				}
+
+				memberCount = len(members)
+
+				// Filter to online members.
+				for _, member := range members {
+					if member.IsOffline(s.GlobalConfig.OfflineThreshold()) {
+						continue
+					}
+
+					onlineMemberIDs = append(onlineMemberIDs, member.ID)
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			logger.Error("Failed getting custom volume backup schedule info", logger.Ctx{"err": err})
+			return
+		}
+
+		if len(remoteVolumes) > 0 {
+			// Skip backing up remote custom volumes if there are no online members, as we can't be
+			// sure that the cluster isn't partitioned and we may end up attempting the backup on
+			// multiple members.
+			if memberCount > 1 && len(onlineMemberIDs) <= 0 {
+				logger.Error("Skipping remote volumes for auto custom volume backup task due to no online members")
+			} else {
+				localMemberID := s.DB.Cluster.GetNodeID()
+
+				for _, v := range remoteVolumes {
+					// If there are multiple cluster members, a stable random member is chosen
+					// to perform the backup from.
+					if memberCount > 1 {
+						selectedNodeID, err := util.GetStableRandomInt64FromList(v.ID, onlineMemberIDs)
+						if err != nil {
+							logger.Error("Failed scheduling remote auto custom volume backup task", logger.Ctx{"volName": v.Name, "project": v.ProjectName, "pool": v.PoolName, "err": err})
+							continue
+						}
+
+						// Don't back up, if we're not the chosen one.
+						if localMemberID != selectedNodeID {
+							continue
+						}
+					}
+
+					logger.Debug("Scheduling remote auto custom volume backup", logger.Ctx{"volName": v.Name, "project": v.ProjectName, "pool": v.PoolName})
+					volumes = append(volumes, v)
+				}
+			}
+		}
+
+		// Handle instance backups.
+		if len(instances) > 0 {
+			opRun := func(op *operations.Operation) error {
+				return autoCreateInstanceBackups(ctx, s, instances, op)
+			}
+
+			op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.BackupCreate, nil, nil, opRun, nil, nil, nil)
+			if err != nil {
+				logger.Error("Failed creating scheduled instance backup operation", logger.Ctx{"err": err})
+			} else {
+				logger.Info("Creating scheduled instance backups")
+
+				err = op.Start()
+				if err != nil {
+					logger.Error("Failed starting scheduled instance backup operation", logger.Ctx{"err": err})
+				} else {
+					err = op.Wait(ctx)
+					if err != nil {
+						logger.Error("Failed scheduled instance backups", logger.Ctx{"err": err})
+					} else {
+						logger.Info("Done creating scheduled instance backups")
+					}
+				}
+			}
+		}
+
+		// Handle custom volume backups.
+		if len(volumes) > 0 {
+			opRun := func(op *operations.Operation) error {
+				return autoCreateCustomVolumeBackups(ctx, s, volumes, op)
+			}
+
+			op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.CustomVolumeBackupCreate, nil, nil, opRun, nil, nil, nil)
+			if err != nil {
+				logger.Error("Failed creating scheduled custom volume backup operation", logger.Ctx{"err": err})
+			} else {
+				logger.Info("Creating scheduled custom volume backups")
+
+				err = op.Start()
+				if err != nil {
+					logger.Error("Failed starting scheduled custom volume backup operation", logger.Ctx{"err": err})
+				} else {
+					err = op.Wait(ctx)
+					if err != nil {
+						logger.Error("Failed scheduled custom volume backups", logger.Ctx{"err": err})
+					} else {
+						logger.Info("Done creating scheduled custom volume backups")
+					}
+				}
+			}
+		}
+	}
+
+	first := true
+	schedule := func() (time.Duration, error) {
+		interval := time.Minute
+
+		if first {
+			first = false
+			return interval, task.ErrSkip
+		}
+
+		return interval, nil
+	}
+
+	return f, schedule
+}
@@ -0,0 +1,267 @@
+package bgp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	bgpAPI "github.com/osrg/gobgp/v3/api"
+)
+
+// PeerPolicy represents the import and export policy of a BGP peer.
+type PeerPolicy struct {
+	// Only accept routes for these prefixes (see ParsePrefix). All prefixes are accepted when empty.
+	ImportPrefixes []string
+
+	// Only accept routes tagged with one of these communities. All routes are accepted when empty.
+	ImportCommunities []string
+
+	// Local preference set on the accepted routes (left untouched when 0).
+	ImportLocalPref uint32
+
+	// Only advertise these prefixes (see ParsePrefix). All prefixes are advertised when empty.
+	ExportPrefixes []string
+
+	// Communities added to the advertised routes.
+	ExportCommunities []string
+
+	// MED set on the advertised routes (left untouched when 0).
+	ExportMED uint32
+
+	// Install the routes received from the peer in the host routing table.
+	InstallRoutes bool
+
+	// Enable graceful restart for the session.
+	GracefulRestart bool
+}
+
+// ParsePrefix parses a prefix list entry. The entry is either a prefix (matching only that prefix) or a prefix
+// followed by a range of prefix lengths (e.g. 10.0.0.0/8..24 matches 10.0.0.0/8 and the prefixes it contains that
+// are at most /24). It returns the prefix along with the minimum and maximum prefix lengths matched.
+func ParsePrefix(value string) (*net.IPNet, uint32, uint32, error) {
+	prefix, lengths, hasRange := strings.Cut(value, "..")
+
+	_, subnet, err := net.ParseCIDR(prefix)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("Invalid prefix %q: %w", value, err)
+	}
+
+	ones, bits := subnet.Mask.Size()
+	minLength := uint32(ones)
+	maxLength := uint32(ones)
+
+	if hasRange {
+		length, err := strconv.ParseUint(lengths, 10, 8)
+		if err != nil || length < uint64(ones) || length > uint64(bits) {
+			return nil, 0, 0, fmt.Errorf("Invalid prefix length range in %q (must be between %d and %d)", value, ones, bits)
+		}
+
+		maxLength = uint32(length)
+	}
+
+	return subnet, minLength, maxLength, nil
+}
+
+// policyName returns the base name of the policy and defined sets used for a peer in the given direction.
+func policyName(address net.IP, direction bgpAPI.PolicyDirection) string {
+	if direction == bgpAPI.PolicyDirection_IMPORT {
+		return fmt.Sprintf("lxd-import-%s", address.String())
+	}
+
+	return fmt.Sprintf("lxd-export-%s", address.String())
+}
+
+// peerPolicy returns the defined sets and the policy applied to the routes exchanged with a peer in the given
+// direction. The policy accepts the routes matching the prefixes and communities (applying the actions to them)
+// and rejects all the other routes of the peer. No policy is returned when there is nothing to filter or change.
+func peerPolicy(address net.IP, direction bgpAPI.PolicyDirection, prefixes []string, communities []string, actions *bgpAPI.Actions) ([]*bgpAPI.DefinedSet, *bgpAPI.Policy, error) {
+	hasActions := actions.LocalPref != nil || actions.Med != nil || actions.Community != nil
+	if len(prefixes) == 0 && len(communities) == 0 && !hasActions {
+		return nil, nil, nil
+	}
+
+	name := policyName(address, direction)
+
+	hostBits := 128
+	if address.To4() != nil {
+		hostBits = 32
+	}
+
+	sets := []*bgpAPI.DefinedSet{{
+		DefinedType: bgpAPI.DefinedType_NEIGHBOR,
+		Name:        name,
+		List:        []string{fmt.Sprintf("%s/%d", address.String(), hostBits)},
+	}}
+
+	// Prefix sets can only hold a single address family, so build one for each family in use.
+	familyPrefixes := map[int][]*bgpAPI.Prefix{}
+	for _, prefix := range prefixes {
+		subnet, minLength, maxLength, err := ParsePrefix(prefix)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		_, bits := subnet.Mask.Size()
+		familyPrefixes[bits] = append(familyPrefixes[bits], &bgpAPI.Prefix{
+			IpPrefix:      subnet.String(),
+			MaskLengthMin: minLength,
+			MaskLengthMax: maxLength,
+		})
+	}
+
+	prefixSets := []*bgpAPI.MatchSet{}
+	for _, bits := range []int{32, 128} {
+		if len(familyPrefixes[bits]) == 0 {
+			continue
+		}
+
+		setName := fmt.Sprintf("%s-prefixes-%d", name, bits)
+		sets = append(sets, &bgpAPI.DefinedSet{
+			DefinedType: bgpAPI.DefinedType_PREFIX,
+			Name:        setName,
+			Prefixes:    familyPrefixes[bits],
+		})
+
+		prefixSets = append(prefixSets, &bgpAPI.MatchSet{Type: bgpAPI.MatchSet_ANY, Name: setName})
+	}
+
+	// Without prefix filtering, a single statement matches all the prefixes.
+	if len(prefixSets) == 0 {
+		prefixSets = append(prefixSets, nil)
+	}
+
+	var communitySet *bgpAPI.MatchSet
+	if len(communities) > 0 {
+		setName := fmt.Sprintf("%s-communities", name)
+		sets = append(sets, &bgpAPI.DefinedSet{
+			DefinedType: bgpAPI.DefinedType_COMMUNITY,
+			Name:        setName,
+			List:        communities,
+		})
+
+		communitySet = &bgpAPI.MatchSet{Type: bgpAPI.MatchSet_ANY, Name: setName}
+	}
+
+	neighborSet := &bgpAPI.MatchSet{Type: bgpAPI.MatchSet_ANY, Name: name}
+	policy := &bgpAPI.Policy{Name: name}
+
+	acceptActions := &bgpAPI.Actions{
+		RouteAction: bgpAPI.RouteAction_ACCEPT,
+		Community:   actions.Community,
+		Med:         actions.Med,
+		LocalPref:   actions.LocalPref,
+	}
+
+	for i, prefixSet := range prefixSets {
+		policy.Statements = append(policy.Statements, &bgpAPI.Statement{
+			Name: fmt.Sprintf("%s-accept-%d", name, i),
+			Conditions: &bgpAPI.Conditions{
+				NeighborSet:  neighborSet,
+				PrefixSet:    prefixSet,
+				CommunitySet: communitySet,
+			},
+			Actions: acceptActions,
+		})
+	}
+
+	// Reject the routes of the peer that didn't match the filters.
+	if len(prefixes) > 0 || len(communities) > 0 {
+		policy.Statements = append(policy.Statements, &bgpAPI.Statement{
+			Name:       fmt.Sprintf("%s-reject", name),
+			Conditions: &bgpAPI.Conditions{NeighborSet: neighborSet},
+			Actions:    &bgpAPI.Actions{RouteAction: bgpAPI.RouteAction_REJECT},
+		})
+	}
+
+	return sets, policy, nil
+}
+
+// setupPolicies replaces the import and export policies of the BGP server with the ones of the current peers.
+func (s *Server) setupPolicies() error {
+	if s.bgp == nil {
+		return nil
+	}
+
+	definedSets := []*bgpAPI.DefinedSet{}
+	policies := map[bgpAPI.PolicyDirection][]*bgpAPI.Policy{}
+
+	for _, peer := range s.peers {
+		importActions := &bgpAPI.Actions{}
+		if peer.policy.ImportLocalPref > 0 {
+			importActions.LocalPref = &bgpAPI.LocalPrefAction{Value: peer.policy.ImportLocalPref}
+		}
+
+		exportActions := &bgpAPI.Actions{}
+		if peer.policy.ExportMED > 0 {
+			exportActions.Med = &bgpAPI.MedAction{Type: bgpAPI.MedAction_REPLACE, Value: int64(peer.policy.ExportMED)}
+		}
+
+		if len(peer.policy.ExportCommunities) > 0 {
+			exportActions.Community = &bgpAPI.CommunityAction{Type: bgpAPI.CommunityAction_ADD, Communities: peer.policy.ExportCommunities}
+		}
+
+		for direction, actions := range map[bgpAPI.PolicyDirection]*bgpAPI.Actions{bgpAPI.PolicyDirection_IMPORT: importActions, bgpAPI.PolicyDirection_EXPORT: exportActions} {
+			prefixes := peer.policy.ImportPrefixes
+			communities := peer.policy.ImportCommunities
+			if direction == bgpAPI.PolicyDirection_EXPORT {
+				prefixes = peer.policy.ExportPrefixes
+				communities = nil
+			}
+
+			sets, policy, err := peerPolicy(peer.address, direction, prefixes, communities, actions)
+			if err != nil {
+				return err
+			}
+
+			if policy == nil {
+				continue
+			}
+
+			definedSets = append(definedSets, sets...)
+			policies[direction] = append(policies[direction], policy)
+		}
+	}
+
+	ctx := context.Background()
+	directions := []bgpAPI.PolicyDirection{bgpAPI.PolicyDirection_IMPORT, bgpAPI.PolicyDirection_EXPORT}
+
+	// Detach the current policies so that they can be replaced.
+	for _, direction := range directions {
+		err := s.bgp.SetPolicyAssignment(ctx, &bgpAPI.SetPolicyAssignmentRequest{Assignment: &bgpAPI.PolicyAssignment{
+			Name:          "global",
+			Direction:     direction,
+			DefaultAction: bgpAPI.RouteAction_ACCEPT,
+		}})
+		if err != nil {
+			return fmt.Errorf("Failed clearing BGP policy assignment: %w", err)
+		}
+	}
+
+	allPolicies := append(policies[bgpAPI.PolicyDirection_IMPORT], policies[bgpAPI.PolicyDirection_EXPORT]...)
+	err := s.bgp.SetPolicies(ctx, &bgpAPI.SetPoliciesRequest{DefinedSets: definedSets, Policies: allPolicies})
+	if err != nil {
+		return fmt.Errorf("Failed setting BGP policies: %w", err)
+	}
+
+	// Routes not matching any policy keep being accepted.
+	for _, direction := range directions {
+		err := s.bgp.SetPolicyAssignment(ctx, &bgpAPI.SetPolicyAssignmentRequest{Assignment: &bgpAPI.PolicyAssignment{
+			Name:          "global",
+			Direction:     direction,
+			Policies:      policies[direction],
+			DefaultAction: bgpAPI.RouteAction_ACCEPT,
+		}})
+		if err != nil {
+			return fmt.Errorf("Failed assigning BGP policies: %w", err)
+		}
+	}
+
+	// Re-evaluate the routes already exchanged with the established peers.
+	for _, peer := range s.peers {
+		_ = s.bgp.ResetPeer(ctx, &bgpAPI.ResetPeerRequest{Address: peer.address.String(), Soft: true, Direction: bgpAPI.ResetPeerRequest_BOTH})
+	}
+
+	return nil
+}
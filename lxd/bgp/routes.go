@@ -0,0 +1,194 @@
+package bgp
+
+import (
+	"context"
+	"net"
+
+	bgpAPI "github.com/osrg/gobgp/v3/api"
+
+	"github.com/canonical/lxd/lxd/ip"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// routeProto is the routing protocol identifier used for the routes installed in the host routing table.
+const routeProto = "bgp"
+
+// route represents a route received from a BGP peer.
+type route struct {
+	peer    string
+	prefix  string
+	nexthop string
+}
+
+// routeFamilies are the address families of the routes installed in the host routing table.
+var routeFamilies = []*bgpAPI.Family{
+	{Afi: bgpAPI.Family_AFI_IP, Safi: bgpAPI.Family_SAFI_UNICAST},
+	{Afi: bgpAPI.Family_AFI_IP6, Safi: bgpAPI.Family_SAFI_UNICAST},
+}
+
+// startRoutesMonitor starts watching the best paths so that the routes received from the peers that have route
+// installation enabled are kept in sync with the host routing table.
+func (s *Server) startRoutesMonitor() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	updated := make(chan struct{}, 1)
+
+	req := &bgpAPI.WatchEventRequest{
+		Table: &bgpAPI.WatchEventRequest_Table{
+			Filters: []*bgpAPI.WatchEventRequest_Table_Filter{{Type: bgpAPI.WatchEventRequest_Table_Filter_BEST}},
+		},
+	}
+
+	err := s.bgp.WatchEvent(ctx, req, func(_ *bgpAPI.WatchEventResponse) {
+		// Coalesce the updates, the whole table is synced each time.
+		select {
+		case updated <- struct{}{}:
+		default:
+		}
+	})
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-updated:
+			}
+
+			s.mu.Lock()
+			err := s.syncRoutes()
+			s.mu.Unlock()
+			if err != nil {
+				logger.Warn("Failed syncing BGP routes", logger.Ctx{"err": err})
+			}
+		}
+	}()
+
+	s.routesCancel = cancel
+
+	return nil
+}
+
+// stopRoutesMonitor stops watching the best paths and removes the installed routes.
+func (s *Server) stopRoutesMonitor() {
+	if s.routesCancel != nil {
+		s.routesCancel()
+		s.routesCancel = nil
+	}
+
+	for prefix, r := range s.routes {
+		s.removeRoute(r)
+		delete(s.routes, prefix)
+	}
+}
+
+// syncRoutes installs the best paths received from the peers that have route installation enabled in the host
+// routing table and removes the routes that are no longer wanted.
+func (s *Server) syncRoutes() error {
+	wanted := map[string]route{}
+
+	if s.bgp != nil && s.routesCancel != nil {
+		for _, family := range routeFamilies {
+			err := s.bgp.ListPath(context.Background(), &bgpAPI.ListPathRequest{TableType: bgpAPI.TableType_GLOBAL, Family: family}, func(d *bgpAPI.Destination) {
+				for _, p := range d.Paths {
+					if !p.Best || p.IsWithdraw || p.IsNexthopInvalid {
+						continue
+					}
+
+					neighbor := net.ParseIP(p.NeighborIp)
+					if neighbor == nil {
+						continue // Locally originated path.
+					}
+
+					peer, found := s.peers[neighbor.String()]
+					if !found || !peer.policy.InstallRoutes {
+						continue
+					}
+
+					// Link-local next hops can't be used without knowing the interface.
+					nexthop := pathNextHop(p)
+					if nexthop == nil || nexthop.IsUnspecified() || nexthop.IsLinkLocalUnicast() {
+						continue
+					}
+
+					wanted[d.Prefix] = route{peer: neighbor.String(), prefix: d.Prefix, nexthop: nexthop.String()}
+				}
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Remove the routes that are gone or changed.
+	for prefix, r := range s.routes {
+		if wanted[prefix] == r {
+			continue
+		}
+
+		s.removeRoute(r)
+		delete(s.routes, prefix)
+	}
+
+	// Add the new routes.
+	for prefix, r := range wanted {
+		_, found := s.routes[prefix]
+		if found {
+			continue
+		}
+
+		ipRoute := &ip.Route{Route: r.prefix, Via: r.nexthop, Proto: routeProto, Family: routeFamily(r.prefix)}
+		err := ipRoute.Add()
+		if err != nil {
+			logger.Warn("Failed installing BGP route", logger.Ctx{"prefix": r.prefix, "nexthop": r.nexthop, "err": err})
+			continue
+		}
+
+		s.routes[prefix] = r
+	}
+
+	return nil
+}
+
+// removeRoute removes an installed route from the host routing table.
+func (s *Server) removeRoute(r route) {
+	ipRoute := &ip.Route{Route: r.prefix, Proto: routeProto, Family: routeFamily(r.prefix)}
+	err := ipRoute.Delete()
+	if err != nil {
+		logger.Warn("Failed removing BGP route", logger.Ctx{"prefix": r.prefix, "err": err})
+	}
+}
+
+// routeFamily returns the ip command family argument to use for a prefix.
+func routeFamily(prefix string) string {
+	ipAddr, _, _ := net.ParseCIDR(prefix)
+	if ipAddr != nil && ipAddr.To4() == nil {
+		return ip.FamilyV6
+	}
+
+	return ip.FamilyV4
+}
+
+// pathNextHop returns the next hop of a path.
+func pathNextHop(p *bgpAPI.Path) net.IP {
+	for _, attr := range p.Pattrs {
+		msg, err := attr.UnmarshalNew()
+		if err != nil {
+			continue
+		}
+
+		switch a := msg.(type) {
+		case *bgpAPI.NextHopAttribute:
+			return net.ParseIP(a.NextHop)
+		case *bgpAPI.MpReachNLRIAttribute:
+			if len(a.NextHops) > 0 {
+				return net.ParseIP(a.NextHops[0])
+			}
+		}
+	}
+
+	return nil
+}
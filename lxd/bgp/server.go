@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"reflect"
 	"strconv"
 	"sync"
 
@@ -28,6 +29,10 @@ type Server struct {
 	paths    map[string]path
 	peers    map[string]peer
 
+	// Routes received from the peers and installed in the host routing table.
+	routes       map[string]route
+	routesCancel context.CancelFunc
+
 	mu sync.Mutex
 }
 
@@ -42,6 +47,7 @@ type peer struct {
 	asn      uint32
 	password string
 	holdtime uint64
+	policy   PeerPolicy
 	count    int
 }
 
@@ -49,8 +55,9 @@ type peer struct {
 func NewServer() *Server {
 	// Setup new struct.
 	s := &Server{
-		paths: map[string]path{},
-		peers: map[string]peer{},
+		paths:  map[string]path{},
+		peers:  map[string]peer{},
+		routes: map[string]route{},
 	}
 
 	return s
@@ -133,12 +140,24 @@ func (s *Server) start(address string, asn uint32, routerID net.IP) error {
 
 	// Add any existing peers.
 	for _, peer := range s.peers {
-		err := s.addPeer(peer.address, peer.asn, peer.password, peer.holdtime)
+		err := s.addPeer(peer.address, peer.asn, peer.password, peer.holdtime, peer.policy)
 		if err != nil {
 			return err
 		}
 	}
 
+	// Apply the peer policies.
+	err = s.setupPolicies()
+	if err != nil {
+		return err
+	}
+
+	// Install the routes received from the peers.
+	err = s.startRoutesMonitor()
+	if err != nil {
+		return err
+	}
+
 	// Record the address.
 	s.address = address
 	s.asn = asn
@@ -170,6 +189,9 @@ func (s *Server) stop() error {
 		}
 	}
 
+	// Remove the installed routes.
+	s.stopRoutesMonitor()
+
 	// Stop the listener.
 	err := s.bgp.StopBgp(context.Background(), &bgpAPI.StopBgpRequest{})
 	if err != nil {
@@ -390,15 +412,20 @@ func (s *Server) removePrefixByUUID(pathUUID string) error {
 }
 
 // AddPeer adds a new BGP peer.
-func (s *Server) AddPeer(address net.IP, asn uint32, password string, holdTime uint64) error {
+func (s *Server) AddPeer(address net.IP, asn uint32, password string, holdTime uint64, policy PeerPolicy) error {
 	// Locking.
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	return s.addPeer(address, asn, password, holdTime)
+	err := s.addPeer(address, asn, password, holdTime, policy)
+	if err != nil {
+		return err
+	}
+
+	return s.setupPolicies()
 }
 
-func (s *Server) addPeer(address net.IP, asn uint32, password string, holdTime uint64) error {
+func (s *Server) addPeer(address net.IP, asn uint32, password string, holdTime uint64, policy PeerPolicy) error {
 	// Look for an existing peer.
 	bgpPeer, bgpPeerExists := s.peers[address.String()]
 	if bgpPeerExists {
@@ -410,6 +437,10 @@ func (s *Server) addPeer(address net.IP, asn uint32, password string, holdTime u
 			return fmt.Errorf("Peer %q already used but with a different password", address)
 		}
 
+		if !reflect.DeepEqual(bgpPeer.policy, policy) {
+			return fmt.Errorf("Peer %q already used but with a different policy", address)
+		}
+
 		// Re-use the existing entry.
 		bgpPeer.count++
 		s.peers[address.String()] = bgpPeer
@@ -425,12 +456,6 @@ func (s *Server) addPeer(address net.IP, asn uint32, password string, holdTime u
 			AuthPassword:    password,
 		},
 
-		// Allow for 120s offline before route removal.
-		GracefulRestart: &bgpAPI.GracefulRestart{
-			Enabled:     true,
-			RestartTime: 3600,
-		},
-
 		// Always allow for the maximum multihop.
 		EbgpMultihop: &bgpAPI.EbgpMultihop{
 			Enabled:     true,
@@ -438,6 +463,14 @@ func (s *Server) addPeer(address net.IP, asn uint32, password string, holdTime u
 		},
 	}
 
+	// Allow for 120s offline before route removal.
+	if policy.GracefulRestart {
+		n.GracefulRestart = &bgpAPI.GracefulRestart{
+			Enabled:     true,
+			RestartTime: 3600,
+		}
+	}
+
 	// Add hold time if configured.
 	if holdTime > 0 {
 		n.Timers = &bgpAPI.Timers{
@@ -464,7 +497,7 @@ func (s *Server) addPeer(address net.IP, asn uint32, password string, holdTime u
 		n.AfiSafis = append(n.AfiSafis, &bgpAPI.AfiSafi{
 			MpGracefulRestart: &bgpAPI.MpGracefulRestart{
 				Config: &bgpAPI.MpGracefulRestartConfig{
-					Enabled: true,
+					Enabled: policy.GracefulRestart,
 				},
 			},
 			Config: &bgpAPI.AfiSafiConfig{Family: family},
@@ -489,6 +522,7 @@ func (s *Server) addPeer(address net.IP, asn uint32, password string, holdTime u
 			asn:      asn,
 			password: password,
 			holdtime: holdTime,
+			policy:   policy,
 			count:    1,
 		}
 	}
@@ -502,7 +536,17 @@ func (s *Server) RemovePeer(address net.IP) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	return s.removePeer(address)
+	err := s.removePeer(address)
+	if err != nil {
+		return err
+	}
+
+	err = s.setupPolicies()
+	if err != nil {
+		return err
+	}
+
+	return s.syncRoutes()
 }
 
 func (s *Server) removePeer(address net.IP) error {
@@ -0,0 +1,90 @@
+package bgp
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strings"
+
+	bgpAPI "github.com/osrg/gobgp/v3/api"
+)
+
+// PeerState represents the live state of a BGP peer.
+type PeerState struct {
+	// Session state (e.g. "established").
+	State string
+
+	// Prefixes advertised to the peer.
+	AdvertisedPrefixes []string
+
+	// Prefixes received from the peer (before applying the import policy).
+	ReceivedPrefixes []string
+
+	// Prefixes received from the peer and installed in the host routing table.
+	InstalledPrefixes []string
+}
+
+// PeerState returns the live state of a BGP peer.
+func (s *Server) PeerState(address net.IP) (*PeerState, error) {
+	// Locking.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, found := s.peers[address.String()]
+	if !found {
+		return nil, ErrPeerNotFound
+	}
+
+	state := &PeerState{
+		State:              "down",
+		AdvertisedPrefixes: []string{},
+		ReceivedPrefixes:   []string{},
+		InstalledPrefixes:  []string{},
+	}
+
+	if s.bgp == nil || s.address == "" {
+		return state, nil
+	}
+
+	ctx := context.Background()
+
+	err := s.bgp.ListPeer(ctx, &bgpAPI.ListPeerRequest{Address: address.String()}, func(p *bgpAPI.Peer) {
+		if p.State != nil {
+			state.State = strings.ToLower(p.State.SessionState.String())
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Paths can only be listed once the session is up.
+	if state.State == "established" {
+		tables := map[bgpAPI.TableType]*[]string{
+			bgpAPI.TableType_ADJ_OUT: &state.AdvertisedPrefixes,
+			bgpAPI.TableType_ADJ_IN:  &state.ReceivedPrefixes,
+		}
+
+		for tableType, prefixes := range tables {
+			for _, family := range routeFamilies {
+				err := s.bgp.ListPath(ctx, &bgpAPI.ListPathRequest{TableType: tableType, Name: address.String(), Family: family}, func(d *bgpAPI.Destination) {
+					*prefixes = append(*prefixes, d.Prefix)
+				})
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	for _, r := range s.routes {
+		if r.peer == address.String() {
+			state.InstalledPrefixes = append(state.InstalledPrefixes, r.prefix)
+		}
+	}
+
+	sort.Strings(state.AdvertisedPrefixes)
+	sort.Strings(state.ReceivedPrefixes)
+	sort.Strings(state.InstalledPrefixes)
+
+	return state, nil
+}
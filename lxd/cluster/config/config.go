@@ -44,6 +44,11 @@ func (c *Config) BackupsCompressionAlgorithm() string {
 	return c.m.GetString("backups.compression_algorithm")
 }
 
+// BackupsS3AllowedSubnets returns the private subnets that S3 backup targets may be reached on.
+func (c *Config) BackupsS3AllowedSubnets() string {
+	return c.m.GetString("backups.s3.allowed_subnets")
+}
+
 // MetricsAuthentication checks whether metrics API requires authentication.
 func (c *Config) MetricsAuthentication() bool {
 	return c.m.GetBool("core.metrics_authentication")
@@ -331,6 +336,16 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Compression algorithm to use for backups
 	"backups.compression_algorithm": {Default: "gzip", Validator: validate.IsCompressionAlgorithm},
 
+	// lxdmeta:generate(entities=server; group=miscellaneous; key=backups.s3.allowed_subnets)
+	// Specify a comma-separated list of CIDR subnets.
+	// By default, LXD refuses to connect to private addresses (RFC 1918 and unique local addresses) when streaming backups to S3 or importing them from S3.
+	// Private addresses within these subnets are allowed.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Private subnets that S3 backup targets may be reached on
+	"backups.s3.allowed_subnets": {Validator: validate.Optional(validate.IsListOf(validate.IsNetwork))},
+
 	// lxdmeta:generate(entities=server; group=cluster; key=cluster.offline_threshold)
 	// Specify the number of seconds after which an unresponsive member is considered offline.
 	// ---
@@ -631,13 +646,13 @@ var ConfigSchema = config.Schema{
 
 	// lxdmeta:generate(entities=server; group=loki; key=loki.types)
 	// Specify a comma-separated list of events to send to the Loki server.
-	// The events can be any combination of `lifecycle`, `logging`, and `ovn`.
+	// The events can be any combination of `lifecycle`, `logging`, `network-acl`, and `ovn`.
 	// ---
 	//  type: string
 	//  scope: global
 	//  defaultdesc: `lifecycle,logging`
 	//  shortdesc: Events to send to the Loki server
-	"loki.types": {Validator: validate.Optional(validate.IsListOf(validate.IsOneOf("lifecycle", "logging", "ovn"))), Default: "lifecycle,logging"},
+	"loki.types": {Validator: validate.Optional(validate.IsListOf(validate.IsOneOf("lifecycle", "logging", "network-acl", "ovn"))), Default: "lifecycle,logging"},
 
 	// lxdmeta:generate(entities=server; group=miscellaneous; key=maas.api.key)
 	//
@@ -55,6 +55,8 @@ import (
 	"github.com/canonical/lxd/lxd/loki"
 	"github.com/canonical/lxd/lxd/maas"
 	"github.com/canonical/lxd/lxd/metrics"
+	"github.com/canonical/lxd/lxd/network"
+	"github.com/canonical/lxd/lxd/network/acl"
 	networkZone "github.com/canonical/lxd/lxd/network/zone"
 	"github.com/canonical/lxd/lxd/node"
 	"github.com/canonical/lxd/lxd/request"
@@ -957,6 +959,9 @@ func (d *Daemon) init() error {
 	// Setup internal event listener
 	d.internalListener = events.NewInternalListener(d.shutdownCtx, d.events)
 
+	// Keep the hosts of the native DHCP servers up to date.
+	d.internalListener.AddHandler("network-dhcp", network.NativeDHCPHandleEvent)
+
 	// Lets check if there's an existing LXD running
 	err = endpoints.CheckAlreadyRunning(d.UnixSocket())
 	if err != nil {
@@ -1376,6 +1381,12 @@ func (d *Daemon) init() error {
 	d.firewall = firewall.New()
 	logger.Info("Firewall loaded driver", logger.Ctx{"driver": d.firewall})
 
+	// Send the log entries of the network ACL rules applied to the firewall as events.
+	err = acl.StartFirewallLogListener(d.shutdownCtx, d.events)
+	if err != nil {
+		logger.Warn("Failed starting network ACL log listener", logger.Ctx{"err": err})
+	}
+
 	err = cluster.NotifyUpgradeCompleted(d.State(), networkCert, d.serverCert())
 	if err != nil {
 		// Ignore the error, since it's not fatal for this particular
@@ -1588,7 +1599,7 @@ func (d *Daemon) init() error {
 	}
 
 	// Setup DNS listener.
-	d.dns = dns.NewServer(d.db.Cluster, func(name string, full bool) (*dns.Zone, error) {
+	d.dns = dns.NewServer(d.db.Cluster, func(name string) (*dns.Zone, error) {
 		// Fetch the zone.
 		zone, err := networkZone.LoadByName(d.State(), name)
 		if err != nil {
@@ -1601,26 +1612,14 @@ func (d *Daemon) init() error {
 		resp := &dns.Zone{}
 		resp.Info = *zoneInfo
 
-		if full {
-			// Full content was requested.
-			zoneBuilder, err := zone.Content()
-			if err != nil {
-				logger.Errorf("Failed to render DNS zone %q: %v", name, err)
-				return nil, err
-			}
-
-			resp.Content = strings.TrimSpace(zoneBuilder.String())
-		} else {
-			// SOA only.
-			zoneBuilder, err := zone.SOA()
-			if err != nil {
-				logger.Errorf("Failed to render DNS zone %q: %v", name, err)
-				return nil, err
-			}
-
-			resp.Content = strings.TrimSpace(zoneBuilder.String())
+		zoneBuilder, err := zone.Content()
+		if err != nil {
+			logger.Errorf("Failed to render DNS zone %q: %v", name, err)
+			return nil, err
 		}
 
+		resp.Content = strings.TrimSpace(zoneBuilder.String())
+
 		return resp, nil
 	})
 
@@ -1823,6 +1822,9 @@ func (d *Daemon) init() error {
 		// Prune expired custom volume snapshots and take snapshots of custom volumes (minutely check of configurable cron expression)
 		d.tasks.Add(pruneExpiredAndAutoCreateCustomVolumeSnapshotsTask(d))
 
+		// Take scheduled backups of instances and custom volumes (minutely check of configurable cron expression)
+		d.tasks.Add(autoCreateScheduledBackupsTask(d))
+
 		// Remove resolved warnings (daily)
 		d.tasks.Add(pruneResolvedWarningsTask(d))
 
@@ -1831,6 +1833,15 @@ func (d *Daemon) init() error {
 
 		// Remove expired tokens (hourly)
 		d.tasks.Add(autoRemoveExpiredTokensTask(d))
+
+		// Check storage pool free space (every 5 minutes)
+		d.tasks.Add(storagePoolSpaceCheckTask(d))
+
+		// Refresh network zones and notify their DNS peers of changes (minutely)
+		d.tasks.Add(refreshNetworkZonesTask(d))
+
+		// Reapply firewall rules that went missing (every 5 minutes)
+		d.tasks.Add(firewallReconcileTask(d))
 	}
 
 	// Start all background tasks
@@ -2121,7 +2132,7 @@ func (d *Daemon) setupSyslogSocket(enable bool) error {
 
 	logger.Debug("Starting syslog socket")
 
-	err := StartSyslogListener(ctx, d.events)
+	err := StartSyslogListener(ctx, d.State())
 	if err != nil {
 		return err
 	}
@@ -2316,6 +2327,13 @@ func (d *Daemon) nodeRefreshTask(heartbeatData *cluster.APIHeartbeat, isLeader b
 		logger.Error("Error restarting OVN networks", logger.Ctx{"err": err})
 	}
 
+	// Refresh the WireGuard network peers, members may have allocated their subnet since the last heartbeat.
+	err = networkUpdateWireguardPeersTask(s, heartbeatData)
+	if err != nil {
+		stateChangeTaskFailure = true
+		logger.Error("Error refreshing WireGuard peers", logger.Ctx{"err": err, "local": localClusterAddress})
+	}
+
 	if d.hasMemberStateChanged(heartbeatData) {
 		logger.Info("Cluster member state has changed", logger.Ctx{"local": localClusterAddress})
 
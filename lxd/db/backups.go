@@ -24,6 +24,7 @@ type InstanceBackup struct {
 	InstanceOnly         bool
 	OptimizedStorage     bool
 	CompressionAlgorithm string
+	IncrementalFrom      string
 }
 
 // StoragePoolVolumeBackup is a value object holding all db-related details about a storage volume backup.
@@ -36,6 +37,7 @@ type StoragePoolVolumeBackup struct {
 	VolumeOnly           bool
 	OptimizedStorage     bool
 	CompressionAlgorithm string
+	IncrementalFrom      string
 }
 
 // Returns the ID of the instance backup with the given name.
@@ -63,7 +65,8 @@ func (c *ClusterTx) GetInstanceBackup(ctx context.Context, projectName string, n
 	q := `
 SELECT instances_backups.id, instances_backups.instance_id,
        instances_backups.creation_date, instances_backups.expiry_date,
-       instances_backups.container_only, instances_backups.optimized_storage
+       instances_backups.container_only, instances_backups.optimized_storage,
+       instances_backups.incremental_from
     FROM instances_backups
     JOIN instances ON instances.id=instances_backups.instance_id
     JOIN projects ON projects.id=instances.project_id
@@ -71,7 +74,7 @@ SELECT instances_backups.id, instances_backups.instance_id,
 `
 	arg1 := []any{projectName, name}
 	arg2 := []any{&args.ID, &args.InstanceID, &args.CreationDate,
-		&args.ExpiryDate, &instanceOnlyInt, &optimizedStorageInt}
+		&args.ExpiryDate, &instanceOnlyInt, &optimizedStorageInt, &args.IncrementalFrom}
 
 	err := dbQueryRowScan(ctx, c, q, arg1, arg2)
 	if err != nil {
@@ -103,7 +106,8 @@ func (c *ClusterTx) GetInstanceBackupWithID(ctx context.Context, backupID int) (
 	q := `
 SELECT instances_backups.name, instances_backups.instance_id,
        instances_backups.creation_date, instances_backups.expiry_date,
-       instances_backups.container_only, instances_backups.optimized_storage
+       instances_backups.container_only, instances_backups.optimized_storage,
+       instances_backups.incremental_from
     FROM instances_backups
     JOIN instances ON instances.id=instances_backups.instance_id
     JOIN projects ON projects.id=instances.project_id
@@ -111,7 +115,7 @@ SELECT instances_backups.name, instances_backups.instance_id,
 `
 	arg1 := []any{backupID}
 	arg2 := []any{&args.Name, &args.InstanceID, &args.CreationDate,
-		&args.ExpiryDate, &instanceOnlyInt, &optimizedStorageInt}
+		&args.ExpiryDate, &instanceOnlyInt, &optimizedStorageInt, &args.IncrementalFrom}
 
 	err := dbQueryRowScan(ctx, c, q, arg1, arg2)
 	if err != nil {
@@ -174,7 +178,7 @@ func (c *ClusterTx) CreateInstanceBackup(ctx context.Context, args InstanceBacku
 		optimizedStorageInt = 1
 	}
 
-	str := "INSERT INTO instances_backups (instance_id, name, creation_date, expiry_date, container_only, optimized_storage) VALUES (?, ?, ?, ?, ?, ?)"
+	str := "INSERT INTO instances_backups (instance_id, name, creation_date, expiry_date, container_only, optimized_storage, incremental_from) VALUES (?, ?, ?, ?, ?, ?, ?)"
 	stmt, err := c.tx.Prepare(str)
 	if err != nil {
 		return err
@@ -183,7 +187,7 @@ func (c *ClusterTx) CreateInstanceBackup(ctx context.Context, args InstanceBacku
 	defer func() { _ = stmt.Close() }()
 	result, err := stmt.Exec(args.InstanceID, args.Name,
 		args.CreationDate.Unix(), args.ExpiryDate.Unix(), instanceOnlyInt,
-		optimizedStorageInt)
+		optimizedStorageInt, args.IncrementalFrom)
 	if err != nil {
 		return err
 	}
@@ -328,7 +332,8 @@ func (c *ClusterTx) GetStoragePoolVolumeBackups(ctx context.Context, projectName
 		backups.creation_date,
 		backups.expiry_date,
 		backups.volume_only,
-		backups.optimized_storage
+		backups.optimized_storage,
+		backups.incremental_from
 	FROM storage_volumes_backups AS backups
 	JOIN storage_volumes ON storage_volumes.id=backups.storage_volume_id
 	JOIN projects ON projects.id=storage_volumes.project_id
@@ -342,7 +347,7 @@ func (c *ClusterTx) GetStoragePoolVolumeBackups(ctx context.Context, projectName
 		var b StoragePoolVolumeBackup
 		var expiryTime sql.NullTime
 
-		err := scan(&b.ID, &b.VolumeID, &b.Name, &b.CreationDate, &expiryTime, &b.VolumeOnly, &b.OptimizedStorage)
+		err := scan(&b.ID, &b.VolumeID, &b.Name, &b.CreationDate, &expiryTime, &b.VolumeOnly, &b.OptimizedStorage, &b.IncrementalFrom)
 		if err != nil {
 			return err
 		}
@@ -401,7 +406,7 @@ func (c *ClusterTx) CreateStoragePoolVolumeBackup(ctx context.Context, args Stor
 		optimizedStorageInt = 1
 	}
 
-	str := "INSERT INTO storage_volumes_backups (storage_volume_id, name, creation_date, expiry_date, volume_only, optimized_storage) VALUES (?, ?, ?, ?, ?, ?)"
+	str := "INSERT INTO storage_volumes_backups (storage_volume_id, name, creation_date, expiry_date, volume_only, optimized_storage, incremental_from) VALUES (?, ?, ?, ?, ?, ?, ?)"
 	stmt, err := c.tx.Prepare(str)
 	if err != nil {
 		return err
@@ -410,7 +415,7 @@ func (c *ClusterTx) CreateStoragePoolVolumeBackup(ctx context.Context, args Stor
 	defer func() { _ = stmt.Close() }()
 	result, err := stmt.Exec(args.VolumeID, args.Name,
 		args.CreationDate.Unix(), args.ExpiryDate.Unix(), volumeOnlyInt,
-		optimizedStorageInt)
+		optimizedStorageInt, args.IncrementalFrom)
 	if err != nil {
 		return err
 	}
@@ -464,14 +469,15 @@ SELECT
 	backups.creation_date,
 	backups.expiry_date,
 	backups.volume_only,
-	backups.optimized_storage
+	backups.optimized_storage,
+	backups.incremental_from
 FROM storage_volumes_backups AS backups
 JOIN storage_volumes ON storage_volumes.id=backups.storage_volume_id
 JOIN projects ON projects.id=storage_volumes.project_id
 WHERE projects.name=? AND backups.name=?
 `
 	arg1 := []any{projectName, backupName}
-	outfmt := []any{&args.ID, &args.VolumeID, &args.Name, &args.CreationDate, &args.ExpiryDate, &args.VolumeOnly, &args.OptimizedStorage}
+	outfmt := []any{&args.ID, &args.VolumeID, &args.Name, &args.CreationDate, &args.ExpiryDate, &args.VolumeOnly, &args.OptimizedStorage, &args.IncrementalFrom}
 
 	err := dbQueryRowScan(ctx, c, q, arg1, outfmt)
 	if err != nil {
@@ -496,14 +502,15 @@ SELECT
 	backups.creation_date,
 	backups.expiry_date,
 	backups.volume_only,
-	backups.optimized_storage
+	backups.optimized_storage,
+	backups.incremental_from
 FROM storage_volumes_backups AS backups
 JOIN storage_volumes ON storage_volumes.id=backups.storage_volume_id
 JOIN projects ON projects.id=storage_volumes.project_id
 WHERE backups.id=?
 `
 	arg1 := []any{backupID}
-	outfmt := []any{&args.ID, &args.VolumeID, &args.Name, &args.CreationDate, &args.ExpiryDate, &args.VolumeOnly, &args.OptimizedStorage}
+	outfmt := []any{&args.ID, &args.VolumeID, &args.Name, &args.CreationDate, &args.ExpiryDate, &args.VolumeOnly, &args.OptimizedStorage, &args.IncrementalFrom}
 
 	err := dbQueryRowScan(ctx, c, q, arg1, outfmt)
 	if err != nil {
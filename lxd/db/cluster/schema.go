@@ -162,6 +162,7 @@ CREATE TABLE "instances_backups" (
     expiry_date DATETIME,
     container_only INTEGER NOT NULL default 0,
     optimized_storage INTEGER NOT NULL default 0,
+    incremental_from TEXT NOT NULL DEFAULT "",
     FOREIGN KEY (instance_id) REFERENCES "instances" (id) ON DELETE CASCADE,
     UNIQUE (instance_id, name)
 );
@@ -270,6 +271,24 @@ CREATE TABLE "networks_acls_config" (
     UNIQUE (network_acl_id, key),
     FOREIGN KEY (network_acl_id) REFERENCES "networks_acls" (id) ON DELETE CASCADE
 );
+CREATE TABLE "networks_address_groups" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	project_id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	description TEXT NOT NULL,
+	addresses TEXT NOT NULL,
+	ports TEXT NOT NULL,
+	UNIQUE (project_id, name),
+	FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE
+);
+CREATE TABLE "networks_address_groups_config" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	network_address_group_id INTEGER NOT NULL,
+	key TEXT NOT NULL,
+	value TEXT NOT NULL,
+	UNIQUE (network_address_group_id, key),
+	FOREIGN KEY (network_address_group_id) REFERENCES "networks_address_groups" (id) ON DELETE CASCADE
+);
 CREATE TABLE "networks_config" (
     id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
     network_id INTEGER NOT NULL,
@@ -299,6 +318,44 @@ CREATE TABLE "networks_forwards_config" (
 	UNIQUE (network_forward_id, key),
 	FOREIGN KEY (network_forward_id) REFERENCES "networks_forwards" (id) ON DELETE CASCADE
 );
+CREATE TABLE "networks_ip_allocations" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	network_id INTEGER NOT NULL,
+	project_id INTEGER NOT NULL,
+	address TEXT NOT NULL,
+	description TEXT NOT NULL,
+	instance_id INTEGER,
+	device_name TEXT NOT NULL,
+	UNIQUE (network_id, address),
+	FOREIGN KEY (network_id) REFERENCES "networks" (id) ON DELETE CASCADE,
+	FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE,
+	FOREIGN KEY (instance_id) REFERENCES "instances" (id) ON DELETE CASCADE
+);
+CREATE TABLE "networks_ip_ranges" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	network_id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	description TEXT NOT NULL,
+	start_address TEXT NOT NULL,
+	end_address TEXT NOT NULL,
+	project_id INTEGER,
+	UNIQUE (network_id, name),
+	FOREIGN KEY (network_id) REFERENCES "networks" (id) ON DELETE CASCADE,
+	FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE
+);
+CREATE TABLE "networks_leases" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	network_id INTEGER NOT NULL,
+	node_id INTEGER NOT NULL,
+	hwaddr TEXT NOT NULL,
+	client_id TEXT NOT NULL,
+	address TEXT NOT NULL,
+	hostname TEXT NOT NULL,
+	expiry_date DATETIME,
+	UNIQUE (network_id, node_id, address),
+	FOREIGN KEY (network_id) REFERENCES "networks" (id) ON DELETE CASCADE,
+	FOREIGN KEY (node_id) REFERENCES "nodes" (id) ON DELETE CASCADE
+);
 CREATE TABLE "networks_load_balancers" (
 	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
 	network_id INTEGER NOT NULL,
@@ -383,6 +440,14 @@ CREATE TABLE "networks_zones_records_config" (
 	UNIQUE (network_zone_record_id, key),
 	FOREIGN KEY (network_zone_record_id) REFERENCES "networks_zones_records" (id) ON DELETE CASCADE
 );
+CREATE TABLE "networks_zones_serials" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	network_zone_id INTEGER NOT NULL,
+	serial INTEGER NOT NULL,
+	content_hash TEXT NOT NULL,
+	UNIQUE (network_zone_id),
+	FOREIGN KEY (network_zone_id) REFERENCES "networks_zones" (id) ON DELETE CASCADE
+);
 CREATE TABLE "nodes" (
     id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
     name TEXT NOT NULL,
@@ -597,6 +662,7 @@ CREATE TABLE "storage_volumes_backups" (
     expiry_date DATETIME,
     volume_only INTEGER NOT NULL default 0,
     optimized_storage INTEGER NOT NULL default 0,
+    incremental_from TEXT NOT NULL DEFAULT "",
     FOREIGN KEY (storage_volume_id) REFERENCES "storage_volumes" (id) ON DELETE CASCADE,
     UNIQUE (storage_volume_id, name)
 );
@@ -662,5 +728,5 @@ CREATE TABLE "warnings" (
 );
 CREATE UNIQUE INDEX warnings_unique_node_id_project_id_entity_type_code_entity_id_type_code ON warnings(IFNULL(node_id, -1), IFNULL(project_id, -1), entity_type_code, entity_id, type_code);
 
-INSERT INTO schema (version, updated_at) VALUES (73, strftime("%s"))
+INSERT INTO schema (version, updated_at) VALUES (78, strftime("%s"))
 `
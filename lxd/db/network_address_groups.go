@@ -0,0 +1,259 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// GetNetworkAddressGroups returns the names of existing network address groups.
+func (c *ClusterTx) GetNetworkAddressGroups(ctx context.Context, project string) ([]string, error) {
+	q := `SELECT name FROM networks_address_groups
+		WHERE project_id = (SELECT id FROM projects WHERE name = ? LIMIT 1)
+		ORDER BY id
+	`
+
+	var groupNames []string
+
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		var groupName string
+
+		err := scan(&groupName)
+		if err != nil {
+			return err
+		}
+
+		groupNames = append(groupNames, groupName)
+
+		return nil
+	}, project)
+	if err != nil {
+		return nil, err
+	}
+
+	return groupNames, nil
+}
+
+// GetNetworkAddressGroup returns the network address group with the given name in the given project.
+func (c *ClusterTx) GetNetworkAddressGroup(ctx context.Context, projectName string, name string) (int64, *api.NetworkAddressGroup, error) {
+	var id = int64(-1)
+	var addressesJSON string
+	var portsJSON string
+
+	group := api.NetworkAddressGroup{
+		Name: name,
+	}
+
+	q := `
+		SELECT id, description, addresses, ports
+		FROM networks_address_groups
+		WHERE project_id = (SELECT id FROM projects WHERE name = ? LIMIT 1) AND name=?
+		LIMIT 1
+	`
+
+	err := c.tx.QueryRowContext(ctx, q, projectName, name).Scan(&id, &group.Description, &addressesJSON, &portsJSON)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return -1, nil, api.StatusErrorf(http.StatusNotFound, "Network address group not found")
+		}
+
+		return -1, nil, err
+	}
+
+	err = networkAddressGroupConfig(ctx, c, id, &group)
+	if err != nil {
+		return -1, nil, fmt.Errorf("Failed loading config: %w", err)
+	}
+
+	group.Addresses = []string{}
+	if addressesJSON != "" {
+		err = json.Unmarshal([]byte(addressesJSON), &group.Addresses)
+		if err != nil {
+			return -1, nil, fmt.Errorf("Failed unmarshalling addresses: %w", err)
+		}
+	}
+
+	group.Ports = []string{}
+	if portsJSON != "" {
+		err = json.Unmarshal([]byte(portsJSON), &group.Ports)
+		if err != nil {
+			return -1, nil, fmt.Errorf("Failed unmarshalling ports: %w", err)
+		}
+	}
+
+	return id, &group, nil
+}
+
+// networkAddressGroupConfig populates the config map of the network address group with the given ID.
+func networkAddressGroupConfig(ctx context.Context, tx *ClusterTx, id int64, group *api.NetworkAddressGroup) error {
+	q := `
+		SELECT key, value
+		FROM networks_address_groups_config
+		WHERE network_address_group_id=?
+	`
+
+	group.Config = make(map[string]string)
+	return query.Scan(ctx, tx.Tx(), q, func(scan func(dest ...any) error) error {
+		var key, value string
+
+		err := scan(&key, &value)
+		if err != nil {
+			return err
+		}
+
+		_, found := group.Config[key]
+		if found {
+			return fmt.Errorf("Duplicate config row found for key %q for network address group ID %d", key, id)
+		}
+
+		group.Config[key] = value
+
+		return nil
+	}, id)
+}
+
+// networkAddressGroupMarshal returns the JSON encoded addresses and ports of a network address group.
+func networkAddressGroupMarshal(put api.NetworkAddressGroupPut) (string, string, error) {
+	addresses := put.Addresses
+	if addresses == nil {
+		addresses = []string{}
+	}
+
+	addressesJSON, err := json.Marshal(addresses)
+	if err != nil {
+		return "", "", fmt.Errorf("Failed marshalling addresses: %w", err)
+	}
+
+	ports := put.Ports
+	if ports == nil {
+		ports = []string{}
+	}
+
+	portsJSON, err := json.Marshal(ports)
+	if err != nil {
+		return "", "", fmt.Errorf("Failed marshalling ports: %w", err)
+	}
+
+	return string(addressesJSON), string(portsJSON), nil
+}
+
+// CreateNetworkAddressGroup creates a new network address group.
+func (c *ClusterTx) CreateNetworkAddressGroup(ctx context.Context, projectName string, info *api.NetworkAddressGroupsPost) (int64, error) {
+	addressesJSON, portsJSON, err := networkAddressGroupMarshal(info.NetworkAddressGroupPut)
+	if err != nil {
+		return -1, err
+	}
+
+	// Insert a new network address group record.
+	result, err := c.tx.ExecContext(ctx, `
+			INSERT INTO networks_address_groups (project_id, name, description, addresses, ports)
+			VALUES ((SELECT id FROM projects WHERE name = ? LIMIT 1), ?, ?, ?, ?)
+		`, projectName, info.Name, info.Description, addressesJSON, portsJSON)
+	if err != nil {
+		return -1, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return -1, err
+	}
+
+	err = networkAddressGroupConfigAdd(c.tx, id, info.Config)
+	if err != nil {
+		return -1, err
+	}
+
+	return id, nil
+}
+
+// networkAddressGroupConfigAdd inserts network address group config keys.
+func networkAddressGroupConfigAdd(tx *sql.Tx, id int64, config map[string]string) error {
+	sql := "INSERT INTO networks_address_groups_config (network_address_group_id, key, value) VALUES(?, ?, ?)"
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = stmt.Close() }()
+
+	for k, v := range config {
+		if v == "" {
+			continue
+		}
+
+		_, err = stmt.Exec(id, k, v)
+		if err != nil {
+			return fmt.Errorf("Failed inserting config: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// UpdateNetworkAddressGroup updates the network address group with the given ID.
+func (c *ClusterTx) UpdateNetworkAddressGroup(ctx context.Context, id int64, config api.NetworkAddressGroupPut) error {
+	addressesJSON, portsJSON, err := networkAddressGroupMarshal(config)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.tx.ExecContext(ctx, `
+			UPDATE networks_address_groups
+			SET description=?, addresses = ?, ports = ?
+			WHERE id=?
+		`, config.Description, addressesJSON, portsJSON, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.tx.ExecContext(ctx, "DELETE FROM networks_address_groups_config WHERE network_address_group_id=?", id)
+	if err != nil {
+		return err
+	}
+
+	err = networkAddressGroupConfigAdd(c.tx, id, config.Config)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RenameNetworkAddressGroup renames a network address group.
+func (c *ClusterTx) RenameNetworkAddressGroup(ctx context.Context, id int64, newName string) error {
+	_, err := c.tx.ExecContext(ctx, "UPDATE networks_address_groups SET name=? WHERE id=?", newName, id)
+
+	return err
+}
+
+// DeleteNetworkAddressGroup deletes the network address group.
+func (c *ClusterTx) DeleteNetworkAddressGroup(ctx context.Context, id int64) error {
+	_, err := c.tx.ExecContext(ctx, "DELETE FROM networks_address_groups WHERE id=?", id)
+
+	return err
+}
+
+// GetNetworkAddressGroupURIs returns the URIs for the network address groups with the given project.
+func (c *ClusterTx) GetNetworkAddressGroupURIs(ctx context.Context, projectID int, project string) ([]string, error) {
+	sql := `SELECT networks_address_groups.name from networks_address_groups WHERE networks_address_groups.project_id = ?`
+
+	names, err := query.SelectStrings(ctx, c.tx, sql, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get URIs for network address group: %w", err)
+	}
+
+	uris := make([]string, len(names))
+	for i := range names {
+		uris[i] = api.NewURL().Path(version.APIVersion, "network-address-groups", names[i]).Project(project).String()
+	}
+
+	return uris, nil
+}
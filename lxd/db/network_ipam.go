@@ -0,0 +1,221 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// NetworkIPAllocation represents an address allocated in a network along with its database identifiers.
+type NetworkIPAllocation struct {
+	api.NetworkIPAllocation
+
+	ID           int64
+	InstanceID   int64 // -1 if the address isn't allocated to an instance NIC.
+	InstanceUUID string
+}
+
+// GetNetworkIPRanges returns the IP ranges of the network with the given ID.
+func (c *ClusterTx) GetNetworkIPRanges(ctx context.Context, networkID int64) ([]*api.NetworkIPRange, error) {
+	q := `
+		SELECT networks_ip_ranges.name, networks_ip_ranges.description, networks_ip_ranges.start_address, networks_ip_ranges.end_address, IFNULL(projects.name, '')
+		FROM networks_ip_ranges
+		LEFT JOIN projects ON projects.id = networks_ip_ranges.project_id
+		WHERE networks_ip_ranges.network_id = ?
+		ORDER BY networks_ip_ranges.id
+	`
+
+	var ipRanges []*api.NetworkIPRange
+
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		var ipRange api.NetworkIPRange
+
+		err := scan(&ipRange.Name, &ipRange.Description, &ipRange.Start, &ipRange.End, &ipRange.Project)
+		if err != nil {
+			return err
+		}
+
+		ipRanges = append(ipRanges, &ipRange)
+
+		return nil
+	}, networkID)
+	if err != nil {
+		return nil, err
+	}
+
+	return ipRanges, nil
+}
+
+// GetNetworkIPRange returns the IP range with the given name of the network with the given ID.
+func (c *ClusterTx) GetNetworkIPRange(ctx context.Context, networkID int64, name string) (int64, *api.NetworkIPRange, error) {
+	var id = int64(-1)
+
+	ipRange := api.NetworkIPRange{
+		Name: name,
+	}
+
+	q := `
+		SELECT networks_ip_ranges.id, networks_ip_ranges.description, networks_ip_ranges.start_address, networks_ip_ranges.end_address, IFNULL(projects.name, '')
+		FROM networks_ip_ranges
+		LEFT JOIN projects ON projects.id = networks_ip_ranges.project_id
+		WHERE networks_ip_ranges.network_id = ? AND networks_ip_ranges.name = ?
+		LIMIT 1
+	`
+
+	err := c.tx.QueryRowContext(ctx, q, networkID, name).Scan(&id, &ipRange.Description, &ipRange.Start, &ipRange.End, &ipRange.Project)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return -1, nil, api.StatusErrorf(http.StatusNotFound, "Network IP range not found")
+		}
+
+		return -1, nil, err
+	}
+
+	return id, &ipRange, nil
+}
+
+// networkIPRangeProjectID returns the ID of the project an IP range is delegated to (NULL for reserved ranges).
+func networkIPRangeProjectID(ctx context.Context, tx *sql.Tx, projectName string) (sql.NullInt64, error) {
+	if projectName == "" {
+		return sql.NullInt64{}, nil
+	}
+
+	var projectID int64
+
+	err := tx.QueryRowContext(ctx, "SELECT id FROM projects WHERE name = ? LIMIT 1", projectName).Scan(&projectID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return sql.NullInt64{}, api.StatusErrorf(http.StatusNotFound, "Project %q not found", projectName)
+		}
+
+		return sql.NullInt64{}, err
+	}
+
+	return sql.NullInt64{Int64: projectID, Valid: true}, nil
+}
+
+// CreateNetworkIPRange creates a new IP range in the network with the given ID.
+func (c *ClusterTx) CreateNetworkIPRange(ctx context.Context, networkID int64, info *api.NetworkIPRangesPost) (int64, error) {
+	projectID, err := networkIPRangeProjectID(ctx, c.tx, info.Project)
+	if err != nil {
+		return -1, err
+	}
+
+	result, err := c.tx.ExecContext(ctx, `
+		INSERT INTO networks_ip_ranges (network_id, name, description, start_address, end_address, project_id)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, networkID, info.Name, info.Description, info.Start, info.End, projectID)
+	if err != nil {
+		return -1, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return -1, err
+	}
+
+	return id, nil
+}
+
+// UpdateNetworkIPRange updates the IP range with the given ID.
+func (c *ClusterTx) UpdateNetworkIPRange(ctx context.Context, id int64, put api.NetworkIPRangePut) error {
+	projectID, err := networkIPRangeProjectID(ctx, c.tx, put.Project)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.tx.ExecContext(ctx, `
+		UPDATE networks_ip_ranges
+		SET description = ?, start_address = ?, end_address = ?, project_id = ?
+		WHERE id = ?
+	`, put.Description, put.Start, put.End, projectID, id)
+
+	return err
+}
+
+// DeleteNetworkIPRange deletes the IP range with the given ID.
+func (c *ClusterTx) DeleteNetworkIPRange(ctx context.Context, id int64) error {
+	_, err := c.tx.ExecContext(ctx, "DELETE FROM networks_ip_ranges WHERE id = ?", id)
+
+	return err
+}
+
+// GetNetworkIPAllocations returns the addresses allocated in the network with the given ID.
+func (c *ClusterTx) GetNetworkIPAllocations(ctx context.Context, networkID int64) ([]*NetworkIPAllocation, error) {
+	q := `
+		SELECT
+			networks_ip_allocations.id,
+			networks_ip_allocations.address,
+			networks_ip_allocations.description,
+			projects.name,
+			IFNULL(networks_ip_allocations.instance_id, -1),
+			IFNULL(instances.name, ''),
+			IFNULL(instances_config.value, ''),
+			networks_ip_allocations.device_name
+		FROM networks_ip_allocations
+		JOIN projects ON projects.id = networks_ip_allocations.project_id
+		LEFT JOIN instances ON instances.id = networks_ip_allocations.instance_id
+		LEFT JOIN instances_config ON instances_config.instance_id = instances.id AND instances_config.key = 'volatile.uuid'
+		WHERE networks_ip_allocations.network_id = ?
+		ORDER BY networks_ip_allocations.id
+	`
+
+	var allocations []*NetworkIPAllocation
+
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		var allocation NetworkIPAllocation
+
+		err := scan(&allocation.ID, &allocation.Address, &allocation.Description, &allocation.Project, &allocation.InstanceID, &allocation.Instance, &allocation.InstanceUUID, &allocation.Device)
+		if err != nil {
+			return err
+		}
+
+		allocations = append(allocations, &allocation)
+
+		return nil
+	}, networkID)
+	if err != nil {
+		return nil, err
+	}
+
+	return allocations, nil
+}
+
+// CreateNetworkIPAllocation records an address allocated in the network with the given ID.
+// The instanceID should be -1 if the address isn't allocated to an instance NIC.
+func (c *ClusterTx) CreateNetworkIPAllocation(ctx context.Context, networkID int64, projectName string, instanceID int64, deviceName string, address string, description string) error {
+	var instID sql.NullInt64
+	if instanceID >= 0 {
+		instID = sql.NullInt64{Int64: instanceID, Valid: true}
+	}
+
+	_, err := c.tx.ExecContext(ctx, `
+		INSERT INTO networks_ip_allocations (network_id, project_id, address, description, instance_id, device_name)
+		VALUES (?, (SELECT id FROM projects WHERE name = ? LIMIT 1), ?, ?, ?, ?)
+	`, networkID, projectName, address, description, instID, deviceName)
+	if err != nil {
+		return fmt.Errorf("Failed recording allocation of address %q: %w", address, err)
+	}
+
+	return nil
+}
+
+// UpdateNetworkIPAllocationInstance moves the allocation with the given ID to another instance.
+func (c *ClusterTx) UpdateNetworkIPAllocationInstance(ctx context.Context, id int64, instanceID int64) error {
+	_, err := c.tx.ExecContext(ctx, "UPDATE networks_ip_allocations SET instance_id = ? WHERE id = ?", instanceID, id)
+
+	return err
+}
+
+// DeleteNetworkIPAllocation deletes the allocation with the given ID.
+func (c *ClusterTx) DeleteNetworkIPAllocation(ctx context.Context, id int64) error {
+	_, err := c.tx.ExecContext(ctx, "DELETE FROM networks_ip_allocations WHERE id = ?", id)
+
+	return err
+}
@@ -0,0 +1,105 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/canonical/lxd/lxd/db/query"
+)
+
+// NetworkLease represents a lease handed out by the native DHCP server of a network.
+type NetworkLease struct {
+	Hwaddr   string
+	ClientID string
+	Address  string
+	Hostname string
+	Expiry   time.Time
+	Location string
+}
+
+// GetNetworkLeases returns the leases of the native DHCP server of a network.
+// If memberSpecific is true, then only the leases handed out by this member are returned.
+func (c *ClusterTx) GetNetworkLeases(ctx context.Context, networkID int64, memberSpecific bool) ([]NetworkLease, error) {
+	var q = &strings.Builder{}
+	args := []any{networkID}
+
+	q.WriteString(`
+	SELECT
+		networks_leases.hwaddr,
+		networks_leases.client_id,
+		networks_leases.address,
+		networks_leases.hostname,
+		networks_leases.expiry_date,
+		nodes.name
+	FROM networks_leases
+	JOIN nodes ON nodes.id = networks_leases.node_id
+	WHERE networks_leases.network_id = ?
+	`)
+
+	if memberSpecific {
+		q.WriteString("AND networks_leases.node_id = ? ")
+		args = append(args, c.nodeID)
+	}
+
+	q.WriteString("ORDER BY networks_leases.id")
+
+	leases := []NetworkLease{}
+
+	err := query.Scan(ctx, c.tx, q.String(), func(scan func(dest ...any) error) error {
+		var lease NetworkLease
+		var expiry sql.NullTime
+
+		err := scan(&lease.Hwaddr, &lease.ClientID, &lease.Address, &lease.Hostname, &expiry, &lease.Location)
+		if err != nil {
+			return err
+		}
+
+		if expiry.Valid {
+			lease.Expiry = expiry.Time
+		}
+
+		leases = append(leases, lease)
+
+		return nil
+	}, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return leases, nil
+}
+
+// UpsertNetworkLease records a lease handed out by the native DHCP server of a network on this member, replacing
+// any existing lease of the same address.
+func (c *ClusterTx) UpsertNetworkLease(ctx context.Context, networkID int64, lease NetworkLease) error {
+	var expiry any
+	if !lease.Expiry.IsZero() {
+		expiry = lease.Expiry
+	}
+
+	_, err := c.tx.ExecContext(ctx, `
+		INSERT OR REPLACE INTO networks_leases
+		(network_id, node_id, hwaddr, client_id, address, hostname, expiry_date)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, networkID, c.nodeID, lease.Hwaddr, lease.ClientID, lease.Address, lease.Hostname, expiry)
+
+	return err
+}
+
+// DeleteNetworkLease deletes a lease handed out by the native DHCP server of a network on this member.
+func (c *ClusterTx) DeleteNetworkLease(ctx context.Context, networkID int64, address string) error {
+	_, err := c.tx.ExecContext(ctx, "DELETE FROM networks_leases WHERE network_id = ? AND node_id = ? AND address = ?", networkID, c.nodeID, address)
+
+	return err
+}
+
+// DeleteNetworkLeases deletes all the leases handed out by the native DHCP server of a network on this member.
+func (c *ClusterTx) DeleteNetworkLeases(ctx context.Context, networkID int64) error {
+	_, err := c.tx.ExecContext(ctx, "DELETE FROM networks_leases WHERE network_id = ? AND node_id = ?", networkID, c.nodeID)
+
+	return err
+}
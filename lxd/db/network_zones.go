@@ -287,6 +287,51 @@ func (c *Cluster) UpdateNetworkZone(id int64, config *api.NetworkZonePut) error
 	})
 }
 
+// GetNetworkZoneSerial returns the serial of the version of a zone with the given content hash.
+// If the content hash differs from the recorded one, the serial is increased (to at least minSerial) and the new
+// content hash recorded. This way all cluster members hand out the same serial for the same zone content.
+func (c *ClusterTx) GetNetworkZoneSerial(ctx context.Context, name string, contentHash string, minSerial uint32) (uint32, error) {
+	q := `
+		SELECT networks_zones.id, IFNULL(networks_zones_serials.serial, 0), IFNULL(networks_zones_serials.content_hash, '')
+		FROM networks_zones
+		LEFT JOIN networks_zones_serials ON networks_zones_serials.network_zone_id=networks_zones.id
+		WHERE networks_zones.name=?
+		LIMIT 1
+	`
+
+	var id int64
+	var serial uint32
+	var hash string
+
+	err := c.tx.QueryRowContext(ctx, q, name).Scan(&id, &serial, &hash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, api.StatusErrorf(http.StatusNotFound, "Network zone not found")
+		}
+
+		return 0, err
+	}
+
+	if hash == contentHash {
+		return serial, nil
+	}
+
+	serial++
+	if minSerial > serial {
+		serial = minSerial
+	}
+
+	_, err = c.tx.ExecContext(ctx, `
+		INSERT OR REPLACE INTO networks_zones_serials (network_zone_id, serial, content_hash)
+		VALUES (?, ?, ?)
+	`, id, serial, contentHash)
+	if err != nil {
+		return 0, err
+	}
+
+	return serial, nil
+}
+
 // DeleteNetworkZone deletes the Network zone.
 func (c *ClusterTx) DeleteNetworkZone(ctx context.Context, id int64) error {
 	_, err := c.tx.ExecContext(ctx, "DELETE FROM networks_zones WHERE id=?", id)
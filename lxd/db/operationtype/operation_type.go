@@ -76,6 +76,7 @@ const (
 	RenewServerCertificate
 	RemoveExpiredTokens
 	ClusterHeal
+	VolumeVerify
 )
 
 // Description return a human-readable description of the operation type.
@@ -199,6 +200,8 @@ func (t Type) Description() string {
 		return "Remove expired tokens"
 	case ClusterHeal:
 		return "Healing cluster"
+	case VolumeVerify:
+		return "Verifying storage volume"
 	default:
 		return "Executing operation"
 	}
@@ -280,6 +283,8 @@ func (t Type) Permission() (entity.Type, auth.Entitlement) {
 		return entity.TypeStorageVolume, auth.EntitlementCanManageBackups
 	case CustomVolumeBackupRestore:
 		return entity.TypeStorageVolume, auth.EntitlementCanEdit
+	case VolumeVerify:
+		return entity.TypeStorageVolume, auth.EntitlementCanEdit
 	}
 
 	return "", ""
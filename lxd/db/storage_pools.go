@@ -905,6 +905,12 @@ var NodeSpecificStorageConfig = []string{
 	"lvm.vg_name",
 }
 
+// InternalStorageConfig lists all storage pool config keys which are managed by LXD and are never exposed
+// through the API, such as the key used to encrypt the pool's volumes.
+var InternalStorageConfig = []string{
+	"volatile.encryption.key",
+}
+
 // IsRemoteStorage return whether a given pool is backed by remote storage.
 func (c *ClusterTx) IsRemoteStorage(ctx context.Context, poolID int64) (bool, error) {
 	driver, err := c.GetStoragePoolDriver(ctx, poolID)
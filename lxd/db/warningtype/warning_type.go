@@ -58,6 +58,14 @@ const (
 	StoragePoolUnvailable
 	// UnableToUpdateClusterCertificate represents the unable to update cluster certificate warning.
 	UnableToUpdateClusterCertificate
+	// ScheduledBackupFailure represents the failure of a scheduled instance or custom volume backup.
+	ScheduledBackupFailure
+	// StorageVolumeVerificationFailure represents problems found while verifying the integrity of a storage volume.
+	StorageVolumeVerificationFailure
+	// StoragePoolLowSpace represents the free space on a storage pool falling below its warning threshold.
+	StoragePoolLowSpace
+	// FirewallRulesDrift represents firewall rules that went missing from the live firewall state.
+	FirewallRulesDrift
 )
 
 // TypeNames associates a warning code to its name.
@@ -88,6 +96,10 @@ var TypeNames = map[Type]string{
 	InstanceTypeNotOperational:             "Instance type not operational",
 	StoragePoolUnvailable:                  "Storage pool unavailable",
 	UnableToUpdateClusterCertificate:       "Unable to update cluster certificate",
+	ScheduledBackupFailure:                 "Failed to create scheduled backup",
+	StorageVolumeVerificationFailure:       "Storage volume integrity problems found",
+	StoragePoolLowSpace:                    "Storage pool is running low on space",
+	FirewallRulesDrift:                     "Firewall rules drift detected",
 }
 
 // Severity returns the severity of the warning type.
@@ -145,6 +157,14 @@ func (t Type) Severity() Severity {
 		return SeverityHigh
 	case UnableToUpdateClusterCertificate:
 		return SeverityLow
+	case ScheduledBackupFailure:
+		return SeverityModerate
+	case StorageVolumeVerificationFailure:
+		return SeverityHigh
+	case StoragePoolLowSpace:
+		return SeverityModerate
+	case FirewallRulesDrift:
+		return SeverityModerate
 	}
 
 	return SeverityLow
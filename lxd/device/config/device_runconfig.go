@@ -50,6 +50,7 @@ type DiskLimits struct {
 	ReadIOps   int64
 	WriteBytes int64
 	WriteIOps  int64
+	Group      string // Name of the throttle group shared with the other disks using the same volume (optional).
 }
 
 // RunConfig represents run-time config used for device setup/cleanup.
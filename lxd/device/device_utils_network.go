@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"hash/crc32"
 	"net"
 	"net/netip"
 	"os"
@@ -17,6 +18,7 @@ import (
 
 	deviceConfig "github.com/canonical/lxd/lxd/device/config"
 	pcidev "github.com/canonical/lxd/lxd/device/pci"
+	"github.com/canonical/lxd/lxd/firewall"
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/ip"
@@ -392,6 +394,15 @@ func networkVethFillFromVolatile(device deviceConfig.Device, volatile map[string
 	if device["hwaddr"] == "" {
 		device["hwaddr"] = volatile["hwaddr"]
 	}
+
+	// If not configured, check if volatile data contains the addresses allocated from the network's IPAM.
+	if device["ipv4.address"] == "" {
+		device["ipv4.address"] = volatile["ipv4.address"]
+	}
+
+	if device["ipv6.address"] == "" {
+		device["ipv6.address"] = volatile["ipv6.address"]
+	}
 }
 
 // networkNICRouteAdd applies any static host-side routes configured for an instance NIC.
@@ -485,7 +496,186 @@ func networkNICRouteDelete(routeDev string, routes ...string) {
 	}
 }
 
+// networkShapingLock serialises the setup of the per-project traffic shaping devices.
+var networkShapingLock sync.Mutex
+
+// networkShapingMinRate is the rate of the per-project shaping class of NICs without a guaranteed rate.
+const networkShapingMinRate = "8kbit"
+
+// networkShapingQuantum is the quantum of the per-project shaping classes. Using the same quantum for all classes
+// shares the unused bandwidth of a project equally between the NICs borrowing it.
+const networkShapingQuantum = "1514"
+
+// networkShapingIFBName returns the name of the IFB device used to shape the aggregate traffic of the NICs of a
+// project in the specified direction ("ingress" or "egress"), from the instances' point of view.
+func networkShapingIFBName(projectName string, direction string) string {
+	return fmt.Sprintf("lxdsh%s%08x", direction[:1], crc32.ChecksumIEEE([]byte(projectName)))
+}
+
+// networkShapingClassID returns the ID of the class of a host side interface in the per-project IFB devices.
+// The class minor is derived from the interface index so that it is unique among the existing interfaces.
+func networkShapingClassID(hostName string) (string, error) {
+	iface, err := net.InterfaceByName(hostName)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("1:%x", (iface.Index%0xfffd)+2), nil
+}
+
+// networkSetupProjectShaping adds the class of a host side interface to the per-project IFB device of the specified
+// direction, creating the device if needed, and returns the name of the device to redirect the traffic to.
+// The class is guaranteed its rate and may borrow the unused bandwidth of the project up to its ceiling.
+func networkSetupProjectShaping(projectName string, direction string, classID string, projectLimit int64, limit int64, guaranteed int64) (string, error) {
+	networkShapingLock.Lock()
+	defer networkShapingLock.Unlock()
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	ifbName := networkShapingIFBName(projectName, direction)
+	if !network.InterfaceExists(ifbName) {
+		ifb := &ip.IFB{Link: ip.Link{Name: ifbName}}
+		err := ifb.Add()
+		if err != nil {
+			return "", fmt.Errorf("Failed to create shaping device %q: %w", ifbName, err)
+		}
+
+		revert.Add(func() { _ = ifb.Delete() })
+
+		err = ifb.SetUp()
+		if err != nil {
+			return "", fmt.Errorf("Failed to bring up shaping device %q: %w", ifbName, err)
+		}
+
+		qdiscHTB := &ip.QdiscHTB{Qdisc: ip.Qdisc{Dev: ifbName, Handle: "1:0", Root: true}}
+		err = qdiscHTB.Add()
+		if err != nil {
+			return "", fmt.Errorf("Failed to create root tc qdisc on shaping device %q: %w", ifbName, err)
+		}
+	}
+
+	// The root class enforces the project limit and is replaced so that it follows changes of the limit.
+	projectRate := fmt.Sprintf("%dbit", projectLimit)
+	rootClassHTB := &ip.ClassHTB{Class: ip.Class{Dev: ifbName, Parent: "1:0", Classid: "1:1"}, Rate: projectRate, Ceil: projectRate}
+	err := rootClassHTB.Replace()
+	if err != nil {
+		return "", fmt.Errorf("Failed to create project tc class on shaping device %q: %w", ifbName, err)
+	}
+
+	ceil := projectRate
+	if limit > 0 && limit < projectLimit {
+		ceil = fmt.Sprintf("%dbit", limit)
+	}
+
+	rate := networkShapingMinRate
+	if guaranteed > 0 {
+		rate = fmt.Sprintf("%dbit", min(guaranteed, projectLimit))
+	}
+
+	classHTB := &ip.ClassHTB{Class: ip.Class{Dev: ifbName, Parent: "1:1", Classid: classID}, Rate: rate, Ceil: ceil, Quantum: networkShapingQuantum}
+	err = classHTB.Replace()
+	if err != nil {
+		return "", fmt.Errorf("Failed to create limit tc class on shaping device %q: %w", ifbName, err)
+	}
+
+	revert.Success()
+	return ifbName, nil
+}
+
+// networkClearProjectShaping removes the class of a host side interface from the per-project IFB devices and
+// removes the devices that aren't used by any other interface anymore.
+func networkClearProjectShaping(projectName string, hostName string) error {
+	classID, err := networkShapingClassID(hostName)
+	if err != nil {
+		return nil // Nothing to clear if the interface doesn't exist anymore.
+	}
+
+	networkShapingLock.Lock()
+	defer networkShapingLock.Unlock()
+
+	for _, direction := range []string{"ingress", "egress"} {
+		ifbName := networkShapingIFBName(projectName, direction)
+		if !network.InterfaceExists(ifbName) {
+			continue
+		}
+
+		class := &ip.Class{Dev: ifbName, Classid: classID}
+		_ = class.Delete()
+
+		rootClass := &ip.Class{Dev: ifbName, Classid: "1:1"}
+		children, err := rootClass.Children()
+		if err != nil {
+			return fmt.Errorf("Failed listing tc classes on shaping device %q: %w", ifbName, err)
+		}
+
+		if len(children) == 0 {
+			err = network.InterfaceRemove(ifbName)
+			if err != nil {
+				return fmt.Errorf("Failed to remove shaping device %q: %w", ifbName, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// networkParseLimit parses the bit rate of a limit, returning 0 if the limit isn't set.
+func networkParseLimit(config map[string]string, key string) (int64, error) {
+	if config[key] == "" {
+		return 0, nil
+	}
+
+	limit, err := units.ParseBitSizeString(config[key])
+	if err != nil {
+		return 0, fmt.Errorf("Invalid %q value %q: %w", key, config[key], err)
+	}
+
+	return limit, nil
+}
+
+// networkHasLimits returns whether any limits are set in the NIC config.
+func networkHasLimits(config map[string]string) bool {
+	for _, key := range []string{"limits.ingress", "limits.egress", "limits.max", "limits.priority", "limits.ingress.guaranteed", "limits.egress.guaranteed", "limits.dscp"} {
+		if config[key] != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// networkValidateLimits checks that the guaranteed bandwidth of a NIC doesn't exceed its limits.
+func networkValidateLimits(config deviceConfig.Device) error {
+	for _, direction := range []string{"ingress", "egress"} {
+		limitKey := fmt.Sprintf("limits.%s", direction)
+		if config["limits.max"] != "" {
+			limitKey = "limits.max"
+		}
+
+		limit, err := networkParseLimit(config, limitKey)
+		if err != nil {
+			return err
+		}
+
+		guaranteedKey := fmt.Sprintf("limits.%s.guaranteed", direction)
+		guaranteed, err := networkParseLimit(config, guaranteedKey)
+		if err != nil {
+			return err
+		}
+
+		if limit > 0 && guaranteed > limit {
+			return fmt.Errorf("%q cannot be greater than %q", guaranteedKey, limitKey)
+		}
+	}
+
+	return nil
+}
+
 // networkSetupHostVethLimits applies any network rate limits to the veth device specified in the config.
+// Without a project limit, the traffic of the veth device is limited on the device itself. With a project limit,
+// the traffic is redirected to the per-project IFB device of its direction where it is shaped against the aggregate
+// traffic of the project's NICs on this member.
 func networkSetupHostVethLimits(d *deviceCommon, oldConfig deviceConfig.Device, bridged bool) error {
 	var err error
 
@@ -502,17 +692,17 @@ func networkSetupHostVethLimits(d *deviceCommon, oldConfig deviceConfig.Device,
 	}
 
 	// Parse the values
-	var ingressInt int64
-	if d.config["limits.ingress"] != "" {
-		ingressInt, err = units.ParseBitSizeString(d.config["limits.ingress"])
+	limits := map[string]int64{}
+	projectConfig := d.inst.Project().Config
+	for _, key := range []string{"limits.ingress", "limits.egress", "limits.ingress.guaranteed", "limits.egress.guaranteed"} {
+		limits[key], err = networkParseLimit(d.config, key)
 		if err != nil {
 			return err
 		}
 	}
 
-	var egressInt int64
-	if d.config["limits.egress"] != "" {
-		egressInt, err = units.ParseBitSizeString(d.config["limits.egress"])
+	for _, key := range []string{"limits.network.ingress", "limits.network.egress"} {
+		limits[key], err = networkParseLimit(projectConfig, key)
 		if err != nil {
 			return err
 		}
@@ -524,15 +714,63 @@ func networkSetupHostVethLimits(d *deviceCommon, oldConfig deviceConfig.Device,
 	qdisc = &ip.Qdisc{Dev: veth, Ingress: true}
 	_ = qdisc.Delete()
 
+	err = networkClearProjectShaping(d.inst.Project().Name, veth)
+	if err != nil {
+		return err
+	}
+
+	// The traffic redirected to the per-project IFB devices is classified from the clsact qdisc.
+	ingressParent := "ffff:0"
+	if limits["limits.network.ingress"] > 0 || limits["limits.network.egress"] > 0 {
+		ingressParent = "ffff:fff2"
+
+		qdisc = &ip.Qdisc{Dev: veth, Handle: "ffff:0", Clsact: true}
+		err := qdisc.Add()
+		if err != nil {
+			return fmt.Errorf("Failed to create clsact tc qdisc: %s", err)
+		}
+	} else if limits["limits.egress"] > 0 {
+		qdisc = &ip.Qdisc{Dev: veth, Handle: "ffff:0", Ingress: true}
+		err := qdisc.Add()
+		if err != nil {
+			return fmt.Errorf("Failed to create ingress tc qdisc: %s", err)
+		}
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	var classID string
+	if ingressParent != "ffff:0" {
+		classID, err = networkShapingClassID(veth)
+		if err != nil {
+			return err
+		}
+
+		revert.Add(func() { _ = networkClearProjectShaping(d.inst.Project().Name, veth) })
+	}
+
 	// Apply new limits
-	if d.config["limits.ingress"] != "" {
+	if limits["limits.network.ingress"] > 0 {
+		ifbName, err := networkSetupProjectShaping(d.inst.Project().Name, "ingress", classID, limits["limits.network.ingress"], limits["limits.ingress"], limits["limits.ingress.guaranteed"])
+		if err != nil {
+			return err
+		}
+
+		actions := []ip.Action{&ip.ActionSkbedit{Priority: classID}, &ip.ActionMirred{Dev: ifbName}}
+		filter := &ip.U32Filter{Filter: ip.Filter{Dev: veth, Parent: "ffff:fff3", Protocol: "all"}, Value: "0", Mask: "0", Actions: actions}
+		err = filter.Add()
+		if err != nil {
+			return fmt.Errorf("Failed to create egress tc filter: %s", err)
+		}
+	} else if limits["limits.ingress"] > 0 {
 		qdiscHTB := &ip.QdiscHTB{Qdisc: ip.Qdisc{Dev: veth, Handle: "1:0", Root: true}, Default: "10"}
 		err := qdiscHTB.Add()
 		if err != nil {
 			return fmt.Errorf("Failed to create root tc qdisc: %s", err)
 		}
 
-		classHTB := &ip.ClassHTB{Class: ip.Class{Dev: veth, Parent: "1:0", Classid: "1:10"}, Rate: fmt.Sprintf("%dbit", ingressInt)}
+		classHTB := &ip.ClassHTB{Class: ip.Class{Dev: veth, Parent: "1:0", Classid: "1:10"}, Rate: fmt.Sprintf("%dbit", limits["limits.ingress"])}
 		err = classHTB.Add()
 		if err != nil {
 			return fmt.Errorf("Failed to create limit tc class: %s", err)
@@ -545,22 +783,55 @@ func networkSetupHostVethLimits(d *deviceCommon, oldConfig deviceConfig.Device,
 		}
 	}
 
-	if d.config["limits.egress"] != "" {
-		qdisc = &ip.Qdisc{Dev: veth, Handle: "ffff:0", Ingress: true}
-		err := qdisc.Add()
+	if limits["limits.network.egress"] > 0 {
+		ifbName, err := networkSetupProjectShaping(d.inst.Project().Name, "egress", classID, limits["limits.network.egress"], limits["limits.egress"], limits["limits.egress.guaranteed"])
 		if err != nil {
-			return fmt.Errorf("Failed to create ingress tc qdisc: %s", err)
+			return err
 		}
 
-		police := &ip.ActionPolice{Rate: fmt.Sprintf("%dbit", egressInt), Burst: "1024k", Mtu: "64kb", Drop: true}
-		filter := &ip.U32Filter{Filter: ip.Filter{Dev: veth, Parent: "ffff:0", Protocol: "all"}, Value: "0", Mask: "0", Actions: []ip.Action{police}}
+		actions := []ip.Action{&ip.ActionSkbedit{Priority: classID}, &ip.ActionMirred{Dev: ifbName}}
+		filter := &ip.U32Filter{Filter: ip.Filter{Dev: veth, Parent: ingressParent, Protocol: "all"}, Value: "0", Mask: "0", Actions: actions}
 		err = filter.Add()
 		if err != nil {
 			return fmt.Errorf("Failed to create ingress tc filter: %s", err)
 		}
+	} else if limits["limits.egress"] > 0 {
+		police := &ip.ActionPolice{Rate: fmt.Sprintf("%dbit", limits["limits.egress"]), Burst: "1024k", Mtu: "64kb", Drop: true}
+		filter := &ip.U32Filter{Filter: ip.Filter{Dev: veth, Parent: ingressParent, Protocol: "all"}, Value: "0", Mask: "0", Actions: []ip.Action{police}}
+		err = filter.Add()
+		if err != nil {
+			return fmt.Errorf("Failed to create ingress tc filter: %s", err)
+		}
+	}
+
+	netPrioChanged := oldConfig != nil && (oldConfig["limits.priority"] != d.config["limits.priority"] || oldConfig["limits.dscp"] != d.config["limits.dscp"])
+	if netPrioChanged {
+		err = d.state.Firewall.InstanceClearNetPrio(d.inst.Project().Name, d.inst.Name(), d.name, veth)
+		if err != nil {
+			return err
+		}
 	}
 
+	if oldConfig == nil || netPrioChanged {
+		if bridged && d.state.Firewall.String() == "xtables" && (d.config["limits.priority"] != "" || d.config["limits.dscp"] != "") {
+			return fmt.Errorf("Failed to setup instance device network priority. The xtables firewall driver does not support required functionality.")
+		}
+
+		err = networkSetupHostVethNetPrio(d, d.state.Firewall, veth)
+		if err != nil {
+			return fmt.Errorf("Failed to setup instance device network priority: %w", err)
+		}
+	}
+
+	revert.Success()
+	return nil
+}
+
+// networkSetupHostVethNetPrio sets up the network priority and DSCP marking of the traffic of the veth device using
+// the provided firewall driver, if any are set in the config.
+func networkSetupHostVethNetPrio(d *deviceCommon, fw firewall.Driver, veth string) error {
 	var networkPriority uint64
+	var err error
 	if d.config["limits.priority"] != "" {
 		networkPriority, err = strconv.ParseUint(d.config["limits.priority"], 10, 32)
 		if err != nil {
@@ -568,37 +839,126 @@ func networkSetupHostVethLimits(d *deviceCommon, oldConfig deviceConfig.Device,
 		}
 	}
 
-	if oldConfig != nil && oldConfig["limits.priority"] != d.config["limits.priority"] {
-		err = d.state.Firewall.InstanceClearNetPrio(d.inst.Project().Name, d.inst.Name(), veth)
+	dscp := -1
+	if d.config["limits.dscp"] != "" {
+		dscp, err = strconv.Atoi(d.config["limits.dscp"])
 		if err != nil {
-			return err
+			return fmt.Errorf("Failed to parse limits.dscp %q: %w", d.config["limits.dscp"], err)
 		}
 	}
 
-	if oldConfig == nil || oldConfig["limits.priority"] != d.config["limits.priority"] {
-		if networkPriority != 0 {
-			if bridged && d.state.Firewall.String() == "xtables" {
-				return fmt.Errorf("Failed to setup instance device network priority. The xtables firewall driver does not support required functionality.")
+	if networkPriority == 0 && dscp < 0 {
+		return nil
+	}
+
+	return fw.InstanceSetupNetPrio(d.inst.Project().Name, d.inst.Name(), d.name, veth, uint32(networkPriority), dscp)
+}
+
+// networkClearHostVethLimits clears any network rate limits to the veth device specified in the config.
+func networkClearHostVethLimits(d *deviceCommon) error {
+	err := d.state.Firewall.InstanceClearNetPrio(d.inst.Project().Name, d.inst.Name(), d.name, d.config["host_name"])
+	if err != nil {
+		return err
+	}
+
+	err = networkClearProjectShaping(d.inst.Project().Name, d.config["host_name"])
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// NetworkUpdateProjectShaping re-applies the limits of the host side interfaces of a running instance's NICs so that
+// they follow changes of the project's aggregate bandwidth limits. The interfaces are moved into or out of the
+// per-project IFB devices, which are removed once unused.
+func NetworkUpdateProjectShaping(s *state.State, inst instance.Instance) error {
+	for devName, devConfig := range inst.ExpandedDevices() {
+		if devConfig["type"] != "nic" {
+			continue
+		}
+
+		volatileGet := func() map[string]string {
+			prefix := fmt.Sprintf("volatile.%s.", devName)
+			volatile := make(map[string]string)
+			for key, value := range inst.LocalConfig() {
+				if strings.HasPrefix(key, prefix) {
+					volatile[strings.TrimPrefix(key, prefix)] = value
+				}
 			}
 
-			err = d.state.Firewall.InstanceSetupNetPrio(d.inst.Project().Name, d.inst.Name(), veth, uint32(networkPriority))
-			if err != nil {
-				return fmt.Errorf("Failed to setup instance device network priority: %w", err)
+			return volatile
+		}
+
+		dev, err := load(inst, s, inst.Project().Name, devName, devConfig.Clone(), volatileGet, nil)
+		if err != nil {
+			return err
+		}
+
+		var d *deviceCommon
+		var bridged bool
+		switch nic := dev.(type) {
+		case *nicBridged:
+			d = &nic.deviceCommon
+			bridged = true
+		case *nicOVN:
+			if nic.config["nested"] != "" || shared.ValueInSlice(nic.config["acceleration"], []string{"sriov", "vdpa"}) {
+				continue
 			}
+
+			d = &nic.deviceCommon
+			bridged = true
+		case *nicP2P:
+			d = &nic.deviceCommon
+		case *nicRouted:
+			d = &nic.deviceCommon
+		default:
+			continue // NIC type without a host side interface to shape.
+		}
+
+		networkVethFillFromVolatile(d.config, volatileGet())
+		if d.config["host_name"] == "" || !network.InterfaceExists(d.config["host_name"]) {
+			continue
+		}
+
+		// The unchanged config is passed as the old config so that the network priority is kept as is.
+		err = networkSetupHostVethLimits(d, d.config.Clone(), bridged)
+		if err != nil {
+			return fmt.Errorf("Failed updating limits of device %q: %w", devName, err)
 		}
 	}
 
 	return nil
 }
 
-// networkClearHostVethLimits clears any network rate limits to the veth device specified in the config.
-func networkClearHostVethLimits(d *deviceCommon) error {
-	err := d.state.Firewall.InstanceClearNetPrio(d.inst.Project().Name, d.inst.Name(), d.config["host_name"])
+// NetworkHostShapingCounters returns the traffic shaping statistics of the host side interface of a NIC for the
+// inbound and outbound traffic of the instance.
+func NetworkHostShapingCounters(projectName string, hostName string) (*ip.Stats, *ip.Stats, error) {
+	classID, err := networkShapingClassID(hostName)
 	if err != nil {
-		return err
+		return nil, nil, err
+	}
+
+	// Traffic shaped against a project limit is accounted in the class of the interface in the IFB device,
+	// otherwise in the qdisc of the interface itself.
+	stats := make(map[string]*ip.Stats, 2)
+	for direction, qdisc := range map[string]*ip.Qdisc{"ingress": {Dev: hostName, Root: true}, "egress": {Dev: hostName, Ingress: true}} {
+		ifbName := networkShapingIFBName(projectName, direction)
+		if network.InterfaceExists(ifbName) {
+			class := &ip.Class{Dev: ifbName, Classid: classID}
+			stats[direction], err = class.Stats()
+			if err == nil {
+				continue
+			}
+		}
+
+		stats[direction], err = qdisc.Stats()
+		if err != nil {
+			stats[direction] = &ip.Stats{}
+		}
 	}
 
-	return nil
+	return stats["ingress"], stats["egress"], nil
 }
 
 // networkValidGateway validates the gateway value.
@@ -682,6 +1042,107 @@ func bgpRemovePrefix(d *deviceCommon, config map[string]string) error {
 	return nil
 }
 
+// networkIPAMAllocate records the addresses of the NIC in the IPAM of its managed network.
+// When the NIC doesn't specify an address and the instance's project has ranges delegated to it, an address is
+// picked from them and stored in the volatile data of the NIC. Returns true if the addresses of the NIC changed.
+func networkIPAMAllocate(d *deviceCommon, n network.Network) (bool, error) {
+	if n == nil || !n.IsManaged() || !n.Info().IPAM {
+		return false, nil
+	}
+
+	volatile := d.volatileGet()
+	volatileSave := map[string]string{}
+
+	var addresses []net.IP
+	var autoFamilies []uint
+
+	for _, family := range []uint{4, 6} {
+		key := fmt.Sprintf("ipv%d.address", family)
+
+		address := d.config[key]
+		if address == "" && volatile[key] != "" {
+			address = volatile[key]
+			d.config[key] = address
+		}
+
+		if address == "none" || (address != "" && address != volatile[key]) {
+			// Forget any previously picked address if the NIC doesn't use it anymore.
+			if volatile[key] != "" {
+				volatileSave[key] = ""
+			}
+
+			if address != "none" {
+				addresses = append(addresses, net.ParseIP(address))
+			}
+
+			continue
+		}
+
+		if address != "" {
+			addresses = append(addresses, net.ParseIP(address))
+			continue
+		}
+
+		// Only pick addresses that can be handed out by the network's DHCP server.
+		if family == 4 && n.DHCPv4Subnet() != nil {
+			autoFamilies = append(autoFamilies, family)
+		} else if family == 6 && n.DHCPv6Subnet() != nil && shared.IsTrue(n.Config()["ipv6.dhcp.stateful"]) {
+			autoFamilies = append(autoFamilies, family)
+		}
+	}
+
+	nic := network.IPAMNIC{
+		ProjectName:  d.inst.Project().Name,
+		InstanceID:   int64(d.inst.ID()),
+		InstanceUUID: d.inst.LocalConfig()["volatile.uuid"],
+		DeviceName:   d.name,
+	}
+
+	allocated, err := network.IPAMAllocateNIC(d.state, n, nic, addresses, autoFamilies...)
+	if err != nil {
+		return false, fmt.Errorf("Failed allocating addresses in network %q: %w", n.Name(), err)
+	}
+
+	// Store the picked addresses.
+	for _, address := range allocated {
+		key := "ipv6.address"
+		if address.To4() != nil {
+			key = "ipv4.address"
+		}
+
+		if d.config[key] == "" {
+			volatileSave[key] = address.String()
+			d.config[key] = address.String()
+		}
+	}
+
+	if len(volatileSave) == 0 {
+		return false, nil
+	}
+
+	err = d.volatileSet(volatileSave)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// networkIPAMRelease releases the addresses of the NIC in the IPAM of its managed network.
+func networkIPAMRelease(d *deviceCommon, n network.Network) error {
+	if n == nil || !n.IsManaged() || !n.Info().IPAM {
+		return nil
+	}
+
+	nic := network.IPAMNIC{
+		ProjectName: d.inst.Project().Name,
+		InstanceID:  int64(d.inst.ID()),
+		DeviceName:  d.name,
+	}
+
+	return network.IPAMReleaseNIC(d.state, n, nic)
+}
+
 // networkSRIOVParentVFInfo returns info about an SR-IOV virtual function from the parent NIC using the ip tool.
 func networkSRIOVParentVFInfo(vfParent string, vfID int) (ip.VirtFuncInfo, error) {
 	link := &ip.Link{Name: vfParent}
@@ -24,6 +24,7 @@ import (
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/state"
 	storagePools "github.com/canonical/lxd/lxd/storage"
 	storageDrivers "github.com/canonical/lxd/lxd/storage/drivers"
 	"github.com/canonical/lxd/lxd/storage/filesystem"
@@ -441,6 +442,8 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 		return fmt.Errorf("Missing source path %q for disk %q", d.config["source"], d.name)
 	}
 
+	var dbCustomVolume *db.StorageVolume
+
 	// Check if validating a storage volume disk.
 	if d.config["pool"] != "" {
 		if d.config["shift"] != "" {
@@ -451,7 +454,6 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 			return fmt.Errorf("Storage volumes cannot be specified as absolute paths")
 		}
 
-		var dbCustomVolume *db.StorageVolume
 		var storageProjectName string
 
 		// Check if validating an instance or a custom storage volume attached to a profile.
@@ -523,6 +525,8 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 					}
 				} else if d.config["path"] == "" {
 					return fmt.Errorf("Custom filesystem volumes require a path to be defined")
+				} else if instConf.Type() == instancetype.VM && (dbCustomVolume.Config["limits.iops"] != "" || dbCustomVolume.Config["limits.bandwidth"] != "") {
+					return fmt.Errorf("Custom filesystem volumes with I/O limits cannot be used on virtual machines")
 				}
 			}
 
@@ -578,8 +582,9 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 			return fmt.Errorf("NVME disks aren't supported with migration.stateful=true")
 		}
 
-		if d.config["path"] != "/" && d.pool != nil && !d.pool.Driver().Info().Remote {
-			return fmt.Errorf("Only additional disks coming from a shared storage pool are supported with migration.stateful=true")
+		// Custom block volumes from local storage pools are mirrored to the target during live migration.
+		if d.config["path"] != "/" && d.pool != nil && !d.pool.Driver().Info().Remote && (dbCustomVolume == nil || dbCustomVolume.ContentType != cluster.StoragePoolVolumeContentTypeNameBlock) {
+			return fmt.Errorf("Only additional disks coming from a shared storage pool or custom block volumes are supported with migration.stateful=true")
 		}
 	}
 
@@ -956,18 +961,18 @@ func (d *disk) startVM() (*deviceConfig.RunConfig, error) {
 
 	// Add I/O limits if set.
 	var diskLimits *deviceConfig.DiskLimits
-	if d.config["limits.read"] != "" || d.config["limits.write"] != "" || d.config["limits.max"] != "" {
-		// Parse the limits into usable values.
-		readBps, readIops, writeBps, writeIops, err := d.parseLimit(d.config)
-		if err != nil {
-			return nil, err
-		}
+	limit, group, err := d.ioLimits(d.config)
+	if err != nil {
+		return nil, err
+	}
 
+	if limit != (diskBlockLimit{}) {
 		diskLimits = &deviceConfig.DiskLimits{
-			ReadBytes:  readBps,
-			ReadIOps:   readIops,
-			WriteBytes: writeBps,
-			WriteIOps:  writeIops,
+			ReadBytes:  limit.readBps,
+			ReadIOps:   limit.readIops,
+			WriteBytes: limit.writeBps,
+			WriteIOps:  limit.writeIops,
+			Group:      group,
 		}
 	}
 
@@ -1340,42 +1345,81 @@ func (d *disk) Update(oldDevices deviceConfig.Devices, isRunning bool) error {
 
 	// Only apply IO limits if instance is running.
 	if isRunning {
-		runConf := deviceConfig.RunConfig{}
+		err := d.applyLimits()
+		if err != nil {
+			return err
+		}
+	}
 
-		if d.inst.Type() == instancetype.Container {
-			err := d.generateLimits(&runConf)
-			if err != nil {
-				return err
-			}
+	return nil
+}
+
+// applyLimits applies the I/O limits of the disk to the running instance.
+func (d *disk) applyLimits() error {
+	runConf := deviceConfig.RunConfig{}
+
+	if d.inst.Type() == instancetype.Container {
+		err := d.generateLimits(&runConf)
+		if err != nil {
+			return err
 		}
+	}
 
-		if d.inst.Type() == instancetype.VM {
-			// Parse the limits into usable values.
-			readBps, readIops, writeBps, writeIops, err := d.parseLimit(d.config)
-			if err != nil {
-				return err
-			}
+	if d.inst.Type() == instancetype.VM {
+		// Parse the limits into usable values.
+		limit, group, err := d.ioLimits(d.config)
+		if err != nil {
+			return err
+		}
 
-			// Apply the limits to a minimal mount entry.
-			diskLimits := &deviceConfig.DiskLimits{
-				ReadBytes:  readBps,
-				ReadIOps:   readIops,
-				WriteBytes: writeBps,
-				WriteIOps:  writeIops,
-			}
+		// Apply the limits to a minimal mount entry.
+		diskLimits := &deviceConfig.DiskLimits{
+			ReadBytes:  limit.readBps,
+			ReadIOps:   limit.readIops,
+			WriteBytes: limit.writeBps,
+			WriteIOps:  limit.writeIops,
+			Group:      group,
+		}
 
-			runConf.Mounts = []deviceConfig.MountEntryItem{
-				{
-					DevName: d.name,
-					Limits:  diskLimits,
-				},
-			}
+		runConf.Mounts = []deviceConfig.MountEntryItem{
+			{
+				DevName: d.name,
+				Limits:  diskLimits,
+			},
+		}
+	}
+
+	return d.inst.DeviceEventHandler(&runConf)
+}
+
+// DiskApplyLimits applies the I/O limits of the given disk devices of a running instance again.
+// This is used when the limits of the custom volumes used by the devices change.
+func DiskApplyLimits(s *state.State, inst instance.Instance, devNames []string) error {
+	for _, devName := range devNames {
+		conf, ok := inst.ExpandedDevices()[devName]
+		if !ok || conf["type"] != "disk" {
+			continue
 		}
 
-		err := d.inst.DeviceEventHandler(&runConf)
+		dev, err := load(inst, s, inst.Project().Name, devName, conf.Clone(), nil, nil)
 		if err != nil {
 			return err
 		}
+
+		d, ok := dev.(*disk)
+		if !ok {
+			continue
+		}
+
+		err = d.applyLimits()
+		if err != nil {
+			return fmt.Errorf("Failed applying limits of disk device %q: %w", devName, err)
+		}
+
+		// Container limits are generated for all the disk devices at once.
+		if inst.Type() == instancetype.Container {
+			break
+		}
 	}
 
 	return nil
@@ -1458,6 +1502,17 @@ func (d *disk) generateLimits(runConf *deviceConfig.RunConfig) error {
 
 		if dev["limits.read"] != "" || dev["limits.write"] != "" || dev["limits.max"] != "" {
 			hasDiskLimits = true
+			break
+		}
+
+		limit, _, err := d.ioLimits(dev)
+		if err != nil {
+			return err
+		}
+
+		if limit != (diskBlockLimit{}) {
+			hasDiskLimits = true
+			break
 		}
 	}
 
@@ -2104,11 +2159,13 @@ func (d *disk) getDiskLimits() (map[string]diskBlockLimit, error) {
 		}
 
 		// Parse the user input
-		readBps, readIops, writeBps, writeIops, err := d.parseLimit(dev)
+		limit, _, err := d.ioLimits(dev)
 		if err != nil {
 			return nil, err
 		}
 
+		readBps, readIops, writeBps, writeIops := limit.readBps, limit.readIops, limit.writeBps, limit.writeIops
+
 		// Set the source path
 		source := d.getDevicePath(devName, dev)
 		if dev["source"] == "" {
@@ -2260,6 +2317,84 @@ func (d *disk) parseLimit(dev deviceConfig.Device) (readBps int64, readIops int6
 	return readBps, readIops, writeBps, writeIops, nil
 }
 
+// ioLimits returns the I/O limits of the given disk device, combining the limits set on the device with the ones
+// set on the custom volume it uses (the lowest one applies). When the volume has limits, the name of the throttle
+// group shared by the disks using it is returned too.
+func (d *disk) ioLimits(dev deviceConfig.Device) (diskBlockLimit, string, error) {
+	var limit diskBlockLimit
+	var err error
+
+	limit.readBps, limit.readIops, limit.writeBps, limit.writeIops, err = d.parseLimit(dev)
+	if err != nil {
+		return diskBlockLimit{}, "", err
+	}
+
+	// Only custom volumes have volume limits.
+	if dev["pool"] == "" || dev["source"] == "" || instancetype.IsRootDiskDevice(dev) {
+		return limit, "", nil
+	}
+
+	pool, err := storagePools.LoadByName(d.state, dev["pool"])
+	if err != nil {
+		return diskBlockLimit{}, "", err
+	}
+
+	storageProjectName, err := project.StorageVolumeProject(d.state.DB.Cluster, d.inst.Project().Name, cluster.StoragePoolVolumeTypeCustom)
+	if err != nil {
+		return diskBlockLimit{}, "", err
+	}
+
+	var dbVolume *db.StorageVolume
+	err = d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		dbVolume, err = tx.GetStoragePoolVolume(ctx, pool.ID(), storageProjectName, cluster.StoragePoolVolumeTypeCustom, dev["source"], true)
+		return err
+	})
+	if err != nil {
+		return diskBlockLimit{}, "", fmt.Errorf("Failed loading custom volume: %w", err)
+	}
+
+	// Filesystem volumes are shared with virtual machines through virtiofs which can't be throttled.
+	if d.inst.Type() == instancetype.VM && dbVolume.ContentType == cluster.StoragePoolVolumeContentTypeNameFS {
+		return limit, "", nil
+	}
+
+	var volBps, volIops int64
+
+	if dbVolume.Config["limits.bandwidth"] != "" {
+		volBps, err = units.ParseByteSizeString(dbVolume.Config["limits.bandwidth"])
+		if err != nil {
+			return diskBlockLimit{}, "", fmt.Errorf("Invalid limits.bandwidth on custom volume %q: %w", dev["source"], err)
+		}
+	}
+
+	if dbVolume.Config["limits.iops"] != "" {
+		volIops, err = strconv.ParseInt(dbVolume.Config["limits.iops"], 10, 64)
+		if err != nil {
+			return diskBlockLimit{}, "", fmt.Errorf("Invalid limits.iops on custom volume %q: %w", dev["source"], err)
+		}
+	}
+
+	if volBps == 0 && volIops == 0 {
+		return limit, "", nil
+	}
+
+	// lowest returns the lowest of the two limits, ignoring unset (zero) ones.
+	lowest := func(a int64, b int64) int64 {
+		if a == 0 || (b != 0 && b < a) {
+			return b
+		}
+
+		return a
+	}
+
+	limit.readBps = lowest(limit.readBps, volBps)
+	limit.writeBps = lowest(limit.writeBps, volBps)
+	limit.readIops = lowest(limit.readIops, volIops)
+	limit.writeIops = lowest(limit.writeIops, volIops)
+
+	return limit, fmt.Sprintf("%s/%s", pool.Name(), project.StorageVolume(storageProjectName, dev["source"])), nil
+}
+
 func (d *disk) getParentBlocks(path string) ([]string, error) {
 	var devices []string
 	var dev []string
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/canonical/lxd/lxd/dhcpd"
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/network/acl"
 	"github.com/canonical/lxd/shared"
@@ -191,7 +192,7 @@ func nicValidationRules(requiredFields []string, optionalFields []string, instCo
 		//  defaultdesc: randomly assigned
 		//  shortdesc: Name of the interface inside the host
 		"host_name": validate.IsAny,
-		// lxdmeta:generate(entities=device-nic-bridged; group=device-conf; key=limits.ingress)
+		// lxdmeta:generate(entities=device-nic-{bridged+ovn}; group=device-conf; key=limits.ingress)
 		// Specify the limit in bit/s. Various suffixes are supported (see {ref}`instances-limit-units`).
 		// ---
 		//  type: string
@@ -204,7 +205,7 @@ func nicValidationRules(requiredFields []string, optionalFields []string, instCo
 		//  type: string
 		//  shortdesc: I/O limit for incoming traffic
 		"limits.ingress": validate.IsAny,
-		// lxdmeta:generate(entities=device-nic-bridged; group=device-conf; key=limits.egress)
+		// lxdmeta:generate(entities=device-nic-{bridged+ovn}; group=device-conf; key=limits.egress)
 		// Specify the limit in bit/s. Various suffixes are supported (see {ref}`instances-limit-units`).
 		// ---
 		//  type: string
@@ -217,7 +218,7 @@ func nicValidationRules(requiredFields []string, optionalFields []string, instCo
 		//  type: string
 		//  shortdesc: I/O limit for outgoing traffic
 		"limits.egress": validate.IsAny,
-		// lxdmeta:generate(entities=device-nic-bridged; group=device-conf; key=limits.max)
+		// lxdmeta:generate(entities=device-nic-{bridged+ovn}; group=device-conf; key=limits.max)
 		// This option is the same as setting both {config:option}`device-nic-bridged-device-conf:limits.ingress` and {config:option}`device-nic-bridged-device-conf:limits.egress`.
 		//
 		// Specify the limit in bit/s. Various suffixes are supported (see {ref}`instances-limit-units`).
@@ -234,7 +235,7 @@ func nicValidationRules(requiredFields []string, optionalFields []string, instCo
 		//  type: string
 		//  shortdesc: I/O limit for both incoming and outgoing traffic
 		"limits.max": validate.IsAny,
-		// lxdmeta:generate(entities=device-nic-bridged; group=device-conf; key=limits.priority)
+		// lxdmeta:generate(entities=device-nic-{bridged+ovn}; group=device-conf; key=limits.priority)
 		// The `skb->priority` value for outgoing traffic is used by the kernel queuing discipline (qdisc) to prioritize network packets.
 		// Specify the value as a 32-bit unsigned integer.
 		//
@@ -255,6 +256,63 @@ func nicValidationRules(requiredFields []string, optionalFields []string, instCo
 		//  type: integer
 		//  shortdesc: `skb->priority` value for outgoing traffic
 		"limits.priority": validate.Optional(validate.IsUint32),
+		// lxdmeta:generate(entities=device-nic-{bridged+ovn}; group=device-conf; key=limits.ingress.guaranteed)
+		// This rate is guaranteed to the NIC when the project's {config:option}`project-limits:limits.network.ingress` limit is reached.
+		// Above it, the NIC can use the project's unused bandwidth up to {config:option}`device-nic-bridged-device-conf:limits.ingress`.
+		//
+		// Specify the limit in bit/s. Various suffixes are supported (see {ref}`instances-limit-units`).
+		// See {ref}`devices-nic-limits` for more information.
+		// ---
+		//  type: string
+		//  managed: no
+		//  shortdesc: Guaranteed bandwidth for incoming traffic
+
+		// lxdmeta:generate(entities=device-nic-routed; group=device-conf; key=limits.ingress.guaranteed)
+		// This rate is guaranteed to the NIC when the project's {config:option}`project-limits:limits.network.ingress` limit is reached.
+		// Above it, the NIC can use the project's unused bandwidth up to {config:option}`device-nic-routed-device-conf:limits.ingress`.
+		//
+		// Specify the limit in bit/s. Various suffixes are supported (see {ref}`instances-limit-units`).
+		// See {ref}`devices-nic-limits` for more information.
+		// ---
+		//  type: string
+		//  shortdesc: Guaranteed bandwidth for incoming traffic
+		"limits.ingress.guaranteed": validate.Optional(validate.IsBitSize),
+		// lxdmeta:generate(entities=device-nic-{bridged+ovn}; group=device-conf; key=limits.egress.guaranteed)
+		// This rate is guaranteed to the NIC when the project's {config:option}`project-limits:limits.network.egress` limit is reached.
+		// Above it, the NIC can use the project's unused bandwidth up to {config:option}`device-nic-bridged-device-conf:limits.egress`.
+		//
+		// Specify the limit in bit/s. Various suffixes are supported (see {ref}`instances-limit-units`).
+		// See {ref}`devices-nic-limits` for more information.
+		// ---
+		//  type: string
+		//  managed: no
+		//  shortdesc: Guaranteed bandwidth for outgoing traffic
+
+		// lxdmeta:generate(entities=device-nic-routed; group=device-conf; key=limits.egress.guaranteed)
+		// This rate is guaranteed to the NIC when the project's {config:option}`project-limits:limits.network.egress` limit is reached.
+		// Above it, the NIC can use the project's unused bandwidth up to {config:option}`device-nic-routed-device-conf:limits.egress`.
+		//
+		// Specify the limit in bit/s. Various suffixes are supported (see {ref}`instances-limit-units`).
+		// See {ref}`devices-nic-limits` for more information.
+		// ---
+		//  type: string
+		//  shortdesc: Guaranteed bandwidth for outgoing traffic
+		"limits.egress.guaranteed": validate.Optional(validate.IsBitSize),
+		// lxdmeta:generate(entities=device-nic-{bridged+ovn}; group=device-conf; key=limits.dscp)
+		// The DSCP value is set in the IPv4 and IPv6 headers of the outgoing traffic so that the network can prioritize it.
+		// Specify the value as an integer between 0 and 63.
+		// ---
+		//  type: integer
+		//  managed: no
+		//  shortdesc: DSCP value for outgoing traffic
+
+		// lxdmeta:generate(entities=device-nic-routed; group=device-conf; key=limits.dscp)
+		// The DSCP value is set in the IPv4 and IPv6 headers of the outgoing traffic so that the network can prioritize it.
+		// Specify the value as an integer between 0 and 63.
+		// ---
+		//  type: integer
+		//  shortdesc: DSCP value for outgoing traffic
+		"limits.dscp": validate.Optional(validate.IsInRange(0, 63)),
 		// lxdmeta:generate(entities=device-nic-{bridged+sriov}; group=device-conf; key=security.mac_filtering)
 		// Set this option to `true` to prevent the instance from spoofing another instance’s MAC address.
 		// ---
@@ -344,6 +402,17 @@ func nicValidationRules(requiredFields []string, optionalFields []string, instCo
 		//  type: string
 		//  shortdesc: IPv4 static addresses to add to the instance
 		"ipv4.address": validate.Optional(validate.IsNetworkAddressV4),
+		// lxdmeta:generate(entities=device-nic-bridged; group=device-conf; key=ipv4.dhcp.options)
+		// Specify a comma-separated list of `CODE=VALUE` entries, where the value is a space-separated list of IPv4 addresses, a hexadecimal string prefixed with `0x`, or a text string.
+		// This option is only supported when the parent network uses the native DHCP server (`dhcp.backend=native`).
+		// ---
+		//  type: string
+		//  managed: no
+		//  shortdesc: Additional DHCPv4 options to send to the instance
+		"ipv4.dhcp.options": validate.Optional(func(value string) error {
+			_, err := dhcpd.ParseOptions(value)
+			return err
+		}),
 		// lxdmeta:generate(entities=device-nic-bridged; group=device-conf; key=ipv6.address)
 		// Set this option to `none` to restrict all IPv6 traffic when {config:option}`device-nic-bridged-device-conf:security.ipv6_filtering` is set.
 		// ---
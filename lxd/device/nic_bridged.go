@@ -23,6 +23,7 @@ import (
 	deviceConfig "github.com/canonical/lxd/lxd/device/config"
 	"github.com/canonical/lxd/lxd/dnsmasq"
 	"github.com/canonical/lxd/lxd/dnsmasq/dhcpalloc"
+	"github.com/canonical/lxd/lxd/firewall"
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/ip"
@@ -39,6 +40,10 @@ import (
 
 type bridgeNetwork interface {
 	UsesDNSMasq() bool
+	UsesNativeDHCP() bool
+	LeaseAddresses(hwaddr string) ([]net.IP, error)
+	ClearLeases(hwaddr string, ipv4 bool, ipv6 bool) error
+	RefreshDHCPHosts() error
 }
 
 type nicBridged struct {
@@ -76,8 +81,12 @@ func (d *nicBridged) validateConfig(instConf instance.ConfigReader) error {
 		"limits.egress",
 		"limits.max",
 		"limits.priority",
+		"limits.ingress.guaranteed",
+		"limits.egress.guaranteed",
+		"limits.dscp",
 		"ipv4.address",
 		"ipv6.address",
+		"ipv4.dhcp.options",
 		"ipv4.routes",
 		"ipv6.routes",
 		"ipv4.routes.external",
@@ -98,12 +107,18 @@ func (d *nicBridged) validateConfig(instConf instance.ConfigReader) error {
 			return fmt.Errorf("Specified network is not fully created")
 		}
 
-		if n.Type() != "bridge" {
-			return fmt.Errorf("Specified network must be of type bridge")
+		if !shared.ValueInSlice(n.Type(), []string{"bridge", "wireguard"}) {
+			return fmt.Errorf("Specified network must be of type bridge or wireguard")
 		}
 
 		netConfig := n.Config()
 
+		// Check that the parent network hands out the DHCP options itself.
+		bridgeNet, ok := n.(bridgeNetwork)
+		if d.config["ipv4.dhcp.options"] != "" && (!ok || !bridgeNet.UsesNativeDHCP()) {
+			return fmt.Errorf(`Cannot specify "ipv4.dhcp.options" unless network %q uses the native DHCP server`, n.Name())
+		}
+
 		if d.config["ipv4.address"] != "" {
 			dhcpv4Subnet := n.DHCPv4Subnet()
 
@@ -119,7 +134,7 @@ func (d *nicBridged) validateConfig(instConf instance.ConfigReader) error {
 				return fmt.Errorf("Device IP address %q not within network %q subnet", d.config["ipv4.address"], n.Name())
 			}
 
-			parentAddress := netConfig["ipv4.address"]
+			parentAddress := n.AddressCIDR(4)
 			if shared.ValueInSlice(parentAddress, []string{"", "none"}) {
 				return nil
 			}
@@ -154,7 +169,7 @@ func (d *nicBridged) validateConfig(instConf instance.ConfigReader) error {
 				return fmt.Errorf("Device IP address %q not within network %q subnet", d.config["ipv6.address"], n.Name())
 			}
 
-			parentAddress := netConfig["ipv6.address"]
+			parentAddress := n.AddressCIDR(6)
 			if shared.ValueInSlice(parentAddress, []string{"", "none"}) {
 				return nil
 			}
@@ -256,6 +271,10 @@ func (d *nicBridged) validateConfig(instConf instance.ConfigReader) error {
 				return err
 			}
 		} else {
+			if d.config["ipv4.dhcp.options"] != "" {
+				return fmt.Errorf(`Cannot use "ipv4.dhcp.options" when using unmanaged parent bridge`)
+			}
+
 			// Check that static IPs are only specified with IP filtering when using an unmanaged
 			// parent bridge.
 			if shared.IsTrue(d.config["security.ipv4_filtering"]) {
@@ -353,6 +372,11 @@ func (d *nicBridged) validateConfig(instConf instance.ConfigReader) error {
 		return err
 	}
 
+	err = networkValidateLimits(d.config)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -457,15 +481,21 @@ func (d *nicBridged) UpdatableFields(oldDevice Type) []string {
 		return []string{}
 	}
 
-	return []string{"limits.ingress", "limits.egress", "limits.max", "limits.priority", "ipv4.routes", "ipv6.routes", "ipv4.routes.external", "ipv6.routes.external", "ipv4.address", "ipv6.address", "security.mac_filtering", "security.ipv4_filtering", "security.ipv6_filtering"}
+	return []string{"limits.ingress", "limits.egress", "limits.max", "limits.priority", "limits.ingress.guaranteed", "limits.egress.guaranteed", "limits.dscp", "ipv4.routes", "ipv6.routes", "ipv4.routes.external", "ipv6.routes.external", "ipv4.address", "ipv6.address", "security.mac_filtering", "security.ipv4_filtering", "security.ipv6_filtering"}
 }
 
 // Add is run when a device is added to a non-snapshot instance whether or not the instance is running.
 func (d *nicBridged) Add() error {
 	networkVethFillFromVolatile(d.config, d.volatileGet())
 
+	// Record the addresses of the NIC in the network's IPAM.
+	_, err := networkIPAMAllocate(&d.deviceCommon, d.network)
+	if err != nil {
+		return err
+	}
+
 	// Rebuild dnsmasq entry if needed and reload.
-	err := d.rebuildDnsmasqEntry()
+	err = d.rebuildDnsmasqEntry()
 	if err != nil {
 		return err
 	}
@@ -495,6 +525,12 @@ func (d *nicBridged) Start() (*deviceConfig.RunConfig, error) {
 		return nil, err
 	}
 
+	// Record the addresses of the NIC in the network's IPAM (picking one if needed).
+	ipamChanged, err := networkIPAMAllocate(&d.deviceCommon, d.network)
+	if err != nil {
+		return nil, err
+	}
+
 	revert := revert.New()
 	defer revert.Fail()
 
@@ -534,11 +570,11 @@ func (d *nicBridged) Start() (*deviceConfig.RunConfig, error) {
 	networkVethFillFromVolatile(d.config, saveData)
 
 	// Rebuild dnsmasq config if parent is a managed bridge network using dnsmasq and static lease file is
-	// missing.
+	// missing or the addresses allocated to the NIC changed.
 	bridgeNet, ok := d.network.(bridgeNetwork)
 	if ok && d.network.IsManaged() && bridgeNet.UsesDNSMasq() {
 		deviceStaticFileName := dnsmasq.DHCPStaticAllocationPath(d.network.Name(), dnsmasq.StaticAllocationFileName(d.inst.Project().Name, d.inst.Name(), d.Name()))
-		if !shared.PathExists(deviceStaticFileName) {
+		if ipamChanged || !shared.PathExists(deviceStaticFileName) {
 			err = d.rebuildDnsmasqEntry()
 			if err != nil {
 				return nil, fmt.Errorf("Failed creating DHCP static allocation: %w", err)
@@ -546,6 +582,14 @@ func (d *nicBridged) Start() (*deviceConfig.RunConfig, error) {
 		}
 	}
 
+	// Make sure the native DHCP server knows about the NIC before the instance asks for an address.
+	if ok && d.network.IsManaged() && bridgeNet.UsesNativeDHCP() {
+		err = bridgeNet.RefreshDHCPHosts()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Apply host-side routes to bridge interface.
 	routes := []string{}
 	routes = append(routes, shared.SplitNTrimSpace(d.config["ipv4.routes"], ",", -1, true)...)
@@ -626,22 +670,30 @@ func (d *nicBridged) Start() (*deviceConfig.RunConfig, error) {
 		}
 
 		if brNetfilterEnabled {
-			var listenAddresses map[int64]string
+			var forwardListenAddresses, loadBalancerListenAddresses map[int64]string
 
 			err = d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-				listenAddresses, err = tx.GetNetworkForwardListenAddresses(ctx, d.network.ID(), true)
+				forwardListenAddresses, err = tx.GetNetworkForwardListenAddresses(ctx, d.network.ID(), true)
+				if err != nil {
+					return fmt.Errorf("Failed loading network forwards: %w", err)
+				}
 
-				return err
+				loadBalancerListenAddresses, err = tx.GetNetworkLoadBalancerListenAddresses(ctx, d.network.ID(), true)
+				if err != nil {
+					return fmt.Errorf("Failed loading network load balancers: %w", err)
+				}
+
+				return nil
 			})
 			if err != nil {
-				return nil, fmt.Errorf("Failed loading network forwards: %w", err)
+				return nil, err
 			}
 
-			// If br_netfilter is enabled and bridge has forwards, we enable hairpin mode on NIC's
-			// bridge port in case any of the forwards target this NIC and the instance attempts to
-			// connect to the forward's listener. Without hairpin mode on the target of the forward
-			// will not be able to connect to the listener.
-			if len(listenAddresses) > 0 {
+			// If br_netfilter is enabled and bridge has forwards or load balancers, we enable hairpin
+			// mode on NIC's bridge port in case any of them target this NIC and the instance attempts to
+			// connect to the listener. Without hairpin mode on the target of the forward will not be
+			// able to connect to the listener.
+			if len(forwardListenAddresses) > 0 || len(loadBalancerListenAddresses) > 0 {
 				link := &ip.Link{Name: saveData["host_name"]}
 				err = link.BridgeLinkSetHairpin(true)
 				if err != nil {
@@ -700,6 +752,12 @@ func (d *nicBridged) Update(oldDevices deviceConfig.Devices, isRunning bool) err
 	networkVethFillFromVolatile(d.config, v)
 	networkVethFillFromVolatile(oldConfig, v)
 
+	// Record the new addresses of the NIC in the network's IPAM.
+	_, err := networkIPAMAllocate(&d.deviceCommon, d.network)
+	if err != nil {
+		return err
+	}
+
 	// If an IPv6 address has changed, flush all existing IPv6 leases for instance so instance
 	// isn't allocated old IP. This is important with IPv6 because DHCPv6 supports multiple IP
 	// address allocation and would result in instance having leases for both old and new IPs.
@@ -763,7 +821,7 @@ func (d *nicBridged) Update(oldDevices deviceConfig.Devices, isRunning bool) err
 	}
 
 	// Rebuild dnsmasq entry if needed and reload.
-	err := d.rebuildDnsmasqEntry()
+	err = d.rebuildDnsmasqEntry()
 	if err != nil {
 		return err
 	}
@@ -866,6 +924,11 @@ func (d *nicBridged) postStop() error {
 
 // Remove is run when the device is removed from the instance or the instance is deleted.
 func (d *nicBridged) Remove() error {
+	err := networkIPAMRelease(&d.deviceCommon, d.network)
+	if err != nil {
+		return err
+	}
+
 	if d.config["parent"] != "" {
 		dnsmasq.ConfigMutex.Lock()
 		defer dnsmasq.ConfigMutex.Unlock()
@@ -878,7 +941,7 @@ func (d *nicBridged) Remove() error {
 		}
 
 		// Remove dnsmasq config if it exists (doesn't return error if file is missing).
-		err := dnsmasq.RemoveStaticEntry(d.config["parent"], d.inst.Project().Name, d.inst.Name(), d.Name())
+		err = dnsmasq.RemoveStaticEntry(d.config["parent"], d.inst.Project().Name, d.inst.Name(), d.Name())
 		if err != nil {
 			return err
 		}
@@ -970,7 +1033,7 @@ func (d *nicBridged) setupHostFilters(oldConfig deviceConfig.Device) (revert.Hoo
 
 	// Setup network filters.
 	if shared.IsTrue(d.config["security.mac_filtering"]) || shared.IsTrue(d.config["security.ipv4_filtering"]) || shared.IsTrue(d.config["security.ipv6_filtering"]) {
-		err := d.setFilters()
+		err := d.setFilters(d.state.Firewall)
 		if err != nil {
 			return nil, err
 		}
@@ -1060,7 +1123,7 @@ func (d *nicBridged) removeFilters(m deviceConfig.Device) {
 
 // setFilters sets up any network level filters defined for the instance.
 // These are controlled by the security.mac_filtering, security.ipv4_Filtering and security.ipv6_filtering config keys.
-func (d *nicBridged) setFilters() (err error) {
+func (d *nicBridged) setFilters(fw firewall.Driver) (err error) {
 	if d.config["hwaddr"] == "" {
 		return fmt.Errorf("Failed to set network filters: require hwaddr defined")
 	}
@@ -1094,11 +1157,25 @@ func (d *nicBridged) setFilters() (err error) {
 		}
 	}
 
+	// The native DHCP server of a managed bridge doesn't pin dynamic addresses to the instance, so check that a
+	// manually specified IP is available if IP filtering enabled.
+	bridgeNet, ok := d.network.(bridgeNetwork)
+	nativeDHCP := ok && bridgeNet.UsesNativeDHCP()
+	if nativeDHCP {
+		if shared.IsTrue(d.config["security.ipv4_filtering"]) && d.config["ipv4.address"] == "" {
+			return fmt.Errorf("IPv4 filtering requires a manually specified ipv4.address when using the native DHCP server")
+		}
+
+		if shared.IsTrue(d.config["security.ipv6_filtering"]) && d.config["ipv6.address"] == "" {
+			return fmt.Errorf("IPv6 filtering requires a manually specified ipv6.address when using the native DHCP server")
+		}
+	}
+
 	// Use a clone of the config. This can be amended with the allocated IPs so that the correct ones are added to the firewall.
 	config := d.config.Clone()
 
 	// If parent bridge is managed, allocate the static IPs (if needed).
-	if d.network != nil && (IPv4 == nil || IPv6 == nil) {
+	if d.network != nil && !nativeDHCP && (IPv4 == nil || IPv6 == nil) {
 		opts := &dhcpalloc.Options{
 			ProjectName: d.inst.Project().Name,
 			HostName:    d.inst.Name(),
@@ -1139,17 +1216,17 @@ func (d *nicBridged) setFilters() (err error) {
 		}
 	}
 
-	// If anything goes wrong, clean up so we don't leave orphaned rules.
-	revert := revert.New()
-	defer revert.Fail()
-	revert.Add(func() { d.removeFilters(config) })
-
 	IPv4Nets, IPv6Nets, err := allowedIPNets(config)
 	if err != nil {
 		return err
 	}
 
-	err = d.state.Firewall.InstanceSetupBridgeFilter(d.inst.Project().Name, d.inst.Name(), d.name, d.config["parent"], d.config["host_name"], d.config["hwaddr"], IPv4Nets, IPv6Nets, d.network != nil)
+	// If anything goes wrong, clean up so we don't leave orphaned rules.
+	revert := revert.New()
+	defer revert.Fail()
+	revert.Add(func() { d.removeFilters(config) })
+
+	err = fw.InstanceSetupBridgeFilter(d.inst.Project().Name, d.inst.Name(), d.name, d.config["parent"], d.config["host_name"], d.config["hwaddr"], IPv4Nets, IPv6Nets, d.network != nil)
 	if err != nil {
 		return err
 	}
@@ -1220,8 +1297,13 @@ const (
 	clearLeaseIPv6Only
 )
 
-// networkClearLease clears leases from a running dnsmasq process.
+// networkClearLease clears leases from a running dnsmasq process (or from the native DHCP server).
 func (d *nicBridged) networkClearLease(name string, network string, hwaddr string, mode int) error {
+	bridgeNet, ok := d.network.(bridgeNetwork)
+	if ok && bridgeNet.UsesNativeDHCP() {
+		return bridgeNet.ClearLeases(hwaddr, mode != clearLeaseIPv6Only, mode != clearLeaseIPv4Only)
+	}
+
 	leaseFile := shared.VarPath("networks", network, "dnsmasq.leases")
 
 	// Check that we are in fact running a dnsmasq for the network
@@ -1628,9 +1710,8 @@ func (d *nicBridged) State() (*api.InstanceStateNetwork, error) {
 
 	if d.network != nil {
 		// Extract subnet sizes from bridge addresses if available.
-		netConfig := d.network.Config()
-		_, v4subnet, _ := net.ParseCIDR(netConfig["ipv4.address"])
-		_, v6subnet, _ := net.ParseCIDR(netConfig["ipv6.address"])
+		_, v4subnet, _ := net.ParseCIDR(d.network.AddressCIDR(4))
+		_, v6subnet, _ := net.ParseCIDR(d.network.AddressCIDR(6))
 
 		if v4subnet != nil {
 			mask, _ := v4subnet.Mask.Size()
@@ -1643,8 +1724,17 @@ func (d *nicBridged) State() (*api.InstanceStateNetwork, error) {
 		}
 
 		if d.config["hwaddr"] != "" {
-			// Parse the leases file if parent network is managed.
-			leaseIPs, err := network.GetLeaseAddresses(d.network.Name(), d.config["hwaddr"])
+			// Parse the leases file if parent network is managed (or get the leases of the native DHCP server).
+			var leaseIPs []net.IP
+			var err error
+
+			bridgeNet, ok := d.network.(bridgeNetwork)
+			if ok && bridgeNet.UsesNativeDHCP() {
+				leaseIPs, err = bridgeNet.LeaseAddresses(d.config["hwaddr"])
+			} else {
+				leaseIPs, err = network.GetLeaseAddresses(d.network.Name(), d.config["hwaddr"])
+			}
+
 			if err == nil {
 				for _, leaseIP := range leaseIPs {
 					ipStore(leaseIP)
@@ -1768,5 +1858,17 @@ func (d *nicBridged) Register() error {
 		return err
 	}
 
-	return nil
+	// Track the firewall rules applied when the instance was started so that their drift is detected.
+	networkVethFillFromVolatile(d.config, d.volatileGet())
+
+	return d.state.Firewall.Track(func(fw firewall.Driver) error {
+		if shared.IsTrue(d.config["security.mac_filtering"]) || shared.IsTrue(d.config["security.ipv4_filtering"]) || shared.IsTrue(d.config["security.ipv6_filtering"]) {
+			err := d.setFilters(fw)
+			if err != nil {
+				return err
+			}
+		}
+
+		return networkSetupHostVethNetPrio(&d.deviceCommon, fw, d.config["host_name"])
+	})
 }
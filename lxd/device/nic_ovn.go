@@ -16,6 +16,7 @@ import (
 	deviceConfig "github.com/canonical/lxd/lxd/device/config"
 	pcidev "github.com/canonical/lxd/lxd/device/pci"
 	"github.com/canonical/lxd/lxd/dnsmasq/dhcpalloc"
+	"github.com/canonical/lxd/lxd/firewall"
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/ip"
@@ -66,7 +67,7 @@ func (d *nicOVN) UpdatableFields(oldDevice Type) []string {
 		return []string{}
 	}
 
-	return []string{"security.acls"}
+	return []string{"security.acls", "limits.ingress", "limits.egress", "limits.max", "limits.priority", "limits.ingress.guaranteed", "limits.egress.guaranteed", "limits.dscp"}
 }
 
 // validateConfig checks the supplied config for correctness.
@@ -99,6 +100,13 @@ func (d *nicOVN) validateConfig(instConf instance.ConfigReader) error {
 		"acceleration",
 		"nested",
 		"vlan",
+		"limits.ingress",
+		"limits.egress",
+		"limits.max",
+		"limits.priority",
+		"limits.ingress.guaranteed",
+		"limits.egress.guaranteed",
+		"limits.dscp",
 	}
 
 	// The NIC's network may be a non-default project, so lookup project and get network's project name.
@@ -250,6 +258,22 @@ func (d *nicOVN) validateConfig(instConf instance.ConfigReader) error {
 		return err
 	}
 
+	// Limits are applied on the host side interface, which nested and accelerated NICs don't have.
+	if networkHasLimits(d.config) {
+		if d.config["nested"] != "" {
+			return fmt.Errorf("Limits cannot be used with nested NICs")
+		}
+
+		if shared.ValueInSlice(d.config["acceleration"], []string{"sriov", "vdpa"}) {
+			return fmt.Errorf("Limits cannot be used with hardware acceleration")
+		}
+	}
+
+	err = networkValidateLimits(d.config)
+	if err != nil {
+		return err
+	}
+
 	// Check IP external routes are within the network's external routes.
 	var externalRoutes []*net.IPNet
 	for _, k := range []string{"ipv4.routes.external", "ipv6.routes.external"} {
@@ -345,10 +369,15 @@ func (d *nicOVN) checkAddressConflict() error {
 func (d *nicOVN) Add() error {
 	networkVethFillFromVolatile(d.config, d.volatileGet())
 
+	// Record the addresses of the NIC in the network's IPAM.
+	_, err := networkIPAMAllocate(&d.deviceCommon, d.network)
+	if err != nil {
+		return err
+	}
+
 	// Load uplink network config.
 	uplinkNetworkName := d.network.Config()["network"]
 
-	var err error
 	var uplink *api.Network
 
 	err = d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
@@ -417,6 +446,12 @@ func (d *nicOVN) Start() (*deviceConfig.RunConfig, error) {
 		return nil, err
 	}
 
+	// Record the addresses of the NIC in the network's IPAM (picking one if needed).
+	_, err = networkIPAMAllocate(&d.deviceCommon, d.network)
+	if err != nil {
+		return nil, err
+	}
+
 	revert := revert.New()
 	defer revert.Fail()
 
@@ -610,6 +645,18 @@ func (d *nicOVN) Start() (*deviceConfig.RunConfig, error) {
 		revert.Add(cleanup)
 	}
 
+	// Apply host-side limits (if not nested or accelerated).
+	if networkHasLimits(d.config) || d.inst.Project().Config["limits.network.ingress"] != "" || d.inst.Project().Config["limits.network.egress"] != "" {
+		if d.config["nested"] == "" && !shared.ValueInSlice(d.config["acceleration"], []string{"sriov", "vdpa"}) {
+			err = networkSetupHostVethLimits(&d.deviceCommon, nil, true)
+			if err != nil {
+				return nil, err
+			}
+
+			revert.Add(func() { _ = networkClearHostVethLimits(&d.deviceCommon) })
+		}
+	}
+
 	runConf := deviceConfig.RunConfig{}
 
 	// Get local chassis ID for chassis group.
@@ -711,6 +758,12 @@ func (d *nicOVN) Update(oldDevices deviceConfig.Devices, isRunning bool) error {
 	// Populate device config with volatile fields if needed.
 	networkVethFillFromVolatile(d.config, d.volatileGet())
 
+	// Record the new addresses of the NIC in the network's IPAM.
+	_, err := networkIPAMAllocate(&d.deviceCommon, d.network)
+	if err != nil {
+		return err
+	}
+
 	// If an IPv6 address has changed, if the instance is running we should bounce the host-side
 	// veth interface to give the instance a chance to detect the change and re-apply for an
 	// updated lease with new IP address.
@@ -727,6 +780,14 @@ func (d *nicOVN) Update(oldDevices deviceConfig.Devices, isRunning bool) error {
 		}
 	}
 
+	// Apply host-side limits if the instance is running and the NIC has a host side interface.
+	if isRunning && (networkHasLimits(d.config) || networkHasLimits(oldConfig)) && d.config["nested"] == "" && !shared.ValueInSlice(d.config["acceleration"], []string{"sriov", "vdpa"}) {
+		err := networkSetupHostVethLimits(&d.deviceCommon, oldConfig, true)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Apply any changes needed when assigned ACLs change.
 	if d.config["security.acls"] != oldConfig["security.acls"] {
 		// Work out which ACLs have been removed and remove logical port from those groups.
@@ -784,7 +845,7 @@ func (d *nicOVN) Update(oldDevices deviceConfig.Devices, isRunning bool) error {
 	}
 
 	// If an external address changed, update the BGP advertisements.
-	err := bgpRemovePrefix(&d.deviceCommon, oldConfig)
+	err = bgpRemovePrefix(&d.deviceCommon, oldConfig)
 	if err != nil {
 		return err
 	}
@@ -859,6 +920,14 @@ func (d *nicOVN) Stop() (*deviceConfig.RunConfig, error) {
 		}
 	}
 
+	// Clear host-side limits (if not nested or accelerated).
+	if d.config["host_name"] != "" && d.config["nested"] == "" && !shared.ValueInSlice(d.config["acceleration"], []string{"sriov", "vdpa"}) {
+		err = networkClearHostVethLimits(&d.deviceCommon)
+		if err != nil {
+			d.logger.Error("Failed clearing host-side limits", logger.Ctx{"err": err})
+		}
+	}
+
 	// Remove BGP announcements.
 	err = bgpRemovePrefix(&d.deviceCommon, d.config)
 	if err != nil {
@@ -950,6 +1019,11 @@ func (d *nicOVN) postStop() error {
 
 // Remove is run when the device is removed from the instance or the instance is deleted.
 func (d *nicOVN) Remove() error {
+	err := networkIPAMRelease(&d.deviceCommon, d.network)
+	if err != nil {
+		return err
+	}
+
 	// Check for port groups that will become unused (and need deleting) as this NIC is deleted.
 	securityACLs := shared.SplitNTrimSpace(d.config["security.acls"], ",", -1, true)
 	if len(securityACLs) > 0 {
@@ -1095,6 +1169,15 @@ func (d *nicOVN) Register() error {
 		return err
 	}
 
+	// Track the firewall rules applied when the instance was started so that their drift is detected.
+	if d.config["nested"] == "" && !shared.ValueInSlice(d.config["acceleration"], []string{"sriov", "vdpa"}) {
+		networkVethFillFromVolatile(d.config, d.volatileGet())
+
+		return d.state.Firewall.Track(func(fw firewall.Driver) error {
+			return networkSetupHostVethNetPrio(&d.deviceCommon, fw, d.config["host_name"])
+		})
+	}
+
 	return nil
 }
 
@@ -5,6 +5,7 @@ import (
 	"os"
 
 	deviceConfig "github.com/canonical/lxd/lxd/device/config"
+	"github.com/canonical/lxd/lxd/firewall"
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/network"
@@ -198,6 +199,16 @@ func (d *nicP2P) Update(oldDevices deviceConfig.Devices, isRunning bool) error {
 	return nil
 }
 
+// Register sets up anything needed on LXD startup.
+func (d *nicP2P) Register() error {
+	// Track the firewall rules applied when the instance was started so that their drift is detected.
+	networkVethFillFromVolatile(d.config, d.volatileGet())
+
+	return d.state.Firewall.Track(func(fw firewall.Driver) error {
+		return networkSetupHostVethNetPrio(&d.deviceCommon, fw, d.config["host_name"])
+	})
+}
+
 // Stop is run when the device is removed from the instance.
 func (d *nicP2P) Stop() (*deviceConfig.RunConfig, error) {
 	// Populate device config with volatile fields (hwaddr and host_name) if needed.
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	deviceConfig "github.com/canonical/lxd/lxd/device/config"
+	"github.com/canonical/lxd/lxd/firewall"
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/ip"
@@ -43,7 +44,7 @@ func (d *nicRouted) UpdatableFields(oldDevice Type) []string {
 		return []string{}
 	}
 
-	return []string{"limits.ingress", "limits.egress", "limits.max", "limits.priority"}
+	return []string{"limits.ingress", "limits.egress", "limits.max", "limits.priority", "limits.ingress.guaranteed", "limits.egress.guaranteed", "limits.dscp"}
 }
 
 // validateConfig checks the supplied config for correctness.
@@ -70,6 +71,9 @@ func (d *nicRouted) validateConfig(instConf instance.ConfigReader) error {
 		"limits.egress",
 		"limits.max",
 		"limits.priority",
+		"limits.ingress.guaranteed",
+		"limits.egress.guaranteed",
+		"limits.dscp",
 		"ipv4.gateway",
 		"ipv6.gateway",
 		"ipv4.routes",
@@ -105,6 +109,11 @@ func (d *nicRouted) validateConfig(instConf instance.ConfigReader) error {
 		return err
 	}
 
+	err = networkValidateLimits(d.config)
+	if err != nil {
+		return err
+	}
+
 	// Detect duplicate IPs in config.
 	for _, key := range []string{"ipv4.address", "ipv6.address"} {
 		ips := make(map[string]struct{})
@@ -599,6 +608,21 @@ func (d *nicRouted) Update(oldDevices deviceConfig.Devices, isRunning bool) erro
 	return nil
 }
 
+// Register sets up anything needed on LXD startup.
+func (d *nicRouted) Register() error {
+	// Track the firewall rules applied when the instance was started so that their drift is detected.
+	networkVethFillFromVolatile(d.config, d.volatileGet())
+
+	return d.state.Firewall.Track(func(fw firewall.Driver) error {
+		err := fw.InstanceSetupRPFilter(d.inst.Project().Name, d.inst.Name(), d.name, d.config["host_name"])
+		if err != nil {
+			return err
+		}
+
+		return networkSetupHostVethNetPrio(&d.deviceCommon, fw, d.config["host_name"])
+	})
+}
+
 // Stop is run when the device is removed from the instance.
 func (d *nicRouted) Stop() (*deviceConfig.RunConfig, error) {
 	// Populate device config with volatile fields (hwaddr and host_name) if needed.
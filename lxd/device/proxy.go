@@ -19,6 +19,7 @@ import (
 	"github.com/canonical/lxd/lxd/db/warningtype"
 	deviceConfig "github.com/canonical/lxd/lxd/device/config"
 	"github.com/canonical/lxd/lxd/device/nictype"
+	"github.com/canonical/lxd/lxd/firewall"
 	firewallDrivers "github.com/canonical/lxd/lxd/firewall/drivers"
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
@@ -282,6 +283,18 @@ func (d *proxy) validateListenAddressConflicts(proxyListenAddr net.IP) error {
 	})
 }
 
+// Register sets up anything needed on LXD startup.
+func (d *proxy) Register() error {
+	if shared.IsFalseOrEmpty(d.config["nat"]) {
+		return nil
+	}
+
+	// Track the firewall rules applied when the instance was started so that their drift is detected.
+	return d.state.Firewall.Track(func(fw firewall.Driver) error {
+		return d.setupNAT(fw)
+	})
+}
+
 // Start is run when the device is added to the instance.
 func (d *proxy) Start() (*deviceConfig.RunConfig, error) {
 	err := d.validateEnvironment()
@@ -294,7 +307,7 @@ func (d *proxy) Start() (*deviceConfig.RunConfig, error) {
 	runConf.PostHooks = []func() error{
 		func() error {
 			if shared.IsTrue(d.config["nat"]) {
-				err = d.setupNAT()
+				err = d.setupNAT(d.state.Firewall)
 				if err != nil {
 					return fmt.Errorf("Failed to start device %q: %w", d.name, err)
 				}
@@ -447,7 +460,7 @@ func (d *proxy) Stop() (*deviceConfig.RunConfig, error) {
 	return nil, nil
 }
 
-func (d *proxy) setupNAT() error {
+func (d *proxy) setupNAT(fw firewall.Driver) error {
 	listenAddr, err := network.ProxyParseAddr(d.config["listen"])
 	if err != nil {
 		return err
@@ -551,7 +564,7 @@ func (d *proxy) setupNAT() error {
 		TargetPorts:   connectAddr.Ports,
 	}
 
-	err = d.state.Firewall.InstanceSetupProxyNAT(d.inst.Project().Name, d.inst.Name(), d.name, &addressForward)
+	err = fw.InstanceSetupProxyNAT(d.inst.Project().Name, d.inst.Name(), d.name, &addressForward)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,401 @@
+package dhcpd
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// hostnameInvalidChars matches the characters that aren't allowed in the hostnames sent by the clients.
+var hostnameInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9-]`)
+
+// serveDHCPv4 handles the DHCPv4 requests received on the connection until it is closed.
+func (s *Server) serveDHCPv4(conn *net.UDPConn) {
+	buf := make([]byte, 1500)
+
+	for {
+		n, _, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+
+			logger.Warn("Failed reading DHCPv4 request", logger.Ctx{"interface": s.config.Interface, "err": err})
+			continue
+		}
+
+		req := &layers.DHCPv4{}
+		err = req.DecodeFromBytes(buf[:n], gopacket.NilDecodeFeedback)
+		if err != nil || req.Operation != layers.DHCPOpRequest || len(req.ClientHWAddr) != 6 {
+			continue
+		}
+
+		reply, err := s.handleDHCPv4(req)
+		if err != nil {
+			logger.Warn("Failed handling DHCPv4 request", logger.Ctx{"interface": s.config.Interface, "hwaddr": req.ClientHWAddr.String(), "err": err})
+			continue
+		}
+
+		if reply == nil {
+			continue
+		}
+
+		err = s.sendDHCPv4(conn, req, reply)
+		if err != nil {
+			logger.Warn("Failed sending DHCPv4 reply", logger.Ctx{"interface": s.config.Interface, "hwaddr": req.ClientHWAddr.String(), "err": err})
+		}
+	}
+}
+
+// handleDHCPv4 handles a DHCPv4 request and returns the reply to send (if any).
+func (s *Server) handleDHCPv4(req *layers.DHCPv4) (*layers.DHCPv4, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hosts, err := s.backend.Hosts()
+	if err != nil {
+		return nil, err
+	}
+
+	leases, err := s.backend.Leases()
+	if err != nil {
+		return nil, err
+	}
+
+	hwaddr := net.HardwareAddr(append([]byte(nil), req.ClientHWAddr...))
+
+	var host *Host
+	h, found := hosts[hwaddr.String()]
+	if found {
+		host = &h
+	}
+
+	msgType := layers.DHCPMsgTypeUnspecified
+	data := dhcpv4Option(req, layers.DHCPOptMessageType)
+	if len(data) == 1 {
+		msgType = layers.DHCPMsgType(data[0])
+	}
+
+	requested := net.IP(dhcpv4Option(req, layers.DHCPOptRequestIP)).To4()
+	serverID := net.IP(dhcpv4Option(req, layers.DHCPOptServerID)).To4()
+
+	switch msgType {
+	case layers.DHCPMsgTypeDiscover:
+		address := s.allocateIPv4(hwaddr, requested, host, hosts, leases)
+		if address == nil {
+			return nil, fmt.Errorf("No available IPv4 address")
+		}
+
+		s.offers[address.String()] = offer{client: hwaddr.String(), expiry: time.Now().Add(offerTimeout)}
+
+		return s.dhcpv4Reply(req, layers.DHCPMsgTypeOffer, address, host), nil
+
+	case layers.DHCPMsgTypeRequest:
+		// The client picked the offer of another server.
+		if serverID != nil && !serverID.Equal(s.config.IPv4.Address) {
+			for address, o := range s.offers {
+				if o.client == hwaddr.String() {
+					delete(s.offers, address)
+				}
+			}
+
+			return nil, nil
+		}
+
+		address := requested
+		if address == nil {
+			address = req.ClientIP.To4()
+		}
+
+		if address == nil || address.IsUnspecified() {
+			return nil, nil
+		}
+
+		if !s.ipv4Allowed(hwaddr, address, host, hosts, leases) {
+			return s.dhcpv4Reply(req, layers.DHCPMsgTypeNak, nil, nil), nil
+		}
+
+		hostname := ""
+		if host != nil {
+			hostname = host.Hostname
+		} else {
+			hostname = hostnameInvalidChars.ReplaceAllString(string(dhcpv4Option(req, layers.DHCPOptHostname)), "")
+		}
+
+		lease := Lease{
+			Hwaddr:   hwaddr,
+			Address:  address,
+			Hostname: hostname,
+			Expiry:   expiry(s.config.IPv4.LeaseTime),
+		}
+
+		err := s.saveLease(leases, lease, func(l Lease) bool { return l.Hwaddr.String() == hwaddr.String() })
+		if err != nil {
+			return nil, err
+		}
+
+		delete(s.offers, address.String())
+
+		return s.dhcpv4Reply(req, layers.DHCPMsgTypeAck, address, host), nil
+
+	case layers.DHCPMsgTypeRelease, layers.DHCPMsgTypeDecline:
+		address := req.ClientIP.To4()
+		if msgType == layers.DHCPMsgTypeDecline {
+			address = requested
+		}
+
+		lease := leaseFor(leases, address)
+		if lease != nil && lease.Hwaddr.String() == hwaddr.String() {
+			return nil, s.backend.DeleteLease(*lease)
+		}
+
+		return nil, nil
+
+	case layers.DHCPMsgTypeInform:
+		return s.dhcpv4Reply(req, layers.DHCPMsgTypeAck, nil, host), nil
+	}
+
+	return nil, nil
+}
+
+// ipv4Allowed returns whether the address can be leased to the client.
+func (s *Server) ipv4Allowed(hwaddr net.HardwareAddr, address net.IP, host *Host, hosts map[string]Host, leases []Lease) bool {
+	// Clients with a reserved address can only use that address.
+	if host != nil && host.IPv4 != nil {
+		return host.IPv4.Equal(address)
+	}
+
+	if !s.config.IPv4.Subnet.Contains(address) || address.Equal(s.config.IPv4.Address) || address.Equal(s.config.IPv4.Gateway) {
+		return false
+	}
+
+	// Check that the address isn't used by another client.
+	if reservedBy(hosts, address) != "" {
+		return false
+	}
+
+	lease := leaseFor(leases, address)
+	if lease != nil && lease.Hwaddr.String() != hwaddr.String() && !lease.Expired() {
+		return false
+	}
+
+	o, found := s.offers[address.String()]
+	if found && o.client != hwaddr.String() && time.Now().Before(o.expiry) {
+		return false
+	}
+
+	// Clients can keep their current address, new addresses must come from the dynamic ranges.
+	if lease != nil && lease.Hwaddr.String() == hwaddr.String() {
+		return true
+	}
+
+	return inRanges(s.config.IPv4.Ranges, address)
+}
+
+// allocateIPv4 returns the address to offer to a client (nil if there's none left).
+func (s *Server) allocateIPv4(hwaddr net.HardwareAddr, requested net.IP, host *Host, hosts map[string]Host, leases []Lease) net.IP {
+	if host != nil && host.IPv4 != nil {
+		return host.IPv4
+	}
+
+	// Prefer the current address of the client and then the address it asked for.
+	for _, lease := range leases {
+		if lease.Hwaddr.String() == hwaddr.String() && lease.Address.To4() != nil && s.ipv4Allowed(hwaddr, lease.Address, host, hosts, leases) {
+			return lease.Address.To4()
+		}
+	}
+
+	if requested != nil && s.ipv4Allowed(hwaddr, requested, host, hosts, leases) {
+		return requested
+	}
+
+	return firstAvailable(s.config.IPv4.Ranges, func(address net.IP) bool {
+		return s.ipv4Allowed(hwaddr, address, host, hosts, leases)
+	})
+}
+
+// dhcpv4Reply builds the reply to a DHCPv4 request.
+func (s *Server) dhcpv4Reply(req *layers.DHCPv4, msgType layers.DHCPMsgType, address net.IP, host *Host) *layers.DHCPv4 {
+	reply := &layers.DHCPv4{
+		Operation:    layers.DHCPOpReply,
+		HardwareType: req.HardwareType,
+		HardwareLen:  req.HardwareLen,
+		Xid:          req.Xid,
+		Flags:        req.Flags,
+		ClientIP:     req.ClientIP,
+		YourClientIP: address,
+		RelayAgentIP: req.RelayAgentIP,
+		ClientHWAddr: req.ClientHWAddr,
+	}
+
+	reply.Options = append(reply.Options,
+		layers.NewDHCPOption(layers.DHCPOptMessageType, []byte{byte(msgType)}),
+		layers.NewDHCPOption(layers.DHCPOptServerID, s.config.IPv4.Address.To4()),
+	)
+
+	if msgType == layers.DHCPMsgTypeNak {
+		return reply
+	}
+
+	// Only offers and acknowledgements of an address carry the lease time.
+	if address != nil {
+		if s.config.IPv4.LeaseTime == 0 {
+			reply.Options = append(reply.Options, layers.NewDHCPOption(layers.DHCPOptLeaseTime, uint32Bytes(0xffffffff)))
+		} else {
+			seconds := uint32(s.config.IPv4.LeaseTime / time.Second)
+			reply.Options = append(reply.Options,
+				layers.NewDHCPOption(layers.DHCPOptLeaseTime, uint32Bytes(seconds)),
+				layers.NewDHCPOption(layers.DHCPOptT1, uint32Bytes(seconds/2)),
+				layers.NewDHCPOption(layers.DHCPOptT2, uint32Bytes(seconds/8*7)),
+			)
+		}
+	}
+
+	gateway := s.config.IPv4.Gateway
+	if gateway == nil {
+		gateway = s.config.IPv4.Address
+	}
+
+	options := map[uint8][]byte{
+		uint8(layers.DHCPOptSubnetMask): []byte(s.config.IPv4.Subnet.Mask),
+		uint8(layers.DHCPOptRouter):     gateway.To4(),
+		uint8(layers.DHCPOptDNS):        s.config.IPv4.Address.To4(),
+	}
+
+	if s.config.Domain != "" {
+		options[uint8(layers.DHCPOptDomainName)] = []byte(s.config.Domain)
+	}
+
+	if len(s.config.Search) > 0 {
+		options[uint8(layers.DHCPOptDomainSearch)] = encodeDomainList(s.config.Search)
+	}
+
+	if s.config.MTU > 0 {
+		options[uint8(layers.DHCPOptInterfaceMTU)] = binary.BigEndian.AppendUint16(nil, uint16(s.config.MTU))
+	}
+
+	// The options of the host take precedence.
+	if host != nil {
+		for code, data := range host.Options {
+			options[code] = data
+		}
+	}
+
+	codes := make([]int, 0, len(options))
+	for code := range options {
+		codes = append(codes, int(code))
+	}
+
+	sort.Ints(codes)
+
+	for _, code := range codes {
+		reply.Options = append(reply.Options, layers.NewDHCPOption(layers.DHCPOpt(code), options[uint8(code)]))
+	}
+
+	return reply
+}
+
+// sendDHCPv4 sends the reply to a DHCPv4 request.
+func (s *Server) sendDHCPv4(conn *net.UDPConn, req *layers.DHCPv4, reply *layers.DHCPv4) error {
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{
+		ComputeChecksums: true,
+		FixLengths:       true,
+	}
+
+	err := gopacket.SerializeLayers(buf, opts, reply)
+	if err != nil {
+		return err
+	}
+
+	// Relayed requests are answered to the relay, renewals to the client and everything else is broadcast as
+	// the client doesn't have an address yet.
+	dst := &net.UDPAddr{IP: net.IPv4bcast, Port: 68}
+	if req.RelayAgentIP != nil && !req.RelayAgentIP.IsUnspecified() {
+		dst = &net.UDPAddr{IP: req.RelayAgentIP, Port: 67}
+	} else if req.ClientIP != nil && !req.ClientIP.IsUnspecified() && reply.YourClientIP != nil {
+		dst = &net.UDPAddr{IP: req.ClientIP, Port: 68}
+	}
+
+	_, err = conn.WriteToUDP(buf.Bytes(), dst)
+	return err
+}
+
+// dhcpv4Option returns the data of an option of a DHCPv4 packet (nil if it isn't set).
+func dhcpv4Option(packet *layers.DHCPv4, optType layers.DHCPOpt) []byte {
+	for _, o := range packet.Options {
+		if o.Type == optType {
+			return o.Data
+		}
+	}
+
+	return nil
+}
+
+// uint32Bytes returns the network byte order representation of a number.
+func uint32Bytes(value uint32) []byte {
+	return binary.BigEndian.AppendUint32(nil, value)
+}
+
+// inRanges returns whether the address is within one of the ranges.
+func inRanges(ranges []shared.IPRange, address net.IP) bool {
+	for _, r := range ranges {
+		// Compare the addresses using the same representation as the range.
+		ip := address.To16()
+		if len(r.Start) == net.IPv4len {
+			ip = address.To4()
+		}
+
+		if ip != nil && r.ContainsIP(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// maxScan is the maximum number of addresses checked when looking for an available address in large ranges.
+const maxScan = 65536
+
+// firstAvailable returns the first address of the ranges for which available returns true.
+func firstAvailable(ranges []shared.IPRange, available func(net.IP) bool) net.IP {
+	scanned := 0
+
+	for _, r := range ranges {
+		start := big.NewInt(0).SetBytes(r.Start.To16())
+		end := big.NewInt(0).SetBytes(r.End.To16())
+		if r.End == nil {
+			end.Set(start)
+		}
+
+		v4 := r.Start.To4() != nil
+
+		for i := start; i.Cmp(end) <= 0 && scanned < maxScan; i.Add(i, big.NewInt(1)) {
+			scanned++
+
+			address := make(net.IP, net.IPv6len)
+			i.FillBytes(address)
+
+			if v4 {
+				address = address.To4()
+			}
+
+			if available(address) {
+				return address
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,398 @@
+package dhcpd
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket/layers"
+	"github.com/stretchr/testify/assert"
+)
+
+// dhcpv4Request returns a DHCPv4 request of the given type from a client.
+func dhcpv4Request(hwaddr net.HardwareAddr, msgType layers.DHCPMsgType, clientIP string, requested string, serverID string) *layers.DHCPv4 {
+	req := &layers.DHCPv4{
+		Operation:    layers.DHCPOpRequest,
+		HardwareType: layers.LinkTypeEthernet,
+		HardwareLen:  6,
+		Xid:          42,
+		ClientHWAddr: hwaddr,
+		ClientIP:     net.IPv4zero.To4(),
+	}
+
+	if clientIP != "" {
+		req.ClientIP = net.ParseIP(clientIP).To4()
+	}
+
+	req.Options = append(req.Options, layers.NewDHCPOption(layers.DHCPOptMessageType, []byte{byte(msgType)}))
+
+	if requested != "" {
+		req.Options = append(req.Options, layers.NewDHCPOption(layers.DHCPOptRequestIP, net.ParseIP(requested).To4()))
+	}
+
+	if serverID != "" {
+		req.Options = append(req.Options, layers.NewDHCPOption(layers.DHCPOptServerID, net.ParseIP(serverID).To4()))
+	}
+
+	return req
+}
+
+// dhcpv4MsgType returns the message type of a DHCPv4 reply.
+func dhcpv4MsgType(reply *layers.DHCPv4) layers.DHCPMsgType {
+	data := dhcpv4Option(reply, layers.DHCPOptMessageType)
+	if len(data) != 1 {
+		return layers.DHCPMsgTypeUnspecified
+	}
+
+	return layers.DHCPMsgType(data[0])
+}
+
+func Test_handleDHCPv4(t *testing.T) {
+	client1 := net.HardwareAddr{0, 0x16, 0x3e, 0, 0, 1}
+	client2 := net.HardwareAddr{0, 0x16, 0x3e, 0, 0, 2}
+	client3 := net.HardwareAddr{0, 0x16, 0x3e, 0, 0, 3}
+
+	type step struct {
+		req       *layers.DHCPv4
+		msgType   layers.DHCPMsgType // Unspecified when no reply is expected.
+		address   string
+		wantErr   bool
+		wantLease map[string]string // Expected leases (address to MAC address) after the step.
+	}
+
+	tests := []struct {
+		name   string
+		hosts  map[string]Host
+		leases []Lease
+		steps  []step
+	}{
+		{
+			name: "Discover and request",
+			steps: []step{
+				{
+					req:       dhcpv4Request(client1, layers.DHCPMsgTypeDiscover, "", "", ""),
+					msgType:   layers.DHCPMsgTypeOffer,
+					address:   "10.0.0.10",
+					wantLease: map[string]string{},
+				},
+				{
+					req:       dhcpv4Request(client1, layers.DHCPMsgTypeRequest, "", "10.0.0.10", "10.0.0.1"),
+					msgType:   layers.DHCPMsgTypeAck,
+					address:   "10.0.0.10",
+					wantLease: map[string]string{"10.0.0.10": client1.String()},
+				},
+			},
+		},
+		{
+			name: "Offered addresses are kept aside for the client",
+			steps: []step{
+				{
+					req:     dhcpv4Request(client1, layers.DHCPMsgTypeDiscover, "", "", ""),
+					msgType: layers.DHCPMsgTypeOffer,
+					address: "10.0.0.10",
+				},
+				{
+					req:     dhcpv4Request(client2, layers.DHCPMsgTypeDiscover, "", "", ""),
+					msgType: layers.DHCPMsgTypeOffer,
+					address: "10.0.0.11",
+				},
+				{
+					req:     dhcpv4Request(client2, layers.DHCPMsgTypeRequest, "", "10.0.0.10", "10.0.0.1"),
+					msgType: layers.DHCPMsgTypeNak,
+				},
+			},
+		},
+		{
+			name: "Requested address is offered if available",
+			steps: []step{
+				{
+					req:     dhcpv4Request(client1, layers.DHCPMsgTypeDiscover, "", "10.0.0.12", ""),
+					msgType: layers.DHCPMsgTypeOffer,
+					address: "10.0.0.12",
+				},
+				{
+					req:     dhcpv4Request(client2, layers.DHCPMsgTypeDiscover, "", "10.0.0.200", ""),
+					msgType: layers.DHCPMsgTypeOffer,
+					address: "10.0.0.10",
+				},
+			},
+		},
+		{
+			name:   "Renewal keeps the address",
+			leases: []Lease{{Hwaddr: client1, Address: net.ParseIP("10.0.0.11").To4(), Expiry: time.Now().Add(time.Minute)}},
+			steps: []step{
+				{
+					req:     dhcpv4Request(client1, layers.DHCPMsgTypeDiscover, "", "", ""),
+					msgType: layers.DHCPMsgTypeOffer,
+					address: "10.0.0.11",
+				},
+				{
+					req:       dhcpv4Request(client1, layers.DHCPMsgTypeRequest, "10.0.0.11", "", ""),
+					msgType:   layers.DHCPMsgTypeAck,
+					address:   "10.0.0.11",
+					wantLease: map[string]string{"10.0.0.11": client1.String()},
+				},
+			},
+		},
+		{
+			name:   "Address leased to another client is refused",
+			leases: []Lease{{Hwaddr: client1, Address: net.ParseIP("10.0.0.10").To4(), Expiry: time.Now().Add(time.Minute)}},
+			steps: []step{
+				{
+					req:       dhcpv4Request(client2, layers.DHCPMsgTypeRequest, "", "10.0.0.10", "10.0.0.1"),
+					msgType:   layers.DHCPMsgTypeNak,
+					wantLease: map[string]string{"10.0.0.10": client1.String()},
+				},
+				{
+					req:     dhcpv4Request(client2, layers.DHCPMsgTypeDiscover, "", "10.0.0.10", ""),
+					msgType: layers.DHCPMsgTypeOffer,
+					address: "10.0.0.11",
+				},
+			},
+		},
+		{
+			name:   "Expired lease of another client is reused",
+			leases: []Lease{{Hwaddr: client1, Address: net.ParseIP("10.0.0.10").To4(), Expiry: time.Now().Add(-time.Minute)}},
+			steps: []step{
+				{
+					req:       dhcpv4Request(client2, layers.DHCPMsgTypeRequest, "", "10.0.0.10", "10.0.0.1"),
+					msgType:   layers.DHCPMsgTypeAck,
+					address:   "10.0.0.10",
+					wantLease: map[string]string{"10.0.0.10": client2.String()},
+				},
+			},
+		},
+		{
+			name:  "Static reservation",
+			hosts: map[string]Host{client1.String(): {Hostname: "c1", IPv4: net.ParseIP("10.0.0.50").To4()}},
+			steps: []step{
+				{
+					req:     dhcpv4Request(client1, layers.DHCPMsgTypeDiscover, "", "10.0.0.10", ""),
+					msgType: layers.DHCPMsgTypeOffer,
+					address: "10.0.0.50",
+				},
+				{
+					req:     dhcpv4Request(client1, layers.DHCPMsgTypeRequest, "", "10.0.0.10", "10.0.0.1"),
+					msgType: layers.DHCPMsgTypeNak,
+				},
+				{
+					req:       dhcpv4Request(client1, layers.DHCPMsgTypeRequest, "", "10.0.0.50", "10.0.0.1"),
+					msgType:   layers.DHCPMsgTypeAck,
+					address:   "10.0.0.50",
+					wantLease: map[string]string{"10.0.0.50": client1.String()},
+				},
+				{
+					req:     dhcpv4Request(client2, layers.DHCPMsgTypeRequest, "", "10.0.0.50", "10.0.0.1"),
+					msgType: layers.DHCPMsgTypeNak,
+				},
+			},
+		},
+		{
+			name:  "Reserved addresses within the ranges aren't handed out",
+			hosts: map[string]Host{client1.String(): {IPv4: net.ParseIP("10.0.0.10").To4()}},
+			steps: []step{
+				{
+					req:     dhcpv4Request(client2, layers.DHCPMsgTypeDiscover, "", "", ""),
+					msgType: layers.DHCPMsgTypeOffer,
+					address: "10.0.0.11",
+				},
+			},
+		},
+		{
+			name: "Addresses outside of the ranges are refused",
+			steps: []step{
+				{
+					req:     dhcpv4Request(client1, layers.DHCPMsgTypeRequest, "", "10.0.0.100", "10.0.0.1"),
+					msgType: layers.DHCPMsgTypeNak,
+				},
+				{
+					req:     dhcpv4Request(client1, layers.DHCPMsgTypeRequest, "", "10.0.0.1", "10.0.0.1"),
+					msgType: layers.DHCPMsgTypeNak,
+				},
+				{
+					req:     dhcpv4Request(client1, layers.DHCPMsgTypeRequest, "", "192.0.2.10", "10.0.0.1"),
+					msgType: layers.DHCPMsgTypeNak,
+				},
+			},
+		},
+		{
+			name: "Ranges exhausted",
+			leases: []Lease{
+				{Hwaddr: client1, Address: net.ParseIP("10.0.0.10").To4()},
+				{Hwaddr: client2, Address: net.ParseIP("10.0.0.11").To4()},
+				{Hwaddr: net.HardwareAddr{0, 0x16, 0x3e, 0, 0, 4}, Address: net.ParseIP("10.0.0.12").To4()},
+			},
+			steps: []step{
+				{
+					req:     dhcpv4Request(client3, layers.DHCPMsgTypeDiscover, "", "", ""),
+					wantErr: true,
+				},
+			},
+		},
+		{
+			name: "Request for another server",
+			steps: []step{
+				{
+					req:     dhcpv4Request(client1, layers.DHCPMsgTypeDiscover, "", "", ""),
+					msgType: layers.DHCPMsgTypeOffer,
+					address: "10.0.0.10",
+				},
+				{
+					req:       dhcpv4Request(client1, layers.DHCPMsgTypeRequest, "", "10.0.0.10", "10.0.0.2"),
+					wantLease: map[string]string{},
+				},
+				{
+					// The offer made to the client was withdrawn.
+					req:     dhcpv4Request(client2, layers.DHCPMsgTypeDiscover, "", "", ""),
+					msgType: layers.DHCPMsgTypeOffer,
+					address: "10.0.0.10",
+				},
+			},
+		},
+		{
+			name: "Release",
+			leases: []Lease{
+				{Hwaddr: client1, Address: net.ParseIP("10.0.0.10").To4()},
+				{Hwaddr: client2, Address: net.ParseIP("10.0.0.11").To4()},
+			},
+			steps: []step{
+				{
+					req:       dhcpv4Request(client1, layers.DHCPMsgTypeRelease, "10.0.0.10", "", "10.0.0.1"),
+					wantLease: map[string]string{"10.0.0.11": client2.String()},
+				},
+				{
+					// Clients can't release the leases of other clients.
+					req:       dhcpv4Request(client1, layers.DHCPMsgTypeRelease, "10.0.0.11", "", "10.0.0.1"),
+					wantLease: map[string]string{"10.0.0.11": client2.String()},
+				},
+			},
+		},
+		{
+			name:   "Decline",
+			leases: []Lease{{Hwaddr: client1, Address: net.ParseIP("10.0.0.10").To4()}},
+			steps: []step{
+				{
+					req:       dhcpv4Request(client1, layers.DHCPMsgTypeDecline, "", "10.0.0.10", "10.0.0.1"),
+					wantLease: map[string]string{},
+				},
+			},
+		},
+		{
+			name: "Inform",
+			steps: []step{
+				{
+					req:       dhcpv4Request(client1, layers.DHCPMsgTypeInform, "10.0.0.100", "", ""),
+					msgType:   layers.DHCPMsgTypeAck,
+					wantLease: map[string]string{},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &testBackend{hosts: tt.hosts, leases: tt.leases}
+			s := newTestServer(backend, "10.0.0.10-10.0.0.12", "fd42::10-fd42::12")
+
+			for i, step := range tt.steps {
+				reply, err := s.handleDHCPv4(step.req)
+				if step.wantErr {
+					assert.Error(t, err, "Step %d", i)
+					continue
+				}
+
+				assert.NoError(t, err, "Step %d", i)
+
+				if step.msgType == layers.DHCPMsgTypeUnspecified {
+					assert.Nil(t, reply, "Step %d", i)
+				} else if assert.NotNil(t, reply, "Step %d", i) {
+					assert.Equal(t, step.msgType, dhcpv4MsgType(reply), "Step %d", i)
+					assert.Equal(t, uint32(42), reply.Xid, "Step %d", i)
+
+					if step.address != "" {
+						assert.Equal(t, step.address, reply.YourClientIP.String(), "Step %d", i)
+					} else {
+						assert.Nil(t, reply.YourClientIP, "Step %d", i)
+					}
+				}
+
+				if step.wantLease != nil {
+					leases := map[string]string{}
+					for _, lease := range backend.leases {
+						leases[lease.Address.String()] = lease.Hwaddr.String()
+					}
+
+					assert.Equal(t, step.wantLease, leases, "Step %d", i)
+				}
+			}
+		})
+	}
+}
+
+func Test_handleDHCPv4Lease(t *testing.T) {
+	client := net.HardwareAddr{0, 0x16, 0x3e, 0, 0, 1}
+
+	backend := &testBackend{
+		hosts: map[string]Host{client.String(): {Hostname: "c1", Options: map[uint8][]byte{uint8(layers.DHCPOptDNS): {192, 0, 2, 53}}}},
+	}
+
+	s := newTestServer(backend, "10.0.0.10-10.0.0.12", "fd42::10-fd42::12")
+
+	reply, err := s.handleDHCPv4(dhcpv4Request(client, layers.DHCPMsgTypeRequest, "", "10.0.0.10", "10.0.0.1"))
+	assert.NoError(t, err)
+	assert.Equal(t, layers.DHCPMsgTypeAck, dhcpv4MsgType(reply))
+
+	// The lease uses the hostname of the host and expires after the lease time.
+	assert.Len(t, backend.created, 1)
+	lease := backend.created[0]
+	assert.Equal(t, "c1", lease.Hostname)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), lease.Expiry, time.Minute)
+
+	// The reply carries the lease time, renewal times and the network options, with those of the host taking
+	// precedence.
+	assert.Equal(t, uint32Bytes(3600), dhcpv4Option(reply, layers.DHCPOptLeaseTime))
+	assert.Equal(t, uint32Bytes(1800), dhcpv4Option(reply, layers.DHCPOptT1))
+	assert.Equal(t, uint32Bytes(3150), dhcpv4Option(reply, layers.DHCPOptT2))
+	assert.Equal(t, []byte{255, 255, 255, 0}, dhcpv4Option(reply, layers.DHCPOptSubnetMask))
+	assert.Equal(t, []byte{10, 0, 0, 1}, dhcpv4Option(reply, layers.DHCPOptRouter))
+	assert.Equal(t, []byte{10, 0, 0, 1}, dhcpv4Option(reply, layers.DHCPOptServerID))
+	assert.Equal(t, []byte{192, 0, 2, 53}, dhcpv4Option(reply, layers.DHCPOptDNS))
+	assert.Equal(t, []byte("lxd"), dhcpv4Option(reply, layers.DHCPOptDomainName))
+
+	// Renewing the lease updates it and extends its expiry.
+	backend.leases[0].Expiry = time.Now().Add(time.Minute)
+
+	reply, err = s.handleDHCPv4(dhcpv4Request(client, layers.DHCPMsgTypeRequest, "10.0.0.10", "", ""))
+	assert.NoError(t, err)
+	assert.Equal(t, layers.DHCPMsgTypeAck, dhcpv4MsgType(reply))
+	assert.Len(t, backend.created, 1)
+	assert.Len(t, backend.updated, 1)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), backend.leases[0].Expiry, time.Minute)
+
+	// Leases that never expire.
+	s.config.IPv4.LeaseTime = 0
+
+	reply, err = s.handleDHCPv4(dhcpv4Request(client, layers.DHCPMsgTypeRequest, "10.0.0.10", "", ""))
+	assert.NoError(t, err)
+	assert.Equal(t, uint32Bytes(0xffffffff), dhcpv4Option(reply, layers.DHCPOptLeaseTime))
+	assert.Nil(t, dhcpv4Option(reply, layers.DHCPOptT1))
+	assert.True(t, backend.leases[0].Expiry.IsZero())
+	assert.False(t, backend.leases[0].Expired())
+}
+
+func Test_handleDHCPv4Hostname(t *testing.T) {
+	client := net.HardwareAddr{0, 0x16, 0x3e, 0, 0, 1}
+
+	backend := &testBackend{}
+	s := newTestServer(backend, "10.0.0.10-10.0.0.12", "fd42::10-fd42::12")
+
+	req := dhcpv4Request(client, layers.DHCPMsgTypeRequest, "", "10.0.0.10", "10.0.0.1")
+	req.Options = append(req.Options, layers.NewDHCPOption(layers.DHCPOptHostname, []byte("my_host.name-1")))
+
+	_, err := s.handleDHCPv4(req)
+	assert.NoError(t, err)
+
+	// Invalid characters are removed from the hostnames sent by unknown clients.
+	assert.Len(t, backend.created, 1)
+	assert.Equal(t, "myhostname-1", backend.created[0].Hostname)
+}
@@ -0,0 +1,399 @@
+package dhcpd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"golang.org/x/net/ipv6"
+
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// dhcpv6Multicast is the All_DHCP_Relay_Agents_and_Servers multicast address.
+var dhcpv6Multicast = net.ParseIP("ff02::1:2")
+
+// DHCPv6 status codes used in the replies.
+const (
+	dhcpv6StatusSuccess      uint16 = 0
+	dhcpv6StatusNoAddrsAvail uint16 = 2
+	dhcpv6StatusNotOnLink    uint16 = 4
+)
+
+// dhcpv6IANA represents an Identity Association for Non-temporary Addresses requested by a client.
+type dhcpv6IANA struct {
+	iaid    uint32
+	address net.IP
+}
+
+// joinGroup joins a multicast group on the network interface.
+func joinGroup(conn *net.UDPConn, iface *net.Interface, group net.IP) error {
+	return ipv6.NewPacketConn(conn).JoinGroup(iface, &net.UDPAddr{IP: group})
+}
+
+// serveDHCPv6 handles the DHCPv6 requests received on the connection until it is closed.
+func (s *Server) serveDHCPv6(conn *net.UDPConn, iface *net.Interface) {
+	buf := make([]byte, 1500)
+
+	// The server is identified by a DUID based on the MAC address of the interface.
+	duid := &layers.DHCPv6DUID{
+		Type:             layers.DHCPv6DUIDTypeLL,
+		HardwareType:     []byte{0, byte(layers.LinkTypeEthernet)},
+		LinkLayerAddress: iface.HardwareAddr,
+	}
+
+	serverID := duid.Encode()
+
+	for {
+		n, src, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+
+			logger.Warn("Failed reading DHCPv6 request", logger.Ctx{"interface": s.config.Interface, "err": err})
+			continue
+		}
+
+		req := &layers.DHCPv6{}
+		err = req.DecodeFromBytes(buf[:n], gopacket.NilDecodeFeedback)
+		if err != nil {
+			continue
+		}
+
+		reply, err := s.handleDHCPv6(req, src.IP, serverID)
+		if err != nil {
+			logger.Warn("Failed handling DHCPv6 request", logger.Ctx{"interface": s.config.Interface, "client": src.IP.String(), "err": err})
+			continue
+		}
+
+		if reply == nil {
+			continue
+		}
+
+		out := gopacket.NewSerializeBuffer()
+		err = gopacket.SerializeLayers(out, gopacket.SerializeOptions{FixLengths: true}, reply)
+		if err != nil {
+			logger.Warn("Failed encoding DHCPv6 reply", logger.Ctx{"interface": s.config.Interface, "err": err})
+			continue
+		}
+
+		_, err = conn.WriteToUDP(out.Bytes(), src)
+		if err != nil {
+			logger.Warn("Failed sending DHCPv6 reply", logger.Ctx{"interface": s.config.Interface, "client": src.IP.String(), "err": err})
+		}
+	}
+}
+
+// handleDHCPv6 handles a DHCPv6 request and returns the reply to send (if any).
+func (s *Server) handleDHCPv6(req *layers.DHCPv6, src net.IP, serverID []byte) (*layers.DHCPv6, error) {
+	clientID := dhcpv6Option(req, layers.DHCPv6OptClientID)
+	if clientID == nil && req.MsgType != layers.DHCPv6MsgTypeInformationRequest {
+		return nil, nil
+	}
+
+	// Ignore the requests meant for other servers.
+	reqServerID := dhcpv6Option(req, layers.DHCPv6OptServerID)
+	if reqServerID != nil && !bytes.Equal(reqServerID, serverID) {
+		return nil, nil
+	}
+
+	reply := &layers.DHCPv6{
+		MsgType:       layers.DHCPv6MsgTypeReply,
+		TransactionID: req.TransactionID,
+	}
+
+	reply.Options = append(reply.Options, layers.NewDHCPv6Option(layers.DHCPv6OptServerID, serverID))
+	if clientID != nil {
+		reply.Options = append(reply.Options, layers.NewDHCPv6Option(layers.DHCPv6OptClientID, clientID))
+	}
+
+	if s.config.IPv6.Address != nil {
+		reply.Options = append(reply.Options, layers.NewDHCPv6Option(layers.DHCPv6OptDNSServers, s.config.IPv6.Address.To16()))
+	}
+
+	domains := s.config.Search
+	if len(domains) == 0 && s.config.Domain != "" {
+		domains = []string{s.config.Domain}
+	}
+
+	if len(domains) > 0 {
+		reply.Options = append(reply.Options, layers.NewDHCPv6Option(layers.DHCPv6OptDomainList, encodeDomainList(domains)))
+	}
+
+	switch req.MsgType {
+	case layers.DHCPv6MsgTypeInformationRequest:
+		return reply, nil
+
+	case layers.DHCPv6MsgTypeSolicit, layers.DHCPv6MsgTypeRequest, layers.DHCPv6MsgTypeRenew, layers.DHCPv6MsgTypeRebind:
+		// Solicit must not carry a server identifier while Request and Renew must.
+		if req.MsgType == layers.DHCPv6MsgTypeSolicit && reqServerID != nil {
+			return nil, nil
+		}
+
+		if (req.MsgType == layers.DHCPv6MsgTypeRequest || req.MsgType == layers.DHCPv6MsgTypeRenew) && reqServerID == nil {
+			return nil, nil
+		}
+
+		if req.MsgType == layers.DHCPv6MsgTypeSolicit {
+			reply.MsgType = layers.DHCPv6MsgTypeAdverstise
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		for _, ia := range dhcpv6IANAs(req) {
+			data, err := s.dhcpv6Lease(req.MsgType, hex.EncodeToString(clientID), macFromClient(clientID, src), ia)
+			if err != nil {
+				return nil, err
+			}
+
+			reply.Options = append(reply.Options, layers.NewDHCPv6Option(layers.DHCPv6OptIANA, data))
+		}
+
+		return reply, nil
+
+	case layers.DHCPv6MsgTypeRelease, layers.DHCPv6MsgTypeDecline:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		leases, err := s.backend.Leases()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ia := range dhcpv6IANAs(req) {
+			lease := leaseFor(leases, ia.address)
+			if lease != nil && lease.ClientID == hex.EncodeToString(clientID) {
+				err := s.backend.DeleteLease(*lease)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		reply.Options = append(reply.Options, layers.NewDHCPv6Option(layers.DHCPv6OptStatusCode, dhcpv6Status(dhcpv6StatusSuccess)))
+		return reply, nil
+
+	case layers.DHCPv6MsgTypeConfirm:
+		status := dhcpv6StatusSuccess
+		for _, ia := range dhcpv6IANAs(req) {
+			if ia.address != nil && !s.config.IPv6.Subnet.Contains(ia.address) {
+				status = dhcpv6StatusNotOnLink
+			}
+		}
+
+		reply.Options = append(reply.Options, layers.NewDHCPv6Option(layers.DHCPv6OptStatusCode, dhcpv6Status(status)))
+		return reply, nil
+	}
+
+	return nil, nil
+}
+
+// dhcpv6Lease leases an address for an identity association of a client and returns the IA_NA option data of
+// the reply.
+func (s *Server) dhcpv6Lease(msgType layers.DHCPv6MsgType, clientID string, hwaddr net.HardwareAddr, ia dhcpv6IANA) ([]byte, error) {
+	data := binary.BigEndian.AppendUint32(nil, ia.iaid)
+
+	if !s.config.IPv6.Stateful {
+		data = append(data, make([]byte, 8)...)
+		return appendDHCPv6Option(data, layers.DHCPv6OptStatusCode, dhcpv6Status(dhcpv6StatusNoAddrsAvail)), nil
+	}
+
+	hosts, err := s.backend.Hosts()
+	if err != nil {
+		return nil, err
+	}
+
+	leases, err := s.backend.Leases()
+	if err != nil {
+		return nil, err
+	}
+
+	var host *Host
+	if hwaddr != nil {
+		h, found := hosts[hwaddr.String()]
+		if found {
+			host = &h
+		}
+	}
+
+	address := s.allocateIPv6(clientID, ia.address, host, hosts, leases)
+	if address == nil {
+		data = append(data, make([]byte, 8)...)
+		return appendDHCPv6Option(data, layers.DHCPv6OptStatusCode, dhcpv6Status(dhcpv6StatusNoAddrsAvail)), nil
+	}
+
+	// Only record the lease once the client actually requests the address.
+	if msgType == layers.DHCPv6MsgTypeSolicit {
+		s.offers[address.String()] = offer{client: clientID, expiry: time.Now().Add(offerTimeout)}
+	} else {
+		hostname := ""
+		if host != nil {
+			hostname = host.Hostname
+		}
+
+		lease := Lease{
+			Hwaddr:   hwaddr,
+			ClientID: clientID,
+			Address:  address,
+			Hostname: hostname,
+			Expiry:   expiry(s.config.IPv6.LeaseTime),
+		}
+
+		err := s.saveLease(leases, lease, func(l Lease) bool { return l.ClientID == clientID })
+		if err != nil {
+			return nil, err
+		}
+
+		delete(s.offers, address.String())
+	}
+
+	validLifetime := uint32(0xffffffff)
+	if s.config.IPv6.LeaseTime > 0 {
+		validLifetime = uint32(s.config.IPv6.LeaseTime / time.Second)
+	}
+
+	data = binary.BigEndian.AppendUint32(data, validLifetime/2)
+	data = binary.BigEndian.AppendUint32(data, validLifetime/8*7)
+
+	iaAddr := append([]byte(nil), address.To16()...)
+	iaAddr = binary.BigEndian.AppendUint32(iaAddr, validLifetime)
+	iaAddr = binary.BigEndian.AppendUint32(iaAddr, validLifetime)
+
+	return appendDHCPv6Option(data, layers.DHCPv6OptIAAddr, iaAddr), nil
+}
+
+// ipv6Allowed returns whether the address can be leased to the client.
+func (s *Server) ipv6Allowed(clientID string, address net.IP, host *Host, hosts map[string]Host, leases []Lease) bool {
+	// Clients with a reserved address can only use that address.
+	if host != nil && host.IPv6 != nil {
+		return host.IPv6.Equal(address)
+	}
+
+	if !s.config.IPv6.Subnet.Contains(address) || address.Equal(s.config.IPv6.Address) {
+		return false
+	}
+
+	// Check that the address isn't used by another client.
+	if reservedBy(hosts, address) != "" {
+		return false
+	}
+
+	lease := leaseFor(leases, address)
+	if lease != nil && lease.ClientID != clientID && !lease.Expired() {
+		return false
+	}
+
+	o, found := s.offers[address.String()]
+	if found && o.client != clientID && time.Now().Before(o.expiry) {
+		return false
+	}
+
+	// Clients can keep their current address, new addresses must come from the dynamic ranges.
+	if lease != nil && lease.ClientID == clientID {
+		return true
+	}
+
+	return inRanges(s.config.IPv6.Ranges, address)
+}
+
+// allocateIPv6 returns the address to lease to a client (nil if there's none left).
+func (s *Server) allocateIPv6(clientID string, requested net.IP, host *Host, hosts map[string]Host, leases []Lease) net.IP {
+	if host != nil && host.IPv6 != nil {
+		return host.IPv6
+	}
+
+	// Prefer the current address of the client and then the address it asked for.
+	for _, lease := range leases {
+		if lease.ClientID == clientID && lease.Address.To4() == nil && s.ipv6Allowed(clientID, lease.Address, host, hosts, leases) {
+			return lease.Address
+		}
+	}
+
+	if requested != nil && s.ipv6Allowed(clientID, requested, host, hosts, leases) {
+		return requested
+	}
+
+	return firstAvailable(s.config.IPv6.Ranges, func(address net.IP) bool {
+		return s.ipv6Allowed(clientID, address, host, hosts, leases)
+	})
+}
+
+// dhcpv6Option returns the data of an option of a DHCPv6 packet (nil if it isn't set).
+func dhcpv6Option(packet *layers.DHCPv6, code layers.DHCPv6Opt) []byte {
+	for _, o := range packet.Options {
+		if o.Code == code {
+			return o.Data
+		}
+	}
+
+	return nil
+}
+
+// dhcpv6IANAs returns the IA_NA options of a DHCPv6 packet.
+func dhcpv6IANAs(packet *layers.DHCPv6) []dhcpv6IANA {
+	ias := []dhcpv6IANA{}
+
+	for _, o := range packet.Options {
+		if o.Code != layers.DHCPv6OptIANA || len(o.Data) < 12 {
+			continue
+		}
+
+		ia := dhcpv6IANA{iaid: binary.BigEndian.Uint32(o.Data[0:4])}
+
+		// Look for the address requested by the client in the IA_NA options.
+		data := o.Data[12:]
+		for len(data) >= 4 {
+			code := binary.BigEndian.Uint16(data[0:2])
+			length := int(binary.BigEndian.Uint16(data[2:4]))
+			if len(data) < 4+length {
+				break
+			}
+
+			if layers.DHCPv6Opt(code) == layers.DHCPv6OptIAAddr && length >= net.IPv6len {
+				ia.address = net.IP(append([]byte(nil), data[4:4+net.IPv6len]...))
+			}
+
+			data = data[4+length:]
+		}
+
+		ias = append(ias, ia)
+	}
+
+	return ias
+}
+
+// appendDHCPv6Option appends an encoded DHCPv6 option to the data.
+func appendDHCPv6Option(data []byte, code layers.DHCPv6Opt, value []byte) []byte {
+	data = binary.BigEndian.AppendUint16(data, uint16(code))
+	data = binary.BigEndian.AppendUint16(data, uint16(len(value)))
+	return append(data, value...)
+}
+
+// dhcpv6Status returns the data of a status code option.
+func dhcpv6Status(code uint16) []byte {
+	return binary.BigEndian.AppendUint16(nil, code)
+}
+
+// macFromClient returns the MAC address of a DHCPv6 client, taken from its DUID when based on it or from its
+// EUI-64 link-local address otherwise (nil if it can't be found).
+func macFromClient(clientID []byte, src net.IP) net.HardwareAddr {
+	duid := &layers.DHCPv6DUID{}
+	err := duid.DecodeFromBytes(clientID)
+	if err == nil && (duid.Type == layers.DHCPv6DUIDTypeLL || duid.Type == layers.DHCPv6DUIDTypeLLT) && len(duid.LinkLayerAddress) == 6 {
+		return append(net.HardwareAddr(nil), duid.LinkLayerAddress...)
+	}
+
+	ip := src.To16()
+	if ip == nil || !ip.IsLinkLocalUnicast() || ip[11] != 0xff || ip[12] != 0xfe {
+		return nil
+	}
+
+	return net.HardwareAddr{ip[8] ^ 0x02, ip[9], ip[10], ip[13], ip[14], ip[15]}
+}
@@ -0,0 +1,325 @@
+package dhcpd
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket/layers"
+	"github.com/stretchr/testify/assert"
+)
+
+// testServerID is the DUID of the server used in the DHCPv6 tests.
+var testServerID = (&layers.DHCPv6DUID{
+	Type:             layers.DHCPv6DUIDTypeLL,
+	HardwareType:     []byte{0, byte(layers.LinkTypeEthernet)},
+	LinkLayerAddress: net.HardwareAddr{0, 0x16, 0x3e, 0xff, 0xff, 0xff},
+}).Encode()
+
+// dhcpv6ClientID returns a DUID based on the MAC address of a client.
+func dhcpv6ClientID(hwaddr net.HardwareAddr) []byte {
+	duid := &layers.DHCPv6DUID{
+		Type:             layers.DHCPv6DUIDTypeLL,
+		HardwareType:     []byte{0, byte(layers.LinkTypeEthernet)},
+		LinkLayerAddress: hwaddr,
+	}
+
+	return duid.Encode()
+}
+
+// dhcpv6Request returns a DHCPv6 request of the given type from a client for an IA_NA with the given address.
+func dhcpv6Request(msgType layers.DHCPv6MsgType, clientID []byte, serverID []byte, address string) *layers.DHCPv6 {
+	req := &layers.DHCPv6{
+		MsgType:       msgType,
+		TransactionID: []byte{1, 2, 3},
+	}
+
+	if clientID != nil {
+		req.Options = append(req.Options, layers.NewDHCPv6Option(layers.DHCPv6OptClientID, clientID))
+	}
+
+	if serverID != nil {
+		req.Options = append(req.Options, layers.NewDHCPv6Option(layers.DHCPv6OptServerID, serverID))
+	}
+
+	iana := binary.BigEndian.AppendUint32(nil, 1)
+	iana = append(iana, make([]byte, 8)...)
+	if address != "" {
+		iaAddr := append([]byte(nil), net.ParseIP(address).To16()...)
+		iaAddr = append(iaAddr, make([]byte, 8)...)
+		iana = appendDHCPv6Option(iana, layers.DHCPv6OptIAAddr, iaAddr)
+	}
+
+	req.Options = append(req.Options, layers.NewDHCPv6Option(layers.DHCPv6OptIANA, iana))
+
+	return req
+}
+
+// dhcpv6Address returns the address leased in the first IA_NA of a DHCPv6 reply (empty if there's none).
+func dhcpv6Address(reply *layers.DHCPv6) string {
+	ias := dhcpv6IANAs(reply)
+	if len(ias) == 0 || ias[0].address == nil {
+		return ""
+	}
+
+	return ias[0].address.String()
+}
+
+func Test_handleDHCPv6(t *testing.T) {
+	client1 := net.HardwareAddr{0, 0x16, 0x3e, 0, 0, 1}
+	client2 := net.HardwareAddr{0, 0x16, 0x3e, 0, 0, 2}
+	client1ID := dhcpv6ClientID(client1)
+	client2ID := dhcpv6ClientID(client2)
+	otherServerID := dhcpv6ClientID(net.HardwareAddr{0, 0x16, 0x3e, 0xee, 0xee, 0xee})
+
+	type step struct {
+		req       *layers.DHCPv6
+		msgType   layers.DHCPv6MsgType // 0 when no reply is expected.
+		address   string
+		wantLease map[string]string // Expected leases (address to hex encoded DUID) after the step.
+	}
+
+	tests := []struct {
+		name      string
+		stateless bool
+		hosts     map[string]Host
+		leases    []Lease
+		steps     []step
+	}{
+		{
+			name: "Solicit and request",
+			steps: []step{
+				{
+					req:       dhcpv6Request(layers.DHCPv6MsgTypeSolicit, client1ID, nil, ""),
+					msgType:   layers.DHCPv6MsgTypeAdverstise,
+					address:   "fd42::10",
+					wantLease: map[string]string{},
+				},
+				{
+					req:     dhcpv6Request(layers.DHCPv6MsgTypeSolicit, client2ID, nil, ""),
+					msgType: layers.DHCPv6MsgTypeAdverstise,
+					address: "fd42::11",
+				},
+				{
+					req:       dhcpv6Request(layers.DHCPv6MsgTypeRequest, client1ID, testServerID, "fd42::10"),
+					msgType:   layers.DHCPv6MsgTypeReply,
+					address:   "fd42::10",
+					wantLease: map[string]string{"fd42::10": hex.EncodeToString(client1ID)},
+				},
+			},
+		},
+		{
+			name: "Requests with an unexpected server identifier are ignored",
+			steps: []step{
+				{
+					req: dhcpv6Request(layers.DHCPv6MsgTypeSolicit, client1ID, testServerID, ""),
+				},
+				{
+					req: dhcpv6Request(layers.DHCPv6MsgTypeRequest, client1ID, nil, "fd42::10"),
+				},
+				{
+					req: dhcpv6Request(layers.DHCPv6MsgTypeRenew, client1ID, nil, "fd42::10"),
+				},
+				{
+					req:       dhcpv6Request(layers.DHCPv6MsgTypeRequest, client1ID, otherServerID, "fd42::10"),
+					wantLease: map[string]string{},
+				},
+				{
+					req: dhcpv6Request(layers.DHCPv6MsgTypeSolicit, nil, nil, ""),
+				},
+			},
+		},
+		{
+			name:   "Renewal keeps the address",
+			leases: []Lease{{ClientID: hex.EncodeToString(client1ID), Address: net.ParseIP("fd42::12"), Expiry: time.Now().Add(time.Minute)}},
+			steps: []step{
+				{
+					req:       dhcpv6Request(layers.DHCPv6MsgTypeRenew, client1ID, testServerID, "fd42::12"),
+					msgType:   layers.DHCPv6MsgTypeReply,
+					address:   "fd42::12",
+					wantLease: map[string]string{"fd42::12": hex.EncodeToString(client1ID)},
+				},
+				{
+					req:       dhcpv6Request(layers.DHCPv6MsgTypeRebind, client1ID, nil, ""),
+					msgType:   layers.DHCPv6MsgTypeReply,
+					address:   "fd42::12",
+					wantLease: map[string]string{"fd42::12": hex.EncodeToString(client1ID)},
+				},
+			},
+		},
+		{
+			name:   "Address leased to another client isn't handed out",
+			leases: []Lease{{ClientID: hex.EncodeToString(client1ID), Address: net.ParseIP("fd42::10")}},
+			steps: []step{
+				{
+					req:       dhcpv6Request(layers.DHCPv6MsgTypeRequest, client2ID, testServerID, "fd42::10"),
+					msgType:   layers.DHCPv6MsgTypeReply,
+					address:   "fd42::11",
+					wantLease: map[string]string{"fd42::10": hex.EncodeToString(client1ID), "fd42::11": hex.EncodeToString(client2ID)},
+				},
+			},
+		},
+		{
+			name:  "Static reservation",
+			hosts: map[string]Host{client1.String(): {Hostname: "c1", IPv6: net.ParseIP("fd42::50")}},
+			steps: []step{
+				{
+					req:       dhcpv6Request(layers.DHCPv6MsgTypeRequest, client1ID, testServerID, "fd42::10"),
+					msgType:   layers.DHCPv6MsgTypeReply,
+					address:   "fd42::50",
+					wantLease: map[string]string{"fd42::50": hex.EncodeToString(client1ID)},
+				},
+				{
+					req:     dhcpv6Request(layers.DHCPv6MsgTypeSolicit, client2ID, nil, "fd42::50"),
+					msgType: layers.DHCPv6MsgTypeAdverstise,
+					address: "fd42::10",
+				},
+			},
+		},
+		{
+			name: "Ranges exhausted",
+			leases: []Lease{
+				{ClientID: "01", Address: net.ParseIP("fd42::10")},
+				{ClientID: "02", Address: net.ParseIP("fd42::11")},
+				{ClientID: "03", Address: net.ParseIP("fd42::12")},
+			},
+			steps: []step{
+				{
+					req:     dhcpv6Request(layers.DHCPv6MsgTypeSolicit, client1ID, nil, ""),
+					msgType: layers.DHCPv6MsgTypeAdverstise,
+				},
+			},
+		},
+		{
+			name:      "Stateless",
+			stateless: true,
+			steps: []step{
+				{
+					req:       dhcpv6Request(layers.DHCPv6MsgTypeRequest, client1ID, testServerID, ""),
+					msgType:   layers.DHCPv6MsgTypeReply,
+					wantLease: map[string]string{},
+				},
+				{
+					req:     dhcpv6Request(layers.DHCPv6MsgTypeInformationRequest, nil, nil, ""),
+					msgType: layers.DHCPv6MsgTypeReply,
+				},
+			},
+		},
+		{
+			name: "Release",
+			leases: []Lease{
+				{ClientID: hex.EncodeToString(client1ID), Address: net.ParseIP("fd42::10")},
+				{ClientID: hex.EncodeToString(client2ID), Address: net.ParseIP("fd42::11")},
+			},
+			steps: []step{
+				{
+					req:       dhcpv6Request(layers.DHCPv6MsgTypeRelease, client1ID, testServerID, "fd42::10"),
+					msgType:   layers.DHCPv6MsgTypeReply,
+					wantLease: map[string]string{"fd42::11": hex.EncodeToString(client2ID)},
+				},
+				{
+					// Clients can't release the leases of other clients.
+					req:       dhcpv6Request(layers.DHCPv6MsgTypeRelease, client1ID, testServerID, "fd42::11"),
+					msgType:   layers.DHCPv6MsgTypeReply,
+					wantLease: map[string]string{"fd42::11": hex.EncodeToString(client2ID)},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &testBackend{hosts: tt.hosts, leases: tt.leases}
+			s := newTestServer(backend, "10.0.0.10-10.0.0.12", "fd42::10-fd42::12")
+			s.config.IPv6.Stateful = !tt.stateless
+
+			for i, step := range tt.steps {
+				reply, err := s.handleDHCPv6(step.req, net.ParseIP("fe80::1"), testServerID)
+				assert.NoError(t, err, "Step %d", i)
+
+				if step.msgType == 0 {
+					assert.Nil(t, reply, "Step %d", i)
+				} else if assert.NotNil(t, reply, "Step %d", i) {
+					assert.Equal(t, step.msgType, reply.MsgType, "Step %d", i)
+					assert.Equal(t, []byte{1, 2, 3}, reply.TransactionID, "Step %d", i)
+					assert.Equal(t, testServerID, dhcpv6Option(reply, layers.DHCPv6OptServerID), "Step %d", i)
+					assert.Equal(t, step.address, dhcpv6Address(reply), "Step %d", i)
+				}
+
+				if step.wantLease != nil {
+					leases := map[string]string{}
+					for _, lease := range backend.leases {
+						leases[lease.Address.String()] = lease.ClientID
+					}
+
+					assert.Equal(t, step.wantLease, leases, "Step %d", i)
+				}
+			}
+		})
+	}
+}
+
+func Test_handleDHCPv6Lease(t *testing.T) {
+	client := net.HardwareAddr{0, 0x16, 0x3e, 0, 0, 1}
+	clientID := dhcpv6ClientID(client)
+
+	backend := &testBackend{hosts: map[string]Host{client.String(): {Hostname: "c1"}}}
+	s := newTestServer(backend, "10.0.0.10-10.0.0.12", "fd42::10-fd42::12")
+
+	reply, err := s.handleDHCPv6(dhcpv6Request(layers.DHCPv6MsgTypeRequest, clientID, testServerID, ""), net.ParseIP("fe80::1"), testServerID)
+	assert.NoError(t, err)
+	assert.Equal(t, "fd42::10", dhcpv6Address(reply))
+
+	// The MAC address of the client is taken from its DUID to find the host.
+	assert.Len(t, backend.created, 1)
+	lease := backend.created[0]
+	assert.Equal(t, client.String(), lease.Hwaddr.String())
+	assert.Equal(t, "c1", lease.Hostname)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), lease.Expiry, time.Minute)
+
+	// The reply carries the DNS server and the domain.
+	assert.Equal(t, net.ParseIP("fd42::1").To16(), net.IP(dhcpv6Option(reply, layers.DHCPv6OptDNSServers)))
+	assert.Equal(t, encodeDomainList([]string{"lxd"}), dhcpv6Option(reply, layers.DHCPv6OptDomainList))
+
+	// Renewing the lease updates it.
+	_, err = s.handleDHCPv6(dhcpv6Request(layers.DHCPv6MsgTypeRenew, clientID, testServerID, "fd42::10"), net.ParseIP("fe80::1"), testServerID)
+	assert.NoError(t, err)
+	assert.Len(t, backend.created, 1)
+	assert.Len(t, backend.updated, 1)
+}
+
+func Test_handleDHCPv6Confirm(t *testing.T) {
+	clientID := dhcpv6ClientID(net.HardwareAddr{0, 0x16, 0x3e, 0, 0, 1})
+
+	backend := &testBackend{}
+	s := newTestServer(backend, "10.0.0.10-10.0.0.12", "fd42::10-fd42::12")
+
+	tests := map[string]uint16{
+		"fd42::10":   dhcpv6StatusSuccess,
+		"fd43::10":   dhcpv6StatusNotOnLink,
+		"2001:db8::": dhcpv6StatusNotOnLink,
+	}
+
+	for address, status := range tests {
+		reply, err := s.handleDHCPv6(dhcpv6Request(layers.DHCPv6MsgTypeConfirm, clientID, nil, address), net.ParseIP("fe80::1"), testServerID)
+		assert.NoError(t, err, address)
+		assert.Equal(t, dhcpv6Status(status), dhcpv6Option(reply, layers.DHCPv6OptStatusCode), address)
+	}
+}
+
+func Test_macFromClient(t *testing.T) {
+	hwaddr := net.HardwareAddr{0, 0x16, 0x3e, 0x12, 0x34, 0x56}
+
+	// From a DUID based on the link-layer address.
+	assert.Equal(t, hwaddr, macFromClient(dhcpv6ClientID(hwaddr), net.ParseIP("fe80::1")))
+
+	// From the EUI-64 link-local address of the client.
+	duid := (&layers.DHCPv6DUID{Type: layers.DHCPv6DUIDTypeEN, EnterpriseNumber: []byte{0, 0, 0, 1}, Identifier: []byte{1, 2, 3, 4}}).Encode()
+	assert.Equal(t, hwaddr, macFromClient(duid, net.ParseIP("fe80::216:3eff:fe12:3456")))
+
+	// Not found.
+	assert.Nil(t, macFromClient(duid, net.ParseIP("fe80::1")))
+	assert.Nil(t, macFromClient(duid, net.ParseIP("fd42::216:3eff:fe12:3456")))
+}
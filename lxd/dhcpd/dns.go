@@ -0,0 +1,300 @@
+package dhcpd
+
+import (
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mdlayher/netx/eui64"
+	"github.com/miekg/dns"
+
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// dnsForwardTimeout is the timeout of the queries forwarded to the upstream servers.
+const dnsForwardTimeout = 5 * time.Second
+
+// dnsTTL is the TTL of the records of the clients.
+const dnsTTL = 0
+
+// dnsHandler answers the DNS queries of the clients of the network.
+type dnsHandler struct {
+	server *Server
+}
+
+// serveDNS starts answering DNS queries on the given address and returns the closers of the listeners.
+func (s *Server) serveDNS(ctx context.Context, address net.IP) ([]io.Closer, error) {
+	listenAddress := net.JoinHostPort(address.String(), "53")
+	lc := net.ListenConfig{}
+
+	packetConn, err := lc.ListenPacket(ctx, "udp", listenAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := lc.Listen(ctx, "tcp", listenAddress)
+	if err != nil {
+		_ = packetConn.Close()
+		return nil, err
+	}
+
+	handler := &dnsHandler{server: s}
+
+	go func() {
+		_ = (&dns.Server{PacketConn: packetConn, Handler: handler}).ActivateAndServe()
+	}()
+
+	go func() {
+		_ = (&dns.Server{Listener: listener, Handler: handler}).ActivateAndServe()
+	}()
+
+	return []io.Closer{packetConn, listener}, nil
+}
+
+// ServeDNS answers a DNS query.
+func (h *dnsHandler) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
+	s := h.server
+
+	if s.config.Names && len(r.Question) == 1 && r.Opcode == dns.OpcodeQuery {
+		m, err := s.answerLocal(r)
+		if err != nil {
+			logger.Warn("Failed answering DNS query", logger.Ctx{"interface": s.config.Interface, "name": r.Question[0].Name, "err": err})
+
+			m = new(dns.Msg)
+			m.SetRcode(r, dns.RcodeServerFailure)
+		}
+
+		if m != nil {
+			_ = w.WriteMsg(m)
+			return
+		}
+	}
+
+	s.forward(w, r)
+}
+
+// answerLocal answers the queries for the names and addresses of the clients of the network. It returns nil for
+// the queries that must be forwarded.
+func (s *Server) answerLocal(r *dns.Msg) (*dns.Msg, error) {
+	q := r.Question[0]
+	name := strings.ToLower(q.Name)
+	domain := dns.Fqdn(strings.ToLower(s.config.Domain))
+
+	m := new(dns.Msg)
+	m.SetReply(r)
+	m.Authoritative = true
+
+	// Reverse lookups of the addresses of the network.
+	if q.Qtype == dns.TypePTR {
+		address := reverseAddress(name)
+		if address == nil {
+			return nil, nil
+		}
+
+		inSubnet := (s.config.IPv4 != nil && s.config.IPv4.Subnet.Contains(address)) || (s.config.IPv6 != nil && s.config.IPv6.Subnet.Contains(address))
+		if !inSubnet {
+			return nil, nil
+		}
+
+		records, err := s.records()
+		if err != nil {
+			return nil, err
+		}
+
+		for hostname, addresses := range records {
+			for _, a := range addresses {
+				if !a.Equal(address) {
+					continue
+				}
+
+				m.Answer = append(m.Answer, &dns.PTR{
+					Hdr: dns.RR_Header{Name: q.Name, Rrtype: dns.TypePTR, Class: dns.ClassINET, Ttl: dnsTTL},
+					Ptr: hostname + "." + domain,
+				})
+			}
+		}
+
+		if len(m.Answer) == 0 {
+			m.Rcode = dns.RcodeNameError
+		}
+
+		return m, nil
+	}
+
+	if !dns.IsSubDomain(domain, name) {
+		return nil, nil
+	}
+
+	// The domain itself has no records.
+	if name == domain {
+		return m, nil
+	}
+
+	records, err := s.records()
+	if err != nil {
+		return nil, err
+	}
+
+	addresses, found := records[strings.TrimSuffix(name, "."+domain)]
+	if !found {
+		m.Rcode = dns.RcodeNameError
+		return m, nil
+	}
+
+	for _, address := range addresses {
+		if address.To4() != nil && (q.Qtype == dns.TypeA || q.Qtype == dns.TypeANY) {
+			m.Answer = append(m.Answer, &dns.A{
+				Hdr: dns.RR_Header{Name: q.Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: dnsTTL},
+				A:   address.To4(),
+			})
+		} else if address.To4() == nil && (q.Qtype == dns.TypeAAAA || q.Qtype == dns.TypeANY) {
+			m.Answer = append(m.Answer, &dns.AAAA{
+				Hdr:  dns.RR_Header{Name: q.Name, Rrtype: dns.TypeAAAA, Class: dns.ClassINET, Ttl: dnsTTL},
+				AAAA: address,
+			})
+		}
+	}
+
+	return m, nil
+}
+
+// records returns the addresses of the clients of the network keyed by hostname.
+func (s *Server) records() (map[string][]net.IP, error) {
+	hosts, err := s.backend.Hosts()
+	if err != nil {
+		return nil, err
+	}
+
+	leases, err := s.backend.Leases()
+	if err != nil {
+		return nil, err
+	}
+
+	records := map[string][]net.IP{}
+
+	add := func(hostname string, address net.IP) {
+		hostname = strings.ToLower(hostname)
+		if hostname == "" || address == nil {
+			return
+		}
+
+		for _, a := range records[hostname] {
+			if a.Equal(address) {
+				return
+			}
+		}
+
+		records[hostname] = append(records[hostname], address)
+	}
+
+	add("_gateway", s.ipv4Address())
+	add("_gateway", s.ipv6Address())
+
+	for hwaddr, host := range hosts {
+		add(host.Hostname, host.IPv4)
+		add(host.Hostname, host.IPv6)
+
+		// Clients not using stateful DHCPv6 configure their address using SLAAC.
+		if s.config.IPv6 != nil && !(s.config.IPv6.DHCP && s.config.IPv6.Stateful) {
+			mac, err := net.ParseMAC(hwaddr)
+			if err != nil {
+				continue
+			}
+
+			address, err := eui64.ParseMAC(s.config.IPv6.Subnet.IP, mac)
+			if err == nil {
+				add(host.Hostname, address)
+			}
+		}
+	}
+
+	for _, lease := range leases {
+		if !lease.Expired() {
+			add(lease.Hostname, lease.Address)
+		}
+	}
+
+	return records, nil
+}
+
+// forward forwards a query to the upstream servers of the host.
+func (s *Server) forward(w dns.ResponseWriter, r *dns.Msg) {
+	network := "udp"
+	_, ok := w.RemoteAddr().(*net.TCPAddr)
+	if ok {
+		network = "tcp"
+	}
+
+	config, err := dns.ClientConfigFromFile("/etc/resolv.conf")
+	if err == nil {
+		client := &dns.Client{Net: network, Timeout: dnsForwardTimeout}
+
+		for _, server := range config.Servers {
+			// Don't forward the queries to ourselves.
+			ip := net.ParseIP(server)
+			if ip != nil && (ip.Equal(s.ipv4Address()) || ip.Equal(s.ipv6Address())) {
+				continue
+			}
+
+			resp, _, err := client.Exchange(r, net.JoinHostPort(server, config.Port))
+			if err != nil {
+				continue
+			}
+
+			_ = w.WriteMsg(resp)
+			return
+		}
+	}
+
+	m := new(dns.Msg)
+	m.SetRcode(r, dns.RcodeServerFailure)
+	_ = w.WriteMsg(m)
+}
+
+// reverseAddress returns the address of a reverse lookup name (nil if the name isn't one).
+func reverseAddress(name string) net.IP {
+	name = strings.TrimSuffix(name, ".")
+
+	if strings.HasSuffix(name, ".in-addr.arpa") {
+		labels := strings.Split(strings.TrimSuffix(name, ".in-addr.arpa"), ".")
+		if len(labels) != 4 {
+			return nil
+		}
+
+		for i, j := 0, len(labels)-1; i < j; i, j = i+1, j-1 {
+			labels[i], labels[j] = labels[j], labels[i]
+		}
+
+		return net.ParseIP(strings.Join(labels, ".")).To4()
+	}
+
+	if strings.HasSuffix(name, ".ip6.arpa") {
+		nibbles := strings.Split(strings.TrimSuffix(name, ".ip6.arpa"), ".")
+		if len(nibbles) != 32 {
+			return nil
+		}
+
+		address := make(net.IP, net.IPv6len)
+		for i, nibble := range nibbles {
+			value, err := strconv.ParseUint(nibble, 16, 8)
+			if err != nil || len(nibble) != 1 {
+				return nil
+			}
+
+			// The nibbles are in reverse order.
+			pos := 31 - i
+			if pos%2 == 0 {
+				address[pos/2] |= byte(value) << 4
+			} else {
+				address[pos/2] |= byte(value)
+			}
+		}
+
+		return address
+	}
+
+	return nil
+}
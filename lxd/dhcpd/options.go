@@ -0,0 +1,151 @@
+package dhcpd
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/canonical/lxd/shared"
+)
+
+// reservedOptions are the DHCPv4 options managed by the server that can't be overridden per host.
+var reservedOptions = []uint8{0, 50, 51, 52, 53, 54, 55, 57, 58, 59, 61, 255}
+
+// ParseOptions parses a comma-separated list of DHCPv4 options in CODE=VALUE format. The value is either a
+// space-separated list of IPv4 addresses, a hexadecimal string prefixed with 0x or a text string.
+func ParseOptions(value string) (map[uint8][]byte, error) {
+	options := map[uint8][]byte{}
+
+	for _, entry := range shared.SplitNTrimSpace(value, ",", -1, true) {
+		codeStr, optValue, found := strings.Cut(entry, "=")
+		if !found || optValue == "" {
+			return nil, fmt.Errorf("Invalid DHCP option %q (must be CODE=VALUE)", entry)
+		}
+
+		code, err := strconv.ParseUint(strings.TrimSpace(codeStr), 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid DHCP option code %q", codeStr)
+		}
+
+		for _, reserved := range reservedOptions {
+			if uint8(code) == reserved {
+				return nil, fmt.Errorf("DHCP option %d is managed by the server", code)
+			}
+		}
+
+		_, duplicate := options[uint8(code)]
+		if duplicate {
+			return nil, fmt.Errorf("Duplicate DHCP option %d", code)
+		}
+
+		data, err := parseOptionValue(strings.TrimSpace(optValue))
+		if err != nil {
+			return nil, fmt.Errorf("Invalid value for DHCP option %d: %w", code, err)
+		}
+
+		options[uint8(code)] = data
+	}
+
+	return options, nil
+}
+
+// parseOptionValue returns the encoded value of a DHCPv4 option.
+func parseOptionValue(value string) ([]byte, error) {
+	if strings.HasPrefix(value, "0x") {
+		data, err := hex.DecodeString(strings.TrimPrefix(value, "0x"))
+		if err != nil {
+			return nil, err
+		}
+
+		if len(data) == 0 || len(data) > 255 {
+			return nil, fmt.Errorf("Value must be between 1 and 255 bytes long")
+		}
+
+		return data, nil
+	}
+
+	var data []byte
+
+	// Lists of IPv4 addresses.
+	for _, field := range strings.Fields(value) {
+		ip := net.ParseIP(field).To4()
+		if ip == nil {
+			data = nil
+			break
+		}
+
+		data = append(data, ip...)
+	}
+
+	if data == nil {
+		data = []byte(value)
+	}
+
+	if len(data) > 255 {
+		return nil, fmt.Errorf("Value must be between 1 and 255 bytes long")
+	}
+
+	return data, nil
+}
+
+// ParseExpiry parses a lease time (a number of seconds optionally followed by one of the m, h, d or w units, or
+// infinite). Leases that never expire have a lease time of 0.
+func ParseExpiry(value string) (time.Duration, error) {
+	if value == "infinite" {
+		return 0, nil
+	}
+
+	units := map[byte]time.Duration{
+		's': time.Second,
+		'm': time.Minute,
+		'h': time.Hour,
+		'd': 24 * time.Hour,
+		'w': 7 * 24 * time.Hour,
+	}
+
+	unit := time.Second
+	number := value
+
+	if value != "" {
+		u, found := units[value[len(value)-1]]
+		if found {
+			unit = u
+			number = value[:len(value)-1]
+		}
+	}
+
+	count, err := strconv.ParseUint(number, 10, 32)
+	if err != nil || count == 0 {
+		return 0, fmt.Errorf("Invalid lease time %q", value)
+	}
+
+	expiry := time.Duration(count) * unit
+	if expiry < 2*time.Minute {
+		return 0, fmt.Errorf("Lease time %q is too short (must be at least 2 minutes)", value)
+	}
+
+	return expiry, nil
+}
+
+// encodeDomainList encodes a list of domain names in the DNS wire format (RFC 1035) used by the DHCP options.
+func encodeDomainList(domains []string) []byte {
+	data := []byte{}
+
+	for _, domain := range domains {
+		for _, label := range strings.Split(strings.Trim(domain, "."), ".") {
+			if label == "" || len(label) > 63 {
+				continue
+			}
+
+			data = append(data, byte(len(label)))
+			data = append(data, label...)
+		}
+
+		data = append(data, 0)
+	}
+
+	return data
+}
@@ -0,0 +1,43 @@
+package dhcpd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseOptions(t *testing.T) {
+	options, err := ParseOptions("42=192.0.2.1 192.0.2.2, 66=tftp.example.com,224=0x0a0b")
+	assert.NoError(t, err)
+	assert.Equal(t, map[uint8][]byte{
+		42:  {192, 0, 2, 1, 192, 0, 2, 2},
+		66:  []byte("tftp.example.com"),
+		224: {0x0a, 0x0b},
+	}, options)
+
+	for _, value := range []string{"42", "256=1", "53=1", "42=1.2.3.4,42=5.6.7.8", "224=0xzz"} {
+		_, err := ParseOptions(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func Test_ParseExpiry(t *testing.T) {
+	tests := map[string]time.Duration{
+		"infinite": 0,
+		"3600":     time.Hour,
+		"90m":      90 * time.Minute,
+		"1d":       24 * time.Hour,
+	}
+
+	for value, expected := range tests {
+		expiry, err := ParseExpiry(value)
+		assert.NoError(t, err, value)
+		assert.Equal(t, expected, expiry, value)
+	}
+
+	for _, value := range []string{"", "1m", "0", "1y", "-5h"} {
+		_, err := ParseExpiry(value)
+		assert.Error(t, err, value)
+	}
+}
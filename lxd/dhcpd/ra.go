@@ -0,0 +1,148 @@
+package dhcpd
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"time"
+
+	"github.com/mdlayher/ndp"
+
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// raInterval is the interval between unsolicited router advertisements.
+const raInterval = 200 * time.Second
+
+// raRouterLifetime is the router lifetime advertised to the clients.
+const raRouterLifetime = 30 * time.Minute
+
+// raRetryInterval is how often listening is retried while the interface has no link-local address yet.
+const raRetryInterval = 5 * time.Second
+
+// serveRA sends router advertisements on the network interface, both periodically and in response to router
+// solicitations, until the context is cancelled.
+func (s *Server) serveRA(ctx context.Context, iface *net.Interface) {
+	var conn *ndp.Conn
+
+	// The link-local address of a bridge only shows up once it has a port with carrier.
+	for {
+		var err error
+
+		conn, _, err = ndp.Listen(iface, ndp.LinkLocal)
+		if err == nil {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(raRetryInterval):
+		}
+	}
+
+	defer func() { _ = conn.Close() }()
+
+	err := conn.JoinGroup(netip.MustParseAddr("ff02::2"))
+	if err != nil {
+		logger.Warn("Failed joining the all routers multicast group", logger.Ctx{"interface": s.config.Interface, "err": err})
+	}
+
+	solicited := make(chan struct{}, 1)
+
+	go func() {
+		for {
+			msg, _, _, err := conn.ReadFrom()
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+
+				continue
+			}
+
+			_, ok := msg.(*ndp.RouterSolicitation)
+			if !ok {
+				continue
+			}
+
+			select {
+			case solicited <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	// Unblock the reader when stopping.
+	go func() {
+		<-ctx.Done()
+		_ = conn.SetReadDeadline(time.Now())
+	}()
+
+	ra := s.routerAdvertisement(iface)
+	allNodes := netip.MustParseAddr("ff02::1")
+
+	for {
+		err := conn.WriteTo(ra, nil, allNodes)
+		if err != nil {
+			logger.Warn("Failed sending router advertisement", logger.Ctx{"interface": s.config.Interface, "err": err})
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-solicited:
+		case <-time.After(raInterval):
+		}
+	}
+}
+
+// routerAdvertisement returns the router advertisement for the network.
+func (s *Server) routerAdvertisement(iface *net.Interface) *ndp.RouterAdvertisement {
+	stateful := s.config.IPv6.DHCP && s.config.IPv6.Stateful
+	ones, _ := s.config.IPv6.Subnet.Mask.Size()
+	prefix, _ := netip.AddrFromSlice(s.config.IPv6.Subnet.IP.To16())
+
+	ra := &ndp.RouterAdvertisement{
+		CurrentHopLimit:      64,
+		ManagedConfiguration: stateful,
+		OtherConfiguration:   s.config.IPv6.DHCP,
+		RouterLifetime:       raRouterLifetime,
+		Options: []ndp.Option{
+			&ndp.PrefixInformation{
+				PrefixLength:                   uint8(ones),
+				OnLink:                         true,
+				AutonomousAddressConfiguration: !stateful,
+				ValidLifetime:                  raRouterLifetime,
+				PreferredLifetime:              raRouterLifetime,
+				Prefix:                         prefix,
+			},
+			&ndp.LinkLayerAddress{
+				Direction: ndp.Source,
+				Addr:      iface.HardwareAddr,
+			},
+		},
+	}
+
+	server, ok := netip.AddrFromSlice(s.config.IPv6.Address.To16())
+	if ok {
+		ra.Options = append(ra.Options, &ndp.RecursiveDNSServer{
+			Lifetime: raRouterLifetime,
+			Servers:  []netip.Addr{server},
+		})
+	}
+
+	domains := s.config.Search
+	if len(domains) == 0 && s.config.Domain != "" {
+		domains = []string{s.config.Domain}
+	}
+
+	if len(domains) > 0 {
+		ra.Options = append(ra.Options, &ndp.DNSSearchList{
+			Lifetime:    raRouterLifetime,
+			DomainNames: domains,
+		})
+	}
+
+	return ra
+}
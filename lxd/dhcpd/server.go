@@ -0,0 +1,411 @@
+// Package dhcpd implements the native DHCPv4, DHCPv6, router advertisement and DNS services of managed bridges.
+package dhcpd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/revert"
+)
+
+// offerTimeout is how long an offered address is kept aside for the client it was offered to.
+const offerTimeout = 30 * time.Second
+
+// expiryInterval is how often the expired leases are removed.
+const expiryInterval = time.Minute
+
+// Lease represents an address leased to a client.
+type Lease struct {
+	// MAC address of the client (empty for DHCPv6 clients whose MAC address isn't known).
+	Hwaddr net.HardwareAddr
+
+	// DUID of the client (hex encoded) for DHCPv6 leases.
+	ClientID string
+
+	// Leased address.
+	Address net.IP
+
+	// Hostname of the client.
+	Hostname string
+
+	// Expiry time of the lease (zero for leases that never expire).
+	Expiry time.Time
+}
+
+// Expired returns whether the lease has expired.
+func (l Lease) Expired() bool {
+	return !l.Expiry.IsZero() && time.Now().After(l.Expiry)
+}
+
+// Host represents a known client of the network.
+type Host struct {
+	// Hostname of the client.
+	Hostname string
+
+	// Statically reserved IPv4 address (if any).
+	IPv4 net.IP
+
+	// Statically reserved IPv6 address (if any).
+	IPv6 net.IP
+
+	// Additional DHCPv4 options sent to the client (see ParseOptions).
+	Options map[uint8][]byte
+}
+
+// Backend provides the known hosts of the network and stores the leases handed out by the server.
+type Backend interface {
+	// Hosts returns the known clients of the network keyed by MAC address.
+	Hosts() (map[string]Host, error)
+
+	// Leases returns the current leases.
+	Leases() ([]Lease, error)
+
+	// CreateLease records a new lease.
+	CreateLease(lease Lease) error
+
+	// UpdateLease records the renewal of an existing lease.
+	UpdateLease(lease Lease) error
+
+	// DeleteLease removes a lease that has been released, declined or has expired.
+	DeleteLease(lease Lease) error
+}
+
+// IPv4Config represents the DHCPv4 configuration of the server.
+type IPv4Config struct {
+	// Address of the server.
+	Address net.IP
+
+	// Subnet of the network.
+	Subnet *net.IPNet
+
+	// Whether to answer DHCPv4 requests.
+	DHCP bool
+
+	// Gateway advertised to the clients (defaults to the server address).
+	Gateway net.IP
+
+	// Ranges of addresses handed out dynamically.
+	Ranges []shared.IPRange
+
+	// Lease time (0 for leases that never expire).
+	LeaseTime time.Duration
+}
+
+// IPv6Config represents the DHCPv6 and router advertisement configuration of the server.
+type IPv6Config struct {
+	// Address of the server.
+	Address net.IP
+
+	// Subnet of the network.
+	Subnet *net.IPNet
+
+	// Whether to answer DHCPv6 requests (stateless unless Stateful is set).
+	DHCP bool
+
+	// Whether to hand out addresses through DHCPv6 rather than letting the clients use SLAAC.
+	Stateful bool
+
+	// Ranges of addresses handed out dynamically.
+	Ranges []shared.IPRange
+
+	// Lease time (0 for leases that never expire).
+	LeaseTime time.Duration
+}
+
+// Config represents the configuration of the server.
+type Config struct {
+	// Name of the network interface to serve.
+	Interface string
+
+	// DNS domain of the network.
+	Domain string
+
+	// DNS search domains advertised to the clients.
+	Search []string
+
+	// Whether to answer DNS queries for the names of the clients (other queries are always forwarded).
+	Names bool
+
+	// MTU advertised to the DHCPv4 clients (0 to not advertise it).
+	MTU uint32
+
+	// DHCPv4 configuration (nil to disable IPv4).
+	IPv4 *IPv4Config
+
+	// DHCPv6 and router advertisement configuration (nil to disable IPv6).
+	IPv6 *IPv6Config
+}
+
+// offer represents an address offered to a client that hasn't requested it yet.
+type offer struct {
+	client string
+	expiry time.Time
+}
+
+// Server represents a native DHCP server running on a network interface.
+type Server struct {
+	config  Config
+	backend Backend
+
+	// Serializes the handling of the requests (and the changes to the leases).
+	mu     sync.Mutex
+	offers map[string]offer
+
+	cancel  context.CancelFunc
+	closers []io.Closer
+}
+
+// NewServer returns a new server for the given configuration.
+func NewServer(config Config, backend Backend) *Server {
+	return &Server{config: config, backend: backend, offers: map[string]offer{}}
+}
+
+// Start starts serving the network interface.
+func (s *Server) Start() error {
+	iface, err := net.InterfaceByName(s.config.Interface)
+	if err != nil {
+		return fmt.Errorf("Failed getting interface %q: %w", s.config.Interface, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	revert := revert.New()
+	defer revert.Fail()
+	revert.Add(s.Stop)
+
+	if s.config.IPv4 != nil && s.config.IPv4.DHCP {
+		conn, err := listenUDP(ctx, "udp4", "0.0.0.0:67", iface.Name)
+		if err != nil {
+			return fmt.Errorf("Failed listening for DHCPv4 requests: %w", err)
+		}
+
+		s.closers = append(s.closers, conn)
+		go s.serveDHCPv4(conn)
+	}
+
+	if s.config.IPv6 != nil {
+		if s.config.IPv6.DHCP {
+			conn, err := listenUDP(ctx, "udp6", "[::]:547", iface.Name)
+			if err != nil {
+				return fmt.Errorf("Failed listening for DHCPv6 requests: %w", err)
+			}
+
+			s.closers = append(s.closers, conn)
+
+			err = joinGroup(conn, iface, dhcpv6Multicast)
+			if err != nil {
+				return fmt.Errorf("Failed joining the DHCPv6 multicast group: %w", err)
+			}
+
+			go s.serveDHCPv6(conn, iface)
+		}
+
+		go s.serveRA(ctx, iface)
+	}
+
+	for _, address := range []net.IP{s.ipv4Address(), s.ipv6Address()} {
+		if address == nil {
+			continue
+		}
+
+		closers, err := s.serveDNS(ctx, address)
+		if err != nil {
+			return fmt.Errorf("Failed listening for DNS queries on %q: %w", address.String(), err)
+		}
+
+		s.closers = append(s.closers, closers...)
+	}
+
+	go s.expireLeases(ctx)
+
+	revert.Success()
+	return nil
+}
+
+// Stop stops serving the network interface.
+func (s *Server) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+
+	for _, closer := range s.closers {
+		_ = closer.Close()
+	}
+
+	s.closers = nil
+}
+
+// Config returns the configuration of the server.
+func (s *Server) Config() Config {
+	return s.config
+}
+
+// ipv4Address returns the IPv4 address of the server (nil if IPv4 isn't served).
+func (s *Server) ipv4Address() net.IP {
+	if s.config.IPv4 == nil {
+		return nil
+	}
+
+	return s.config.IPv4.Address
+}
+
+// ipv6Address returns the IPv6 address of the server (nil if IPv6 isn't served).
+func (s *Server) ipv6Address() net.IP {
+	if s.config.IPv6 == nil {
+		return nil
+	}
+
+	return s.config.IPv6.Address
+}
+
+// expireLeases periodically removes the expired leases.
+func (s *Server) expireLeases(ctx context.Context) {
+	ticker := time.NewTicker(expiryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		s.removeExpired()
+	}
+}
+
+// removeExpired removes the expired leases and offers.
+func (s *Server) removeExpired() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	leases, err := s.backend.Leases()
+	if err != nil {
+		logger.Warn("Failed getting DHCP leases", logger.Ctx{"interface": s.config.Interface, "err": err})
+	}
+
+	for _, lease := range leases {
+		if !lease.Expired() {
+			continue
+		}
+
+		err := s.backend.DeleteLease(lease)
+		if err != nil {
+			logger.Warn("Failed removing expired DHCP lease", logger.Ctx{"interface": s.config.Interface, "address": lease.Address.String(), "err": err})
+		}
+	}
+
+	for address, o := range s.offers {
+		if time.Now().After(o.expiry) {
+			delete(s.offers, address)
+		}
+	}
+}
+
+// listenUDP listens on a UDP address of a network interface. Other sockets can listen on the same address for
+// other interfaces.
+func listenUDP(ctx context.Context, network string, address string, interfaceName string) (*net.UDPConn, error) {
+	lc := net.ListenConfig{
+		Control: func(_ string, _ string, c syscall.RawConn) error {
+			var sockErr error
+
+			err := c.Control(func(fd uintptr) {
+				sockErr = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEADDR, 1)
+				if sockErr != nil {
+					return
+				}
+
+				sockErr = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_BROADCAST, 1)
+				if sockErr != nil {
+					return
+				}
+
+				sockErr = unix.SetsockoptString(int(fd), unix.SOL_SOCKET, unix.SO_BINDTODEVICE, interfaceName)
+			})
+			if err != nil {
+				return err
+			}
+
+			return sockErr
+		},
+	}
+
+	conn, err := lc.ListenPacket(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	return conn.(*net.UDPConn), nil
+}
+
+// leaseFor returns the lease of the given address (if any).
+func leaseFor(leases []Lease, address net.IP) *Lease {
+	for i := range leases {
+		if leases[i].Address.Equal(address) {
+			return &leases[i]
+		}
+	}
+
+	return nil
+}
+
+// reservedBy returns the MAC address of the host the given address is reserved for (if any).
+func reservedBy(hosts map[string]Host, address net.IP) string {
+	for hwaddr, host := range hosts {
+		if host.IPv4.Equal(address) || host.IPv6.Equal(address) {
+			return hwaddr
+		}
+	}
+
+	return ""
+}
+
+// expiry returns the expiry time of a lease of the given duration (zero for leases that never expire).
+func expiry(leaseTime time.Duration) time.Time {
+	if leaseTime == 0 {
+		return time.Time{}
+	}
+
+	return time.Now().Add(leaseTime)
+}
+
+// saveLease records the lease of an address to a client, replacing the leases of the same client (for the same
+// address family) and the stale leases of the address.
+func (s *Server) saveLease(leases []Lease, lease Lease, sameClient func(Lease) bool) error {
+	renewal := false
+
+	for _, l := range leases {
+		if (l.Address.To4() == nil) != (lease.Address.To4() == nil) {
+			continue
+		}
+
+		if l.Address.Equal(lease.Address) && sameClient(l) {
+			renewal = true
+			continue
+		}
+
+		if l.Address.Equal(lease.Address) || sameClient(l) {
+			err := s.backend.DeleteLease(l)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if renewal {
+		return s.backend.UpdateLease(lease)
+	}
+
+	return s.backend.CreateLease(lease)
+}
@@ -0,0 +1,211 @@
+package dhcpd
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/canonical/lxd/shared"
+)
+
+// testBackend is an in-memory Backend that records the changes made to the leases.
+type testBackend struct {
+	hosts  map[string]Host
+	leases []Lease
+
+	created []Lease
+	updated []Lease
+	deleted []Lease
+}
+
+func (b *testBackend) Hosts() (map[string]Host, error) {
+	return b.hosts, nil
+}
+
+func (b *testBackend) Leases() ([]Lease, error) {
+	return append([]Lease(nil), b.leases...), nil
+}
+
+func (b *testBackend) CreateLease(lease Lease) error {
+	b.created = append(b.created, lease)
+	b.leases = append(b.leases, lease)
+	return nil
+}
+
+func (b *testBackend) UpdateLease(lease Lease) error {
+	b.updated = append(b.updated, lease)
+	for i := range b.leases {
+		if b.leases[i].Address.Equal(lease.Address) {
+			b.leases[i] = lease
+		}
+	}
+
+	return nil
+}
+
+func (b *testBackend) DeleteLease(lease Lease) error {
+	b.deleted = append(b.deleted, lease)
+	for i := range b.leases {
+		if b.leases[i].Address.Equal(lease.Address) {
+			b.leases = append(b.leases[:i], b.leases[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+// lease returns the lease of the given address (nil if there's none).
+func (b *testBackend) lease(address string) *Lease {
+	return leaseFor(b.leases, net.ParseIP(address))
+}
+
+// newTestServer returns a server for 10.0.0.0/24 and fd42::/64 handing out the addresses of the given ranges.
+func newTestServer(backend *testBackend, ipv4Range string, ipv6Range string) *Server {
+	_, subnet4, _ := net.ParseCIDR("10.0.0.0/24")
+	_, subnet6, _ := net.ParseCIDR("fd42::/64")
+
+	ipv4Ranges, _ := shared.ParseIPRanges(ipv4Range)
+	ipv6Ranges, _ := shared.ParseIPRanges(ipv6Range)
+
+	config := Config{
+		Interface: "lxdbr0",
+		Domain:    "lxd",
+		IPv4: &IPv4Config{
+			Address:   net.ParseIP("10.0.0.1").To4(),
+			Subnet:    subnet4,
+			DHCP:      true,
+			LeaseTime: time.Hour,
+		},
+		IPv6: &IPv6Config{
+			Address:   net.ParseIP("fd42::1"),
+			Subnet:    subnet6,
+			DHCP:      true,
+			Stateful:  true,
+			LeaseTime: time.Hour,
+		},
+	}
+
+	for _, r := range ipv4Ranges {
+		config.IPv4.Ranges = append(config.IPv4.Ranges, *r)
+	}
+
+	for _, r := range ipv6Ranges {
+		config.IPv6.Ranges = append(config.IPv6.Ranges, *r)
+	}
+
+	return NewServer(config, backend)
+}
+
+func Test_removeExpired(t *testing.T) {
+	backend := &testBackend{
+		leases: []Lease{
+			{Hwaddr: net.HardwareAddr{0, 0x16, 0x3e, 0, 0, 1}, Address: net.ParseIP("10.0.0.10").To4(), Expiry: time.Now().Add(-time.Minute)},
+			{Hwaddr: net.HardwareAddr{0, 0x16, 0x3e, 0, 0, 2}, Address: net.ParseIP("10.0.0.11").To4(), Expiry: time.Now().Add(time.Minute)},
+			{Hwaddr: net.HardwareAddr{0, 0x16, 0x3e, 0, 0, 3}, Address: net.ParseIP("10.0.0.12").To4()},
+		},
+	}
+
+	s := newTestServer(backend, "10.0.0.10-10.0.0.20", "fd42::10-fd42::20")
+	s.offers["10.0.0.13"] = offer{client: "00:16:3e:00:00:04", expiry: time.Now().Add(-time.Second)}
+	s.offers["10.0.0.14"] = offer{client: "00:16:3e:00:00:05", expiry: time.Now().Add(offerTimeout)}
+
+	s.removeExpired()
+
+	// Only the expired lease is removed, leases without an expiry never expire.
+	assert.Len(t, backend.deleted, 1)
+	assert.Equal(t, "10.0.0.10", backend.deleted[0].Address.String())
+	assert.Nil(t, backend.lease("10.0.0.10"))
+	assert.NotNil(t, backend.lease("10.0.0.11"))
+	assert.NotNil(t, backend.lease("10.0.0.12"))
+
+	assert.NotContains(t, s.offers, "10.0.0.13")
+	assert.Contains(t, s.offers, "10.0.0.14")
+}
+
+func Test_saveLease(t *testing.T) {
+	hwaddr := net.HardwareAddr{0, 0x16, 0x3e, 0, 0, 1}
+	sameClient := func(l Lease) bool { return l.Hwaddr.String() == hwaddr.String() }
+
+	tests := []struct {
+		name    string
+		leases  []Lease
+		lease   Lease
+		created int
+		updated int
+		deleted []string
+	}{
+		{
+			name:    "New lease",
+			lease:   Lease{Hwaddr: hwaddr, Address: net.ParseIP("10.0.0.10").To4()},
+			created: 1,
+		},
+		{
+			name:    "Renewal",
+			leases:  []Lease{{Hwaddr: hwaddr, Address: net.ParseIP("10.0.0.10").To4()}},
+			lease:   Lease{Hwaddr: hwaddr, Address: net.ParseIP("10.0.0.10").To4()},
+			updated: 1,
+		},
+		{
+			name:    "New address replaces the previous lease of the client",
+			leases:  []Lease{{Hwaddr: hwaddr, Address: net.ParseIP("10.0.0.10").To4()}},
+			lease:   Lease{Hwaddr: hwaddr, Address: net.ParseIP("10.0.0.11").To4()},
+			created: 1,
+			deleted: []string{"10.0.0.10"},
+		},
+		{
+			name:    "Stale lease of the address is replaced",
+			leases:  []Lease{{Hwaddr: net.HardwareAddr{0, 0x16, 0x3e, 0, 0, 2}, Address: net.ParseIP("10.0.0.10").To4(), Expiry: time.Now().Add(-time.Minute)}},
+			lease:   Lease{Hwaddr: hwaddr, Address: net.ParseIP("10.0.0.10").To4()},
+			created: 1,
+			deleted: []string{"10.0.0.10"},
+		},
+		{
+			name:    "Leases of the other address family are kept",
+			leases:  []Lease{{Hwaddr: hwaddr, Address: net.ParseIP("fd42::10")}},
+			lease:   Lease{Hwaddr: hwaddr, Address: net.ParseIP("10.0.0.10").To4()},
+			created: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &testBackend{leases: tt.leases}
+			s := newTestServer(backend, "10.0.0.10-10.0.0.20", "fd42::10-fd42::20")
+
+			err := s.saveLease(append([]Lease(nil), tt.leases...), tt.lease, sameClient)
+			assert.NoError(t, err)
+
+			assert.Len(t, backend.created, tt.created)
+			assert.Len(t, backend.updated, tt.updated)
+
+			var deleted []string
+			for _, l := range backend.deleted {
+				deleted = append(deleted, l.Address.String())
+			}
+
+			assert.Equal(t, tt.deleted, deleted)
+		})
+	}
+}
+
+func Test_firstAvailable(t *testing.T) {
+	ranges, err := shared.ParseIPRanges("10.0.0.10-10.0.0.11,10.0.0.20-10.0.0.21")
+	assert.NoError(t, err)
+
+	var ipRanges []shared.IPRange
+	for _, r := range ranges {
+		ipRanges = append(ipRanges, *r)
+	}
+
+	used := map[string]bool{"10.0.0.10": true, "10.0.0.11": true}
+	available := func(address net.IP) bool { return !used[address.String()] }
+
+	assert.Equal(t, "10.0.0.20", firstAvailable(ipRanges, available).String())
+
+	used["10.0.0.20"] = true
+	used["10.0.0.21"] = true
+	assert.Nil(t, firstAvailable(ipRanges, available))
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/miekg/dns"
 
+	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/logger"
 )
@@ -48,8 +49,8 @@ func (d *dnsHandler) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 		return
 	}
 
-	// Check that it's a supported request type.
-	if r.Question[0].Qtype != dns.TypeAXFR && r.Question[0].Qtype != dns.TypeIXFR && r.Question[0].Qtype != dns.TypeSOA {
+	// Only standard queries are supported.
+	if r.Opcode != dns.OpcodeQuery {
 		m := new(dns.Msg)
 		m.SetRcode(r, dns.RcodeNotImplemented)
 		err := w.WriteMsg(m)
@@ -61,7 +62,7 @@ func (d *dnsHandler) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 	}
 
 	// Extract the request information.
-	name := strings.TrimSuffix(r.Question[0].Name, ".")
+	name := strings.ToLower(strings.TrimSuffix(r.Question[0].Name, "."))
 	ip, _, err := net.SplitHostPort(w.RemoteAddr().String())
 	if err != nil {
 		m := new(dns.Msg)
@@ -74,71 +75,200 @@ func (d *dnsHandler) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 		return
 	}
 
-	// Prepare the response.
-	m := new(dns.Msg)
-	m.SetReply(r)
-	m.Authoritative = true
+	var m *dns.Msg
+	if r.Question[0].Qtype == dns.TypeAXFR || r.Question[0].Qtype == dns.TypeIXFR {
+		m = d.transfer(r, name, ip, w.TsigStatus() == nil)
+	} else {
+		m = d.query(r, name, ip, w.TsigStatus() == nil)
+	}
+
+	tsig := r.IsTsig()
+	if tsig != nil && w.TsigStatus() == nil {
+		m.SetTsig(tsig.Hdr.Name, tsig.Algorithm, 300, time.Now().Unix())
+	}
+
+	err = w.WriteMsg(m)
+	if err != nil {
+		logger.Error("Unable to write message", logger.Ctx{"err": err})
+	}
+}
 
+// transfer handles AXFR and IXFR requests for a zone.
+func (d *dnsHandler) transfer(r *dns.Msg, name string, ip string, tsigStatus bool) *dns.Msg {
 	// Load the zone.
-	zone, err := d.server.zoneRetriever(name, r.Question[0].Qtype != dns.TypeSOA)
+	zone, _, err := d.server.loadZone(name, 0)
 	if err != nil {
 		// On failure, return NXDOMAIN.
 		m := new(dns.Msg)
 		m.SetRcode(r, dns.RcodeNameError)
-		err := w.WriteMsg(m)
-		if err != nil {
-			logger.Error("Unable to write message", logger.Ctx{"err": err})
-		}
-
-		return
+		return m
 	}
 
 	// Check access.
-	if !d.isAllowed(zone.Info, ip, r.IsTsig(), w.TsigStatus() == nil) {
+	if !d.isAllowed(zone.info, ip, r.IsTsig(), tsigStatus) {
 		// On auth failure, return NXDOMAIN to avoid information leaks.
 		m := new(dns.Msg)
 		m.SetRcode(r, dns.RcodeNameError)
-		err := w.WriteMsg(m)
-		if err != nil {
-			logger.Error("Unable to write message", logger.Ctx{"err": err})
-		}
+		return m
+	}
 
-		return
+	// Prepare the response.
+	m := new(dns.Msg)
+	m.SetReply(r)
+	m.Authoritative = true
+
+	// For IXFR, the secondary provides the serial it currently has.
+	if r.Question[0].Qtype == dns.TypeIXFR && len(r.Ns) > 0 {
+		clientSOA, ok := r.Ns[0].(*dns.SOA)
+		if ok {
+			// The secondary is up to date.
+			if clientSOA.Serial == zone.soa.Serial {
+				m.Answer = []dns.RR{zone.soa}
+				return m
+			}
+
+			// Send the changes since the secondary's serial if we still have them.
+			for i, change := range zone.changes {
+				if change.serial != clientSOA.Serial {
+					continue
+				}
+
+				m.Answer = append(m.Answer, zone.soa)
+				for j, change := range zone.changes[i:] {
+					nextSerial := zone.soa.Serial
+					if i+j+1 < len(zone.changes) {
+						nextSerial = zone.changes[i+j+1].serial
+					}
+
+					m.Answer = append(m.Answer, soaWithSerial(zone.soa, change.serial))
+					m.Answer = append(m.Answer, change.deleted...)
+					m.Answer = append(m.Answer, soaWithSerial(zone.soa, nextSerial))
+					m.Answer = append(m.Answer, change.added...)
+				}
+
+				m.Answer = append(m.Answer, zone.soa)
+				return m
+			}
+		}
 	}
 
-	zoneRR := dns.NewZoneParser(strings.NewReader(zone.Content), "", "")
+	// Otherwise send the full zone.
+	m.Answer = append(m.Answer, zone.soa)
+	m.Answer = append(m.Answer, zone.records...)
+	m.Answer = append(m.Answer, zone.soa)
+
+	return m
+}
+
+// query handles ordinary queries for the records of a zone.
+func (d *dnsHandler) query(r *dns.Msg, name string, ip string, tsigStatus bool) *dns.Msg {
+	// Find the most specific zone containing the name.
+	var zone *zoneState
+	zoneName := name
 	for {
-		rr, ok := zoneRR.Next()
-		if !ok {
-			err := zoneRR.Err()
-			if err != nil {
-				logger.Errorf("Bad DNS record in zone %q: %v", name, err)
-
-				m := new(dns.Msg)
-				m.SetRcode(r, dns.RcodeFormatError)
-				err := w.WriteMsg(m)
-				if err != nil {
-					logger.Error("Unable to write message", logger.Ctx{"err": err})
+		var err error
+
+		zone, _, err = d.server.loadZone(zoneName, zoneCacheAge)
+		if err == nil {
+			break
+		}
+
+		_, parent, found := strings.Cut(zoneName, ".")
+		if !found {
+			// We're not authoritative for this name.
+			m := new(dns.Msg)
+			m.SetRcode(r, dns.RcodeRefused)
+			return m
+		}
+
+		zoneName = parent
+	}
+
+	// Check access.
+	if !d.isAllowed(zone.info, ip, r.IsTsig(), tsigStatus) && !d.isQueryAllowed(zone.info, ip) {
+		m := new(dns.Msg)
+		m.SetRcode(r, dns.RcodeRefused)
+		return m
+	}
+
+	// Prepare the response.
+	m := new(dns.Msg)
+	m.SetReply(r)
+	m.Authoritative = true
+
+	records := append([]dns.RR{zone.soa}, zone.records...)
+	qtype := r.Question[0].Qtype
+	answerName := dns.Fqdn(name)
+
+	// Follow CNAME records within the zone (with a limit to avoid loops).
+	for i := 0; i < 8; i++ {
+		var cname *dns.CNAME
+		answers := []dns.RR{}
+		nameExists := false
+		for _, rr := range records {
+			owner := strings.ToLower(rr.Header().Name)
+			if owner != answerName {
+				// Names that only exist as the parent of other names exist too.
+				if strings.HasSuffix(owner, "."+answerName) {
+					nameExists = true
 				}
 
-				return
+				continue
 			}
 
+			nameExists = true
+			if qtype == dns.TypeANY || rr.Header().Rrtype == qtype {
+				answers = append(answers, rr)
+			} else if rr.Header().Rrtype == dns.TypeCNAME {
+				cname, _ = rr.(*dns.CNAME)
+			}
+		}
+
+		// Only the queried name itself decides whether the name doesn't exist.
+		if i == 0 && !nameExists {
+			m.Rcode = dns.RcodeNameError
 			break
 		}
 
-		m.Answer = append(m.Answer, rr)
+		if len(answers) > 0 || cname == nil {
+			m.Answer = append(m.Answer, answers...)
+			break
+		}
+
+		m.Answer = append(m.Answer, cname)
+		answerName = strings.ToLower(cname.Target)
+		if !dns.IsSubDomain(dns.Fqdn(zone.info.Name), answerName) {
+			break
+		}
 	}
 
-	tsig := r.IsTsig()
-	if tsig != nil && w.TsigStatus() == nil {
-		m.SetTsig(tsig.Hdr.Name, tsig.Algorithm, 300, time.Now().Unix())
+	// Negative answers include the SOA record so that they can be cached.
+	if len(m.Answer) == 0 {
+		m.Ns = []dns.RR{zone.soa}
 	}
 
-	err = w.WriteMsg(m)
-	if err != nil {
-		logger.Error("Unable to write message", logger.Ctx{"err": err})
+	return m
+}
+
+// isQueryAllowed checks whether the address is allowed to make ordinary queries against the zone.
+func (d *dnsHandler) isQueryAllowed(zone api.NetworkZone, ip string) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+
+	for _, subnet := range shared.SplitNTrimSpace(zone.Config["dns.query.allowed_subnets"], ",", -1, true) {
+		_, allowedNet, err := net.ParseCIDR(subnet)
+		if err != nil {
+			continue
+		}
+
+		if allowedNet.Contains(addr) {
+			return true
+		}
 	}
+
+	return false
 }
 
 func (d *dnsHandler) isAllowed(zone api.NetworkZone, ip string, tsig *dns.TSIG, tsigStatus bool) bool {
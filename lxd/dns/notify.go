@@ -0,0 +1,79 @@
+package dns
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// RefreshZone renders the zone again and sends a DNS NOTIFY to its peers if its content changed.
+func (s *Server) RefreshZone(name string) error {
+	// Skip if the DNS server isn't running.
+	s.mu.Lock()
+	running := s.address != ""
+	s.mu.Unlock()
+
+	if !running {
+		return nil
+	}
+
+	zone, changed, err := s.loadZone(name, 0)
+	if err != nil {
+		return err
+	}
+
+	if changed {
+		go s.notifyPeers(zone)
+	}
+
+	return nil
+}
+
+// notifyPeers sends a DNS NOTIFY for the zone to each of its peers which has an address.
+func (s *Server) notifyPeers(zone *zoneState) {
+	for k, address := range zone.info.Config {
+		peerName, found := strings.CutPrefix(k, "peers.")
+		if !found {
+			continue
+		}
+
+		peerName, found = strings.CutSuffix(peerName, ".address")
+		if !found || address == "" {
+			continue
+		}
+
+		m := new(dns.Msg)
+		m.SetNotify(dns.Fqdn(zone.info.Name))
+		m.Answer = []dns.RR{zone.soa}
+
+		client := &dns.Client{Net: "udp", Timeout: 5 * time.Second}
+
+		// Sign the notification with the peer's TSIG key if it has one.
+		key := zone.info.Config[fmt.Sprintf("peers.%s.key", peerName)]
+		if key != "" {
+			keyName := fmt.Sprintf("%s_%s.", zone.info.Name, peerName)
+			client.TsigSecret = map[string]string{keyName: key}
+			m.SetTsig(keyName, dns.HmacSHA256, 300, time.Now().Unix())
+		}
+
+		l := logger.AddContext(logger.Ctx{"zone": zone.info.Name, "peer": peerName, "address": address, "serial": zone.soa.Serial})
+
+		resp, _, err := client.Exchange(m, net.JoinHostPort(address, "53"))
+		if err != nil {
+			l.Warn("Failed sending DNS NOTIFY", logger.Ctx{"err": err})
+			continue
+		}
+
+		if resp.Rcode != dns.RcodeSuccess {
+			l.Debug("DNS NOTIFY not accepted", logger.Ctx{"rcode": dns.RcodeToString[resp.Rcode]})
+			continue
+		}
+
+		l.Debug("Sent DNS NOTIFY")
+	}
+}
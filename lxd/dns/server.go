@@ -3,6 +3,7 @@ package dns
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/miekg/dns"
 
@@ -13,7 +14,7 @@ import (
 )
 
 // ZoneRetriever is a function which fetches a DNS zone.
-type ZoneRetriever func(name string, full bool) (*Zone, error)
+type ZoneRetriever func(name string) (*Zone, error)
 
 // Server represents a DNS server instance.
 type Server struct {
@@ -28,12 +29,19 @@ type Server struct {
 	address string
 
 	mu sync.Mutex
+
+	// Zones served so far along with their recent changes (to handle IXFR).
+	zones   map[string]*zoneState
+	zonesMu sync.Mutex
+
+	// Names recently found not to be zones, along with when they were looked up.
+	missingZones map[string]time.Time
 }
 
 // NewServer returns a new server instance.
 func NewServer(db *db.Cluster, retriever ZoneRetriever) *Server {
 	// Setup new struct.
-	s := &Server{db: db, zoneRetriever: retriever}
+	s := &Server{db: db, zoneRetriever: retriever, zones: map[string]*zoneState{}, missingZones: map[string]time.Time{}}
 	return s
 }
 
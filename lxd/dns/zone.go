@@ -1,11 +1,272 @@
 package dns
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+
+	"github.com/canonical/lxd/lxd/db"
 	"github.com/canonical/lxd/shared/api"
 )
 
+// zoneMaxChanges is the number of changes kept for each zone to answer IXFR requests.
+const zoneMaxChanges = 100
+
+// zoneCacheAge is how long a rendered zone is used to answer ordinary queries before being rendered again.
+const zoneCacheAge = 10 * time.Second
+
+// zoneMissingMax is the number of names not found to be zones that are remembered.
+const zoneMissingMax = 1024
+
 // Zone represents a DNS zone configuration and its content.
 type Zone struct {
 	Info    api.NetworkZone
 	Content string
 }
+
+// zoneChange represents the records deleted and added when a zone moved from one serial to the next.
+type zoneChange struct {
+	serial  uint32
+	deleted []dns.RR
+	added   []dns.RR
+}
+
+// zoneState represents the last rendered version of a zone along with its recent changes.
+type zoneState struct {
+	info    api.NetworkZone
+	soa     *dns.SOA
+	records []dns.RR
+	changes []zoneChange
+	hash    string
+	updated time.Time
+}
+
+// parseZone returns the SOA record and the other records of a zone.
+func parseZone(name string, content string) (*dns.SOA, []dns.RR, error) {
+	var soa *dns.SOA
+	records := []dns.RR{}
+
+	zoneRR := dns.NewZoneParser(strings.NewReader(content), "", "")
+	for rr, ok := zoneRR.Next(); ok; rr, ok = zoneRR.Next() {
+		rrSOA, isSOA := rr.(*dns.SOA)
+		if isSOA {
+			if soa == nil {
+				soa = rrSOA
+			}
+
+			continue
+		}
+
+		records = append(records, rr)
+	}
+
+	err := zoneRR.Err()
+	if err != nil {
+		return nil, nil, fmt.Errorf("Bad DNS record in zone %q: %w", name, err)
+	}
+
+	if soa == nil {
+		return nil, nil, fmt.Errorf("Missing SOA record in zone %q", name)
+	}
+
+	return soa, records, nil
+}
+
+// diffRecords returns the records only found in oldRecords and the records only found in newRecords.
+func diffRecords(oldRecords []dns.RR, newRecords []dns.RR) ([]dns.RR, []dns.RR) {
+	oldSet := make(map[string]struct{}, len(oldRecords))
+	for _, rr := range oldRecords {
+		oldSet[rr.String()] = struct{}{}
+	}
+
+	newSet := make(map[string]struct{}, len(newRecords))
+	for _, rr := range newRecords {
+		newSet[rr.String()] = struct{}{}
+	}
+
+	deleted := []dns.RR{}
+	for _, rr := range oldRecords {
+		_, found := newSet[rr.String()]
+		if !found {
+			deleted = append(deleted, rr)
+		}
+	}
+
+	added := []dns.RR{}
+	for _, rr := range newRecords {
+		_, found := oldSet[rr.String()]
+		if !found {
+			added = append(added, rr)
+		}
+	}
+
+	return deleted, added
+}
+
+// loadZone returns the current version of a zone. The zone is rendered again unless the last rendered version
+// is more recent than maxAge. The returned boolean indicates whether the zone changed since the last version.
+// The zone serial is only increased when its content changes so that secondaries can use IXFR.
+// Zones that don't exist are remembered for maxAge so that queries for unknown names don't hit the database.
+func (s *Server) loadZone(name string, maxAge time.Duration) (*zoneState, bool, error) {
+	s.zonesMu.Lock()
+	defer s.zonesMu.Unlock()
+
+	state := s.zones[name]
+	if state != nil && maxAge > 0 && time.Since(state.updated) < maxAge {
+		stateCopy := *state
+		return &stateCopy, false, nil
+	}
+
+	missingSince, missing := s.missingZones[name]
+	if missing && maxAge > 0 && time.Since(missingSince) < maxAge {
+		return nil, false, api.StatusErrorf(http.StatusNotFound, "Network zone not found")
+	}
+
+	zone, err := s.zoneRetriever(name)
+	if err != nil {
+		delete(s.zones, name)
+
+		if api.StatusErrorCheck(err, http.StatusNotFound) {
+			s.addMissingZone(name)
+		}
+
+		return nil, false, err
+	}
+
+	delete(s.missingZones, name)
+
+	soa, records, err := parseZone(name, zone.Content)
+	if err != nil {
+		return nil, false, err
+	}
+
+	now := time.Now()
+	hash := zoneHash(soa, records)
+
+	soa.Serial, err = s.zoneSerial(name, state, hash, now)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Start a new history for zones we haven't served yet.
+	if state == nil {
+		state = &zoneState{info: zone.Info, soa: soa, records: records, hash: hash, updated: now}
+		s.zones[name] = state
+
+		stateCopy := *state
+		return &stateCopy, true, nil
+	}
+
+	state.info = zone.Info
+	state.updated = now
+
+	if soa.Serial == state.soa.Serial {
+		stateCopy := *state
+		return &stateCopy, false, nil
+	}
+
+	// Record the change.
+	deleted, added := diffRecords(state.records, records)
+	change := zoneChange{serial: state.soa.Serial, deleted: deleted, added: added}
+	changes := append(state.changes, change)
+	if len(changes) > zoneMaxChanges {
+		changes = changes[len(changes)-zoneMaxChanges:]
+	}
+
+	state.soa = soa
+	state.records = records
+	state.hash = hash
+	state.changes = changes
+
+	stateCopy := *state
+	return &stateCopy, true, nil
+}
+
+// addMissingZone remembers that the given name isn't a zone.
+// Expired names are pruned once the limit is reached and the oldest names are evicted if it's still reached.
+// Must be called with zonesMu held.
+func (s *Server) addMissingZone(name string) {
+	if len(s.missingZones) >= zoneMissingMax {
+		for missingName, missingSince := range s.missingZones {
+			if time.Since(missingSince) >= zoneCacheAge {
+				delete(s.missingZones, missingName)
+			}
+		}
+	}
+
+	for len(s.missingZones) >= zoneMissingMax {
+		var oldestName string
+		var oldestSince time.Time
+
+		for missingName, missingSince := range s.missingZones {
+			if oldestName == "" || missingSince.Before(oldestSince) {
+				oldestName = missingName
+				oldestSince = missingSince
+			}
+		}
+
+		delete(s.missingZones, oldestName)
+	}
+
+	s.missingZones[name] = time.Now()
+}
+
+// zoneSerial returns the serial to use for the zone content with the given hash.
+// The serial is stored in the database so that all cluster members use the same serial for the same content.
+// New serials are based on the current time so that they are higher than any serial handed out by older versions.
+func (s *Server) zoneSerial(name string, state *zoneState, hash string, now time.Time) (uint32, error) {
+	if s.db == nil {
+		if state != nil && state.hash == hash {
+			return state.soa.Serial, nil
+		}
+
+		serial := uint32(now.Unix())
+		if state != nil && state.soa.Serial >= serial {
+			serial = state.soa.Serial + 1
+		}
+
+		return serial, nil
+	}
+
+	var serial uint32
+	err := s.db.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		serial, err = tx.GetNetworkZoneSerial(ctx, name, hash, uint32(now.Unix()))
+
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("Failed getting serial of zone %q: %w", name, err)
+	}
+
+	return serial, nil
+}
+
+// zoneHash returns a hash of the content of a zone, ignoring its serial and the order of its records.
+func zoneHash(soa *dns.SOA, records []dns.RR) string {
+	lines := make([]string, 0, len(records)+1)
+	lines = append(lines, soa.Ns+" "+soa.Mbox)
+	for _, rr := range records {
+		lines = append(lines, rr.String())
+	}
+
+	sort.Strings(lines[1:])
+
+	hash := sha256.Sum256([]byte(strings.Join(lines, "\n")))
+	return hex.EncodeToString(hash[:])
+}
+
+// soaWithSerial returns a copy of the SOA record using the given serial.
+func soaWithSerial(soa *dns.SOA, serial uint32) *dns.SOA {
+	rr, _ := dns.Copy(soa).(*dns.SOA)
+	rr.Serial = serial
+	return rr
+}
@@ -80,6 +80,7 @@ type Network interface {
 	Name() string
 	Type() string
 	Config() map[string]string
+	AddressCIDR(ipVersion uint) string
 	DHCPv4Subnet() *net.IPNet
 	DHCPv6Subnet() *net.IPNet
 	DHCPv4Ranges() []shared.IPRange
@@ -179,7 +180,7 @@ func (t *Transaction) AllocateIPv6() (net.IP, error) {
 // It first checks whether there is an existing allocation for the instance.
 // If no previous allocation, then a free IP is picked from the ranges configured.
 func (t *Transaction) getDHCPFreeIPv4(usedIPs map[[4]byte]dnsmasq.DHCPAllocation, deviceStaticFileName string, mac net.HardwareAddr) (net.IP, error) {
-	lxdIP, subnet, err := net.ParseCIDR(t.opts.Network.Config()["ipv4.address"])
+	lxdIP, subnet, err := net.ParseCIDR(t.opts.Network.AddressCIDR(4))
 	if err != nil {
 		return nil, err
 	}
@@ -248,7 +249,7 @@ func (t *Transaction) getDHCPFreeIPv4(usedIPs map[[4]byte]dnsmasq.DHCPAllocation
 // device's MAC address. Finally if stateful custom ranges are enabled, then a free IP is picked
 // from the ranges configured.
 func (t *Transaction) getDHCPFreeIPv6(usedIPs map[[16]byte]dnsmasq.DHCPAllocation, deviceStaticFileName string, mac net.HardwareAddr) (net.IP, error) {
-	lxdIP, subnet, err := net.ParseCIDR(t.opts.Network.Config()["ipv6.address"])
+	lxdIP, subnet, err := net.ParseCIDR(t.opts.Network.AddressCIDR(6))
 	if err != nil {
 		return nil, err
 	}
@@ -32,6 +32,7 @@ type ACLRule struct {
 	Action          string
 	Log             bool   // Whether or not to log matched packets.
 	LogName         string // Log label name (requires Log be true).
+	CounterName     string // Name used to retrieve the counters of matched packets (optional).
 	Source          string
 	Destination     string
 	Protocol        string
@@ -41,6 +42,20 @@ type ACLRule struct {
 	ICMPCode        string
 }
 
+// AddressSet represents a named set of addresses and ports that ACL rules can reference using "$<name>" as their
+// only source, destination, source port or destination port criterion.
+type AddressSet struct {
+	Name      string
+	Addresses []string // IP addresses and subnets.
+	Ports     []string // Ports and port ranges.
+}
+
+// ACLRuleCounter represents the counters of the packets matched by an ACL rule.
+type ACLRuleCounter struct {
+	Packets uint64
+	Bytes   uint64
+}
+
 // AddressForward represents a NAT address forward.
 type AddressForward struct {
 	ListenAddress net.IP
@@ -49,3 +64,26 @@ type AddressForward struct {
 	ListenPorts   []uint64
 	TargetPorts   []uint64
 }
+
+// LoadBalancer represents a NAT address forward that spreads connections over several backends.
+type LoadBalancer struct {
+	ListenAddress net.IP
+	Protocol      string
+	ListenPorts   []uint64
+	Backends      []LoadBalancerBackend
+	Selection     string // Either "round-robin" or "hash" (based on the source address).
+}
+
+// LoadBalancerBackend represents a backend of a load balancer.
+// If TargetPorts is empty then the listen ports are used.
+type LoadBalancerBackend struct {
+	TargetAddress net.IP
+	TargetPorts   []uint64
+}
+
+// NetworkPeer represents a routed connection from the subnets of a network to those of a peer network.
+type NetworkPeer struct {
+	Interface     string       // Interface name of the peer network.
+	LocalSubnets  []*net.IPNet // Subnets of the local network.
+	TargetSubnets []*net.IPNet // Subnets of the peer network.
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net"
 	"os/exec"
+	"regexp"
 	"strconv"
 	"strings"
 	"text/template"
@@ -30,6 +31,19 @@ const nftablesChainSeparator = "."
 // nftablesMinVersion We need at least 0.9.1 as this was when the arp ether saddr filters were added.
 const nftablesMinVersion = "0.9.1"
 
+// nftablesNetworkChains are the chains (suffixed with the network name) holding the rules of a network.
+var nftablesNetworkChains = []string{
+	"fwd", "pstrt", "in", "out", // Chains used for network operation rules.
+	"aclin", "aclout", "aclfwd", "acl", // Chains used by ACL rules.
+	"peernat",                        // Chain used by network peer rules (jumped to from "pstrt").
+	"fwdprert", "fwdout", "fwdpstrt", // Chains used by Address Forward rules.
+	"lbprert", "lbout", "lbpstrt", // Chains used by Load Balancer rules.
+	"egress", // Chains added for limits.priority option
+}
+
+// nftablesCounterRegex matches the anonymous counters of a rule expression so that their values can be ignored.
+var nftablesCounterRegex = regexp.MustCompile(`"counter":\s*\{[^}]*\}`)
+
 // Nftables is an implmentation of LXD firewall using nftables.
 type Nftables struct{}
 
@@ -126,11 +140,13 @@ func (d Nftables) Compat() (bool, error) {
 
 // nftGenericItem represents some common fields amongst the different nftables types.
 type nftGenericItem struct {
-	ItemType string `json:"-"`      // Type of item (table, chain or rule). Populated by LXD.
+	ItemType string `json:"-"`      // Type of item (table, chain, set or rule). Populated by LXD.
 	Family   string `json:"family"` // Family of item (ip, ip6, bridge etc).
-	Table    string `json:"table"`  // Table the item belongs to (for chains and rules).
+	Table    string `json:"table"`  // Table the item belongs to (for chains, sets and rules).
 	Chain    string `json:"chain"`  // Chain the item belongs to (for rules).
-	Name     string `json:"name"`   // Name of item (for tables and chains).
+	Name     string `json:"name"`   // Name of item (for tables, chains and sets).
+
+	Expr json.RawMessage `json:"expr"` // Expression of item (for rules).
 }
 
 // nftParseRuleset parses the ruleset and returns the generic parts as a slice of items.
@@ -163,10 +179,14 @@ func (d Nftables) nftParseRuleset() ([]nftGenericItem, error) {
 	for _, item := range v.Nftables {
 		rule, foundRule := item["rule"]
 		chain, foundChain := item["chain"]
+		set, foundSet := item["set"]
 		table, foundTable := item["table"]
 		if foundRule {
 			rule.ItemType = "rule"
 			items = append(items, rule)
+		} else if foundSet {
+			set.ItemType = "set"
+			items = append(items, set)
 		} else if foundChain {
 			chain.ItemType = "chain"
 			items = append(items, chain)
@@ -359,23 +379,32 @@ func (d Nftables) NetworkSetup(networkName string, ip4Address net.IP, ip6Address
 // NetworkClear removes the LXD network related chains.
 // The delete and ipeVersions arguments have no effect for nftables driver.
 func (d Nftables) NetworkClear(networkName string, _ bool, _ []uint) error {
-	removeChains := []string{
-		"fwd", "pstrt", "in", "out", // Chains used for network operation rules.
-		"aclin", "aclout", "aclfwd", "acl", // Chains used by ACL rules.
-		"fwdprert", "fwdout", "fwdpstrt", // Chains used by Address Forward rules.
-		"egress", // Chains added for limits.priority option
-	}
-
 	// Remove chains created by network rules.
 	// Remove from ip and ip6 tables to ensure cleanup for instances started before we moved to inet table
-	err := d.removeChains([]string{"inet", "ip", "ip6", "netdev"}, networkName, removeChains...)
+	err := d.removeChains([]string{"inet", "ip", "ip6", "netdev"}, networkName, nftablesNetworkChains...)
 	if err != nil {
 		return fmt.Errorf("Failed clearing nftables rules for network %q: %w", networkName, err)
 	}
 
+	// Remove the sets used by ACL rules once the chains referencing them are gone.
+	err = d.removeACLAddressSets(networkName)
+	if err != nil {
+		return fmt.Errorf("Failed clearing nftables sets for network %q: %w", networkName, err)
+	}
+
 	return nil
 }
 
+// NetworkRules returns the live rules of the LXD network related chains.
+func (d Nftables) NetworkRules(networkName string) ([]string, error) {
+	rules, err := d.chainRules(networkName, nftablesNetworkChains...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed listing nftables rules for network %q: %w", networkName, err)
+	}
+
+	return rules, nil
+}
+
 // instanceDeviceLabel returns the unique label used for instance device chains.
 func (d Nftables) instanceDeviceLabel(projectName, instanceName, deviceName string) string {
 	return fmt.Sprintf("%s%s%s", project.Instance(projectName, instanceName), nftablesChainSeparator, deviceName)
@@ -627,6 +656,36 @@ func (d Nftables) removeChains(families []string, chainSuffix string, chains ...
 	return nil
 }
 
+// chainRules returns the rules of the specified chains of any family in a form suitable for comparison.
+// If not empty, chain suffix is appended to each chain name, separated with ".".
+func (d Nftables) chainRules(chainSuffix string, chains ...string) ([]string, error) {
+	ruleset, err := d.nftParseRuleset()
+	if err != nil {
+		return nil, err
+	}
+
+	fullChains := chains
+	if chainSuffix != "" {
+		fullChains = make([]string, 0, len(chains))
+		for _, chain := range chains {
+			fullChains = append(fullChains, fmt.Sprintf("%s%s%s", chain, nftablesChainSeparator, chainSuffix))
+		}
+	}
+
+	rules := []string{}
+	for _, item := range ruleset {
+		if item.ItemType != "rule" || item.Table != nftablesNamespace || !shared.ValueInSlice(item.Chain, fullChains) {
+			continue
+		}
+
+		// Ignore the counter values as they change with the traffic.
+		expr := nftablesCounterRegex.ReplaceAll(item.Expr, []byte(`"counter":null`))
+		rules = append(rules, fmt.Sprintf("%s %s %s %s", item.Family, item.Table, item.Chain, expr))
+	}
+
+	return rules, nil
+}
+
 // InstanceSetupRPFilter activates reverse path filtering for the specified instance device on the host interface.
 func (d Nftables) InstanceSetupRPFilter(projectName string, instanceName string, deviceName string, hostName string) error {
 	deviceLabel := d.instanceDeviceLabel(projectName, instanceName, deviceName)
@@ -659,16 +718,19 @@ func (d Nftables) InstanceClearRPFilter(projectName string, instanceName string,
 	return nil
 }
 
-// InstanceSetupNetPrio activates setting of skb->priority for the specified instance device on the host interface.
-func (d Nftables) InstanceSetupNetPrio(projectName string, instanceName string, deviceName string, netPrio uint32) error {
+// InstanceSetupNetPrio activates setting of skb->priority and of the DSCP field of the outgoing packets for the
+// specified instance device on the host interface. A netPrio of 0 leaves skb->priority unchanged and a negative dscp
+// leaves the DSCP field unchanged.
+func (d Nftables) InstanceSetupNetPrio(projectName string, instanceName string, deviceName string, hostName string, netPrio uint32, dscp int) error {
 	deviceLabel := d.instanceDeviceLabel(projectName, instanceName, deviceName)
 	tplFields := map[string]any{
 		"namespace":      nftablesNamespace,
 		"family":         "netdev",
 		"chainSeparator": nftablesChainSeparator,
 		"deviceLabel":    deviceLabel,
-		"deviceName":     deviceName,
+		"hostName":       hostName,
 		"netPrio":        netPrio,
+		"dscp":           dscp,
 	}
 
 	err := d.applyNftConfig(nftablesInstanceNetPrio, tplFields)
@@ -679,29 +741,62 @@ func (d Nftables) InstanceSetupNetPrio(projectName string, instanceName string,
 	return nil
 }
 
-// InstanceClearNetPrio removes setting of skb->priority for the specified instance device on the host interface.
-func (d Nftables) InstanceClearNetPrio(projectName string, instanceName string, deviceName string) error {
+// InstanceClearNetPrio removes setting of skb->priority and DSCP for the specified instance device on the host interface.
+func (d Nftables) InstanceClearNetPrio(projectName string, instanceName string, deviceName string, hostName string) error {
 	if deviceName == "" {
 		return fmt.Errorf("Failed clearing netprio rules for instance %q in project %q: device name is empty", instanceName, projectName)
 	}
 
+	// Also remove the chains labelled with the host interface name, as used by older versions.
+	labels := []string{d.instanceDeviceLabel(projectName, instanceName, deviceName)}
+	if hostName != "" && hostName != deviceName {
+		labels = append(labels, d.instanceDeviceLabel(projectName, instanceName, hostName))
+	}
+
+	for _, deviceLabel := range labels {
+		chainLabel := fmt.Sprintf("netprio%s%s", nftablesChainSeparator, deviceLabel)
+
+		err := d.removeChains([]string{"netdev"}, chainLabel, "egress", "ingress")
+		if err != nil {
+			return fmt.Errorf("Failed clearing netprio rules for instance device %q: %w", deviceLabel, err)
+		}
+	}
+
+	return nil
+}
+
+// InstanceRules returns the live rules of the chains of the specified instance device.
+func (d Nftables) InstanceRules(projectName string, instanceName string, deviceName string) ([]string, error) {
 	deviceLabel := d.instanceDeviceLabel(projectName, instanceName, deviceName)
-	chainLabel := fmt.Sprintf("netprio%s%s", nftablesChainSeparator, deviceLabel)
 
-	err := d.removeChains([]string{"netdev"}, chainLabel, "egress")
+	// Chains used by bridge filter, proxy NAT and reverse path filter rules.
+	rules, err := d.chainRules(deviceLabel, "in", "fwd", "out", "prert", "pstrt")
 	if err != nil {
-		return fmt.Errorf("Failed clearing netprio rules for instance device %q: %w", deviceLabel, err)
+		return nil, fmt.Errorf("Failed listing nftables rules for instance device %q: %w", deviceLabel, err)
 	}
 
-	return nil
+	// Chains used by netprio rules.
+	netPrioRules, err := d.chainRules(fmt.Sprintf("netprio%s%s", nftablesChainSeparator, deviceLabel), "egress", "ingress")
+	if err != nil {
+		return nil, fmt.Errorf("Failed listing nftables rules for instance device %q: %w", deviceLabel, err)
+	}
+
+	return append(rules, netPrioRules...), nil
 }
 
 // NetworkApplyACLRules applies ACL rules to the existing firewall chains.
-func (d Nftables) NetworkApplyACLRules(networkName string, rules []ACLRule) error {
+// The referenced address sets are created as nftables sets so that the rules don't need to be regenerated when
+// their members change.
+func (d Nftables) NetworkApplyACLRules(networkName string, rules []ACLRule, addressSets []AddressSet) error {
+	nftSets, err := d.aclNftSets(networkName, addressSets)
+	if err != nil {
+		return err
+	}
+
 	nftRules := make([]string, 0)
 	for _, rule := range rules {
 		// First try generating rules with IPv4 or IP agnostic criteria.
-		nftRule, partial, err := d.aclRuleCriteriaToRules(networkName, 4, &rule)
+		nftRule, partial, err := d.aclRuleCriteriaToRules(networkName, 4, &rule, addressSets)
 		if err != nil {
 			return err
 		}
@@ -713,16 +808,17 @@ func (d Nftables) NetworkApplyACLRules(networkName string, rules []ACLRule) erro
 		if partial {
 			// If we couldn't fully generate the ruleset with only IPv4 or IP agnostic criteria, then
 			// fill in the remaining parts using IPv6 criteria.
-			nftRule, _, err = d.aclRuleCriteriaToRules(networkName, 6, &rule)
+			nftRule, _, err = d.aclRuleCriteriaToRules(networkName, 6, &rule, addressSets)
 			if err != nil {
 				return err
 			}
 
-			if nftRule == "" {
+			if nftRule != "" {
+				nftRules = append(nftRules, nftRule)
+			} else if !aclRuleHasAddressSet(&rule) {
+				// Only rules using address sets with an ICMP protocol don't apply to both IP versions.
 				return fmt.Errorf("Invalid empty rule generated")
 			}
-
-			nftRules = append(nftRules, nftRule)
 		} else if nftRule == "" {
 			return fmt.Errorf("Invalid empty rule generated")
 		}
@@ -734,10 +830,11 @@ func (d Nftables) NetworkApplyACLRules(networkName string, rules []ACLRule) erro
 		"networkName":    networkName,
 		"family":         "inet",
 		"rules":          nftRules,
+		"sets":           nftSets,
 	}
 
 	config := &strings.Builder{}
-	err := nftablesNetACLRules.Execute(config, tplFields)
+	err = nftablesNetACLRules.Execute(config, tplFields)
 	if err != nil {
 		return fmt.Errorf("Failed running %q template: %w", nftablesNetACLRules.Name(), err)
 	}
@@ -747,11 +844,148 @@ func (d Nftables) NetworkApplyACLRules(networkName string, rules []ACLRule) erro
 		return err
 	}
 
+	// Remove the sets of the address sets that are no longer referenced.
+	keepSets := make([]string, 0, len(nftSets))
+	for _, nftSet := range nftSets {
+		keepSets = append(keepSets, nftSet["name"].(string))
+	}
+
+	err = d.removeACLAddressSets(networkName, keepSets...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// aclNftSets returns the nftables sets holding the IPv4 addresses, IPv6 addresses and ports of the address sets
+// used by the ACL rules of a network.
+func (d Nftables) aclNftSets(networkName string, addressSets []AddressSet) ([]map[string]any, error) {
+	nftSets := make([]map[string]any, 0, len(addressSets)*3)
+	for _, addressSet := range addressSets {
+		elements := map[string][]string{}
+		for _, address := range addressSet.Addresses {
+			ip := net.ParseIP(address)
+			if ip == nil {
+				ip, _, _ = net.ParseCIDR(address)
+			}
+
+			if ip == nil {
+				return nil, fmt.Errorf("Invalid address %q in address set %q", address, addressSet.Name)
+			}
+
+			if ip.To4() == nil {
+				elements["ipv6"] = append(elements["ipv6"], address)
+			} else {
+				elements["ipv4"] = append(elements["ipv4"], address)
+			}
+		}
+
+		elements["ports"] = addressSet.Ports
+
+		for _, setType := range []string{"ipv4", "ipv6", "ports"} {
+			nftType := setType + "_addr"
+			if setType == "ports" {
+				nftType = "inet_service"
+			}
+
+			nftSets = append(nftSets, map[string]any{
+				"name":     d.aclAddressSetName(networkName, addressSet.Name, setType),
+				"type":     nftType,
+				"elements": strings.Join(elements[setType], ", "),
+			})
+		}
+	}
+
+	return nftSets, nil
+}
+
+// NetworkUpdateAddressSets replaces the members of the address sets used by the ACL rules of a network without
+// changing the rules, so that their counters are kept. The address sets that the network doesn't use are skipped.
+func (d Nftables) NetworkUpdateAddressSets(networkName string, addressSets []AddressSet) error {
+	nftSets, err := d.aclNftSets(networkName, addressSets)
+	if err != nil {
+		return err
+	}
+
+	ruleset, err := d.nftParseRuleset()
+	if err != nil {
+		return err
+	}
+
+	existingSets := []string{}
+	for _, item := range ruleset {
+		if item.ItemType == "set" && item.Family == "inet" && item.Table == nftablesNamespace {
+			existingSets = append(existingSets, item.Name)
+		}
+	}
+
+	updateSets := make([]map[string]any, 0, len(nftSets))
+	for _, nftSet := range nftSets {
+		if shared.ValueInSlice(nftSet["name"].(string), existingSets) {
+			updateSets = append(updateSets, nftSet)
+		}
+	}
+
+	if len(updateSets) == 0 {
+		return nil
+	}
+
+	tplFields := map[string]any{
+		"namespace": nftablesNamespace,
+		"family":    "inet",
+		"sets":      updateSets,
+	}
+
+	config := &strings.Builder{}
+	err = nftablesNetACLSets.Execute(config, tplFields)
+	if err != nil {
+		return fmt.Errorf("Failed running %q template: %w", nftablesNetACLSets.Name(), err)
+	}
+
+	err = shared.RunCommandWithFds(context.TODO(), strings.NewReader(config.String()), nil, "nft", "-f", "-")
+	if err != nil {
+		return fmt.Errorf("Failed updating nftables sets for network %q: %w", networkName, err)
+	}
+
+	return nil
+}
+
+// aclAddressSetName returns the name of the nftables set holding the members of the given type ("ipv4", "ipv6"
+// or "ports") of an address set used by the ACL rules of a network.
+func (d Nftables) aclAddressSetName(networkName string, addressSetName string, setType string) string {
+	return strings.Join([]string{"aclset", networkName, addressSetName, setType}, nftablesChainSeparator)
+}
+
+// removeACLAddressSets removes the nftables sets of the address sets used by the ACL rules of a network, apart
+// from the ones to keep.
+func (d Nftables) removeACLAddressSets(networkName string, keepSets ...string) error {
+	ruleset, err := d.nftParseRuleset()
+	if err != nil {
+		return err
+	}
+
+	prefix := strings.Join([]string{"aclset", networkName, ""}, nftablesChainSeparator)
+	for _, item := range ruleset {
+		if item.ItemType != "set" || item.Family != "inet" || item.Table != nftablesNamespace {
+			continue
+		}
+
+		if !strings.HasPrefix(item.Name, prefix) || shared.ValueInSlice(item.Name, keepSets) {
+			continue
+		}
+
+		_, err = shared.RunCommand("nft", "delete", "set", item.Family, nftablesNamespace, item.Name)
+		if err != nil {
+			return fmt.Errorf("Failed deleting nftables set %q (%s): %w", item.Name, item.Family, err)
+		}
+	}
+
 	return nil
 }
 
 // aclRuleCriteriaToRules converts an ACL rule into 1 or more nftables rules.
-func (d Nftables) aclRuleCriteriaToRules(networkName string, ipVersion uint, rule *ACLRule) (string, bool, error) {
+func (d Nftables) aclRuleCriteriaToRules(networkName string, ipVersion uint, rule *ACLRule, addressSets []AddressSet) (string, bool, error) {
 	var args []string
 
 	if rule.Direction == "ingress" {
@@ -764,7 +998,7 @@ func (d Nftables) aclRuleCriteriaToRules(networkName string, ipVersion uint, rul
 	isPartialRule := false
 
 	if rule.Source != "" {
-		matchArgs, partial, err := d.aclRuleSubjectToACLMatch("saddr", ipVersion, shared.SplitNTrimSpace(rule.Source, ",", -1, false)...)
+		matchArgs, partial, err := d.aclRuleSubjectToACLMatch(networkName, "saddr", ipVersion, addressSets, shared.SplitNTrimSpace(rule.Source, ",", -1, false)...)
 		if err != nil {
 			return "", false, err
 		}
@@ -781,7 +1015,7 @@ func (d Nftables) aclRuleCriteriaToRules(networkName string, ipVersion uint, rul
 	}
 
 	if rule.Destination != "" {
-		matchArgs, partial, err := d.aclRuleSubjectToACLMatch("daddr", ipVersion, shared.SplitNTrimSpace(rule.Destination, ",", -1, false)...)
+		matchArgs, partial, err := d.aclRuleSubjectToACLMatch(networkName, "daddr", ipVersion, addressSets, shared.SplitNTrimSpace(rule.Destination, ",", -1, false)...)
 		if err != nil {
 			return "", false, err
 		}
@@ -802,11 +1036,21 @@ func (d Nftables) aclRuleCriteriaToRules(networkName string, ipVersion uint, rul
 		args = append(args, "meta", "l4proto", rule.Protocol)
 
 		if rule.SourcePort != "" {
-			args = append(args, d.aclRulePortToACLMatch("sport", shared.SplitNTrimSpace(rule.SourcePort, ",", -1, false)...)...)
+			matchArgs, err := d.aclRulePortToACLMatch(networkName, "sport", addressSets, shared.SplitNTrimSpace(rule.SourcePort, ",", -1, false)...)
+			if err != nil {
+				return "", false, err
+			}
+
+			args = append(args, matchArgs...)
 		}
 
 		if rule.DestinationPort != "" {
-			args = append(args, d.aclRulePortToACLMatch("dport", shared.SplitNTrimSpace(rule.DestinationPort, ",", -1, false)...)...)
+			matchArgs, err := d.aclRulePortToACLMatch(networkName, "dport", addressSets, shared.SplitNTrimSpace(rule.DestinationPort, ",", -1, false)...)
+			if err != nil {
+				return "", false, err
+			}
+
+			args = append(args, matchArgs...)
 		}
 	} else if shared.ValueInSlice(rule.Protocol, []string{"icmp4", "icmp6"}) {
 		var icmpIPVersion uint
@@ -824,6 +1068,12 @@ func (d Nftables) aclRuleCriteriaToRules(networkName string, ipVersion uint, rul
 		}
 
 		if ipVersion != icmpIPVersion {
+			// Address sets hold addresses of both IP versions, only the part of the rule matching the
+			// IP version of the ICMP protocol applies.
+			if aclRuleHasAddressSet(rule) {
+				return "", ipVersion < icmpIPVersion, nil // Rule is not appropriate for ipVersion.
+			}
+
 			// If we got this far it means that source/destination are either empty or are filled
 			// with at least some subjects in the same family as ipVersion. So if the icmpIPVersion
 			// doesn't match the ipVersion then it means the rule contains mixed-version subjects
@@ -846,6 +1096,11 @@ func (d Nftables) aclRuleCriteriaToRules(networkName string, ipVersion uint, rul
 		}
 	}
 
+	// Handle counters.
+	if rule.CounterName != "" {
+		args = append(args, "counter")
+	}
+
 	// Handle logging.
 	if rule.Log {
 		args = append(args, "log")
@@ -864,12 +1119,89 @@ func (d Nftables) aclRuleCriteriaToRules(networkName string, ipVersion uint, rul
 
 	args = append(args, action)
 
+	// Add the counter name as a comment so the counters can be matched back to the rule.
+	if rule.CounterName != "" {
+		args = append(args, "comment", fmt.Sprintf(`"%s"`, rule.CounterName))
+	}
+
 	return strings.Join(args, " "), isPartialRule, nil
 }
 
+// NetworkACLRuleCounters returns the counters of the ACL rules of a network keyed by counter name.
+// Rules that were split into several nftables rules have their counters summed.
+func (d Nftables) NetworkACLRuleCounters(networkName string) (map[string]ACLRuleCounter, error) {
+	chain := fmt.Sprintf("acl%s%s", nftablesChainSeparator, networkName)
+
+	output, err := shared.RunCommandCLocale("nft", "--json", "-nn", "list", "chain", "inet", nftablesNamespace, chain)
+	if err != nil {
+		return nil, fmt.Errorf("Failed listing nftables chain %q: %w", chain, err)
+	}
+
+	// This only extracts the comment and counter parts of the rules, see man libnftables-json for more info.
+	v := &struct {
+		Nftables []struct {
+			Rule *struct {
+				Comment string `json:"comment"`
+				Expr    []struct {
+					Counter *struct {
+						Packets uint64 `json:"packets"`
+						Bytes   uint64 `json:"bytes"`
+					} `json:"counter"`
+				} `json:"expr"`
+			} `json:"rule"`
+		} `json:"nftables"`
+	}{}
+
+	err = json.Unmarshal([]byte(output), v)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing nftables chain %q: %w", chain, err)
+	}
+
+	counters := make(map[string]ACLRuleCounter)
+	for _, item := range v.Nftables {
+		if item.Rule == nil || item.Rule.Comment == "" {
+			continue
+		}
+
+		for _, expr := range item.Rule.Expr {
+			if expr.Counter == nil {
+				continue
+			}
+
+			counter := counters[item.Rule.Comment]
+			counter.Packets += expr.Counter.Packets
+			counter.Bytes += expr.Counter.Bytes
+			counters[item.Rule.Comment] = counter
+		}
+	}
+
+	return counters, nil
+}
+
 // aclRuleSubjectToACLMatch converts direction (source/destination) and subject criteria list into xtables args.
 // Returns nil if none of the subjects are appropriate for the ipVersion.
-func (d Nftables) aclRuleSubjectToACLMatch(direction string, ipVersion uint, subjectCriteria ...string) ([]string, bool, error) {
+func (d Nftables) aclRuleSubjectToACLMatch(networkName string, direction string, ipVersion uint, addressSets []AddressSet, subjectCriteria ...string) ([]string, bool, error) {
+	ipFamily := "ip"
+	if ipVersion == 6 {
+		ipFamily = "ip6"
+	}
+
+	// An address set is the only subject of the criteria. Its IPv4 and IPv6 members are held in separate sets
+	// that are always both referenced, so that changing its members never changes the rules.
+	if len(subjectCriteria) == 1 {
+		addressSetName, isSet := aclAddressSetName(subjectCriteria[0])
+		if isSet {
+			_, err := aclAddressSetByName(addressSets, addressSetName)
+			if err != nil {
+				return nil, false, err
+			}
+
+			setName := d.aclAddressSetName(networkName, addressSetName, fmt.Sprintf("ipv%d", ipVersion))
+
+			return []string{ipFamily, direction, "@" + setName}, ipVersion == 4, nil
+		}
+	}
+
 	fieldParts := make([]string, 0, len(subjectCriteria))
 
 	partial := false
@@ -922,11 +1254,6 @@ func (d Nftables) aclRuleSubjectToACLMatch(direction string, ipVersion uint, sub
 	}
 
 	if len(fieldParts) > 0 {
-		ipFamily := "ip"
-		if ipVersion == 6 {
-			ipFamily = "ip6"
-		}
-
 		return []string{ipFamily, direction, fmt.Sprintf("{%s}", strings.Join(fieldParts, ","))}, partial, nil
 	}
 
@@ -935,7 +1262,20 @@ func (d Nftables) aclRuleSubjectToACLMatch(direction string, ipVersion uint, sub
 
 // aclRulePortToACLMatch converts protocol (tcp/udp), direction (sports/dports) and port criteria list into
 // xtables args.
-func (d Nftables) aclRulePortToACLMatch(direction string, portCriteria ...string) []string {
+func (d Nftables) aclRulePortToACLMatch(networkName string, direction string, addressSets []AddressSet, portCriteria ...string) ([]string, error) {
+	// An address set is the only port criterion.
+	if len(portCriteria) == 1 {
+		addressSetName, isSet := aclAddressSetName(portCriteria[0])
+		if isSet {
+			_, err := aclAddressSetByName(addressSets, addressSetName)
+			if err != nil {
+				return nil, err
+			}
+
+			return []string{"th", direction, "@" + d.aclAddressSetName(networkName, addressSetName, "ports")}, nil
+		}
+	}
+
 	fieldParts := make([]string, 0, len(portCriteria))
 
 	for _, portCriterion := range portCriteria {
@@ -947,7 +1287,7 @@ func (d Nftables) aclRulePortToACLMatch(direction string, portCriteria ...string
 		}
 	}
 
-	return []string{"th", direction, fmt.Sprintf("{%s}", strings.Join(fieldParts, ","))}
+	return []string{"th", direction, fmt.Sprintf("{%s}", strings.Join(fieldParts, ","))}, nil
 }
 
 // NetworkApplyForwards apply network address forward rules to firewall.
@@ -1083,3 +1423,143 @@ func (d Nftables) NetworkApplyForwards(networkName string, rules []AddressForwar
 
 	return nil
 }
+
+// NetworkApplyLoadBalancers apply network load balancer rules to firewall.
+func (d Nftables) NetworkApplyLoadBalancers(networkName string, loadBalancers []LoadBalancer) error {
+	var dnatRules []map[string]any
+	var snatRules []map[string]any
+
+	for lbIndex, lb := range loadBalancers {
+		err := validateLoadBalancer(&lb)
+		if err != nil {
+			return fmt.Errorf("Invalid load balancer %d: %w", lbIndex, err)
+		}
+
+		ipFamily := "ip"
+		if lb.ListenAddress.To4() == nil {
+			ipFamily = "ip6"
+		}
+
+		// Pick the backend either in turn or from a hash of the source address, so that a client
+		// always ends up on the same backend.
+		selector := fmt.Sprintf("numgen inc mod %d", len(lb.Backends))
+		if lb.Selection == "hash" {
+			selector = fmt.Sprintf("jhash %s saddr mod %d", ipFamily, len(lb.Backends))
+		}
+
+		for listenPortIndex, listenPort := range lb.ListenPorts {
+			targets := make([]string, 0, len(lb.Backends))
+			for backendIndex, backend := range lb.Backends {
+				targetPort := loadBalancerTargetPort(&backend, lb.ListenPorts, listenPortIndex)
+				targets = append(targets, fmt.Sprintf("%d : %s . %d", backendIndex, backend.TargetAddress.String(), targetPort))
+			}
+
+			dnatRules = append(dnatRules, map[string]any{
+				"ipFamily":      ipFamily,
+				"protocol":      lb.Protocol,
+				"listenAddress": lb.ListenAddress.String(),
+				"listenPort":    listenPort,
+				"selector":      selector,
+				"targets":       strings.Join(targets, ", "),
+			})
+		}
+
+		for _, backend := range lb.Backends {
+			targetPorts := backend.TargetPorts
+			if len(targetPorts) == 0 {
+				targetPorts = lb.ListenPorts
+			}
+
+			for _, targetPortRange := range portRangesFromSlice(targetPorts) {
+				snatRules = append(snatRules, map[string]any{
+					"ipFamily":    ipFamily,
+					"protocol":    lb.Protocol,
+					"targetHost":  backend.TargetAddress.String(),
+					"targetPorts": portRangeStr(targetPortRange, "-"),
+				})
+			}
+		}
+	}
+
+	// Remove chains if no rules generated.
+	if len(dnatRules) == 0 {
+		err := d.removeChains([]string{"inet"}, networkName, "lbprert", "lbout", "lbpstrt")
+		if err != nil {
+			return fmt.Errorf("Failed clearing nftables load balancer rules for network %q: %w", networkName, err)
+		}
+
+		return nil
+	}
+
+	tplFields := map[string]any{
+		"namespace":      nftablesNamespace,
+		"chainSeparator": nftablesChainSeparator,
+		"family":         "inet",
+		"networkName":    networkName,
+		"dnatRules":      dnatRules,
+		"snatRules":      snatRules,
+	}
+
+	config := &strings.Builder{}
+	err := nftablesNetLoadBalancerNAT.Execute(config, tplFields)
+	if err != nil {
+		return fmt.Errorf("Failed running %q template: %w", nftablesNetLoadBalancerNAT.Name(), err)
+	}
+
+	err = shared.RunCommandWithFds(context.TODO(), strings.NewReader(config.String()), nil, "nft", "-f", "-")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// NetworkApplyPeers apply the rules exempting traffic to peer networks from outbound NAT.
+func (d Nftables) NetworkApplyPeers(networkName string, peers []NetworkPeer) error {
+	var rules []map[string]any
+
+	for _, peer := range peers {
+		for _, localSubnet := range peer.LocalSubnets {
+			for _, targetSubnet := range peer.TargetSubnets {
+				localIsIP4 := localSubnet.IP.To4() != nil
+				if localIsIP4 != (targetSubnet.IP.To4() != nil) {
+					continue
+				}
+
+				ipFamily := "ip"
+				if !localIsIP4 {
+					ipFamily = "ip6"
+				}
+
+				rules = append(rules, map[string]any{
+					"interface":    peer.Interface,
+					"ipFamily":     ipFamily,
+					"localSubnet":  localSubnet.String(),
+					"targetSubnet": targetSubnet.String(),
+				})
+			}
+		}
+	}
+
+	tplFields := map[string]any{
+		"namespace":      nftablesNamespace,
+		"chainSeparator": nftablesChainSeparator,
+		"family":         "inet",
+		"networkName":    networkName,
+		"rules":          rules,
+	}
+
+	// The chain is always kept (even when empty) as the outbound NAT chain jumps to it.
+	config := &strings.Builder{}
+	err := nftablesNetPeerNAT.Execute(config, tplFields)
+	if err != nil {
+		return fmt.Errorf("Failed running %q template: %w", nftablesNetPeerNAT.Name(), err)
+	}
+
+	err = shared.RunCommandWithFds(context.TODO(), strings.NewReader(config.String()), nil, "nft", "-f", "-")
+	if err != nil {
+		return fmt.Errorf("Failed applying nftables peer rules for network %q: %w", networkName, err)
+	}
+
+	return nil
+}
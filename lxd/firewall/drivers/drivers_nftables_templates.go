@@ -27,8 +27,12 @@ chain fwd{{.chainSeparator}}{{.networkName}} {
 `))
 
 var nftablesNetOutboundNAT = template.Must(template.New("nftablesNetOutboundNAT").Parse(`
+chain peernat{{.chainSeparator}}{{.networkName}} {
+}
+
 chain pstrt{{.chainSeparator}}{{.networkName}} {
 	type nat hook postrouting priority 100; policy accept;
+	jump peernat{{.chainSeparator}}{{.networkName}}
 
 	{{- range $ipFamily, $config := .rules}}
 	{{if $config.SNATAddress -}}
@@ -117,6 +121,56 @@ table {{.family}} {{.namespace}} {
 }
 `))
 
+// nftablesNetLoadBalancerNAT defines the rules spreading new connections to a load balancer over its backends.
+var nftablesNetLoadBalancerNAT = template.Must(template.New("nftablesNetLoadBalancerNAT").Parse(`
+add table {{.family}} {{.namespace}}
+add chain {{.family}} {{.namespace}} lbprert{{.chainSeparator}}{{.networkName}} {type nat hook prerouting priority -100; policy accept;}
+add chain {{.family}} {{.namespace}} lbout{{.chainSeparator}}{{.networkName}} {type nat hook output priority -100; policy accept;}
+add chain {{.family}} {{.namespace}} lbpstrt{{.chainSeparator}}{{.networkName}} {type nat hook postrouting priority 100; policy accept;}
+flush chain {{.family}} {{.namespace}} lbprert{{.chainSeparator}}{{.networkName}}
+flush chain {{.family}} {{.namespace}} lbout{{.chainSeparator}}{{.networkName}}
+flush chain {{.family}} {{.namespace}} lbpstrt{{.chainSeparator}}{{.networkName}}
+
+table {{.family}} {{.namespace}} {
+	chain lbprert{{.chainSeparator}}{{.networkName}} {
+		type nat hook prerouting priority -100; policy accept;
+		{{- range .dnatRules}}
+		{{.ipFamily}} daddr {{.listenAddress}} {{.protocol}} dport {{.listenPort}} dnat {{.ipFamily}} addr . port to {{.selector}} map { {{.targets}} }
+		{{- end}}
+	}
+
+	chain lbout{{.chainSeparator}}{{.networkName}} {
+		type nat hook output priority -100; policy accept;
+		{{- range .dnatRules}}
+		{{.ipFamily}} daddr {{.listenAddress}} {{.protocol}} dport {{.listenPort}} dnat {{.ipFamily}} addr . port to {{.selector}} map { {{.targets}} }
+		{{- end}}
+	}
+
+	chain lbpstrt{{.chainSeparator}}{{.networkName}} {
+		type nat hook postrouting priority 100; policy accept;
+		{{- range .snatRules}}
+		{{.ipFamily}} saddr {{.targetHost}} {{.ipFamily}} daddr {{.targetHost}} {{.protocol}} dport {{.targetPorts}} masquerade
+		{{- end}}
+	}
+}
+`))
+
+// nftablesNetPeerNAT defines the rules exempting traffic to peer networks from the outbound NAT of the network.
+// The chain is jumped to from the network's outbound NAT chain, so accepting a packet here skips the SNAT rules.
+var nftablesNetPeerNAT = template.Must(template.New("nftablesNetPeerNAT").Parse(`
+add table {{.family}} {{.namespace}}
+add chain {{.family}} {{.namespace}} peernat{{.chainSeparator}}{{.networkName}}
+flush chain {{.family}} {{.namespace}} peernat{{.chainSeparator}}{{.networkName}}
+
+table {{.family}} {{.namespace}} {
+	chain peernat{{.chainSeparator}}{{.networkName}} {
+		{{- range .rules}}
+		oifname "{{.interface}}" {{.ipFamily}} saddr {{.localSubnet}} {{.ipFamily}} daddr {{.targetSubnet}} accept
+		{{- end}}
+	}
+}
+`))
+
 var nftablesNetACLSetup = template.Must(template.New("nftablesNetACLSetup").Parse(`
 add table {{.family}} {{.namespace}}
 add chain {{.family}} {{.namespace}} acl{{.chainSeparator}}{{.networkName}}
@@ -171,6 +225,14 @@ table {{.family}} {{.namespace}} {
 var nftablesNetACLRules = template.Must(template.New("nftablesNetACLRules").Parse(`
 flush chain {{.family}} {{.namespace}} acl{{.chainSeparator}}{{.networkName}}
 
+{{- range .sets}}
+add set {{$.family}} {{$.namespace}} {{.name}} {type {{.type}}; flags interval; auto-merge;}
+flush set {{$.family}} {{$.namespace}} {{.name}}
+{{- if .elements}}
+add element {{$.family}} {{$.namespace}} {{.name}} { {{.elements}} }
+{{- end}}
+{{- end}}
+
 table {{.family}} {{.namespace}} {
 	chain acl{{.chainSeparator}}{{.networkName}} {
                 ct state established,related accept
@@ -182,6 +244,16 @@ table {{.family}} {{.namespace}} {
 }
 `))
 
+// nftablesNetACLSets replaces the elements of the sets used by ACL rules.
+var nftablesNetACLSets = template.Must(template.New("nftablesNetACLSets").Parse(`
+{{- range .sets}}
+flush set {{$.family}} {{$.namespace}} {{.name}}
+{{- if .elements}}
+add element {{$.family}} {{$.namespace}} {{.name}} { {{.elements}} }
+{{- end}}
+{{- end}}
+`))
+
 // nftablesInstanceBridgeFilter defines the rules needed for MAC, IPv4 and IPv6 bridge security filtering.
 // To prevent instances from using IPs that are different from their assigned IPs we use ARP and NDP filtering
 // to prevent neighbour advertisements that are not allowed. However in order for DHCPv4 & DHCPv6 to work back to
@@ -269,10 +341,20 @@ chain prert{{.chainSeparator}}{{.deviceLabel}} {
 }
 `))
 
-// nftablesInstanceNetPrio defines the rules to perform setting of skb->priority.
+// nftablesInstanceNetPrio defines the rules to perform setting of skb->priority and DSCP marking.
 var nftablesInstanceNetPrio = template.Must(template.New("nftablesInstanceNetPrio").Parse(`
+{{if .netPrio -}}
 chain egress{{.chainSeparator}}netprio{{.chainSeparator}}{{.deviceLabel}} {
-	type filter hook egress device "{{.deviceName}}" priority 0 ;
+	type filter hook egress device "{{.hostName}}" priority 0 ;
 	meta priority set "{{.netPrio}}"
 }
+{{- end}}
+
+{{if ge .dscp 0 -}}
+chain ingress{{.chainSeparator}}netprio{{.chainSeparator}}{{.deviceLabel}} {
+	type filter hook ingress device "{{.hostName}}" priority 0 ;
+	meta protocol ip ip dscp set {{.dscp}}
+	meta protocol ip6 ip6 dscp set {{.dscp}}
+}
+{{- end}}
 `))
@@ -4,6 +4,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"net"
+	"strings"
 )
 
 // portRangesFromSlice checks if adjacent indices in the given slice contain consecutive
@@ -111,6 +112,84 @@ func getOptimisedDNATRanges(forward *AddressForward) map[[2]uint64][2]uint64 {
 	return snatRules
 }
 
+// validateLoadBalancer checks that the load balancer has a listen address, listen ports and backends whose
+// target addresses and ports are compatible with them.
+func validateLoadBalancer(lb *LoadBalancer) error {
+	if lb.ListenAddress == nil {
+		return fmt.Errorf("Listen address is required")
+	}
+
+	if lb.Protocol == "" || len(lb.ListenPorts) == 0 {
+		return fmt.Errorf("Protocol and listen ports are required")
+	}
+
+	if lb.Selection != "" && lb.Selection != "round-robin" && lb.Selection != "hash" {
+		return fmt.Errorf("Invalid backend selection %q", lb.Selection)
+	}
+
+	if len(lb.Backends) == 0 {
+		return fmt.Errorf("At least one backend is required")
+	}
+
+	listenIsIP4 := lb.ListenAddress.To4() != nil
+	for i, backend := range lb.Backends {
+		if backend.TargetAddress == nil {
+			return fmt.Errorf("Target address is required for backend %d", i)
+		}
+
+		if listenIsIP4 != (backend.TargetAddress.To4() != nil) {
+			return fmt.Errorf("Mismatch between listen address and target address IP versions for backend %d", i)
+		}
+
+		targetPortsLen := len(backend.TargetPorts)
+		if targetPortsLen > 1 && targetPortsLen != len(lb.ListenPorts) {
+			return fmt.Errorf("Mismatch between listen port(s) and target port(s) count for backend %d", i)
+		}
+	}
+
+	return nil
+}
+
+// loadBalancerTargetPort returns the port of the backend that the listen port at the given index is forwarded to.
+func loadBalancerTargetPort(backend *LoadBalancerBackend, listenPorts []uint64, listenPortIndex int) uint64 {
+	switch len(backend.TargetPorts) {
+	case 0:
+		return listenPorts[listenPortIndex]
+	case 1:
+		return backend.TargetPorts[0]
+	}
+
+	return backend.TargetPorts[listenPortIndex]
+}
+
+// aclAddressSetName returns the name of the address set referenced by an ACL rule criterion (if any).
+func aclAddressSetName(criterion string) (string, bool) {
+	return strings.CutPrefix(criterion, "$")
+}
+
+// aclAddressSetByName returns the address set with the given name.
+func aclAddressSetByName(addressSets []AddressSet, name string) (*AddressSet, error) {
+	for i := range addressSets {
+		if addressSets[i].Name == name {
+			return &addressSets[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("Unknown address set %q", name)
+}
+
+// aclRuleHasAddressSet returns whether the ACL rule references an address set.
+func aclRuleHasAddressSet(rule *ACLRule) bool {
+	for _, criterion := range []string{rule.Source, rule.Destination, rule.SourcePort, rule.DestinationPort} {
+		_, isSet := aclAddressSetName(criterion)
+		if isSet {
+			return true
+		}
+	}
+
+	return false
+}
+
 // subnetMask returns the subnet mask of the given network as a string. Both IPv4 and IPv6 are handled.
 func subnetMask(ipNet *net.IPNet) string {
 	if ipNet.IP.To4() != nil {
@@ -2,6 +2,7 @@ package drivers
 
 import (
 	"log"
+	"net"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -145,3 +146,129 @@ func Test_getOptimisedSNATRanges(t *testing.T) {
 		assert.Equal(t, tt.expected, actual)
 	}
 }
+
+func Test_loadBalancerTargetPort(t *testing.T) {
+	listenPorts := []uint64{80, 81, 82}
+
+	tests := []struct {
+		name     string
+		backend  *LoadBalancerBackend
+		expected []uint64
+	}{
+		{
+			name:     "No target ports",
+			backend:  &LoadBalancerBackend{},
+			expected: []uint64{80, 81, 82},
+		},
+		{
+			name:     "Single target port",
+			backend:  &LoadBalancerBackend{TargetPorts: []uint64{8080}},
+			expected: []uint64{8080, 8080, 8080},
+		},
+		{
+			name:     "One to one target ports",
+			backend:  &LoadBalancerBackend{TargetPorts: []uint64{90, 91, 92}},
+			expected: []uint64{90, 91, 92},
+		},
+	}
+
+	for _, tt := range tests {
+		actual := make([]uint64, 0, len(listenPorts))
+		for i := range listenPorts {
+			actual = append(actual, loadBalancerTargetPort(tt.backend, listenPorts, i))
+		}
+
+		assert.Equal(t, tt.expected, actual, tt.name)
+	}
+}
+
+func Test_validateLoadBalancer(t *testing.T) {
+	tests := []struct {
+		name  string
+		lb    *LoadBalancer
+		valid bool
+	}{
+		{
+			name: "Valid",
+			lb: &LoadBalancer{
+				ListenAddress: net.ParseIP("192.0.2.1"),
+				Protocol:      "tcp",
+				ListenPorts:   []uint64{80, 81},
+				Backends: []LoadBalancerBackend{
+					{TargetAddress: net.ParseIP("10.0.0.2")},
+					{TargetAddress: net.ParseIP("10.0.0.3"), TargetPorts: []uint64{8080}},
+				},
+				Selection: "hash",
+			},
+			valid: true,
+		},
+		{
+			name: "No backends",
+			lb: &LoadBalancer{
+				ListenAddress: net.ParseIP("192.0.2.1"),
+				Protocol:      "tcp",
+				ListenPorts:   []uint64{80},
+			},
+		},
+		{
+			name: "Mixed IP versions",
+			lb: &LoadBalancer{
+				ListenAddress: net.ParseIP("192.0.2.1"),
+				Protocol:      "tcp",
+				ListenPorts:   []uint64{80},
+				Backends:      []LoadBalancerBackend{{TargetAddress: net.ParseIP("fd00::2")}},
+			},
+		},
+		{
+			name: "Mismatched target ports",
+			lb: &LoadBalancer{
+				ListenAddress: net.ParseIP("192.0.2.1"),
+				Protocol:      "udp",
+				ListenPorts:   []uint64{80, 81, 82},
+				Backends:      []LoadBalancerBackend{{TargetAddress: net.ParseIP("10.0.0.2"), TargetPorts: []uint64{90, 91}}},
+			},
+		},
+		{
+			name: "Invalid selection",
+			lb: &LoadBalancer{
+				ListenAddress: net.ParseIP("192.0.2.1"),
+				Protocol:      "tcp",
+				ListenPorts:   []uint64{80},
+				Backends:      []LoadBalancerBackend{{TargetAddress: net.ParseIP("10.0.0.2")}},
+				Selection:     "random",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		err := validateLoadBalancer(tt.lb)
+		if tt.valid {
+			assert.NoError(t, err, tt.name)
+		} else {
+			assert.Error(t, err, tt.name)
+		}
+	}
+}
+
+func Test_aclIPSetMembers(t *testing.T) {
+	addressSet := AddressSet{
+		Name:      "web",
+		Addresses: []string{"192.0.2.10", "198.51.100.0/24", "0.0.0.0/0", "2001:db8::/64", "::/0"},
+		Ports:     []string{"80", "8000-8080"},
+	}
+
+	members, err := aclIPSetMembers(addressSet, "4")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"192.0.2.10", "198.51.100.0/24", "0.0.0.0/1", "128.0.0.0/1"}, members)
+
+	members, err = aclIPSetMembers(addressSet, "6")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2001:db8::/64", "::/1", "8000::/1"}, members)
+
+	members, err = aclIPSetMembers(addressSet, "p")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"80", "8000-8080"}, members)
+
+	_, err = aclIPSetMembers(AddressSet{Name: "bad", Addresses: []string{"foo"}}, "4")
+	assert.Error(t, err)
+}
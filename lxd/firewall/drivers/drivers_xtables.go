@@ -2,6 +2,8 @@ package drivers
 
 import (
 	"bufio"
+	"context"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"net"
@@ -26,6 +28,19 @@ const iptablesChainACLFilterPrefix = "lxd_acl"
 // iptablesCommentPrefix is used to prefix the rule comment.
 const iptablesCommentPrefix = "generated for"
 
+// ipsetACLPrefix is the prefix of the ipsets holding the members of the address sets used by ACL rules.
+const ipsetACLPrefix = "lxd_"
+
+// ipsetACLTempPrefix is the prefix of the ipsets used to replace the members of an ACL ipset atomically.
+const ipsetACLTempPrefix = "lxt_"
+
+// iptablesLoadBalancerHashSeed is the seed used by the cluster match when picking a backend for hash based load
+// balancing.
+const iptablesLoadBalancerHashSeed = 0x4c5844
+
+// iptablesLoadBalancerHashBackendsMax is the maximum number of backends the cluster match can pick from.
+const iptablesLoadBalancerHashBackendsMax = 32
+
 // ebtablesMu used for locking concurrent operations against ebtables.
 // As its own locking mechanism isn't always available.
 var ebtablesMu sync.Mutex
@@ -167,6 +182,16 @@ func (d Xtables) networkForwardIPTablesComment(networkName string) string {
 	return fmt.Sprintf("LXD network-forward %s", networkName)
 }
 
+// networkLoadBalancerIPTablesComment returns the iptables comment that is added to each network load balancer related rule.
+func (d Xtables) networkLoadBalancerIPTablesComment(networkName string) string {
+	return fmt.Sprintf("LXD network-load-balancer %s", networkName)
+}
+
+// networkPeerIPTablesComment returns the iptables comment that is added to each network peer related rule.
+func (d Xtables) networkPeerIPTablesComment(networkName string) string {
+	return fmt.Sprintf("LXD network-peer %s", networkName)
+}
+
 // networkSetupNICFilteringChain creates the NIC filtering chain if it doesn't exist, and adds the jump rules to
 // the INPUT and FORWARD filter chains. Must be called after networkSetupForwardingPolicy so that the rules are
 // prepended before the default fowarding policy rules.
@@ -521,7 +546,9 @@ func (d Xtables) NetworkSetup(networkName string, ipv4Address net.IP, ipv6Addres
 }
 
 // NetworkApplyACLRules applies ACL rules to the existing firewall chains.
-func (d Xtables) NetworkApplyACLRules(networkName string, rules []ACLRule) error {
+// The referenced address sets are created as ipsets so that the rules don't need to be regenerated when their
+// members change.
+func (d Xtables) NetworkApplyACLRules(networkName string, rules []ACLRule, addressSets []AddressSet) error {
 	chain := fmt.Sprintf("%s_%s", iptablesChainACLFilterPrefix, networkName)
 
 	// Parse rules for both IP families before applying either family of rules.
@@ -534,7 +561,7 @@ func (d Xtables) NetworkApplyACLRules(networkName string, rules []ACLRule) error
 
 		iptRules := make([][]string, 0)
 		for _, rule := range rules {
-			actionArgs, logArgs, err := d.aclRuleCriteriaToArgs(networkName, ipVersion, &rule)
+			actionArgs, logArgs, err := d.aclRuleCriteriaToArgs(networkName, ipVersion, &rule, addressSets)
 			if err != nil {
 				return err
 			}
@@ -577,6 +604,12 @@ func (d Xtables) NetworkApplyACLRules(networkName string, rules []ACLRule) error
 		return nil
 	}
 
+	// Create the ipsets before the rules referencing them.
+	ipsetNames, err := d.aclIPSetsApply(networkName, addressSets, false)
+	if err != nil {
+		return err
+	}
+
 	// Apply each family of rules.
 	for cmd, rules := range iptCmdRules {
 		err := applyACLRules(cmd, rules)
@@ -585,13 +618,250 @@ func (d Xtables) NetworkApplyACLRules(networkName string, rules []ACLRule) error
 		}
 	}
 
+	// Remove the ipsets of the address sets that are no longer referenced.
+	err = d.aclIPSetsRemove(networkName, ipsetNames...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// NetworkUpdateAddressSets replaces the members of the address sets used by the ACL rules of a network without
+// changing the rules, so that their counters are kept. The address sets that the network doesn't use are skipped.
+func (d Xtables) NetworkUpdateAddressSets(networkName string, addressSets []AddressSet) error {
+	_, err := d.aclIPSetsApply(networkName, addressSets, true)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// aclIPSetName returns the name of the ipset holding the members of the given type ("4" for IPv4 addresses, "6"
+// for IPv6 addresses or "p" for ports) of an address set used by the ACL rules of a network.
+// As ipset names are limited to 31 characters, the address set name is hashed.
+func (d Xtables) aclIPSetName(networkName string, addressSetName string, setType string) string {
+	hash := sha256.Sum256([]byte(addressSetName))
+
+	return fmt.Sprintf("%s%s_%x_%s", ipsetACLPrefix, networkName, hash[:4], setType)
+}
+
+// aclIPSetMembers returns the members of the address set that belong in its ipset of the given type.
+func aclIPSetMembers(addressSet AddressSet, setType string) ([]string, error) {
+	if setType == "p" {
+		return addressSet.Ports, nil
+	}
+
+	members := []string{}
+	for _, address := range addressSet.Addresses {
+		ip := net.ParseIP(address)
+		prefixLen := -1
+		if ip == nil {
+			var subnet *net.IPNet
+
+			ip, subnet, _ = net.ParseCIDR(address)
+			if subnet != nil {
+				prefixLen, _ = subnet.Mask.Size()
+			}
+		}
+
+		if ip == nil {
+			return nil, fmt.Errorf("Invalid address %q in address set %q", address, addressSet.Name)
+		}
+
+		isIPv4 := ip.To4() != nil
+		if isIPv4 != (setType == "4") {
+			continue
+		}
+
+		// The hash:net ipsets don't accept a zero prefix length, so cover the whole range with two halves.
+		if prefixLen == 0 {
+			if isIPv4 {
+				members = append(members, "0.0.0.0/1", "128.0.0.0/1")
+			} else {
+				members = append(members, "::/1", "8000::/1")
+			}
+
+			continue
+		}
+
+		members = append(members, address)
+	}
+
+	return members, nil
+}
+
+// aclIPSetsApply creates the ipsets of the address sets used by the ACL rules of a network and atomically replaces
+// their members. If onlyExisting is true, the ipsets that don't exist yet are skipped.
+// Returns the names of the ipsets.
+func (d Xtables) aclIPSetsApply(networkName string, addressSets []AddressSet, onlyExisting bool) ([]string, error) {
+	if len(addressSets) == 0 {
+		return nil, nil
+	}
+
+	existing, err := d.aclIPSets(networkName)
+	if err != nil {
+		return nil, err
+	}
+
+	ipsetTypes := map[string]string{
+		"4": "hash:net family inet",
+		"6": "hash:net family inet6",
+		"p": "bitmap:port range 0-65535",
+	}
+
+	names := make([]string, 0, len(addressSets)*len(ipsetTypes))
+	script := &strings.Builder{}
+	for _, addressSet := range addressSets {
+		for _, setType := range []string{"4", "6", "p"} {
+			name := d.aclIPSetName(networkName, addressSet.Name, setType)
+			if onlyExisting && !shared.ValueInSlice(name, existing) {
+				continue
+			}
+
+			members, err := aclIPSetMembers(addressSet, setType)
+			if err != nil {
+				return nil, err
+			}
+
+			// Fill a temporary ipset and swap it with the one referenced by the rules.
+			tempName := ipsetACLTempPrefix + strings.TrimPrefix(name, ipsetACLPrefix)
+			fmt.Fprintf(script, "create %s %s -exist\n", tempName, ipsetTypes[setType])
+			fmt.Fprintf(script, "flush %s\n", tempName)
+			for _, member := range members {
+				fmt.Fprintf(script, "add %s %s -exist\n", tempName, member)
+			}
+
+			fmt.Fprintf(script, "create %s %s -exist\n", name, ipsetTypes[setType])
+			fmt.Fprintf(script, "swap %s %s\n", tempName, name)
+			fmt.Fprintf(script, "destroy %s\n", tempName)
+
+			names = append(names, name)
+		}
+	}
+
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	err = shared.RunCommandWithFds(context.TODO(), strings.NewReader(script.String()), nil, "ipset", "restore")
+	if err != nil {
+		return nil, fmt.Errorf("Failed applying ipsets for network %q: %w", networkName, err)
+	}
+
+	return names, nil
+}
+
+// aclIPSets returns the names of the ipsets of the address sets used by the ACL rules of a network.
+func (d Xtables) aclIPSets(networkName string) ([]string, error) {
+	// Without the ipset tool no ipsets can have been created.
+	_, err := exec.LookPath("ipset")
+	if err != nil {
+		return nil, nil
+	}
+
+	output, err := shared.RunCommandCLocale("ipset", "list", "-n")
+	if err != nil {
+		return nil, fmt.Errorf("Failed listing ipsets: %w", err)
+	}
+
+	// The names are made of the prefix, the network name, an 8 characters hash and the type.
+	prefix := ipsetACLPrefix + networkName + "_"
+
+	names := []string{}
+	for _, name := range strings.Split(output, "\n") {
+		suffix, found := strings.CutPrefix(strings.TrimSpace(name), prefix)
+		if found && len(suffix) == 10 && suffix[8] == '_' {
+			names = append(names, prefix+suffix)
+		}
+	}
+
+	return names, nil
+}
+
+// aclIPSetsRemove removes the ipsets of the address sets used by the ACL rules of a network, apart from the ones
+// to keep. The ipsets must not be referenced by any rule anymore.
+func (d Xtables) aclIPSetsRemove(networkName string, keepSets ...string) error {
+	names, err := d.aclIPSets(networkName)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if shared.ValueInSlice(name, keepSets) {
+			continue
+		}
+
+		_, err := shared.RunCommand("ipset", "destroy", name)
+		if err != nil {
+			return fmt.Errorf("Failed deleting ipset %q: %w", name, err)
+		}
+	}
+
 	return nil
 }
 
+// NetworkACLRuleCounters returns the counters of the ACL rules of a network keyed by counter name.
+// The counters of the IPv4 and IPv6 rules are summed.
+func (d Xtables) NetworkACLRuleCounters(networkName string) (map[string]ACLRuleCounter, error) {
+	chain := fmt.Sprintf("%s_%s", iptablesChainACLFilterPrefix, networkName)
+
+	counters := make(map[string]ACLRuleCounter)
+	for _, ipVersion := range []uint{4, 6} {
+		cmd := "iptables"
+		if ipVersion == 6 {
+			cmd = "ip6tables"
+		}
+
+		exists, _, err := d.iptablesChainExists(ipVersion, "filter", chain)
+		if err != nil || !exists {
+			continue
+		}
+
+		output, err := shared.RunCommandCLocale(cmd, "-w", "-t", "filter", "-L", chain, "-n", "-v", "-x")
+		if err != nil {
+			return nil, fmt.Errorf("Failed listing %q chain %q in table %q: %w", cmd, chain, "filter", err)
+		}
+
+		// Each rule line starts with the packet and byte counters, and the comment is shown as "/* <name> */".
+		for _, line := range strings.Split(output, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) < 2 {
+				continue
+			}
+
+			commentStart := strings.Index(line, "/* ")
+			commentEnd := strings.Index(line, " */")
+			if commentStart < 0 || commentEnd < commentStart {
+				continue
+			}
+
+			packets, err := strconv.ParseUint(fields[0], 10, 64)
+			if err != nil {
+				continue // Skip the header lines.
+			}
+
+			bytes, err := strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				continue
+			}
+
+			name := line[commentStart+3 : commentEnd]
+			counter := counters[name]
+			counter.Packets += packets
+			counter.Bytes += bytes
+			counters[name] = counter
+		}
+	}
+
+	return counters, nil
+}
+
 // aclRuleCriteriaToArgs converts an ACL rule into an set of arguments for an xtables rule.
 // Returns the arguments to use for the action command and separately the arguments for logging if enabled.
 // Returns nil arguments if the rule is not appropriate for the ipVersion.
-func (d Xtables) aclRuleCriteriaToArgs(networkName string, ipVersion uint, rule *ACLRule) ([]string, []string, error) {
+func (d Xtables) aclRuleCriteriaToArgs(networkName string, ipVersion uint, rule *ACLRule, addressSets []AddressSet) ([]string, []string, error) {
 	var args []string
 
 	if rule.Direction == "ingress" {
@@ -602,7 +872,7 @@ func (d Xtables) aclRuleCriteriaToArgs(networkName string, ipVersion uint, rule
 
 	// Add subject filters.
 	if rule.Source != "" {
-		matchArgs, err := d.aclRuleSubjectToACLMatch("source", ipVersion, shared.SplitNTrimSpace(rule.Source, ",", -1, false)...)
+		matchArgs, err := d.aclRuleSubjectToACLMatch(networkName, "source", ipVersion, addressSets, shared.SplitNTrimSpace(rule.Source, ",", -1, false)...)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -615,7 +885,7 @@ func (d Xtables) aclRuleCriteriaToArgs(networkName string, ipVersion uint, rule
 	}
 
 	if rule.Destination != "" {
-		matchArgs, err := d.aclRuleSubjectToACLMatch("destination", ipVersion, shared.SplitNTrimSpace(rule.Destination, ",", -1, false)...)
+		matchArgs, err := d.aclRuleSubjectToACLMatch(networkName, "destination", ipVersion, addressSets, shared.SplitNTrimSpace(rule.Destination, ",", -1, false)...)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -632,11 +902,21 @@ func (d Xtables) aclRuleCriteriaToArgs(networkName string, ipVersion uint, rule
 		args = append(args, "-p", rule.Protocol)
 
 		if rule.SourcePort != "" {
-			args = append(args, d.aclRulePortToACLMatch("sports", shared.SplitNTrimSpace(rule.SourcePort, ",", -1, false)...)...)
+			matchArgs, err := d.aclRulePortToACLMatch(networkName, "sports", addressSets, shared.SplitNTrimSpace(rule.SourcePort, ",", -1, false)...)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			args = append(args, matchArgs...)
 		}
 
 		if rule.DestinationPort != "" {
-			args = append(args, d.aclRulePortToACLMatch("dports", shared.SplitNTrimSpace(rule.DestinationPort, ",", -1, false)...)...)
+			matchArgs, err := d.aclRulePortToACLMatch(networkName, "dports", addressSets, shared.SplitNTrimSpace(rule.DestinationPort, ",", -1, false)...)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			args = append(args, matchArgs...)
 		}
 	} else if shared.ValueInSlice(rule.Protocol, []string{"icmp4", "icmp6"}) {
 		var icmpIPVersion uint
@@ -655,6 +935,12 @@ func (d Xtables) aclRuleCriteriaToArgs(networkName string, ipVersion uint, rule
 		}
 
 		if ipVersion != icmpIPVersion {
+			// Address sets hold addresses of both IP versions, only the part of the rule matching the
+			// IP version of the ICMP protocol applies.
+			if aclRuleHasAddressSet(rule) {
+				return nil, nil, nil // Rule is not appropriate for ipVersion.
+			}
+
 			// If we got this far it means that source/destination are either empty or are filled
 			// with at least some subjects in the same family as ipVersion. So if the icmpIPVersion
 			// doesn't match the ipVersion then it means the rule contains mixed-version subjects
@@ -691,7 +977,14 @@ func (d Xtables) aclRuleCriteriaToArgs(networkName string, ipVersion uint, rule
 		action = "accept"
 	}
 
-	actionArgs := append(args, "-j", strings.ToUpper(action))
+	actionArgs := append([]string{}, args...)
+
+	// Add the counter name as a comment so the counters can be matched back to the rule.
+	if rule.CounterName != "" {
+		actionArgs = append(actionArgs, "-m", "comment", "--comment", rule.CounterName)
+	}
+
+	actionArgs = append(actionArgs, "-j", strings.ToUpper(action))
 
 	// Handle logging.
 	var logArgs []string
@@ -709,7 +1002,28 @@ func (d Xtables) aclRuleCriteriaToArgs(networkName string, ipVersion uint, rule
 
 // aclRuleSubjectToACLMatch converts direction (source/destination) and subject criteria list into xtables args.
 // Returns nil if none of the subjects are appropriate for the ipVersion.
-func (d Xtables) aclRuleSubjectToACLMatch(direction string, ipVersion uint, subjectCriteria ...string) ([]string, error) {
+func (d Xtables) aclRuleSubjectToACLMatch(networkName string, direction string, ipVersion uint, addressSets []AddressSet, subjectCriteria ...string) ([]string, error) {
+	// An address set is the only subject of the criteria. Its IPv4 and IPv6 members are held in separate
+	// ipsets, matched by the iptables and ip6tables rules respectively.
+	if len(subjectCriteria) == 1 {
+		addressSetName, isSet := aclAddressSetName(subjectCriteria[0])
+		if isSet {
+			_, err := aclAddressSetByName(addressSets, addressSetName)
+			if err != nil {
+				return nil, err
+			}
+
+			ipsetDirection := "src"
+			if direction == "destination" {
+				ipsetDirection = "dst"
+			}
+
+			ipsetName := d.aclIPSetName(networkName, addressSetName, strconv.FormatUint(uint64(ipVersion), 10))
+
+			return []string{"-m", "set", "--match-set", ipsetName, ipsetDirection}, nil
+		}
+	}
+
 	fieldParts := make([]string, 0, len(subjectCriteria))
 
 	// For each criterion check if value looks like IP CIDR.
@@ -744,7 +1058,25 @@ func (d Xtables) aclRuleSubjectToACLMatch(direction string, ipVersion uint, subj
 
 // aclRulePortToACLMatch converts protocol (tcp/udp), direction (sports/dports) and port criteria list into
 // xtables args.
-func (d Xtables) aclRulePortToACLMatch(direction string, portCriteria ...string) []string {
+func (d Xtables) aclRulePortToACLMatch(networkName string, direction string, addressSets []AddressSet, portCriteria ...string) ([]string, error) {
+	// An address set is the only port criterion.
+	if len(portCriteria) == 1 {
+		addressSetName, isSet := aclAddressSetName(portCriteria[0])
+		if isSet {
+			_, err := aclAddressSetByName(addressSets, addressSetName)
+			if err != nil {
+				return nil, err
+			}
+
+			ipsetDirection := "src"
+			if direction == "dports" {
+				ipsetDirection = "dst"
+			}
+
+			return []string{"-m", "set", "--match-set", d.aclIPSetName(networkName, addressSetName, "p"), ipsetDirection}, nil
+		}
+	}
+
 	fieldParts := make([]string, 0, len(portCriteria))
 
 	for _, portCriterion := range portCriteria {
@@ -756,7 +1088,7 @@ func (d Xtables) aclRulePortToACLMatch(direction string, portCriteria ...string)
 		}
 	}
 
-	return []string{"-m", "multiport", fmt.Sprintf("--%s", direction), strings.Join(fieldParts, ",")}
+	return []string{"-m", "multiport", fmt.Sprintf("--%s", direction), strings.Join(fieldParts, ",")}, nil
 }
 
 // NetworkClear removes network rules from filter, mangle and nat tables.
@@ -765,10 +1097,12 @@ func (d Xtables) NetworkClear(networkName string, delete bool, ipVersions []uint
 	comments := []string{
 		d.networkIPTablesComment(networkName),
 		d.networkForwardIPTablesComment(networkName),
+		d.networkLoadBalancerIPTablesComment(networkName),
+		d.networkPeerIPTablesComment(networkName),
 	}
 
 	for _, ipVersion := range ipVersions {
-		// Clear any rules associated to the network and network address forwards.
+		// Clear any rules associated to the network, network address forwards, load balancers and peers.
 		err := d.iptablesClear(ipVersion, comments, "filter", "mangle", "nat")
 		if err != nil {
 			return err
@@ -806,9 +1140,41 @@ func (d Xtables) NetworkClear(networkName string, delete bool, ipVersions []uint
 		}
 	}
 
+	// Remove the ipsets used by ACL rules once the chains of both IP versions referencing them are gone.
+	if shared.ValueInSlice(4, ipVersions) && shared.ValueInSlice(6, ipVersions) {
+		err := d.aclIPSetsRemove(networkName)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// NetworkRules returns the live rules of the network, network address forwards, load balancers, peers and ACLs.
+func (d Xtables) NetworkRules(networkName string) ([]string, error) {
+	comments := []string{
+		d.networkIPTablesComment(networkName),
+		d.networkForwardIPTablesComment(networkName),
+		d.networkLoadBalancerIPTablesComment(networkName),
+		d.networkPeerIPTablesComment(networkName),
+	}
+
+	aclFilterChain := fmt.Sprintf("%s_%s", iptablesChainACLFilterPrefix, networkName)
+
+	rules := []string{}
+	for _, ipVersion := range []uint{4, 6} {
+		ipRules, err := d.iptablesRules(ipVersion, comments, []string{aclFilterChain}, "filter", "mangle", "nat")
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, ipRules...)
+	}
+
+	return rules, nil
+}
+
 // instanceDeviceIPTablesComment returns the iptables comment that is added to each instance device related rule.
 func (d Xtables) instanceDeviceIPTablesComment(projectName string, instanceName string, deviceName string) string {
 	return fmt.Sprintf("LXD container %s (%s)", project.Instance(projectName, instanceName), deviceName)
@@ -1256,8 +1622,9 @@ func (d Xtables) iptablesPrepend(ipVersion uint, comment string, table string, c
 	return d.iptablesAdd(ipVersion, comment, table, "-I", chain, rule...)
 }
 
-// iptablesClear clears iptables rules matching the supplied comment in the specified tables.
-func (d Xtables) iptablesClear(ipVersion uint, comments []string, fromTables ...string) error {
+// iptablesListRules returns the command used for the IP version along with the rules of the specified tables
+// (as shown by --list-rules) keyed by table. Tables that don't exist are skipped.
+func (d Xtables) iptablesListRules(ipVersion uint, fromTables ...string) (string, map[string][]string, error) {
 	var cmd string
 	var tablesFile string
 	if ipVersion == 4 {
@@ -1267,18 +1634,20 @@ func (d Xtables) iptablesClear(ipVersion uint, comments []string, fromTables ...
 		cmd = "ip6tables"
 		tablesFile = "/proc/self/net/ip6_tables_names"
 	} else {
-		return fmt.Errorf("Invalid IP version")
+		return "", nil, fmt.Errorf("Invalid IP version")
 	}
 
+	rules := make(map[string][]string, len(fromTables))
+
 	// Detect kernels that lack IPv6 support.
 	if !shared.PathExists("/proc/sys/net/ipv6") && ipVersion == 6 {
-		return nil
+		return cmd, rules, nil
 	}
 
 	// Check command exists.
 	_, err := exec.LookPath(cmd)
 	if err != nil {
-		return nil
+		return cmd, rules, nil
 	}
 
 	// Check which tables exist.
@@ -1305,16 +1674,33 @@ func (d Xtables) iptablesClear(ipVersion uint, comments []string, fromTables ...
 			continue
 		}
 
-		baseArgs := []string{"-w", "-t", fromTable}
-
 		// List the rules.
-		args := append(baseArgs, "--list-rules")
-		output, err := shared.TryRunCommand(cmd, args...)
+		output, err := shared.TryRunCommand(cmd, "-w", "-t", fromTable, "--list-rules")
 		if err != nil {
-			return fmt.Errorf("Failed to list IPv%d rules (table %s)", ipVersion, fromTable)
+			return "", nil, fmt.Errorf("Failed to list IPv%d rules (table %s)", ipVersion, fromTable)
 		}
 
 		for _, line := range strings.Split(output, "\n") {
+			if line != "" {
+				rules[fromTable] = append(rules[fromTable], line)
+			}
+		}
+	}
+
+	return cmd, rules, nil
+}
+
+// iptablesClear clears iptables rules matching the supplied comment in the specified tables.
+func (d Xtables) iptablesClear(ipVersion uint, comments []string, fromTables ...string) error {
+	cmd, rules, err := d.iptablesListRules(ipVersion, fromTables...)
+	if err != nil {
+		return err
+	}
+
+	for _, fromTable := range fromTables {
+		baseArgs := []string{"-w", "-t", fromTable}
+
+		for _, line := range rules[fromTable] {
 			for _, comment := range comments {
 				if !strings.Contains(line, fmt.Sprintf("%s %s", iptablesCommentPrefix, comment)) {
 					continue
@@ -1324,7 +1710,7 @@ func (d Xtables) iptablesClear(ipVersion uint, comments []string, fromTables ...
 				fields := strings.Fields(line)
 				fields[0] = "-D"
 
-				args = append(baseArgs, fields...)
+				args := append(baseArgs, fields...)
 				_, err = shared.TryRunCommand("sh", "-c", fmt.Sprintf("%s %s", cmd, strings.Join(args, " ")))
 				if err != nil {
 					return err
@@ -1336,6 +1722,57 @@ func (d Xtables) iptablesClear(ipVersion uint, comments []string, fromTables ...
 	return nil
 }
 
+// iptablesRules returns the rules of the specified tables that match the supplied comments or that belong to the
+// specified chains, in a form suitable for comparison.
+func (d Xtables) iptablesRules(ipVersion uint, comments []string, chains []string, fromTables ...string) ([]string, error) {
+	cmd, rules, err := d.iptablesListRules(ipVersion, fromTables...)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []string{}
+	for _, fromTable := range fromTables {
+		for _, line := range rules[fromTable] {
+			fields := strings.Fields(line)
+			if len(fields) < 2 || fields[0] != "-A" {
+				continue
+			}
+
+			match := shared.ValueInSlice(fields[1], chains)
+			for _, comment := range comments {
+				if strings.Contains(line, fmt.Sprintf("%s %s", iptablesCommentPrefix, comment)) {
+					match = true
+					break
+				}
+			}
+
+			if match {
+				matches = append(matches, fmt.Sprintf("%s -t %s %s", cmd, fromTable, line))
+			}
+		}
+	}
+
+	return matches, nil
+}
+
+// InstanceRules returns the live iptables rules of the specified instance device.
+// The ebtables rules used for bridge filtering aren't included as they don't carry an identifying comment.
+func (d Xtables) InstanceRules(projectName string, instanceName string, deviceName string) ([]string, error) {
+	comment := d.instanceDeviceIPTablesComment(projectName, instanceName, deviceName)
+
+	rules := []string{}
+	for _, ipVersion := range []uint{4, 6} {
+		ipRules, err := d.iptablesRules(ipVersion, []string{comment}, nil, "filter", "mangle", "nat", "raw")
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, ipRules...)
+	}
+
+	return rules, nil
+}
+
 // InstanceSetupRPFilter activates reverse path filtering for the specified instance device on the host interface.
 func (d Xtables) InstanceSetupRPFilter(projectName string, instanceName string, deviceName string, hostName string) error {
 	comment := fmt.Sprintf("%s rpfilter", d.instanceDeviceIPTablesComment(projectName, instanceName, deviceName))
@@ -1382,44 +1819,57 @@ func (d Xtables) InstanceClearRPFilter(projectName string, instanceName string,
 	return nil
 }
 
-// InstanceSetupNetPrio activates setting of skb->priority for the specified instance device on the host interface.
-func (d Xtables) InstanceSetupNetPrio(projectName string, instanceName string, deviceName string, netPrio uint32) error {
+// InstanceSetupNetPrio activates setting of skb->priority and of the DSCP field of the outgoing packets for the
+// specified instance device on the host interface. A netPrio of 0 leaves skb->priority unchanged and a negative dscp
+// leaves the DSCP field unchanged.
+func (d Xtables) InstanceSetupNetPrio(projectName string, instanceName string, deviceName string, hostName string, netPrio uint32, dscp int) error {
 	comment := fmt.Sprintf("%s netprio", d.instanceDeviceIPTablesComment(projectName, instanceName, deviceName))
-	class := fmt.Sprintf("%x:%x", uint16(uint32(netPrio)>>16), uint16(uint32(netPrio)&0xFFFF))
-	args := []string{
-		"-i", deviceName,
-		"-j", "CLASSIFY",
-		"--set-class", class,
+
+	rules := [][]string{}
+	if netPrio != 0 {
+		class := fmt.Sprintf("%x:%x", uint16(uint32(netPrio)>>16), uint16(uint32(netPrio)&0xFFFF))
+		rules = append(rules, []string{"-i", hostName, "-j", "CLASSIFY", "--set-class", class})
 	}
 
-	// IPv4 filter.
-	err := d.iptablesPrepend(4, comment, "mangle", "FORWARD", args...)
-	if err != nil {
-		return err
+	if dscp >= 0 {
+		rules = append(rules, []string{"-i", hostName, "-j", "DSCP", "--set-dscp", fmt.Sprintf("%d", dscp)})
 	}
 
-	// IPv6 filter if IPv6 is enabled.
-	if shared.PathExists("/proc/sys/net/ipv6") {
-		err = d.iptablesPrepend(6, comment, "mangle", "FORWARD", args...)
+	for _, args := range rules {
+		// IPv4 filter.
+		err := d.iptablesPrepend(4, comment, "mangle", "FORWARD", args...)
 		if err != nil {
 			return err
 		}
+
+		// IPv6 filter if IPv6 is enabled.
+		if shared.PathExists("/proc/sys/net/ipv6") {
+			err = d.iptablesPrepend(6, comment, "mangle", "FORWARD", args...)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
 }
 
-// InstanceClearNetPrio removes setting of skb->priority for the specified instance device on the host interface.
-func (d Xtables) InstanceClearNetPrio(projectName string, instanceName string, deviceName string) error {
+// InstanceClearNetPrio removes setting of skb->priority and DSCP for the specified instance device on the host interface.
+func (d Xtables) InstanceClearNetPrio(projectName string, instanceName string, deviceName string, hostName string) error {
 	if deviceName == "" {
 		return fmt.Errorf("Failed clearing netprio rules for instance %q in project %q: device name is empty", instanceName, projectName)
 	}
 
-	comment := fmt.Sprintf("%s netprio", d.instanceDeviceIPTablesComment(projectName, instanceName, deviceName))
+	// Also remove the rules labelled with the host interface name, as used by older versions.
+	comments := []string{fmt.Sprintf("%s netprio", d.instanceDeviceIPTablesComment(projectName, instanceName, deviceName))}
+	if hostName != "" && hostName != deviceName {
+		comments = append(comments, fmt.Sprintf("%s netprio", d.instanceDeviceIPTablesComment(projectName, instanceName, hostName)))
+	}
+
 	errs := []error{}
 
 	for _, ipVersion := range []uint{4, 6} {
-		err := d.iptablesClear(ipVersion, []string{comment}, "mangle")
+		err := d.iptablesClear(ipVersion, comments, "mangle")
 		if err != nil {
 			errs = append(errs, err)
 		}
@@ -1658,3 +2108,156 @@ func (d Xtables) NetworkApplyForwards(networkName string, rules []AddressForward
 	reverter.Success()
 	return nil
 }
+
+// NetworkApplyLoadBalancers apply network load balancer rules to firewall.
+func (d Xtables) NetworkApplyLoadBalancers(networkName string, loadBalancers []LoadBalancer) error {
+	// Validate all load balancers first.
+	for i, lb := range loadBalancers {
+		err := validateLoadBalancer(&lb)
+		if err != nil {
+			return fmt.Errorf("Invalid load balancer %d: %w", i, err)
+		}
+
+		if lb.Selection == "hash" && len(lb.Backends) > iptablesLoadBalancerHashBackendsMax {
+			return fmt.Errorf("Invalid load balancer %d: Hash based selection supports at most %d backends", i, iptablesLoadBalancerHashBackendsMax)
+		}
+	}
+
+	comment := d.networkLoadBalancerIPTablesComment(networkName)
+
+	clearNetworkLoadBalancers := func() error {
+		for _, ipVersion := range []uint{4, 6} {
+			// The mangle table is still cleared to remove the marking rules of older versions.
+			err := d.iptablesClear(ipVersion, []string{comment}, "mangle", "nat")
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	// Clear any load balancer rules associated to the network.
+	err := clearNetworkLoadBalancers()
+	if err != nil {
+		return err
+	}
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	// Clear all network load balancers if we fail, otherwise the load balancers are only partially applied.
+	reverter.Add(func() {
+		err := clearNetworkLoadBalancers()
+		if err != nil {
+			logger.Error("Failed to clear firewall rules after failing to apply network load balancers", logger.Ctx{"network_name": networkName, "err": err})
+		}
+	})
+
+	for _, lb := range loadBalancers {
+		ipVersion := uint(4)
+		if lb.ListenAddress.To4() == nil {
+			ipVersion = 6
+		}
+
+		listenAddressStr := lb.ListenAddress.String()
+		backendsLen := len(lb.Backends)
+
+		for listenPortIndex, listenPort := range lb.ListenPorts {
+			listenPortStr := strconv.FormatUint(listenPort, 10)
+
+			// Rules are prepended, so add them in reverse to have the first backend's rule evaluated first.
+			for backendIndex := backendsLen - 1; backendIndex >= 0; backendIndex-- {
+				backend := lb.Backends[backendIndex]
+				targetPortStr := strconv.FormatUint(loadBalancerTargetPort(&backend, lb.ListenPorts, listenPortIndex), 10)
+
+				targetDest := fmt.Sprintf("%s:%s", backend.TargetAddress.String(), targetPortStr)
+				if ipVersion == 6 {
+					targetDest = fmt.Sprintf("[%s]:%s", backend.TargetAddress.String(), targetPortStr)
+				}
+
+				args := []string{"-p", lb.Protocol, "--destination", listenAddressStr, "--dport", listenPortStr}
+				if lb.Selection == "hash" {
+					// Hash based: the cluster match picks the backend from a hash of the connection's
+					// source address without touching the packet or connection marks.
+					args = append(args, "-m", "cluster", "--cluster-total-nodes", strconv.Itoa(backendsLen), "--cluster-local-node", strconv.Itoa(backendIndex+1), "--cluster-hash-seed", strconv.Itoa(iptablesLoadBalancerHashSeed))
+				} else if backendIndex < backendsLen-1 {
+					// Round-robin: each rule takes one in every N of the connections that reach it, where
+					// N is the number of backends left, and the last backend takes the remainder.
+					args = append(args, "-m", "statistic", "--mode", "nth", "--every", strconv.Itoa(backendsLen-backendIndex), "--packet", "0")
+				}
+
+				args = append(args, "-j", "DNAT", "--to-destination", targetDest)
+
+				// outbound <-> instance.
+				err := d.iptablesPrepend(ipVersion, comment, "nat", "PREROUTING", args...)
+				if err != nil {
+					return err
+				}
+
+				// host <-> instance.
+				err = d.iptablesPrepend(ipVersion, comment, "nat", "OUTPUT", args...)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, backend := range lb.Backends {
+			targetPorts := backend.TargetPorts
+			if len(targetPorts) == 0 {
+				targetPorts = lb.ListenPorts
+			}
+
+			targetAddressStr := backend.TargetAddress.String()
+			for _, targetPortRange := range portRangesFromSlice(targetPorts) {
+				// instance <-> instance.
+				// Requires instance's bridge port has hairpin mode enabled when br_netfilter is loaded.
+				err := d.iptablesPrepend(ipVersion, comment, "nat", "POSTROUTING", "-p", lb.Protocol, "--source", targetAddressStr, "--destination", targetAddressStr, "--dport", portRangeStr(targetPortRange, ":"), "-j", "MASQUERADE")
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	reverter.Success()
+	return nil
+}
+
+// NetworkApplyPeers apply the rules exempting traffic to peer networks from outbound NAT.
+func (d Xtables) NetworkApplyPeers(networkName string, peers []NetworkPeer) error {
+	comment := d.networkPeerIPTablesComment(networkName)
+
+	// Clear any peer rules associated to the network.
+	for _, ipVersion := range []uint{4, 6} {
+		err := d.iptablesClear(ipVersion, []string{comment}, "nat")
+		if err != nil {
+			return err
+		}
+	}
+
+	// The rules are prepended so that they come before the network's outbound NAT rules.
+	for _, peer := range peers {
+		for _, localSubnet := range peer.LocalSubnets {
+			for _, targetSubnet := range peer.TargetSubnets {
+				localIsIP4 := localSubnet.IP.To4() != nil
+				if localIsIP4 != (targetSubnet.IP.To4() != nil) {
+					continue
+				}
+
+				ipVersion := uint(4)
+				if !localIsIP4 {
+					ipVersion = 6
+				}
+
+				err := d.iptablesPrepend(ipVersion, comment, "nat", "POSTROUTING", "--source", localSubnet.String(), "--destination", targetSubnet.String(), "-o", peer.Interface, "-j", "ACCEPT")
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
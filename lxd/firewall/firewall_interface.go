@@ -6,15 +6,20 @@ import (
 	"github.com/canonical/lxd/lxd/firewall/drivers"
 )
 
-// Firewall represents a LXD firewall.
-type Firewall interface {
+// Driver represents a LXD firewall driver.
+type Driver interface {
 	String() string
 	Compat() (bool, error)
 
 	NetworkSetup(networkName string, ip4Address net.IP, ip6Address net.IP, opts drivers.Opts) error
 	NetworkClear(networkName string, delete bool, ipVersions []uint) error
-	NetworkApplyACLRules(networkName string, rules []drivers.ACLRule) error
+	NetworkApplyACLRules(networkName string, rules []drivers.ACLRule, addressSets []drivers.AddressSet) error
+	NetworkUpdateAddressSets(networkName string, addressSets []drivers.AddressSet) error
+	NetworkACLRuleCounters(networkName string) (map[string]drivers.ACLRuleCounter, error)
 	NetworkApplyForwards(networkName string, rules []drivers.AddressForward) error
+	NetworkApplyLoadBalancers(networkName string, loadBalancers []drivers.LoadBalancer) error
+	NetworkApplyPeers(networkName string, peers []drivers.NetworkPeer) error
+	NetworkRules(networkName string) ([]string, error)
 
 	InstanceSetupBridgeFilter(projectName string, instanceName string, deviceName string, parentName string, hostName string, hwAddr string, IPv4Nets []*net.IPNet, IPv6Nets []*net.IPNet, parentManaged bool) error
 	InstanceClearBridgeFilter(projectName string, instanceName string, deviceName string, parentName string, hostName string, hwAddr string, IPv4Nets []*net.IPNet, IPv6Nets []*net.IPNet) error
@@ -25,6 +30,19 @@ type Firewall interface {
 	InstanceSetupRPFilter(projectName string, instanceName string, deviceName string, hostName string) error
 	InstanceClearRPFilter(projectName string, instanceName string, deviceName string) error
 
-	InstanceSetupNetPrio(projectName string, instanceName string, deviceName string, netPrio uint32) error
-	InstanceClearNetPrio(projectName string, instanceName string, deviceName string) error
+	InstanceSetupNetPrio(projectName string, instanceName string, deviceName string, hostName string, netPrio uint32, dscp int) error
+	InstanceClearNetPrio(projectName string, instanceName string, deviceName string, hostName string) error
+
+	InstanceRules(projectName string, instanceName string, deviceName string) ([]string, error)
+}
+
+// Firewall represents a LXD firewall.
+// It wraps a firewall driver and keeps track of the rules applied for each network and instance device so that
+// they can be compared with the live rules and reapplied if they went missing.
+type Firewall interface {
+	Driver
+
+	Track(fn func(driver Driver) error) error
+	Check() ([]Drift, error)
+	Reconcile(drifts []Drift) error
 }
@@ -6,8 +6,13 @@ import (
 )
 
 // New returns an appropriate firewall implementation.
-// Uses xtables if nftables isn't compatible or isn't in use already, otherwise uses nftables.
 func New() Firewall {
+	return newTracker(loadDriver())
+}
+
+// loadDriver returns an appropriate firewall driver.
+// Uses xtables if nftables isn't compatible or isn't in use already, otherwise uses nftables.
+func loadDriver() Driver {
 	nftables := drivers.Nftables{}
 	xtables := drivers.Xtables{}
 
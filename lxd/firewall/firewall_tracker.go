@@ -0,0 +1,511 @@
+package firewall
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+
+	"github.com/canonical/lxd/lxd/firewall/drivers"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// Drift describes the rules of a network or instance device that are missing from the live firewall state.
+type Drift struct {
+	Network  string   `json:"network,omitempty" yaml:"network,omitempty"`
+	Project  string   `json:"project,omitempty" yaml:"project,omitempty"`
+	Instance string   `json:"instance,omitempty" yaml:"instance,omitempty"`
+	Device   string   `json:"device,omitempty" yaml:"device,omitempty"`
+	Missing  []string `json:"missing" yaml:"missing"`
+}
+
+// String returns a description of the network or instance device the rules belong to.
+func (d Drift) String() string {
+	if d.Network != "" {
+		return fmt.Sprintf("network %q", d.Network)
+	}
+
+	return fmt.Sprintf("instance %q device %q in project %q", d.Instance, d.Device, d.Project)
+}
+
+// key returns the key identifying the network or instance device the rules belong to.
+func (d Drift) key() string {
+	if d.Network != "" {
+		return fmt.Sprintf("network/%s", d.Network)
+	}
+
+	return fmt.Sprintf("instance/%s/%s/%s", d.Project, d.Instance, d.Device)
+}
+
+// trackerStep is a driver call that applied rules and that can be replayed to reapply them.
+type trackerStep struct {
+	name  string
+	clear func() error // Called before replaying the step (if set).
+	setup func() error
+	acl   *trackerACL // Arguments of the step applying the network ACL rules (if any).
+}
+
+// trackerACL holds the network ACL rules and address sets that are applied when replaying a step.
+type trackerACL struct {
+	rules       []drivers.ACLRule
+	addressSets []drivers.AddressSet
+}
+
+// trackerOwner holds the driver calls that applied the rules of a network or instance device along with the rules
+// that were live right after the last one.
+type trackerOwner struct {
+	mu       sync.Mutex // Serialises the driver calls for the owner.
+	id       Drift
+	steps    []trackerStep
+	expected []string
+	removed  bool // Whether the owner stopped being tracked while waiting for its lock.
+}
+
+// trackerOwners holds the tracked networks and instance devices.
+type trackerOwners struct {
+	mu     sync.Mutex // Protects the map, not the owners.
+	owners map[string]*trackerOwner
+}
+
+// tracker wraps a firewall driver and records the rules applied for each network and instance device.
+type tracker struct {
+	Driver
+
+	state *trackerOwners
+
+	// If set, the driver calls are only recorded as if they had been made. Used to track the rules that were
+	// applied before a restart.
+	trackOnly bool
+}
+
+// newTracker returns a firewall that tracks the rules applied by the driver.
+func newTracker(driver Driver) *tracker {
+	return &tracker{
+		Driver: driver,
+		state:  &trackerOwners{owners: map[string]*trackerOwner{}},
+	}
+}
+
+// lockOwner returns the owner with the given ID with its lock held, creating it if create is true.
+// Returns nil if the owner isn't tracked and create is false.
+func (t *tracker) lockOwner(id Drift, create bool) *trackerOwner {
+	for {
+		t.state.mu.Lock()
+		owner, found := t.state.owners[id.key()]
+		if !found && create {
+			owner = &trackerOwner{id: id}
+			t.state.owners[id.key()] = owner
+			found = true
+		}
+
+		t.state.mu.Unlock()
+
+		if !found {
+			return nil
+		}
+
+		owner.mu.Lock()
+		if !owner.removed {
+			return owner
+		}
+
+		// The owner was removed while waiting for its lock, look it up again.
+		owner.mu.Unlock()
+	}
+}
+
+// removeOwner stops tracking the owner. The owner's lock must be held.
+func (t *tracker) removeOwner(owner *trackerOwner) {
+	owner.removed = true
+
+	t.state.mu.Lock()
+	if t.state.owners[owner.id.key()] == owner {
+		delete(t.state.owners, owner.id.key())
+	}
+
+	t.state.mu.Unlock()
+}
+
+// rules returns the live rules of the owner.
+func (t *tracker) rules(owner *trackerOwner) ([]string, error) {
+	if owner.id.Network != "" {
+		return t.Driver.NetworkRules(owner.id.Network)
+	}
+
+	return t.Driver.InstanceRules(owner.id.Project, owner.id.Instance, owner.id.Device)
+}
+
+// refresh records the live rules of the owner as its expected rules. The owner's lock must be held.
+// If they can't be listed the owner stops being tracked, as drift couldn't be detected reliably anymore.
+func (t *tracker) refresh(owner *trackerOwner) {
+	rules, err := t.rules(owner)
+	if err != nil {
+		logger.Warn("Failed listing firewall rules, drift detection disabled", logger.Ctx{"owner": owner.id.String(), "err": err})
+		t.removeOwner(owner)
+		return
+	}
+
+	owner.expected = rules
+}
+
+// removeStep removes the steps with the given name from the owner, or all of them if the name is empty.
+// The owner's lock must be held. Returns false if the owner stopped being tracked as it has no steps left.
+func (t *tracker) removeStep(owner *trackerOwner, name string) bool {
+	steps := make([]trackerStep, 0, len(owner.steps))
+	for _, step := range owner.steps {
+		if name != "" && step.name != name {
+			steps = append(steps, step)
+		}
+	}
+
+	owner.steps = steps
+	if len(steps) == 0 {
+		t.removeOwner(owner)
+		return false
+	}
+
+	return true
+}
+
+// record runs the step and records it for the owner, replacing any previous step with the same name.
+// If the step fails, the previous step with the same name isn't replayed anymore as the rules it applied may have
+// been partially replaced.
+func (t *tracker) record(id Drift, step trackerStep) error {
+	owner := t.lockOwner(id, true)
+	defer owner.mu.Unlock()
+
+	if !t.trackOnly {
+		err := step.setup()
+		if err != nil {
+			if t.removeStep(owner, step.name) {
+				t.refresh(owner)
+			}
+
+			return err
+		}
+	}
+
+	replaced := false
+	for i := range owner.steps {
+		if owner.steps[i].name == step.name {
+			owner.steps[i] = step
+			replaced = true
+			break
+		}
+	}
+
+	if !replaced {
+		owner.steps = append(owner.steps, step)
+	}
+
+	t.refresh(owner)
+
+	return nil
+}
+
+// forget runs the clear function and removes the step with the given name from the owner.
+// If the name is empty all of the steps are removed and the owner stops being tracked.
+func (t *tracker) forget(id Drift, name string, clear func() error) error {
+	if t.trackOnly {
+		return nil
+	}
+
+	owner := t.lockOwner(id, false)
+	if owner == nil {
+		return clear()
+	}
+
+	defer owner.mu.Unlock()
+
+	err := clear()
+	if err != nil {
+		return err
+	}
+
+	if t.removeStep(owner, name) {
+		t.refresh(owner)
+	}
+
+	return nil
+}
+
+// owners returns the tracked owners sorted by key.
+func (t *tracker) owners() []*trackerOwner {
+	t.state.mu.Lock()
+	defer t.state.mu.Unlock()
+
+	keys := make([]string, 0, len(t.state.owners))
+	for key := range t.state.owners {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	owners := make([]*trackerOwner, 0, len(keys))
+	for _, key := range keys {
+		owners = append(owners, t.state.owners[key])
+	}
+
+	return owners
+}
+
+// Track records the driver calls made by fn on the provided driver without making them, so that the rules that
+// were applied before a restart are tracked again. The live rules are recorded as the expected ones.
+func (t *tracker) Track(fn func(driver Driver) error) error {
+	return fn(&tracker{Driver: t.Driver, state: t.state, trackOnly: true})
+}
+
+// Check compares the rules applied for each network and instance device with the live rules and returns the ones
+// that have rules missing.
+func (t *tracker) Check() ([]Drift, error) {
+	drifts := []Drift{}
+	for _, owner := range t.owners() {
+		owner.mu.Lock()
+		if owner.removed {
+			owner.mu.Unlock()
+			continue
+		}
+
+		live, err := t.rules(owner)
+		if err != nil {
+			owner.mu.Unlock()
+			return nil, fmt.Errorf("Failed listing firewall rules of %s: %w", owner.id.String(), err)
+		}
+
+		missing := missingRules(owner.expected, live)
+		owner.mu.Unlock()
+
+		if len(missing) > 0 {
+			drift := owner.id
+			drift.Missing = missing
+			drifts = append(drifts, drift)
+		}
+	}
+
+	return drifts, nil
+}
+
+// Reconcile reapplies the rules of the networks and instance devices that have drifted by replaying the driver
+// calls that applied them.
+func (t *tracker) Reconcile(drifts []Drift) error {
+	for _, drift := range drifts {
+		err := t.reconcile(drift)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reconcile replays the driver calls that applied the rules of the network or instance device that has drifted.
+func (t *tracker) reconcile(drift Drift) error {
+	owner := t.lockOwner(drift, false)
+	if owner == nil {
+		return nil // The rules have been cleared since the check.
+	}
+
+	defer owner.mu.Unlock()
+
+	if owner.id.Network != "" {
+		err := t.Driver.NetworkClear(owner.id.Network, false, []uint{4, 6})
+		if err != nil {
+			return fmt.Errorf("Failed clearing firewall rules of %s: %w", owner.id.String(), err)
+		}
+	}
+
+	for _, step := range owner.steps {
+		if step.clear != nil {
+			err := step.clear()
+			if err != nil {
+				return fmt.Errorf("Failed clearing firewall rules of %s: %w", owner.id.String(), err)
+			}
+		}
+
+		err := step.setup()
+		if err != nil {
+			return fmt.Errorf("Failed reapplying firewall rules of %s: %w", owner.id.String(), err)
+		}
+	}
+
+	t.refresh(owner)
+
+	return nil
+}
+
+// missingRules returns the expected rules that aren't live.
+func missingRules(expected []string, live []string) []string {
+	liveCount := make(map[string]int, len(live))
+	for _, rule := range live {
+		liveCount[rule]++
+	}
+
+	missing := []string{}
+	for _, rule := range expected {
+		if liveCount[rule] > 0 {
+			liveCount[rule]--
+			continue
+		}
+
+		missing = append(missing, rule)
+	}
+
+	return missing
+}
+
+// NetworkSetup configures the network rules.
+func (t *tracker) NetworkSetup(networkName string, ip4Address net.IP, ip6Address net.IP, opts drivers.Opts) error {
+	return t.record(Drift{Network: networkName}, trackerStep{
+		name:  "setup",
+		setup: func() error { return t.Driver.NetworkSetup(networkName, ip4Address, ip6Address, opts) },
+	})
+}
+
+// NetworkClear removes the network rules and stops tracking them.
+func (t *tracker) NetworkClear(networkName string, delete bool, ipVersions []uint) error {
+	return t.forget(Drift{Network: networkName}, "", func() error {
+		return t.Driver.NetworkClear(networkName, delete, ipVersions)
+	})
+}
+
+// NetworkApplyACLRules applies the network ACL rules.
+func (t *tracker) NetworkApplyACLRules(networkName string, rules []drivers.ACLRule, addressSets []drivers.AddressSet) error {
+	acl := &trackerACL{
+		rules:       rules,
+		addressSets: append([]drivers.AddressSet(nil), addressSets...),
+	}
+
+	return t.record(Drift{Network: networkName}, trackerStep{
+		name:  "acl",
+		setup: func() error { return t.Driver.NetworkApplyACLRules(networkName, acl.rules, acl.addressSets) },
+		acl:   acl,
+	})
+}
+
+// NetworkUpdateAddressSets replaces the members of the address sets used by the network ACL rules, and makes sure
+// that the new members are used if the rules are reapplied.
+func (t *tracker) NetworkUpdateAddressSets(networkName string, addressSets []drivers.AddressSet) error {
+	owner := t.lockOwner(Drift{Network: networkName}, false)
+	if owner == nil {
+		return t.Driver.NetworkUpdateAddressSets(networkName, addressSets)
+	}
+
+	defer owner.mu.Unlock()
+
+	err := t.Driver.NetworkUpdateAddressSets(networkName, addressSets)
+	if err != nil {
+		return err
+	}
+
+	for _, step := range owner.steps {
+		if step.acl == nil {
+			continue
+		}
+
+		for i := range step.acl.addressSets {
+			for _, addressSet := range addressSets {
+				if addressSet.Name == step.acl.addressSets[i].Name {
+					step.acl.addressSets[i] = addressSet
+				}
+			}
+		}
+	}
+
+	t.refresh(owner)
+
+	return nil
+}
+
+// NetworkApplyForwards applies the network address forward rules.
+func (t *tracker) NetworkApplyForwards(networkName string, rules []drivers.AddressForward) error {
+	return t.record(Drift{Network: networkName}, trackerStep{
+		name:  "forwards",
+		setup: func() error { return t.Driver.NetworkApplyForwards(networkName, rules) },
+	})
+}
+
+// NetworkApplyLoadBalancers applies the network load balancer rules.
+func (t *tracker) NetworkApplyLoadBalancers(networkName string, loadBalancers []drivers.LoadBalancer) error {
+	return t.record(Drift{Network: networkName}, trackerStep{
+		name:  "load-balancers",
+		setup: func() error { return t.Driver.NetworkApplyLoadBalancers(networkName, loadBalancers) },
+	})
+}
+
+// NetworkApplyPeers applies the network peer rules.
+func (t *tracker) NetworkApplyPeers(networkName string, peers []drivers.NetworkPeer) error {
+	return t.record(Drift{Network: networkName}, trackerStep{
+		name:  "peers",
+		setup: func() error { return t.Driver.NetworkApplyPeers(networkName, peers) },
+	})
+}
+
+// InstanceSetupBridgeFilter sets up the bridge filter rules of the instance device.
+func (t *tracker) InstanceSetupBridgeFilter(projectName string, instanceName string, deviceName string, parentName string, hostName string, hwAddr string, IPv4Nets []*net.IPNet, IPv6Nets []*net.IPNet, parentManaged bool) error {
+	return t.record(Drift{Project: projectName, Instance: instanceName, Device: deviceName}, trackerStep{
+		name: "bridge-filter",
+		clear: func() error {
+			return t.Driver.InstanceClearBridgeFilter(projectName, instanceName, deviceName, parentName, hostName, hwAddr, IPv4Nets, IPv6Nets)
+		},
+		setup: func() error {
+			return t.Driver.InstanceSetupBridgeFilter(projectName, instanceName, deviceName, parentName, hostName, hwAddr, IPv4Nets, IPv6Nets, parentManaged)
+		},
+	})
+}
+
+// InstanceClearBridgeFilter removes the bridge filter rules of the instance device.
+func (t *tracker) InstanceClearBridgeFilter(projectName string, instanceName string, deviceName string, parentName string, hostName string, hwAddr string, IPv4Nets []*net.IPNet, IPv6Nets []*net.IPNet) error {
+	return t.forget(Drift{Project: projectName, Instance: instanceName, Device: deviceName}, "bridge-filter", func() error {
+		return t.Driver.InstanceClearBridgeFilter(projectName, instanceName, deviceName, parentName, hostName, hwAddr, IPv4Nets, IPv6Nets)
+	})
+}
+
+// InstanceSetupProxyNAT sets up the proxy NAT rules of the instance device.
+func (t *tracker) InstanceSetupProxyNAT(projectName string, instanceName string, deviceName string, forward *drivers.AddressForward) error {
+	return t.record(Drift{Project: projectName, Instance: instanceName, Device: deviceName}, trackerStep{
+		name:  "proxy-nat",
+		clear: func() error { return t.Driver.InstanceClearProxyNAT(projectName, instanceName, deviceName) },
+		setup: func() error { return t.Driver.InstanceSetupProxyNAT(projectName, instanceName, deviceName, forward) },
+	})
+}
+
+// InstanceClearProxyNAT removes the proxy NAT rules of the instance device.
+func (t *tracker) InstanceClearProxyNAT(projectName string, instanceName string, deviceName string) error {
+	return t.forget(Drift{Project: projectName, Instance: instanceName, Device: deviceName}, "proxy-nat", func() error {
+		return t.Driver.InstanceClearProxyNAT(projectName, instanceName, deviceName)
+	})
+}
+
+// InstanceSetupRPFilter sets up the reverse path filter rules of the instance device.
+func (t *tracker) InstanceSetupRPFilter(projectName string, instanceName string, deviceName string, hostName string) error {
+	return t.record(Drift{Project: projectName, Instance: instanceName, Device: deviceName}, trackerStep{
+		name:  "rpfilter",
+		clear: func() error { return t.Driver.InstanceClearRPFilter(projectName, instanceName, deviceName) },
+		setup: func() error { return t.Driver.InstanceSetupRPFilter(projectName, instanceName, deviceName, hostName) },
+	})
+}
+
+// InstanceClearRPFilter removes the reverse path filter rules of the instance device.
+func (t *tracker) InstanceClearRPFilter(projectName string, instanceName string, deviceName string) error {
+	return t.forget(Drift{Project: projectName, Instance: instanceName, Device: deviceName}, "rpfilter", func() error {
+		return t.Driver.InstanceClearRPFilter(projectName, instanceName, deviceName)
+	})
+}
+
+// InstanceSetupNetPrio sets up the netprio rules of the instance device.
+func (t *tracker) InstanceSetupNetPrio(projectName string, instanceName string, deviceName string, hostName string, netPrio uint32, dscp int) error {
+	return t.record(Drift{Project: projectName, Instance: instanceName, Device: deviceName}, trackerStep{
+		name:  "netprio",
+		clear: func() error { return t.Driver.InstanceClearNetPrio(projectName, instanceName, deviceName, hostName) },
+		setup: func() error {
+			return t.Driver.InstanceSetupNetPrio(projectName, instanceName, deviceName, hostName, netPrio, dscp)
+		},
+	})
+}
+
+// InstanceClearNetPrio removes the netprio rules of the instance device.
+func (t *tracker) InstanceClearNetPrio(projectName string, instanceName string, deviceName string, hostName string) error {
+	return t.forget(Drift{Project: projectName, Instance: instanceName, Device: deviceName}, "netprio", func() error {
+		return t.Driver.InstanceClearNetPrio(projectName, instanceName, deviceName, hostName)
+	})
+}
@@ -0,0 +1,157 @@
+package firewall
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/lxd/firewall/drivers"
+)
+
+// fakeDriver keeps the rules of each network in memory.
+type fakeDriver struct {
+	Driver
+
+	rules map[string][]string
+}
+
+func (d *fakeDriver) NetworkSetup(networkName string, _ net.IP, _ net.IP, _ drivers.Opts) error {
+	d.rules[networkName] = append(d.rules[networkName], "-A INPUT -i "+networkName+" -j ACCEPT")
+	return nil
+}
+
+func (d *fakeDriver) NetworkApplyForwards(networkName string, rules []drivers.AddressForward) error {
+	for _, rule := range rules {
+		if rule.ListenAddress == nil {
+			return errors.New("Invalid listen address")
+		}
+
+		d.rules[networkName] = append(d.rules[networkName], "-A PREROUTING -d "+rule.ListenAddress.String()+" -j DNAT")
+	}
+
+	return nil
+}
+
+func (d *fakeDriver) NetworkClear(networkName string, _ bool, _ []uint) error {
+	delete(d.rules, networkName)
+	return nil
+}
+
+func (d *fakeDriver) NetworkRules(networkName string) ([]string, error) {
+	return append([]string{}, d.rules[networkName]...), nil
+}
+
+func Test_trackerCheckAndReconcile(t *testing.T) {
+	driver := &fakeDriver{rules: map[string][]string{}}
+	fw := newTracker(driver)
+
+	require.NoError(t, fw.NetworkClear("lxdbr0", false, nil))
+	require.NoError(t, fw.NetworkSetup("lxdbr0", nil, nil, drivers.Opts{}))
+	require.NoError(t, fw.NetworkApplyForwards("lxdbr0", []drivers.AddressForward{{ListenAddress: net.ParseIP("192.0.2.1")}}))
+
+	drifts, err := fw.Check()
+	require.NoError(t, err)
+	assert.Empty(t, drifts)
+
+	// Remove a rule behind the tracker's back.
+	driver.rules["lxdbr0"] = driver.rules["lxdbr0"][:1]
+
+	drifts, err = fw.Check()
+	require.NoError(t, err)
+	require.Len(t, drifts, 1)
+	assert.Equal(t, "lxdbr0", drifts[0].Network)
+	assert.Equal(t, []string{"-A PREROUTING -d 192.0.2.1 -j DNAT"}, drifts[0].Missing)
+
+	// Reconciling replays the calls that applied the rules.
+	require.NoError(t, fw.Reconcile(drifts))
+	assert.Equal(t, []string{"-A INPUT -i lxdbr0 -j ACCEPT", "-A PREROUTING -d 192.0.2.1 -j DNAT"}, driver.rules["lxdbr0"])
+
+	drifts, err = fw.Check()
+	require.NoError(t, err)
+	assert.Empty(t, drifts)
+
+	// Cleared rules are not expected anymore.
+	require.NoError(t, fw.NetworkClear("lxdbr0", true, nil))
+
+	drifts, err = fw.Check()
+	require.NoError(t, err)
+	assert.Empty(t, drifts)
+	assert.Empty(t, fw.state.owners)
+}
+
+func Test_trackerFailedApply(t *testing.T) {
+	driver := &fakeDriver{rules: map[string][]string{}}
+	fw := newTracker(driver)
+
+	require.NoError(t, fw.NetworkSetup("lxdbr0", nil, nil, drivers.Opts{}))
+	require.NoError(t, fw.NetworkApplyForwards("lxdbr0", []drivers.AddressForward{{ListenAddress: net.ParseIP("192.0.2.1")}}))
+	require.Error(t, fw.NetworkApplyForwards("lxdbr0", []drivers.AddressForward{{}}))
+
+	// The forwards that the failed call was replacing aren't replayed anymore.
+	drifts, err := fw.Check()
+	require.NoError(t, err)
+	assert.Empty(t, drifts)
+
+	owner := fw.lockOwner(Drift{Network: "lxdbr0"}, false)
+	require.NotNil(t, owner)
+	assert.Len(t, owner.steps, 1)
+	owner.mu.Unlock()
+}
+
+func Test_trackerTrack(t *testing.T) {
+	driver := &fakeDriver{rules: map[string][]string{"lxdbr0": {"-A INPUT -i lxdbr0 -j ACCEPT"}}}
+	fw := newTracker(driver)
+
+	// Tracking records the rules without applying them again.
+	require.NoError(t, fw.Track(func(driver Driver) error {
+		return driver.NetworkSetup("lxdbr0", nil, nil, drivers.Opts{})
+	}))
+
+	assert.Equal(t, []string{"-A INPUT -i lxdbr0 -j ACCEPT"}, driver.rules["lxdbr0"])
+
+	delete(driver.rules, "lxdbr0")
+
+	drifts, err := fw.Check()
+	require.NoError(t, err)
+	require.Len(t, drifts, 1)
+
+	require.NoError(t, fw.Reconcile(drifts))
+	assert.Equal(t, []string{"-A INPUT -i lxdbr0 -j ACCEPT"}, driver.rules["lxdbr0"])
+}
+
+func Test_missingRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected []string
+		live     []string
+		missing  []string
+	}{
+		{
+			name:     "No drift",
+			expected: []string{"a", "b"},
+			live:     []string{"b", "a", "c"},
+			missing:  []string{},
+		},
+		{
+			name:     "Missing rules",
+			expected: []string{"a", "b", "c"},
+			live:     []string{"b"},
+			missing:  []string{"a", "c"},
+		},
+		{
+			name:     "Missing duplicate rule",
+			expected: []string{"a", "a"},
+			live:     []string{"a"},
+			missing:  []string{"a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.missing, missingRules(tt.expected, tt.live))
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/db/warningtype"
+	"github.com/canonical/lxd/lxd/firewall"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/lxd/warnings"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+func firewallReconcileTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		_, err := firewallReconcile(ctx, d.State())
+		if err != nil {
+			logger.Warn("Failed reconciling firewall rules", logger.Ctx{"err": err})
+		}
+	}
+
+	return f, task.Every(5 * time.Minute)
+}
+
+// firewallReconcile compares the firewall rules applied for the networks and instance devices with the live ones,
+// reapplies the rules that went missing and raises a warning describing the drift.
+// Returns the drift that was found.
+func firewallReconcile(ctx context.Context, s *state.State) ([]firewall.Drift, error) {
+	drifts, err := s.Firewall.Check()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(drifts) == 0 {
+		err = warnings.ResolveWarningsByLocalNodeAndType(s.DB.Cluster, warningtype.FirewallRulesDrift)
+		if err != nil {
+			logger.Warn("Failed to resolve warning", logger.Ctx{"err": err})
+		}
+
+		return drifts, nil
+	}
+
+	owners := make([]string, 0, len(drifts))
+	for _, drift := range drifts {
+		logger.Warn("Firewall rules missing", logger.Ctx{"owner": drift.String(), "rules": drift.Missing})
+		owners = append(owners, fmt.Sprintf("%s (%d rules)", drift.String(), len(drift.Missing)))
+	}
+
+	msg := fmt.Sprintf("Reapplied missing firewall rules of %s", strings.Join(owners, ", "))
+
+	reconcileErr := s.Firewall.Reconcile(drifts)
+	if reconcileErr != nil {
+		msg = fmt.Sprintf("Failed reapplying missing firewall rules of %s: %v", strings.Join(owners, ", "), reconcileErr)
+	}
+
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.UpsertWarningLocalNode(ctx, "", "", -1, warningtype.FirewallRulesDrift, msg)
+	})
+	if err != nil {
+		logger.Warn("Failed to create warning", logger.Ctx{"err": err})
+	}
+
+	if reconcileErr != nil {
+		return nil, reconcileErr
+	}
+
+	return drifts, nil
+}
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/kballard/go-shellquote"
 	"golang.org/x/sys/unix"
 
 	"github.com/canonical/lxd/lxd/backup"
@@ -47,6 +48,16 @@ var ErrExecCommandNotExecutable = api.StatusErrorf(http.StatusBadRequest, "Comma
 // ErrInstanceIsStopped indicates that the instance is stopped.
 var ErrInstanceIsStopped error = api.StatusErrorf(http.StatusBadRequest, "The instance is already stopped")
 
+// snapshotConsistencyFSFreeze is the snapshots.consistency mode which coordinates snapshots with the guest.
+// It's also recorded in snapshots taken while the instance filesystems were frozen.
+const snapshotConsistencyFSFreeze = "fsfreeze"
+
+// snapshotConsistencyHooks is recorded in snapshots for which only the pre-snapshot hook was run.
+const snapshotConsistencyHooks = "hooks"
+
+// snapshotConsistencyCrash is recorded in snapshots for which coordinating with the guest failed.
+const snapshotConsistencyCrash = "crash"
+
 // deviceManager is an interface that allows managing device lifecycle.
 type deviceManager interface {
 	deviceAdd(dev device.Device, instanceRunning bool) error
@@ -691,15 +702,26 @@ func (d *common) runHooks(hooks []func() error) error {
 }
 
 // snapshot handles the common part of the snapshoting process.
-func (d *common) snapshotCommon(inst instance.Instance, name string, expiry time.Time, stateful bool) error {
+// If set, consistency is recorded in the snapshot as the consistency reached by startSnapshotConsistency.
+func (d *common) snapshotCommon(inst instance.Instance, name string, expiry time.Time, stateful bool, consistency string) error {
 	revert := revert.New()
 	defer revert.Fail()
 
+	config := make(map[string]string, len(inst.LocalConfig())+1)
+	for k, v := range inst.LocalConfig() {
+		config[k] = v
+	}
+
+	delete(config, "volatile.snapshot.consistency")
+	if consistency != "" {
+		config["volatile.snapshot.consistency"] = consistency
+	}
+
 	// Setup the arguments.
 	args := db.InstanceArgs{
 		Project:      inst.Project().Name,
 		Architecture: inst.Architecture(),
-		Config:       inst.LocalConfig(),
+		Config:       config,
 		Type:         inst.Type(),
 		Snapshot:     true,
 		Devices:      inst.LocalDevices(),
@@ -758,6 +780,142 @@ func (d *common) snapshotCommon(inst instance.Instance, name string, expiry time
 	return nil
 }
 
+// snapshotConsistencyTimeout returns how long the snapshot consistency hooks may run and how long the instance
+// filesystems may stay frozen.
+func (d *common) snapshotConsistencyTimeout() time.Duration {
+	timeout := 30
+
+	value := d.expandedConfig["snapshots.consistency.timeout"]
+	if value != "" {
+		// Validated by instancetype.
+		timeout, _ = strconv.Atoi(value)
+	}
+
+	return time.Duration(timeout) * time.Second
+}
+
+// startSnapshotConsistency prepares a running instance for a snapshot when snapshots.consistency is set to
+// fsfreeze. It runs snapshots.consistency.pre_hook inside the instance and then freezes the instance filesystems
+// using freeze (if not nil), which must return a function to thaw them.
+// It returns the consistency reached, to be recorded in the snapshot, and a function which thaws the filesystems
+// and runs snapshots.consistency.post_hook, to be called once the snapshot has been taken.
+// Failures are logged and only result in a crash-consistent snapshot.
+func (d *common) startSnapshotConsistency(inst instance.Instance, freeze func(timeout time.Duration) (func() error, error)) (string, func()) {
+	if d.expandedConfig["snapshots.consistency"] != snapshotConsistencyFSFreeze {
+		return "", func() {}
+	}
+
+	timeout := d.snapshotConsistencyTimeout()
+	preHook := d.expandedConfig["snapshots.consistency.pre_hook"]
+	postHook := d.expandedConfig["snapshots.consistency.post_hook"]
+
+	var thaw func() error
+	finish := func() {
+		if thaw != nil {
+			err := thaw()
+			if err != nil {
+				d.logger.Error("Failed thawing instance filesystems", logger.Ctx{"err": err})
+			}
+		}
+
+		if postHook != "" {
+			err := d.runSnapshotHook(inst, postHook, timeout)
+			if err != nil {
+				d.logger.Warn("Failed running snapshot post hook", logger.Ctx{"err": err})
+			}
+		}
+	}
+
+	if preHook != "" {
+		err := d.runSnapshotHook(inst, preHook, timeout)
+		if err != nil {
+			d.logger.Warn("Failed running snapshot pre hook, snapshot will only be crash-consistent", logger.Ctx{"err": err})
+			return snapshotConsistencyCrash, finish
+		}
+	}
+
+	if freeze == nil {
+		if preHook == "" {
+			return snapshotConsistencyCrash, finish
+		}
+
+		return snapshotConsistencyHooks, finish
+	}
+
+	var err error
+	thaw, err = freeze(timeout)
+	if err != nil {
+		d.logger.Warn("Failed freezing instance filesystems, snapshot will only be crash-consistent", logger.Ctx{"err": err})
+		return snapshotConsistencyCrash, finish
+	}
+
+	return snapshotConsistencyFSFreeze, finish
+}
+
+// runSnapshotHook runs a snapshot consistency hook inside the instance and waits for it to exit.
+func (d *common) runSnapshotHook(inst instance.Instance, hook string, timeout time.Duration) error {
+	command, err := shellquote.Split(hook)
+	if err != nil {
+		return fmt.Errorf("Failed parsing hook %q: %w", hook, err)
+	}
+
+	if len(command) == 0 {
+		return fmt.Errorf("Empty hook")
+	}
+
+	// Record the output of the hook to include it in errors.
+	output, err := os.CreateTemp("", "lxd_snapshot_hook_")
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = output.Close()
+		_ = os.Remove(output.Name())
+	}()
+
+	req := api.InstanceExecPost{
+		Command: command,
+		Environment: map[string]string{
+			"PATH": "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin",
+			"HOME": "/root",
+		},
+	}
+
+	cmd, err := inst.Exec(req, nil, output, output)
+	if err != nil {
+		return fmt.Errorf("Failed running hook %q: %w", hook, err)
+	}
+
+	chErr := make(chan error, 1)
+	go func() {
+		exitStatus, err := cmd.Wait()
+		if err == nil && exitStatus != 0 {
+			err = fmt.Errorf("Exit status %d", exitStatus)
+		}
+
+		chErr <- err
+	}()
+
+	select {
+	case err = <-chErr:
+	case <-time.After(timeout):
+		_ = cmd.Signal(unix.SIGKILL)
+		return fmt.Errorf("Hook %q timed out after %s", hook, timeout)
+	}
+
+	if err != nil {
+		out, _ := os.ReadFile(output.Name())
+		if len(out) > 0 {
+			return fmt.Errorf("Hook %q failed: %w (%s)", hook, err, strings.TrimSpace(string(out)))
+		}
+
+		return fmt.Errorf("Hook %q failed: %w", hook, err)
+	}
+
+	return nil
+}
+
 // updateProgress updates the operation metadata with a new progress string.
 func (d *common) updateProgress(progress string) {
 	if d.op == nil {
@@ -823,6 +981,50 @@ func (d *common) isStartableStatusCode(statusCode api.StatusCode) error {
 	return nil
 }
 
+// networkShapingState adds the traffic shaping counters of the instance's shaped NICs to the network state.
+// The network state entries are matched to the NICs using the host side interface name.
+func (d *common) networkShapingState(networks map[string]api.InstanceStateNetwork) {
+	projectShaped := d.project.Config["limits.network.ingress"] != "" || d.project.Config["limits.network.egress"] != ""
+
+	shapedHostNames := []string{}
+	for devName, devConfig := range d.expandedDevices {
+		if devConfig["type"] != "nic" {
+			continue
+		}
+
+		if !projectShaped && devConfig["limits.ingress"] == "" && devConfig["limits.egress"] == "" && devConfig["limits.max"] == "" {
+			continue
+		}
+
+		hostName := devConfig["host_name"]
+		if hostName == "" {
+			hostName = d.localConfig[fmt.Sprintf("volatile.%s.host_name", devName)]
+		}
+
+		if hostName != "" {
+			shapedHostNames = append(shapedHostNames, hostName)
+		}
+	}
+
+	for netName, network := range networks {
+		if network.HostName == "" || !shared.ValueInSlice(network.HostName, shapedHostNames) {
+			continue
+		}
+
+		inbound, outbound, err := device.NetworkHostShapingCounters(d.project.Name, network.HostName)
+		if err != nil {
+			d.logger.Warn("Failed getting NIC traffic shaping counters", logger.Ctx{"host_name": network.HostName, "err": err})
+			continue
+		}
+
+		network.Counters.ShapingPacketsDroppedInbound = int64(inbound.Drops)
+		network.Counters.ShapingPacketsDroppedOutbound = int64(outbound.Drops)
+		network.Counters.ShapingOverlimitsInbound = int64(inbound.Overlimits)
+		network.Counters.ShapingOverlimitsOutbound = int64(outbound.Overlimits)
+		networks[netName] = network
+	}
+}
+
 // getStartupSnapNameAndExpiry returns the name and expiry for a snapshot to be taken at startup.
 func (d *common) getStartupSnapNameAndExpiry(inst instance.Instance) (string, *time.Time, error) {
 	schedule := strings.ToLower(d.expandedConfig["snapshots.schedule"])
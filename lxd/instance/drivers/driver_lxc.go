@@ -3374,6 +3374,7 @@ func (d *lxc) renderState(statusCode api.StatusCode, hostInterfaces []net.Interf
 		status.CPU = d.cpuState()
 		status.Memory = d.memoryState()
 		status.Network = d.networkState(hostInterfaces)
+		d.networkShapingState(status.Network)
 		status.Pid = int64(pid)
 		status.Processes = processesState
 	}
@@ -3465,10 +3466,18 @@ func (d *lxc) snapshot(name string, expiry time.Time, stateful bool) error {
 		}
 	}
 
+	// Run the snapshot hooks inside the container if requested.
+	var consistency string
+	if !stateful && d.IsRunning() {
+		var finishConsistency func()
+		consistency, finishConsistency = d.startSnapshotConsistency(d, nil)
+		defer finishConsistency()
+	}
+
 	// Wait for any file operations to complete to have a more consistent snapshot.
 	d.stopForkfile(false)
 
-	return d.snapshotCommon(d, name, expiry, stateful)
+	return d.snapshotCommon(d, name, expiry, stateful, consistency)
 }
 
 // Snapshot takes a new snapshot.
@@ -5242,10 +5251,15 @@ func (d *lxc) MigrateSend(args instance.MigrateSendArgs) error {
 		return fmt.Errorf("Failed loading instance: %w", err)
 	}
 
+	srcConfig, err := pool.GenerateInstanceBackupConfig(d, args.Snapshots, d.op)
+	if err != nil {
+		return fmt.Errorf("Failed generating instance migration config: %w", err)
+	}
+
 	// The refresh argument passed to MigrationTypes() is always set to false here.
 	// The migration source/sender doesn't need to care whether or not it's doing a refresh as the migration
 	// sink/receiver will know this, and adjust the migration types accordingly.
-	poolMigrationTypes := pool.MigrationTypes(storagePools.InstanceContentType(d), false, args.Snapshots)
+	poolMigrationTypes := storagePools.EncryptedMigrationTypes(pool.MigrationTypes(storagePools.InstanceContentType(d), false, args.Snapshots), srcConfig.Volume.Config)
 	if len(poolMigrationTypes) == 0 {
 		return fmt.Errorf("No source migration types available")
 	}
@@ -5293,11 +5307,6 @@ func (d *lxc) MigrateSend(args instance.MigrateSendArgs) error {
 		}
 	}
 
-	srcConfig, err := pool.GenerateInstanceBackupConfig(d, args.Snapshots, d.op)
-	if err != nil {
-		return fmt.Errorf("Failed generating instance migration config: %w", err)
-	}
-
 	// If we are copying snapshots, retrieve a list of snapshots from source volume.
 	if args.Snapshots {
 		offerHeader.SnapshotNames = make([]string, 0, len(srcConfig.Snapshots))
@@ -8500,6 +8509,7 @@ func (d *lxc) getFSStats() (*metrics.MetricSet, error) {
 		var statfs *unix.Statfs_t
 		labels := make(map[string]string)
 		realDev := ""
+		sharedPoolFS := false
 
 		if dev["pool"] != "" {
 			// Expected volume name.
@@ -8540,6 +8550,7 @@ func (d *lxc) getFSStats() (*metrics.MetricSet, error) {
 
 			if !isMounted {
 				realDev = dev["source"]
+				sharedPoolFS = true
 			}
 		} else {
 			source := shared.HostPath(dev["source"])
@@ -8598,16 +8609,69 @@ func (d *lxc) getFSStats() (*metrics.MetricSet, error) {
 			labels["fstype"] = fsType
 		}
 
-		// Add sample
 		statfsBsize := uint64(statfs.Bsize)
-		out.AddSamples(metrics.FilesystemSizeBytes, metrics.Sample{Value: float64(statfs.Blocks * statfsBsize), Labels: labels})
-		out.AddSamples(metrics.FilesystemAvailBytes, metrics.Sample{Value: float64(statfs.Bavail * statfsBsize), Labels: labels})
-		out.AddSamples(metrics.FilesystemFreeBytes, metrics.Sample{Value: float64(statfs.Bfree * statfsBsize), Labels: labels})
+		sizeBytes := statfs.Blocks * statfsBsize
+		availBytes := statfs.Bavail * statfsBsize
+		freeBytes := statfs.Bfree * statfsBsize
+
+		// Volumes that aren't mounted on their own (such as on dir pools) report the stats of the whole pool's
+		// filesystem, so use the volume's own usage and size from the storage pool when available.
+		if sharedPoolFS {
+			usage := d.poolVolumeUsage(dev)
+			if usage != nil && usage.Total > 0 && usage.Used >= 0 {
+				sizeBytes = uint64(usage.Total)
+				availBytes = 0
+				if usage.Total > usage.Used {
+					availBytes = uint64(usage.Total - usage.Used)
+				}
+
+				freeBytes = availBytes
+			}
+		}
+
+		// Add sample
+		out.AddSamples(metrics.FilesystemSizeBytes, metrics.Sample{Value: float64(sizeBytes), Labels: labels})
+		out.AddSamples(metrics.FilesystemAvailBytes, metrics.Sample{Value: float64(availBytes), Labels: labels})
+		out.AddSamples(metrics.FilesystemFreeBytes, metrics.Sample{Value: float64(freeBytes), Labels: labels})
 	}
 
 	return out, nil
 }
 
+// poolVolumeUsage returns the usage of the storage volume backing a pool disk device.
+// Returns nil if the usage cannot be retrieved.
+func (d *lxc) poolVolumeUsage(dev deviceConfig.Device) *storagePools.VolumeUsage {
+	// Reuse the instance's storage pool rather than loading it on every metrics request.
+	pool, err := d.getStoragePool()
+	if err != nil {
+		return nil
+	}
+
+	if pool.Name() != dev["pool"] {
+		pool, err = storagePools.LoadByName(d.state, dev["pool"])
+		if err != nil {
+			return nil
+		}
+	}
+
+	var usage *storagePools.VolumeUsage
+	if dev["source"] != "" {
+		usage, err = pool.GetCustomVolumeUsage(d.project.Name, dev["source"])
+	} else {
+		usage, err = pool.GetInstanceUsage(d)
+	}
+
+	if err != nil {
+		if !errors.Is(err, storageDrivers.ErrNotSupported) {
+			d.logger.Warn("Failed getting volume usage", logger.Ctx{"device": dev["path"], "err": err})
+		}
+
+		return nil
+	}
+
+	return usage
+}
+
 func (d *lxc) loadRawLXCConfig(cc *liblxc.Container) error {
 	// Load the LXC raw config.
 	lxcConfig, ok := d.expandedConfig["raw.lxc"]
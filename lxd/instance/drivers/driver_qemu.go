@@ -48,6 +48,7 @@ import (
 	"github.com/canonical/lxd/lxd/device/nictype"
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/drivers/edk2"
+	"github.com/canonical/lxd/lxd/instance/drivers/qga"
 	"github.com/canonical/lxd/lxd/instance/drivers/qmp"
 	"github.com/canonical/lxd/lxd/instance/drivers/uefi"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
@@ -108,6 +109,13 @@ const qemuDeviceNameMaxLength = 31
 // qemuMigrationNBDExportName is the name of the disk device export by the migration NBD server.
 const qemuMigrationNBDExportName = "lxd_root"
 
+// qemuMemoryHotplugSlots is the number of memory slots available for hot-plugged memory.
+const qemuMemoryHotplugSlots = 16
+
+// qemuMemoryHotplugPrefix is used as part of the name given to the QEMU memory backends and devices of
+// hot-plugged memory.
+const qemuMemoryHotplugPrefix = "memhp"
+
 // qemuSparseUSBPorts is the amount of sparse USB ports for VMs.
 // 4 are reserved, and the other 4 can be used for any USB device.
 const qemuSparseUSBPorts = 8
@@ -336,6 +344,9 @@ type qemu struct {
 
 	// Stateful migration streams.
 	migrationReceiveStateful map[string]io.ReadWriteCloser
+
+	// Disks mirrored over NBD during stateful migration.
+	migrationReceiveNBDDisks []qemuMigrationNBDDisk
 }
 
 // getAgentClient returns the current agent client handle.
@@ -619,6 +630,10 @@ func (d *qemu) onStop(target string) error {
 	_ = os.Remove(d.pidFilePath())
 	_ = os.Remove(d.monitorPath())
 
+	qemuGuestAgentUnavailableMu.Lock()
+	delete(qemuGuestAgentUnavailable, d.guestAgentPath())
+	qemuGuestAgentUnavailableMu.Unlock()
+
 	// Stop the storage for the instance.
 	err = d.unmount()
 	if err != nil && !errors.Is(err, storageDrivers.ErrInUse) {
@@ -826,7 +841,12 @@ func (d *qemu) restoreState(monitor *qmp.Monitor) error {
 		// Perform non-shared storage transfer if requested.
 		filesystemConn := d.migrationReceiveStateful[api.SecretNameFilesystem]
 		if filesystemConn != nil {
-			nbdConn, err := monitor.NBDServerStart()
+			nbdDisks := d.migrationReceiveNBDDisks
+			if len(nbdDisks) == 0 {
+				return fmt.Errorf("Migration filesystem connection provided without disks to transfer")
+			}
+
+			nbdConns, err := monitor.NBDServerStart(len(nbdDisks))
 			if err != nil {
 				return fmt.Errorf("Failed starting NBD server: %w", err)
 			}
@@ -834,25 +854,53 @@ func (d *qemu) restoreState(monitor *qmp.Monitor) error {
 			d.logger.Debug("Migration NBD server started")
 
 			defer func() {
-				_ = nbdConn.Close()
+				for _, nbdConn := range nbdConns {
+					_ = nbdConn.Close()
+				}
+
 				_ = monitor.NBDServerStop()
 			}()
 
-			err = monitor.NBDBlockExportAdd(qemuMigrationNBDExportName)
-			if err != nil {
-				return fmt.Errorf("Failed adding root disk to NBD server: %w", err)
+			for _, nbdDisk := range nbdDisks {
+				err = monitor.NBDBlockExportAdd(nbdDisk.nodeName)
+				if err != nil {
+					return fmt.Errorf("Failed adding disk %q to NBD server: %w", nbdDisk.devName, err)
+				}
 			}
 
-			go func() {
-				d.logger.Debug("Migration storage NBD export starting")
+			// Each disk uses its own NBD connection, so they need to be multiplexed over the filesystem
+			// connection when custom volumes are transferred alongside the root disk.
+			sourceConns := []io.ReadWriteCloser{filesystemConn}
+			if qemuMigrationNBDMultiplexed(nbdDisks) {
+				mux, err := migration.NewMultiplexer(filesystemConn, len(nbdDisks))
+				if err != nil {
+					return err
+				}
 
-				go func() { _, _ = io.Copy(filesystemConn, nbdConn) }()
+				sourceConns = make([]io.ReadWriteCloser, 0, len(nbdDisks))
+				for i := range nbdDisks {
+					sourceConns = append(sourceConns, mux.Stream(i))
+				}
+			}
 
-				_, _ = io.Copy(nbdConn, filesystemConn)
-				_ = nbdConn.Close()
+			for i, nbdDisk := range nbdDisks {
+				nbdConn := nbdConns[i]
+				sourceConn := sourceConns[i]
 
-				d.logger.Debug("Migration storage NBD export finished")
-			}()
+				// Track the amount of data mirrored into the disk to report the progress on the operation.
+				sourceReader := migration.ProgressReader(d.op, "block_progress", fmt.Sprintf("Mirroring disk %q", nbdDisk.devName))(sourceConn)
+
+				go func() {
+					d.logger.Debug("Migration storage NBD export starting", logger.Ctx{"disk": nbdDisk.devName})
+
+					go func() { _, _ = io.Copy(sourceConn, nbdConn) }()
+
+					_, _ = io.Copy(nbdConn, sourceReader)
+					_ = nbdConn.Close()
+
+					d.logger.Debug("Migration storage NBD export finished", logger.Ctx{"disk": nbdDisk.devName})
+				}()
+			}
 
 			defer func() { _ = filesystemConn.Close() }()
 		}
@@ -927,8 +975,19 @@ func (d *qemu) restoreState(monitor *qmp.Monitor) error {
 // Once started, the VM is in a paused state and it's up to the caller to wait for the transfer to complete and
 // resume or kill the VM guest.
 func (d *qemu) saveStateHandle(monitor *qmp.Monitor, f *os.File) error {
+	// The state of a VM with hot-plugged memory can't be restored as the VM is then started with all of its
+	// memory as boot time memory.
+	pluggedSizeBytes, err := monitor.GetPluggedMemorySizeBytes()
+	if err != nil {
+		return err
+	}
+
+	if pluggedSizeBytes > 0 {
+		return fmt.Errorf("Cannot save the state of a VM with hot-plugged memory, restart it first")
+	}
+
 	// Send the target file to qemu.
-	err := monitor.SendFile("migration", f)
+	err = monitor.SendFile("migration", f)
 	if err != nil {
 		return err
 	}
@@ -1447,6 +1506,7 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 		"-sandbox", "on,obsolete=deny,elevateprivileges=allow,spawn=allow,resourcecontrol=deny",
 		"-readconfig", confFile,
 		"-spice", d.spiceCmdlineConfig(),
+		"-vnc", d.vncCmdlineConfig(),
 		"-pidfile", d.pidFilePath(),
 		"-D", d.LogFilePath(),
 	}
@@ -2620,6 +2680,14 @@ func (d *qemu) spiceCmdlineConfig() string {
 	return fmt.Sprintf("unix=on,disable-ticketing=on,addr=%s", d.spicePath())
 }
 
+func (d *qemu) vncPath() string {
+	return filepath.Join(d.LogPath(), "qemu.vnc")
+}
+
+func (d *qemu) vncCmdlineConfig() string {
+	return fmt.Sprintf("unix:%s", d.vncPath())
+}
+
 // generateConfigShare generates the config share directory that will be exported to the VM via
 // a 9P share. Due to the unknown size of templates inside the images this directory is created
 // inside the VM's config volume so that it can be restricted by quota.
@@ -3281,6 +3349,10 @@ func (d *qemu) generateQemuConfigFile(cpuInfo *cpuTopology, mountInfo *storagePo
 
 	cfg = append(cfg, qemuSerial(&serialOpts)...)
 
+	if d.guestAgentEnabled() {
+		cfg = append(cfg, qemuGuestAgent(&qemuGuestAgentOpts{d.guestAgentPath()})...)
+	}
+
 	// s390x doesn't really have USB.
 	if d.architecture != osarch.ARCH_64BIT_S390_BIG_ENDIAN {
 		devBus, devAddr, multi = bus.allocate(busFunctionGroupGeneric)
@@ -3668,8 +3740,29 @@ func (d *qemu) addCPUMemoryConfig(cfg *[]cfgSection, cpuInfo *cpuTopology) error
 	nodeMemory := int64(memSizeMB / int64(len(hostNodes)))
 	cpuOpts.memory = nodeMemory
 
+	memOpts := qemuMemoryOpts{memSizeMB: memSizeMB}
+
+	// Configure the memory hotplug limit.
+	if d.expandedConfig["limits.memory.hotplug"] != "" {
+		if d.architecture != osarch.ARCH_64BIT_INTEL_X86 {
+			return fmt.Errorf("Memory hotplug is only supported on x86_64")
+		}
+
+		maxMemSizeBytes, err := units.ParseByteSizeString(d.expandedConfig["limits.memory.hotplug"])
+		if err != nil {
+			return fmt.Errorf("limits.memory.hotplug invalid: %w", err)
+		}
+
+		if maxMemSizeBytes < memSizeBytes {
+			return fmt.Errorf("limits.memory.hotplug must be greater than or equal to limits.memory")
+		}
+
+		memOpts.maxMemSizeMB = maxMemSizeBytes / 1024 / 1024
+		memOpts.memSlots = qemuMemoryHotplugSlots
+	}
+
 	if cfg != nil {
-		*cfg = append(*cfg, qemuMemory(&qemuMemoryOpts{memSizeMB})...)
+		*cfg = append(*cfg, qemuMemory(&memOpts)...)
 		*cfg = append(*cfg, qemuCPU(&cpuOpts, cpuPinning)...)
 	}
 
@@ -4155,7 +4248,7 @@ func (d *qemu) addDriveConfig(qemuDev map[string]string, bootIndexes map[string]
 		}
 
 		if driveConf.Limits != nil {
-			err = m.SetBlockThrottle(qemuDev["id"], int(driveConf.Limits.ReadBytes), int(driveConf.Limits.WriteBytes), int(driveConf.Limits.ReadIOps), int(driveConf.Limits.WriteIOps))
+			err = m.SetBlockThrottle(qemuDev["id"], int(driveConf.Limits.ReadBytes), int(driveConf.Limits.WriteBytes), int(driveConf.Limits.ReadIOps), int(driveConf.Limits.WriteIOps), driveConf.Limits.Group)
 			if err != nil {
 				return fmt.Errorf("Failed applying limits for disk device %q: %w", driveConf.DevName, err)
 			}
@@ -4964,10 +5057,62 @@ func (d *qemu) IsPrivileged() bool {
 	return false
 }
 
+// freezeFilesystems freezes the guest filesystems through the lxd-agent, or through the QEMU guest agent if the
+// lxd-agent isn't available. The lxd-agent thaws the filesystems by itself once the timeout expires.
+// On success, it returns a function that thaws them.
+func (d *qemu) freezeFilesystems(timeout time.Duration) (func() error, error) {
+	client, err := d.getAgentClient()
+	if err != nil {
+		// Fallback to the QEMU guest agent if enabled.
+		if errors.Is(err, errQemuAgentOffline) && d.guestAgentEnabled() {
+			return d.guestAgentFreeze()
+		}
+
+		return nil, err
+	}
+
+	agent, err := lxd.ConnectLXDHTTP(nil, client)
+	if err != nil {
+		d.logger.Error("Failed to connect to lxd-agent", logger.Ctx{"err": err})
+		return nil, fmt.Errorf("Failed to connect to lxd-agent")
+	}
+
+	req := agentAPI.FSFreezePut{
+		Frozen:  true,
+		Timeout: int(timeout / time.Second),
+	}
+
+	_, _, err = agent.RawQuery("PUT", "/1.0/fsfreeze", req, "")
+	if err != nil {
+		agent.Disconnect()
+
+		// Older lxd-agent versions don't support freezing filesystems.
+		if api.StatusErrorCheck(err, http.StatusNotFound) && d.guestAgentEnabled() {
+			return d.guestAgentFreeze()
+		}
+
+		return nil, fmt.Errorf("Failed freezing guest filesystems: %w", err)
+	}
+
+	thaw := func() error {
+		defer agent.Disconnect()
+
+		_, _, err := agent.RawQuery("PUT", "/1.0/fsfreeze", agentAPI.FSFreezePut{Frozen: false}, "")
+		if err != nil {
+			return fmt.Errorf("Failed thawing guest filesystems: %w", err)
+		}
+
+		return nil
+	}
+
+	return thaw, nil
+}
+
 // snapshot creates a snapshot of the instance.
 func (d *qemu) snapshot(name string, expiry time.Time, stateful bool) error {
 	var err error
 	var monitor *qmp.Monitor
+	var consistency string
 
 	// Deal with state.
 	if stateful {
@@ -4992,10 +5137,15 @@ func (d *qemu) snapshot(name string, expiry time.Time, stateful bool) error {
 		if err != nil {
 			return err
 		}
+	} else if d.IsRunning() {
+		// Run the snapshot hooks and freeze the guest filesystems if requested.
+		var finishConsistency func()
+		consistency, finishConsistency = d.startSnapshotConsistency(d, d.freezeFilesystems)
+		defer finishConsistency()
 	}
 
 	// Create the snapshot.
-	err = d.snapshotCommon(d, name, expiry, stateful)
+	err = d.snapshotCommon(d, name, expiry, stateful, consistency)
 	if err != nil {
 		return err
 	}
@@ -5849,15 +5999,12 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 }
 
 // updateMemoryLimit live updates the VM's memory limit by reszing the balloon device.
+// If memory hotplug is enabled, memory is hot-plugged when the new limit exceeds the current memory size.
 func (d *qemu) updateMemoryLimit(newLimit string) error {
 	if newLimit == "" {
 		return nil
 	}
 
-	if shared.IsTrue(d.expandedConfig["limits.memory.hugepages"]) {
-		return fmt.Errorf("Cannot live update memory limit when using huge pages")
-	}
-
 	// Check new size string is valid and convert to bytes.
 	newSizeBytes, err := units.ParseByteSizeString(newLimit)
 	if err != nil {
@@ -5877,19 +6024,58 @@ func (d *qemu) updateMemoryLimit(newLimit string) error {
 		return err
 	}
 
-	baseSizeMB := baseSizeBytes / 1024 / 1024
-
-	curSizeBytes, err := monitor.GetMemoryBalloonSizeBytes()
+	pluggedSizeBytes, err := monitor.GetPluggedMemorySizeBytes()
 	if err != nil {
 		return err
 	}
 
-	curSizeMB := curSizeBytes / 1024 / 1024
+	baseSizeMB := baseSizeBytes / 1024 / 1024
+	totalSizeMB := (baseSizeBytes + pluggedSizeBytes) / 1024 / 1024
+
+	var curSizeMB int64
+	if shared.IsTrue(d.expandedConfig["limits.memory.hugepages"]) {
+		// The balloon can't be used with huge pages, so the whole memory is always available.
+		curSizeMB = totalSizeMB
+	} else {
+		curSizeBytes, err := monitor.GetMemoryBalloonSizeBytes()
+		if err != nil {
+			return err
+		}
+
+		curSizeMB = curSizeBytes / 1024 / 1024
+	}
 
 	if curSizeMB == newSizeMB {
 		return nil
-	} else if baseSizeMB < newSizeMB {
-		return fmt.Errorf("Cannot increase memory size beyond boot time size when VM is running (Boot time size %dMiB, new size %dMiB)", baseSizeMB, newSizeMB)
+	}
+
+	if totalSizeMB < newSizeMB {
+		if d.expandedConfig["limits.memory.hotplug"] == "" {
+			return fmt.Errorf("Cannot increase memory size beyond boot time size when VM is running (Boot time size %dMiB, new size %dMiB)", baseSizeMB, newSizeMB)
+		}
+
+		maxSizeBytes, err := units.ParseByteSizeString(d.expandedConfig["limits.memory.hotplug"])
+		if err != nil {
+			return fmt.Errorf("Invalid memory hotplug limit: %w", err)
+		}
+
+		if maxSizeBytes < newSizeBytes {
+			return fmt.Errorf("Cannot increase memory size beyond limits.memory.hotplug when VM is running (Hotplug limit %dMiB, new size %dMiB)", maxSizeBytes/1024/1024, newSizeMB)
+		}
+
+		err = d.hotplugMemory(monitor, newSizeMB-totalSizeMB)
+		if err != nil {
+			return err
+		}
+
+		// Nothing more to do if the balloon wasn't inflated, the hot-plugged memory is already available.
+		if curSizeMB == totalSizeMB {
+			return nil
+		}
+	}
+
+	if shared.IsTrue(d.expandedConfig["limits.memory.hugepages"]) {
+		return fmt.Errorf("Cannot live update memory limit when using huge pages")
 	}
 
 	// Set effective memory size.
@@ -5901,7 +6087,7 @@ func (d *qemu) updateMemoryLimit(newLimit string) error {
 	// Changing the memory balloon can take time, so poll the effectice size to check it has shrunk within 1%
 	// of the target size, which we then take as success (it may still continue to shrink closer to target).
 	for i := 0; i < 10; i++ {
-		curSizeBytes, err = monitor.GetMemoryBalloonSizeBytes()
+		curSizeBytes, err := monitor.GetMemoryBalloonSizeBytes()
 		if err != nil {
 			return err
 		}
@@ -5925,6 +6111,159 @@ func (d *qemu) updateMemoryLimit(newLimit string) error {
 	return fmt.Errorf("Failed setting memory to %dMiB (currently %dMiB) as it was taking too long", newSizeMB, curSizeMB)
 }
 
+// hotplugMemory hot-plugs the given amount of memory into the VM.
+// The memory is spread evenly across the guest NUMA nodes and is placed on the same host NUMA nodes as the
+// memory of the guest NUMA node it is added to, or on the NUMA nodes in limits.cpu.nodes if set.
+func (d *qemu) hotplugMemory(monitor *qmp.Monitor, sizeMB int64) error {
+	memdevs, err := monitor.QueryMemdev()
+	if err != nil {
+		return err
+	}
+
+	// Find the memory backends of the guest NUMA nodes and the hot-plugged memory.
+	nodeMemdevs := map[int]qmp.Memdev{}
+	nextIndex := 0
+	usedSlots := 0
+	for _, memdev := range memdevs {
+		hotplugIndex, found := strings.CutPrefix(memdev.ID, qemuMemoryHotplugPrefix)
+		if found {
+			index, err := strconv.Atoi(hotplugIndex)
+			if err == nil && index >= nextIndex {
+				nextIndex = index + 1
+			}
+
+			usedSlots++
+			continue
+		}
+
+		nodeIndex, found := strings.CutPrefix(memdev.ID, "mem")
+		if found {
+			node, err := strconv.Atoi(nodeIndex)
+			if err == nil {
+				nodeMemdevs[node] = memdev
+			}
+		}
+	}
+
+	if len(nodeMemdevs) == 0 {
+		return fmt.Errorf("Failed finding the guest NUMA nodes")
+	}
+
+	if usedSlots+len(nodeMemdevs) > qemuMemoryHotplugSlots {
+		return fmt.Errorf("Not enough memory hotplug slots left (%d of %d used)", usedSlots, qemuMemoryHotplugSlots)
+	}
+
+	nodes := make([]int, 0, len(nodeMemdevs))
+	for node := range nodeMemdevs {
+		nodes = append(nodes, node)
+	}
+
+	sort.Ints(nodes)
+
+	// Get the host NUMA nodes to place the memory on if the guest memory isn't already bound to host NUMA nodes.
+	var cpuHostNodes []int
+	if d.expandedConfig["limits.cpu.nodes"] != "" {
+		numaNodeSet, err := resources.ParseNumaNodeSet(d.expandedConfig["limits.cpu.nodes"])
+		if err != nil {
+			return err
+		}
+
+		for _, numaNode := range numaNodeSet {
+			cpuHostNodes = append(cpuHostNodes, int(numaNode))
+		}
+	}
+
+	// The memory added to each guest NUMA node must be a multiple of the page size of its backend.
+	var hugepages string
+	pageSize := int64(os.Getpagesize())
+	if shared.IsTrue(d.expandedConfig["limits.memory.hugepages"]) {
+		hugepages, err = util.HugepagesPath()
+		if err != nil {
+			return err
+		}
+
+		var stat unix.Statfs_t
+		err = unix.Statfs(hugepages, &stat)
+		if err != nil {
+			return fmt.Errorf("Failed getting the huge page size: %w", err)
+		}
+
+		pageSize = stat.Bsize
+	}
+
+	sizeBytes := sizeMB * 1024 * 1024
+	if sizeBytes%pageSize != 0 {
+		return fmt.Errorf("Cannot hotplug %dMiB of memory as it isn't a multiple of the page size (%s)", sizeMB, units.GetByteSizeStringIEC(pageSize, 0))
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	pages := sizeBytes / pageSize
+	nodePages := pages / int64(len(nodes))
+	for i, node := range nodes {
+		memPages := nodePages
+		if i == 0 {
+			memPages += pages % int64(len(nodes))
+		}
+
+		if memPages == 0 {
+			continue
+		}
+
+		backendID := fmt.Sprintf("%s%d", qemuMemoryHotplugPrefix, nextIndex)
+		deviceID := fmt.Sprintf("%s%s", qemuDeviceIDPrefix, backendID)
+		nextIndex++
+
+		backend := map[string]any{
+			"id":   backendID,
+			"size": memPages * pageSize,
+		}
+
+		if hugepages != "" {
+			backend["qom-type"] = "memory-backend-file"
+			backend["mem-path"] = hugepages
+			backend["prealloc"] = true
+			backend["discard-data"] = true
+			backend["share"] = true
+		} else {
+			backend["qom-type"] = "memory-backend-memfd"
+			backend["share"] = true
+		}
+
+		nodeMemdev := nodeMemdevs[node]
+		if nodeMemdev.Policy != "" && nodeMemdev.Policy != "default" {
+			backend["policy"] = nodeMemdev.Policy
+			backend["host-nodes"] = nodeMemdev.HostNodes
+		} else if len(cpuHostNodes) > 0 {
+			backend["policy"] = "bind"
+			backend["host-nodes"] = cpuHostNodes
+		}
+
+		err = monitor.AddObject(backend)
+		if err != nil {
+			return err
+		}
+
+		revert.Add(func() { _ = monitor.RemoveObject(backendID) })
+
+		err = monitor.AddDevice(map[string]string{
+			"driver": "pc-dimm",
+			"id":     deviceID,
+			"memdev": backendID,
+			"node":   fmt.Sprintf("%d", node),
+		})
+		if err != nil {
+			return fmt.Errorf("Failed adding memory device: %w", err)
+		}
+
+		revert.Add(func() { _ = monitor.RemoveDevice(deviceID) })
+	}
+
+	revert.Success()
+	return nil
+}
+
 func (d *qemu) cleanup() {
 	// Unmount any leftovers
 	_ = d.removeUnixDevices()
@@ -6438,11 +6777,37 @@ func (d *qemu) MigrateSend(args instance.MigrateSendArgs) error {
 		return fmt.Errorf("Failed loading instance: %w", err)
 	}
 
+	// Custom volumes from local storage pools are mirrored to the target during live migration, which
+	// requires them to be moved to another cluster member alongside the instance.
+	var localVolumeDisks []qemuMigrationNBDDisk
+	if args.Live {
+		localVolumeDisks, err = d.migrationNBDDisks(false)
+		if err != nil {
+			return err
+		}
+
+		if len(localVolumeDisks) > 0 {
+			if args.ClusterMoveSourceName == "" {
+				return fmt.Errorf("Live migration of instances using custom volumes from local storage pools is only supported between cluster members")
+			}
+
+			err = d.migrateCheckLocalVolumes(localVolumeDisks)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	srcConfig, err := pool.GenerateInstanceBackupConfig(d, args.Snapshots, d.op)
+	if err != nil {
+		return fmt.Errorf("Failed generating instance migration config: %w", err)
+	}
+
 	// The refresh argument passed to MigrationTypes() is always set
 	// to false here. The migration source/sender doesn't need to care whether
 	// or not it's doing a refresh as the migration sink/receiver will know
 	// this, and adjust the migration types accordingly.
-	poolMigrationTypes := pool.MigrationTypes(storagePools.InstanceContentType(d), false, args.Snapshots)
+	poolMigrationTypes := storagePools.EncryptedMigrationTypes(pool.MigrationTypes(storagePools.InstanceContentType(d), false, args.Snapshots), srcConfig.Volume.Config)
 	if len(poolMigrationTypes) == 0 {
 		return fmt.Errorf("No source migration types available")
 	}
@@ -6464,11 +6829,6 @@ func (d *qemu) MigrateSend(args instance.MigrateSendArgs) error {
 	d.logger.Debug("Set migration offer volume size", logger.Ctx{"blockSize": blockSize})
 	offerHeader.VolumeSize = &blockSize
 
-	srcConfig, err := pool.GenerateInstanceBackupConfig(d, args.Snapshots, d.op)
-	if err != nil {
-		return fmt.Errorf("Failed generating instance migration config: %w", err)
-	}
-
 	// If we are copying snapshots, retrieve a list of snapshots from source volume.
 	if args.Snapshots {
 		offerHeader.SnapshotNames = make([]string, 0, len(srcConfig.Snapshots))
@@ -6617,6 +6977,11 @@ func (d *qemu) MigrateSend(args instance.MigrateSendArgs) error {
 				return err
 			}
 		} else {
+			// Custom volumes from local storage pools can only be transferred during live state transfer.
+			if len(localVolumeDisks) > 0 {
+				return fmt.Errorf("Target doesn't support live state transfer required to move custom volumes from local storage pools")
+			}
+
 			// Perform stateful stop if live state transfer is not supported by target.
 			if args.Live {
 				err = d.Stop(true)
@@ -6645,6 +7010,117 @@ func (d *qemu) MigrateSend(args instance.MigrateSendArgs) error {
 	}
 }
 
+// qemuMigrationNBDDisk is a disk mirrored over NBD during stateful migration.
+type qemuMigrationNBDDisk struct {
+	devName  string // Name of the disk device.
+	nodeName string // Name of the block node exported by the migration NBD server.
+}
+
+// qemuMigrationNBDMultiplexed returns whether the NBD connections of the disks are multiplexed over the migration
+// filesystem connection. This is the case when custom volumes are mirrored, as otherwise only the root disk uses it.
+func qemuMigrationNBDMultiplexed(nbdDisks []qemuMigrationNBDDisk) bool {
+	for _, nbdDisk := range nbdDisks {
+		if nbdDisk.nodeName != qemuMigrationNBDExportName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// migrationNBDDisks returns the disks mirrored over NBD during stateful migration in a stable order.
+// These are the root disk (if includeRoot is true) and the custom block volumes from local storage pools.
+func (d *qemu) migrationNBDDisks(includeRoot bool) ([]qemuMigrationNBDDisk, error) {
+	var nbdDisks []qemuMigrationNBDDisk
+
+	diskPools := make(map[string]storagePools.Pool)
+	for _, dev := range d.expandedDevices.Sorted() {
+		if dev.Config["type"] != "disk" {
+			continue
+		}
+
+		if dev.Config["path"] == "/" {
+			if includeRoot {
+				nbdDisks = append(nbdDisks, qemuMigrationNBDDisk{devName: dev.Name, nodeName: qemuMigrationNBDExportName})
+			}
+
+			continue
+		}
+
+		poolName := dev.Config["pool"]
+		if poolName == "" {
+			continue
+		}
+
+		diskPool, ok := diskPools[poolName]
+		if !ok {
+			var err error
+
+			diskPool, err = storagePools.LoadByName(d.state, poolName)
+			if err != nil {
+				return nil, fmt.Errorf("Failed loading storage pool: %w", err)
+			}
+
+			diskPools[poolName] = diskPool
+		}
+
+		// Custom volumes from shared storage pools are accessed directly from the target.
+		if diskPool.Driver().Info().Remote {
+			continue
+		}
+
+		nbdDisks = append(nbdDisks, qemuMigrationNBDDisk{
+			devName:  dev.Name,
+			nodeName: qemuDeviceNameOrID(qemuDeviceNamePrefix, dev.Name, "", qemuDeviceNameMaxLength),
+		})
+	}
+
+	return nbdDisks, nil
+}
+
+// migrateCheckLocalVolumes checks that the custom volumes from local storage pools can be moved to another
+// cluster member alongside the instance during stateful migration.
+func (d *qemu) migrateCheckLocalVolumes(nbdDisks []qemuMigrationNBDDisk) error {
+	// Derive the effective storage project name from the instance config's project.
+	storageProjectName, err := project.StorageVolumeProject(d.state.DB.Cluster, d.project.Name, dbCluster.StoragePoolVolumeTypeCustom)
+	if err != nil {
+		return err
+	}
+
+	for _, nbdDisk := range nbdDisks {
+		devConfig := d.expandedDevices[nbdDisk.devName]
+
+		diskPool, err := storagePools.LoadByName(d.state, devConfig["pool"])
+		if err != nil {
+			return fmt.Errorf("Failed loading storage pool: %w", err)
+		}
+
+		dbVolume, err := storagePools.VolumeDBGet(diskPool, storageProjectName, devConfig["source"], storageDrivers.VolumeTypeCustom)
+		if err != nil {
+			return err
+		}
+
+		if dbVolume.ContentType != dbCluster.StoragePoolVolumeContentTypeNameBlock {
+			return fmt.Errorf("Only custom block volumes from local storage pools can be live migrated (device %q)", nbdDisk.devName)
+		}
+
+		if shared.IsTrue(dbVolume.Config["security.shared"]) {
+			return fmt.Errorf("Custom volume %q of device %q is shared and cannot be live migrated", devConfig["source"], nbdDisk.devName)
+		}
+
+		snapshots, err := storagePools.VolumeDBSnapshotsGet(diskPool, storageProjectName, devConfig["source"], storageDrivers.VolumeTypeCustom)
+		if err != nil {
+			return err
+		}
+
+		if len(snapshots) > 0 {
+			return fmt.Errorf("Custom volume %q of device %q has snapshots and cannot be live migrated", devConfig["source"], nbdDisk.devName)
+		}
+	}
+
+	return nil
+}
+
 // migrateSendLive performs live migration send process.
 func (d *qemu) migrateSendLive(pool storagePools.Pool, clusterMoveSourceName string, rootDiskSize int64, filesystemConn io.ReadWriteCloser, stateConn io.ReadWriteCloser, volSourceArgs *migration.VolumeSourceArgs) error {
 	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
@@ -6660,30 +7136,38 @@ func (d *qemu) migrateSendLive(pool storagePools.Pool, clusterMoveSourceName str
 	// shared storage and avoid needing to sync the root disk.
 	sharedStorage := clusterMoveSourceName != "" && pool.Driver().Info().Remote
 
-	revert := revert.New()
+	// Get the disks to mirror to the target, which include the root disk unless it is on shared storage.
+	nbdDisks, err := d.migrationNBDDisks(!sharedStorage)
+	if err != nil {
+		return err
+	}
 
-	// Non-shared storage snapshot setup.
-	if !sharedStorage {
-		// Setup migration capabilities.
-		capabilities := map[string]bool{
-			// Automatically throttle down the guest to speed up convergence of RAM migration.
-			"auto-converge": true,
+	// Setup migration capabilities.
+	capabilities := map[string]bool{
+		// Automatically throttle down the guest to speed up convergence of RAM migration.
+		"auto-converge": true,
+	}
 
-			// Allow the migration to be paused after the source qemu releases the block devices but
-			// before the serialisation of the device state, to avoid a race condition between
-			// migration and blockdev-mirror. This requires that the migration be continued after it
-			// has reached the "pre-switchover" status.
-			"pause-before-switchover": true,
+	if len(nbdDisks) > 0 {
+		// Allow the migration to be paused after the source qemu releases the block devices but
+		// before the serialisation of the device state, to avoid a race condition between
+		// migration and blockdev-mirror. This requires that the migration be continued after it
+		// has reached the "pre-switchover" status.
+		capabilities["pause-before-switchover"] = true
 
-			// During storage migration encode blocks of zeroes efficiently.
-			"zero-blocks": true,
-		}
+		// During storage migration encode blocks of zeroes efficiently.
+		capabilities["zero-blocks"] = true
+	}
 
-		err = monitor.MigrateSetCapabilities(capabilities)
-		if err != nil {
-			return fmt.Errorf("Failed setting migration capabilities: %w", err)
-		}
+	err = monitor.MigrateSetCapabilities(capabilities)
+	if err != nil {
+		return fmt.Errorf("Failed setting migration capabilities: %w", err)
+	}
+
+	revert := revert.New()
 
+	// Non-shared storage snapshot setup.
+	if !sharedStorage {
 		// Create snapshot of the root disk.
 		// We use the VM's config volume for this so that the maximum size of the snapshot can be limited
 		// by setting the root disk's `size.state` property.
@@ -6766,22 +7250,11 @@ func (d *qemu) migrateSendLive(pool storagePools.Pool, clusterMoveSourceName str
 			}
 		})
 
-		defer revert.Fail() // Run the revert fail before the earlier defers.
-
 		d.logger.Debug("Setup temporary migration storage snapshot")
-	} else {
-		// Still set some options for shared storage.
-		capabilities := map[string]bool{
-			// Automatically throttle down the guest to speed up convergence of RAM migration.
-			"auto-converge": true,
-		}
-
-		err = monitor.MigrateSetCapabilities(capabilities)
-		if err != nil {
-			return fmt.Errorf("Failed setting migration capabilities: %w", err)
-		}
 	}
 
+	defer revert.Fail() // Run the revert fail before the earlier defers.
+
 	// Perform storage transfer while instance is still running.
 	// For shared storage the storage driver will likely not do much here, but we still call it anyway for the
 	// sense checks it performs.
@@ -6823,6 +7296,11 @@ func (d *qemu) migrateSendLive(pool storagePools.Pool, clusterMoveSourceName str
 			diskPools[poolName] = diskPool
 		}
 
+		// Custom volumes from local storage pools are mirrored to the target below.
+		if !diskPool.Driver().Info().Remote {
+			continue
+		}
+
 		// Setup the volume entry.
 		extraSourceArgs := &migration.VolumeSourceArgs{
 			ClusterMove: true,
@@ -6838,78 +7316,121 @@ func (d *qemu) migrateSendLive(pool storagePools.Pool, clusterMoveSourceName str
 		}
 	}
 
-	// Non-shared storage snapshot transfer.
-	if !sharedStorage {
-		listener, err := net.Listen("unix", "")
-		if err != nil {
-			return fmt.Errorf("Failed creating NBD unix listener: %w", err)
+	// Non-shared storage transfer.
+	// Block jobs mirroring the disks to the target, which need to be completed before switching over.
+	var mirrorJobs []string
+	if len(nbdDisks) > 0 {
+		// Resume guest on failure if it was paused before switching over (this is otherwise done when
+		// reverting the migration snapshot of the root disk).
+		if sharedStorage {
+			revert.Add(func() {
+				err := monitor.Start()
+				if err != nil {
+					d.logger.Warn("Failed resuming instance", logger.Ctx{"err": err})
+				}
+			})
 		}
 
-		defer func() { _ = listener.Close() }()
+		// Each disk uses its own NBD connection, so they need to be multiplexed over the filesystem
+		// connection when custom volumes are transferred alongside the root disk.
+		targetConns := []io.ReadWriteCloser{filesystemConn}
+		if qemuMigrationNBDMultiplexed(nbdDisks) {
+			mux, err := migration.NewMultiplexer(filesystemConn, len(nbdDisks))
+			if err != nil {
+				return err
+			}
 
-		go func() {
-			d.logger.Debug("NBD listener waiting for accept")
-			nbdConn, err := listener.Accept()
+			targetConns = make([]io.ReadWriteCloser, 0, len(nbdDisks))
+			for i := range nbdDisks {
+				targetConns = append(targetConns, mux.Stream(i))
+			}
+		}
+
+		for i, nbdDisk := range nbdDisks {
+			targetConn := targetConns[i]
+
+			// The root disk is transferred by the storage driver and only the writes that occurred since
+			// then (stored in the migration snapshot) need to be mirrored. Custom volumes have no backing
+			// image so they are mirrored entirely.
+			sourceNodeName := nbdDisk.nodeName
+			targetNodeName := qemuDeviceNameOrID(qemuDeviceNamePrefix, nbdDisk.devName, "_nbd", qemuDeviceNameMaxLength)
+			if nbdDisk.nodeName == qemuMigrationNBDExportName {
+				sourceNodeName = rootSnapshotDiskName
+				targetNodeName = nbdTargetDiskName
+			}
+
+			listener, err := net.Listen("unix", "")
 			if err != nil {
-				d.logger.Error("Failed accepting connection to NBD client unix listener", logger.Ctx{"err": err})
-				return
+				return fmt.Errorf("Failed creating NBD unix listener: %w", err)
 			}
 
-			defer func() { _ = nbdConn.Close() }()
+			defer func() { _ = listener.Close() }()
 
-			d.logger.Debug("NBD connection on source started")
-			go func() { _, _ = io.Copy(filesystemConn, nbdConn) }()
+			go func() {
+				d.logger.Debug("NBD listener waiting for accept", logger.Ctx{"disk": nbdDisk.devName})
+				nbdConn, err := listener.Accept()
+				if err != nil {
+					d.logger.Error("Failed accepting connection to NBD client unix listener", logger.Ctx{"disk": nbdDisk.devName, "err": err})
+					return
+				}
 
-			_, _ = io.Copy(nbdConn, filesystemConn)
-			d.logger.Debug("NBD connection on source finished")
-		}()
+				defer func() { _ = nbdConn.Close() }()
 
-		// Connect to NBD migration target and add it the source instance as a disk device.
-		d.logger.Debug("Connecting to migration NBD storage target")
-		err = monitor.AddBlockDevice(map[string]any{
-			"node-name": nbdTargetDiskName,
-			"driver":    "raw",
-			"file": map[string]any{
-				"driver": "nbd",
-				"export": qemuMigrationNBDExportName,
-				"server": map[string]any{
-					"type":     "unix",
-					"abstract": true,
-					"path":     strings.TrimPrefix(listener.Addr().String(), "@"),
-				},
-			},
-		}, nil)
-		if err != nil {
-			return fmt.Errorf("Failed adding NBD device: %w", err)
-		}
+				d.logger.Debug("NBD connection on source started", logger.Ctx{"disk": nbdDisk.devName})
+				go func() { _, _ = io.Copy(targetConn, nbdConn) }()
 
-		revert.Add(func() {
-			time.Sleep(time.Second) // Wait for it to be released.
-			err := monitor.RemoveBlockDevice(nbdTargetDiskName)
+				_, _ = io.Copy(nbdConn, targetConn)
+				d.logger.Debug("NBD connection on source finished", logger.Ctx{"disk": nbdDisk.devName})
+			}()
+
+			// Connect to NBD migration target and add it the source instance as a disk device.
+			d.logger.Debug("Connecting to migration NBD storage target", logger.Ctx{"disk": nbdDisk.devName})
+			err = monitor.AddBlockDevice(map[string]any{
+				"node-name": targetNodeName,
+				"driver":    "raw",
+				"file": map[string]any{
+					"driver": "nbd",
+					"export": nbdDisk.nodeName,
+					"server": map[string]any{
+						"type":     "unix",
+						"abstract": true,
+						"path":     strings.TrimPrefix(listener.Addr().String(), "@"),
+					},
+				},
+			}, nil)
 			if err != nil {
-				d.logger.Warn("Failed removing NBD storage target device", logger.Ctx{"err": err})
+				return fmt.Errorf("Failed adding NBD device for disk %q: %w", nbdDisk.devName, err)
 			}
-		})
 
-		d.logger.Debug("Connected to migration NBD storage target")
+			revert.Add(func() {
+				time.Sleep(time.Second) // Wait for it to be released.
+				err := monitor.RemoveBlockDevice(targetNodeName)
+				if err != nil {
+					d.logger.Warn("Failed removing NBD storage target device", logger.Ctx{"disk": nbdDisk.devName, "err": err})
+				}
+			})
 
-		// Begin transferring any writes that occurred during the storage migration by transferring the
-		// contents of the (top) migration snapshot to the target disk to bring them into sync.
-		// Once this has completed the guest OS will be paused.
-		d.logger.Debug("Migration storage snapshot transfer started")
-		err = monitor.BlockDevMirror(rootSnapshotDiskName, nbdTargetDiskName)
-		if err != nil {
-			return fmt.Errorf("Failed transferring migration storage snapshot: %w", err)
-		}
+			d.logger.Debug("Connected to migration NBD storage target", logger.Ctx{"disk": nbdDisk.devName})
 
-		revert.Add(func() {
-			err = monitor.BlockJobCancel(rootSnapshotDiskName)
+			// Begin mirroring the disk to the target disk to bring them into sync. Once in sync, the writes
+			// of the guest OS keep being mirrored until the block job is completed before switching over.
+			d.logger.Debug("Migration storage transfer started", logger.Ctx{"disk": nbdDisk.devName})
+			err = monitor.BlockDevMirror(sourceNodeName, targetNodeName)
 			if err != nil {
-				d.logger.Error("Failed cancelling block job", logger.Ctx{"err": err})
+				return fmt.Errorf("Failed mirroring disk %q: %w", nbdDisk.devName, err)
 			}
-		})
 
-		d.logger.Debug("Migration storage snapshot transfer finished")
+			revert.Add(func() {
+				err := monitor.BlockJobCancel(sourceNodeName)
+				if err != nil {
+					d.logger.Error("Failed cancelling block job", logger.Ctx{"disk": nbdDisk.devName, "err": err})
+				}
+			})
+
+			mirrorJobs = append(mirrorJobs, sourceNodeName)
+
+			d.logger.Debug("Migration storage transfer finished", logger.Ctx{"disk": nbdDisk.devName})
+		}
 	}
 
 	d.logger.Debug("Stateful migration checkpoint send starting")
@@ -6932,8 +7453,8 @@ func (d *qemu) migrateSendLive(pool storagePools.Pool, clusterMoveSourceName str
 		return fmt.Errorf("Failed starting state transfer to target: %w", err)
 	}
 
-	// Non-shared storage snapshot transfer finalization.
-	if !sharedStorage {
+	// Non-shared storage transfer finalization.
+	if len(mirrorJobs) > 0 {
 		// Wait until state transfer has reached pre-switchover state (the guest OS will remain paused).
 		err = monitor.MigrateWait("pre-switchover")
 		if err != nil {
@@ -6942,14 +7463,16 @@ func (d *qemu) migrateSendLive(pool storagePools.Pool, clusterMoveSourceName str
 
 		d.logger.Debug("Stateful migration checkpoint reached pre-switchover phase")
 
-		// Complete the migration snapshot sync process (the guest OS will remain paused).
-		d.logger.Debug("Migration storage snapshot transfer commit started")
-		err = monitor.BlockJobCancel(rootSnapshotDiskName)
-		if err != nil {
-			return fmt.Errorf("Failed cancelling block job: %w", err)
+		// Complete the disk mirroring process (the guest OS will remain paused).
+		d.logger.Debug("Migration storage transfer commit started")
+		for _, mirrorJob := range mirrorJobs {
+			err = monitor.BlockJobCancel(mirrorJob)
+			if err != nil {
+				return fmt.Errorf("Failed cancelling block job: %w", err)
+			}
 		}
 
-		d.logger.Debug("Migration storage snapshot transfer commit finished")
+		d.logger.Debug("Migration storage transfer commit finished")
 
 		// Finalise the migration state transfer (the guest OS will remain paused).
 		err = monitor.MigrateContinue("pre-switchover")
@@ -7006,6 +7529,39 @@ func (d *qemu) migrateSendLive(pool storagePools.Pool, clusterMoveSourceName str
 	return nil
 }
 
+// migrateCreateLocalVolume creates an empty custom volume on this cluster member using the configuration of the
+// volume of the same name on the migration source member. Its content is then mirrored from the source.
+func (d *qemu) migrateCreateLocalVolume(pool storagePools.Pool, projectName string, volName string) error {
+	var srcVolume *db.StorageVolume
+
+	// The volume doesn't exist on this member yet so this loads the one on the source member.
+	err := d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		srcVolume, err = tx.GetStoragePoolVolume(ctx, pool.ID(), projectName, dbCluster.StoragePoolVolumeTypeCustom, volName, false)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading source custom volume %q: %w", volName, err)
+	}
+
+	config := make(map[string]string, len(srcVolume.Config))
+	for k, v := range srcVolume.Config {
+		if strings.HasPrefix(k, "volatile.") {
+			continue
+		}
+
+		config[k] = v
+	}
+
+	err = pool.CreateCustomVolume(projectName, volName, srcVolume.Description, config, storageDrivers.ContentTypeBlock, d.op)
+	if err != nil {
+		return fmt.Errorf("Failed creating custom volume %q: %w", volName, err)
+	}
+
+	return nil
+}
+
 // MigrateReceive receives the migration offer from the source and negotiates the migration options.
 // It establishes the necessary connections and transfers the filesystem and snapshots if required.
 func (d *qemu) MigrateReceive(args instance.MigrateReceiveArgs) error {
@@ -7349,6 +7905,20 @@ func (d *qemu) MigrateReceive(args instance.MigrateReceiveArgs) error {
 				diskPools[poolName] = diskPool
 			}
 
+			// Custom volumes from local storage pools are created empty and then mirrored from the
+			// source once the instance is started.
+			if useStateConn && !diskPool.Driver().Info().Remote {
+				err = d.migrateCreateLocalVolume(diskPool, storageProjectName, dev.Config["source"])
+				if err != nil {
+					return fmt.Errorf("Failed to prepare device %q for migration: %w", dev.Name, err)
+				}
+
+				volName := dev.Config["source"]
+				revert.Add(func() { _ = diskPool.DeleteCustomVolume(storageProjectName, volName, nil) })
+
+				continue
+			}
+
 			// Setup the volume entry.
 			extraTargetArgs := migration.VolumeTargetArgs{
 				ClusterMoveSourceName: args.ClusterMoveSourceName,
@@ -7397,7 +7967,12 @@ func (d *qemu) MigrateReceive(args instance.MigrateReceiveArgs) error {
 
 				// Populate the filesystem connection handle if doing non-shared storage migration.
 				sharedStorage := args.ClusterMoveSourceName != "" && poolInfo.Remote
-				if !sharedStorage {
+				d.migrationReceiveNBDDisks, err = d.migrationNBDDisks(!sharedStorage)
+				if err != nil {
+					return err
+				}
+
+				if len(d.migrationReceiveNBDDisks) > 0 {
 					d.migrationReceiveStateful[api.SecretNameFilesystem] = filesystemConn
 				}
 			}
@@ -7558,6 +8133,11 @@ func (d *qemu) FileSFTPConn() (net.Conn, error) {
 	// Connect to the agent.
 	client, err := d.getAgentClient()
 	if err != nil {
+		// Fallback to the QEMU guest agent if enabled.
+		if errors.Is(err, errQemuAgentOffline) && d.guestAgentEnabled() {
+			return d.guestAgentSFTPConn()
+		}
+
 		return nil, err
 	}
 
@@ -7650,6 +8230,8 @@ func (d *qemu) Console(protocol string) (*os.File, chan error, error) {
 		path = d.consolePath()
 	case instance.ConsoleTypeVGA:
 		path = d.spicePath()
+	case instance.ConsoleTypeVNC:
+		path = d.vncPath()
 	default:
 		return nil, nil, fmt.Errorf("Unknown protocol %q", protocol)
 	}
@@ -7682,6 +8264,11 @@ func (d *qemu) Exec(req api.InstanceExecPost, stdin *os.File, stdout *os.File, s
 
 	client, err := d.getAgentClient()
 	if err != nil {
+		// Fallback to the QEMU guest agent if enabled.
+		if errors.Is(err, errQemuAgentOffline) && d.guestAgentEnabled() {
+			return d.guestAgentExec(req, stdout, stderr)
+		}
+
 		return nil, err
 	}
 
@@ -7899,7 +8486,7 @@ func (d *qemu) renderState(statusCode api.StatusCode) (*api.InstanceState, error
 				status = &api.InstanceState{}
 				status.Processes = -1
 
-				status.Network, err = d.getNetworkState()
+				status.Network, err = d.getFallbackNetworkState()
 				if err != nil {
 					return nil, err
 				}
@@ -7907,7 +8494,7 @@ func (d *qemu) renderState(statusCode api.StatusCode) (*api.InstanceState, error
 		} else {
 			status.Processes = -1
 
-			status.Network, err = d.getNetworkState()
+			status.Network, err = d.getFallbackNetworkState()
 			if err != nil {
 				return nil, err
 			}
@@ -7937,6 +8524,18 @@ func (d *qemu) renderState(statusCode api.StatusCode) (*api.InstanceState, error
 				}
 			}
 		}
+
+		d.networkShapingState(status.Network)
+
+		// Report the memory size (including any hot-plugged memory) if not provided by the agent.
+		if status.Memory.Total == 0 {
+			memTotal, err := d.memorySizeBytes()
+			if err != nil {
+				d.logger.Warn("Error getting memory size", logger.Ctx{"err": err})
+			} else {
+				status.Memory.Total = memTotal
+			}
+		}
 	}
 
 	status.Pid = int64(pid)
@@ -7950,6 +8549,26 @@ func (d *qemu) renderState(statusCode api.StatusCode) (*api.InstanceState, error
 	return status, nil
 }
 
+// memorySizeBytes returns the memory size of the running VM, including any hot-plugged memory.
+func (d *qemu) memorySizeBytes() (int64, error) {
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+	if err != nil {
+		return -1, err
+	}
+
+	baseSizeBytes, err := monitor.GetMemorySizeBytes()
+	if err != nil {
+		return -1, err
+	}
+
+	pluggedSizeBytes, err := monitor.GetPluggedMemorySizeBytes()
+	if err != nil {
+		return -1, err
+	}
+
+	return baseSizeBytes + pluggedSizeBytes, nil
+}
+
 // RenderState returns just state info about the instance.
 func (d *qemu) RenderState(hostInterfaces []net.Interface) (*api.InstanceState, error) {
 	return d.renderState(d.statusCode())
@@ -8095,7 +8714,7 @@ func (d *qemu) DeviceEventHandler(runConf *deviceConfig.RunConfig) error {
 		devID := fmt.Sprintf("%s%s", qemuDeviceIDPrefix, filesystem.PathNameEncode(mount.DevName))
 
 		// Apply the limits.
-		err = m.SetBlockThrottle(devID, int(mount.Limits.ReadBytes), int(mount.Limits.WriteBytes), int(mount.Limits.ReadIOps), int(mount.Limits.WriteIOps))
+		err = m.SetBlockThrottle(devID, int(mount.Limits.ReadBytes), int(mount.Limits.WriteBytes), int(mount.Limits.ReadIOps), int(mount.Limits.WriteIOps), mount.Limits.Group)
 		if err != nil {
 			return fmt.Errorf("Failed applying limits for disk device %q: %w", mount.DevName, err)
 		}
@@ -8914,6 +9533,24 @@ func (d *qemu) getNetworkState() (map[string]api.InstanceStateNetwork, error) {
 	return networks, nil
 }
 
+// getFallbackNetworkState returns the network state when the lxd-agent isn't available.
+// The guest IP addresses are added from the QEMU guest agent if enabled.
+func (d *qemu) getFallbackNetworkState() (map[string]api.InstanceStateNetwork, error) {
+	networks, err := d.getNetworkState()
+	if err != nil {
+		return nil, err
+	}
+
+	if d.guestAgentEnabled() {
+		err = d.guestAgentNetworkState(networks)
+		if err != nil && !errors.Is(err, qga.ErrAgentNotRunning) && !errors.Is(err, qga.ErrAgentBusy) {
+			d.logger.Warn("Could not get network state from QEMU guest agent", logger.Ctx{"err": err})
+		}
+	}
+
+	return networks, nil
+}
+
 func (d *qemu) agentMetricsEnabled() bool {
 	return shared.IsTrueOrEmpty(d.expandedConfig["security.agent.metrics"])
 }
@@ -4,13 +4,16 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"golang.org/x/sys/unix"
 
 	"github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/lxd/instance/drivers/qga"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/logger"
 )
@@ -126,3 +129,62 @@ func (c *qemuCmd) WindowResize(fd, winchWidth, winchHeight int) error {
 	logger.Debugf(`Forwarded window resize "%dx%d" to lxd-agent`, winchWidth, winchHeight)
 	return nil
 }
+
+// qemuGuestAgentCmd represents a command running through the QEMU guest agent of a Qemu VM.
+type qemuGuestAgentCmd struct {
+	agentPath string
+	pid       int
+	stdout    *os.File
+	stderr    *os.File
+}
+
+// PID returns the attached child's process ID.
+func (c *qemuGuestAgentCmd) PID() int {
+	return 0 // Process is not running on LXD host.
+}
+
+// Signal sends a signal to the command.
+func (c *qemuGuestAgentCmd) Signal(sig unix.Signal) error {
+	return fmt.Errorf("Sending signals to commands requires the lxd-agent")
+}
+
+// Wait for the command to end and returns its exit code and any error.
+func (c *qemuGuestAgentCmd) Wait() (int, error) {
+	for {
+		agent, err := qga.Connect(c.agentPath)
+		if err != nil {
+			return -1, ErrExecDisconnected
+		}
+
+		status, err := agent.ExecStatus(c.pid)
+		agent.Disconnect()
+		if err != nil {
+			return -1, err
+		}
+
+		if !status.Exited {
+			time.Sleep(250 * time.Millisecond)
+			continue
+		}
+
+		// The output is only available once the command has exited.
+		if c.stdout != nil {
+			_, _ = c.stdout.Write(status.Stdout)
+		}
+
+		if c.stderr != nil {
+			_, _ = c.stderr.Write(status.Stderr)
+		}
+
+		if status.Signal > 0 {
+			return 128 + status.Signal, nil
+		}
+
+		return status.ExitCode, nil
+	}
+}
+
+// WindowResize resizes the running command's window.
+func (c *qemuGuestAgentCmd) WindowResize(fd, winchWidth, winchHeight int) error {
+	return fmt.Errorf("Resizing the window of commands requires the lxd-agent")
+}
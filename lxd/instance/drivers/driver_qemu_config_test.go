@@ -98,15 +98,27 @@ func TestQemuConfigTemplates(t *testing.T) {
 			opts     qemuMemoryOpts
 			expected string
 		}{{
-			qemuMemoryOpts{4096},
+			qemuMemoryOpts{4096, 0, 0},
 			`# Memory
 			[memory]
 			size = "4096M"`,
 		}, {
-			qemuMemoryOpts{8192},
+			qemuMemoryOpts{8192, 0, 0},
 			`# Memory
 			[memory]
 			size = "8192M"`,
+		}, {
+			qemuMemoryOpts{2048, 16384, 16},
+			`# Memory
+			[memory]
+			size = "2048M"
+			maxmem = "16384M"
+			slots = "16"`,
+		}, {
+			qemuMemoryOpts{2048, 2048, 16},
+			`# Memory
+			[memory]
+			size = "2048M"`,
 		}}
 		for _, tc := range testCases {
 			runTest(tc.expected, qemuMemory(&tc.opts))
@@ -169,6 +181,30 @@ func TestQemuConfigTemplates(t *testing.T) {
 		}
 	})
 
+	t.Run("qemu_guest_agent", func(t *testing.T) {
+		testCases := []struct {
+			opts     qemuGuestAgentOpts
+			expected string
+		}{{
+			qemuGuestAgentOpts{"/var/log/lxd/vm/qemu.guest_agent"},
+			`# QEMU guest agent
+			[chardev "qemu_guest_agent-chardev"]
+			backend = "socket"
+			path = "/var/log/lxd/vm/qemu.guest_agent"
+			server = "on"
+			wait = "off"
+
+			[device "qemu_guest_agent"]
+			driver = "virtserialport"
+			name = "org.qemu.guest_agent.0"
+			chardev = "qemu_guest_agent-chardev"
+			bus = "dev-qemu_serial.0"`,
+		}}
+		for _, tc := range testCases {
+			runTest(tc.expected, qemuGuestAgent(&tc.opts))
+		}
+	})
+
 	t.Run("qemu_pcie", func(t *testing.T) {
 		testCases := []struct {
 			opts     qemuPCIeOpts
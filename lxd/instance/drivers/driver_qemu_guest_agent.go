@@ -0,0 +1,541 @@
+package drivers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/sftp"
+
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/drivers/qga"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// qemuGuestAgentFileChunkSize is the maximum amount of data transferred by a single guest agent file command.
+const qemuGuestAgentFileChunkSize = 1024 * 1024
+
+// qemuGuestAgentStateTimeout is how long the state queries wait for the guest agent to answer.
+const qemuGuestAgentStateTimeout = time.Second
+
+// qemuGuestAgentStateRetryInterval is how long the state queries skip a guest agent that didn't answer.
+const qemuGuestAgentStateRetryInterval = time.Minute
+
+// qemuGuestAgentUnavailable records when the guest agents that didn't answer a state query were last tried,
+// keyed by the path of their socket.
+var qemuGuestAgentUnavailable = map[string]time.Time{}
+var qemuGuestAgentUnavailableMu sync.Mutex
+
+func (d *qemu) guestAgentPath() string {
+	return filepath.Join(d.LogPath(), "qemu.guest_agent")
+}
+
+// guestAgentEnabled returns whether the QEMU guest agent channel is enabled.
+func (d *qemu) guestAgentEnabled() bool {
+	return shared.IsTrue(d.expandedConfig["agent.qemu_guest_agent"])
+}
+
+// getGuestAgent connects to the QEMU guest agent.
+// The caller must disconnect from the guest agent once done with it.
+func (d *qemu) getGuestAgent() (*qga.Agent, error) {
+	if !d.guestAgentEnabled() {
+		return nil, fmt.Errorf("QEMU guest agent channel isn't enabled")
+	}
+
+	return qga.Connect(d.guestAgentPath())
+}
+
+// getGuestAgentForState connects to the QEMU guest agent without waiting for it if it's in use, and without trying
+// again for a while if it didn't answer in time.
+// The caller must disconnect from the guest agent once done with it.
+func (d *qemu) getGuestAgentForState() (*qga.Agent, error) {
+	if !d.guestAgentEnabled() {
+		return nil, fmt.Errorf("QEMU guest agent channel isn't enabled")
+	}
+
+	path := d.guestAgentPath()
+
+	qemuGuestAgentUnavailableMu.Lock()
+	lastTried, unavailable := qemuGuestAgentUnavailable[path]
+	qemuGuestAgentUnavailableMu.Unlock()
+
+	if unavailable && time.Since(lastTried) < qemuGuestAgentStateRetryInterval {
+		return nil, qga.ErrAgentNotRunning
+	}
+
+	agent, err := qga.TryConnect(path, qemuGuestAgentStateTimeout)
+
+	qemuGuestAgentUnavailableMu.Lock()
+	if err != nil && !errors.Is(err, qga.ErrAgentBusy) {
+		qemuGuestAgentUnavailable[path] = time.Now()
+	} else {
+		delete(qemuGuestAgentUnavailable, path)
+	}
+
+	qemuGuestAgentUnavailableMu.Unlock()
+
+	return agent, err
+}
+
+// guestAgentExec runs a command in the guest through the QEMU guest agent.
+// The guest agent doesn't support interactive sessions or forwarding standard input and only returns the output
+// of the command once it has exited.
+func (d *qemu) guestAgentExec(req api.InstanceExecPost, stdout *os.File, stderr *os.File) (instance.Cmd, error) {
+	if req.Interactive {
+		return nil, fmt.Errorf("Interactive commands require the lxd-agent")
+	}
+
+	if req.User != 0 || req.Group != 0 || req.Cwd != "" {
+		return nil, fmt.Errorf("Setting the user, group or working directory of a command requires the lxd-agent")
+	}
+
+	if len(req.Command) == 0 {
+		return nil, fmt.Errorf("No command specified")
+	}
+
+	agent, err := d.getGuestAgent()
+	if err != nil {
+		return nil, err
+	}
+
+	// The environment isn't passed on as it would replace the whole environment of the command, which may not
+	// suit the guest operating system.
+	pid, err := agent.Exec(req.Command[0], req.Command[1:], nil, nil)
+	agent.Disconnect()
+	if err != nil {
+		if strings.Contains(err.Error(), "No such file or directory") {
+			return nil, ErrExecCommandNotFound
+		}
+
+		return nil, fmt.Errorf("Failed running command through the QEMU guest agent: %w", err)
+	}
+
+	d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceExec.Event(d, logger.Ctx{"command": req.Command}))
+
+	return &qemuGuestAgentCmd{
+		agentPath: d.guestAgentPath(),
+		pid:       pid,
+		stdout:    stdout,
+		stderr:    stderr,
+	}, nil
+}
+
+// guestAgentSFTPConn returns a connection to an SFTP server backed by the QEMU guest agent file commands.
+func (d *qemu) guestAgentSFTPConn() (net.Conn, error) {
+	// Check that the guest agent is responding.
+	agent, err := d.getGuestAgent()
+	if err != nil {
+		return nil, err
+	}
+
+	agent.Disconnect()
+
+	serverConn, clientConn := net.Pipe()
+
+	files := &qemuGuestAgentFiles{agentPath: d.guestAgentPath()}
+	server := sftp.NewRequestServer(serverConn, sftp.Handlers{
+		FileGet:  files,
+		FilePut:  files,
+		FileCmd:  files,
+		FileList: files,
+	})
+
+	go func() {
+		_ = server.Serve()
+		_ = server.Close()
+	}()
+
+	return clientConn, nil
+}
+
+// guestAgentFreeze freezes the guest filesystems through the QEMU guest agent.
+// On success, it returns a function that thaws them.
+func (d *qemu) guestAgentFreeze() (func() error, error) {
+	agent, err := d.getGuestAgent()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = agent.FSFreeze()
+	agent.Disconnect()
+	if err != nil {
+		return nil, fmt.Errorf("Failed freezing guest filesystems: %w", err)
+	}
+
+	thaw := func() error {
+		agent, err := d.getGuestAgent()
+		if err != nil {
+			return err
+		}
+
+		defer agent.Disconnect()
+
+		_, err = agent.FSThaw()
+		if err != nil {
+			return fmt.Errorf("Failed thawing guest filesystems: %w", err)
+		}
+
+		return nil
+	}
+
+	return thaw, nil
+}
+
+// guestAgentNetworkState adds the IP addresses reported by the QEMU guest agent to the network state.
+// Guest interfaces are matched with the NICs by MAC address, other guest interfaces are added by name.
+// As it's used on every state query, it doesn't wait for the guest agent if it's in use or was recently found not
+// to be running.
+func (d *qemu) guestAgentNetworkState(networks map[string]api.InstanceStateNetwork) error {
+	agent, err := d.getGuestAgentForState()
+	if err != nil {
+		return err
+	}
+
+	defer agent.Disconnect()
+
+	ifaces, err := agent.NetworkGetInterfaces()
+	if err != nil {
+		return err
+	}
+
+	for _, iface := range ifaces {
+		// Skip the loopback interface.
+		if iface.HardwareAddress == "" || iface.HardwareAddress == "00:00:00:00:00:00" {
+			continue
+		}
+
+		addresses := []api.InstanceStateNetworkAddress{}
+		for _, address := range iface.IPAddresses {
+			ip := net.ParseIP(address.Address)
+			if ip == nil {
+				continue
+			}
+
+			family := "inet"
+			if address.Type == "ipv6" {
+				family = "inet6"
+			}
+
+			scope := "global"
+			if ip.IsLoopback() {
+				scope = "local"
+			} else if ip.IsLinkLocalUnicast() {
+				scope = "link"
+			}
+
+			addresses = append(addresses, api.InstanceStateNetworkAddress{
+				Family:  family,
+				Address: ip.String(),
+				Netmask: fmt.Sprintf("%d", address.Prefix),
+				Scope:   scope,
+			})
+		}
+
+		found := false
+		for name, network := range networks {
+			if strings.EqualFold(network.Hwaddr, iface.HardwareAddress) {
+				network.Addresses = addresses
+				networks[name] = network
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			networks[iface.Name] = api.InstanceStateNetwork{
+				Addresses: addresses,
+				Hwaddr:    iface.HardwareAddress,
+				State:     "up",
+				Type:      "broadcast",
+			}
+		}
+	}
+
+	return nil
+}
+
+// qemuGuestAgentFiles implements the SFTP request handlers using the QEMU guest agent file commands.
+// The guest agent can only read and write files, so file metadata isn't available and changing the permissions
+// or ownership of files is ignored.
+type qemuGuestAgentFiles struct {
+	agentPath string
+}
+
+// run connects to the guest agent and runs f with it.
+func (f *qemuGuestAgentFiles) run(fn func(agent *qga.Agent) error) error {
+	agent, err := qga.Connect(f.agentPath)
+	if err != nil {
+		return err
+	}
+
+	defer agent.Disconnect()
+
+	return fn(agent)
+}
+
+// open opens a file in the guest with the given fopen() mode.
+func (f *qemuGuestAgentFiles) open(path string, mode string) (*qemuGuestAgentFile, error) {
+	file := &qemuGuestAgentFile{files: f}
+
+	err := f.run(func(agent *qga.Agent) error {
+		var err error
+		file.handle, err = agent.FileOpen(path, mode)
+		return err
+	})
+	if err != nil {
+		return nil, qemuGuestAgentFileError("open", path, err)
+	}
+
+	return file, nil
+}
+
+// openFlags opens a file in the guest with the given SFTP open flags.
+func (f *qemuGuestAgentFiles) openFlags(path string, flags sftp.FileOpenFlags) (*qemuGuestAgentFile, error) {
+	switch {
+	case flags.Append && flags.Read:
+		return f.open(path, "a+b")
+	case flags.Append:
+		return f.open(path, "ab")
+	case flags.Trunc && flags.Read:
+		return f.open(path, "w+b")
+	case flags.Trunc:
+		return f.open(path, "wb")
+	case flags.Write:
+		// Open the existing file for writing without truncating it, creating it if needed.
+		file, err := f.open(path, "r+b")
+		if err != nil && flags.Creat && errors.Is(err, os.ErrNotExist) {
+			return f.open(path, "w+b")
+		}
+
+		return file, err
+	default:
+		return f.open(path, "rb")
+	}
+}
+
+// Fileread opens a file for reading.
+func (f *qemuGuestAgentFiles) Fileread(r *sftp.Request) (io.ReaderAt, error) {
+	return f.openFlags(r.Filepath, r.Pflags())
+}
+
+// Filewrite opens a file for writing.
+func (f *qemuGuestAgentFiles) Filewrite(r *sftp.Request) (io.WriterAt, error) {
+	return f.openFlags(r.Filepath, r.Pflags())
+}
+
+// OpenFile opens a file for reading and writing.
+func (f *qemuGuestAgentFiles) OpenFile(r *sftp.Request) (sftp.WriterAtReaderAt, error) {
+	return f.openFlags(r.Filepath, r.Pflags())
+}
+
+// Filecmd handles the file commands.
+func (f *qemuGuestAgentFiles) Filecmd(r *sftp.Request) error {
+	switch r.Method {
+	case "Setstat":
+		// Permissions and ownership can't be changed through the guest agent.
+		if r.AttrFlags().Size {
+			return sftp.ErrSSHFxOpUnsupported
+		}
+
+		return nil
+	default:
+		return sftp.ErrSSHFxOpUnsupported
+	}
+}
+
+// Filelist handles the file listing commands.
+func (f *qemuGuestAgentFiles) Filelist(r *sftp.Request) (sftp.ListerAt, error) {
+	if r.Method != "Stat" {
+		return nil, sftp.ErrSSHFxOpUnsupported
+	}
+
+	// Get the size of the file by seeking to its end.
+	var size int64
+	err := f.run(func(agent *qga.Agent) error {
+		handle, err := agent.FileOpen(r.Filepath, "rb")
+		if err != nil {
+			return err
+		}
+
+		defer func() { _ = agent.FileClose(handle) }()
+
+		size, err = agent.FileSeek(handle, 0, io.SeekEnd)
+		return err
+	})
+	if err != nil {
+		return nil, qemuGuestAgentFileError("stat", r.Filepath, err)
+	}
+
+	return qemuGuestAgentFileList{&qemuGuestAgentFileInfo{name: filepath.Base(r.Filepath), size: size}}, nil
+}
+
+// qemuGuestAgentFileError converts an error from the guest agent file commands.
+func qemuGuestAgentFileError(op string, path string, err error) error {
+	if strings.Contains(err.Error(), "No such file or directory") || strings.Contains(err.Error(), "cannot find the file") {
+		err = os.ErrNotExist
+	}
+
+	return &os.PathError{Op: op, Path: path, Err: err}
+}
+
+// qemuGuestAgentFile is a file opened through the QEMU guest agent.
+type qemuGuestAgentFile struct {
+	files  *qemuGuestAgentFiles
+	handle int
+	offset int64 // Current position of the file in the guest.
+	lock   sync.Mutex
+}
+
+// seek moves the file position in the guest to offset if needed.
+func (f *qemuGuestAgentFile) seek(agent *qga.Agent, offset int64) error {
+	if f.offset == offset {
+		return nil
+	}
+
+	position, err := agent.FileSeek(f.handle, offset, io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	f.offset = position
+	return nil
+}
+
+// ReadAt reads data from the file at the given offset.
+func (f *qemuGuestAgentFile) ReadAt(p []byte, off int64) (int, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	n := 0
+	eof := false
+	err := f.files.run(func(agent *qga.Agent) error {
+		err := f.seek(agent, off)
+		if err != nil {
+			return err
+		}
+
+		for n < len(p) && !eof {
+			var data []byte
+			data, eof, err = agent.FileRead(f.handle, min(len(p)-n, qemuGuestAgentFileChunkSize))
+			if err != nil {
+				return err
+			}
+
+			f.offset += int64(len(data))
+			n += copy(p[n:], data)
+
+			if len(data) == 0 {
+				eof = true
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return n, err
+	}
+
+	if eof && n < len(p) {
+		return n, io.EOF
+	}
+
+	return n, nil
+}
+
+// WriteAt writes data to the file at the given offset.
+func (f *qemuGuestAgentFile) WriteAt(p []byte, off int64) (int, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	n := 0
+	err := f.files.run(func(agent *qga.Agent) error {
+		err := f.seek(agent, off)
+		if err != nil {
+			return err
+		}
+
+		for n < len(p) {
+			written, err := agent.FileWrite(f.handle, p[n:min(len(p), n+qemuGuestAgentFileChunkSize)])
+			if err != nil {
+				return err
+			}
+
+			f.offset += int64(written)
+			n += written
+		}
+
+		return nil
+	})
+
+	return n, err
+}
+
+// Close closes the file.
+func (f *qemuGuestAgentFile) Close() error {
+	return f.files.run(func(agent *qga.Agent) error {
+		return agent.FileClose(f.handle)
+	})
+}
+
+// qemuGuestAgentFileInfo is the information of a file accessed through the QEMU guest agent.
+// Only the name and size of files are known.
+type qemuGuestAgentFileInfo struct {
+	name string
+	size int64
+}
+
+// Name returns the file name.
+func (i *qemuGuestAgentFileInfo) Name() string {
+	return i.name
+}
+
+// Size returns the file size.
+func (i *qemuGuestAgentFileInfo) Size() int64 {
+	return i.size
+}
+
+// Mode returns the file mode, which is always reported as a regular file.
+func (i *qemuGuestAgentFileInfo) Mode() os.FileMode {
+	return 0644
+}
+
+// ModTime returns the modification time, which is unknown.
+func (i *qemuGuestAgentFileInfo) ModTime() time.Time {
+	return time.Unix(0, 0)
+}
+
+// IsDir returns whether the file is a directory.
+func (i *qemuGuestAgentFileInfo) IsDir() bool {
+	return false
+}
+
+// Sys returns the underlying data source.
+func (i *qemuGuestAgentFileInfo) Sys() any {
+	return nil
+}
+
+// qemuGuestAgentFileList is a list of files returned to the SFTP client.
+type qemuGuestAgentFileList []os.FileInfo
+
+// ListAt copies the files starting at the given offset into ls.
+func (l qemuGuestAgentFileList) ListAt(ls []os.FileInfo, offset int64) (int, error) {
+	if offset >= int64(len(l)) {
+		return 0, io.EOF
+	}
+
+	n := copy(ls, l[offset:])
+	if n < len(ls) {
+		return n, io.EOF
+	}
+
+	return n, nil
+}
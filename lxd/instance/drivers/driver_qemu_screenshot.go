@@ -0,0 +1,166 @@
+package drivers
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"strconv"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/canonical/lxd/lxd/instance/drivers/qmp"
+)
+
+// qemuScreenshotFDName is the name of the file descriptor the display is captured into.
+const qemuScreenshotFDName = "lxd_screenshot"
+
+// qemuScreenshotMaxSize is the maximum width and height of a screenshot.
+const qemuScreenshotMaxSize = 16384
+
+// qemuScreenshotMaxHeaderField is the maximum length of a field of the PPM header.
+const qemuScreenshotMaxHeaderField = 16
+
+// ConsoleScreenshot returns a PNG image of the display.
+func (d *qemu) ConsoleScreenshot() ([]byte, error) {
+	if !d.IsRunning() {
+		return nil, fmt.Errorf("Instance is not running")
+	}
+
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+	if err != nil {
+		return nil, err
+	}
+
+	// The display is captured into a file passed to QEMU as it may not be allowed to create files itself.
+	screenshotFile, err := os.CreateTemp(d.LogPath(), "qemu.screenshot.")
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = screenshotFile.Close()
+		_ = os.Remove(screenshotFile.Name())
+	}()
+
+	// QEMU only uses a file descriptor of an FD set if its access mode matches the one it opens the file with.
+	writeFile, err := os.OpenFile(screenshotFile.Name(), unix.O_WRONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = writeFile.Close() }()
+
+	info, err := monitor.SendFileWithFDSet(qemuScreenshotFDName, writeFile, false)
+	if err != nil {
+		return nil, fmt.Errorf("Failed sending file descriptor for the screenshot: %w", err)
+	}
+
+	defer func() { _ = monitor.RemoveFDFromFDSet(qemuScreenshotFDName) }()
+
+	_ = writeFile.Close()
+
+	err = monitor.Screendump(fmt.Sprintf("/dev/fdset/%d", info.ID))
+	if err != nil {
+		return nil, fmt.Errorf("Failed capturing the display: %w", err)
+	}
+
+	img, err := qemuDecodePPM(screenshotFile)
+	if err != nil {
+		return nil, fmt.Errorf("Failed decoding the screenshot: %w", err)
+	}
+
+	var buf bytes.Buffer
+	err = png.Encode(&buf, img)
+	if err != nil {
+		return nil, fmt.Errorf("Failed encoding the screenshot: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// qemuDecodePPM decodes an image in the binary PPM format written by QEMU's screendump command.
+func qemuDecodePPM(r io.Reader) (image.Image, error) {
+	br := bufio.NewReader(r)
+
+	// Read the header fields (magic number, width, height and maximum color value), skipping comments.
+	fields := make([]string, 0, 4)
+	for len(fields) < 4 {
+		var field []byte
+		for {
+			c, err := br.ReadByte()
+			if err != nil {
+				return nil, fmt.Errorf("Invalid PPM header: %w", err)
+			}
+
+			if c == '#' && len(field) == 0 {
+				_, err = br.ReadString('\n')
+				if err != nil {
+					return nil, fmt.Errorf("Invalid PPM header: %w", err)
+				}
+
+				continue
+			}
+
+			if c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+				if len(field) > 0 {
+					break
+				}
+
+				continue
+			}
+
+			if len(field) >= qemuScreenshotMaxHeaderField {
+				return nil, fmt.Errorf("Invalid PPM header: Field too long")
+			}
+
+			field = append(field, c)
+		}
+
+		fields = append(fields, string(field))
+	}
+
+	if fields[0] != "P6" {
+		return nil, fmt.Errorf("Unsupported PPM format %q", fields[0])
+	}
+
+	width, err := strconv.Atoi(fields[1])
+	if err != nil || width <= 0 || width > qemuScreenshotMaxSize {
+		return nil, fmt.Errorf("Invalid PPM width %q", fields[1])
+	}
+
+	height, err := strconv.Atoi(fields[2])
+	if err != nil || height <= 0 || height > qemuScreenshotMaxSize {
+		return nil, fmt.Errorf("Invalid PPM height %q", fields[2])
+	}
+
+	maxVal, err := strconv.Atoi(fields[3])
+	if err != nil || maxVal <= 0 || maxVal > 255 {
+		return nil, fmt.Errorf("Unsupported PPM maximum color value %q", fields[3])
+	}
+
+	pixels := make([]byte, width*height*3)
+	_, err = io.ReadFull(br, pixels)
+	if err != nil {
+		return nil, fmt.Errorf("Failed reading PPM pixels: %w", err)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := (y*width + x) * 3
+			img.SetRGBA(x, y, color.RGBA{
+				R: uint8(int(pixels[i]) * 255 / maxVal),
+				G: uint8(int(pixels[i+1]) * 255 / maxVal),
+				B: uint8(int(pixels[i+2]) * 255 / maxVal),
+				A: 255,
+			})
+		}
+	}
+
+	return img, nil
+}
@@ -0,0 +1,48 @@
+package drivers
+
+import (
+	"bytes"
+	"image/color"
+	"testing"
+)
+
+func TestQemuDecodePPM(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		data := append([]byte("P6\n# Created by QEMU\n2 1\n255\n"), 255, 0, 0, 0, 128, 255)
+
+		img, err := qemuDecodePPM(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if img.Bounds().Dx() != 2 || img.Bounds().Dy() != 1 {
+			t.Fatalf("Unexpected image size: %v", img.Bounds())
+		}
+
+		expected := []color.RGBA{{R: 255, A: 255}, {G: 128, B: 255, A: 255}}
+		for x, c := range expected {
+			if img.At(x, 0) != c {
+				t.Errorf("Unexpected color at %d: %v", x, img.At(x, 0))
+			}
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		testCases := map[string][]byte{
+			"ascii format":     []byte("P3\n1 1\n255\n255 0 0\n"),
+			"truncated pixels": append([]byte("P6\n2 2\n255\n"), 0, 0, 0),
+			"invalid size":     []byte("P6\n0 1\n255\n"),
+			"oversized width":  []byte("P6\n16385 1\n255\n"),
+			"oversized height": []byte("P6\n1 100000000\n255\n"),
+			"long field":       []byte("P6\n00000000000000001 1\n255\n"),
+			"missing header":   []byte("P6\n1"),
+		}
+
+		for name, data := range testCases {
+			_, err := qemuDecodePPM(bytes.NewReader(data))
+			if err == nil {
+				t.Errorf("Expected error for %s", name)
+			}
+		}
+	})
+}
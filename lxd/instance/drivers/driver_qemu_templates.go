@@ -141,14 +141,27 @@ func qemuBase(opts *qemuBaseOpts) []cfgSection {
 }
 
 type qemuMemoryOpts struct {
-	memSizeMB int64
+	memSizeMB    int64
+	maxMemSizeMB int64
+	memSlots     int
 }
 
 func qemuMemory(opts *qemuMemoryOpts) []cfgSection {
+	entries := []cfgEntry{{key: "size", value: fmt.Sprintf("%dM", opts.memSizeMB)}}
+
+	// Reserve room for hot-plugged memory.
+	if opts.maxMemSizeMB > opts.memSizeMB && opts.memSlots > 0 {
+		entries = append(entries, cfgEntry{
+			key: "maxmem", value: fmt.Sprintf("%dM", opts.maxMemSizeMB),
+		}, cfgEntry{
+			key: "slots", value: fmt.Sprintf("%d", opts.memSlots),
+		})
+	}
+
 	return []cfgSection{{
 		name:    "memory",
 		comment: "Memory",
-		entries: []cfgEntry{{key: "size", value: fmt.Sprintf("%dM", opts.memSizeMB)}},
+		entries: entries,
 	}}
 }
 
@@ -263,6 +276,31 @@ func qemuSerial(opts *qemuSerialOpts) []cfgSection {
 	}}
 }
 
+type qemuGuestAgentOpts struct {
+	path string
+}
+
+func qemuGuestAgent(opts *qemuGuestAgentOpts) []cfgSection {
+	return []cfgSection{{
+		name:    `chardev "qemu_guest_agent-chardev"`,
+		comment: "QEMU guest agent",
+		entries: []cfgEntry{
+			{key: "backend", value: "socket"},
+			{key: "path", value: opts.path},
+			{key: "server", value: "on"},
+			{key: "wait", value: "off"},
+		},
+	}, {
+		name: `device "qemu_guest_agent"`,
+		entries: []cfgEntry{
+			{key: "driver", value: "virtserialport"},
+			{key: "name", value: "org.qemu.guest_agent.0"},
+			{key: "chardev", value: "qemu_guest_agent-chardev"},
+			{key: "bus", value: "dev-qemu_serial.0"},
+		},
+	}}
+}
+
 type qemuPCIeOpts struct {
 	portName      string
 	index         int
@@ -0,0 +1,250 @@
+package qga
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// syncTimeout is how long to wait for the guest agent to answer the initial synchronization.
+const syncTimeout = 5 * time.Second
+
+// commandTimeout is how long to wait for the guest agent to answer a command.
+const commandTimeout = 60 * time.Second
+
+// syncDelimiter is sent by the guest agent before the response to guest-sync-delimited.
+// Sending it to the guest agent resets its parser.
+const syncDelimiter = 0xFF
+
+// maxResponseSize is the maximum length of a single guest agent response.
+// The guest agent captures up to 16MiB of output per stream of a command, which is base64 encoded in the response.
+const maxResponseSize = 64 * 1024 * 1024
+
+var agentLocks = map[string]*sync.Mutex{}
+var agentLocksLock sync.Mutex
+
+// Agent represents a connection to a QEMU guest agent.
+type Agent struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	unlock func()
+}
+
+// Connect connects to the QEMU guest agent listening on the given socket path.
+// The guest agent channel only supports a single client at a time, so Connect waits for any other connection
+// to the same path to be closed. The caller must call Disconnect once done with the guest agent.
+func Connect(path string) (*Agent, error) {
+	lock := agentLock(path)
+	lock.Lock()
+
+	return connect(path, lock, syncTimeout)
+}
+
+// TryConnect connects to the QEMU guest agent listening on the given socket path like Connect, but returns
+// ErrAgentBusy instead of waiting if another connection to the same path is open, and ErrAgentNotRunning if the
+// guest agent doesn't answer the initial synchronization within the given timeout.
+func TryConnect(path string, timeout time.Duration) (*Agent, error) {
+	lock := agentLock(path)
+	if !lock.TryLock() {
+		return nil, ErrAgentBusy
+	}
+
+	return connect(path, lock, timeout)
+}
+
+// agentLock returns the lock serializing the connections to the guest agent listening on the given path.
+func agentLock(path string) *sync.Mutex {
+	agentLocksLock.Lock()
+	defer agentLocksLock.Unlock()
+
+	lock, ok := agentLocks[path]
+	if !ok {
+		lock = &sync.Mutex{}
+		agentLocks[path] = lock
+	}
+
+	return lock
+}
+
+// connect connects to the guest agent while holding its lock, which is released on failure or on Disconnect.
+func connect(path string, lock *sync.Mutex, timeout time.Duration) (*Agent, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		lock.Unlock()
+		return nil, err
+	}
+
+	a := &Agent{
+		conn:   conn,
+		reader: bufio.NewReader(conn),
+		unlock: lock.Unlock,
+	}
+
+	err = a.sync(timeout)
+	if err != nil {
+		a.Disconnect()
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// Disconnect closes the connection to the guest agent.
+func (a *Agent) Disconnect() {
+	_ = a.conn.Close()
+	a.unlock()
+}
+
+// sync synchronizes with the guest agent, discarding any leftover responses from previous connections.
+func (a *Agent) sync(timeout time.Duration) error {
+	err := a.conn.SetDeadline(time.Now().Add(timeout))
+	if err != nil {
+		return err
+	}
+
+	_, err = a.conn.Write([]byte{syncDelimiter})
+	if err != nil {
+		return err
+	}
+
+	id := rand.Int63()
+	err = a.write("guest-sync-delimited", map[string]int64{"id": id})
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Skip any data until the delimiter sent before the response.
+		err = a.skip(syncDelimiter)
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				return ErrAgentNotRunning
+			}
+
+			return err
+		}
+
+		var resp struct {
+			Return int64 `json:"return"`
+		}
+
+		err = a.read(&resp)
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				return ErrAgentNotRunning
+			}
+
+			if errors.Is(err, errResponseTooLarge) {
+				return err
+			}
+
+			continue
+		}
+
+		if resp.Return == id {
+			return nil
+		}
+	}
+}
+
+// write sends a command to the guest agent.
+func (a *Agent) write(cmd string, args any) error {
+	request, err := json.Marshal(struct {
+		Execute   string `json:"execute"`
+		Arguments any    `json:"arguments,omitempty"`
+	}{
+		Execute:   cmd,
+		Arguments: args,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = a.conn.Write(append(request, '\n'))
+	return err
+}
+
+// read reads a response from the guest agent and decodes its return value into resp.
+func (a *Agent) read(resp any) error {
+	line, err := a.readLine('\n')
+	if err != nil {
+		return err
+	}
+
+	var response struct {
+		Return json.RawMessage `json:"return"`
+		Error  *struct {
+			Class string `json:"class"`
+			Desc  string `json:"desc"`
+		} `json:"error"`
+	}
+
+	err = json.Unmarshal(line, &response)
+	if err != nil {
+		return fmt.Errorf("Unexpected guest agent response: %w (%q)", err, string(line))
+	}
+
+	if response.Error != nil {
+		return fmt.Errorf("%s", response.Error.Desc)
+	}
+
+	if resp != nil && response.Return != nil {
+		err = json.Unmarshal(response.Return, resp)
+		if err != nil {
+			return fmt.Errorf("Unexpected guest agent response: %w (%q)", err, string(line))
+		}
+	}
+
+	return nil
+}
+
+// skip discards the data received from the guest agent up to and including the given delimiter.
+func (a *Agent) skip(delim byte) error {
+	for {
+		_, err := a.reader.ReadSlice(delim)
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return err
+		}
+	}
+}
+
+// readLine reads the data received from the guest agent up to and including the given delimiter.
+// The connection is closed if the data exceeds maxResponseSize as the guest agent can't be trusted to send
+// well-formed responses.
+func (a *Agent) readLine(delim byte) ([]byte, error) {
+	var line []byte
+
+	for {
+		chunk, err := a.reader.ReadSlice(delim)
+		if len(line)+len(chunk) > maxResponseSize {
+			_ = a.conn.Close()
+			return nil, errResponseTooLarge
+		}
+
+		line = append(line, chunk...)
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return line, err
+		}
+	}
+}
+
+// run executes a command.
+func (a *Agent) run(cmd string, args any, resp any) error {
+	err := a.conn.SetDeadline(time.Now().Add(commandTimeout))
+	if err != nil {
+		return err
+	}
+
+	err = a.write(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	return a.read(resp)
+}
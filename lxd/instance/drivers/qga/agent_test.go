@@ -0,0 +1,67 @@
+package qga
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"testing"
+)
+
+func TestAgentReadLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		want    []byte
+		wantErr error
+	}{
+		{
+			name: "Short line",
+			data: []byte("{\"return\": {}}\n"),
+			want: []byte("{\"return\": {}}\n"),
+		},
+		{
+			name: "Line larger than the read buffer",
+			data: append(bytes.Repeat([]byte("a"), 3*4096), '\n'),
+			want: append(bytes.Repeat([]byte("a"), 3*4096), '\n'),
+		},
+		{
+			name:    "Line exceeding the maximum response size",
+			data:    bytes.Repeat([]byte("a"), maxResponseSize+2*4096),
+			wantErr: errResponseTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := net.Pipe()
+			defer func() { _ = server.Close() }()
+
+			go func() {
+				_, _ = server.Write(tt.data)
+			}()
+
+			a := &Agent{
+				conn:   client,
+				reader: bufio.NewReader(client),
+			}
+
+			line, err := a.readLine('\n')
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+
+			if !bytes.Equal(line, tt.want) {
+				t.Fatalf("Expected %d bytes, got %d", len(tt.want), len(line))
+			}
+
+			if tt.wantErr != nil {
+				_, err = client.Read(make([]byte, 1))
+				if !errors.Is(err, io.ErrClosedPipe) {
+					t.Fatalf("Expected the connection to be closed, got %v", err)
+				}
+			}
+		})
+	}
+}
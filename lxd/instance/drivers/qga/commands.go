@@ -0,0 +1,220 @@
+package qga
+
+import (
+	"encoding/base64"
+	"fmt"
+)
+
+// ExecStatus contains the status of a command started with Exec.
+type ExecStatus struct {
+	Exited   bool
+	ExitCode int
+	Signal   int
+	Stdout   []byte
+	Stderr   []byte
+}
+
+// NetworkAddress contains information about an IP address of a guest network interface.
+type NetworkAddress struct {
+	Type    string `json:"ip-address-type"`
+	Address string `json:"ip-address"`
+	Prefix  int    `json:"prefix"`
+}
+
+// NetworkInterface contains information about a guest network interface.
+type NetworkInterface struct {
+	Name            string           `json:"name"`
+	HardwareAddress string           `json:"hardware-address"`
+	IPAddresses     []NetworkAddress `json:"ip-addresses"`
+}
+
+// Ping checks that the guest agent is responding.
+func (a *Agent) Ping() error {
+	return a.run("guest-ping", nil, nil)
+}
+
+// Exec starts a command in the guest with its output captured and returns its PID.
+func (a *Agent) Exec(path string, args []string, env []string, input []byte) (int, error) {
+	// Prepare the response.
+	var resp struct {
+		PID int `json:"pid"`
+	}
+
+	cmdArgs := map[string]any{
+		"path":           path,
+		"capture-output": true,
+	}
+
+	if len(args) > 0 {
+		cmdArgs["arg"] = args
+	}
+
+	if len(env) > 0 {
+		cmdArgs["env"] = env
+	}
+
+	if len(input) > 0 {
+		cmdArgs["input-data"] = base64.StdEncoding.EncodeToString(input)
+	}
+
+	err := a.run("guest-exec", cmdArgs, &resp)
+	if err != nil {
+		return -1, err
+	}
+
+	return resp.PID, nil
+}
+
+// ExecStatus returns the status of a command started with Exec.
+// The captured output is only returned once the command has exited.
+func (a *Agent) ExecStatus(pid int) (*ExecStatus, error) {
+	// Prepare the response.
+	var resp struct {
+		Exited   bool   `json:"exited"`
+		ExitCode int    `json:"exitcode"`
+		Signal   int    `json:"signal"`
+		OutData  string `json:"out-data"`
+		ErrData  string `json:"err-data"`
+	}
+
+	err := a.run("guest-exec-status", map[string]int{"pid": pid}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &ExecStatus{
+		Exited:   resp.Exited,
+		ExitCode: resp.ExitCode,
+		Signal:   resp.Signal,
+	}
+
+	status.Stdout, err = base64.StdEncoding.DecodeString(resp.OutData)
+	if err != nil {
+		return nil, fmt.Errorf("Failed decoding command output: %w", err)
+	}
+
+	status.Stderr, err = base64.StdEncoding.DecodeString(resp.ErrData)
+	if err != nil {
+		return nil, fmt.Errorf("Failed decoding command error output: %w", err)
+	}
+
+	return status, nil
+}
+
+// FileOpen opens a file in the guest with the given fopen() mode and returns its handle.
+func (a *Agent) FileOpen(path string, mode string) (int, error) {
+	var handle int
+
+	err := a.run("guest-file-open", map[string]string{"path": path, "mode": mode}, &handle)
+	if err != nil {
+		return -1, err
+	}
+
+	return handle, nil
+}
+
+// FileClose closes a file opened with FileOpen.
+func (a *Agent) FileClose(handle int) error {
+	return a.run("guest-file-close", map[string]int{"handle": handle}, nil)
+}
+
+// FileRead reads up to count bytes from a file opened with FileOpen.
+// It also returns whether the end of the file was reached.
+func (a *Agent) FileRead(handle int, count int) ([]byte, bool, error) {
+	// Prepare the response.
+	var resp struct {
+		Count int    `json:"count"`
+		Data  string `json:"buf-b64"`
+		EOF   bool   `json:"eof"`
+	}
+
+	err := a.run("guest-file-read", map[string]int{"handle": handle, "count": count}, &resp)
+	if err != nil {
+		return nil, false, err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(resp.Data)
+	if err != nil {
+		return nil, false, fmt.Errorf("Failed decoding file data: %w", err)
+	}
+
+	return data, resp.EOF, nil
+}
+
+// FileWrite writes data to a file opened with FileOpen and returns the number of bytes written.
+func (a *Agent) FileWrite(handle int, data []byte) (int, error) {
+	// Prepare the response.
+	var resp struct {
+		Count int `json:"count"`
+	}
+
+	args := map[string]any{
+		"handle":  handle,
+		"buf-b64": base64.StdEncoding.EncodeToString(data),
+	}
+
+	err := a.run("guest-file-write", args, &resp)
+	if err != nil {
+		return -1, err
+	}
+
+	return resp.Count, nil
+}
+
+// FileSeek sets the position of a file opened with FileOpen (whence is one of io.SeekStart, io.SeekCurrent
+// or io.SeekEnd) and returns the new position.
+func (a *Agent) FileSeek(handle int, offset int64, whence int) (int64, error) {
+	// Prepare the response.
+	var resp struct {
+		Position int64 `json:"position"`
+	}
+
+	args := map[string]any{
+		"handle": handle,
+		"offset": offset,
+		"whence": whence,
+	}
+
+	err := a.run("guest-file-seek", args, &resp)
+	if err != nil {
+		return -1, err
+	}
+
+	return resp.Position, nil
+}
+
+// FSFreeze freezes the guest filesystems and returns the number of frozen filesystems.
+func (a *Agent) FSFreeze() (int, error) {
+	var count int
+
+	err := a.run("guest-fsfreeze-freeze", nil, &count)
+	if err != nil {
+		return -1, err
+	}
+
+	return count, nil
+}
+
+// FSThaw thaws the guest filesystems and returns the number of thawed filesystems.
+func (a *Agent) FSThaw() (int, error) {
+	var count int
+
+	err := a.run("guest-fsfreeze-thaw", nil, &count)
+	if err != nil {
+		return -1, err
+	}
+
+	return count, nil
+}
+
+// NetworkGetInterfaces returns the guest network interfaces and their IP addresses.
+func (a *Agent) NetworkGetInterfaces() ([]NetworkInterface, error) {
+	var resp []NetworkInterface
+
+	err := a.run("guest-network-get-interfaces", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,14 @@
+package qga
+
+import (
+	"fmt"
+)
+
+// ErrAgentNotRunning is returned when the guest agent doesn't respond.
+var ErrAgentNotRunning = fmt.Errorf("QEMU guest agent isn't currently running")
+
+// ErrAgentBusy is returned when the guest agent is in use by another connection.
+var ErrAgentBusy = fmt.Errorf("QEMU guest agent is busy")
+
+// errResponseTooLarge is returned when a guest agent response exceeds maxResponseSize.
+var errResponseTooLarge = fmt.Errorf("QEMU guest agent response exceeds %d bytes", maxResponseSize)
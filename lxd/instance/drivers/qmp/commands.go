@@ -61,6 +61,14 @@ type HotpluggableCPU struct {
 	Props CPUInstanceProperties `json:"props"`
 }
 
+// Memdev contains information about a memory backend.
+type Memdev struct {
+	ID        string `json:"id,omitempty"`
+	Size      int64  `json:"size"`
+	Policy    string `json:"policy"`
+	HostNodes []int  `json:"host-nodes"`
+}
+
 // QueryCPUs returns a list of CPUs.
 func (m *Monitor) QueryCPUs() ([]CPU, error) {
 	// Prepare the response.
@@ -442,6 +450,38 @@ func (m *Monitor) GetMemorySizeBytes() (int64, error) {
 	return resp.Return.BaseMemory, nil
 }
 
+// GetPluggedMemorySizeBytes returns the current size of the hot-plugged memory in bytes.
+func (m *Monitor) GetPluggedMemorySizeBytes() (int64, error) {
+	// Prepare the response.
+	var resp struct {
+		Return struct {
+			PluggedMemory int64 `json:"plugged-memory"`
+		} `json:"return"`
+	}
+
+	err := m.run("query-memory-size-summary", nil, &resp)
+	if err != nil {
+		return -1, err
+	}
+
+	return resp.Return.PluggedMemory, nil
+}
+
+// QueryMemdev returns a list of memory backends.
+func (m *Monitor) QueryMemdev() ([]Memdev, error) {
+	// Prepare the response.
+	var resp struct {
+		Return []Memdev `json:"return"`
+	}
+
+	err := m.run("query-memdev", nil, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query memory backends: %w", err)
+	}
+
+	return resp.Return, nil
+}
+
 // GetMemoryBalloonSizeBytes returns effective size of the memory in bytes (considering the current balloon size).
 func (m *Monitor) GetMemoryBalloonSizeBytes() (int64, error) {
 	// Prepare the response.
@@ -746,6 +786,28 @@ func (m *Monitor) AddSecret(id string, secret string) error {
 	return nil
 }
 
+// AddObject adds a new object.
+func (m *Monitor) AddObject(object map[string]any) error {
+	err := m.run("object-add", object, nil)
+	if err != nil {
+		return fmt.Errorf("Failed adding object: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveObject removes an object.
+func (m *Monitor) RemoveObject(objectID string) error {
+	args := map[string]string{"id": objectID}
+
+	err := m.run("object-del", args, nil)
+	if err != nil {
+		return fmt.Errorf("Failed removing object: %w", err)
+	}
+
+	return nil
+}
+
 // AMDSEVCapabilities represents the SEV capabilities of QEMU.
 type AMDSEVCapabilities struct {
 	PDH             string `json:"pdh"`               // Platform Diffie-Hellman key (base64-encoded)
@@ -770,8 +832,8 @@ func (m *Monitor) SEVCapabilities() (AMDSEVCapabilities, error) {
 	return resp.Return, nil
 }
 
-// NBDServerStart starts internal NBD server and returns a connection to it.
-func (m *Monitor) NBDServerStart() (net.Conn, error) {
+// NBDServerStart starts internal NBD server and returns the requested number of connections to it.
+func (m *Monitor) NBDServerStart(connections int) ([]net.Conn, error) {
 	var args struct {
 		Addr struct {
 			Data struct {
@@ -796,20 +858,29 @@ func (m *Monitor) NBDServerStart() (net.Conn, error) {
 	args.Addr.Type = "unix"
 	args.Addr.Data.Path = strings.TrimPrefix(listenAddress, "@")
 	args.Addr.Data.Abstract = true
-	args.MaxConnections = 1
+	args.MaxConnections = connections
 
 	err = m.run("nbd-server-start", args, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	// Connect to the NBD server and return the connection.
-	conn, err := net.Dial("unix", listenAddress)
-	if err != nil {
-		return nil, fmt.Errorf("Failed connecting to NBD server: %w", err)
+	// Connect to the NBD server and return the connections.
+	conns := make([]net.Conn, 0, connections)
+	for i := 0; i < connections; i++ {
+		conn, err := net.Dial("unix", listenAddress)
+		if err != nil {
+			for _, conn := range conns {
+				_ = conn.Close()
+			}
+
+			return nil, fmt.Errorf("Failed connecting to NBD server: %w", err)
+		}
+
+		conns = append(conns, conn)
 	}
 
-	return conn, nil
+	return conns, nil
 }
 
 // NBDServerStop stops the internal NBD server.
@@ -1016,9 +1087,11 @@ func (m *Monitor) Eject(id string) error {
 }
 
 // SetBlockThrottle applies an I/O limit on a disk.
-func (m *Monitor) SetBlockThrottle(id string, bytesRead int, bytesWrite int, iopsRead int, iopsWrite int) error {
+// If group is set, the limits are shared by all the disks in the same throttle group.
+func (m *Monitor) SetBlockThrottle(id string, bytesRead int, bytesWrite int, iopsRead int, iopsWrite int, group string) error {
 	var args struct {
-		ID string `json:"id"`
+		ID    string `json:"id"`
+		Group string `json:"group,omitempty"`
 
 		Bytes      int `json:"bps"`
 		BytesRead  int `json:"bps_rd"`
@@ -1029,6 +1102,7 @@ func (m *Monitor) SetBlockThrottle(id string, bytesRead int, bytesWrite int, iop
 	}
 
 	args.ID = id
+	args.Group = group
 	args.BytesRead = bytesRead
 	args.BytesWrite = bytesWrite
 	args.IOPsRead = iopsRead
@@ -1041,3 +1115,19 @@ func (m *Monitor) SetBlockThrottle(id string, bytesRead int, bytesWrite int, iop
 
 	return nil
 }
+
+// Screendump captures the display into the specified file (in PPM format).
+func (m *Monitor) Screendump(filename string) error {
+	var args struct {
+		Filename string `json:"filename"`
+	}
+
+	args.Filename = filename
+
+	err := m.run("screendump", args, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -37,6 +37,13 @@ const HookStop = "onstop"
 const (
 	ConsoleTypeConsole = "console"
 	ConsoleTypeVGA     = "vga"
+	ConsoleTypeVNC     = "vnc"
+)
+
+// Possible values for the protocol of the VGA console.
+const (
+	ConsoleProtocolSPICE = "spice"
+	ConsoleProtocolVNC   = "vnc"
 )
 
 // TemplateTrigger trigger name.
@@ -110,7 +117,7 @@ type Instance interface {
 	FileSFTPConn() (net.Conn, error)
 	FileSFTP() (*sftp.Client, error)
 
-	// Console - Allocate and run a console tty or a spice Unix socket.
+	// Console - Allocate and run a console tty or a spice or VNC Unix socket.
 	Console(protocol string) (*os.File, chan error, error)
 	Exec(req api.InstanceExecPost, stdin *os.File, stdout *os.File, stderr *os.File) (Cmd, error)
 
@@ -198,6 +205,9 @@ type VM interface {
 	// UEFI vars handling.
 	UEFIVars() (*api.InstanceUEFIVars, error)
 	UEFIVarsUpdate(newUEFIVarsSet api.InstanceUEFIVars) error
+
+	// ConsoleScreenshot returns a PNG image of the display.
+	ConsoleScreenshot() ([]byte, error)
 }
 
 // CriuMigrationArgs arguments for CRIU migration.
@@ -3,10 +3,14 @@ package instancetype
 import (
 	"errors"
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/kballard/go-shellquote"
+
+	backupConfig "github.com/canonical/lxd/lxd/backup/config"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/units"
@@ -109,6 +113,38 @@ var HugePageSizeSuffix = [...]string{"64KB", "1MB", "2MB", "1GB"}
 
 // InstanceConfigKeysAny is a map of config key to validator. (keys applying to containers AND virtual machines).
 var InstanceConfigKeysAny = map[string]func(value string) error{
+	// lxdmeta:generate(entities=instance; group=backups; key=backups.schedule)
+	// Specify either a cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`), or `@never` or an empty value to disable automatic backups.
+	//
+	// ---
+	//  type: string
+	//  defaultdesc: empty
+	//  liveupdate: no
+	//  shortdesc: Schedule for automatic instance backups
+	"backups.schedule": validate.Optional(validate.IsCron([]string{"@hourly", "@daily", "@midnight", "@weekly", "@monthly", "@annually", "@yearly", "@never"})),
+
+	// lxdmeta:generate(entities=instance; group=backups; key=backups.expiry)
+	// Specify an expression like `1M 2H 3d 4w 5m 6y`.
+	// ---
+	//  type: string
+	//  liveupdate: no
+	//  shortdesc: When scheduled backups are to be deleted
+	"backups.expiry": func(value string) error {
+		// Validate expression
+		_, err := shared.GetExpiry(time.Time{}, value)
+		return err
+	},
+
+	// lxdmeta:generate(entities=instance; group=backups; key=backups.target)
+	// Specify `volume:<pool>/<volume>` to write scheduled backups to a custom storage volume, or `bucket:<pool>/<bucket>` to write them to a storage bucket.
+	// Leave empty to keep scheduled backups with the other backups of the instance.
+	// ---
+	//  type: string
+	//  defaultdesc: empty
+	//  liveupdate: no
+	//  shortdesc: Where to write scheduled backups to
+	"backups.target": validate.Optional(backupConfig.ValidateTarget),
+
 	// lxdmeta:generate(entities=instance; group=boot; key=boot.autostart)
 	// If set to `false`, restore the last state.
 	// ---
@@ -393,6 +429,47 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: Template for the snapshot name
 	"snapshots.pattern": validate.IsAny,
 
+	// lxdmeta:generate(entities=instance; group=snapshots; key=snapshots.consistency)
+	// Possible values are `crash` and `fsfreeze`.
+	// When set to `fsfreeze`, snapshots of running instances are coordinated with the guest: LXD runs {config:option}`instance-snapshots:snapshots.consistency.pre_hook` inside the instance and, for virtual machines, freezes the guest file systems through the `lxd-agent` (or the QEMU guest agent) while taking the snapshot.
+	// Afterwards, the file systems are thawed and {config:option}`instance-snapshots:snapshots.consistency.post_hook` is run.
+	// The consistency that was reached is recorded in the {config:option}`instance-volatile:volatile.snapshot.consistency` key of the snapshot.
+	//
+	// See {ref}`instance-options-snapshots-consistency` for more information.
+	// ---
+	//  type: string
+	//  defaultdesc: `crash`
+	//  liveupdate: yes
+	//  shortdesc: How to make snapshots of running instances consistent
+	"snapshots.consistency": validate.Optional(validate.IsOneOf("crash", "fsfreeze")),
+
+	// lxdmeta:generate(entities=instance; group=snapshots; key=snapshots.consistency.pre_hook)
+	// The command is run inside the instance before the snapshot is taken, when {config:option}`instance-snapshots:snapshots.consistency` is set to `fsfreeze`.
+	// If it fails or times out, the snapshot is only crash-consistent.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: Command to run in the instance before a snapshot
+	"snapshots.consistency.pre_hook": validate.Optional(isShellCommand),
+
+	// lxdmeta:generate(entities=instance; group=snapshots; key=snapshots.consistency.post_hook)
+	// The command is run inside the instance after the snapshot is taken, when {config:option}`instance-snapshots:snapshots.consistency` is set to `fsfreeze`.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: Command to run in the instance after a snapshot
+	"snapshots.consistency.post_hook": validate.Optional(isShellCommand),
+
+	// lxdmeta:generate(entities=instance; group=snapshots; key=snapshots.consistency.timeout)
+	// Number of seconds that each snapshot hook may run for, and that the guest file systems may stay frozen for.
+	// The value must be at least `1`.
+	// ---
+	//  type: integer
+	//  defaultdesc: `30`
+	//  liveupdate: yes
+	//  shortdesc: Timeout for the snapshot hooks and file system freeze
+	"snapshots.consistency.timeout": validate.Optional(validate.IsInRange(1, math.MaxUint32)),
+
 	// lxdmeta:generate(entities=instance; group=snapshots; key=snapshots.expiry)
 	// Specify an expression like `1M 2H 3d 4w 5m 6y`.
 	// ---
@@ -457,6 +534,14 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	"volatile.last_state.power": validate.IsAny,
 	"volatile.last_state.ready": validate.IsBool,
 	"volatile.apply_quota":      validate.IsAny,
+	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.snapshot.consistency)
+	// This key is only set on snapshots taken with {config:option}`instance-snapshots:snapshots.consistency` set to `fsfreeze`.
+	// It is `fsfreeze` if the guest file systems were frozen, `hooks` if only the pre-snapshot hook was run, and `crash` otherwise.
+	// ---
+	//  type: string
+	//  shortdesc: Consistency reached by the snapshot
+	"volatile.snapshot.consistency": validate.Optional(validate.IsOneOf("crash", "hooks", "fsfreeze")),
+
 	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.uuid)
 	// The instance UUID is globally unique across all servers and projects.
 	// ---
@@ -960,6 +1045,18 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 
 // InstanceConfigKeysVM is a map of config key to validator. (keys applying to VM only).
 var InstanceConfigKeysVM = map[string]func(value string) error{
+	// lxdmeta:generate(entities=instance; group=resource-limits; key=limits.memory.hotplug)
+	// Setting this option enables memory hotplug, which allows increasing {config:option}`instance-resource-limits:limits.memory` up to this size while the instance is running.
+	//
+	// See {ref}`instance-options-limits-memory-vm` for more information.
+	// ---
+	//  type: string
+	//  defaultdesc: empty (memory hotplug disabled)
+	//  liveupdate: no
+	//  condition: virtual machine
+	//  shortdesc: Maximum memory size that the instance can be grown to while running
+	"limits.memory.hotplug": validate.Optional(validate.IsSize),
+
 	// lxdmeta:generate(entities=instance; group=resource-limits; key=limits.memory.hugepages)
 	// If this option is set to `false`, regular system memory is used.
 	// ---
@@ -1088,6 +1185,20 @@ var InstanceConfigKeysVM = map[string]func(value string) error{
 	//  shortdesc: Whether to use the name and MTU of the default network interfaces
 	"agent.nic_config": validate.Optional(validate.IsBool),
 
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=agent.qemu_guest_agent)
+	// Set this option to `true` to add a channel for the QEMU guest agent (`qemu-guest-agent`) to the instance.
+	// If the `lxd-agent` isn't running, LXD then uses the QEMU guest agent to run commands, transfer files and report the guest IP addresses.
+	// It is also used to freeze the guest file systems during snapshots if {config:option}`instance-snapshots:snapshots.consistency` is set to `fsfreeze`.
+	//
+	// See {ref}`instances-qemu-guest-agent` for more information.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  liveupdate: no
+	//  condition: virtual machine
+	//  shortdesc: Whether to fall back to the QEMU guest agent when the `lxd-agent` isn't running
+	"agent.qemu_guest_agent": validate.Optional(validate.IsBool),
+
 	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.apply_nvram)
 	//
 	// ---
@@ -1159,6 +1270,26 @@ func ConfigKeyChecker(key string, instanceType Type) (func(value string) error,
 			return validate.IsAny, nil
 		}
 
+		// lxdmeta:generate(entities=instance; group=volatile; key=volatile.<name>.ipv4.address)
+		// The IPv4 address allocated to the network device from the ranges delegated to the project.
+		// It is used when no `ipv4.address` property is set on the device itself.
+		// ---
+		//  type: string
+		//  shortdesc: Network device allocated IPv4 address
+		if strings.HasSuffix(key, ".ipv4.address") {
+			return validate.Optional(validate.IsNetworkAddressV4), nil
+		}
+
+		// lxdmeta:generate(entities=instance; group=volatile; key=volatile.<name>.ipv6.address)
+		// The IPv6 address allocated to the network device from the ranges delegated to the project.
+		// It is used when no `ipv6.address` property is set on the device itself.
+		// ---
+		//  type: string
+		//  shortdesc: Network device allocated IPv6 address
+		if strings.HasSuffix(key, ".ipv6.address") {
+			return validate.Optional(validate.IsNetworkAddressV6), nil
+		}
+
 		// lxdmeta:generate(entities=instance; group=volatile; key=volatile.<name>.last_state.vdpa.name)
 		// The VDPA device name used when moving a VDPA device file descriptor into an instance.
 		// ---
@@ -1300,6 +1431,12 @@ func ConfigKeyChecker(key string, instanceType Type) (func(value string) error,
 	return nil, fmt.Errorf("Unknown configuration key: %s", key)
 }
 
+// isShellCommand validates whether the value can be split into a command and its arguments.
+func isShellCommand(value string) error {
+	_, err := shellquote.Split(value)
+	return err
+}
+
 // InstanceIncludeWhenCopying is used to decide whether to include a config item or not when copying an instance.
 // The remoteCopy argument indicates if the copy is remote (i.e between LXD nodes) as this affects the keys kept.
 func InstanceIncludeWhenCopying(configKey string, remoteCopy bool) bool {
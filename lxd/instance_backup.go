@@ -315,6 +315,29 @@ func instanceBackupsPost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(fmt.Errorf("Backup names may not contain slashes"))
 	}
 
+	// Validate the parent backup the backup is incremental from.
+	if req.IncrementalFrom != "" {
+		err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+			_, err := tx.GetInstanceBackup(ctx, projectName, name+shared.SnapshotDelimiter+req.IncrementalFrom)
+			return err
+		})
+		if err != nil {
+			if response.IsNotFoundError(err) {
+				return response.BadRequest(fmt.Errorf("Parent backup %q to create incremental backup from not found", req.IncrementalFrom))
+			}
+
+			return response.SmartError(err)
+		}
+	}
+
+	// Validate the S3 target.
+	if req.Target != nil {
+		err = backupS3Validate(req.Target)
+		if err != nil {
+			return response.BadRequest(err)
+		}
+	}
+
 	fullName := name + shared.SnapshotDelimiter + req.Name
 	instanceOnly := req.InstanceOnly || req.ContainerOnly
 
@@ -327,9 +350,10 @@ func instanceBackupsPost(d *Daemon, r *http.Request) response.Response {
 			InstanceOnly:         instanceOnly,
 			OptimizedStorage:     req.OptimizedStorage,
 			CompressionAlgorithm: req.CompressionAlgorithm,
+			IncrementalFrom:      req.IncrementalFrom,
 		}
 
-		err := backupCreate(s, args, inst, op)
+		err := backupCreate(s, args, inst, req.Target, op)
 		if err != nil {
 			return fmt.Errorf("Create backup: %w", err)
 		}
@@ -344,7 +368,9 @@ func instanceBackupsPost(d *Daemon, r *http.Request) response.Response {
 		resources["containers"] = resources["instances"]
 	}
 
-	resources["backups"] = []api.URL{*api.NewURL().Path(version.APIVersion, "instances", name, "backups", req.Name)}
+	if req.Target == nil {
+		resources["backups"] = []api.URL{*api.NewURL().Path(version.APIVersion, "instances", name, "backups", req.Name)}
+	}
 
 	op, err := operations.OperationCreate(s, projectName, operations.OperationClassTask,
 		operationtype.BackupCreate, resources, nil, backup, nil, nil, r)
@@ -61,6 +61,9 @@ type consoleWs struct {
 
 	// channel type (either console or vga)
 	protocol string
+
+	// console type used for the connections of the vga channel (either vga for SPICE or vnc)
+	vgaType string
 }
 
 // Metadata returns a map of metadata.
@@ -159,7 +162,7 @@ func (s *consoleWs) connectVGA(op *operations.Operation, r *http.Request, w http
 
 		logger.Debug("VGA dynamic websocket connected")
 
-		console, _, err := s.instance.Console("vga")
+		console, _, err := s.instance.Console(s.vgaType)
 		if err != nil {
 			_ = conn.Close()
 			return err
@@ -485,6 +488,22 @@ func instanceConsolePost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(fmt.Errorf("VGA console is only supported by virtual machines"))
 	}
 
+	vgaType := instance.ConsoleTypeVGA
+	if post.Protocol != "" {
+		if post.Type != instance.ConsoleTypeVGA {
+			return response.BadRequest(fmt.Errorf("Console protocol can only be set for the VGA console"))
+		}
+
+		switch post.Protocol {
+		case instance.ConsoleProtocolSPICE:
+			vgaType = instance.ConsoleTypeVGA
+		case instance.ConsoleProtocolVNC:
+			vgaType = instance.ConsoleTypeVNC
+		default:
+			return response.BadRequest(fmt.Errorf("Unknown console protocol %q", post.Protocol))
+		}
+	}
+
 	if !inst.IsRunning() {
 		return response.BadRequest(fmt.Errorf("Instance is not running"))
 	}
@@ -512,6 +531,7 @@ func instanceConsolePost(d *Daemon, r *http.Request) response.Response {
 	ws.width = post.Width
 	ws.height = post.Height
 	ws.protocol = post.Type
+	ws.vgaType = vgaType
 
 	resources := map[string][]api.URL{}
 	resources["instances"] = []api.URL{*api.NewURL().Path(version.APIVersion, "instances", ws.instance.Name())}
@@ -748,3 +768,86 @@ func instanceConsoleLogDelete(d *Daemon, r *http.Request) response.Response {
 
 	return response.SmartError(nil)
 }
+
+// swagger:operation GET /1.0/instances/{name}/console/screenshot instances instance_console_screenshot_get
+//
+//	Get a screenshot of the display
+//
+//	Captures the display of a running virtual machine.
+//
+//	---
+//	produces:
+//	  - image/png
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	     description: PNG image of the display
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceConsoleScreenshotGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if shared.IsSnapshot(name) {
+		return response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	// Forward the request if the instance is remote.
+	resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, name, instanceType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if resp != nil {
+		return resp
+	}
+
+	inst, err := instance.LoadByProjectAndName(s, projectName, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	vm, ok := inst.(instance.VM)
+	if !ok {
+		return response.BadRequest(fmt.Errorf("Screenshots are only supported by virtual machines"))
+	}
+
+	if !vm.IsRunning() {
+		return response.BadRequest(fmt.Errorf("Instance is not running"))
+	}
+
+	screenshot, err := vm.ConsoleScreenshot()
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.ManualResponse(func(w http.ResponseWriter) error {
+		w.Header().Set("Content-Type", "image/png")
+		w.Header().Set("Content-Length", strconv.Itoa(len(screenshot)))
+
+		_, err := w.Write(screenshot)
+
+		return err
+	})
+}
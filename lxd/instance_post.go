@@ -20,6 +20,7 @@ import (
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/project/limits"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
@@ -825,6 +826,14 @@ func instancePostClusteringMigrate(s *state.State, r *http.Request, srcPool stor
 			return err
 		}
 
+		// Delete the custom volumes from local storage pools that were mirrored to the destination.
+		if live && srcInst.Type() == instancetype.VM {
+			err = instancePostClusteringMigrateDeleteLocalVolumes(s, srcInst)
+			if err != nil {
+				return err
+			}
+		}
+
 		// Cleanup instance paths on source member if using remote shared storage.
 		if srcPool.Driver().Info().Remote {
 			err = srcPool.CleanupInstancePaths(srcInst, nil)
@@ -874,6 +883,38 @@ func instancePostClusteringMigrate(s *state.State, r *http.Request, srcPool stor
 	return run, nil
 }
 
+// instancePostClusteringMigrateDeleteLocalVolumes deletes the custom volumes from local storage pools used by a
+// virtual machine on the source member once they have been mirrored to the destination member by live migration.
+func instancePostClusteringMigrateDeleteLocalVolumes(s *state.State, inst instance.Instance) error {
+	// Derive the effective storage project name from the instance config's project.
+	storageProjectName, err := project.StorageVolumeProject(s.DB.Cluster, inst.Project().Name, dbCluster.StoragePoolVolumeTypeCustom)
+	if err != nil {
+		return err
+	}
+
+	for _, dev := range inst.ExpandedDevices().Sorted() {
+		if dev.Config["type"] != "disk" || dev.Config["path"] == "/" || dev.Config["pool"] == "" {
+			continue
+		}
+
+		pool, err := storagePools.LoadByName(s, dev.Config["pool"])
+		if err != nil {
+			return fmt.Errorf("Failed loading storage pool: %w", err)
+		}
+
+		if pool.Driver().Info().Remote {
+			continue
+		}
+
+		err = pool.DeleteCustomVolume(storageProjectName, dev.Config["source"], nil)
+		if err != nil {
+			return fmt.Errorf("Failed deleting custom volume %q on source member: %w", dev.Config["source"], err)
+		}
+	}
+
+	return nil
+}
+
 // instancePostClusteringMigrateWithRemoteStorage handles moving a remote shared storage instance from a source member that is offline.
 // This function must be run on the target cluster member to move the instance to.
 func instancePostClusteringMigrateWithRemoteStorage(s *state.State, r *http.Request, srcPool storagePools.Pool, srcInst instance.Instance, newInstName string, newMember db.NodeInfo, stateful bool) (func(op *operations.Operation) error, error) {
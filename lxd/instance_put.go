@@ -228,6 +228,9 @@ func instanceSnapRestore(s *state.State, projectName string, name string, snap s
 	// Generate a new `volatile.uuid.generation` to differentiate this instance restored from a snapshot from the original instance.
 	source.LocalConfig()["volatile.uuid.generation"] = uuid.New().String()
 
+	// The snapshot consistency only describes the snapshot itself.
+	delete(source.LocalConfig(), "volatile.snapshot.consistency")
+
 	err = inst.Restore(source, stateful)
 	if err != nil {
 		return err
@@ -153,6 +153,16 @@ var instanceConsoleCmd = APIEndpoint{
 	Delete: APIEndpointAction{Handler: instanceConsoleLogDelete, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanEdit, "name")},
 }
 
+var instanceConsoleScreenshotCmd = APIEndpoint{
+	Name: "instanceConsoleScreenshot",
+	Path: "instances/{name}/console/screenshot",
+	Aliases: []APIEndpointAlias{
+		{Name: "vmConsoleScreenshot", Path: "virtual-machines/{name}/console/screenshot"},
+	},
+
+	Get: APIEndpointAction{Handler: instanceConsoleScreenshotGet, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanAccessConsole, "name")},
+}
+
 var instanceExecCmd = APIEndpoint{
 	Name: "instanceExec",
 	Path: "instances/{name}/exec",
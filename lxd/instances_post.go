@@ -26,6 +26,7 @@ import (
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/instance/operationlock"
+	"github.com/canonical/lxd/lxd/lifecycle"
 	"github.com/canonical/lxd/lxd/operations"
 	"github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/project/limits"
@@ -708,14 +709,75 @@ func createFromBackup(s *state.State, r *http.Request, projectName string, data
 		backupFile = tarFile
 	}
 
+	revert.Success()
+
+	return createFromBackupData(s, r, projectName, backupFile, backupFile.Name(), pool, instanceName, devices)
+}
+
+// createFromBackupS3 creates an instance from a backup stored in an S3 object.
+// The backup is read directly from the object, without being stored on the server first.
+func createFromBackupS3(s *state.State, r *http.Request, projectName string, req *api.InstancesPost) response.Response {
+	if req.Source.Backup == nil {
+		return response.BadRequest(fmt.Errorf("Must specify the S3 backup to import"))
+	}
+
+	err := backupS3Validate(req.Source.Backup)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	object, err := backupS3Open(s, req.Source.Backup)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Use the storage pool of the root disk if specified.
+	var pool string
+	_, rootDev, err := instancetype.GetRootDiskDevice(req.Devices)
+	if err == nil {
+		pool = rootDev["pool"]
+	}
+
+	// Squashfs backups can only be converted from a file, so store them on the server first.
+	_, algo, _, err := shared.DetectCompressionFile(object)
+	if err != nil {
+		_ = object.Close()
+		return response.BadRequest(err)
+	}
+
+	if algo == ".squashfs" {
+		defer func() { _ = object.Close() }()
+
+		_, err = object.Seek(0, io.SeekStart)
+		if err != nil {
+			return response.InternalError(err)
+		}
+
+		return createFromBackup(s, r, projectName, object, pool, req.Name, req.Devices)
+	}
+
+	// The output path is only used to name the AppArmor profile of the unpacker.
+	outputPath := shared.VarPath("backups", fmt.Sprintf("%s_s3_%s", backup.WorkingDirPrefix, uuid.New().String()))
+
+	return createFromBackupData(s, r, projectName, object, outputPath, pool, req.Name, req.Devices)
+}
+
+// createFromBackupData creates an instance from the backup read from backupFile, which is closed once done.
+// The outputPath is passed to the unpacker of the backup.
+func createFromBackupData(s *state.State, r *http.Request, projectName string, backupFile io.ReadSeekCloser, outputPath string, pool string, instanceName string, devices map[string]map[string]string) response.Response {
+	revert := revert.New()
+	defer revert.Fail()
+
+	revert.Add(func() { _ = backupFile.Close() })
+
 	// Parse the backup information.
-	_, err = backupFile.Seek(0, io.SeekStart)
+	_, err := backupFile.Seek(0, io.SeekStart)
 	if err != nil {
 		return response.InternalError(err)
 	}
 
 	logger.Debug("Reading backup file info")
-	bInfo, err := backup.GetInfo(backupFile, s.OS, backupFile.Name())
+	bInfo, err := backup.GetInfo(backupFile, s.OS, outputPath)
 	if err != nil {
 		return response.BadRequest(err)
 	}
@@ -730,6 +792,11 @@ func createFromBackup(s *state.State, r *http.Request, projectName string, data
 			Type:        api.InstanceType(bInfo.Config.Container.Type),
 		}
 
+		// Incremental backups are applied to an existing instance.
+		if bInfo.IncrementalFrom != "" {
+			return nil
+		}
+
 		return limits.AllowInstanceCreation(s.GlobalConfig, tx, projectName, req)
 	})
 	if err != nil {
@@ -761,13 +828,14 @@ func createFromBackup(s *state.State, r *http.Request, projectName string, data
 	}
 
 	logger.Debug("Backup file info loaded", logger.Ctx{
-		"type":      bInfo.Type,
-		"name":      bInfo.Name,
-		"project":   bInfo.Project,
-		"backend":   bInfo.Backend,
-		"pool":      bInfo.Pool,
-		"optimized": *bInfo.OptimizedStorage,
-		"snapshots": bInfo.Snapshots,
+		"type":            bInfo.Type,
+		"name":            bInfo.Name,
+		"project":         bInfo.Project,
+		"backend":         bInfo.Backend,
+		"pool":            bInfo.Pool,
+		"optimized":       *bInfo.OptimizedStorage,
+		"snapshots":       bInfo.Snapshots,
+		"incrementalFrom": bInfo.IncrementalFrom,
 	})
 
 	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
@@ -814,6 +882,17 @@ func createFromBackup(s *state.State, r *http.Request, projectName string, data
 		defer func() { _ = backupFile.Close() }()
 		defer runRevert.Fail()
 
+		// Apply incremental backups on top of the existing instance.
+		if bInfo.IncrementalFrom != "" {
+			err := instanceRefreshFromBackup(s, *bInfo, backupFile, pool, op)
+			if err != nil {
+				return err
+			}
+
+			runRevert.Success()
+			return nil
+		}
+
 		pool, err := storagePools.LoadByName(s, bInfo.Pool)
 		if err != nil {
 			return err
@@ -875,6 +954,112 @@ func createFromBackup(s *state.State, r *http.Request, projectName string, data
 	return operations.OperationResponse(op)
 }
 
+// instanceRefreshFromBackup applies an incremental backup on top of an existing instance.
+// If poolName is set, it must match the storage pool of the instance.
+func instanceRefreshFromBackup(s *state.State, bInfo backup.Info, backupFile io.ReadSeeker, poolName string, op *operations.Operation) error {
+	revert := revert.New()
+	defer revert.Fail()
+
+	inst, err := instance.LoadByProjectAndName(s, bInfo.Project, bInfo.Name)
+	if err != nil {
+		return fmt.Errorf("Failed loading instance to apply incremental backup to: %w", err)
+	}
+
+	// Prevent the instance from being started or updated while the backup is applied to it.
+	instOp, err := operationlock.Create(inst.Project().Name, inst.Name(), operationlock.ActionUpdate, false, false)
+	if err != nil {
+		return fmt.Errorf("Failed to create instance update operation: %w", err)
+	}
+
+	defer instOp.Done(nil)
+
+	pool, err := storagePools.LoadByInstance(s, inst)
+	if err != nil {
+		return fmt.Errorf("Failed loading instance storage pool: %w", err)
+	}
+
+	if poolName != "" && pool.Name() != poolName {
+		return fmt.Errorf("Incremental backup must be applied to the storage pool %q of the instance", pool.Name())
+	}
+
+	// Check if the backup is optimized that the source pool driver matches the target pool driver.
+	if *bInfo.OptimizedStorage && pool.Driver().Info().Name != bInfo.Backend {
+		return fmt.Errorf("Optimized backup storage driver %q differs from the target storage pool driver %q", bInfo.Backend, pool.Driver().Info().Name)
+	}
+
+	// Apply the changes to the storage volume and create the new snapshot volumes.
+	revertHook, err := pool.RefreshInstanceFromBackup(inst, bInfo, backupFile, op)
+	if err != nil {
+		return fmt.Errorf("Refresh instance from backup: %w", err)
+	}
+
+	revert.Add(revertHook)
+
+	// Create the database records of the new instance snapshots.
+	for _, snap := range bInfo.Config.Snapshots {
+		snapInstName := inst.Name() + shared.SnapshotDelimiter + snap.Name
+
+		arch, err := osarch.ArchitectureId(snap.Architecture)
+		if err != nil {
+			return err
+		}
+
+		var profiles []api.Profile
+		err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			profiles, err = tx.GetProfiles(ctx, bInfo.Project, snap.Profiles)
+
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("Failed loading profiles for instance snapshot %q: %w", snapInstName, err)
+		}
+
+		// Add root device if needed.
+		if snap.Devices == nil {
+			snap.Devices = make(map[string]map[string]string, 0)
+		}
+
+		if snap.ExpandedDevices == nil {
+			snap.ExpandedDevices = make(map[string]map[string]string, 0)
+		}
+
+		internalImportRootDevicePopulate(pool.Name(), snap.Devices, snap.ExpandedDevices, profiles)
+
+		_, snapInstOp, cleanup, err := instance.CreateInternal(s, db.InstanceArgs{
+			Project:      bInfo.Project,
+			Architecture: arch,
+			BaseImage:    snap.Config["volatile.base_image"],
+			Config:       snap.Config,
+			CreationDate: snap.CreatedAt,
+			Type:         inst.Type(),
+			Snapshot:     true,
+			Devices:      deviceConfig.NewDevices(snap.Devices),
+			Ephemeral:    snap.Ephemeral,
+			LastUsedDate: snap.LastUsedAt,
+			Name:         snapInstName,
+			Profiles:     profiles,
+			Stateful:     snap.Stateful,
+		}, true)
+		if err != nil {
+			return fmt.Errorf("Failed creating instance snapshot record %q: %w", snap.Name, err)
+		}
+
+		revert.Add(cleanup)
+		snapInstOp.Done(nil)
+	}
+
+	// Save the new snapshots to the on-disk backup.yaml file.
+	err = inst.UpdateBackupFile()
+	if err != nil {
+		return fmt.Errorf("Failed updating backup file: %w", err)
+	}
+
+	revert.Success()
+	s.Events.SendLifecycle(bInfo.Project, lifecycle.InstanceRestored.Event(inst, map[string]any{"incremental_from": bInfo.IncrementalFrom}))
+
+	return nil
+}
+
 // setupInstanceArgs sets the database instance arguments and determines the storage pool to use.
 func setupInstanceArgs(s *state.State, instType instancetype.Type, projectName string, profiles []api.Profile, req *api.InstancesPost) (storagePool string, instArgs *db.InstanceArgs, resp response.Response) {
 	// Parse the architecture name
@@ -1045,6 +1230,11 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 		req.Config = map[string]string{}
 	}
 
+	// Backups stored in S3 are imported like uploaded backup files.
+	if req.Source.Type == "backup" {
+		return createFromBackupS3(s, r, targetProjectName, &req)
+	}
+
 	if req.InstanceType != "" {
 		conf, err := instanceParseType(req.InstanceType)
 		if err != nil {
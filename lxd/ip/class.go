@@ -11,15 +11,46 @@ type Class struct {
 	Classid string
 }
 
+// Delete deletes class from a node.
+func (class *Class) Delete() error {
+	_, err := shared.RunCommand("tc", "class", "del", "dev", class.Dev, "classid", class.Classid)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Stats returns the statistics of the class.
+func (class *Class) Stats() (*Stats, error) {
+	return tcStats("class", "show", "dev", class.Dev, "classid", class.Classid)
+}
+
+// Children returns the class IDs of the child classes of the class.
+func (class *Class) Children() ([]string, error) {
+	objects, err := tcShow("class", "show", "dev", class.Dev, "parent", class.Classid)
+	if err != nil {
+		return nil, err
+	}
+
+	classids := make([]string, 0, len(objects))
+	for _, object := range objects {
+		classids = append(classids, object.Handle)
+	}
+
+	return classids, nil
+}
+
 // ClassHTB represents htb qdisc class object.
 type ClassHTB struct {
 	Class
-	Rate string
+	Rate    string
+	Ceil    string
+	Quantum string
 }
 
-// Add adds class to a node.
-func (class *ClassHTB) Add() error {
-	cmd := []string{"class", "add", "dev", class.Dev, "parent", class.Parent}
+func (class *ClassHTB) mainCmd(action string) []string {
+	cmd := []string{"class", action, "dev", class.Dev, "parent", class.Parent}
 	if class.Classid != "" {
 		cmd = append(cmd, "classid", class.Classid)
 	}
@@ -29,7 +60,30 @@ func (class *ClassHTB) Add() error {
 		cmd = append(cmd, "rate", class.Rate)
 	}
 
-	_, err := shared.RunCommand("tc", cmd...)
+	if class.Ceil != "" {
+		cmd = append(cmd, "ceil", class.Ceil)
+	}
+
+	if class.Quantum != "" {
+		cmd = append(cmd, "quantum", class.Quantum)
+	}
+
+	return cmd
+}
+
+// Add adds class to a node.
+func (class *ClassHTB) Add() error {
+	_, err := shared.RunCommand("tc", class.mainCmd("add")...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Replace adds class to a node or updates it if it already exists.
+func (class *ClassHTB) Replace() error {
+	_, err := shared.RunCommand("tc", class.mainCmd("replace")...)
 	if err != nil {
 		return err
 	}
@@ -39,6 +39,31 @@ func (a *ActionPolice) AddAction() []string {
 	return result
 }
 
+// ActionSkbedit represents an action of 'skbedit' type.
+type ActionSkbedit struct {
+	Priority string
+}
+
+// AddAction generates a part of command specific for 'skbedit' action.
+func (a *ActionSkbedit) AddAction() []string {
+	result := []string{"action", "skbedit"}
+	if a.Priority != "" {
+		result = append(result, "priority", a.Priority)
+	}
+
+	return result
+}
+
+// ActionMirred represents an action of 'mirred' type redirecting packets to the egress of another device.
+type ActionMirred struct {
+	Dev string
+}
+
+// AddAction generates a part of command specific for 'mirred' action.
+func (a *ActionMirred) AddAction() []string {
+	return []string{"action", "mirred", "egress", "redirect", "dev", a.Dev}
+}
+
 // Filter represents filter object.
 type Filter struct {
 	Dev      string
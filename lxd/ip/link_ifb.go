@@ -0,0 +1,11 @@
+package ip
+
+// IFB represents arguments for link device of type ifb.
+type IFB struct {
+	Link
+}
+
+// Add adds new virtual link.
+func (i *IFB) Add() error {
+	return i.Link.add("ifb", nil)
+}
@@ -10,6 +10,7 @@ type Qdisc struct {
 	Handle  string
 	Root    bool
 	Ingress bool
+	Clsact  bool
 }
 
 func (qdisc *Qdisc) mainCmd() []string {
@@ -26,6 +27,10 @@ func (qdisc *Qdisc) mainCmd() []string {
 		cmd = append(cmd, "ingress")
 	}
 
+	if qdisc.Clsact {
+		cmd = append(cmd, "clsact")
+	}
+
 	return cmd
 }
 
@@ -51,6 +56,10 @@ func (qdisc *Qdisc) Delete() error {
 		cmd = append(cmd, "ingress")
 	}
 
+	if qdisc.Clsact {
+		cmd = append(cmd, "clsact")
+	}
+
 	_, err := shared.RunCommand("tc", cmd...)
 	if err != nil {
 		return err
@@ -59,6 +68,21 @@ func (qdisc *Qdisc) Delete() error {
 	return nil
 }
 
+// Stats returns the statistics of the qdisc.
+func (qdisc *Qdisc) Stats() (*Stats, error) {
+	cmd := []string{"qdisc", "show", "dev", qdisc.Dev}
+	if qdisc.Root {
+		cmd = append(cmd, "root")
+	}
+
+	// The clsact qdisc is attached at the same place as the ingress qdisc.
+	if qdisc.Ingress || qdisc.Clsact {
+		cmd = append(cmd, "ingress")
+	}
+
+	return tcStats(cmd...)
+}
+
 // QdiscHTB represents the hierarchy token bucket qdisc object.
 type QdiscHTB struct {
 	Qdisc
@@ -28,7 +28,11 @@ func (r *Route) Add() error {
 		cmd = append(cmd, "via", r.Via)
 	}
 
-	cmd = append(cmd, r.Route, "dev", r.DevName)
+	cmd = append(cmd, r.Route)
+	if r.DevName != "" {
+		cmd = append(cmd, "dev", r.DevName)
+	}
+
 	if r.Src != "" {
 		cmd = append(cmd, "src", r.Src)
 	}
@@ -47,7 +51,21 @@ func (r *Route) Add() error {
 
 // Delete deletes routing table.
 func (r *Route) Delete() error {
-	_, err := shared.RunCommand("ip", r.Family, "route", "delete", "table", r.Table, r.Route, "dev", r.DevName)
+	cmd := []string{r.Family, "route", "delete"}
+	if r.Table != "" {
+		cmd = append(cmd, "table", r.Table)
+	}
+
+	cmd = append(cmd, r.Route)
+	if r.DevName != "" {
+		cmd = append(cmd, "dev", r.DevName)
+	}
+
+	if r.Proto != "" {
+		cmd = append(cmd, "proto", r.Proto)
+	}
+
+	_, err := shared.RunCommand("ip", cmd...)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,54 @@
+package ip
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/canonical/lxd/shared"
+)
+
+// Stats represents the statistics of a traffic control object.
+type Stats struct {
+	Bytes      uint64 `json:"bytes"`
+	Packets    uint64 `json:"packets"`
+	Drops      uint64 `json:"drops"`
+	Overlimits uint64 `json:"overlimits"`
+}
+
+// tcObject represents a traffic control object as returned by the JSON output of tc.
+type tcObject struct {
+	Stats
+
+	Handle string `json:"handle"`
+	Parent string `json:"parent"`
+}
+
+// tcShow returns the traffic control objects matching the specified tc show command.
+func tcShow(args ...string) ([]tcObject, error) {
+	out, err := shared.RunCommand("tc", append([]string{"-s", "-j"}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []tcObject
+	err = json.Unmarshal([]byte(out), &objects)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing tc output: %w", err)
+	}
+
+	return objects, nil
+}
+
+// tcStats returns the statistics of the traffic control object matching the specified tc show command.
+func tcStats(args ...string) (*Stats, error) {
+	objects, err := tcShow(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("Traffic control object not found")
+	}
+
+	return &objects[0].Stats, nil
+}
@@ -0,0 +1,35 @@
+package lifecycle
+
+import (
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// Internal copy of the network address group interface.
+type networkAddressGroup interface {
+	Info() *api.NetworkAddressGroup
+	Project() string
+}
+
+// NetworkAddressGroupAction represents a lifecycle event action for network address groups.
+type NetworkAddressGroupAction string
+
+// All supported lifecycle events for network address groups.
+const (
+	NetworkAddressGroupCreated = NetworkAddressGroupAction(api.EventLifecycleNetworkAddressGroupCreated)
+	NetworkAddressGroupDeleted = NetworkAddressGroupAction(api.EventLifecycleNetworkAddressGroupDeleted)
+	NetworkAddressGroupUpdated = NetworkAddressGroupAction(api.EventLifecycleNetworkAddressGroupUpdated)
+	NetworkAddressGroupRenamed = NetworkAddressGroupAction(api.EventLifecycleNetworkAddressGroupRenamed)
+)
+
+// Event creates the lifecycle event for an action on a network address group.
+func (a NetworkAddressGroupAction) Event(n networkAddressGroup, requestor *api.EventLifecycleRequestor, ctx map[string]any) api.EventLifecycle {
+	u := api.NewURL().Path(version.APIVersion, "network-address-groups", n.Info().Name).Project(n.Project())
+
+	return api.EventLifecycle{
+		Action:    string(a),
+		Source:    u.String(),
+		Context:   ctx,
+		Requestor: requestor,
+	}
+}
@@ -0,0 +1,49 @@
+package lifecycle
+
+import (
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// NetworkIPRangeAction represents a lifecycle event action for network IP ranges.
+type NetworkIPRangeAction string
+
+// All supported lifecycle events for network IP ranges.
+const (
+	NetworkIPRangeCreated = NetworkIPRangeAction(api.EventLifecycleNetworkIPRangeCreated)
+	NetworkIPRangeDeleted = NetworkIPRangeAction(api.EventLifecycleNetworkIPRangeDeleted)
+	NetworkIPRangeUpdated = NetworkIPRangeAction(api.EventLifecycleNetworkIPRangeUpdated)
+)
+
+// Event creates the lifecycle event for an action on a network IP range.
+func (a NetworkIPRangeAction) Event(n network, rangeName string, requestor *api.EventLifecycleRequestor, ctx map[string]any) api.EventLifecycle {
+	u := api.NewURL().Path(version.APIVersion, "networks", n.Name(), "ip-ranges", rangeName).Project(n.Project())
+
+	return api.EventLifecycle{
+		Action:    string(a),
+		Source:    u.String(),
+		Context:   ctx,
+		Requestor: requestor,
+	}
+}
+
+// NetworkIPAllocationAction represents a lifecycle event action for network IP allocations.
+type NetworkIPAllocationAction string
+
+// All supported lifecycle events for network IP allocations.
+const (
+	NetworkIPAllocationCreated = NetworkIPAllocationAction(api.EventLifecycleNetworkIPAllocationCreated)
+	NetworkIPAllocationDeleted = NetworkIPAllocationAction(api.EventLifecycleNetworkIPAllocationDeleted)
+)
+
+// Event creates the lifecycle event for an action on a network IP allocation.
+func (a NetworkIPAllocationAction) Event(n network, address string, requestor *api.EventLifecycleRequestor, ctx map[string]any) api.EventLifecycle {
+	u := api.NewURL().Path(version.APIVersion, "networks", n.Name(), "ip-allocations", address).Project(n.Project())
+
+	return api.EventLifecycle{
+		Action:    string(a),
+		Source:    u.String(),
+		Context:   ctx,
+		Requestor: requestor,
+	}
+}
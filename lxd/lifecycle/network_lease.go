@@ -0,0 +1,27 @@
+package lifecycle
+
+import (
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// NetworkLeaseAction represents a lifecycle event action for network leases.
+type NetworkLeaseAction string
+
+// All supported lifecycle events for network leases.
+const (
+	NetworkLeaseCreated = NetworkLeaseAction(api.EventLifecycleNetworkLeaseCreated)
+	NetworkLeaseDeleted = NetworkLeaseAction(api.EventLifecycleNetworkLeaseDeleted)
+)
+
+// Event creates the lifecycle event for an action on a network lease.
+func (a NetworkLeaseAction) Event(n network, requestor *api.EventLifecycleRequestor, ctx map[string]any) api.EventLifecycle {
+	u := api.NewURL().Path(version.APIVersion, "networks", n.Name(), "leases").Project(n.Project())
+
+	return api.EventLifecycle{
+		Action:    string(a),
+		Source:    u.String(),
+		Context:   ctx,
+		Requestor: requestor,
+	}
+}
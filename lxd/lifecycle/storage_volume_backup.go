@@ -12,6 +12,7 @@ type StorageVolumeBackupAction string
 const (
 	StorageVolumeBackupCreated   = StorageVolumeBackupAction(api.EventLifecycleStorageVolumeBackupCreated)
 	StorageVolumeBackupDeleted   = StorageVolumeBackupAction(api.EventLifecycleStorageVolumeBackupDeleted)
+	StorageVolumeBackupFailed    = StorageVolumeBackupAction(api.EventLifecycleStorageVolumeBackupFailed)
 	StorageVolumeBackupRetrieved = StorageVolumeBackupAction(api.EventLifecycleStorageVolumeBackupRetrieved)
 	StorageVolumeBackupRenamed   = StorageVolumeBackupAction(api.EventLifecycleStorageVolumeBackupRenamed)
 )
@@ -302,7 +302,7 @@ func (c *Client) HandleEvent(event api.Event) {
 		}
 
 		entry.Line = fmt.Sprintf("%s%s", messagePrefix, lifecycleEvent.Action)
-	} else if event.Type == api.EventTypeLogging || event.Type == api.EventTypeOVN {
+	} else if event.Type == api.EventTypeLogging || event.Type == api.EventTypeOVN || event.Type == api.EventTypeNetworkACL {
 		logEvent := api.EventLogging{}
 
 		err := json.Unmarshal(event.Metadata, &logEvent)
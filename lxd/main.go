@@ -118,6 +118,10 @@ func main() {
 	callhookCmd := cmdCallhook{global: &globalCmd}
 	app.AddCommand(callhookCmd.Command())
 
+	// firewall sub-command
+	firewallCmd := cmdFirewall{global: &globalCmd}
+	app.AddCommand(firewallCmd.Command())
+
 	// forkconsole sub-command
 	forkconsoleCmd := cmdForkconsole{global: &globalCmd}
 	app.AddCommand(forkconsoleCmd.Command())
@@ -141,9 +141,16 @@ func (c *cmdActivateifneeded) Run(cmd *cobra.Command, args []string) error {
 			_, err := lxd.ConnectLXDUnix("", nil)
 			return err
 		}
+
+		// Check for scheduled instance backups
+		if config["backups.schedule"] != "" {
+			logger.Debugf("Daemon has scheduled instance backups, activating...")
+			_, err := lxd.ConnectLXDUnix("", nil)
+			return err
+		}
 	}
 
-	// Check for scheduled volume snapshots
+	// Check for scheduled volume snapshots and backups
 	var volumes []db.StorageVolumeArgs
 	err = d.State().DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 		volumes, err = tx.GetStoragePoolVolumesWithType(ctx, cluster.StoragePoolVolumeTypeCustom, false)
@@ -163,6 +170,12 @@ func (c *cmdActivateifneeded) Run(cmd *cobra.Command, args []string) error {
 			_, err := lxd.ConnectLXDUnix("", nil)
 			return err
 		}
+
+		if vol.Config["backups.schedule"] != "" {
+			logger.Debugf("Daemon has scheduled volume backups, activating...")
+			_, err := lxd.ConnectLXDUnix("", nil)
+			return err
+		}
 	}
 
 	logger.Debugf("No need to start the daemon now")
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/lxd/firewall"
+)
+
+type cmdFirewall struct {
+	global *cmdGlobal
+}
+
+// Command returns a subcommand for inspecting the firewall rules applied by LXD.
+func (c *cmdFirewall) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = "firewall"
+	cmd.Short = "Low-level firewall diagnostic commands"
+	cmd.Long = `Description:
+  Low level tools for inspecting the firewall rules applied by LXD.
+`
+	// Check
+	firewallCheck := cmdFirewallCheck{global: c.global}
+	cmd.AddCommand(firewallCheck.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+type cmdFirewallCheck struct {
+	global *cmdGlobal
+
+	flagReapply bool
+}
+
+// Command returns a command for checking the live firewall rules.
+func (c *cmdFirewallCheck) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = "check"
+	cmd.Short = "Check that the firewall rules applied by LXD are still in place"
+	cmd.Long = `Description:
+  Check that the firewall rules applied by LXD are still in place
+
+  This compares the rules applied for each network and instance device
+  with the live firewall rules and lists the ones that are missing,
+  for example because they were flushed by another tool.
+
+  The command fails if rules are missing, unless --reapply is used in
+  which case the missing rules are reapplied.
+`
+	cmd.RunE = c.Run
+	cmd.Flags().BoolVar(&c.flagReapply, "reapply", false, "Reapply the missing rules")
+
+	return cmd
+}
+
+// Run executes the command for checking the live firewall rules.
+func (c *cmdFirewallCheck) Run(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("Unexpected arguments: %v", args)
+	}
+
+	d, err := lxd.ConnectLXDUnix("", nil)
+	if err != nil {
+		return err
+	}
+
+	method := http.MethodGet
+	if c.flagReapply {
+		method = http.MethodPost
+	}
+
+	response, _, err := d.RawQuery(method, "/internal/firewall/check", nil, "")
+	if err != nil {
+		return fmt.Errorf("Failed checking firewall rules: %w", err)
+	}
+
+	var drifts []firewall.Drift
+	err = json.Unmarshal(response.Metadata, &drifts)
+	if err != nil {
+		return fmt.Errorf("Failed parsing firewall check result: %w", err)
+	}
+
+	if len(drifts) == 0 {
+		fmt.Println("No missing firewall rules")
+		return nil
+	}
+
+	for _, drift := range drifts {
+		fmt.Printf("Missing firewall rules of %s:\n", drift.String())
+		for _, rule := range drift.Missing {
+			fmt.Printf("  %s\n", rule)
+		}
+	}
+
+	if c.flagReapply {
+		fmt.Println("Missing firewall rules have been reapplied")
+		return nil
+	}
+
+	return fmt.Errorf("Found %d networks or instance devices with missing firewall rules", len(drifts))
+}
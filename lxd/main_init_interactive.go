@@ -762,6 +762,12 @@ func (c *cmdInit) askStoragePool(config *api.InitPreseed, d lxd.InstanceServer,
 				if err != nil {
 					return err
 				}
+			} else if pool.Driver == "nfs" {
+				// Ask for the export or shared path
+				pool.Config["source"], err = c.global.asker.AskString("NFS export (host:/path) or path to the shared file system: ", "", nil)
+				if err != nil {
+					return err
+				}
 			} else {
 				useEmptyBlockDev, err := c.global.asker.AskBool("Would you like to use an existing empty block device (e.g. a disk or partition)? (yes/no) [default=no]: ", "no")
 				if err != nil {
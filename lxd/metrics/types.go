@@ -126,6 +126,10 @@ const (
 	MemoryUnevictableBytes
 	// MemoryWritebackBytes represents the amount of memory queued for syncing to disk.
 	MemoryWritebackBytes
+	// NetworkACLRuleBytesTotal represents the amount of bytes matched by a given network ACL rule.
+	NetworkACLRuleBytesTotal
+	// NetworkACLRulePacketsTotal represents the amount of packets matched by a given network ACL rule.
+	NetworkACLRulePacketsTotal
 	// NetworkReceiveBytesTotal represents the amount of received bytes on a given interface.
 	NetworkReceiveBytesTotal
 	// NetworkReceiveDropTotal represents the amount of received dropped bytes on a given interface.
@@ -146,6 +150,12 @@ const (
 	OperationsTotal
 	// ProcsTotal represents the number of running processes.
 	ProcsTotal
+	// StoragePoolAllocatedBytes represents the space allocated to the volumes on a storage pool.
+	StoragePoolAllocatedBytes
+	// StoragePoolTotalBytes represents the total space of a storage pool.
+	StoragePoolTotalBytes
+	// StoragePoolUsedBytes represents the used space of a storage pool.
+	StoragePoolUsedBytes
 	// UptimeSeconds represents the daemon uptime in seconds.
 	UptimeSeconds
 	// WarningsTotal represents the number of active warnings.
@@ -208,6 +218,8 @@ var MetricNames = map[MetricType]string{
 	MemoryUnevictableBytes:      "lxd_memory_Unevictable_bytes",
 	MemoryWritebackBytes:        "lxd_memory_Writeback_bytes",
 	MemoryOOMKillsTotal:         "lxd_memory_OOM_kills_total",
+	NetworkACLRuleBytesTotal:    "lxd_network_acl_rule_bytes_total",
+	NetworkACLRulePacketsTotal:  "lxd_network_acl_rule_packets_total",
 	NetworkReceiveBytesTotal:    "lxd_network_receive_bytes_total",
 	NetworkReceiveDropTotal:     "lxd_network_receive_drop_total",
 	NetworkReceiveErrsTotal:     "lxd_network_receive_errs_total",
@@ -218,6 +230,9 @@ var MetricNames = map[MetricType]string{
 	NetworkTransmitPacketsTotal: "lxd_network_transmit_packets_total",
 	OperationsTotal:             "lxd_operations_total",
 	ProcsTotal:                  "lxd_procs_total",
+	StoragePoolAllocatedBytes:   "lxd_storage_pool_space_allocated_bytes",
+	StoragePoolTotalBytes:       "lxd_storage_pool_space_total_bytes",
+	StoragePoolUsedBytes:        "lxd_storage_pool_space_used_bytes",
 	UptimeSeconds:               "lxd_uptime_seconds",
 	WarningsTotal:               "lxd_warnings_total",
 	Instances:                   "lxd_instances",
@@ -279,6 +294,8 @@ var MetricHeaders = map[MetricType]string{
 	MemoryUnevictableBytes:      "# HELP lxd_memory_Unevictable_bytes The amount of unevictable memory.",
 	MemoryWritebackBytes:        "# HELP lxd_memory_Writeback_bytes The amount of memory queued for syncing to disk.",
 	MemoryOOMKillsTotal:         "# HELP lxd_memory_OOM_kills_total The number of out of memory kills.",
+	NetworkACLRuleBytesTotal:    "# HELP lxd_network_acl_rule_bytes_total The amount of bytes matched by a given network ACL rule.",
+	NetworkACLRulePacketsTotal:  "# HELP lxd_network_acl_rule_packets_total The amount of packets matched by a given network ACL rule.",
 	NetworkReceiveBytesTotal:    "# HELP lxd_network_receive_bytes_total The amount of received bytes on a given interface.",
 	NetworkReceiveDropTotal:     "# HELP lxd_network_receive_drop_total The amount of received dropped bytes on a given interface.",
 	NetworkReceiveErrsTotal:     "# HELP lxd_network_receive_errs_total The amount of received errors on a given interface.",
@@ -289,6 +306,9 @@ var MetricHeaders = map[MetricType]string{
 	NetworkTransmitPacketsTotal: "# HELP lxd_network_transmit_packets_total The amount of transmitted packets on a given interface.",
 	OperationsTotal:             "# HELP lxd_operations_total The number of running operations",
 	ProcsTotal:                  "# HELP lxd_procs_total The number of running processes.",
+	StoragePoolAllocatedBytes:   "# HELP lxd_storage_pool_space_allocated_bytes The space allocated to the volumes on the storage pool in bytes.",
+	StoragePoolTotalBytes:       "# HELP lxd_storage_pool_space_total_bytes The total space of the storage pool in bytes.",
+	StoragePoolUsedBytes:        "# HELP lxd_storage_pool_space_used_bytes The used space of the storage pool in bytes.",
 	UptimeSeconds:               "# HELP lxd_uptime_seconds The daemon uptime in seconds.",
 	WarningsTotal:               "# HELP lxd_warnings_total The number of active warnings.",
 	Instances:                   "# HELP lxd_instances The number of instances.",
@@ -60,6 +60,7 @@ type VolumeSourceArgs struct {
 	Info               *Info
 	VolumeOnly         bool
 	ClusterMove        bool
+	BlockDelta         bool // Send block volumes as deltas against the previously sent one (local copies only).
 }
 
 // VolumeTargetArgs represents the arguments needed to setup a volume migration sink.
@@ -78,6 +79,7 @@ type VolumeTargetArgs struct {
 	ContentType           string
 	VolumeOnly            bool
 	ClusterMoveSourceName string
+	BlockDelta            bool // Receive block volumes as deltas against the previously received one (local copies only).
 }
 
 // TypesToHeader converts one or more Types to a MigrationHeader. It uses the first type argument
@@ -0,0 +1,216 @@
+package migration
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// multiplexerHeaderSize is the size of the header of each frame (stream index and payload length).
+const multiplexerHeaderSize = 5
+
+// multiplexerMaxPayloadSize is the maximum size of the payload of a single frame.
+const multiplexerMaxPayloadSize = 64 * 1024
+
+// Multiplexer carries multiple bidirectional streams over a single connection.
+// It is used to transfer several NBD connections over the migration filesystem connection.
+// Both sides of the connection must use the same number of streams.
+type Multiplexer struct {
+	conn      io.ReadWriteCloser
+	writeLock sync.Mutex
+	streams   []*multiplexerStream
+}
+
+// multiplexerStream is a single stream carried by a Multiplexer.
+// Received data is buffered so that a stream that isn't being read doesn't block the other streams.
+type multiplexerStream struct {
+	mux   *Multiplexer
+	index uint8
+
+	lock    sync.Mutex
+	cond    *sync.Cond
+	buf     bytes.Buffer
+	readErr error // Error returned once the buffered data has been read.
+	closed  bool  // Whether the stream was closed locally.
+}
+
+// NewMultiplexer returns a Multiplexer carrying the given number of streams (at most 256) over conn.
+func NewMultiplexer(conn io.ReadWriteCloser, streams int) (*Multiplexer, error) {
+	if streams < 1 || streams > 256 {
+		return nil, fmt.Errorf("Invalid number of multiplexed streams %d", streams)
+	}
+
+	m := &Multiplexer{
+		conn:    conn,
+		streams: make([]*multiplexerStream, 0, streams),
+	}
+
+	for i := 0; i < streams; i++ {
+		stream := &multiplexerStream{
+			mux:   m,
+			index: uint8(i),
+		}
+
+		stream.cond = sync.NewCond(&stream.lock)
+		m.streams = append(m.streams, stream)
+	}
+
+	go m.demultiplex()
+
+	return m, nil
+}
+
+// Stream returns the stream with the given index.
+func (m *Multiplexer) Stream(index int) io.ReadWriteCloser {
+	return m.streams[index]
+}
+
+// Close closes the underlying connection, which ends all the streams.
+func (m *Multiplexer) Close() error {
+	return m.conn.Close()
+}
+
+// demultiplex reads the frames from the connection and dispatches them to their stream until the
+// connection fails.
+func (m *Multiplexer) demultiplex() {
+	header := make([]byte, multiplexerHeaderSize)
+	payload := make([]byte, multiplexerMaxPayloadSize)
+
+	err := func() error {
+		for {
+			_, err := io.ReadFull(m.conn, header)
+			if err != nil {
+				return err
+			}
+
+			index := int(header[0])
+			length := binary.BigEndian.Uint32(header[1:])
+
+			if index >= len(m.streams) {
+				return fmt.Errorf("Invalid multiplexed stream index %d", index)
+			}
+
+			if length > multiplexerMaxPayloadSize {
+				return fmt.Errorf("Invalid multiplexed frame length %d", length)
+			}
+
+			stream := m.streams[index]
+
+			// An empty frame indicates that the remote side closed the stream.
+			if length == 0 {
+				stream.end(io.EOF)
+				continue
+			}
+
+			_, err = io.ReadFull(m.conn, payload[:length])
+			if err != nil {
+				return err
+			}
+
+			stream.receive(payload[:length])
+		}
+	}()
+
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+
+	for _, stream := range m.streams {
+		stream.end(err)
+	}
+}
+
+// writeFrame writes a frame with the given payload for the given stream.
+func (m *Multiplexer) writeFrame(index uint8, payload []byte) error {
+	frame := make([]byte, multiplexerHeaderSize+len(payload))
+	frame[0] = index
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(payload)))
+	copy(frame[multiplexerHeaderSize:], payload)
+
+	m.writeLock.Lock()
+	defer m.writeLock.Unlock()
+
+	_, err := m.conn.Write(frame)
+	return err
+}
+
+// receive buffers data received for the stream.
+func (s *multiplexerStream) receive(data []byte) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// Discard the data if the stream was closed locally.
+	if s.closed || s.readErr != nil {
+		return
+	}
+
+	_, _ = s.buf.Write(data)
+	s.cond.Broadcast()
+}
+
+// end marks the end of the data received for the stream.
+func (s *multiplexerStream) end(err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.readErr == nil {
+		s.readErr = err
+	}
+
+	s.cond.Broadcast()
+}
+
+// Read reads data received for the stream.
+func (s *multiplexerStream) Read(p []byte) (int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for s.buf.Len() == 0 && s.readErr == nil && !s.closed {
+		s.cond.Wait()
+	}
+
+	if s.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	if s.buf.Len() > 0 {
+		return s.buf.Read(p)
+	}
+
+	return 0, s.readErr
+}
+
+// Write sends data on the stream.
+func (s *multiplexerStream) Write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		end := min(written+multiplexerMaxPayloadSize, len(p))
+
+		err := s.mux.writeFrame(s.index, p[written:end])
+		if err != nil {
+			return written, err
+		}
+
+		written = end
+	}
+
+	return written, nil
+}
+
+// Close closes the stream and notifies the remote side.
+func (s *multiplexerStream) Close() error {
+	s.lock.Lock()
+	if s.closed {
+		s.lock.Unlock()
+		return nil
+	}
+
+	s.closed = true
+	s.buf.Reset()
+	s.cond.Broadcast()
+	s.lock.Unlock()
+
+	return s.mux.writeFrame(s.index, nil)
+}
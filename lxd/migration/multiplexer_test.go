@@ -0,0 +1,69 @@
+package migration
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+)
+
+func TestMultiplexer(t *testing.T) {
+	connA, connB := net.Pipe()
+
+	muxA, err := NewMultiplexer(connA, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() { _ = muxA.Close() }()
+
+	muxB, err := NewMultiplexer(connB, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() { _ = muxB.Close() }()
+
+	// Send more than a single frame on each stream concurrently.
+	payloads := [][]byte{
+		bytes.Repeat([]byte("a"), multiplexerMaxPayloadSize*2+1),
+		bytes.Repeat([]byte("b"), 10),
+	}
+
+	for i, payload := range payloads {
+		go func(stream io.WriteCloser, payload []byte) {
+			_, _ = stream.Write(payload)
+			_ = stream.Close()
+		}(muxA.Stream(i), payload)
+	}
+
+	for i, payload := range payloads {
+		received, err := io.ReadAll(muxB.Stream(i))
+		if err != nil {
+			t.Fatalf("Failed reading stream %d: %v", i, err)
+		}
+
+		if !bytes.Equal(received, payload) {
+			t.Errorf("Unexpected data on stream %d: got %d bytes, expected %d bytes", i, len(received), len(payload))
+		}
+	}
+
+	// Closing the connection ends the streams.
+	_ = muxA.Close()
+
+	_, err = muxA.Stream(0).Read(make([]byte, 1))
+	if err == nil {
+		t.Error("Expected error reading from stream after closing the connection")
+	}
+}
+
+func TestNewMultiplexerInvalidStreams(t *testing.T) {
+	connA, _ := net.Pipe()
+
+	for _, streams := range []int{0, 257} {
+		_, err := NewMultiplexer(connA, streams)
+		if err == nil {
+			t.Errorf("Expected error with %d streams", streams)
+		}
+	}
+}
@@ -3,6 +3,9 @@ package acl
 import (
 	"context"
 	"fmt"
+	"net"
+	"strings"
+	"sync"
 
 	"github.com/canonical/lxd/lxd/db"
 	firewallDrivers "github.com/canonical/lxd/lxd/firewall/drivers"
@@ -12,24 +15,214 @@ import (
 	"github.com/canonical/lxd/shared/logger"
 )
 
+// FirewallRule identifies the ACL rule that a rule in the firewall of a network was generated from.
+type FirewallRule struct {
+	Project   string
+	Network   string
+	ACLID     int64
+	ACL       string
+	Direction string
+	Index     int
+	Action    string
+}
+
+// FirewallRuleCounter represents the counters of the packets matched by an ACL rule in the firewall of a network.
+type FirewallRuleCounter struct {
+	FirewallRule
+
+	Packets uint64
+	Bytes   uint64
+}
+
+// firewallRules holds the ACL rules applied to the firewall of each network keyed by network and counter name.
+var firewallRules = make(map[string]map[string]FirewallRule)
+var firewallRulesMu sync.Mutex
+
+// FirewallClearACLRules forgets the ACL rules applied to the firewall of a network.
+// This should be called when the ACL rules of the network have been removed from the firewall.
+func FirewallClearACLRules(networkName string) {
+	firewallRulesMu.Lock()
+	delete(firewallRules, networkName)
+	firewallRulesMu.Unlock()
+}
+
+// firewallRuleByName returns the ACL rule that a logged or counted firewall rule was generated from.
+func firewallRuleByName(name string) (FirewallRule, bool) {
+	firewallRulesMu.Lock()
+	defer firewallRulesMu.Unlock()
+
+	for _, rules := range firewallRules {
+		rule, found := rules[name]
+		if found {
+			return rule, true
+		}
+	}
+
+	return FirewallRule{}, false
+}
+
+// FirewallACLRuleCounters returns the counters of the ACL rules applied to the firewall of the networks on this
+// member. The counters are reset whenever the ACL rules of a network are applied again.
+func FirewallACLRuleCounters(s *state.State) ([]FirewallRuleCounter, error) {
+	firewallRulesMu.Lock()
+	networkRules := make(map[string]map[string]FirewallRule, len(firewallRules))
+	for networkName, rules := range firewallRules {
+		networkRules[networkName] = rules
+	}
+
+	firewallRulesMu.Unlock()
+
+	ruleCounters := []FirewallRuleCounter{}
+	for networkName, rules := range networkRules {
+		counters, err := s.Firewall.NetworkACLRuleCounters(networkName)
+		if err != nil {
+			return nil, fmt.Errorf("Failed getting ACL rule counters for network %q: %w", networkName, err)
+		}
+
+		for name, rule := range rules {
+			ruleCounters = append(ruleCounters, FirewallRuleCounter{
+				FirewallRule: rule,
+				Packets:      counters[name].Packets,
+				Bytes:        counters[name].Bytes,
+			})
+		}
+	}
+
+	return ruleCounters, nil
+}
+
 // FirewallApplyACLRules applies ACL rules to network firewall.
 func FirewallApplyACLRules(s *state.State, logger logger.Logger, aclProjectName string, aclNet NetworkACLUsage) error {
 	var dropRules []firewallDrivers.ACLRule
 	var rejectRules []firewallDrivers.ACLRule
 	var allowRules []firewallDrivers.ACLRule
+	var peerTargetNetIDs map[db.NetworkPeer]int64
+
+	// The rules of all the ACLs of the network are numbered per direction so that the log and counter names
+	// are unique within the network.
+	ruleNames := make(map[string]FirewallRule)
+	ruleCounts := make(map[string]int)
+
+	// The address groups referenced by the rules are passed to the firewall as address sets.
+	addressGroupNames := []string{}
+
+	// expandPeerSubjects replaces the "@<network>/<peer>" subjects with the subnets of the peer's target network.
+	expandPeerSubjects := func(subjects string) (string, error) {
+		if !strings.Contains(subjects, "@") {
+			return subjects, nil
+		}
+
+		expanded := []string{}
+		for _, subject := range shared.SplitNTrimSpace(subjects, ",", -1, false) {
+			if !strings.HasPrefix(subject, "@") {
+				expanded = append(expanded, subject)
+				continue
+			}
+
+			peerParts := strings.SplitN(strings.TrimPrefix(subject, "@"), "/", 2)
+			if len(peerParts) != 2 {
+				return "", fmt.Errorf("Cannot parse subject as peer %q", subject)
+			}
+
+			peer := db.NetworkPeer{
+				NetworkName: peerParts[0],
+				PeerName:    peerParts[1],
+			}
+
+			if peer.NetworkName != aclNet.Name {
+				return "", fmt.Errorf(`ACL requiring peer "%s/%s" cannot be applied to network %q`, peer.NetworkName, peer.PeerName, aclNet.Name)
+			}
+
+			if peerTargetNetIDs == nil {
+				var err error
+
+				peerTargetNetIDs, err = s.DB.Cluster.GetNetworkPeersTargetNetworkIDs(aclProjectName, db.NetworkTypeBridge)
+				if err != nil {
+					return "", fmt.Errorf("Failed getting peer connection mappings: %w", err)
+				}
+			}
+
+			networkID, found := peerTargetNetIDs[peer]
+			if !found {
+				return "", fmt.Errorf("Cannot find network ID for peer %q", subject)
+			}
+
+			var targetNet *api.Network
+
+			err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+				networkName, projectName, err := tx.GetNetworkNameAndProjectWithID(ctx, int(networkID))
+				if err != nil {
+					return err
+				}
+
+				_, targetNet, _, err = tx.GetNetworkInAnyState(ctx, projectName, networkName)
+
+				return err
+			})
+			if err != nil {
+				return "", fmt.Errorf("Failed loading target network of peer %q: %w", subject, err)
+			}
+
+			// Use the subnets routed to the target network, as is done for the peering itself.
+			peerSubnets := []string{}
+			for _, ipVersion := range []uint{4, 6} {
+				_, subnet, err := net.ParseCIDR(targetNet.Config[fmt.Sprintf("ipv%d.address", ipVersion)])
+				if err == nil {
+					peerSubnets = append(peerSubnets, subnet.String())
+				}
+
+				for _, route := range shared.SplitNTrimSpace(targetNet.Config[fmt.Sprintf("ipv%d.routes", ipVersion)], ",", -1, true) {
+					_, subnet, err := net.ParseCIDR(route)
+					if err == nil {
+						peerSubnets = append(peerSubnets, subnet.String())
+					}
+				}
+			}
+
+			// An empty subject list would match any address, so refuse peers without subnets.
+			if len(peerSubnets) == 0 {
+				return "", fmt.Errorf("Target network of peer %q has no subnets", subject)
+			}
+
+			expanded = append(expanded, peerSubnets...)
+		}
+
+		return strings.Join(expanded, ","), nil
+	}
 
 	// convertACLRules converts the ACL rules to Firewall ACL rules.
-	convertACLRules := func(direction string, logPrefix string, rules ...api.NetworkACLRule) error {
+	convertACLRules := func(aclID int64, aclName string, direction string, logPrefix string, rules ...api.NetworkACLRule) error {
 		for ruleIndex, rule := range rules {
 			if rule.State == "disabled" {
 				continue
 			}
 
+			source, err := expandPeerSubjects(rule.Source)
+			if err != nil {
+				return err
+			}
+
+			destination, err := expandPeerSubjects(rule.Destination)
+			if err != nil {
+				return err
+			}
+
+			for _, groupName := range ruleAddressGroups(rule) {
+				if !shared.ValueInSlice(groupName, addressGroupNames) {
+					addressGroupNames = append(addressGroupNames, groupName)
+				}
+			}
+
+			// Max 29 chars.
+			ruleName := fmt.Sprintf("%s-%s-%d", logPrefix, direction, ruleCounts[direction])
+			ruleCounts[direction]++
+
 			firewallACLRule := firewallDrivers.ACLRule{
 				Direction:       direction,
 				Action:          rule.Action,
-				Source:          rule.Source,
-				Destination:     rule.Destination,
+				CounterName:     ruleName,
+				Source:          source,
+				Destination:     destination,
 				Protocol:        rule.Protocol,
 				SourcePort:      rule.SourcePort,
 				DestinationPort: rule.DestinationPort,
@@ -39,8 +232,17 @@ func FirewallApplyACLRules(s *state.State, logger logger.Logger, aclProjectName
 
 			if rule.State == "logged" {
 				firewallACLRule.Log = true
-				// Max 29 chars.
-				firewallACLRule.LogName = fmt.Sprintf("%s-%s-%d", logPrefix, direction, ruleIndex)
+				firewallACLRule.LogName = ruleName
+			}
+
+			ruleNames[ruleName] = FirewallRule{
+				Project:   aclProjectName,
+				Network:   aclNet.Name,
+				ACLID:     aclID,
+				ACL:       aclName,
+				Direction: direction,
+				Index:     ruleIndex,
+				Action:    rule.Action,
 			}
 
 			switch {
@@ -62,12 +264,13 @@ func FirewallApplyACLRules(s *state.State, logger logger.Logger, aclProjectName
 
 	// Load ACLs specified by network.
 	for _, aclName := range shared.SplitNTrimSpace(aclNet.Config["security.acls"], ",", -1, true) {
+		var aclID int64
 		var aclInfo *api.NetworkACL
 
 		err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 			var err error
 
-			_, aclInfo, err = tx.GetNetworkACL(ctx, aclProjectName, aclName)
+			aclID, aclInfo, err = tx.GetNetworkACL(ctx, aclProjectName, aclName)
 
 			return err
 		})
@@ -75,12 +278,12 @@ func FirewallApplyACLRules(s *state.State, logger logger.Logger, aclProjectName
 			return fmt.Errorf("Failed loading ACL %q for network %q: %w", aclName, aclNet.Name, err)
 		}
 
-		err = convertACLRules("ingress", logPrefix, aclInfo.Ingress...)
+		err = convertACLRules(aclID, aclInfo.Name, "ingress", logPrefix, aclInfo.Ingress...)
 		if err != nil {
 			return fmt.Errorf("Failed converting ACL %q ingress rules for network %q: %w", aclInfo.Name, aclNet.Name, err)
 		}
 
-		err = convertACLRules("egress", logPrefix, aclInfo.Egress...)
+		err = convertACLRules(aclID, aclInfo.Name, "egress", logPrefix, aclInfo.Egress...)
 		if err != nil {
 			return fmt.Errorf("Failed converting ACL %q egress rules for network %q: %w", aclInfo.Name, aclNet.Name, err)
 		}
@@ -109,7 +312,26 @@ func FirewallApplyACLRules(s *state.State, logger logger.Logger, aclProjectName
 		LogName:   fmt.Sprintf("%s-ingress", logPrefix),
 	})
 
-	return s.Firewall.NetworkApplyACLRules(aclNet.Name, rules)
+	addressGroups, err := loadAddressGroups(s, aclProjectName, addressGroupNames...)
+	if err != nil {
+		return fmt.Errorf("Failed loading address groups for network %q: %w", aclNet.Name, err)
+	}
+
+	addressSets := make([]firewallDrivers.AddressSet, 0, len(addressGroupNames))
+	for _, groupName := range addressGroupNames {
+		addressSets = append(addressSets, addressGroups[groupName].firewallAddressSet())
+	}
+
+	err = s.Firewall.NetworkApplyACLRules(aclNet.Name, rules, addressSets)
+	if err != nil {
+		return err
+	}
+
+	firewallRulesMu.Lock()
+	firewallRules[aclNet.Name] = ruleNames
+	firewallRulesMu.Unlock()
+
+	return nil
 }
 
 // firewallACLDefaults returns the action and logging mode to use for the specified direction's default rule.
@@ -302,7 +302,7 @@ type NetworkACLUsage struct {
 
 // NetworkUsage populates the provided aclNets map with networks that are using any of the specified ACLs.
 func NetworkUsage(s *state.State, aclProjectName string, aclNames []string, aclNets map[string]NetworkACLUsage) error {
-	supportedNetTypes := []string{"bridge", "ovn"}
+	supportedNetTypes := []string{"bridge", "ovn", "wireguard"}
 
 	// Find all networks and instance/profile NICs that use any of the specified Network ACLs.
 	err := UsedBy(s, aclProjectName, func(ctx context.Context, tx *db.ClusterTx, matchedACLNames []string, usageType any, _ string, nicConfig map[string]string) error {
@@ -0,0 +1,209 @@
+package acl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/events"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// firewallLogFields maps the fields of the kernel log entries of the firewall to the context of the ACL events.
+var firewallLogFields = map[string]string{
+	"IN":   "in",
+	"OUT":  "out",
+	"SRC":  "source",
+	"DST":  "destination",
+	"SPT":  "source_port",
+	"DPT":  "destination_port",
+	"TYPE": "icmp_type",
+	"CODE": "icmp_code",
+}
+
+// ovnLogFields maps the fields of the OVN ACL log entries to the context of the ACL events.
+var ovnLogFields = map[string]string{
+	"nw_src":    "source",
+	"ipv6_src":  "source",
+	"nw_dst":    "destination",
+	"ipv6_dst":  "destination",
+	"tp_src":    "source_port",
+	"tp_dst":    "destination_port",
+	"icmp_type": "icmp_type",
+	"icmp_code": "icmp_code",
+}
+
+// StartFirewallLogListener reads the kernel log and sends the entries of the logged ACL rules applied to the
+// firewall of the networks as network-acl events.
+func StartFirewallLogListener(ctx context.Context, eventServer *events.Server) error {
+	kmsg, err := os.Open("/dev/kmsg")
+	if err != nil {
+		return fmt.Errorf("Failed opening kernel log: %w", err)
+	}
+
+	// Only consider the entries logged from now on.
+	_, err = kmsg.Seek(0, io.SeekEnd)
+	if err != nil {
+		_ = kmsg.Close()
+		return fmt.Errorf("Failed seeking to the end of the kernel log: %w", err)
+	}
+
+	// This goroutine waits for the context to be cancelled and then closes the kernel log causing `Read` to return an error and exit the goroutine below.
+	go func() {
+		<-ctx.Done()
+		_ = kmsg.Close()
+	}()
+
+	go func() {
+		// Each read returns a single entry, which is limited to 8KiB by the kernel.
+		buf := make([]byte, 8192)
+
+		for {
+			n, err := kmsg.Read(buf)
+			if err != nil {
+				// Some entries were overwritten before they could be read, carry on with the next one.
+				if errors.Is(err, unix.EPIPE) {
+					continue
+				}
+
+				return
+			}
+
+			projectName, event := firewallParseLogEntry(string(buf[:n]))
+			if event == nil {
+				continue
+			}
+
+			_ = eventServer.Send(projectName, api.EventTypeNetworkACL, event)
+		}
+	}()
+
+	return nil
+}
+
+// firewallParseLogEntry takes a kernel log entry and returns the project and event of the logged ACL rule it
+// comes from. Returns a nil event if the entry doesn't come from an ACL rule.
+func firewallParseLogEntry(entry string) (string, *api.EventLogging) {
+	// Entries have the "<priority>,<sequence>,<timestamp>,<flags>;<message>" format and may be followed by
+	// continuation lines.
+	_, message, found := strings.Cut(entry, ";")
+	if !found {
+		return "", nil
+	}
+
+	message, _, _ = strings.Cut(message, "\n")
+
+	// The message starts with the log prefix of the rule.
+	fields := strings.Fields(message)
+	if len(fields) < 2 {
+		return "", nil
+	}
+
+	rule, found := firewallRuleByName(fields[0])
+	if !found {
+		return "", nil
+	}
+
+	logEntry := map[string]string{}
+	for _, field := range fields[1:] {
+		key, value, found := strings.Cut(field, "=")
+		if found {
+			logEntry[key] = value
+		}
+	}
+
+	eventCtx := map[string]string{
+		"protocol": strings.ToLower(logEntry["PROTO"]),
+	}
+
+	for key, name := range firewallLogFields {
+		if logEntry[key] != "" {
+			eventCtx[name] = logEntry[key]
+		}
+	}
+
+	return rule.Project, networkACLLogEvent(rule, eventCtx)
+}
+
+// OVNLogEvent takes the message of an OVN ACL log entry and returns the project and event of the logged ACL rule
+// it comes from. Returns a nil event if the entry doesn't come from an ACL rule.
+func OVNLogEvent(s *state.State, message string) (string, *api.EventLogging) {
+	logEntry := ovnParseLogFields(message)
+
+	// The logged rules are named "<port group>-<direction>-<index>", the port group including the ACL ID.
+	nameParts := strings.Split(logEntry["name"], "-")
+	if len(nameParts) != 3 || !strings.HasPrefix(nameParts[0], ovnACLPortGroupPrefix) {
+		return "", nil
+	}
+
+	aclID, err := strconv.Atoi(strings.TrimPrefix(nameParts[0], ovnACLPortGroupPrefix))
+	if err != nil {
+		return "", nil
+	}
+
+	ruleIndex, err := strconv.Atoi(nameParts[2])
+	if err != nil {
+		return "", nil
+	}
+
+	rule := FirewallRule{
+		ACLID:     int64(aclID),
+		Direction: nameParts[1],
+		Index:     ruleIndex,
+		Action:    logEntry["verdict"],
+	}
+
+	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		rule.ACL, rule.Project, err = tx.GetNetworkACLNameAndProjectWithID(ctx, aclID)
+
+		return err
+	})
+	if err != nil {
+		return "", nil
+	}
+
+	eventCtx := map[string]string{}
+
+	// The direction field is followed by the protocol.
+	directionFields := strings.Split(logEntry["direction"], " ")
+	if len(directionFields) == 2 {
+		eventCtx["protocol"] = directionFields[1]
+	}
+
+	for key, name := range ovnLogFields {
+		if logEntry[key] != "" {
+			eventCtx[name] = logEntry[key]
+		}
+	}
+
+	return rule.Project, networkACLLogEvent(rule, eventCtx)
+}
+
+// networkACLLogEvent returns the event of a packet matched by a logged ACL rule.
+func networkACLLogEvent(rule FirewallRule, eventCtx map[string]string) *api.EventLogging {
+	eventCtx["project"] = rule.Project
+	eventCtx["acl"] = rule.ACL
+	eventCtx["direction"] = rule.Direction
+	eventCtx["rule"] = strconv.Itoa(rule.Index)
+	eventCtx["action"] = rule.Action
+
+	if rule.Network != "" {
+		eventCtx["network"] = rule.Network
+	}
+
+	return &api.EventLogging{
+		Level:   "info",
+		Message: "Network ACL rule matched",
+		Context: eventCtx,
+	}
+}
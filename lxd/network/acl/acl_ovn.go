@@ -49,6 +49,14 @@ func OVNACLNetworkPortGroupName(networkACLID int64, networkID int64) openvswitch
 	return openvswitch.OVNPortGroup(fmt.Sprintf("%s%d_net%d", ovnACLPortGroupPrefix, networkACLID, networkID))
 }
 
+// ovnAddressGroupPrefix prefix used when naming address group related address sets in OVN.
+const ovnAddressGroupPrefix = "lxd_addrgroup"
+
+// ovnAddressGroupAddressSetPrefix returns the address set prefix for a Network address group ID.
+func ovnAddressGroupAddressSetPrefix(networkAddressGroupID int64) openvswitch.OVNAddressSet {
+	return openvswitch.OVNAddressSet(fmt.Sprintf("%s%d", ovnAddressGroupPrefix, networkAddressGroupID))
+}
+
 // OVNIntSwitchPortGroupName returns the port group name for a Network ID.
 func OVNIntSwitchPortGroupName(networkID int64) openvswitch.OVNPortGroup {
 	return openvswitch.OVNPortGroup(fmt.Sprintf("lxd_net%d", networkID))
@@ -185,6 +193,25 @@ func OVNEnsureACLs(s *state.State, l logger.Logger, client *openvswitch.OVN, acl
 		}
 	}
 
+	// Load the address groups referenced in the rules of the ACLs we need to apply.
+	addressGroupNames := []string{}
+	for _, aclStatus := range append(append([]aclStatus{}, createACLPortGroups...), existingACLPortGroups...) {
+		if aclStatus.aclInfo == nil {
+			continue
+		}
+
+		for _, groupName := range ruleAddressGroups(append(append([]api.NetworkACLRule{}, aclStatus.aclInfo.Ingress...), aclStatus.aclInfo.Egress...)...) {
+			if !shared.ValueInSlice(groupName, addressGroupNames) {
+				addressGroupNames = append(addressGroupNames, groupName)
+			}
+		}
+	}
+
+	addressGroups, err := loadAddressGroups(s, aclProjectName, addressGroupNames...)
+	if err != nil {
+		return nil, err
+	}
+
 	// Build a list of referenced ACLs in the rules of ACLs we need to create.
 	// We will create port groups (without ACL rules) for any missing referenced ACL OVN port groups so that
 	// when we add the rules for the new ACL port groups this doesn't trigger an OVN log error about missing
@@ -258,7 +285,7 @@ func OVNEnsureACLs(s *state.State, l logger.Logger, client *openvswitch.OVN, acl
 		}
 
 		// Now apply our ACL rules to port group (and any per-ACL-per-network port groups needed).
-		err = ovnApplyToPortGroup(l, client, aclStatus.aclInfo, portGroupName, aclNameIDs, aclNets, peerTargetNetIDs)
+		err = ovnApplyToPortGroup(l, client, aclStatus.aclInfo, portGroupName, aclNameIDs, aclNets, peerTargetNetIDs, addressGroups)
 		if err != nil {
 			return nil, fmt.Errorf("Failed applying ACL rules to port group %q for security ACL %q setup: %w", portGroupName, aclStatus.name, err)
 		}
@@ -288,7 +315,7 @@ func OVNEnsureACLs(s *state.State, l logger.Logger, client *openvswitch.OVN, acl
 		if aclStatus.aclInfo != nil {
 			l.Debug("Applying ACL rules to OVN port group", logger.Ctx{"networkACL": aclStatus.name, "portGroup": portGroupName})
 
-			err := ovnApplyToPortGroup(l, client, aclStatus.aclInfo, portGroupName, aclNameIDs, aclNets, peerTargetNetIDs)
+			err := ovnApplyToPortGroup(l, client, aclStatus.aclInfo, portGroupName, aclNameIDs, aclNets, peerTargetNetIDs, addressGroups)
 			if err != nil {
 				return nil, fmt.Errorf("Failed applying ACL rules to port group %q for security ACL %q setup: %w", portGroupName, aclStatus.name, err)
 			}
@@ -317,6 +344,11 @@ func ovnAddReferencedACLs(info *api.NetworkACL, referencedACLNames map[string]st
 				continue // Skip if the subject is an IP CIDR or IP range.
 			}
 
+			_, isAddressGroup := addressGroupReference(subject)
+			if isAddressGroup {
+				continue // Skip address groups as they are address sets rather than port groups.
+			}
+
 			// Anything else must be a referenced ACL name.
 			// Record newly seen referenced ACL into authoritative list.
 			referencedACLNames[subject] = struct{}{}
@@ -333,7 +365,8 @@ func ovnAddReferencedACLs(info *api.NetworkACL, referencedACLNames map[string]st
 }
 
 // ovnApplyToPortGroup applies the rules in the specified ACL to the specified port group.
-func ovnApplyToPortGroup(l logger.Logger, client *openvswitch.OVN, aclInfo *api.NetworkACL, portGroupName openvswitch.OVNPortGroup, aclNameIDs map[string]int64, aclNets map[string]NetworkACLUsage, peerTargetNetIDs map[db.NetworkPeer]int64) error {
+// The addressGroups map must contain the address groups referenced by the rules of the ACL.
+func ovnApplyToPortGroup(l logger.Logger, client *openvswitch.OVN, aclInfo *api.NetworkACL, portGroupName openvswitch.OVNPortGroup, aclNameIDs map[string]int64, aclNets map[string]NetworkACLUsage, peerTargetNetIDs map[db.NetworkPeer]int64, addressGroups map[string]*addressGroup) error {
 	// Create slice for port group rules that has the capacity for ingress and egress rules, plus default rule.
 	portGroupRules := make([]openvswitch.OVNACLRule, 0, len(aclInfo.Ingress)+len(aclInfo.Egress)+1)
 	networkRules := make([]openvswitch.OVNACLRule, 0)
@@ -346,7 +379,7 @@ func ovnApplyToPortGroup(l logger.Logger, client *openvswitch.OVN, aclInfo *api.
 				continue
 			}
 
-			ovnACLRule, networkSpecific, networkPeers, err := ovnRuleCriteriaToOVNACLRule(direction, &rule, portGroupName, aclNameIDs, peerTargetNetIDs)
+			ovnACLRule, networkSpecific, networkPeers, err := ovnRuleCriteriaToOVNACLRule(direction, &rule, portGroupName, aclNameIDs, peerTargetNetIDs, addressGroups)
 			if err != nil {
 				return err
 			}
@@ -401,6 +434,20 @@ func ovnApplyToPortGroup(l logger.Logger, client *openvswitch.OVN, aclInfo *api.
 		}
 	}
 
+	// Ensure the address sets of the referenced address groups exist and are up to date before they are used.
+	for _, groupName := range ruleAddressGroups(append(append([]api.NetworkACLRule{}, aclInfo.Ingress...), aclInfo.Egress...)...) {
+		group, found := addressGroups[groupName]
+		if !found {
+			return fmt.Errorf("Cannot find address group %q", groupName)
+		}
+
+		addressSetPrefix := ovnAddressGroupAddressSetPrefix(group.id)
+		err = client.AddressSetSet(addressSetPrefix, group.ovnAddresses()...)
+		if err != nil {
+			return fmt.Errorf("Failed setting address set %q for address group %q: %w", addressSetPrefix, groupName, err)
+		}
+	}
+
 	// Clear all existing ACL rules from port group then add the new rules to the port group.
 	err = client.PortGroupSetACLRules(portGroupName, nil, portGroupRules...)
 	if err != nil {
@@ -429,7 +476,7 @@ func ovnApplyToPortGroup(l logger.Logger, client *openvswitch.OVN, aclInfo *api.
 
 // ovnRuleCriteriaToOVNACLRule converts a LXD ACL rule into an OVNACLRule for an OVN port group or network.
 // Returns a bool indicating if any of the rule subjects are network specific.
-func ovnRuleCriteriaToOVNACLRule(direction string, rule *api.NetworkACLRule, portGroupName openvswitch.OVNPortGroup, aclNameIDs map[string]int64, peerTargetNetIDs map[db.NetworkPeer]int64) (openvswitch.OVNACLRule, bool, []db.NetworkPeer, error) {
+func ovnRuleCriteriaToOVNACLRule(direction string, rule *api.NetworkACLRule, portGroupName openvswitch.OVNPortGroup, aclNameIDs map[string]int64, peerTargetNetIDs map[db.NetworkPeer]int64, addressGroups map[string]*addressGroup) (openvswitch.OVNACLRule, bool, []db.NetworkPeer, error) {
 	networkSpecific := false
 	networkPeersNeeded := make([]db.NetworkPeer, 0)
 	portGroupRule := openvswitch.OVNACLRule{
@@ -463,7 +510,7 @@ func ovnRuleCriteriaToOVNACLRule(direction string, rule *api.NetworkACLRule, por
 
 	// Add subject filters.
 	if rule.Source != "" {
-		match, netSpecificMatch, networkPeers, err := ovnRuleSubjectToOVNACLMatch("src", aclNameIDs, peerTargetNetIDs, shared.SplitNTrimSpace(rule.Source, ",", -1, false)...)
+		match, netSpecificMatch, networkPeers, err := ovnRuleSubjectToOVNACLMatch("src", aclNameIDs, peerTargetNetIDs, addressGroups, shared.SplitNTrimSpace(rule.Source, ",", -1, false)...)
 		if err != nil {
 			return openvswitch.OVNACLRule{}, false, nil, err
 		}
@@ -477,7 +524,7 @@ func ovnRuleCriteriaToOVNACLRule(direction string, rule *api.NetworkACLRule, por
 	}
 
 	if rule.Destination != "" {
-		match, netSpecificMatch, networkPeers, err := ovnRuleSubjectToOVNACLMatch("dst", aclNameIDs, peerTargetNetIDs, shared.SplitNTrimSpace(rule.Destination, ",", -1, false)...)
+		match, netSpecificMatch, networkPeers, err := ovnRuleSubjectToOVNACLMatch("dst", aclNameIDs, peerTargetNetIDs, addressGroups, shared.SplitNTrimSpace(rule.Destination, ",", -1, false)...)
 		if err != nil {
 			return openvswitch.OVNACLRule{}, false, nil, err
 		}
@@ -495,11 +542,21 @@ func ovnRuleCriteriaToOVNACLRule(direction string, rule *api.NetworkACLRule, por
 		matchParts = append(matchParts, rule.Protocol)
 
 		if rule.SourcePort != "" {
-			matchParts = append(matchParts, ovnRulePortToOVNACLMatch(rule.Protocol, "src", shared.SplitNTrimSpace(rule.SourcePort, ",", -1, false)...))
+			portCriteria, err := ovnRulePortCriteria(addressGroups, rule.SourcePort)
+			if err != nil {
+				return openvswitch.OVNACLRule{}, false, nil, err
+			}
+
+			matchParts = append(matchParts, ovnRulePortToOVNACLMatch(rule.Protocol, "src", portCriteria...))
 		}
 
 		if rule.DestinationPort != "" {
-			matchParts = append(matchParts, ovnRulePortToOVNACLMatch(rule.Protocol, "dst", shared.SplitNTrimSpace(rule.DestinationPort, ",", -1, false)...))
+			portCriteria, err := ovnRulePortCriteria(addressGroups, rule.DestinationPort)
+			if err != nil {
+				return openvswitch.OVNACLRule{}, false, nil, err
+			}
+
+			matchParts = append(matchParts, ovnRulePortToOVNACLMatch(rule.Protocol, "dst", portCriteria...))
 		}
 	} else if shared.ValueInSlice(rule.Protocol, []string{"icmp4", "icmp6"}) {
 		matchParts = append(matchParts, rule.Protocol)
@@ -519,9 +576,30 @@ func ovnRuleCriteriaToOVNACLRule(direction string, rule *api.NetworkACLRule, por
 	return portGroupRule, networkSpecific, networkPeersNeeded, nil
 }
 
+// ovnRulePortCriteria returns the port criteria list of a rule's port field, expanding any address group reference
+// into the ports of the group.
+func ovnRulePortCriteria(addressGroups map[string]*addressGroup, ports string) ([]string, error) {
+	groupName, isAddressGroup := addressGroupReference(ports)
+	if !isAddressGroup {
+		return shared.SplitNTrimSpace(ports, ",", -1, false), nil
+	}
+
+	group, found := addressGroups[groupName]
+	if !found {
+		return nil, fmt.Errorf("Cannot find address group %q", groupName)
+	}
+
+	return group.info.Ports, nil
+}
+
 // ovnRulePortToOVNACLMatch converts protocol (tcp/udp), direction (src/dst) and port criteria list into an OVN
 // match statement.
 func ovnRulePortToOVNACLMatch(protocol string, direction string, portCriteria ...string) string {
+	// An empty port criteria list (such as from an address group without ports) matches no traffic.
+	if len(portCriteria) == 0 {
+		return "0"
+	}
+
 	fieldParts := make([]string, 0, len(portCriteria))
 
 	for _, portCriterion := range portCriteria {
@@ -538,7 +616,7 @@ func ovnRulePortToOVNACLMatch(protocol string, direction string, portCriteria ..
 
 // ovnRuleSubjectToOVNACLMatch converts direction (src/dst) and subject criteria list into an OVN match statement.
 // Returns a bool indicating if any of the subjects are network specific.
-func ovnRuleSubjectToOVNACLMatch(direction string, aclNameIDs map[string]int64, peerTargetNetIDs map[db.NetworkPeer]int64, subjectCriteria ...string) (string, bool, []db.NetworkPeer, error) {
+func ovnRuleSubjectToOVNACLMatch(direction string, aclNameIDs map[string]int64, peerTargetNetIDs map[db.NetworkPeer]int64, addressGroups map[string]*addressGroup, subjectCriteria ...string) (string, bool, []db.NetworkPeer, error) {
 	fieldParts := make([]string, 0, len(subjectCriteria))
 	networkSpecific := false
 	networkPeersNeeded := make([]db.NetworkPeer, 0)
@@ -589,6 +667,18 @@ func ovnRuleSubjectToOVNACLMatch(direction string, aclNameIDs map[string]int64,
 					// Convert deprecated #external to non-deprecated @external if needed.
 					subjectPortSelector = openvswitch.OVNPortGroup(ruleSubjectExternal)
 					networkSpecific = true
+				} else if groupName, isAddressGroup := addressGroupReference(subjectCriterion); isAddressGroup {
+					// Subject is an address group. Convert to address set criteria.
+					group, found := addressGroups[groupName]
+					if !found {
+						return "", false, nil, fmt.Errorf("Cannot find address group %q", groupName)
+					}
+
+					addrSetPrefix := ovnAddressGroupAddressSetPrefix(group.id)
+
+					fieldParts = append(fieldParts, fmt.Sprintf("ip4.%s == $%s_ip4 || ip6.%s == $%s_ip6", direction, addrSetPrefix, direction, addrSetPrefix))
+
+					continue // Not a port based selector.
 				} else if strings.HasPrefix(subjectCriterion, "@") {
 					// Subject is a network peer name. Convert to address set criteria.
 					peerParts := strings.SplitN(strings.TrimPrefix(subjectCriterion, "@"), "/", 2)
@@ -1041,6 +1131,21 @@ type ovnLogEntry struct {
 	Action   string `json:"action"`
 }
 
+// ovnParseLogFields parses the comma separated key/value pairs of an OVN ACL log message.
+func ovnParseLogFields(message string) map[string]string {
+	aclEntry := map[string]string{}
+	for _, entry := range shared.SplitNTrimSpace(message, ",", -1, true) {
+		pair := strings.Split(entry, "=")
+		if len(pair) != 2 {
+			continue
+		}
+
+		aclEntry[strings.Trim(pair[0], "\"")] = strings.Trim(pair[1], "\"")
+	}
+
+	return aclEntry
+}
+
 // ovnParseLogEntry takes a log line and expected ACL prefix and returns a re-formated log entry if matching.
 func ovnParseLogEntry(input string, prefix string) string {
 	fields := strings.Split(input, "|")
@@ -1056,15 +1161,7 @@ func ovnParseLogEntry(input string, prefix string) string {
 	}
 
 	// Parse the ACL log entry.
-	aclEntry := map[string]string{}
-	for _, entry := range shared.SplitNTrimSpace(fields[4], ",", -1, true) {
-		pair := strings.Split(entry, "=")
-		if len(pair) != 2 {
-			continue
-		}
-
-		aclEntry[strings.Trim(pair[0], "\"")] = strings.Trim(pair[1], "\"")
-	}
+	aclEntry := ovnParseLogFields(fields[4])
 
 	// Filter for our ACL.
 	if !strings.HasPrefix(aclEntry["name"], prefix) {
@@ -0,0 +1,544 @@
+package acl
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/lxd/cluster"
+	"github.com/canonical/lxd/lxd/cluster/request"
+	"github.com/canonical/lxd/lxd/db"
+	firewallDrivers "github.com/canonical/lxd/lxd/firewall/drivers"
+	"github.com/canonical/lxd/lxd/network/openvswitch"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/revert"
+	"github.com/canonical/lxd/shared/validate"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// addressGroupPrefix is the prefix used to reference an address group in the subjects and ports of ACL rules.
+const addressGroupPrefix = "$"
+
+// addressGroupReference returns the name of the address group referenced by an ACL rule field (if any).
+func addressGroupReference(value string) (string, bool) {
+	return strings.CutPrefix(value, addressGroupPrefix)
+}
+
+// ruleAddressGroups returns the names of the address groups referenced by the rules.
+func ruleAddressGroups(rules ...api.NetworkACLRule) []string {
+	groupNames := []string{}
+	for _, rule := range rules {
+		for _, value := range []string{rule.Source, rule.Destination, rule.SourcePort, rule.DestinationPort} {
+			groupName, found := addressGroupReference(value)
+			if found && !shared.ValueInSlice(groupName, groupNames) {
+				groupNames = append(groupNames, groupName)
+			}
+		}
+	}
+
+	return groupNames
+}
+
+// LoadAddressGroupByName loads and initialises a Network address group from the database by project and name.
+func LoadAddressGroupByName(s *state.State, projectName string, name string) (NetworkAddressGroup, error) {
+	var id int64
+	var groupInfo *api.NetworkAddressGroup
+
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		id, groupInfo, err = tx.GetNetworkAddressGroup(ctx, projectName, name)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var group NetworkAddressGroup = &addressGroup{}
+	group.init(s, id, projectName, groupInfo)
+
+	return group, nil
+}
+
+// CreateAddressGroup validates supplied record and creates new Network address group record in the database.
+func CreateAddressGroup(s *state.State, projectName string, groupInfo *api.NetworkAddressGroupsPost) error {
+	var group NetworkAddressGroup = &addressGroup{}
+	group.init(s, -1, projectName, nil)
+
+	err := group.validateName(groupInfo.Name)
+	if err != nil {
+		return err
+	}
+
+	err = group.validateConfig(&groupInfo.NetworkAddressGroupPut)
+	if err != nil {
+		return err
+	}
+
+	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		// Insert DB record.
+		_, err := tx.CreateNetworkAddressGroup(ctx, projectName, groupInfo)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// loadAddressGroups loads the address groups with the given names keyed by name.
+func loadAddressGroups(s *state.State, projectName string, names ...string) (map[string]*addressGroup, error) {
+	groups := make(map[string]*addressGroup, len(names))
+	if len(names) == 0 {
+		return groups, nil
+	}
+
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		for _, name := range names {
+			id, groupInfo, err := tx.GetNetworkAddressGroup(ctx, projectName, name)
+			if err != nil {
+				return fmt.Errorf("Failed loading network address group %q: %w", name, err)
+			}
+
+			group := &addressGroup{}
+			group.init(s, id, projectName, groupInfo)
+			groups[name] = group
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// addressGroup represents a Network address group.
+type addressGroup struct {
+	logger      logger.Logger
+	state       *state.State
+	id          int64
+	projectName string
+	info        *api.NetworkAddressGroup
+}
+
+// init initialise internal variables.
+func (d *addressGroup) init(state *state.State, id int64, projectName string, info *api.NetworkAddressGroup) {
+	if info == nil {
+		d.info = &api.NetworkAddressGroup{}
+	} else {
+		d.info = info
+	}
+
+	d.logger = logger.AddContext(logger.Ctx{"project": projectName, "networkAddressGroup": d.info.Name})
+	d.id = id
+	d.projectName = projectName
+	d.state = state
+
+	if d.info.Addresses == nil {
+		d.info.Addresses = []string{}
+	}
+
+	if d.info.Ports == nil {
+		d.info.Ports = []string{}
+	}
+
+	if d.info.Config == nil {
+		d.info.Config = make(map[string]string)
+	}
+}
+
+// ID returns the Network address group ID.
+func (d *addressGroup) ID() int64 {
+	return d.id
+}
+
+// Project returns the project name.
+func (d *addressGroup) Project() string {
+	return d.projectName
+}
+
+// Info returns copy of internal info for the Network address group.
+func (d *addressGroup) Info() *api.NetworkAddressGroup {
+	// Copy internal info to prevent modification externally.
+	info := api.NetworkAddressGroup{}
+	info.Name = d.info.Name
+	info.Description = d.info.Description
+	info.Addresses = append(make([]string, 0, len(d.info.Addresses)), d.info.Addresses...)
+	info.Ports = append(make([]string, 0, len(d.info.Ports)), d.info.Ports...)
+	info.Config = util.CopyConfig(d.info.Config)
+	info.UsedBy = nil // To indicate its not populated (use Usedby() function to populate).
+
+	return &info
+}
+
+// Etag returns the values used for etag generation.
+func (d *addressGroup) Etag() []any {
+	return []any{d.info.Name, d.info.Description, d.info.Addresses, d.info.Ports, d.info.Config}
+}
+
+// usedByACLs returns the names of the ACLs whose rules reference this address group.
+func (d *addressGroup) usedByACLs(firstOnly bool) ([]string, error) {
+	aclNames := []string{}
+
+	err := d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		projectACLNames, err := tx.GetNetworkACLs(ctx, d.projectName)
+		if err != nil {
+			return err
+		}
+
+		for _, aclName := range projectACLNames {
+			_, aclInfo, err := tx.GetNetworkACL(ctx, d.projectName, aclName)
+			if err != nil {
+				return err
+			}
+
+			rules := append(append([]api.NetworkACLRule{}, aclInfo.Ingress...), aclInfo.Egress...)
+			if shared.ValueInSlice(d.info.Name, ruleAddressGroups(rules...)) {
+				aclNames = append(aclNames, aclName)
+
+				if firstOnly {
+					return nil
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting address group usage: %w", err)
+	}
+
+	return aclNames, nil
+}
+
+// UsedBy returns a list of API endpoints referencing this address group.
+func (d *addressGroup) UsedBy() ([]string, error) {
+	aclNames, err := d.usedByACLs(false)
+	if err != nil {
+		return nil, err
+	}
+
+	usedBy := make([]string, 0, len(aclNames))
+	for _, aclName := range aclNames {
+		usedBy = append(usedBy, api.NewURL().Path(version.APIVersion, "network-acls", aclName).Project(d.projectName).String())
+	}
+
+	return usedBy, nil
+}
+
+// isUsed returns whether or not the address group is in use.
+func (d *addressGroup) isUsed() (bool, error) {
+	aclNames, err := d.usedByACLs(true)
+	if err != nil {
+		return false, err
+	}
+
+	return len(aclNames) > 0, nil
+}
+
+// validateName checks name is valid.
+func (d *addressGroup) validateName(name string) error {
+	return ValidName(name)
+}
+
+// validateConfig checks the config, addresses and ports are valid.
+func (d *addressGroup) validateConfig(info *api.NetworkAddressGroupPut) error {
+	for k := range info.Config {
+		// User keys are not validated.
+		if shared.IsUserConfig(k) {
+			continue
+		}
+
+		return fmt.Errorf("Invalid config option %q", k)
+	}
+
+	for i := range info.Addresses {
+		info.Addresses[i] = strings.TrimSpace(info.Addresses[i])
+
+		// Subnets must not have host bits set so that they can be used as set elements.
+		if validate.IsNetworkAddress(info.Addresses[i]) != nil && validate.IsNetwork(info.Addresses[i]) != nil {
+			return fmt.Errorf("Invalid address %q: Must be an IP address or a subnet in CIDR notation", info.Addresses[i])
+		}
+
+		if slices.Contains(info.Addresses[:i], info.Addresses[i]) {
+			return fmt.Errorf("Duplicate address %q", info.Addresses[i])
+		}
+	}
+
+	for i := range info.Ports {
+		info.Ports[i] = strings.TrimSpace(info.Ports[i])
+
+		err := validate.IsNetworkPortRange(info.Ports[i])
+		if err != nil {
+			return fmt.Errorf("Invalid port %q: %w", info.Ports[i], err)
+		}
+
+		if slices.Contains(info.Ports[:i], info.Ports[i]) {
+			return fmt.Errorf("Duplicate port %q", info.Ports[i])
+		}
+	}
+
+	return nil
+}
+
+// firewallAddressSet returns the address set holding the members of the address group in the firewall.
+func (d *addressGroup) firewallAddressSet() firewallDrivers.AddressSet {
+	return firewallDrivers.AddressSet{
+		Name:      d.info.Name,
+		Addresses: d.info.Addresses,
+		Ports:     d.info.Ports,
+	}
+}
+
+// ovnAddresses returns the addresses of the address group as subnets for the OVN address sets.
+func (d *addressGroup) ovnAddresses() []net.IPNet {
+	subnets := make([]net.IPNet, 0, len(d.info.Addresses))
+	for _, address := range d.info.Addresses {
+		_, subnet, err := net.ParseCIDR(address)
+		if err != nil {
+			ip := net.ParseIP(address)
+			if ip == nil {
+				continue
+			}
+
+			subnet = &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}
+			if ip.To4() != nil {
+				subnet = &net.IPNet{IP: ip.To4(), Mask: net.CIDRMask(32, 32)}
+			}
+		}
+
+		subnets = append(subnets, *subnet)
+	}
+
+	return subnets
+}
+
+// Update applies the supplied config to the address group and to the firewall of the networks using ACLs that
+// reference it.
+func (d *addressGroup) Update(config *api.NetworkAddressGroupPut, clientType request.ClientType) error {
+	err := d.validateConfig(config)
+	if err != nil {
+		return err
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	oldConfig := d.info.Writable()
+
+	if clientType == request.ClientTypeNormal {
+		err = d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			// Update database. Its important this occurs before we attempt to apply to networks using the
+			// address group as the ACL rules are loaded from the database.
+			return tx.UpdateNetworkAddressGroup(ctx, d.id, *config)
+		})
+		if err != nil {
+			return err
+		}
+
+		revert.Add(func() {
+			_ = d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+				return tx.UpdateNetworkAddressGroup(ctx, d.id, oldConfig)
+			})
+
+			d.info.SetWritable(oldConfig)
+			d.init(d.state, d.id, d.projectName, d.info)
+		})
+	}
+
+	// Apply changes internally and reinitialise.
+	d.info.SetWritable(*config)
+	d.init(d.state, d.id, d.projectName, d.info)
+
+	// Get a list of networks that are using ACLs referencing this address group.
+	aclNames, err := d.usedByACLs(false)
+	if err != nil {
+		return err
+	}
+
+	aclNets := map[string]NetworkACLUsage{}
+	err = NetworkUsage(d.state, d.projectName, aclNames, aclNets)
+	if err != nil {
+		return fmt.Errorf("Failed getting ACL network usage: %w", err)
+	}
+
+	// Separate out OVN networks from non-OVN networks, as OVN networks share the address sets of the group.
+	aclOVNNets := map[string]NetworkACLUsage{}
+	for k, v := range aclNets {
+		if v.Type == "ovn" {
+			delete(aclNets, k)
+			aclOVNNets[k] = v
+		}
+	}
+
+	// Replace the members of the group in the firewall of non-OVN networks on this member. The rules themselves
+	// don't change, so they aren't applied again and keep their counters.
+	for _, aclNet := range aclNets {
+		err = d.state.Firewall.NetworkUpdateAddressSets(aclNet.Name, []firewallDrivers.AddressSet{d.firewallAddressSet()})
+		if err != nil {
+			return fmt.Errorf("Failed updating address group %q in the firewall of network %q: %w", d.info.Name, aclNet.Name, err)
+		}
+
+		revert.Add(func() {
+			oldGroup := &addressGroup{}
+			oldGroup.init(d.state, d.id, d.projectName, &api.NetworkAddressGroup{Name: d.info.Name, Addresses: oldConfig.Addresses, Ports: oldConfig.Ports})
+			_ = d.state.Firewall.NetworkUpdateAddressSets(aclNet.Name, []firewallDrivers.AddressSet{oldGroup.firewallAddressSet()})
+		})
+	}
+
+	// If there are affected OVN networks, then update the address sets of the group in place, but only if the
+	// request type is normal. This way we won't apply the same changes multiple times for each cluster member.
+	if len(aclOVNNets) > 0 && clientType == request.ClientTypeNormal {
+		client, err := openvswitch.NewOVN(d.state)
+		if err != nil {
+			return fmt.Errorf("Failed to get OVN client: %w", err)
+		}
+
+		addressSetPrefix := ovnAddressGroupAddressSetPrefix(d.id)
+		err = client.AddressSetSet(addressSetPrefix, d.ovnAddresses()...)
+		if err != nil {
+			return fmt.Errorf("Failed updating address set %q: %w", addressSetPrefix, err)
+		}
+
+		revert.Add(func() {
+			oldGroup := &addressGroup{}
+			oldGroup.init(d.state, d.id, d.projectName, &api.NetworkAddressGroup{Addresses: oldConfig.Addresses})
+			_ = client.AddressSetSet(addressSetPrefix, oldGroup.ovnAddresses()...)
+		})
+
+		// The ports are expanded into the OVN ACL rules, so those need to be applied again if they changed.
+		if !slices.Equal(oldConfig.Ports, d.info.Ports) {
+			var aclNameIDs map[string]int64
+
+			err = d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+				var err error
+
+				aclNameIDs, err = tx.GetNetworkACLIDsByNames(ctx, d.projectName)
+
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("Failed getting network ACL IDs for address group update: %w", err)
+			}
+
+			cleanup, err := OVNEnsureACLs(d.state, d.logger, client, d.projectName, aclNameIDs, aclOVNNets, aclNames, true)
+			if err != nil {
+				return fmt.Errorf("Failed ensuring ACLs are configured in OVN: %w", err)
+			}
+
+			revert.Add(cleanup)
+		}
+	}
+
+	// Apply the changes to non-OVN networks on other cluster members.
+	if clientType == request.ClientTypeNormal && len(aclNets) > 0 {
+		notifier, err := cluster.NewNotifier(d.state, d.state.Endpoints.NetworkCert(), d.state.ServerCert(), cluster.NotifyAll)
+		if err != nil {
+			return err
+		}
+
+		err = notifier(func(client lxd.InstanceServer) error {
+			return client.UseProject(d.projectName).UpdateNetworkAddressGroup(d.info.Name, d.info.Writable(), "")
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	revert.Success()
+	return nil
+}
+
+// Rename renames the address group if not in use.
+func (d *addressGroup) Rename(newName string) error {
+	_, err := LoadAddressGroupByName(d.state, d.projectName, newName)
+	if err == nil {
+		return fmt.Errorf("An address group by that name exists already")
+	}
+
+	isUsed, err := d.isUsed()
+	if err != nil {
+		return err
+	}
+
+	if isUsed {
+		return fmt.Errorf("Cannot rename an address group that is in use")
+	}
+
+	err = d.validateName(newName)
+	if err != nil {
+		return err
+	}
+
+	err = d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.RenameNetworkAddressGroup(ctx, d.id, newName)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Apply changes internally.
+	d.info.Name = newName
+
+	return nil
+}
+
+// Delete deletes the address group if not in use.
+func (d *addressGroup) Delete() error {
+	isUsed, err := d.isUsed()
+	if err != nil {
+		return err
+	}
+
+	if isUsed {
+		return fmt.Errorf("Cannot delete an address group that is in use")
+	}
+
+	hasOVNNetworks := false
+
+	err = d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		networks, err := tx.GetCreatedNetworksByProject(ctx, d.projectName)
+		if err != nil {
+			return err
+		}
+
+		for _, network := range networks {
+			if network.Type == "ovn" {
+				hasOVNNetworks = true
+				break
+			}
+		}
+
+		return tx.DeleteNetworkAddressGroup(ctx, d.id)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Remove the OVN address sets that ACLs previously referencing the group may have left behind.
+	if hasOVNNetworks {
+		client, err := openvswitch.NewOVN(d.state)
+		if err != nil {
+			return fmt.Errorf("Failed to get OVN client: %w", err)
+		}
+
+		err = client.AddressSetDelete(ovnAddressGroupAddressSetPrefix(d.id))
+		if err != nil {
+			return fmt.Errorf("Failed deleting address sets of address group %q: %w", d.info.Name, err)
+		}
+	}
+
+	return nil
+}
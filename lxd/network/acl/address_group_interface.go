@@ -0,0 +1,29 @@
+package acl
+
+import (
+	"github.com/canonical/lxd/lxd/cluster/request"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// NetworkAddressGroup represents a Network address group.
+type NetworkAddressGroup interface {
+	// Initialise.
+	init(state *state.State, id int64, projectName string, groupInfo *api.NetworkAddressGroup)
+
+	// Info.
+	ID() int64
+	Project() string
+	Info() *api.NetworkAddressGroup
+	Etag() []any
+	UsedBy() ([]string, error)
+
+	// Internal validation.
+	validateName(name string) error
+	validateConfig(config *api.NetworkAddressGroupPut) error
+
+	// Modifications.
+	Update(config *api.NetworkAddressGroupPut, clientType request.ClientType) error
+	Rename(newName string) error
+	Delete() error
+}
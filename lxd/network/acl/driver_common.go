@@ -300,12 +300,19 @@ func (d *common) validateRule(direction ruleDirection, rule api.NetworkACLRule)
 	}
 
 	var acls map[string]int64
+	var addressGroupNames []string
 
 	err := d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 		var err error
 
 		// Get map of ACL names to DB IDs (used for generating OVN port group names).
 		acls, err = tx.GetNetworkACLIDsByNames(ctx, d.Project())
+		if err != nil {
+			return err
+		}
+
+		// Get the address groups that can be referenced by the rule.
+		addressGroupNames, err = tx.GetNetworkAddressGroups(ctx, d.Project())
 
 		return err
 	})
@@ -326,7 +333,7 @@ func (d *common) validateRule(direction ruleDirection, rule api.NetworkACLRule)
 
 	// Validate Source field.
 	if rule.Source != "" {
-		srcHasName, srcHasIPv4, srcHasIPv6, err = d.validateRuleSubjects("Source", direction, shared.SplitNTrimSpace(rule.Source, ",", -1, false), validSubjectNames)
+		srcHasName, srcHasIPv4, srcHasIPv6, err = d.validateRuleSubjects("Source", direction, shared.SplitNTrimSpace(rule.Source, ",", -1, false), validSubjectNames, addressGroupNames)
 		if err != nil {
 			return fmt.Errorf("Invalid Source: %w", err)
 		}
@@ -334,7 +341,7 @@ func (d *common) validateRule(direction ruleDirection, rule api.NetworkACLRule)
 
 	// Validate Destination field.
 	if rule.Destination != "" {
-		dstHasName, dstHasIPv4, dstHasIPv6, err = d.validateRuleSubjects("Destination", direction, shared.SplitNTrimSpace(rule.Destination, ",", -1, false), validSubjectNames)
+		dstHasName, dstHasIPv4, dstHasIPv6, err = d.validateRuleSubjects("Destination", direction, shared.SplitNTrimSpace(rule.Destination, ",", -1, false), validSubjectNames, addressGroupNames)
 		if err != nil {
 			return fmt.Errorf("Invalid Destination: %w", err)
 		}
@@ -370,7 +377,7 @@ func (d *common) validateRule(direction ruleDirection, rule api.NetworkACLRule)
 
 		// Validate SourcePort field.
 		if rule.SourcePort != "" {
-			err := d.validatePorts(shared.SplitNTrimSpace(rule.SourcePort, ",", -1, false))
+			err := d.validatePorts(shared.SplitNTrimSpace(rule.SourcePort, ",", -1, false), addressGroupNames)
 			if err != nil {
 				return fmt.Errorf("Invalid Source port: %w", err)
 			}
@@ -378,7 +385,7 @@ func (d *common) validateRule(direction ruleDirection, rule api.NetworkACLRule)
 
 		// Validate DestinationPort field.
 		if rule.DestinationPort != "" {
-			err := d.validatePorts(shared.SplitNTrimSpace(rule.DestinationPort, ",", -1, false))
+			err := d.validatePorts(shared.SplitNTrimSpace(rule.DestinationPort, ",", -1, false), addressGroupNames)
 			if err != nil {
 				return fmt.Errorf("Invalid Destination port: %w", err)
 			}
@@ -446,10 +453,44 @@ func (d *common) validateRule(direction ruleDirection, rule api.NetworkACLRule)
 	return nil
 }
 
+// validateAddressGroupReference checks that an address group referenced by the values of a rule field exists and
+// is the only value of the field. Returns whether the values reference an address group.
+func (d *common) validateAddressGroupReference(values []string, addressGroupNames []string) (bool, error) {
+	for _, value := range values {
+		groupName, found := addressGroupReference(value)
+		if !found {
+			continue
+		}
+
+		if len(values) > 1 {
+			return false, fmt.Errorf("Address group %q cannot be combined with other values", value)
+		}
+
+		if !shared.ValueInSlice(groupName, addressGroupNames) {
+			return false, fmt.Errorf("Address group %q not found", groupName)
+		}
+
+		return true, nil
+	}
+
+	return false, nil
+}
+
 // validateRuleSubjects checks that the source or destination subjects for a rule are valid.
-// Accepts a validSubjectNames list of valid ACL or special classifier names.
+// Accepts a validSubjectNames list of valid ACL or special classifier names and a addressGroupNames list of
+// address groups that can be referenced using the "$<group>" format.
 // Returns whether the subjects include names, IPv4 and IPv6 addresses respectively.
-func (d *common) validateRuleSubjects(fieldName string, direction ruleDirection, subjects []string, validSubjectNames []string) (hasName bool, hasIPv4 bool, hasIPv6 bool, err error) {
+func (d *common) validateRuleSubjects(fieldName string, direction ruleDirection, subjects []string, validSubjectNames []string, addressGroupNames []string) (hasName bool, hasIPv4 bool, hasIPv6 bool, err error) {
+	// Address groups can contain both IPv4 and IPv6 addresses so are treated as names for the family checks.
+	isAddressGroup, err := d.validateAddressGroupReference(subjects, addressGroupNames)
+	if err != nil {
+		return false, false, false, err
+	}
+
+	if isAddressGroup {
+		return true, false, false, nil
+	}
+
 	// Check if named subjects are allowed in field/direction combination.
 	allowSubjectNames := false
 	if (fieldName == "Source" && direction == ruleDirectionIngress) || (fieldName == "Destination" && direction == ruleDirectionEgress) {
@@ -563,7 +604,12 @@ func (d *common) validateRuleSubjects(fieldName string, direction ruleDirection,
 }
 
 // validatePorts checks that the source or destination ports for a rule are valid.
-func (d *common) validatePorts(ports []string) error {
+func (d *common) validatePorts(ports []string, addressGroupNames []string) error {
+	isAddressGroup, err := d.validateAddressGroupReference(ports, addressGroupNames)
+	if err != nil || isAddressGroup {
+		return err
+	}
+
 	for _, port := range ports {
 		err := validate.IsNetworkPortRange(port)
 		if err != nil {
@@ -624,7 +670,7 @@ func (d *common) Update(config *api.NetworkACLPut, clientType request.ClientType
 		if v.Type == "ovn" {
 			delete(aclNets, k)
 			aclOVNNets[k] = v
-		} else if v.Type != "bridge" {
+		} else if !shared.ValueInSlice(v.Type, []string{"bridge", "wireguard"}) {
 			return fmt.Errorf("Unsupported network ACL type %q", v.Type)
 		}
 	}
@@ -835,3 +881,80 @@ func (d *common) GetLog(clientType request.ClientType) (string, error) {
 
 	return strings.Join(logEntries, "\n") + "\n", nil
 }
+
+// GetState gets the counters of the ACL rules applied to the firewall of the networks using the ACL.
+// OVN networks don't provide rule counters and so aren't accounted for.
+func (d *common) GetState(clientType request.ClientType) (*api.NetworkACLState, error) {
+	aclState := &api.NetworkACLState{
+		Ingress: make([]api.NetworkACLRuleState, len(d.info.Ingress)),
+		Egress:  make([]api.NetworkACLRuleState, len(d.info.Egress)),
+	}
+
+	// addCounters adds the counters of a set of rules to the ones of the ACL.
+	addCounters := func(rules []api.NetworkACLRuleState, counters []api.NetworkACLRuleState) {
+		// Skip the counters of rules that don't match the current ones.
+		if len(rules) != len(counters) {
+			return
+		}
+
+		for i := range rules {
+			rules[i].Packets += counters[i].Packets
+			rules[i].Bytes += counters[i].Bytes
+		}
+	}
+
+	ruleCounters, err := FirewallACLRuleCounters(d.state)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ruleCounter := range ruleCounters {
+		if ruleCounter.ACLID != d.id {
+			continue
+		}
+
+		rules := aclState.Ingress
+		if ruleCounter.Direction == string(ruleDirectionEgress) {
+			rules = aclState.Egress
+		}
+
+		// Skip the rules that have been removed since the ACL was applied.
+		if ruleCounter.Index >= len(rules) {
+			continue
+		}
+
+		rules[ruleCounter.Index].Packets += ruleCounter.Packets
+		rules[ruleCounter.Index].Bytes += ruleCounter.Bytes
+	}
+
+	// Aggregates the counters from the rest of the cluster.
+	if clientType == request.ClientTypeNormal {
+		// Setup notifier to reach the rest of the cluster.
+		notifier, err := cluster.NewNotifier(d.state, d.state.Endpoints.NetworkCert(), d.state.ServerCert(), cluster.NotifyAll)
+		if err != nil {
+			return nil, err
+		}
+
+		mu := sync.Mutex{}
+		err = notifier(func(client lxd.InstanceServer) error {
+			memberState, err := client.UseProject(d.projectName).GetNetworkACLState(d.info.Name)
+			if err != nil {
+				return err
+			}
+
+			// Prevent concurrent writes to the counters.
+			mu.Lock()
+			defer mu.Unlock()
+
+			addCounters(aclState.Ingress, memberState.Ingress)
+			addCounters(aclState.Egress, memberState.Egress)
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return aclState, nil
+}
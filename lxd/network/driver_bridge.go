@@ -25,11 +25,13 @@ import (
 	"github.com/canonical/lxd/lxd/db"
 	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
 	"github.com/canonical/lxd/lxd/db/warningtype"
+	"github.com/canonical/lxd/lxd/dhcpd"
 	"github.com/canonical/lxd/lxd/dnsmasq"
 	"github.com/canonical/lxd/lxd/dnsmasq/dhcpalloc"
 	firewallDrivers "github.com/canonical/lxd/lxd/firewall/drivers"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/ip"
+	"github.com/canonical/lxd/lxd/locking"
 	"github.com/canonical/lxd/lxd/network/acl"
 	"github.com/canonical/lxd/lxd/network/openvswitch"
 	"github.com/canonical/lxd/lxd/project"
@@ -70,6 +72,9 @@ func (n *bridge) DBType() db.NetworkType {
 func (n *bridge) Info() Info {
 	info := n.common.Info()
 	info.AddressForwards = true
+	info.LoadBalancers = true
+	info.Peering = true
+	info.IPAM = true
 
 	return info
 }
@@ -195,6 +200,12 @@ func (n *bridge) ValidateName(name string) error {
 
 // Validate network config.
 func (n *bridge) Validate(config map[string]string) error {
+	return n.validateConfig(config, nil)
+}
+
+// validateConfig validates the bridge config along with the additional rules of the network types that are
+// based on the bridge network type.
+func (n *bridge) validateConfig(config map[string]string, driverRules map[string]func(value string) error) error {
 	// Build driver specific rules dynamically.
 	rules := map[string]func(value string) error{
 		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=bgp.peers.NAME.address)
@@ -229,6 +240,78 @@ func (n *bridge) Validate(config map[string]string) error {
 		//  required: no
 		//  shortdesc: Peer session hold time
 
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=bgp.peers.NAME.import.prefixes)
+		// Comma-separated list of prefixes. Only the routes for these prefixes are accepted from the peer. Add a maximum prefix length to also match more specific prefixes (for example, `10.0.0.0/8..24`).
+		// ---
+		//  type: string
+		//  condition: BGP server
+		//  defaultdesc: (all prefixes)
+		//  required: no
+		//  shortdesc: Prefixes accepted from the peer
+
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=bgp.peers.NAME.import.communities)
+		// Comma-separated list of communities in the `ASN:VALUE` format. Only the routes tagged with one of these communities are accepted from the peer.
+		// ---
+		//  type: string
+		//  condition: BGP server
+		//  defaultdesc: (all routes)
+		//  required: no
+		//  shortdesc: Communities required on the routes accepted from the peer
+
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=bgp.peers.NAME.import.local_pref)
+		//
+		// ---
+		//  type: integer
+		//  condition: BGP server
+		//  defaultdesc: (unchanged)
+		//  required: no
+		//  shortdesc: Local preference set on the routes accepted from the peer
+
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=bgp.peers.NAME.export.prefixes)
+		// Comma-separated list of prefixes. Only these prefixes are advertised to the peer. Add a maximum prefix length to also match more specific prefixes (for example, `10.0.0.0/8..24`).
+		// ---
+		//  type: string
+		//  condition: BGP server
+		//  defaultdesc: (all prefixes)
+		//  required: no
+		//  shortdesc: Prefixes advertised to the peer
+
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=bgp.peers.NAME.export.communities)
+		// Comma-separated list of communities in the `ASN:VALUE` format.
+		// ---
+		//  type: string
+		//  condition: BGP server
+		//  defaultdesc: (no communities)
+		//  required: no
+		//  shortdesc: Communities added to the routes advertised to the peer
+
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=bgp.peers.NAME.export.med)
+		//
+		// ---
+		//  type: integer
+		//  condition: BGP server
+		//  defaultdesc: (unchanged)
+		//  required: no
+		//  shortdesc: MED set on the routes advertised to the peer
+
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=bgp.peers.NAME.install_routes)
+		// The best routes accepted from the peer are added to the host routing table, for example to fail over between uplinks.
+		// ---
+		//  type: bool
+		//  condition: BGP server
+		//  defaultdesc: `false`
+		//  required: no
+		//  shortdesc: Whether to install the routes received from the peer
+
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=bgp.peers.NAME.graceful_restart)
+		//
+		// ---
+		//  type: bool
+		//  condition: BGP server
+		//  defaultdesc: `true`
+		//  required: no
+		//  shortdesc: Whether to enable graceful restart for the session
+
 		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=bgp.ipv4.nexthop)
 		//
 		// ---
@@ -520,6 +603,15 @@ func (n *bridge) Validate(config map[string]string) error {
 		//  type: string
 		//  shortdesc: IPv6 ranges to use for child OVN network routers
 		"ipv6.ovn.ranges": validate.Optional(validate.IsListOf(validate.IsNetworkRangeV6)),
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=dhcp.backend)
+		// Possible values are `dnsmasq` and `native`.
+		// The `native` backend runs the DHCP, router advertisement and DNS services inside LXD and stores the leases in the LXD database.
+		// It can't be used in fan mode or with `raw.dnsmasq`.
+		// ---
+		//  type: string
+		//  defaultdesc: `dnsmasq`
+		//  shortdesc: Backend providing the DHCP and DNS services
+		"dhcp.backend": validate.Optional(validate.IsOneOf("dnsmasq", "native")),
 		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=dns.domain)
 		//
 		// ---
@@ -731,6 +823,10 @@ func (n *bridge) Validate(config map[string]string) error {
 		rules[k] = v
 	}
 
+	for k, v := range driverRules {
+		rules[k] = v
+	}
+
 	// Validate the configuration.
 	err = n.validate(config, rules)
 	if err != nil {
@@ -797,6 +893,28 @@ func (n *bridge) Validate(config map[string]string) error {
 		}
 	}
 
+	// Check the native DHCP server settings.
+	if config["dhcp.backend"] == "native" {
+		if bridgeMode == "fan" {
+			return fmt.Errorf(`The native DHCP server can't be used in "fan" mode`)
+		}
+
+		if config["raw.dnsmasq"] != "" {
+			return fmt.Errorf(`"raw.dnsmasq" can't be used with the native DHCP server`)
+		}
+
+		for _, key := range []string{"ipv4.dhcp.expiry", "ipv6.dhcp.expiry"} {
+			if config[key] == "" {
+				continue
+			}
+
+			_, err := dhcpd.ParseExpiry(config[key])
+			if err != nil {
+				return fmt.Errorf("Invalid value for %q: %w", key, err)
+			}
+		}
+	}
+
 	// Check using same MAC address on every cluster node is safe.
 	if config["bridge.hwaddr"] != "" {
 		err = n.checkClusterWideMACSafe(config)
@@ -892,6 +1010,12 @@ func (n *bridge) Create(clientType request.ClientType) error {
 		return fmt.Errorf("Network interface %q already exists", n.name)
 	}
 
+	// Check the subnets of the bridge aren't already in use.
+	err := n.ipamCheckSubnetConflicts(n.config)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -911,8 +1035,14 @@ func (n *bridge) Delete(clientType request.ClientType) error {
 		}
 	}
 
+	// Remove the peerings with this network from the firewall of the peer networks.
+	err := n.peerSetupTargetsFirewall(n.ID())
+	if err != nil {
+		return err
+	}
+
 	// Delete apparmor profiles.
-	err := apparmor.NetworkDelete(n.state.OS, n)
+	err = apparmor.NetworkDelete(n.state.OS, n)
 	if err != nil {
 		return err
 	}
@@ -1304,8 +1434,10 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		return err
 	}
 
+	ipv4AddressConfig := n.ipv4AddressConfig()
+
 	// Configure IPv4 firewall (includes fan).
-	if n.config["bridge.mode"] == "fan" || !shared.ValueInSlice(n.config["ipv4.address"], []string{"", "none"}) {
+	if n.config["bridge.mode"] == "fan" || !shared.ValueInSlice(ipv4AddressConfig, []string{"", "none"}) {
 		if n.hasDHCPv4() && n.hasIPv4Firewall() {
 			fwOpts.FeaturesV4.ICMPDHCPDNSAccess = true
 		}
@@ -1331,9 +1463,13 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		"--no-ping",   // --no-ping is very important to prevent delays to lease file updates.
 		fmt.Sprintf("--interface=%s", n.name)}
 
-	dnsmasqVersion, err := dnsmasq.GetVersion()
-	if err != nil {
-		return err
+	// The native DHCP server doesn't need dnsmasq to be installed.
+	dnsmasqVersion := &version.DottedVersion{}
+	if !n.UsesNativeDHCP() {
+		dnsmasqVersion, err = dnsmasq.GetVersion()
+		if err != nil {
+			return err
+		}
 	}
 
 	// --dhcp-rapid-commit option is only supported on >2.79.
@@ -1360,11 +1496,11 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 	var ipv4Address net.IP
 
 	// Configure IPv4.
-	if !shared.ValueInSlice(n.config["ipv4.address"], []string{"", "none"}) {
+	if !shared.ValueInSlice(ipv4AddressConfig, []string{"", "none"}) {
 		var subnet *net.IPNet
 
 		// Parse the subnet.
-		ipv4Address, subnet, err = net.ParseCIDR(n.config["ipv4.address"])
+		ipv4Address, subnet, err = net.ParseCIDR(ipv4AddressConfig)
 		if err != nil {
 			return fmt.Errorf("Failed parsing ipv4.address: %w", err)
 		}
@@ -1407,7 +1543,7 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		// Add the address.
 		addr := &ip.Addr{
 			DevName: n.name,
-			Address: n.config["ipv4.address"],
+			Address: ipv4AddressConfig,
 			Family:  ip.FamilyV4,
 		}
 
@@ -1972,6 +2108,19 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		return err
 	}
 
+	// Stop any existing native DHCP server for this network.
+	n.nativeDHCPStop()
+
+	// Remove the leases of the native DHCP server when switching to dnsmasq.
+	if oldConfig["dhcp.backend"] == "native" && !n.UsesNativeDHCP() {
+		err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.DeleteNetworkLeases(ctx, n.id)
+		})
+		if err != nil {
+			return fmt.Errorf("Failed removing native DHCP leases: %w", err)
+		}
+	}
+
 	// Configure dnsmasq.
 	if n.UsesDNSMasq() {
 		// Setup the dnsmasq domain.
@@ -2125,6 +2274,16 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 				return fmt.Errorf("Failed to remove old dnsmasq pid file %q: %w", pidPath, err)
 			}
 		}
+
+		// Start the native DHCP server.
+		if n.UsesNativeDHCP() && (!shared.ValueInSlice(ipv4AddressConfig, []string{"", "none"}) || !shared.ValueInSlice(n.config["ipv6.address"], []string{"", "none"})) {
+			err = n.nativeDHCPStart(bridge.MTU)
+			if err != nil {
+				return err
+			}
+
+			revert.Add(n.nativeDHCPStop)
+		}
 	}
 
 	// Setup firewall.
@@ -2147,6 +2306,8 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		if err != nil {
 			return err
 		}
+	} else {
+		acl.FirewallClearACLRules(n.name)
 	}
 
 	// Setup network address forwards.
@@ -2155,6 +2316,23 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		return err
 	}
 
+	// Setup network load balancers.
+	err = n.loadBalancerSetupFirewall()
+	if err != nil {
+		return err
+	}
+
+	// Setup network peerings, and refresh the peer networks in case our subnets changed.
+	err = n.peerSetupFirewall()
+	if err != nil {
+		return err
+	}
+
+	err = n.peerSetupTargetsFirewall()
+	if err != nil {
+		return err
+	}
+
 	// Setup BGP.
 	err = n.bgpSetup(oldConfig)
 	if err != nil {
@@ -2169,6 +2347,12 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 func (n *bridge) Stop() error {
 	n.logger.Debug("Stop")
 
+	// Stop the load balancer health checks.
+	loadBalancerMonitorsStop(n.id)
+
+	// Stop the native DHCP server.
+	n.nativeDHCPStop()
+
 	if !n.isRunning() {
 		return nil
 	}
@@ -2213,6 +2397,8 @@ func (n *bridge) Stop() error {
 		}
 	}
 
+	acl.FirewallClearACLRules(n.name)
+
 	// Kill any existing dnsmasq and forkdns daemon for this network
 	err = dnsmasq.Kill(n.name, false)
 	if err != nil {
@@ -2276,6 +2462,19 @@ func (n *bridge) Update(newNetwork api.NetworkPut, targetNode string, clientType
 		return n.common.update(newNetwork, targetNode, clientType)
 	}
 
+	// Check the new subnets aren't already in use and still contain the IP ranges of the network.
+	if shared.ValueInSlice("ipv4.address", changedKeys) || shared.ValueInSlice("ipv6.address", changedKeys) {
+		err = n.ipamCheckSubnetConflicts(newNetwork.Config)
+		if err != nil {
+			return err
+		}
+
+		err = n.ipamCheckSubnetChange(newNetwork.Config)
+		if err != nil {
+			return err
+		}
+	}
+
 	revert := revert.New()
 	defer revert.Fail()
 
@@ -2678,11 +2877,38 @@ func (n *bridge) updateForkdnsServersFile(addresses []string) error {
 	return nil
 }
 
+// ipv4AddressConfig returns the IPv4 address of the bridge interface in CIDR notation. WireGuard networks don't
+// have an ipv4.address setting and use the first address of the subnet allocated to the local cluster member.
+func (n *bridge) ipv4AddressConfig() string {
+	if n.netType != "wireguard" {
+		return n.config["ipv4.address"]
+	}
+
+	_, subnet, err := net.ParseCIDR(n.config[wireguardVolatileSubnet])
+	if err != nil {
+		return ""
+	}
+
+	prefixLen, _ := subnet.Mask.Size()
+
+	return fmt.Sprintf("%s/%d", dhcpalloc.GetIP(subnet, 1), prefixLen)
+}
+
+// AddressCIDR returns the address of the bridge interface in CIDR notation for the given IP version. This is the
+// subnet the instances connected to the network get their addresses from.
+func (n *bridge) AddressCIDR(ipVersion uint) string {
+	if ipVersion == 4 {
+		return n.ipv4AddressConfig()
+	}
+
+	return n.config["ipv6.address"]
+}
+
 // hasIPv4Firewall indicates whether the network has IPv4 firewall enabled.
 func (n *bridge) hasIPv4Firewall() bool {
 	// IPv4 firewall is only enabled if there is a bridge ipv4.address or fan mode, and ipv4.firewall enabled.
 	// When using fan bridge.mode, there can be an empty ipv4.address, so we assume it is active.
-	if (n.config["bridge.mode"] == "fan" || !shared.ValueInSlice(n.config["ipv4.address"], []string{"", "none"})) && shared.IsTrueOrEmpty(n.config["ipv4.firewall"]) {
+	if (n.config["bridge.mode"] == "fan" || !shared.ValueInSlice(n.ipv4AddressConfig(), []string{"", "none"})) && shared.IsTrueOrEmpty(n.config["ipv4.firewall"]) {
 		return true
 	}
 
@@ -2746,7 +2972,7 @@ func (n *bridge) DHCPv4Subnet() *net.IPNet {
 	}
 
 	// Non-fan mode. Return configured bridge subnet directly.
-	_, subnet, err := net.ParseCIDR(n.config["ipv4.address"])
+	_, subnet, err := net.ParseCIDR(n.ipv4AddressConfig())
 	if err != nil {
 		return nil
 	}
@@ -2951,6 +3177,7 @@ func (n *bridge) getExternalSubnetInUse() ([]externalSubnetUsage, error) {
 	var err error
 	var projectNetworks map[string]map[int64]api.Network
 	var projectNetworksForwardsOnUplink map[string]map[int64][]string
+	var projectNetworksLoadBalancersOnUplink map[string]map[int64][]string
 	var externalSubnets []externalSubnetUsage
 
 	err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
@@ -2966,6 +3193,12 @@ func (n *bridge) getExternalSubnetInUse() ([]externalSubnetUsage, error) {
 			return fmt.Errorf("Failed loading network forward listen addresses: %w", err)
 		}
 
+		// Get all network load balancer listen addresses for load balancers assigned to this specific cluster member.
+		projectNetworksLoadBalancersOnUplink, err = tx.GetProjectNetworkLoadBalancerListenAddressesOnMember(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed loading network load balancer listen addresses: %w", err)
+		}
+
 		externalSubnets, err = n.common.getExternalSubnetInUse(ctx, tx, n.name, true)
 		if err != nil {
 			return fmt.Errorf("Failed getting external subnets in use: %w", err)
@@ -3054,6 +3287,26 @@ func (n *bridge) getExternalSubnetInUse() ([]externalSubnetUsage, error) {
 		}
 	}
 
+	// Add load balancer listen addresses to this list.
+	for projectName, networks := range projectNetworksLoadBalancersOnUplink {
+		for networkID, listenAddresses := range networks {
+			for _, listenAddress := range listenAddresses {
+				// Convert listen address to subnet.
+				listenAddressNet, err := ParseIPToNet(listenAddress)
+				if err != nil {
+					return nil, fmt.Errorf("Invalid existing load balancer listen address %q", listenAddress)
+				}
+
+				externalSubnets = append(externalSubnets, externalSubnetUsage{
+					subnet:         *listenAddressNet,
+					networkProject: projectName,
+					networkName:    projectNetworks[projectName][networkID].Name,
+					usageType:      subnetUsageNetworkLoadBalancer,
+				})
+			}
+		}
+	}
+
 	return externalSubnets, nil
 }
 
@@ -3149,78 +3402,9 @@ func (n *bridge) ForwardCreate(forward api.NetworkForwardsPost, clientType reque
 	}
 
 	// Check if hairpin mode needs to be enabled on active NIC bridge ports.
-	if n.config["bridge.driver"] != "openvswitch" {
-		brNetfilterEnabled := false
-		for _, ipVersion := range []uint{4, 6} {
-			if BridgeNetfilterEnabled(ipVersion) == nil {
-				brNetfilterEnabled = true
-				break
-			}
-		}
-
-		// If br_netfilter is enabled and bridge has forwards, we enable hairpin mode on each NIC's bridge
-		// port in case any of the forwards target the NIC and the instance attempts to connect to the
-		// forward's listener. Without hairpin mode on the target of the forward will not be able to
-		// connect to the listener.
-		if brNetfilterEnabled {
-			var listenAddresses map[int64]string
-
-			err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-				listenAddresses, err = tx.GetNetworkForwardListenAddresses(ctx, n.ID(), true)
-
-				return err
-			})
-			if err != nil {
-				return nil, fmt.Errorf("Failed loading network forwards: %w", err)
-			}
-
-			// If we are the first forward on this bridge, enable hairpin mode on active NIC ports.
-			if len(listenAddresses) <= 1 {
-				filter := dbCluster.InstanceFilter{Node: &n.state.ServerName}
-
-				err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-					return tx.InstanceList(ctx, func(inst db.InstanceArgs, p api.Project) error {
-						// Get the instance's effective network project name.
-						instNetworkProject := project.NetworkProjectFromRecord(&p)
-
-						if instNetworkProject != api.ProjectDefaultName {
-							return nil // Managed bridge networks can only exist in default project.
-						}
-
-						devices := instancetype.ExpandInstanceDevices(inst.Devices.Clone(), inst.Profiles)
-
-						// Iterate through each of the instance's devices, looking for bridged NICs
-						// that are linked to this network.
-						for devName, devConfig := range devices {
-							if devConfig["type"] != "nic" {
-								continue
-							}
-
-							// Check whether the NIC device references our network..
-							if !NICUsesNetwork(devConfig, &api.Network{Name: n.Name()}) {
-								continue
-							}
-
-							hostName := inst.Config[fmt.Sprintf("volatile.%s.host_name", devName)]
-							if InterfaceExists(hostName) {
-								link := &ip.Link{Name: hostName}
-								err = link.BridgeLinkSetHairpin(true)
-								if err != nil {
-									return fmt.Errorf("Error enabling hairpin mode on bridge port %q: %w", link.Name, err)
-								}
-
-								n.logger.Debug("Enabled hairpin mode on NIC bridge port", logger.Ctx{"inst": inst.Name, "project": inst.Project, "device": devName, "dev": link.Name})
-							}
-						}
-
-						return nil
-					}, filter)
-				})
-				if err != nil {
-					return nil, err
-				}
-			}
-		}
+	err = n.hairpinModeSetup()
+	if err != nil {
+		return nil, err
 	}
 
 	// Refresh exported BGP prefixes on local member.
@@ -3438,81 +3622,959 @@ func (n *bridge) forwardSetupFirewall() error {
 	return nil
 }
 
-// Leases returns a list of leases for the bridged network. It will reach out to other cluster members as needed.
-// The projectName passed here refers to the initial project from the API request which may differ from the network's project.
-func (n *bridge) Leases(projectName string, clientType request.ClientType) ([]api.NetworkLease, error) {
-	var err error
-	var projectMacs []string
-	leases := []api.NetworkLease{}
+// hairpinModeSetup enables hairpin mode on the active NIC bridge ports when the first address forward or load
+// balancer is added to the bridge.
+func (n *bridge) hairpinModeSetup() error {
+	if n.config["bridge.driver"] == "openvswitch" {
+		return nil
+	}
 
-	// Get all static leases.
-	if clientType == request.ClientTypeNormal {
-		// If requested project matches network's project then include gateway and downstream uplink IPs.
-		if projectName == n.project {
-			// Add our own gateway IPs.
-			for _, addr := range []string{n.config["ipv4.address"], n.config["ipv6.address"]} {
-				ip, _, _ := net.ParseCIDR(addr)
-				if ip != nil {
-					leases = append(leases, api.NetworkLease{
-						Hostname: fmt.Sprintf("%s.gw", n.Name()),
-						Address:  ip.String(),
-						Type:     "gateway",
-					})
-				}
-			}
+	brNetfilterEnabled := false
+	for _, ipVersion := range []uint{4, 6} {
+		if BridgeNetfilterEnabled(ipVersion) == nil {
+			brNetfilterEnabled = true
+			break
+		}
+	}
 
-			// Include downstream OVN routers using the network as an uplink.
-			var projectNetworks map[string]map[int64]api.Network
-			err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-				projectNetworks, err = tx.GetCreatedNetworks(ctx)
-				return err
-			})
-			if err != nil {
-				return nil, err
-			}
+	// If br_netfilter is enabled and bridge has forwards or load balancers, we enable hairpin mode on each NIC's
+	// bridge port in case any of them target the NIC and the instance attempts to connect to the listener.
+	// Without hairpin mode on the target of the forward will not be able to connect to the listener.
+	if !brNetfilterEnabled {
+		return nil
+	}
 
-			// Look for networks using the current network as an uplink.
-			for projectName, networks := range projectNetworks {
-				for _, network := range networks {
-					if network.Config["network"] != n.name {
-						continue
-					}
+	var forwardListenAddresses, loadBalancerListenAddresses map[int64]string
 
-					// Found a network, add leases.
-					for _, k := range []string{"volatile.network.ipv4.address", "volatile.network.ipv6.address"} {
-						v := network.Config[k]
-						if v != "" {
-							leases = append(leases, api.NetworkLease{
-								Hostname: fmt.Sprintf("%s-%s.uplink", projectName, network.Name),
-								Address:  v,
-								Type:     "uplink",
-							})
-						}
-					}
-				}
-			}
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		forwardListenAddresses, err = tx.GetNetworkForwardListenAddresses(ctx, n.ID(), true)
+		if err != nil {
+			return fmt.Errorf("Failed loading network forwards: %w", err)
 		}
 
-		// Get all the instances in the requested project that are connected to this network.
-		filter := dbCluster.InstanceFilter{Project: &projectName}
-		err = UsedByInstanceDevices(n.state, n.Project(), n.Name(), n.Type(), func(inst db.InstanceArgs, nicName string, nicConfig map[string]string) error {
-			// Fill in the hwaddr from volatile.
-			if nicConfig["hwaddr"] == "" {
-				nicConfig["hwaddr"] = inst.Config[fmt.Sprintf("volatile.%s.hwaddr", nicName)]
-			}
+		loadBalancerListenAddresses, err = tx.GetNetworkLoadBalancerListenAddresses(ctx, n.ID(), true)
+		if err != nil {
+			return fmt.Errorf("Failed loading network load balancers: %w", err)
+		}
 
-			// Record the MAC.
-			hwAddr, _ := net.ParseMAC(nicConfig["hwaddr"])
-			if hwAddr != nil {
-				projectMacs = append(projectMacs, hwAddr.String())
-			}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
 
-			// Add the lease.
-			nicIP4 := net.ParseIP(nicConfig["ipv4.address"])
-			if nicIP4 != nil {
-				leases = append(leases, api.NetworkLease{
-					Hostname: inst.Name,
-					Address:  nicIP4.String(),
+	// If we are the first forward or load balancer on this bridge, enable hairpin mode on active NIC ports.
+	if len(forwardListenAddresses)+len(loadBalancerListenAddresses) > 1 {
+		return nil
+	}
+
+	filter := dbCluster.InstanceFilter{Node: &n.state.ServerName}
+
+	return n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.InstanceList(ctx, func(inst db.InstanceArgs, p api.Project) error {
+			// Get the instance's effective network project name.
+			instNetworkProject := project.NetworkProjectFromRecord(&p)
+
+			if instNetworkProject != api.ProjectDefaultName {
+				return nil // Managed bridge networks can only exist in default project.
+			}
+
+			devices := instancetype.ExpandInstanceDevices(inst.Devices.Clone(), inst.Profiles)
+
+			// Iterate through each of the instance's devices, looking for bridged NICs
+			// that are linked to this network.
+			for devName, devConfig := range devices {
+				if devConfig["type"] != "nic" {
+					continue
+				}
+
+				// Check whether the NIC device references our network..
+				if !NICUsesNetwork(devConfig, &api.Network{Name: n.Name()}) {
+					continue
+				}
+
+				hostName := inst.Config[fmt.Sprintf("volatile.%s.host_name", devName)]
+				if InterfaceExists(hostName) {
+					link := &ip.Link{Name: hostName}
+					err := link.BridgeLinkSetHairpin(true)
+					if err != nil {
+						return fmt.Errorf("Error enabling hairpin mode on bridge port %q: %w", link.Name, err)
+					}
+
+					n.logger.Debug("Enabled hairpin mode on NIC bridge port", logger.Ctx{"inst": inst.Name, "project": inst.Project, "device": devName, "dev": link.Name})
+				}
+			}
+
+			return nil
+		}, filter)
+	})
+}
+
+// loadBalancerValidate validates the load balancer request, including the config keys specific to bridge networks.
+func (n *bridge) loadBalancerValidate(listenAddress net.IP, loadBalancer api.NetworkLoadBalancerPut) ([]*loadBalancerPortMap, error) {
+	rules := map[string]func(value string) error{
+		// lxdmeta:generate(entities=network-load-balancer; group=load-balancer-bridge-conf; key=selection)
+		// Possible values are `round-robin` (new connections are sent to each backend in turn) and `hash`
+		// (new connections from the same client address are always sent to the same backend).
+		// ---
+		//  type: string
+		//  defaultdesc: `round-robin`
+		//  shortdesc: How a backend is selected for new connections
+		"selection": validate.Optional(validate.IsOneOf("round-robin", "hash")),
+		// lxdmeta:generate(entities=network-load-balancer; group=load-balancer-bridge-conf; key=healthcheck)
+		// Backends are checked by opening a TCP connection to them. Backends that fail their health
+		// check don't receive new connections until they pass it again.
+		// ---
+		//  type: bool
+		//  defaultdesc: `false`
+		//  shortdesc: Whether to check the health of the backends
+		"healthcheck": validate.Optional(validate.IsBool),
+		// lxdmeta:generate(entities=network-load-balancer; group=load-balancer-bridge-conf; key=healthcheck.interval)
+		//
+		// ---
+		//  type: integer
+		//  defaultdesc: `10`
+		//  shortdesc: Number of seconds between health checks
+		"healthcheck.interval": validate.Optional(validate.IsInRange(1, 3600)),
+		// lxdmeta:generate(entities=network-load-balancer; group=load-balancer-bridge-conf; key=healthcheck.timeout)
+		//
+		// ---
+		//  type: integer
+		//  defaultdesc: `5`
+		//  shortdesc: Number of seconds after which a health check fails
+		"healthcheck.timeout": validate.Optional(validate.IsInRange(1, 3600)),
+		// lxdmeta:generate(entities=network-load-balancer; group=load-balancer-bridge-conf; key=healthcheck.failure_count)
+		//
+		// ---
+		//  type: integer
+		//  defaultdesc: `3`
+		//  shortdesc: Number of failed checks after which a backend is unhealthy
+		"healthcheck.failure_count": validate.Optional(validate.IsInRange(1, 100)),
+		// lxdmeta:generate(entities=network-load-balancer; group=load-balancer-bridge-conf; key=healthcheck.success_count)
+		//
+		// ---
+		//  type: integer
+		//  defaultdesc: `2`
+		//  shortdesc: Number of successful checks after which a backend is healthy again
+		"healthcheck.success_count": validate.Optional(validate.IsInRange(1, 100)),
+	}
+
+	// Validate the bridge specific keys and leave the rest to the common validation.
+	commonConfig := make(map[string]string, len(loadBalancer.Config))
+	for k, v := range loadBalancer.Config {
+		validator, found := rules[k]
+		if !found {
+			commonConfig[k] = v
+			continue
+		}
+
+		err := validator(v)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid value for load balancer option %q: %w", k, err)
+		}
+	}
+
+	loadBalancer.Config = commonConfig
+
+	return n.common.loadBalancerValidate(listenAddress, loadBalancer)
+}
+
+// LoadBalancerCreate creates a network load balancer.
+func (n *bridge) LoadBalancerCreate(loadBalancer api.NetworkLoadBalancersPost, clientType request.ClientType) (net.IP, error) {
+	memberSpecific := true // bridge supports per-member load balancers.
+
+	// Convert listen address to subnet so we can check its valid and can be used.
+	listenAddressNet, err := ParseIPToNet(loadBalancer.ListenAddress)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing load balancer listen address %q: %w", loadBalancer.ListenAddress, err)
+	}
+
+	if listenAddressNet.IP.IsUnspecified() {
+		return nil, api.StatusErrorf(http.StatusNotImplemented, "Automatic listen address allocation not supported for drivers of type %q", n.netType)
+	}
+
+	err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		// Check if there is an existing load balancer using the same listen address.
+		_, _, err := tx.GetNetworkLoadBalancer(ctx, n.ID(), memberSpecific, loadBalancer.ListenAddress)
+
+		return err
+	})
+	if err == nil {
+		return nil, api.StatusErrorf(http.StatusConflict, "A load balancer for that listen address already exists")
+	}
+
+	_, err = n.loadBalancerValidate(listenAddressNet.IP, loadBalancer.NetworkLoadBalancerPut)
+	if err != nil {
+		return nil, err
+	}
+
+	externalSubnetsInUse, err := n.getExternalSubnetInUse()
+	if err != nil {
+		return nil, err
+	}
+
+	checkAddressNotInUse := func(netip *net.IPNet) (bool, error) {
+		// Check the listen address subnet doesn't fall within any existing network external subnets.
+		for _, externalSubnetUser := range externalSubnetsInUse {
+			// Check if usage is from our own network.
+			if externalSubnetUser.networkProject == n.project && externalSubnetUser.networkName == n.name {
+				// Skip checking conflict with our own network's subnet or SNAT address.
+				// But do not allow other conflict with other usage types within our own network.
+				if externalSubnetUser.usageType == subnetUsageNetwork || externalSubnetUser.usageType == subnetUsageNetworkSNAT {
+					continue
+				}
+			}
+
+			if SubnetContains(&externalSubnetUser.subnet, netip) || SubnetContains(netip, &externalSubnetUser.subnet) {
+				return false, nil
+			}
+		}
+
+		return true, nil
+	}
+
+	isValid, err := checkAddressNotInUse(listenAddressNet)
+	if err != nil {
+		return nil, err
+	} else if !isValid {
+		// This error is purposefully vague so that it doesn't reveal any names of
+		// resources potentially outside of the network.
+		return nil, fmt.Errorf("Load balancer listen address %q overlaps with another network or NIC", listenAddressNet.String())
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	var loadBalancerID int64
+
+	err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		// Create load balancer DB record.
+		loadBalancerID, err = tx.CreateNetworkLoadBalancer(ctx, n.ID(), memberSpecific, &loadBalancer)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	revert.Add(func() {
+		_ = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.DeleteNetworkLoadBalancer(ctx, n.ID(), loadBalancerID)
+		})
+		_ = n.loadBalancerSetupFirewall()
+		_ = n.loadBalancerBGPSetupPrefixes()
+	})
+
+	err = n.loadBalancerSetupFirewall()
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if hairpin mode needs to be enabled on active NIC bridge ports.
+	err = n.hairpinModeSetup()
+	if err != nil {
+		return nil, err
+	}
+
+	// Refresh exported BGP prefixes on local member.
+	err = n.loadBalancerBGPSetupPrefixes()
+	if err != nil {
+		return nil, fmt.Errorf("Failed applying BGP prefixes for load balancers: %w", err)
+	}
+
+	revert.Success()
+	return listenAddressNet.IP, nil
+}
+
+// LoadBalancerUpdate updates a network load balancer.
+func (n *bridge) LoadBalancerUpdate(listenAddress string, req api.NetworkLoadBalancerPut, clientType request.ClientType) error {
+	memberSpecific := true // bridge supports per-member load balancers.
+
+	var curLoadBalancerID int64
+	var curLoadBalancer *api.NetworkLoadBalancer
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		curLoadBalancerID, curLoadBalancer, err = tx.GetNetworkLoadBalancer(ctx, n.ID(), memberSpecific, listenAddress)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = n.loadBalancerValidate(net.ParseIP(curLoadBalancer.ListenAddress), req)
+	if err != nil {
+		return err
+	}
+
+	curLoadBalancerEtagHash, err := util.EtagHash(curLoadBalancer.Etag())
+	if err != nil {
+		return err
+	}
+
+	newLoadBalancer := api.NetworkLoadBalancer{
+		ListenAddress: curLoadBalancer.ListenAddress,
+	}
+
+	newLoadBalancer.SetWritable(req)
+
+	newLoadBalancerEtagHash, err := util.EtagHash(newLoadBalancer.Etag())
+	if err != nil {
+		return err
+	}
+
+	if curLoadBalancerEtagHash == newLoadBalancerEtagHash {
+		return nil // Nothing has changed.
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.UpdateNetworkLoadBalancer(ctx, n.ID(), curLoadBalancerID, newLoadBalancer.Writable())
+	})
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() {
+		_ = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.UpdateNetworkLoadBalancer(ctx, n.ID(), curLoadBalancerID, curLoadBalancer.Writable())
+		})
+		_ = n.loadBalancerSetupFirewall()
+		_ = n.loadBalancerBGPSetupPrefixes()
+	})
+
+	err = n.loadBalancerSetupFirewall()
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+	return nil
+}
+
+// LoadBalancerDelete deletes a network load balancer.
+func (n *bridge) LoadBalancerDelete(listenAddress string, clientType request.ClientType) error {
+	memberSpecific := true // bridge supports per-member load balancers.
+	var loadBalancerID int64
+	var loadBalancer *api.NetworkLoadBalancer
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		loadBalancerID, loadBalancer, err = tx.GetNetworkLoadBalancer(ctx, n.ID(), memberSpecific, listenAddress)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.DeleteNetworkLoadBalancer(ctx, n.ID(), loadBalancerID)
+	})
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() {
+		newLoadBalancer := api.NetworkLoadBalancersPost{
+			NetworkLoadBalancerPut: loadBalancer.Writable(),
+			ListenAddress:          loadBalancer.ListenAddress,
+		}
+
+		_ = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			_, _ = tx.CreateNetworkLoadBalancer(ctx, n.ID(), memberSpecific, &newLoadBalancer)
+
+			return nil
+		})
+
+		_ = n.loadBalancerSetupFirewall()
+		_ = n.loadBalancerBGPSetupPrefixes()
+	})
+
+	err = n.loadBalancerSetupFirewall()
+	if err != nil {
+		return err
+	}
+
+	// Refresh exported BGP prefixes on local member.
+	err = n.loadBalancerBGPSetupPrefixes()
+	if err != nil {
+		return fmt.Errorf("Failed applying BGP prefixes for load balancers: %w", err)
+	}
+
+	revert.Success()
+	return nil
+}
+
+// loadBalancerConvertToFirewallLoadBalancers converts the load balancer port maps to firewall load balancers,
+// leaving out the backends that failed their health check. If all the backends of a port specification are
+// unhealthy then they are all kept, as there is nowhere better to send the traffic.
+func (n *bridge) loadBalancerConvertToFirewallLoadBalancers(listenAddress net.IP, loadBalancer *api.NetworkLoadBalancer, portMaps []*loadBalancerPortMap, unhealthyBackends map[string]bool) []firewallDrivers.LoadBalancer {
+	var fwLoadBalancers []firewallDrivers.LoadBalancer
+
+	for portMapIndex, portMap := range portMaps {
+		var backends, healthyBackends []firewallDrivers.LoadBalancerBackend
+
+		// The port map targets are in the same order as the backend names of the port specification.
+		for targetIndex, target := range portMap.targets {
+			backend := firewallDrivers.LoadBalancerBackend{
+				TargetAddress: target.address,
+				TargetPorts:   target.ports,
+			}
+
+			backends = append(backends, backend)
+
+			if !unhealthyBackends[loadBalancer.Ports[portMapIndex].TargetBackend[targetIndex]] {
+				healthyBackends = append(healthyBackends, backend)
+			}
+		}
+
+		if len(healthyBackends) > 0 {
+			backends = healthyBackends
+		}
+
+		if len(backends) == 0 {
+			continue
+		}
+
+		fwLoadBalancers = append(fwLoadBalancers, firewallDrivers.LoadBalancer{
+			ListenAddress: listenAddress,
+			Protocol:      portMap.protocol,
+			ListenPorts:   portMap.listenPorts,
+			Backends:      backends,
+			Selection:     loadBalancer.Config["selection"],
+		})
+	}
+
+	return fwLoadBalancers
+}
+
+// loadBalancerSetupFirewall applies all network load balancers defined for this network and this member, and
+// starts or stops the health checks of their backends accordingly.
+func (n *bridge) loadBalancerSetupFirewall() error {
+	// Lock the network's load balancers so that the rules applied after a health check change don't race
+	// with the ones applied after an API change and end up applying stale backends.
+	unlock, err := locking.Lock(context.TODO(), n.loadBalancerOperationLockName())
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	memberSpecific := true // Get all load balancers for this cluster member.
+
+	var loadBalancers map[int64]*api.NetworkLoadBalancer
+
+	err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		loadBalancers, err = tx.GetNetworkLoadBalancers(ctx, n.ID(), memberSpecific)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading network load balancers: %w", err)
+	}
+
+	var fwLoadBalancers []firewallDrivers.LoadBalancer
+	healthChecks := make(map[string]loadBalancerHealthCheck)
+
+	for _, loadBalancer := range loadBalancers {
+		// Convert listen address to subnet so we can check its valid and can be used.
+		listenAddressNet, err := ParseIPToNet(loadBalancer.ListenAddress)
+		if err != nil {
+			return fmt.Errorf("Failed parsing load balancer listen address %q: %w", loadBalancer.ListenAddress, err)
+		}
+
+		portMaps, err := n.loadBalancerValidate(listenAddressNet.IP, loadBalancer.Writable())
+		if err != nil {
+			return fmt.Errorf("Failed validating firewall load balancer for listen address %q: %w", loadBalancer.ListenAddress, err)
+		}
+
+		healthCheck := loadBalancerHealthCheckFromConfig(loadBalancer)
+		if healthCheck != nil && len(healthCheck.targets) > 0 {
+			healthChecks[loadBalancer.ListenAddress] = *healthCheck
+		}
+
+		unhealthyBackends := loadBalancerUnhealthyBackends(n.id, loadBalancer.ListenAddress)
+		fwLoadBalancers = append(fwLoadBalancers, n.loadBalancerConvertToFirewallLoadBalancers(listenAddressNet.IP, loadBalancer, portMaps, unhealthyBackends)...)
+	}
+
+	err = n.state.Firewall.NetworkApplyLoadBalancers(n.name, fwLoadBalancers)
+	if err != nil {
+		return fmt.Errorf("Failed applying firewall load balancers: %w", err)
+	}
+
+	// Apply the rules again whenever a backend becomes healthy or unhealthy.
+	loadBalancerMonitorsSync(n.id, healthChecks, func() {
+		err := n.loadBalancerSetupFirewall()
+		if err != nil {
+			n.logger.Warn("Failed applying firewall load balancers after health check change", logger.Ctx{"err": err})
+		}
+	})
+
+	return nil
+}
+
+// loadBalancerOperationLockName returns the name of the lock held while applying the network's load balancers.
+func (n *bridge) loadBalancerOperationLockName() string {
+	return fmt.Sprintf("network.bridge.%d.load-balancers", n.id)
+}
+
+// peerSubnets returns the subnets routed to the network, which are reachable from its peer networks.
+func (n *bridge) peerSubnets() []*net.IPNet {
+	var subnets []*net.IPNet
+
+	for _, ipVersion := range []uint{4, 6} {
+		_, subnet, err := net.ParseCIDR(n.config[fmt.Sprintf("ipv%d.address", ipVersion)])
+		if err == nil {
+			subnets = append(subnets, subnet)
+		}
+
+		for _, route := range shared.SplitNTrimSpace(n.config[fmt.Sprintf("ipv%d.routes", ipVersion)], ",", -1, true) {
+			_, subnet, err := net.ParseCIDR(route)
+			if err == nil {
+				subnets = append(subnets, subnet)
+			}
+		}
+	}
+
+	return subnets
+}
+
+// PeerCreate creates a network peering.
+func (n *bridge) PeerCreate(peer api.NetworkPeersPost, clientType request.ClientType) error {
+	revert := revert.New()
+	defer revert.Fail()
+
+	if clientType == request.ClientTypeNormal {
+		// Perform create-time validation.
+
+		// Default to network's project if target project not specified.
+		if peer.TargetProject == "" {
+			peer.TargetProject = n.Project()
+		}
+
+		// Target network name is required.
+		if peer.TargetNetwork == "" {
+			return api.StatusErrorf(http.StatusBadRequest, "Target network is required")
+		}
+
+		if peer.TargetProject == n.Project() && peer.TargetNetwork == n.Name() {
+			return api.StatusErrorf(http.StatusBadRequest, "Target network cannot be the network itself")
+		}
+
+		var peers map[int64]*api.NetworkPeer
+
+		err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			var err error
+
+			// Check if there is an existing peer using the same name, or whether there is already a peering
+			// (in any state) to the target network.
+			peers, err = tx.GetNetworkPeers(ctx, n.ID())
+
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, existingPeer := range peers {
+			if peer.Name == existingPeer.Name {
+				return api.StatusErrorf(http.StatusConflict, "A peer for that name already exists")
+			}
+
+			if peer.TargetProject == existingPeer.TargetProject && peer.TargetNetwork == existingPeer.TargetNetwork {
+				return api.StatusErrorf(http.StatusConflict, "A peer for that target network already exists")
+			}
+		}
+
+		// Bridge networks can only be peered with other bridge networks. The target network may not exist
+		// yet, in which case the peering stays pending until it is created and peered back.
+		targetNet, err := LoadByName(n.state, peer.TargetProject, peer.TargetNetwork)
+		if err == nil && targetNet.Type() != n.Type() {
+			return api.StatusErrorf(http.StatusBadRequest, "Target network must be of type %q", n.Type())
+		}
+
+		// Perform general (create and update) validation.
+		err = n.peerValidate(peer.Name, &peer.NetworkPeerPut)
+		if err != nil {
+			return err
+		}
+
+		var peerID int64
+		var mutualExists bool
+
+		err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			// Create peer DB record.
+			peerID, mutualExists, err = tx.CreateNetworkPeer(ctx, n.ID(), &peer)
+
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		revert.Add(func() {
+			_ = n.state.DB.Cluster.DeleteNetworkPeer(n.ID(), peerID)
+		})
+
+		if !mutualExists {
+			revert.Success()
+			return nil // Nothing to apply until the target network peers back.
+		}
+	}
+
+	var peerInfo *api.NetworkPeer
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		// Load peering to get mutual peering info.
+		_, peerInfo, err = tx.GetNetworkPeer(ctx, n.ID(), peer.Name)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if peerInfo.Status != api.NetworkStatusCreated {
+		return fmt.Errorf("Only peerings in %q state can be setup", api.NetworkStatusCreated)
+	}
+
+	targetBridgeNet, err := n.peerTargetNetwork(peerInfo)
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() {
+		_ = n.peerSetupFirewall(targetBridgeNet.ID())
+		_ = targetBridgeNet.peerSetupFirewall(n.ID())
+	})
+
+	// Exempt the traffic between the two networks from outbound NAT on both sides.
+	err = n.peerSetupFirewall()
+	if err != nil {
+		return err
+	}
+
+	err = targetBridgeNet.peerSetupFirewall()
+	if err != nil {
+		return err
+	}
+
+	if clientType == request.ClientTypeNormal {
+		// Notify all other members to apply the peering to their firewall.
+		notifier, err := cluster.NewNotifier(n.state, n.state.Endpoints.NetworkCert(), n.state.ServerCert(), cluster.NotifyAll)
+		if err != nil {
+			return err
+		}
+
+		err = notifier(func(client lxd.InstanceServer) error {
+			return client.UseProject(n.project).CreateNetworkPeer(n.name, peer)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	revert.Success()
+	return nil
+}
+
+// PeerUpdate updates a network peering.
+func (n *bridge) PeerUpdate(peerName string, req api.NetworkPeerPut) error {
+	var curPeerID int64
+	var curPeer *api.NetworkPeer
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		curPeerID, curPeer, err = tx.GetNetworkPeer(ctx, n.ID(), peerName)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	err = n.peerValidate(peerName, &req)
+	if err != nil {
+		return err
+	}
+
+	curPeerEtagHash, err := util.EtagHash(curPeer.Etag())
+	if err != nil {
+		return err
+	}
+
+	newPeer := api.NetworkPeer{
+		Name: curPeer.Name,
+	}
+
+	newPeer.SetWritable(req)
+
+	newPeerEtagHash, err := util.EtagHash(newPeer.Etag())
+	if err != nil {
+		return err
+	}
+
+	if curPeerEtagHash == newPeerEtagHash {
+		return nil // Nothing has changed.
+	}
+
+	// Only the description and config of a peering can be changed, neither of which affects the firewall.
+	return n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.UpdateNetworkPeer(ctx, n.ID(), curPeerID, newPeer.Writable())
+	})
+}
+
+// PeerDelete deletes a network peering.
+func (n *bridge) PeerDelete(peerName string, clientType request.ClientType) error {
+	var peerID int64
+	var peer *api.NetworkPeer
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		peerID, peer, err = tx.GetNetworkPeer(ctx, n.ID(), peerName)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if clientType == request.ClientTypeNormal {
+		isUsed, err := n.peerIsUsed(peer.Name)
+		if err != nil {
+			return err
+		}
+
+		if isUsed {
+			return fmt.Errorf("Cannot delete a Peer that is in use")
+		}
+	}
+
+	if peer.Status == api.NetworkStatusCreated {
+		targetBridgeNet, err := n.peerTargetNetwork(peer)
+		if err != nil {
+			return err
+		}
+
+		// The peering is still in the database, so leave it out explicitly on both sides.
+		err = n.peerSetupFirewall(targetBridgeNet.ID())
+		if err != nil {
+			return err
+		}
+
+		err = targetBridgeNet.peerSetupFirewall(n.ID())
+		if err != nil {
+			return err
+		}
+	}
+
+	if clientType == request.ClientTypeNormal {
+		// Notify all other members to remove the peering from their firewall before deleting it.
+		notifier, err := cluster.NewNotifier(n.state, n.state.Endpoints.NetworkCert(), n.state.ServerCert(), cluster.NotifyAll)
+		if err != nil {
+			return err
+		}
+
+		err = notifier(func(client lxd.InstanceServer) error {
+			return client.UseProject(n.project).DeleteNetworkPeer(n.name, peerName)
+		})
+		if err != nil {
+			return err
+		}
+
+		err = n.state.DB.Cluster.DeleteNetworkPeer(n.ID(), peerID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// peerTargetNetwork loads the target network of a peering.
+func (n *bridge) peerTargetNetwork(peer *api.NetworkPeer) (*bridge, error) {
+	targetNet, err := LoadByName(n.state, peer.TargetProject, peer.TargetNetwork)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading target network: %w", err)
+	}
+
+	targetBridgeNet, ok := targetNet.(*bridge)
+	if !ok {
+		return nil, fmt.Errorf("Target network is not bridge interface type")
+	}
+
+	return targetBridgeNet, nil
+}
+
+// forPeers runs f for each target peer network that this network is connected to.
+func (n *bridge) forPeers(f func(targetBridgeNet *bridge) error) error {
+	var peers map[int64]*api.NetworkPeer
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		peers, err = tx.GetNetworkPeers(ctx, n.ID())
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, peer := range peers {
+		if peer.Status != api.NetworkStatusCreated {
+			continue
+		}
+
+		targetBridgeNet, err := n.peerTargetNetwork(peer)
+		if err != nil {
+			return err
+		}
+
+		err = f(targetBridgeNet)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// peerSetupFirewall applies the firewall rules of all the network peerings of this network, other than those
+// with the networks in excludeNetworkIDs. Traffic between the network and its peers is routed without NAT.
+func (n *bridge) peerSetupFirewall(excludeNetworkIDs ...int64) error {
+	if !n.isRunning() {
+		return nil
+	}
+
+	var fwPeers []firewallDrivers.NetworkPeer
+	localSubnets := n.peerSubnets()
+
+	err := n.forPeers(func(targetBridgeNet *bridge) error {
+		if shared.ValueInSlice(targetBridgeNet.ID(), excludeNetworkIDs) {
+			return nil
+		}
+
+		fwPeers = append(fwPeers, firewallDrivers.NetworkPeer{
+			Interface:     targetBridgeNet.Name(),
+			LocalSubnets:  localSubnets,
+			TargetSubnets: targetBridgeNet.peerSubnets(),
+		})
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = n.state.Firewall.NetworkApplyPeers(n.name, fwPeers)
+	if err != nil {
+		return fmt.Errorf("Failed applying firewall network peers: %w", err)
+	}
+
+	return nil
+}
+
+// peerSetupTargetsFirewall applies the firewall rules of the networks this network is peered with, so that they
+// pick up changes to this network's subnets. Peerings with the networks in excludeNetworkIDs are left out.
+func (n *bridge) peerSetupTargetsFirewall(excludeNetworkIDs ...int64) error {
+	return n.forPeers(func(targetBridgeNet *bridge) error {
+		return targetBridgeNet.peerSetupFirewall(excludeNetworkIDs...)
+	})
+}
+
+// Leases returns a list of leases for the bridged network. It will reach out to other cluster members as needed.
+// The projectName passed here refers to the initial project from the API request which may differ from the network's project.
+func (n *bridge) Leases(projectName string, clientType request.ClientType) ([]api.NetworkLease, error) {
+	var err error
+	var projectMacs []string
+	leases := []api.NetworkLease{}
+
+	// Get all static leases.
+	if clientType == request.ClientTypeNormal {
+		// If requested project matches network's project then include gateway and downstream uplink IPs.
+		if projectName == n.project {
+			// Add our own gateway IPs.
+			for _, addr := range []string{n.ipv4AddressConfig(), n.config["ipv6.address"]} {
+				ip, _, _ := net.ParseCIDR(addr)
+				if ip != nil {
+					leases = append(leases, api.NetworkLease{
+						Hostname: fmt.Sprintf("%s.gw", n.Name()),
+						Address:  ip.String(),
+						Type:     "gateway",
+					})
+				}
+			}
+
+			// Include downstream OVN routers using the network as an uplink.
+			var projectNetworks map[string]map[int64]api.Network
+			err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+				projectNetworks, err = tx.GetCreatedNetworks(ctx)
+				return err
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			// Look for networks using the current network as an uplink.
+			for projectName, networks := range projectNetworks {
+				for _, network := range networks {
+					if network.Config["network"] != n.name {
+						continue
+					}
+
+					// Found a network, add leases.
+					for _, k := range []string{"volatile.network.ipv4.address", "volatile.network.ipv6.address"} {
+						v := network.Config[k]
+						if v != "" {
+							leases = append(leases, api.NetworkLease{
+								Hostname: fmt.Sprintf("%s-%s.uplink", projectName, network.Name),
+								Address:  v,
+								Type:     "uplink",
+							})
+						}
+					}
+				}
+			}
+		}
+
+		// Get all the instances in the requested project that are connected to this network.
+		filter := dbCluster.InstanceFilter{Project: &projectName}
+		err = UsedByInstanceDevices(n.state, n.Project(), n.Name(), n.Type(), func(inst db.InstanceArgs, nicName string, nicConfig map[string]string) error {
+			// Fill in the hwaddr from volatile.
+			if nicConfig["hwaddr"] == "" {
+				nicConfig["hwaddr"] = inst.Config[fmt.Sprintf("volatile.%s.hwaddr", nicName)]
+			}
+
+			// Record the MAC.
+			hwAddr, _ := net.ParseMAC(nicConfig["hwaddr"])
+			if hwAddr != nil {
+				projectMacs = append(projectMacs, hwAddr.String())
+			}
+
+			// Add the lease.
+			nicIP4 := net.ParseIP(nicConfig["ipv4.address"])
+			if nicIP4 != nil {
+				leases = append(leases, api.NetworkLease{
+					Hostname: inst.Name,
+					Address:  nicIP4.String(),
 					Hwaddr:   hwAddr.String(),
 					Type:     "static",
 					Location: inst.Node,
@@ -3552,6 +4614,15 @@ func (n *bridge) Leases(projectName string, clientType request.ClientType) ([]ap
 		}
 	}
 
+	// Get the dynamic leases of the native DHCP server from the database (which has those of all members).
+	if n.UsesNativeDHCP() {
+		if clientType != request.ClientTypeNormal {
+			return leases, nil
+		}
+
+		return n.nativeDHCPLeases(leases, projectMacs)
+	}
+
 	// Get dynamic leases.
 	leaseFile := shared.VarPath("networks", n.name, "dnsmasq.leases")
 	if !shared.PathExists(leaseFile) {
@@ -3643,5 +4714,9 @@ func (n *bridge) Leases(projectName string, clientType request.ClientType) ([]ap
 
 // UsesDNSMasq indicates if network's config indicates if it needs to use dnsmasq.
 func (n *bridge) UsesDNSMasq() bool {
-	return n.config["bridge.mode"] == "fan" || !shared.ValueInSlice(n.config["ipv4.address"], []string{"", "none"}) || !shared.ValueInSlice(n.config["ipv6.address"], []string{"", "none"})
+	if n.UsesNativeDHCP() {
+		return false
+	}
+
+	return n.config["bridge.mode"] == "fan" || !shared.ValueInSlice(n.ipv4AddressConfig(), []string{"", "none"}) || !shared.ValueInSlice(n.config["ipv6.address"], []string{"", "none"})
 }
@@ -2,14 +2,18 @@ package network
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"os"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"unicode"
 
 	"github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/lxd/bgp"
 	"github.com/canonical/lxd/lxd/cluster"
 	"github.com/canonical/lxd/lxd/cluster/request"
 	"github.com/canonical/lxd/lxd/db"
@@ -32,6 +36,7 @@ type Info struct {
 	AddressForwards    bool // Indicates if driver supports address forwards.
 	LoadBalancers      bool // Indicates if driver supports load balancers.
 	Peering            bool // Indicates if the driver supports network peering.
+	IPAM               bool // Indicates if the driver supports IP address management.
 }
 
 // forwardTarget represents a single port forward target.
@@ -304,6 +309,12 @@ func (n *common) IsUsed() (bool, error) {
 	return len(usedBy) > 0, nil
 }
 
+// AddressCIDR returns the address of the network in CIDR notation for the given IP version, as configured in its
+// ipv{n}.address setting.
+func (n *common) AddressCIDR(ipVersion uint) string {
+	return n.config[fmt.Sprintf("ipv%d.address", ipVersion)]
+}
+
 // DHCPv4Subnet returns nil always.
 func (n *common) DHCPv4Subnet() *net.IPNet {
 	return nil
@@ -595,7 +606,7 @@ func (n *common) bgpValidationRules(config map[string]string) (map[string]func(v
 		}
 
 		// Validate remote name in key.
-		fields := strings.Split(k, ".")
+		fields := strings.SplitN(k, ".", 4)
 		if len(fields) != 4 {
 			return nil, fmt.Errorf("Invalid network configuration key: %q", k)
 		}
@@ -612,12 +623,40 @@ func (n *common) bgpValidationRules(config map[string]string) (map[string]func(v
 			rules[k] = validate.Optional(validate.IsAny)
 		case "holdtime":
 			rules[k] = validate.Optional(validate.IsInRange(9, 65535))
+		case "import.prefixes", "export.prefixes":
+			rules[k] = validate.Optional(validate.IsListOf(func(value string) error {
+				_, _, _, err := bgp.ParsePrefix(value)
+				return err
+			}))
+		case "import.communities", "export.communities":
+			rules[k] = validate.Optional(validate.IsListOf(bgpValidateCommunity))
+		case "import.local_pref", "export.med":
+			rules[k] = validate.Optional(validate.IsUint32)
+		case "install_routes", "graceful_restart":
+			rules[k] = validate.Optional(validate.IsBool)
 		}
 	}
 
 	return rules, nil
 }
 
+// bgpValidateCommunity validates a BGP community in the ASN:VALUE format.
+func bgpValidateCommunity(value string) error {
+	asn, val, found := strings.Cut(value, ":")
+	if !found {
+		return fmt.Errorf("Invalid BGP community %q (must be ASN:VALUE)", value)
+	}
+
+	for _, part := range []string{asn, val} {
+		_, err := strconv.ParseUint(part, 10, 16)
+		if err != nil {
+			return fmt.Errorf("Invalid BGP community %q (must be ASN:VALUE with both between 0 and 65535)", value)
+		}
+	}
+
+	return nil
+}
+
 // bgpSetup initializes BGP peers and prefixes.
 func (n *common) bgpSetup(oldConfig map[string]string) error {
 	err := n.bgpSetupPeers(oldConfig)
@@ -664,11 +703,14 @@ func (n *common) bgpClear(config map[string]string) error {
 
 // bgpClearPeers removes all BGP peers on the network.
 func (n *common) bgpClearPeers(config map[string]string) error {
-	peers := n.bgpGetPeers(config)
+	peers, err := n.bgpGetPeers(config)
+	if err != nil {
+		return err
+	}
+
 	for _, peer := range peers {
 		// Remove the peer.
-		fields := strings.Split(peer, ",")
-		err := n.state.BGP.RemovePeer(net.ParseIP(fields[0]))
+		err := n.state.BGP.RemovePeer(peer.address)
 		if err != nil {
 			return err
 		}
@@ -680,45 +722,37 @@ func (n *common) bgpClearPeers(config map[string]string) error {
 // bgpSetupPeers updates the list of BGP peers.
 func (n *common) bgpSetupPeers(oldConfig map[string]string) error {
 	// Setup BGP (and handled config changes).
-	newPeers := n.bgpGetPeers(n.config)
-	oldPeers := n.bgpGetPeers(oldConfig)
+	newPeers, err := n.bgpGetPeers(n.config)
+	if err != nil {
+		return err
+	}
+
+	oldPeers, err := n.bgpGetPeers(oldConfig)
+	if err != nil {
+		return err
+	}
 
 	// Remove old peers.
-	for _, peer := range oldPeers {
-		if shared.ValueInSlice(peer, newPeers) {
+	for name, peer := range oldPeers {
+		if reflect.DeepEqual(peer, newPeers[name]) {
 			continue
 		}
 
 		// Remove old peer.
-		fields := strings.Split(peer, ",")
-		err := n.state.BGP.RemovePeer(net.ParseIP(fields[0]))
+		err := n.state.BGP.RemovePeer(peer.address)
 		if err != nil {
 			return err
 		}
 	}
 
 	// Add new peers.
-	for _, peer := range newPeers {
-		if shared.ValueInSlice(peer, oldPeers) {
+	for name, peer := range newPeers {
+		if reflect.DeepEqual(peer, oldPeers[name]) {
 			continue
 		}
 
 		// Add new peer.
-		fields := strings.Split(peer, ",")
-		asn, err := strconv.ParseUint(fields[1], 10, 32)
-		if err != nil {
-			return err
-		}
-
-		var holdTime uint64
-		if fields[3] != "" {
-			holdTime, err = strconv.ParseUint(fields[3], 10, 32)
-			if err != nil {
-				return err
-			}
-		}
-
-		err = n.state.BGP.AddPeer(net.ParseIP(fields[0]), uint32(asn), fields[2], holdTime)
+		err := n.state.BGP.AddPeer(peer.address, peer.asn, peer.password, peer.holdTime, peer.policy)
 		if err != nil {
 			return err
 		}
@@ -797,8 +831,17 @@ func (n *common) bgpSetupPrefixes(oldConfig map[string]string) error {
 	return nil
 }
 
-// bgpGetPeers returns a list of strings representing the BGP peers.
-func (n *common) bgpGetPeers(config map[string]string) []string {
+// bgpPeer represents a BGP peer defined in the network config.
+type bgpPeer struct {
+	address  net.IP
+	asn      uint32
+	password string
+	holdTime uint64
+	policy   bgp.PeerPolicy
+}
+
+// bgpGetPeers returns the BGP peers defined in the config keyed by peer name.
+func (n *common) bgpGetPeers(config map[string]string) (map[string]bgpPeer, error) {
 	// Get a list of peer names.
 	peerNames := []string{}
 	for k := range config {
@@ -812,20 +855,117 @@ func (n *common) bgpGetPeers(config map[string]string) []string {
 		}
 	}
 
-	// Build up a list of peer strings.
-	peers := []string{}
+	// Build up the list of peers.
+	peers := map[string]bgpPeer{}
 	for _, peerName := range peerNames {
-		peerAddress := config[fmt.Sprintf("bgp.peers.%s.address", peerName)]
-		peerASN := config[fmt.Sprintf("bgp.peers.%s.asn", peerName)]
-		peerPassword := config[fmt.Sprintf("bgp.peers.%s.password", peerName)]
-		peerHoldTime := config[fmt.Sprintf("bgp.peers.%s.holdtime", peerName)]
+		peerKey := func(key string) string {
+			return config[fmt.Sprintf("bgp.peers.%s.%s", peerName, key)]
+		}
 
-		if peerAddress != "" && peerASN != "" {
-			peers = append(peers, fmt.Sprintf("%s,%s,%s,%s", peerAddress, peerASN, peerPassword, peerHoldTime))
+		if peerKey("address") == "" || peerKey("asn") == "" {
+			continue
+		}
+
+		asn, err := strconv.ParseUint(peerKey("asn"), 10, 32)
+		if err != nil {
+			return nil, err
 		}
+
+		peer := bgpPeer{
+			address:  net.ParseIP(peerKey("address")),
+			asn:      uint32(asn),
+			password: peerKey("password"),
+			policy: bgp.PeerPolicy{
+				ImportPrefixes:    shared.SplitNTrimSpace(peerKey("import.prefixes"), ",", -1, true),
+				ImportCommunities: shared.SplitNTrimSpace(peerKey("import.communities"), ",", -1, true),
+				ExportPrefixes:    shared.SplitNTrimSpace(peerKey("export.prefixes"), ",", -1, true),
+				ExportCommunities: shared.SplitNTrimSpace(peerKey("export.communities"), ",", -1, true),
+				InstallRoutes:     shared.IsTrue(peerKey("install_routes")),
+				GracefulRestart:   shared.IsTrueOrEmpty(peerKey("graceful_restart")),
+			},
+		}
+
+		if peerKey("holdtime") != "" {
+			peer.holdTime, err = strconv.ParseUint(peerKey("holdtime"), 10, 32)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if peerKey("import.local_pref") != "" {
+			localPref, err := strconv.ParseUint(peerKey("import.local_pref"), 10, 32)
+			if err != nil {
+				return nil, err
+			}
+
+			peer.policy.ImportLocalPref = uint32(localPref)
+		}
+
+		if peerKey("export.med") != "" {
+			med, err := strconv.ParseUint(peerKey("export.med"), 10, 32)
+			if err != nil {
+				return nil, err
+			}
+
+			peer.policy.ExportMED = uint32(med)
+		}
+
+		peers[peerName] = peer
+	}
+
+	return peers, nil
+}
+
+// BGPState returns the state of the BGP peers of the network on the local member.
+func (n *common) BGPState() (*api.NetworkBGPState, error) {
+	return n.bgpState(n.config)
+}
+
+// bgpState returns the state of the BGP peers defined in the config.
+func (n *common) bgpState(config map[string]string) (*api.NetworkBGPState, error) {
+	peers, err := n.bgpGetPeers(config)
+	if err != nil {
+		return nil, err
+	}
+
+	peerNames := make([]string, 0, len(peers))
+	for peerName := range peers {
+		peerNames = append(peerNames, peerName)
+	}
+
+	sort.Strings(peerNames)
+
+	state := &api.NetworkBGPState{Peers: []api.NetworkBGPPeerState{}}
+	for _, peerName := range peerNames {
+		peer := peers[peerName]
+
+		peerState, err := n.state.BGP.PeerState(peer.address)
+		if err != nil {
+			if !errors.Is(err, bgp.ErrPeerNotFound) {
+				return nil, fmt.Errorf("Failed getting state of BGP peer %q: %w", peerName, err)
+			}
+
+			// The peer isn't set up on this member (e.g. the network failed to start).
+			peerState = &bgp.PeerState{
+				State:              "down",
+				AdvertisedPrefixes: []string{},
+				ReceivedPrefixes:   []string{},
+				InstalledPrefixes:  []string{},
+			}
+		}
+
+		state.Peers = append(state.Peers, api.NetworkBGPPeerState{
+			Name:               peerName,
+			Address:            peer.address.String(),
+			ASN:                peer.asn,
+			State:              peerState.State,
+			AdvertisedPrefixes: peerState.AdvertisedPrefixes,
+			ReceivedPrefixes:   peerState.ReceivedPrefixes,
+			InstalledPrefixes:  peerState.InstalledPrefixes,
+		})
 	}
 
-	return peers
+	return state, nil
 }
 
 // forwardValidate validates the forward request.
@@ -1398,7 +1538,7 @@ func (n *common) Leases(projectName string, clientType request.ClientType) ([]ap
 }
 
 // PeerCreate returns ErrNotImplemented for drivers that do not support forwards.
-func (n *common) PeerCreate(forward api.NetworkPeersPost) error {
+func (n *common) PeerCreate(peer api.NetworkPeersPost, clientType request.ClientType) error {
 	return ErrNotImplemented
 }
 
@@ -1408,7 +1548,7 @@ func (n *common) PeerUpdate(peerName string, newPeer api.NetworkPeerPut) error {
 }
 
 // PeerDelete returns ErrNotImplemented for drivers that do not support forwards.
-func (n *common) PeerDelete(peerName string) error {
+func (n *common) PeerDelete(peerName string, clientType request.ClientType) error {
 	return ErrNotImplemented
 }
 
@@ -101,6 +101,7 @@ func (n *ovn) Info() Info {
 	info.AddressForwards = true
 	info.LoadBalancers = true
 	info.Peering = true
+	info.IPAM = true
 
 	return info
 }
@@ -3183,6 +3184,14 @@ func (n *ovn) Update(newNetwork api.NetworkPut, targetNode string, clientType re
 		return n.common.update(newNetwork, targetNode, clientType)
 	}
 
+	// Check the new subnets still contain the IP ranges of the network.
+	if shared.ValueInSlice("ipv4.address", changedKeys) || shared.ValueInSlice("ipv6.address", changedKeys) {
+		err = n.ipamCheckSubnetChange(newNetwork.Config)
+		if err != nil {
+			return err
+		}
+	}
+
 	revert := revert.New()
 	defer revert.Fail()
 
@@ -5420,6 +5429,17 @@ func (n *ovn) LoadBalancerDelete(listenAddress string, clientType request.Client
 	return nil
 }
 
+// BGPState returns the state of the BGP peers of the uplink network, which are used to advertise the OVN network.
+func (n *ovn) BGPState() (*api.NetworkBGPState, error) {
+	// Uplink network must be in default project.
+	uplinkNet, err := LoadByName(n.state, api.ProjectDefaultName, n.config["network"])
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading uplink network %q: %w", n.config["network"], err)
+	}
+
+	return n.bgpState(uplinkNet.Config())
+}
+
 // Leases returns a list of leases for the OVN network. Those are directly extracted from the OVN database.
 func (n *ovn) Leases(projectName string, clientType request.ClientType) ([]api.NetworkLease, error) {
 	var err error
@@ -5488,7 +5508,7 @@ func (n *ovn) Leases(projectName string, clientType request.ClientType) ([]api.N
 }
 
 // PeerCreate creates a network peering.
-func (n *ovn) PeerCreate(peer api.NetworkPeersPost) error {
+func (n *ovn) PeerCreate(peer api.NetworkPeersPost, clientType request.ClientType) error {
 	revert := revert.New()
 	defer revert.Fail()
 
@@ -5822,7 +5842,7 @@ func (n *ovn) PeerUpdate(peerName string, req api.NetworkPeerPut) error {
 }
 
 // PeerDelete deletes a network peering.
-func (n *ovn) PeerDelete(peerName string) error {
+func (n *ovn) PeerDelete(peerName string, clientType request.ClientType) error {
 	var peerID int64
 	var peer *api.NetworkPeer
 
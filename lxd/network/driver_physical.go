@@ -182,6 +182,78 @@ func (n *physical) Validate(config map[string]string) error {
 	//  defaultdesc: `180`
 	//  required: no
 	//  shortdesc: Peer session hold time
+
+	// lxdmeta:generate(entities=network-physical; group=network-conf; key=bgp.peers.NAME.import.prefixes)
+	// Comma-separated list of prefixes. Only the routes for these prefixes are accepted from the peer. Add a maximum prefix length to also match more specific prefixes (for example, `10.0.0.0/8..24`).
+	// ---
+	//  type: string
+	//  condition: BGP server
+	//  defaultdesc: (all prefixes)
+	//  required: no
+	//  shortdesc: Prefixes accepted from the peer
+
+	// lxdmeta:generate(entities=network-physical; group=network-conf; key=bgp.peers.NAME.import.communities)
+	// Comma-separated list of communities in the `ASN:VALUE` format. Only the routes tagged with one of these communities are accepted from the peer.
+	// ---
+	//  type: string
+	//  condition: BGP server
+	//  defaultdesc: (all routes)
+	//  required: no
+	//  shortdesc: Communities required on the routes accepted from the peer
+
+	// lxdmeta:generate(entities=network-physical; group=network-conf; key=bgp.peers.NAME.import.local_pref)
+	//
+	// ---
+	//  type: integer
+	//  condition: BGP server
+	//  defaultdesc: (unchanged)
+	//  required: no
+	//  shortdesc: Local preference set on the routes accepted from the peer
+
+	// lxdmeta:generate(entities=network-physical; group=network-conf; key=bgp.peers.NAME.export.prefixes)
+	// Comma-separated list of prefixes. Only these prefixes are advertised to the peer. Add a maximum prefix length to also match more specific prefixes (for example, `10.0.0.0/8..24`).
+	// ---
+	//  type: string
+	//  condition: BGP server
+	//  defaultdesc: (all prefixes)
+	//  required: no
+	//  shortdesc: Prefixes advertised to the peer
+
+	// lxdmeta:generate(entities=network-physical; group=network-conf; key=bgp.peers.NAME.export.communities)
+	// Comma-separated list of communities in the `ASN:VALUE` format.
+	// ---
+	//  type: string
+	//  condition: BGP server
+	//  defaultdesc: (no communities)
+	//  required: no
+	//  shortdesc: Communities added to the routes advertised to the peer
+
+	// lxdmeta:generate(entities=network-physical; group=network-conf; key=bgp.peers.NAME.export.med)
+	//
+	// ---
+	//  type: integer
+	//  condition: BGP server
+	//  defaultdesc: (unchanged)
+	//  required: no
+	//  shortdesc: MED set on the routes advertised to the peer
+
+	// lxdmeta:generate(entities=network-physical; group=network-conf; key=bgp.peers.NAME.install_routes)
+	// The best routes accepted from the peer are added to the host routing table, for example to fail over between uplinks.
+	// ---
+	//  type: bool
+	//  condition: BGP server
+	//  defaultdesc: `false`
+	//  required: no
+	//  shortdesc: Whether to install the routes received from the peer
+
+	// lxdmeta:generate(entities=network-physical; group=network-conf; key=bgp.peers.NAME.graceful_restart)
+	//
+	// ---
+	//  type: bool
+	//  condition: BGP server
+	//  defaultdesc: `true`
+	//  required: no
+	//  shortdesc: Whether to enable graceful restart for the session
 	bgpRules, err := n.bgpValidationRules(config)
 	if err != nil {
 		return err
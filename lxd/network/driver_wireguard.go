@@ -0,0 +1,639 @@
+package network
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/cluster"
+	"github.com/canonical/lxd/lxd/cluster/request"
+	"github.com/canonical/lxd/lxd/db"
+	firewallDrivers "github.com/canonical/lxd/lxd/firewall/drivers"
+	"github.com/canonical/lxd/lxd/ip"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/revert"
+	"github.com/canonical/lxd/shared/validate"
+)
+
+// wireguardVolatileSubnet is the member specific config key holding the subnet allocated to the cluster member.
+const wireguardVolatileSubnet = "volatile.wireguard.subnet"
+
+// wireguardVolatilePublicKey is the member specific config key holding the public key of the cluster member.
+const wireguardVolatilePublicKey = "volatile.wireguard.public_key"
+
+// Default UDP port used by the WireGuard tunnels.
+const wireguardPortDefault = 51820
+
+// Default prefix length of the subnets allocated to each cluster member.
+const wireguardMemberPrefixDefault = 24
+
+// Default MTU for WireGuard networks. This leaves room for the WireGuard encapsulation over IPv6.
+const wireguardMTUDefault = 1420
+
+// wireguard represents a LXD WireGuard network. It is a bridge network on each cluster member, with the bridges
+// being connected together through an encrypted WireGuard mesh.
+type wireguard struct {
+	bridge
+}
+
+// DBType returns the network type DB ID.
+func (n *wireguard) DBType() db.NetworkType {
+	return db.NetworkTypeWireguard
+}
+
+// Info returns the network driver info.
+func (n *wireguard) Info() Info {
+	info := n.bridge.Info()
+	info.Peering = false
+
+	return info
+}
+
+// ValidateName validates network name.
+func (n *wireguard) ValidateName(name string) error {
+	err := n.bridge.ValidateName(name)
+	if err != nil {
+		return err
+	}
+
+	// The WireGuard interface is named after the network with a "-wg" suffix.
+	if len(name) > 12 {
+		return fmt.Errorf("Network name too long to use with WireGuard (must be 12 characters or less)")
+	}
+
+	return nil
+}
+
+// FillConfig fills requested config with any default values.
+func (n *wireguard) FillConfig(config map[string]string) error {
+	// We enable NAT by default for traffic leaving the WireGuard range.
+	if config["ipv4.nat"] == "" {
+		config["ipv4.nat"] = "true"
+	}
+
+	if config["bridge.mtu"] == "" {
+		config["bridge.mtu"] = strconv.Itoa(wireguardMTUDefault)
+	}
+
+	return nil
+}
+
+// Validate network config.
+func (n *wireguard) Validate(config map[string]string) error {
+	rules := map[string]func(value string) error{
+		// lxdmeta:generate(entities=network-wireguard; group=network-conf; key=wireguard.range)
+		// Use CIDR notation.
+		//
+		// A subnet of this range is allocated to each cluster member the network is started on.
+		// ---
+		//  type: string
+		//  required: yes
+		//  shortdesc: IPv4 range shared by the cluster members
+		"wireguard.range": validate.Required(validate.IsNetworkV4),
+		// lxdmeta:generate(entities=network-wireguard; group=network-conf; key=wireguard.member_prefix)
+		//
+		// ---
+		//  type: integer
+		//  defaultdesc: `24`
+		//  shortdesc: Prefix length of the subnet allocated to each cluster member
+		"wireguard.member_prefix": validate.Optional(validate.IsInRange(1, 30)),
+		// lxdmeta:generate(entities=network-wireguard; group=network-conf; key=wireguard.port)
+		//
+		// ---
+		//  type: integer
+		//  defaultdesc: `51820`
+		//  shortdesc: UDP port used by the WireGuard tunnels
+		"wireguard.port": validate.Optional(validate.IsNetworkPort),
+
+		// Member specific keys managed by LXD.
+		wireguardVolatileSubnet:    validate.Optional(validate.IsNetworkV4),
+		wireguardVolatilePublicKey: validate.IsAny,
+	}
+
+	for k, v := range config {
+		if v == "" {
+			continue
+		}
+
+		if k == "ipv4.address" || k == "bridge.mode" || strings.HasPrefix(k, "fan.") || strings.HasPrefix(k, "tunnel.") {
+			return fmt.Errorf("Config key %q cannot be used with WireGuard networks", k)
+		}
+	}
+
+	if !shared.ValueInSlice(config["ipv6.address"], []string{"", "none"}) {
+		return fmt.Errorf("IPv6 isn't supported on WireGuard networks")
+	}
+
+	err := n.bridge.validateConfig(config, rules)
+	if err != nil {
+		return err
+	}
+
+	// Check the member subnets fit into the range.
+	_, wgRange, _ := net.ParseCIDR(config["wireguard.range"])
+	rangePrefix, _ := wgRange.Mask.Size()
+	if n.memberPrefix(config) <= rangePrefix {
+		return fmt.Errorf(`"wireguard.member_prefix" must be larger than the prefix length of "wireguard.range"`)
+	}
+
+	return nil
+}
+
+// memberPrefix returns the prefix length of the subnets allocated to the cluster members.
+func (n *wireguard) memberPrefix(config map[string]string) int {
+	memberPrefix, err := strconv.Atoi(config["wireguard.member_prefix"])
+	if err != nil {
+		return wireguardMemberPrefixDefault
+	}
+
+	return memberPrefix
+}
+
+// tunnelName returns the name of the WireGuard interface of the network.
+func (n *wireguard) tunnelName() string {
+	return fmt.Sprintf("%s-wg", n.name)
+}
+
+// privateKeyPath returns the path of the file holding the WireGuard private key of the local cluster member.
+func (n *wireguard) privateKeyPath() string {
+	return shared.VarPath("networks", n.name, "wireguard.key")
+}
+
+// Start starts the network.
+func (n *wireguard) Start() error {
+	n.logger.Debug("Start")
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	revert.Add(func() { n.setUnavailable() })
+
+	err := n.allocate()
+	if err != nil {
+		return err
+	}
+
+	err = n.bridge.setup(nil)
+	if err != nil {
+		return err
+	}
+
+	err = n.setupTunnel()
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+
+	// Ensure network is marked as available now its started.
+	n.setAvailable()
+
+	return nil
+}
+
+// Update updates the network. Accepts notification boolean indicating if this update request is coming from a
+// cluster notification, in which case do not update the database, just apply local changes needed.
+func (n *wireguard) Update(newNetwork api.NetworkPut, targetNode string, clientType request.ClientType) error {
+	// The volatile keys are managed by LXD, so keep them if they aren't part of the new config.
+	for _, k := range []string{wireguardVolatileSubnet, wireguardVolatilePublicKey} {
+		_, found := newNetwork.Config[k]
+		if !found && n.config[k] != "" {
+			newNetwork.Config[k] = n.config[k]
+		}
+	}
+
+	_, changedKeys, _, err := n.common.configChanged(newNetwork)
+	if err != nil {
+		return err
+	}
+
+	err = n.bridge.Update(newNetwork, targetNode, clientType)
+	if err != nil {
+		return err
+	}
+
+	if len(changedKeys) == 0 || n.Status() == api.NetworkStatusPending || n.LocalStatus() == api.NetworkStatusPending {
+		return nil
+	}
+
+	// Allocate a new subnet if the current one doesn't fit the range anymore.
+	if shared.ValueInSlice("wireguard.range", changedKeys) || shared.ValueInSlice("wireguard.member_prefix", changedKeys) {
+		oldSubnet := n.config[wireguardVolatileSubnet]
+
+		err = n.allocate()
+		if err != nil {
+			return err
+		}
+
+		if n.config[wireguardVolatileSubnet] != oldSubnet {
+			err = n.bridge.setup(nil)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// The bridge setup removes the WireGuard interface, so set it up again.
+	return n.setupTunnel()
+}
+
+// Rename renames a network.
+func (n *wireguard) Rename(newName string) error {
+	err := n.bridge.Rename(newName)
+	if err != nil {
+		return err
+	}
+
+	return n.setupTunnel()
+}
+
+// HandleHeartbeat refreshes the WireGuard peers and routes from the cluster members in the heartbeat.
+func (n *wireguard) HandleHeartbeat(heartbeatData *cluster.APIHeartbeat) error {
+	memberAddresses := make(map[string]string, len(heartbeatData.Members))
+	for _, member := range heartbeatData.Members {
+		memberAddresses[member.Name] = member.Address
+	}
+
+	return n.setupPeers(memberAddresses)
+}
+
+// allocate generates the WireGuard key of the local cluster member and allocates it a subnet of the range that
+// doesn't overlap with those of the other members. The current subnet is kept if it still fits the range.
+func (n *wireguard) allocate() error {
+	publicKey, err := n.loadKey()
+	if err != nil {
+		return err
+	}
+
+	_, wgRange, err := net.ParseCIDR(n.config["wireguard.range"])
+	if err != nil {
+		return fmt.Errorf("Failed parsing wireguard.range: %w", err)
+	}
+
+	memberPrefix := n.memberPrefix(n.config)
+
+	return n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		memberConfigs, err := tx.NetworkNodeConfigs(ctx, n.id)
+		if err != nil {
+			return fmt.Errorf("Failed loading member config: %w", err)
+		}
+
+		var memberSubnet *net.IPNet
+		usedSubnets := []*net.IPNet{}
+
+		for memberName, memberConfig := range memberConfigs {
+			_, subnet, err := net.ParseCIDR(memberConfig[wireguardVolatileSubnet])
+			if err != nil {
+				continue
+			}
+
+			if memberName != n.state.ServerName {
+				usedSubnets = append(usedSubnets, subnet)
+				continue
+			}
+
+			prefixLen, _ := subnet.Mask.Size()
+			if prefixLen == memberPrefix && SubnetContains(wgRange, subnet) {
+				memberSubnet = subnet
+			}
+		}
+
+		// Check the current subnet hasn't been taken by another member in the meantime.
+		for _, usedSubnet := range usedSubnets {
+			if memberSubnet != nil && (SubnetContains(usedSubnet, memberSubnet) || SubnetContains(memberSubnet, usedSubnet)) {
+				memberSubnet = nil
+			}
+		}
+
+		if memberSubnet == nil {
+			memberSubnet = wireguardFreeSubnet(wgRange, memberPrefix, usedSubnets)
+			if memberSubnet == nil {
+				return fmt.Errorf("No free subnet left in wireguard.range %q", wgRange.String())
+			}
+		}
+
+		if n.config[wireguardVolatileSubnet] == memberSubnet.String() && n.config[wireguardVolatilePublicKey] == publicKey {
+			return nil
+		}
+
+		n.config[wireguardVolatileSubnet] = memberSubnet.String()
+		n.config[wireguardVolatilePublicKey] = publicKey
+
+		return tx.UpdateNetwork(ctx, n.project, n.name, n.description, n.config)
+	})
+}
+
+// wireguardFreeSubnet returns the first subnet of the range with the given prefix length that doesn't overlap
+// with any of the used subnets, or nil if there is none.
+func wireguardFreeSubnet(wgRange *net.IPNet, prefixLen int, usedSubnets []*net.IPNet) *net.IPNet {
+	rangePrefixLen, _ := wgRange.Mask.Size()
+	start := binary.BigEndian.Uint32(wgRange.IP.To4())
+	subnetSize := uint32(1) << (32 - prefixLen)
+
+	for i := uint32(0); i < uint32(1)<<(prefixLen-rangePrefixLen); i++ {
+		subnetIP := make(net.IP, net.IPv4len)
+		binary.BigEndian.PutUint32(subnetIP, start+i*subnetSize)
+
+		subnet := &net.IPNet{IP: subnetIP, Mask: net.CIDRMask(prefixLen, 32)}
+
+		used := false
+		for _, usedSubnet := range usedSubnets {
+			if SubnetContains(usedSubnet, subnet) || SubnetContains(subnet, usedSubnet) {
+				used = true
+				break
+			}
+		}
+
+		if !used {
+			return subnet
+		}
+	}
+
+	return nil
+}
+
+// loadKey loads the WireGuard private key of the local cluster member, generating it if missing, and returns
+// the matching public key.
+func (n *wireguard) loadKey() (string, error) {
+	keyPath := n.privateKeyPath()
+
+	if !shared.PathExists(keyPath) {
+		err := os.MkdirAll(shared.VarPath("networks", n.name), 0711)
+		if err != nil {
+			return "", err
+		}
+
+		privateKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+		if err != nil {
+			return "", fmt.Errorf("Failed generating WireGuard key: %w", err)
+		}
+
+		err = os.WriteFile(keyPath, []byte(base64.StdEncoding.EncodeToString(privateKey.Bytes())+"\n"), 0600)
+		if err != nil {
+			return "", fmt.Errorf("Failed writing WireGuard key: %w", err)
+		}
+	}
+
+	content, err := os.ReadFile(keyPath)
+	if err != nil {
+		return "", fmt.Errorf("Failed reading WireGuard key: %w", err)
+	}
+
+	keyBytes, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(content)))
+	if err != nil {
+		return "", fmt.Errorf("Failed decoding WireGuard key: %w", err)
+	}
+
+	privateKey, err := ecdh.X25519().NewPrivateKey(keyBytes)
+	if err != nil {
+		return "", fmt.Errorf("Invalid WireGuard key %q: %w", keyPath, err)
+	}
+
+	return base64.StdEncoding.EncodeToString(privateKey.PublicKey().Bytes()), nil
+}
+
+// setupTunnel creates the WireGuard interface of the network and configures its peers.
+func (n *wireguard) setupTunnel() error {
+	// If we are in mock mode, just no-op.
+	if n.state.OS.MockMode {
+		return nil
+	}
+
+	_, memberSubnet, err := net.ParseCIDR(n.config[wireguardVolatileSubnet])
+	if err != nil {
+		return fmt.Errorf("No subnet allocated to the local cluster member: %w", err)
+	}
+
+	_, wgRange, err := net.ParseCIDR(n.config["wireguard.range"])
+	if err != nil {
+		return fmt.Errorf("Failed parsing wireguard.range: %w", err)
+	}
+
+	mtu, err := strconv.ParseUint(n.config["bridge.mtu"], 10, 32)
+	if err != nil {
+		mtu = wireguardMTUDefault
+	}
+
+	port := n.config["wireguard.port"]
+	if port == "" {
+		port = strconv.Itoa(wireguardPortDefault)
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	tunName := n.tunnelName()
+	tunLink := &ip.Wireguard{Link: ip.Link{Name: tunName, MTU: uint32(mtu)}}
+
+	if !InterfaceExists(tunName) {
+		err = tunLink.Add()
+		if err != nil {
+			return err
+		}
+
+		revert.Add(func() { _ = tunLink.Delete() })
+	} else {
+		err = tunLink.SetMTU(uint32(mtu))
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = shared.RunCommand("wg", "set", tunName, "listen-port", port, "private-key", n.privateKeyPath())
+	if err != nil {
+		return fmt.Errorf("Failed configuring WireGuard interface %q: %w", tunName, err)
+	}
+
+	err = tunLink.SetUp()
+	if err != nil {
+		return err
+	}
+
+	// Route the traffic between the member subnets without NAT.
+	err = n.state.Firewall.NetworkApplyPeers(n.name, []firewallDrivers.NetworkPeer{{
+		Interface:     tunName,
+		LocalSubnets:  []*net.IPNet{memberSubnet},
+		TargetSubnets: []*net.IPNet{wgRange},
+	}})
+	if err != nil {
+		return fmt.Errorf("Failed applying firewall WireGuard rules: %w", err)
+	}
+
+	// Configure the peers from the cluster members in the database.
+	var members []db.NodeInfo
+
+	err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		members, err = tx.GetNodes(ctx)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading cluster members: %w", err)
+	}
+
+	memberAddresses := make(map[string]string, len(members))
+	for _, member := range members {
+		memberAddresses[member.Name] = member.Address
+	}
+
+	err = n.setupPeers(memberAddresses)
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+	return nil
+}
+
+// wireguardPeer represents a remote cluster member connected through the WireGuard interface.
+type wireguardPeer struct {
+	endpoint string
+	subnet   *net.IPNet
+}
+
+// setupPeers configures a WireGuard peer and a route for each of the given cluster members (keyed by name with
+// their cluster address as value) that has a subnet allocated. Peers and routes of other members are removed.
+func (n *wireguard) setupPeers(memberAddresses map[string]string) error {
+	tunName := n.tunnelName()
+	if !InterfaceExists(tunName) {
+		return nil
+	}
+
+	var memberConfigs map[string]map[string]string
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		memberConfigs, err = tx.NetworkNodeConfigs(ctx, n.id)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading member config: %w", err)
+	}
+
+	port := n.config["wireguard.port"]
+	if port == "" {
+		port = strconv.Itoa(wireguardPortDefault)
+	}
+
+	// Build the wanted peers keyed by public key.
+	peers := make(map[string]wireguardPeer)
+	for memberName, memberAddress := range memberAddresses {
+		if memberName == n.state.ServerName {
+			continue
+		}
+
+		memberConfig := memberConfigs[memberName]
+		publicKey := memberConfig[wireguardVolatilePublicKey]
+		_, subnet, err := net.ParseCIDR(memberConfig[wireguardVolatileSubnet])
+		if err != nil || publicKey == "" {
+			continue // The network hasn't been started on the member yet.
+		}
+
+		host, _, err := net.SplitHostPort(memberAddress)
+		if err != nil {
+			n.logger.Warn("Skipping WireGuard peer with invalid cluster address", logger.Ctx{"member": memberName, "address": memberAddress})
+			continue
+		}
+
+		peers[publicKey] = wireguardPeer{
+			endpoint: net.JoinHostPort(host, port),
+			subnet:   subnet,
+		}
+	}
+
+	// Remove the peers of the members that left the cluster.
+	out, err := shared.RunCommand("wg", "show", tunName, "peers")
+	if err != nil {
+		return fmt.Errorf("Failed listing WireGuard peers: %w", err)
+	}
+
+	for _, publicKey := range shared.SplitNTrimSpace(out, "\n", -1, true) {
+		_, found := peers[publicKey]
+		if found {
+			continue
+		}
+
+		_, err = shared.RunCommand("wg", "set", tunName, "peer", publicKey, "remove")
+		if err != nil {
+			return fmt.Errorf("Failed removing WireGuard peer %q: %w", publicKey, err)
+		}
+
+		n.logger.Info("Removed WireGuard peer", logger.Ctx{"publicKey": publicKey})
+	}
+
+	// Add or update the peers.
+	for publicKey, peer := range peers {
+		_, err = shared.RunCommand("wg", "set", tunName, "peer", publicKey, "endpoint", peer.endpoint, "allowed-ips", peer.subnet.String(), "persistent-keepalive", "25")
+		if err != nil {
+			return fmt.Errorf("Failed configuring WireGuard peer %q: %w", publicKey, err)
+		}
+	}
+
+	// Route the subnets of the other members through the WireGuard interface.
+	r := &ip.Route{
+		DevName: tunName,
+		Proto:   "static",
+		Family:  ip.FamilyV4,
+	}
+
+	routes, err := r.Show()
+	if err != nil {
+		return err
+	}
+
+	currentRoutes := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		fields := strings.Fields(route)
+		if len(fields) > 0 {
+			currentRoutes[fields[0]] = true
+		}
+	}
+
+	wantedRoutes := make(map[string]bool, len(peers))
+	for _, peer := range peers {
+		wantedRoutes[peer.subnet.String()] = true
+	}
+
+	for route := range currentRoutes {
+		if wantedRoutes[route] {
+			continue
+		}
+
+		r := &ip.Route{DevName: tunName, Route: route, Proto: "static", Family: ip.FamilyV4}
+		err = r.Flush()
+		if err != nil {
+			return err
+		}
+	}
+
+	srcAddress, _, _ := net.ParseCIDR(n.ipv4AddressConfig())
+
+	for route := range wantedRoutes {
+		if currentRoutes[route] {
+			continue
+		}
+
+		r := &ip.Route{DevName: tunName, Route: route, Proto: "static", Family: ip.FamilyV4}
+		if srcAddress != nil {
+			r.Src = srcAddress.String()
+		}
+
+		err = r.Add()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
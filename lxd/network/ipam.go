@@ -0,0 +1,795 @@
+package network
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// IPAMNIC identifies the instance NIC that addresses are allocated to.
+type IPAMNIC struct {
+	ProjectName  string
+	InstanceID   int64
+	InstanceUUID string
+	DeviceName   string
+}
+
+// ipamRange represents an inclusive range of addresses.
+type ipamRange struct {
+	start netip.Addr
+	end   netip.Addr
+}
+
+// contains returns true if the address is within the range.
+func (r ipamRange) contains(addr netip.Addr) bool {
+	return addr.BitLen() == r.start.BitLen() && addr.Compare(r.start) >= 0 && addr.Compare(r.end) <= 0
+}
+
+// overlaps returns true if the ranges have addresses in common.
+func (r ipamRange) overlaps(other ipamRange) bool {
+	return r.start.BitLen() == other.start.BitLen() && r.start.Compare(other.end) <= 0 && other.start.Compare(r.end) <= 0
+}
+
+// String returns the range in the "start-end" format.
+func (r ipamRange) String() string {
+	return fmt.Sprintf("%s-%s", r.start, r.end)
+}
+
+// ipamParseAddress parses an IP address, converting IPv4-mapped IPv6 addresses to IPv4 addresses.
+func ipamParseAddress(address string) (netip.Addr, error) {
+	addr, err := netip.ParseAddr(address)
+	if err != nil || addr.Zone() != "" {
+		return netip.Addr{}, fmt.Errorf("Invalid IP address %q", address)
+	}
+
+	return addr.Unmap(), nil
+}
+
+// ipamSubnet returns the address and subnet of the network for the family of the given address.
+func ipamSubnet(config map[string]string, addr netip.Addr) (netip.Addr, netip.Prefix, error) {
+	key := "ipv4.address"
+	if addr.Is6() {
+		key = "ipv6.address"
+	}
+
+	prefix, err := netip.ParsePrefix(config[key])
+	if err != nil {
+		if addr.Is6() {
+			return netip.Addr{}, netip.Prefix{}, api.StatusErrorf(http.StatusBadRequest, "Network has no IPv6 subnet")
+		}
+
+		return netip.Addr{}, netip.Prefix{}, api.StatusErrorf(http.StatusBadRequest, "Network has no IPv4 subnet")
+	}
+
+	return prefix.Addr().Unmap(), prefix.Masked(), nil
+}
+
+// ipamConfiguredRanges returns the DHCP ranges explicitly configured on the network.
+func ipamConfiguredRanges(config map[string]string) []ipamRange {
+	var ranges []ipamRange
+
+	for _, key := range []string{"ipv4.dhcp.ranges", "ipv6.dhcp.ranges"} {
+		if config[key] == "" {
+			continue
+		}
+
+		for _, r := range strings.Split(config[key], ",") {
+			start, end, found := strings.Cut(strings.TrimSpace(r), "-")
+			if !found {
+				continue
+			}
+
+			startAddr, err := ipamParseAddress(start)
+			if err != nil {
+				continue
+			}
+
+			endAddr, err := ipamParseAddress(end)
+			if err != nil {
+				continue
+			}
+
+			ranges = append(ranges, ipamRange{start: startAddr, end: endAddr})
+		}
+	}
+
+	return ranges
+}
+
+// ipamParseRange parses and validates the bounds of an IP range against the subnet of the network.
+func ipamParseRange(config map[string]string, put api.NetworkIPRangePut) (*ipamRange, error) {
+	start, err := ipamParseAddress(put.Start)
+	if err != nil {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "Invalid start address: %w", err)
+	}
+
+	end, err := ipamParseAddress(put.End)
+	if err != nil {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "Invalid end address: %w", err)
+	}
+
+	if start.BitLen() != end.BitLen() {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "Start address %q and end address %q must be of the same family", start, end)
+	}
+
+	if start.Compare(end) > 0 {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "Start address %q must be lower than end address %q", start, end)
+	}
+
+	_, subnet, err := ipamSubnet(config, start)
+	if err != nil {
+		return nil, err
+	}
+
+	if !subnet.Contains(start) || !subnet.Contains(end) {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "IP range \"%s-%s\" isn't within the network subnet %q", start, end, subnet)
+	}
+
+	return &ipamRange{start: start, end: end}, nil
+}
+
+// ipamRanges returns the parsed IP ranges of the network.
+func ipamRanges(ipRanges []*api.NetworkIPRange) (map[string]ipamRange, error) {
+	parsed := make(map[string]ipamRange, len(ipRanges))
+
+	for _, ipRange := range ipRanges {
+		start, err := ipamParseAddress(ipRange.Start)
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing IP range %q: %w", ipRange.Name, err)
+		}
+
+		end, err := ipamParseAddress(ipRange.End)
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing IP range %q: %w", ipRange.Name, err)
+		}
+
+		parsed[ipRange.Name] = ipamRange{start: start, end: end}
+	}
+
+	return parsed, nil
+}
+
+// ipamRangeValidate validates an IP range against the network config and its other IP ranges.
+func (n *common) ipamRangeValidate(ctx context.Context, tx *db.ClusterTx, name string, put api.NetworkIPRangePut) error {
+	newRange, err := ipamParseRange(n.config, put)
+	if err != nil {
+		return err
+	}
+
+	for _, dhcpRange := range ipamConfiguredRanges(n.config) {
+		if newRange.overlaps(dhcpRange) {
+			return api.StatusErrorf(http.StatusBadRequest, "IP range %q overlaps with the DHCP range %q of the network", newRange, dhcpRange)
+		}
+	}
+
+	ipRanges, err := tx.GetNetworkIPRanges(ctx, n.id)
+	if err != nil {
+		return fmt.Errorf("Failed loading IP ranges: %w", err)
+	}
+
+	parsedRanges, err := ipamRanges(ipRanges)
+	if err != nil {
+		return err
+	}
+
+	for otherName, otherRange := range parsedRanges {
+		if otherName == name {
+			continue
+		}
+
+		if newRange.overlaps(otherRange) {
+			return api.StatusErrorf(http.StatusBadRequest, "IP range %q overlaps with IP range %q", newRange, otherName)
+		}
+	}
+
+	return nil
+}
+
+// IPRangeCreate creates a new IP range in the network.
+func (n *common) IPRangeCreate(ipRange api.NetworkIPRangesPost) error {
+	if ipRange.Name == "" {
+		return api.StatusErrorf(http.StatusBadRequest, "IP range name is required")
+	}
+
+	return n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, _, err := tx.GetNetworkIPRange(ctx, n.id, ipRange.Name)
+		if err == nil {
+			return api.StatusErrorf(http.StatusConflict, "An IP range with that name already exists")
+		} else if !api.StatusErrorCheck(err, http.StatusNotFound) {
+			return err
+		}
+
+		err = n.ipamRangeValidate(ctx, tx, ipRange.Name, ipRange.NetworkIPRangePut)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.CreateNetworkIPRange(ctx, n.id, &ipRange)
+
+		return err
+	})
+}
+
+// IPRangeUpdate updates the IP range with the given name in the network.
+func (n *common) IPRangeUpdate(name string, newRange api.NetworkIPRangePut) error {
+	return n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		id, _, err := tx.GetNetworkIPRange(ctx, n.id, name)
+		if err != nil {
+			return err
+		}
+
+		err = n.ipamRangeValidate(ctx, tx, name, newRange)
+		if err != nil {
+			return err
+		}
+
+		return tx.UpdateNetworkIPRange(ctx, id, newRange)
+	})
+}
+
+// IPRangeDelete deletes the IP range with the given name from the network.
+// Addresses already allocated from the range are kept.
+func (n *common) IPRangeDelete(name string) error {
+	return n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		id, _, err := tx.GetNetworkIPRange(ctx, n.id, name)
+		if err != nil {
+			return err
+		}
+
+		return tx.DeleteNetworkIPRange(ctx, id)
+	})
+}
+
+// ipamState holds the IP ranges and allocations of a network during an allocation transaction.
+type ipamState struct {
+	config      map[string]string
+	ranges      []*api.NetworkIPRange
+	parsed      map[string]ipamRange
+	allocations map[netip.Addr]*db.NetworkIPAllocation
+}
+
+// ipamLoad loads the IP ranges and allocations of the network with the given ID.
+func ipamLoad(ctx context.Context, tx *db.ClusterTx, networkID int64, config map[string]string) (*ipamState, error) {
+	ipRanges, err := tx.GetNetworkIPRanges(ctx, networkID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading IP ranges: %w", err)
+	}
+
+	parsedRanges, err := ipamRanges(ipRanges)
+	if err != nil {
+		return nil, err
+	}
+
+	allocations, err := tx.GetNetworkIPAllocations(ctx, networkID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading IP allocations: %w", err)
+	}
+
+	st := &ipamState{
+		config:      config,
+		ranges:      ipRanges,
+		parsed:      parsedRanges,
+		allocations: make(map[netip.Addr]*db.NetworkIPAllocation, len(allocations)),
+	}
+
+	for _, allocation := range allocations {
+		addr, err := ipamParseAddress(allocation.Address)
+		if err != nil {
+			continue
+		}
+
+		st.allocations[addr] = allocation
+	}
+
+	return st, nil
+}
+
+// delegatedRanges returns the ranges of the given family delegated to the project.
+func (st *ipamState) delegatedRanges(projectName string, bitLen int) []ipamRange {
+	var delegated []ipamRange
+
+	for _, ipRange := range st.ranges {
+		parsed := st.parsed[ipRange.Name]
+		if ipRange.Project == projectName && parsed.start.BitLen() == bitLen {
+			delegated = append(delegated, parsed)
+		}
+	}
+
+	return delegated
+}
+
+// checkAddress checks that the address can be used by the project.
+func (st *ipamState) checkAddress(projectName string, addr netip.Addr) error {
+	gateway, subnet, err := ipamSubnet(st.config, addr)
+	if err != nil {
+		return err
+	}
+
+	if !subnet.Contains(addr) {
+		return api.StatusErrorf(http.StatusBadRequest, "Address %q isn't within the network subnet %q", addr, subnet)
+	}
+
+	if addr == gateway || addr == subnet.Addr() {
+		return api.StatusErrorf(http.StatusBadRequest, "Address %q can't be allocated", addr)
+	}
+
+	for _, ipRange := range st.ranges {
+		if !st.parsed[ipRange.Name].contains(addr) {
+			continue
+		}
+
+		if ipRange.Project == "" {
+			return api.StatusErrorf(http.StatusForbidden, "Address %q is within the reserved IP range %q", addr, ipRange.Name)
+		}
+
+		if ipRange.Project != projectName {
+			return api.StatusErrorf(http.StatusForbidden, "Address %q is within an IP range delegated to another project", addr)
+		}
+
+		return nil
+	}
+
+	// Projects with delegated ranges can only use the addresses within them.
+	if len(st.delegatedRanges(projectName, addr.BitLen())) > 0 {
+		return api.StatusErrorf(http.StatusForbidden, "Address %q isn't within the IP ranges delegated to project %q", addr, projectName)
+	}
+
+	return nil
+}
+
+// freeAddress returns the first address of the ranges delegated to the project that isn't allocated.
+func (st *ipamState) freeAddress(projectName string, bitLen int) (netip.Addr, error) {
+	delegated := st.delegatedRanges(projectName, bitLen)
+	if len(delegated) == 0 {
+		return netip.Addr{}, api.StatusErrorf(http.StatusBadRequest, "No IP range is delegated to project %q", projectName)
+	}
+
+	for _, r := range delegated {
+		for addr := r.start; addr.IsValid() && addr.Compare(r.end) <= 0; addr = addr.Next() {
+			if st.allocations[addr] != nil {
+				continue
+			}
+
+			if st.checkAddress(projectName, addr) != nil {
+				continue
+			}
+
+			return addr, nil
+		}
+	}
+
+	return netip.Addr{}, api.StatusErrorf(http.StatusInsufficientStorage, "No free address left in the IP ranges delegated to project %q", projectName)
+}
+
+// IPAllocate allocates an address in the network to the project.
+// If no address is specified, the first free address of the ranges delegated to the project is allocated.
+func (n *common) IPAllocate(projectName string, allocation api.NetworkIPAllocationsPost) (net.IP, error) {
+	var allocated netip.Addr
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		st, err := ipamLoad(ctx, tx, n.id, n.config)
+		if err != nil {
+			return err
+		}
+
+		if allocation.Address == "" {
+			bitLen := 32
+			switch allocation.Family {
+			case "inet", "":
+			case "inet6":
+				bitLen = 128
+			default:
+				return api.StatusErrorf(http.StatusBadRequest, "Invalid address family %q", allocation.Family)
+			}
+
+			allocated, err = st.freeAddress(projectName, bitLen)
+			if err != nil {
+				return err
+			}
+		} else {
+			allocated, err = ipamParseAddress(allocation.Address)
+			if err != nil {
+				return api.StatusErrorf(http.StatusBadRequest, "%w", err)
+			}
+
+			if st.allocations[allocated] != nil {
+				return api.StatusErrorf(http.StatusConflict, "Address %q is already allocated", allocated)
+			}
+
+			err = st.checkAddress(projectName, allocated)
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.CreateNetworkIPAllocation(ctx, n.id, projectName, -1, "", allocated.String(), allocation.Description)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return net.IP(allocated.AsSlice()), nil
+}
+
+// IPRelease releases an address allocated in the network to the project.
+// Addresses allocated to instance NICs are released when the NIC is removed.
+func (n *common) IPRelease(projectName string, address string) error {
+	addr, err := ipamParseAddress(address)
+	if err != nil {
+		return api.StatusErrorf(http.StatusBadRequest, "%w", err)
+	}
+
+	return n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		st, err := ipamLoad(ctx, tx, n.id, n.config)
+		if err != nil {
+			return err
+		}
+
+		allocation := st.allocations[addr]
+		if allocation == nil || allocation.Project != projectName {
+			return api.StatusErrorf(http.StatusNotFound, "Network IP allocation not found")
+		}
+
+		if allocation.InstanceID >= 0 {
+			return api.StatusErrorf(http.StatusBadRequest, "Address %q is allocated to device %q of instance %q", addr, allocation.Device, allocation.Instance)
+		}
+
+		return tx.DeleteNetworkIPAllocation(ctx, allocation.ID)
+	})
+}
+
+// IPAllocations returns the addresses allocated in the network.
+// If projectName is not empty, only the addresses allocated to that project are returned.
+func (n *common) IPAllocations(projectName string) ([]api.NetworkIPAllocation, error) {
+	var allocations []*db.NetworkIPAllocation
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		allocations, err = tx.GetNetworkIPAllocations(ctx, n.id)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]api.NetworkIPAllocation, 0, len(allocations))
+	for _, allocation := range allocations {
+		if projectName != "" && allocation.Project != projectName {
+			continue
+		}
+
+		result = append(result, allocation.NetworkIPAllocation)
+	}
+
+	return result, nil
+}
+
+// ipamOwnedBy returns true if the allocation belongs to the NIC.
+// Allocations of another copy of the same logical instance (such as during a migration) are considered owned
+// by the NIC as well.
+func ipamOwnedBy(allocation *db.NetworkIPAllocation, nic IPAMNIC) bool {
+	if allocation.InstanceID < 0 || allocation.Project != nic.ProjectName || allocation.Device != nic.DeviceName {
+		return false
+	}
+
+	if allocation.InstanceID == nic.InstanceID {
+		return true
+	}
+
+	return nic.InstanceUUID != "" && allocation.InstanceUUID == nic.InstanceUUID
+}
+
+// IPAMAllocateNIC records the addresses used by an instance NIC in the IP address management of the network and
+// releases any other address previously allocated to it. For each family (4 or 6) in autoFamilies that has no
+// address specified, an address is picked from the ranges delegated to the NIC's project, if any.
+// Returns the addresses allocated to the NIC.
+func IPAMAllocateNIC(s *state.State, n Network, nic IPAMNIC, addresses []net.IP, autoFamilies ...uint) ([]net.IP, error) {
+	var allocated []net.IP
+
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		st, err := ipamLoad(ctx, tx, n.ID(), n.Config())
+		if err != nil {
+			return err
+		}
+
+		wanted := make(map[netip.Addr]bool, len(addresses))
+		for _, ip := range addresses {
+			addr, ok := netip.AddrFromSlice(ip)
+			if !ok {
+				return fmt.Errorf("Invalid IP address %q", ip)
+			}
+
+			wanted[addr.Unmap()] = true
+		}
+
+		// Pick addresses for the families without any.
+		for _, family := range autoFamilies {
+			bitLen := 32
+			if family == 6 {
+				bitLen = 128
+			}
+
+			found := false
+			for addr := range wanted {
+				if addr.BitLen() == bitLen {
+					found = true
+					break
+				}
+			}
+
+			if found || len(st.delegatedRanges(nic.ProjectName, bitLen)) == 0 {
+				continue
+			}
+
+			addr, err := st.freeAddress(nic.ProjectName, bitLen)
+			if err != nil {
+				return err
+			}
+
+			wanted[addr] = true
+		}
+
+		// Release the addresses the NIC doesn't use anymore.
+		for addr, allocation := range st.allocations {
+			if wanted[addr] || !ipamOwnedBy(allocation, nic) {
+				continue
+			}
+
+			err = tx.DeleteNetworkIPAllocation(ctx, allocation.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		for addr := range wanted {
+			allocated = append(allocated, net.IP(addr.AsSlice()))
+
+			allocation := st.allocations[addr]
+			if allocation != nil {
+				if !ipamOwnedBy(allocation, nic) {
+					return api.StatusErrorf(http.StatusConflict, "Address %q is already allocated", addr)
+				}
+
+				// Take over the allocation of another copy of the same instance.
+				if allocation.InstanceID != nic.InstanceID {
+					err = tx.UpdateNetworkIPAllocationInstance(ctx, allocation.ID, nic.InstanceID)
+					if err != nil {
+						return err
+					}
+				}
+
+				continue
+			}
+
+			err = st.checkAddress(nic.ProjectName, addr)
+			if err != nil {
+				return err
+			}
+
+			err = tx.CreateNetworkIPAllocation(ctx, n.ID(), nic.ProjectName, nic.InstanceID, nic.DeviceName, addr.String(), "")
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return allocated, nil
+}
+
+// IPAMReleaseNIC releases the addresses allocated to an instance NIC in the network.
+func IPAMReleaseNIC(s *state.State, n Network, nic IPAMNIC) error {
+	return s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		allocations, err := tx.GetNetworkIPAllocations(ctx, n.ID())
+		if err != nil {
+			return err
+		}
+
+		for _, allocation := range allocations {
+			if allocation.InstanceID != nic.InstanceID || allocation.Device != nic.DeviceName {
+				continue
+			}
+
+			err = tx.DeleteNetworkIPAllocation(ctx, allocation.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ipamHostRoute represents a route of the host routing table.
+type ipamHostRoute struct {
+	subnet net.IPNet
+	device string
+}
+
+// ipamHostRoutes returns the routes of the main routing table of the host for the given family.
+func ipamHostRoutes(ipv6 bool) ([]ipamHostRoute, error) {
+	filename := "/proc/net/route"
+	if ipv6 {
+		filename = "/proc/net/ipv6_route"
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = file.Close() }()
+
+	var routes []ipamHostRoute
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+
+		if ipv6 {
+			if len(fields) < 10 {
+				continue
+			}
+
+			ip, err := hex.DecodeString(fields[0])
+			if err != nil || len(ip) != net.IPv6len {
+				continue
+			}
+
+			size, err := strconv.ParseInt(fields[1], 16, 0)
+			if err != nil {
+				continue
+			}
+
+			routes = append(routes, ipamHostRoute{
+				subnet: net.IPNet{IP: ip, Mask: net.CIDRMask(int(size), 128)},
+				device: fields[9],
+			})
+		} else {
+			if len(fields) < 8 {
+				continue
+			}
+
+			ip, err := hex.DecodeString(fields[1])
+			if err != nil || len(ip) != net.IPv4len {
+				continue
+			}
+
+			mask, err := hex.DecodeString(fields[7])
+			if err != nil || len(mask) != net.IPv4len {
+				continue
+			}
+
+			routes = append(routes, ipamHostRoute{
+				subnet: net.IPNet{IP: net.IPv4(ip[3], ip[2], ip[1], ip[0]).To4(), Mask: net.IPv4Mask(mask[3], mask[2], mask[1], mask[0])},
+				device: fields[0],
+			})
+		}
+	}
+
+	return routes, scanner.Err()
+}
+
+// ipamCheckSubnetConflicts checks that the subnets of a bridge network don't overlap with the routes of the host
+// (other than its own) or with the subnets of other networks whose addresses are reachable from the host.
+func (n *common) ipamCheckSubnetConflicts(config map[string]string) error {
+	var subnets []*net.IPNet
+	for _, key := range []string{"ipv4.address", "ipv6.address"} {
+		_, subnet, err := net.ParseCIDR(config[key])
+		if err != nil {
+			continue
+		}
+
+		subnets = append(subnets, subnet)
+	}
+
+	if len(subnets) == 0 {
+		return nil
+	}
+
+	for _, subnet := range subnets {
+		routes, err := ipamHostRoutes(subnet.IP.To4() == nil)
+		if err != nil {
+			return fmt.Errorf("Failed loading host routes: %w", err)
+		}
+
+		for _, route := range routes {
+			ones, _ := route.subnet.Mask.Size()
+			if ones == 0 || route.device == n.name || route.device == "lo" {
+				continue // Skip default routes as well as our own and local routes.
+			}
+
+			if route.subnet.IP.IsLinkLocalUnicast() || route.subnet.IP.IsMulticast() {
+				continue
+			}
+
+			if SubnetContains(&route.subnet, subnet) || SubnetContains(subnet, &route.subnet) {
+				return api.StatusErrorf(http.StatusConflict, "Subnet %q overlaps with route %q of interface %q", subnet, route.subnet.String(), route.device)
+			}
+		}
+	}
+
+	var projectNetworks map[string]map[int64]api.Network
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		projectNetworks, err = tx.GetCreatedNetworks(ctx)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading networks: %w", err)
+	}
+
+	for _, networks := range projectNetworks {
+		for netID, netInfo := range networks {
+			if netID == n.id {
+				continue
+			}
+
+			if netInfo.Type != "bridge" && netInfo.Type != "ovn" {
+				continue
+			}
+
+			for _, family := range []string{"ipv4", "ipv6"} {
+				// Subnets of OVN networks using NAT aren't reachable from the outside.
+				if netInfo.Type == "ovn" && shared.IsTrue(netInfo.Config[family+".nat"]) {
+					continue
+				}
+
+				_, otherSubnet, err := net.ParseCIDR(netInfo.Config[family+".address"])
+				if err != nil {
+					continue
+				}
+
+				for _, subnet := range subnets {
+					if SubnetContains(otherSubnet, subnet) || SubnetContains(subnet, otherSubnet) {
+						// This error is purposefully vague so that it doesn't reveal any names of
+						// resources potentially outside of the network's project.
+						return api.StatusErrorf(http.StatusConflict, "Subnet %q overlaps with another network", subnet)
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// ipamCheckSubnetChange checks that the IP ranges of the network remain within its new subnets.
+func (n *common) ipamCheckSubnetChange(config map[string]string) error {
+	return n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		ipRanges, err := tx.GetNetworkIPRanges(ctx, n.id)
+		if err != nil {
+			return fmt.Errorf("Failed loading IP ranges: %w", err)
+		}
+
+		for _, ipRange := range ipRanges {
+			_, err = ipamParseRange(config, ipRange.Writable())
+			if err != nil {
+				return fmt.Errorf("IP range %q is incompatible with the new network configuration: %w", ipRange.Name, err)
+			}
+		}
+
+		return nil
+	})
+}
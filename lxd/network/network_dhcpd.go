@@ -0,0 +1,552 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/dhcpd"
+	"github.com/canonical/lxd/lxd/dnsmasq/dhcpalloc"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/locking"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// dhcpServers holds the running native DHCP servers keyed by network ID.
+var dhcpServers = make(map[int64]*dhcpd.Server)
+var dhcpServersMu sync.Mutex
+
+// dhcpHosts and dhcpLeases hold the hosts and the leases of the running native DHCP servers keyed by network ID,
+// so that they don't need to be loaded from the database for every DHCP request and DNS query. The hosts are
+// reloaded whenever an instance or profile changes and the leases are kept up to date as they are stored.
+var dhcpHosts = make(map[int64]map[string]dhcpd.Host)
+var dhcpLeases = make(map[int64][]dhcpd.Lease)
+var dhcpCacheMu sync.Mutex
+
+// dhcpNetworks holds the networks of the running native DHCP servers keyed by network ID, along with whether a
+// reload of their hosts is pending.
+var dhcpNetworks = make(map[int64]*bridge)
+var dhcpHostsReloadPending = make(map[int64]bool)
+
+// UsesNativeDHCP indicates if the network uses the native DHCP and DNS server rather than dnsmasq.
+func (n *bridge) UsesNativeDHCP() bool {
+	return n.config["dhcp.backend"] == "native"
+}
+
+// nativeDHCPConfig returns the configuration of the native DHCP server of the network.
+func (n *bridge) nativeDHCPConfig(mtu uint32) (*dhcpd.Config, error) {
+	config := &dhcpd.Config{
+		Interface: n.name,
+		Domain:    n.config["dns.domain"],
+		Search:    shared.SplitNTrimSpace(n.config["dns.search"], ",", -1, true),
+		Names:     n.config["dns.mode"] != "none",
+	}
+
+	if config.Domain == "" {
+		config.Domain = "lxd"
+	}
+
+	if mtu != bridgeMTUDefault {
+		config.MTU = mtu
+	}
+
+	ipv4AddressConfig := n.ipv4AddressConfig()
+	if !shared.ValueInSlice(ipv4AddressConfig, []string{"", "none"}) {
+		address, subnet, err := net.ParseCIDR(ipv4AddressConfig)
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing ipv4.address: %w", err)
+		}
+
+		expiry := "1h"
+		if n.config["ipv4.dhcp.expiry"] != "" {
+			expiry = n.config["ipv4.dhcp.expiry"]
+		}
+
+		leaseTime, err := dhcpd.ParseExpiry(expiry)
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing ipv4.dhcp.expiry: %w", err)
+		}
+
+		ranges := n.DHCPv4Ranges()
+		if len(ranges) == 0 {
+			ranges = []shared.IPRange{{Start: dhcpalloc.GetIP(subnet, 2).To4(), End: dhcpalloc.GetIP(subnet, -2).To4()}}
+		}
+
+		config.IPv4 = &dhcpd.IPv4Config{
+			Address:   address.To4(),
+			Subnet:    subnet,
+			DHCP:      n.DHCPv4Subnet() != nil,
+			Gateway:   net.ParseIP(n.config["ipv4.dhcp.gateway"]).To4(),
+			Ranges:    ranges,
+			LeaseTime: leaseTime,
+		}
+	}
+
+	if !shared.ValueInSlice(n.config["ipv6.address"], []string{"", "none"}) {
+		address, subnet, err := net.ParseCIDR(n.config["ipv6.address"])
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing ipv6.address: %w", err)
+		}
+
+		expiry := "1h"
+		if n.config["ipv6.dhcp.expiry"] != "" {
+			expiry = n.config["ipv6.dhcp.expiry"]
+		}
+
+		leaseTime, err := dhcpd.ParseExpiry(expiry)
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing ipv6.dhcp.expiry: %w", err)
+		}
+
+		ranges := n.DHCPv6Ranges()
+		if len(ranges) == 0 {
+			ranges = []shared.IPRange{{Start: dhcpalloc.GetIP(subnet, 2), End: dhcpalloc.GetIP(subnet, -1)}}
+		}
+
+		config.IPv6 = &dhcpd.IPv6Config{
+			Address:   address,
+			Subnet:    subnet,
+			DHCP:      n.DHCPv6Subnet() != nil,
+			Stateful:  shared.IsTrue(n.config["ipv6.dhcp.stateful"]),
+			Ranges:    ranges,
+			LeaseTime: leaseTime,
+		}
+	}
+
+	return config, nil
+}
+
+// nativeDHCPStart starts the native DHCP server of the network (replacing any running one).
+func (n *bridge) nativeDHCPStart(mtu uint32) error {
+	n.nativeDHCPStop()
+
+	config, err := n.nativeDHCPConfig(mtu)
+	if err != nil {
+		return err
+	}
+
+	backend := &bridgeDHCPBackend{n: n}
+
+	// Load the hosts and leases before the server starts answering requests.
+	hosts, err := backend.loadHosts()
+	if err != nil {
+		return fmt.Errorf("Failed loading the native DHCP server hosts: %w", err)
+	}
+
+	leases, err := backend.loadLeases()
+	if err != nil {
+		return fmt.Errorf("Failed loading the native DHCP server leases: %w", err)
+	}
+
+	dhcpCacheMu.Lock()
+	dhcpHosts[n.id] = hosts
+	dhcpLeases[n.id] = leases
+	dhcpNetworks[n.id] = n
+	dhcpCacheMu.Unlock()
+
+	server := dhcpd.NewServer(*config, backend)
+	err = server.Start()
+	if err != nil {
+		return fmt.Errorf("Failed starting the native DHCP server: %w", err)
+	}
+
+	dhcpServersMu.Lock()
+	dhcpServers[n.id] = server
+	dhcpServersMu.Unlock()
+
+	return nil
+}
+
+// nativeDHCPStop stops the native DHCP server of the network (if running).
+func (n *bridge) nativeDHCPStop() {
+	dhcpServersMu.Lock()
+	server := dhcpServers[n.id]
+	delete(dhcpServers, n.id)
+	dhcpServersMu.Unlock()
+
+	if server != nil {
+		server.Stop()
+	}
+
+	dhcpCacheMu.Lock()
+	delete(dhcpHosts, n.id)
+	delete(dhcpLeases, n.id)
+	delete(dhcpNetworks, n.id)
+	dhcpCacheMu.Unlock()
+}
+
+// RefreshDHCPHosts reloads the hosts of the native DHCP server of the network (if running).
+func (n *bridge) RefreshDHCPHosts() error {
+	if !n.UsesNativeDHCP() {
+		return nil
+	}
+
+	// Serialize the reloads so that an older set of hosts never replaces a newer one.
+	unlock, err := locking.Lock(context.TODO(), fmt.Sprintf("network.bridge.%d.dhcp-hosts", n.id))
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	// Any change made from now on needs another reload.
+	dhcpCacheMu.Lock()
+	delete(dhcpHostsReloadPending, n.id)
+	dhcpCacheMu.Unlock()
+
+	hosts, err := (&bridgeDHCPBackend{n: n}).loadHosts()
+	if err != nil {
+		return fmt.Errorf("Failed loading the native DHCP server hosts: %w", err)
+	}
+
+	dhcpCacheMu.Lock()
+	defer dhcpCacheMu.Unlock()
+
+	// Only replace the hosts of a running server.
+	_, found := dhcpHosts[n.id]
+	if found {
+		dhcpHosts[n.id] = hosts
+	}
+
+	return nil
+}
+
+// NativeDHCPHandleEvent reloads the hosts of the running native DHCP servers in the background when an instance
+// or a profile is created, changed or removed. The instance devices are stored in the database only after they
+// have been set up, so the lifecycle events are used rather than the device hooks.
+func NativeDHCPHandleEvent(event api.Event) {
+	if event.Type != api.EventTypeLifecycle {
+		return
+	}
+
+	lifecycleEvent := api.EventLifecycle{}
+	err := json.Unmarshal(event.Metadata, &lifecycleEvent)
+	if err != nil {
+		return
+	}
+
+	actions := []string{
+		api.EventLifecycleInstanceCreated,
+		api.EventLifecycleInstanceDeleted,
+		api.EventLifecycleInstanceRenamed,
+		api.EventLifecycleInstanceRestored,
+		api.EventLifecycleInstanceUpdated,
+		api.EventLifecycleProfileUpdated,
+	}
+
+	if !shared.ValueInSlice(lifecycleEvent.Action, actions) {
+		return
+	}
+
+	dhcpCacheMu.Lock()
+	defer dhcpCacheMu.Unlock()
+
+	for networkID, n := range dhcpNetworks {
+		// Skip the networks that already have a reload waiting to start.
+		if dhcpHostsReloadPending[networkID] {
+			continue
+		}
+
+		dhcpHostsReloadPending[networkID] = true
+
+		go func(n *bridge) {
+			err := n.RefreshDHCPHosts()
+			if err != nil {
+				n.logger.Warn("Failed reloading the native DHCP server hosts", logger.Ctx{"err": err})
+			}
+		}(n)
+	}
+}
+
+// nativeDHCPLeases returns the leases handed out by the native DHCP servers of the network on all cluster members
+// that aren't already part of the given leases. When projectMacs isn't nil, the leases of MAC addresses that don't
+// belong to the project are skipped.
+func (n *bridge) nativeDHCPLeases(leases []api.NetworkLease, projectMacs []string) ([]api.NetworkLease, error) {
+	var dbLeases []db.NetworkLease
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		dbLeases, err = tx.GetNetworkLeases(ctx, n.id, false)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dbLease := range dbLeases {
+		// Skip the leases of the static addresses that have already been added.
+		found := false
+		for _, entry := range leases {
+			if entry.Hwaddr == dbLease.Hwaddr && entry.Address == dbLease.Address {
+				found = true
+				break
+			}
+		}
+
+		if found {
+			continue
+		}
+
+		if projectMacs != nil && dbLease.Hwaddr != "" && !shared.ValueInSlice(dbLease.Hwaddr, projectMacs) {
+			continue
+		}
+
+		leases = append(leases, api.NetworkLease{
+			Hostname: dbLease.Hostname,
+			Address:  dbLease.Address,
+			Hwaddr:   dbLease.Hwaddr,
+			Type:     "dynamic",
+			Location: dbLease.Location,
+		})
+	}
+
+	return leases, nil
+}
+
+// LeaseAddresses returns the addresses leased to a MAC address by the native DHCP server on this member.
+func (n *bridge) LeaseAddresses(hwaddr string) ([]net.IP, error) {
+	leases, err := (&bridgeDHCPBackend{n: n}).Leases()
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := []net.IP{}
+	for _, lease := range leases {
+		if lease.Hwaddr.String() == hwaddr && !lease.Expired() {
+			addresses = append(addresses, lease.Address)
+		}
+	}
+
+	return addresses, nil
+}
+
+// ClearLeases removes the IPv4 and/or IPv6 leases of a MAC address handed out by the native DHCP server on this
+// member, so that the client gets a new address on its next request.
+func (n *bridge) ClearLeases(hwaddr string, ipv4 bool, ipv6 bool) error {
+	backend := &bridgeDHCPBackend{n: n}
+
+	leases, err := backend.Leases()
+	if err != nil {
+		return err
+	}
+
+	for _, lease := range leases {
+		if lease.Hwaddr.String() != hwaddr {
+			continue
+		}
+
+		isIPv4 := lease.Address.To4() != nil
+		if (isIPv4 && !ipv4) || (!isIPv4 && !ipv6) {
+			continue
+		}
+
+		err := backend.DeleteLease(lease)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// bridgeDHCPBackend provides the hosts of the instances connected to a bridge network to its native DHCP server
+// and stores the leases in the database.
+type bridgeDHCPBackend struct {
+	n *bridge
+}
+
+// Hosts returns the NICs of the instances connected to the network keyed by MAC address. The returned map must not
+// be modified.
+func (b *bridgeDHCPBackend) Hosts() (map[string]dhcpd.Host, error) {
+	dhcpCacheMu.Lock()
+	hosts, found := dhcpHosts[b.n.id]
+	dhcpCacheMu.Unlock()
+
+	if found {
+		return hosts, nil
+	}
+
+	return b.loadHosts()
+}
+
+// loadHosts loads the NICs of the instances connected to the network from the database.
+func (b *bridgeDHCPBackend) loadHosts() (map[string]dhcpd.Host, error) {
+	hosts := map[string]dhcpd.Host{}
+
+	err := UsedByInstanceDevices(b.n.state, b.n.Project(), b.n.Name(), b.n.Type(), func(inst db.InstanceArgs, nicName string, nicConfig map[string]string) error {
+		// Fill in the hwaddr from volatile.
+		if nicConfig["hwaddr"] == "" {
+			nicConfig["hwaddr"] = inst.Config[fmt.Sprintf("volatile.%s.hwaddr", nicName)]
+		}
+
+		// Fill in the addresses allocated from the network's IPAM.
+		for _, key := range []string{"ipv4.address", "ipv6.address"} {
+			if nicConfig[key] == "" {
+				nicConfig[key] = inst.Config[fmt.Sprintf("volatile.%s.%s", nicName, key)]
+			}
+		}
+
+		hwAddr, err := net.ParseMAC(nicConfig["hwaddr"])
+		if err != nil {
+			return nil
+		}
+
+		options, err := dhcpd.ParseOptions(nicConfig["ipv4.dhcp.options"])
+		if err != nil {
+			b.n.logger.Warn("Ignoring invalid DHCP options", logger.Ctx{"instance": inst.Name, "project": inst.Project, "device": nicName, "err": err})
+			options = nil
+		}
+
+		hosts[hwAddr.String()] = dhcpd.Host{
+			Hostname: inst.Name,
+			IPv4:     net.ParseIP(nicConfig["ipv4.address"]).To4(),
+			IPv6:     net.ParseIP(nicConfig["ipv6.address"]),
+			Options:  options,
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return hosts, nil
+}
+
+// Leases returns the leases handed out by the server on this member.
+func (b *bridgeDHCPBackend) Leases() ([]dhcpd.Lease, error) {
+	dhcpCacheMu.Lock()
+	leases, found := dhcpLeases[b.n.id]
+	if found {
+		leases = append([]dhcpd.Lease(nil), leases...)
+	}
+
+	dhcpCacheMu.Unlock()
+
+	if found {
+		return leases, nil
+	}
+
+	return b.loadLeases()
+}
+
+// loadLeases loads the leases handed out by the server on this member from the database.
+func (b *bridgeDHCPBackend) loadLeases() ([]dhcpd.Lease, error) {
+	var dbLeases []db.NetworkLease
+
+	err := b.n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		dbLeases, err = tx.GetNetworkLeases(ctx, b.n.id, true)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	leases := make([]dhcpd.Lease, 0, len(dbLeases))
+	for _, dbLease := range dbLeases {
+		hwAddr, _ := net.ParseMAC(dbLease.Hwaddr)
+
+		leases = append(leases, dhcpd.Lease{
+			Hwaddr:   hwAddr,
+			ClientID: dbLease.ClientID,
+			Address:  net.ParseIP(dbLease.Address),
+			Hostname: dbLease.Hostname,
+			Expiry:   dbLease.Expiry,
+		})
+	}
+
+	return leases, nil
+}
+
+// CreateLease stores a new lease and sends a lifecycle event.
+func (b *bridgeDHCPBackend) CreateLease(lease dhcpd.Lease) error {
+	err := b.UpdateLease(lease)
+	if err != nil {
+		return err
+	}
+
+	b.n.state.Events.SendLifecycle(b.n.project, lifecycle.NetworkLeaseCreated.Event(b.n, nil, b.leaseContext(lease)))
+
+	return nil
+}
+
+// UpdateLease stores the renewal of a lease.
+func (b *bridgeDHCPBackend) UpdateLease(lease dhcpd.Lease) error {
+	dbLease := db.NetworkLease{
+		Hwaddr:   lease.Hwaddr.String(),
+		ClientID: lease.ClientID,
+		Address:  lease.Address.String(),
+		Hostname: lease.Hostname,
+		Expiry:   lease.Expiry,
+	}
+
+	err := b.n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.UpsertNetworkLease(ctx, b.n.id, dbLease)
+	})
+	if err != nil {
+		return err
+	}
+
+	b.cacheLease(lease, false)
+
+	return nil
+}
+
+// DeleteLease removes a lease and sends a lifecycle event.
+func (b *bridgeDHCPBackend) DeleteLease(lease dhcpd.Lease) error {
+	err := b.n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.DeleteNetworkLease(ctx, b.n.id, lease.Address.String())
+	})
+	if err != nil {
+		return err
+	}
+
+	b.cacheLease(lease, true)
+
+	b.n.state.Events.SendLifecycle(b.n.project, lifecycle.NetworkLeaseDeleted.Event(b.n, nil, b.leaseContext(lease)))
+
+	return nil
+}
+
+// cacheLease replaces (or removes) the lease of the same address in the cached leases of the running server.
+func (b *bridgeDHCPBackend) cacheLease(lease dhcpd.Lease, remove bool) {
+	dhcpCacheMu.Lock()
+	defer dhcpCacheMu.Unlock()
+
+	leases, found := dhcpLeases[b.n.id]
+	if !found {
+		return
+	}
+
+	newLeases := make([]dhcpd.Lease, 0, len(leases)+1)
+	for _, l := range leases {
+		if !l.Address.Equal(lease.Address) {
+			newLeases = append(newLeases, l)
+		}
+	}
+
+	if !remove {
+		newLeases = append(newLeases, lease)
+	}
+
+	dhcpLeases[b.n.id] = newLeases
+}
+
+// leaseContext returns the context of the lifecycle events of a lease.
+func (b *bridgeDHCPBackend) leaseContext(lease dhcpd.Lease) map[string]any {
+	return map[string]any{
+		"address":  lease.Address.String(),
+		"hwaddr":   lease.Hwaddr.String(),
+		"hostname": lease.Hostname,
+	}
+}
@@ -39,6 +39,7 @@ type Network interface {
 	Locations() []string
 	IsUsed() (bool, error)
 	IsManaged() bool
+	AddressCIDR(ipVersion uint) string
 	DHCPv4Subnet() *net.IPNet
 	DHCPv6Subnet() *net.IPNet
 	DHCPv4Ranges() []shared.IPRange
@@ -57,6 +58,7 @@ type Network interface {
 	// Status.
 	State() (*api.NetworkState, error)
 	Leases(projectName string, clientType request.ClientType) ([]api.NetworkLease, error)
+	BGPState() (*api.NetworkBGPState, error)
 
 	// Address Forwards.
 	ForwardCreate(forward api.NetworkForwardsPost, clientType request.ClientType) (net.IP, error)
@@ -69,8 +71,16 @@ type Network interface {
 	LoadBalancerDelete(listenAddress string, clientType request.ClientType) error
 
 	// Peerings.
-	PeerCreate(forward api.NetworkPeersPost) error
+	PeerCreate(peer api.NetworkPeersPost, clientType request.ClientType) error
 	PeerUpdate(peerName string, newPeer api.NetworkPeerPut) error
-	PeerDelete(peerName string) error
+	PeerDelete(peerName string, clientType request.ClientType) error
 	PeerUsedBy(peerName string) ([]string, error)
+
+	// IP address management.
+	IPRangeCreate(ipRange api.NetworkIPRangesPost) error
+	IPRangeUpdate(name string, newRange api.NetworkIPRangePut) error
+	IPRangeDelete(name string) error
+	IPAllocations(projectName string) ([]api.NetworkIPAllocation, error)
+	IPAllocate(projectName string, allocation api.NetworkIPAllocationsPost) (net.IP, error)
+	IPRelease(projectName string, address string) error
 }
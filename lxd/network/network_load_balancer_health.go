@@ -0,0 +1,254 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// loadBalancerHealthCheck defines how the backends of a load balancer are checked.
+type loadBalancerHealthCheck struct {
+	interval     time.Duration
+	timeout      time.Duration
+	failureCount int
+	successCount int
+
+	// targets maps the name of each checked backend to the TCP address that is connected to.
+	targets map[string]string
+}
+
+// loadBalancerMonitor periodically checks the backends of a load balancer.
+type loadBalancerMonitor struct {
+	check  loadBalancerHealthCheck
+	cancel context.CancelFunc
+
+	mu        sync.Mutex
+	unhealthy map[string]bool
+}
+
+// loadBalancerMonitors holds the running load balancer monitors keyed by network ID and listen address.
+var loadBalancerMonitors = make(map[string]*loadBalancerMonitor)
+var loadBalancerMonitorsMu sync.Mutex
+
+// loadBalancerMonitorKey returns the key of a load balancer in loadBalancerMonitors.
+func loadBalancerMonitorKey(networkID int64, listenAddress string) string {
+	return fmt.Sprintf("%d/%s", networkID, listenAddress)
+}
+
+// loadBalancerMonitorsSync makes sure that exactly the given health checks (keyed by listen address) are running
+// for the network. Monitors whose health check changed are restarted. The onChange function is called whenever a
+// backend becomes healthy or unhealthy.
+func loadBalancerMonitorsSync(networkID int64, checks map[string]loadBalancerHealthCheck, onChange func()) {
+	loadBalancerMonitorsMu.Lock()
+	defer loadBalancerMonitorsMu.Unlock()
+
+	prefix := loadBalancerMonitorKey(networkID, "")
+
+	// Stop the monitors that are no longer wanted or whose health check changed.
+	for key, monitor := range loadBalancerMonitors {
+		if len(key) < len(prefix) || key[:len(prefix)] != prefix {
+			continue
+		}
+
+		check, found := checks[key[len(prefix):]]
+		if found && reflect.DeepEqual(check, monitor.check) {
+			continue
+		}
+
+		monitor.cancel()
+		delete(loadBalancerMonitors, key)
+	}
+
+	// Start the missing monitors.
+	for listenAddress, check := range checks {
+		key := loadBalancerMonitorKey(networkID, listenAddress)
+		_, found := loadBalancerMonitors[key]
+		if found {
+			continue
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		monitor := &loadBalancerMonitor{
+			check:     check,
+			cancel:    cancel,
+			unhealthy: make(map[string]bool),
+		}
+
+		loadBalancerMonitors[key] = monitor
+
+		l := logger.AddContext(logger.Ctx{"networkID": networkID, "listenAddress": listenAddress})
+		go monitor.run(ctx, l, onChange)
+	}
+}
+
+// loadBalancerMonitorsStop stops all the load balancer monitors of the network.
+func loadBalancerMonitorsStop(networkID int64) {
+	loadBalancerMonitorsSync(networkID, nil, nil)
+}
+
+// loadBalancerUnhealthyBackends returns the names of the backends of the load balancer that are currently
+// considered unhealthy.
+func loadBalancerUnhealthyBackends(networkID int64, listenAddress string) map[string]bool {
+	loadBalancerMonitorsMu.Lock()
+	monitor := loadBalancerMonitors[loadBalancerMonitorKey(networkID, listenAddress)]
+	loadBalancerMonitorsMu.Unlock()
+
+	unhealthy := make(map[string]bool)
+	if monitor == nil {
+		return unhealthy
+	}
+
+	monitor.mu.Lock()
+	defer monitor.mu.Unlock()
+
+	for name := range monitor.unhealthy {
+		unhealthy[name] = true
+	}
+
+	return unhealthy
+}
+
+// run checks the backends every interval until the context is cancelled. A backend is considered unhealthy
+// after failureCount consecutive failed checks and healthy again after successCount consecutive successful ones.
+func (m *loadBalancerMonitor) run(ctx context.Context, l logger.Logger, onChange func()) {
+	failures := make(map[string]int, len(m.check.targets))
+	successes := make(map[string]int, len(m.check.targets))
+
+	ticker := time.NewTicker(m.check.interval)
+	defer ticker.Stop()
+
+	for {
+		changed := false
+
+		for name, target := range m.check.targets {
+			dialer := net.Dialer{Timeout: m.check.timeout}
+			conn, err := dialer.DialContext(ctx, "tcp", target)
+			if ctx.Err() != nil {
+				return
+			}
+
+			if err == nil {
+				_ = conn.Close()
+				successes[name]++
+				failures[name] = 0
+			} else {
+				failures[name]++
+				successes[name] = 0
+			}
+
+			m.mu.Lock()
+			if m.unhealthy[name] && successes[name] >= m.check.successCount {
+				delete(m.unhealthy, name)
+				changed = true
+				l.Info("Load balancer backend is healthy again", logger.Ctx{"backend": name, "target": target})
+			} else if !m.unhealthy[name] && failures[name] >= m.check.failureCount {
+				m.unhealthy[name] = true
+				changed = true
+				l.Warn("Load balancer backend failed its health check", logger.Ctx{"backend": name, "target": target, "err": err})
+			}
+
+			m.mu.Unlock()
+		}
+
+		if changed && ctx.Err() == nil {
+			onChange()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// loadBalancerHealthCheckFromConfig returns the health check defined in the load balancer config, or nil if health
+// checks aren't enabled for the load balancer.
+func loadBalancerHealthCheckFromConfig(loadBalancer *api.NetworkLoadBalancer) *loadBalancerHealthCheck {
+	if shared.IsFalseOrEmpty(loadBalancer.Config["healthcheck"]) {
+		return nil
+	}
+
+	configInt := func(key string, defaultValue int) int {
+		value, err := strconv.Atoi(loadBalancer.Config[key])
+		if err != nil || value <= 0 {
+			return defaultValue
+		}
+
+		return value
+	}
+
+	return &loadBalancerHealthCheck{
+		interval:     time.Duration(configInt("healthcheck.interval", 10)) * time.Second,
+		timeout:      time.Duration(configInt("healthcheck.timeout", 5)) * time.Second,
+		failureCount: configInt("healthcheck.failure_count", 3),
+		successCount: configInt("healthcheck.success_count", 2),
+		targets:      loadBalancerHealthCheckTargets(loadBalancer),
+	}
+}
+
+// loadBalancerHealthCheckTargets returns the TCP address checked for each backend of the load balancer. Backends
+// are checked on their first target port, or else on the first listen port of the first TCP port specification
+// using them. Backends only used for UDP ports aren't checked.
+func loadBalancerHealthCheckTargets(loadBalancer *api.NetworkLoadBalancer) map[string]string {
+	backends := make(map[string]api.NetworkLoadBalancerBackend, len(loadBalancer.Backends))
+	for _, backend := range loadBalancer.Backends {
+		backends[backend.Name] = backend
+	}
+
+	// firstPort returns the first port of a comma separated list of ports and port ranges, or 0 if there is none.
+	firstPort := func(ports string) int64 {
+		portRanges := shared.SplitNTrimSpace(ports, ",", -1, true)
+		if len(portRanges) == 0 {
+			return 0
+		}
+
+		port, _, err := ParsePortRange(portRanges[0])
+		if err != nil {
+			return 0
+		}
+
+		return port
+	}
+
+	targets := make(map[string]string)
+
+	for _, portSpec := range loadBalancer.Ports {
+		if portSpec.Protocol != "tcp" {
+			continue
+		}
+
+		for _, backendName := range portSpec.TargetBackend {
+			_, found := targets[backendName]
+			if found {
+				continue
+			}
+
+			backend, found := backends[backendName]
+			if !found {
+				continue
+			}
+
+			port := firstPort(backend.TargetPort)
+			if port <= 0 {
+				port = firstPort(portSpec.ListenPort)
+			}
+
+			if port <= 0 {
+				continue
+			}
+
+			targets[backendName] = net.JoinHostPort(backend.TargetAddress, strconv.FormatInt(port, 10))
+		}
+	}
+
+	return targets
+}
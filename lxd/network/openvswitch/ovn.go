@@ -2125,6 +2125,57 @@ func (o *OVN) AddressSetAdd(addressSetPrefix OVNAddressSet, addresses ...net.IPN
 	return nil
 }
 
+// AddressSetSet replaces the addresses of the address sets with the supplied addresses, or creates new address
+// sets if needed. Both the IPv4 and IPv6 address sets are always set, even if there are no addresses for them.
+// The address set name used is "<addressSetPrefix>_ip<IP version>", e.g. "foo_ip4".
+func (o *OVN) AddressSetSet(addressSetPrefix OVNAddressSet, addresses ...net.IPNet) error {
+	ipVersionAddresses := map[uint][]string{4: {}, 6: {}}
+
+	for _, address := range addresses {
+		var ipVersion uint = 4
+		if address.IP.To4() == nil {
+			ipVersion = 6
+		}
+
+		ipVersionAddresses[ipVersion] = append(ipVersionAddresses[ipVersion], fmt.Sprintf(`"%s"`, address.String()))
+	}
+
+	var args []string
+
+	for _, ipVersion := range []uint{4, 6} {
+		if len(args) > 0 {
+			args = append(args, "--")
+		}
+
+		addressSetName := fmt.Sprintf("%s_ip%d", addressSetPrefix, ipVersion)
+
+		if len(ipVersionAddresses[ipVersion]) > 0 {
+			args = append(args, "set", "address_set", addressSetName, fmt.Sprintf("addresses=%s", strings.Join(ipVersionAddresses[ipVersion], ",")))
+		} else {
+			args = append(args, "clear", "address_set", addressSetName, "addresses")
+		}
+	}
+
+	// Optimistically assume the address sets exist.
+	_, err := o.nbctl(args...)
+	if err != nil {
+		// Try creating the address sets one at a time, but ignore errors here in case some of the
+		// address sets already exist. If there was a problem creating the address set it will be
+		// revealead when we run the original command again next.
+		for _, ipVersion := range []uint{4, 6} {
+			_, _ = o.nbctl("create", "address_set", fmt.Sprintf("name=%s_ip%d", addressSetPrefix, ipVersion))
+		}
+
+		// Try original command again.
+		_, err := o.nbctl(args...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // AddressSetRemove removes the supplied addresses from the address set.
 // The address set name used is "<addressSetPrefix>_ip<IP version>", e.g. "foo_ip4".
 func (o *OVN) AddressSetRemove(addressSetPrefix OVNAddressSet, addresses ...net.IPNet) error {
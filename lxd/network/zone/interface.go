@@ -20,7 +20,6 @@ type NetworkZone interface {
 	Etag() []any
 	UsedBy() ([]string, error)
 	Content() (*strings.Builder, error)
-	SOA() (*strings.Builder, error)
 
 	// Records.
 	AddRecord(req api.NetworkZoneRecordsPost) error
@@ -11,6 +11,7 @@ import (
 	"github.com/canonical/lxd/lxd/state"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
 )
 
 // LoadByName loads and initialises a Network zone from the database by name.
@@ -122,6 +123,12 @@ func Create(s *state.State, projectName string, zoneInfo *api.NetworkZonesPost)
 		return err
 	}
 
+	// Load the new zone in the built-in DNS server so that it's served right away.
+	err = s.DNS.RefreshZone(zoneInfo.Name)
+	if err != nil {
+		logger.Warn("Failed refreshing DNS zone", logger.Ctx{"zone": zoneInfo.Name, "err": err})
+	}
+
 	return nil
 }
 
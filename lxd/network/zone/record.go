@@ -64,6 +64,8 @@ func (d *zone) AddRecord(req api.NetworkZoneRecordsPost) error {
 		return err
 	}
 
+	d.refresh()
+
 	return nil
 }
 
@@ -157,6 +159,8 @@ func (d *zone) UpdateRecord(name string, req api.NetworkZoneRecordPut, clientTyp
 		return err
 	}
 
+	d.refresh()
+
 	return nil
 }
 
@@ -183,6 +187,8 @@ func (d *zone) DeleteRecord(name string) error {
 		return err
 	}
 
+	d.refresh()
+
 	return nil
 }
 
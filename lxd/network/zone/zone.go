@@ -171,6 +171,14 @@ func (d *zone) validateConfig(info *api.NetworkZonePut) error {
 	//  required: no
 	//  shortdesc: Comma-separated list of DNS server FQDNs (for NS records)
 	rules["dns.nameservers"] = validate.IsListOf(validate.IsAny)
+	// lxdmeta:generate(entities=network-zone; group=config-options; key=dns.query.allowed_subnets)
+	// By default, only the zone's peers can query the built-in DNS server.
+	// Use `0.0.0.0/0,::/0` to answer queries from any client.
+	// ---
+	//  type: string
+	//  required: no
+	//  shortdesc: Comma-separated list of subnets allowed to query the zone's records
+	rules["dns.query.allowed_subnets"] = validate.Optional(validate.IsListOf(validate.IsNetwork))
 	// lxdmeta:generate(entities=network-zone; group=config-options; key=network.nat)
 	//
 	// ---
@@ -311,10 +319,20 @@ func (d *zone) Update(config *api.NetworkZonePut, clientType request.ClientType)
 		return err
 	}
 
+	d.refresh()
+
 	revert.Success()
 	return nil
 }
 
+// refresh reloads the zone in the built-in DNS server so that its peers get notified of any change.
+func (d *zone) refresh() {
+	err := d.state.DNS.RefreshZone(d.info.Name)
+	if err != nil {
+		d.logger.Warn("Failed refreshing DNS zone", logger.Ctx{"err": err})
+	}
+}
+
 // Delete deletes the zone.
 func (d *zone) Delete() error {
 	isUsed, err := d.isUsed()
@@ -547,37 +565,3 @@ func (d *zone) Content() (*strings.Builder, error) {
 
 	return sb, nil
 }
-
-// SOA returns just the DNS zone SOA record.
-func (d *zone) SOA() (*strings.Builder, error) {
-	// Get the nameservers.
-	nameservers := []string{}
-	for _, entry := range strings.Split(d.info.Config["dns.nameservers"], ",") {
-		entry = strings.TrimSpace(entry)
-		if entry == "" {
-			continue
-		}
-
-		nameservers = append(nameservers, entry)
-	}
-
-	primary := "hostmaster." + d.info.Name
-	if len(nameservers) > 0 {
-		primary = nameservers[0]
-	}
-
-	// Template the zone file.
-	sb := &strings.Builder{}
-	err := zoneTemplate.Execute(sb, map[string]any{
-		"primary":     primary,
-		"nameservers": nameservers,
-		"zone":        d.info.Name,
-		"serial":      time.Now().Unix(),
-		"records":     map[string]string{},
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	return sb, nil
-}
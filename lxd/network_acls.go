@@ -49,6 +49,12 @@ var networkACLLogCmd = APIEndpoint{
 	Get: APIEndpointAction{Handler: networkACLLogGet, AccessHandler: allowPermission(entity.TypeNetworkACL, auth.EntitlementCanView, "name")},
 }
 
+var networkACLStateCmd = APIEndpoint{
+	Path: "network-acls/{name}/state",
+
+	Get: APIEndpointAction{Handler: networkACLStateGet, AccessHandler: allowPermission(entity.TypeNetworkACL, auth.EntitlementCanView, "name")},
+}
+
 // API endpoints.
 
 // swagger:operation GET /1.0/network-acls network-acls network_acls_get
@@ -646,3 +652,73 @@ func networkACLLogGet(d *Daemon, r *http.Request) response.Response {
 
 	return response.FileResponse([]response.FileResponseEntry{ent}, nil)
 }
+
+// swagger:operation GET /1.0/network-acls/{name}/state network-acls network_acl_state_get
+//
+//	Get the network ACL state
+//
+//	Gets the packet and byte counters of the rules of a specific network ACL.
+//	The counters are summed over the bridge networks using the ACL on all cluster members.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Network ACL state
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/NetworkACLState"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkACLStateGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName, _, err := project.NetworkProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	aclName, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	netACL, err := acl.LoadByName(s, projectName, aclName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	clientType := clusterRequest.UserAgentClientType(r.Header.Get("User-Agent"))
+	aclState, err := netACL.GetState(clientType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, aclState)
+}
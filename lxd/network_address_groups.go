@@ -0,0 +1,587 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/lxd/auth"
+	clusterRequest "github.com/canonical/lxd/lxd/cluster/request"
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/network/acl"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// Network address groups are referenced by network ACL rules, so they use the network ACL permissions of the
+// project they belong to.
+var networkAddressGroupsCmd = APIEndpoint{
+	Path: "network-address-groups",
+
+	Get:  APIEndpointAction{Handler: networkAddressGroupsGet, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanViewNetworkACLs)},
+	Post: APIEndpointAction{Handler: networkAddressGroupsPost, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanCreateNetworkACLs)},
+}
+
+var networkAddressGroupCmd = APIEndpoint{
+	Path: "network-address-groups/{name}",
+
+	Delete: APIEndpointAction{Handler: networkAddressGroupDelete, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanDeleteNetworkACLs)},
+	Get:    APIEndpointAction{Handler: networkAddressGroupGet, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanViewNetworkACLs)},
+	Put:    APIEndpointAction{Handler: networkAddressGroupPut, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanEditNetworkACLs)},
+	Patch:  APIEndpointAction{Handler: networkAddressGroupPut, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanEditNetworkACLs)},
+	Post:   APIEndpointAction{Handler: networkAddressGroupPost, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanEditNetworkACLs)},
+}
+
+// API endpoints.
+
+// swagger:operation GET /1.0/network-address-groups network-address-groups network_address_groups_get
+//
+//	Get the network address groups
+//
+//	Returns a list of network address groups (URLs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of endpoints
+//	          items:
+//	            type: string
+//	          example: |-
+//	            [
+//	              "/1.0/network-address-groups/web",
+//	              "/1.0/network-address-groups/dns"
+//	            ]
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/network-address-groups?recursion=1 network-address-groups network_address_groups_get_recursion1
+//
+//	Get the network address groups
+//
+//	Returns a list of network address groups (structs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of network address groups
+//	          items:
+//	            $ref: "#/definitions/NetworkAddressGroup"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkAddressGroupsGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName, _, err := project.NetworkProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	recursion := util.IsRecursionRequest(r)
+
+	var groupNames []string
+
+	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		// Get list of Network address groups.
+		groupNames, err = tx.GetNetworkAddressGroups(ctx, projectName)
+
+		return err
+	})
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	resultString := []string{}
+	resultMap := []api.NetworkAddressGroup{}
+	for _, groupName := range groupNames {
+		if !recursion {
+			resultString = append(resultString, api.NewURL().Path(version.APIVersion, "network-address-groups", groupName).String())
+		} else {
+			group, err := acl.LoadAddressGroupByName(s, projectName, groupName)
+			if err != nil {
+				continue
+			}
+
+			groupInfo := group.Info()
+			groupInfo.UsedBy, _ = group.UsedBy() // Ignore errors in UsedBy, will return nil.
+			groupInfo.UsedBy = project.FilterUsedBy(s.Authorizer, r, groupInfo.UsedBy)
+
+			resultMap = append(resultMap, *groupInfo)
+		}
+	}
+
+	if !recursion {
+		return response.SyncResponse(true, resultString)
+	}
+
+	return response.SyncResponse(true, resultMap)
+}
+
+// swagger:operation POST /1.0/network-address-groups network-address-groups network_address_groups_post
+//
+//	Add a network address group
+//
+//	Creates a new network address group.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: group
+//	    description: Address group
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/NetworkAddressGroupsPost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkAddressGroupsPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName, _, err := project.NetworkProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.NetworkAddressGroupsPost{}
+
+	// Parse the request into a record.
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	_, err = acl.LoadAddressGroupByName(s, projectName, req.Name)
+	if err == nil {
+		return response.BadRequest(fmt.Errorf("The network address group already exists"))
+	}
+
+	err = acl.CreateAddressGroup(s, projectName, &req)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	group, err := acl.LoadAddressGroupByName(s, projectName, req.Name)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	lc := lifecycle.NetworkAddressGroupCreated.Event(group, request.CreateRequestor(r), nil)
+	s.Events.SendLifecycle(projectName, lc)
+
+	return response.SyncResponseLocation(true, nil, lc.Source)
+}
+
+// swagger:operation DELETE /1.0/network-address-groups/{name} network-address-groups network_address_group_delete
+//
+//	Delete the network address group
+//
+//	Removes the network address group. The address group must not be referenced by any network ACL.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkAddressGroupDelete(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName, _, err := project.NetworkProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	groupName, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	group, err := acl.LoadAddressGroupByName(s, projectName, groupName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = group.Delete()
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	s.Events.SendLifecycle(projectName, lifecycle.NetworkAddressGroupDeleted.Event(group, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation GET /1.0/network-address-groups/{name} network-address-groups network_address_group_get
+//
+//	Get the network address group
+//
+//	Gets a specific network address group.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Address group
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/NetworkAddressGroup"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkAddressGroupGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName, _, err := project.NetworkProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	groupName, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	group, err := acl.LoadAddressGroupByName(s, projectName, groupName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	info := group.Info()
+	info.UsedBy, err = group.UsedBy()
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	info.UsedBy = project.FilterUsedBy(s.Authorizer, r, info.UsedBy)
+	return response.SyncResponseETag(true, info, group.Etag())
+}
+
+// swagger:operation PATCH /1.0/network-address-groups/{name} network-address-groups network_address_group_patch
+//
+//	Partially update the network address group
+//
+//	Updates a subset of the network address group configuration.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: group
+//	    description: Address group configuration
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/NetworkAddressGroupPut"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation PUT /1.0/network-address-groups/{name} network-address-groups network_address_group_put
+//
+//	Update the network address group
+//
+//	Updates the entire network address group configuration.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: group
+//	    description: Address group configuration
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/NetworkAddressGroupPut"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkAddressGroupPut(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName, _, err := project.NetworkProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	groupName, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Get the existing Network address group.
+	group, err := acl.LoadAddressGroupByName(s, projectName, groupName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Validate the ETag.
+	err = util.EtagCheck(r, group.Etag())
+	if err != nil {
+		return response.PreconditionFailed(err)
+	}
+
+	req := api.NetworkAddressGroupPut{}
+
+	// Decode the request.
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if r.Method == http.MethodPatch {
+		info := group.Info()
+
+		// If config being updated via "patch" method, then merge all existing config with the keys that
+		// are present in the request config.
+		if req.Config == nil {
+			req.Config = make(map[string]string, len(info.Config))
+		}
+
+		for k, v := range info.Config {
+			_, ok := req.Config[k]
+			if !ok {
+				req.Config[k] = v
+			}
+		}
+
+		// Keep the existing addresses and ports if not present in the request.
+		if req.Addresses == nil {
+			req.Addresses = info.Addresses
+		}
+
+		if req.Ports == nil {
+			req.Ports = info.Ports
+		}
+	}
+
+	clientType := clusterRequest.UserAgentClientType(r.Header.Get("User-Agent"))
+
+	err = group.Update(&req, clientType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	s.Events.SendLifecycle(projectName, lifecycle.NetworkAddressGroupUpdated.Event(group, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation POST /1.0/network-address-groups/{name} network-address-groups network_address_group_post
+//
+//	Rename the network address group
+//
+//	Renames an existing network address group. The address group must not be referenced by any network ACL.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: group
+//	    description: Address group rename request
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/NetworkAddressGroupPost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkAddressGroupPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	groupName, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName, _, err := project.NetworkProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.NetworkAddressGroupPost{}
+
+	// Parse the request.
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// Get the existing Network address group.
+	group, err := acl.LoadAddressGroupByName(s, projectName, groupName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = group.Rename(req.Name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	lc := lifecycle.NetworkAddressGroupRenamed.Event(group, request.CreateRequestor(r), logger.Ctx{"old_name": groupName})
+	s.Events.SendLifecycle(projectName, lc)
+
+	return response.SyncResponseLocation(true, nil, lc.Source)
+}
@@ -0,0 +1,969 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/network"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/version"
+)
+
+var networkIPRangesCmd = APIEndpoint{
+	Path: "networks/{networkName}/ip-ranges",
+
+	Get:  APIEndpointAction{Handler: networkIPRangesGet, AccessHandler: networkAccessHandler(auth.EntitlementCanView)},
+	Post: APIEndpointAction{Handler: networkIPRangesPost, AccessHandler: networkAccessHandler(auth.EntitlementCanEdit)},
+}
+
+var networkIPRangeCmd = APIEndpoint{
+	Path: "networks/{networkName}/ip-ranges/{rangeName}",
+
+	Delete: APIEndpointAction{Handler: networkIPRangeDelete, AccessHandler: networkAccessHandler(auth.EntitlementCanEdit)},
+	Get:    APIEndpointAction{Handler: networkIPRangeGet, AccessHandler: networkAccessHandler(auth.EntitlementCanView)},
+	Put:    APIEndpointAction{Handler: networkIPRangePut, AccessHandler: networkAccessHandler(auth.EntitlementCanEdit)},
+	Patch:  APIEndpointAction{Handler: networkIPRangePut, AccessHandler: networkAccessHandler(auth.EntitlementCanEdit)},
+}
+
+var networkIPAllocationsCmd = APIEndpoint{
+	Path: "networks/{networkName}/ip-allocations",
+
+	Get:  APIEndpointAction{Handler: networkIPAllocationsGet, AccessHandler: networkAccessHandler(auth.EntitlementCanView)},
+	Post: APIEndpointAction{Handler: networkIPAllocationsPost, AccessHandler: networkAccessHandler(auth.EntitlementCanEdit)},
+}
+
+var networkIPAllocationCmd = APIEndpoint{
+	Path: "networks/{networkName}/ip-allocations/{address}",
+
+	Delete: APIEndpointAction{Handler: networkIPAllocationDelete, AccessHandler: networkAccessHandler(auth.EntitlementCanEdit)},
+	Get:    APIEndpointAction{Handler: networkIPAllocationGet, AccessHandler: networkAccessHandler(auth.EntitlementCanView)},
+}
+
+// API endpoints
+
+// swagger:operation GET /1.0/networks/{networkName}/ip-ranges network-ipam network_ip_ranges_get
+//
+//  Get the network IP ranges
+//
+//  Returns a list of network IP ranges (URLs).
+//
+//  ---
+//  produces:
+//    - application/json
+//  parameters:
+//    - in: query
+//      name: project
+//      description: Project name
+//      type: string
+//      example: default
+//  responses:
+//    "200":
+//      description: API endpoints
+//      schema:
+//        type: object
+//        description: Sync response
+//        properties:
+//          type:
+//            type: string
+//            description: Response type
+//            example: sync
+//          status:
+//            type: string
+//            description: Status description
+//            example: Success
+//          status_code:
+//            type: integer
+//            description: Status code
+//            example: 200
+//          metadata:
+//            type: array
+//            description: List of endpoints
+//            items:
+//              type: string
+//            example: |-
+//              [
+//                "/1.0/networks/lxdbr0/ip-ranges/reserved",
+//                "/1.0/networks/lxdbr0/ip-ranges/web"
+//              ]
+//    "403":
+//      $ref: "#/responses/Forbidden"
+//    "500":
+//      $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/networks/{networkName}/ip-ranges?recursion=1 network-ipam network_ip_ranges_get_recursion1
+//
+//	Get the network IP ranges
+//
+//	Returns a list of network IP ranges (structs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of network IP ranges
+//	          items:
+//	            $ref: "#/definitions/NetworkIPRange"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkIPRangesGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	effectiveProjectName, err := request.GetCtxValue[string](r.Context(), request.CtxEffectiveProjectName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	details, err := request.GetCtxValue[networkDetails](r.Context(), ctxNetworkDetails)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	n, err := network.LoadByName(s, effectiveProjectName, details.networkName)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading network: %w", err))
+	}
+
+	// Check if project allows access to network.
+	if !project.NetworkAllowed(details.requestProject.Config, details.networkName, n.IsManaged()) {
+		return response.SmartError(api.StatusErrorf(http.StatusNotFound, "Network not found"))
+	}
+
+	if !n.Info().IPAM {
+		return response.BadRequest(fmt.Errorf("Network driver %q does not support IP address management", n.Type()))
+	}
+
+	var ipRanges []*api.NetworkIPRange
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		ipRanges, err = tx.GetNetworkIPRanges(ctx, n.ID())
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading network IP ranges: %w", err))
+	}
+
+	if util.IsRecursionRequest(r) {
+		if ipRanges == nil {
+			ipRanges = []*api.NetworkIPRange{}
+		}
+
+		return response.SyncResponse(true, ipRanges)
+	}
+
+	rangeURLs := make([]string, 0, len(ipRanges))
+	for _, ipRange := range ipRanges {
+		rangeURLs = append(rangeURLs, fmt.Sprintf("/%s/networks/%s/ip-ranges/%s", version.APIVersion, url.PathEscape(n.Name()), url.PathEscape(ipRange.Name)))
+	}
+
+	return response.SyncResponse(true, rangeURLs)
+}
+
+// swagger:operation POST /1.0/networks/{networkName}/ip-ranges network-ipam network_ip_ranges_post
+//
+//	Add a network IP range
+//
+//	Creates a new network IP range.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: range
+//	    description: IP range
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/NetworkIPRangesPost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkIPRangesPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	effectiveProjectName, err := request.GetCtxValue[string](r.Context(), request.CtxEffectiveProjectName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	details, err := request.GetCtxValue[networkDetails](r.Context(), ctxNetworkDetails)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Parse the request into a record.
+	req := api.NetworkIPRangesPost{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	req.Normalise() // So we handle the request in normalised/canonical form.
+
+	n, err := network.LoadByName(s, effectiveProjectName, details.networkName)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading network: %w", err))
+	}
+
+	// Check if project allows access to network.
+	if !project.NetworkAllowed(details.requestProject.Config, details.networkName, n.IsManaged()) {
+		return response.SmartError(api.StatusErrorf(http.StatusNotFound, "Network not found"))
+	}
+
+	if !n.Info().IPAM {
+		return response.BadRequest(fmt.Errorf("Network driver %q does not support IP address management", n.Type()))
+	}
+
+	err = n.IPRangeCreate(req)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed creating IP range: %w", err))
+	}
+
+	lc := lifecycle.NetworkIPRangeCreated.Event(n, req.Name, request.CreateRequestor(r), nil)
+	s.Events.SendLifecycle(effectiveProjectName, lc)
+
+	return response.SyncResponseLocation(true, nil, lc.Source)
+}
+
+// swagger:operation DELETE /1.0/networks/{networkName}/ip-ranges/{rangeName} network-ipam network_ip_range_delete
+//
+//	Delete the network IP range
+//
+//	Removes the network IP range.
+//	Addresses already allocated from the range are kept.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkIPRangeDelete(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	effectiveProjectName, err := request.GetCtxValue[string](r.Context(), request.CtxEffectiveProjectName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	details, err := request.GetCtxValue[networkDetails](r.Context(), ctxNetworkDetails)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	n, err := network.LoadByName(s, effectiveProjectName, details.networkName)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading network: %w", err))
+	}
+
+	// Check if project allows access to network.
+	if !project.NetworkAllowed(details.requestProject.Config, details.networkName, n.IsManaged()) {
+		return response.SmartError(api.StatusErrorf(http.StatusNotFound, "Network not found"))
+	}
+
+	if !n.Info().IPAM {
+		return response.BadRequest(fmt.Errorf("Network driver %q does not support IP address management", n.Type()))
+	}
+
+	rangeName, err := url.PathUnescape(mux.Vars(r)["rangeName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = n.IPRangeDelete(rangeName)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed deleting IP range: %w", err))
+	}
+
+	s.Events.SendLifecycle(effectiveProjectName, lifecycle.NetworkIPRangeDeleted.Event(n, rangeName, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation GET /1.0/networks/{networkName}/ip-ranges/{rangeName} network-ipam network_ip_range_get
+//
+//	Get the network IP range
+//
+//	Gets a specific network IP range.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: IP range
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/NetworkIPRange"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkIPRangeGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	effectiveProjectName, err := request.GetCtxValue[string](r.Context(), request.CtxEffectiveProjectName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	details, err := request.GetCtxValue[networkDetails](r.Context(), ctxNetworkDetails)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	n, err := network.LoadByName(s, effectiveProjectName, details.networkName)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading network: %w", err))
+	}
+
+	// Check if project allows access to network.
+	if !project.NetworkAllowed(details.requestProject.Config, details.networkName, n.IsManaged()) {
+		return response.SmartError(api.StatusErrorf(http.StatusNotFound, "Network not found"))
+	}
+
+	if !n.Info().IPAM {
+		return response.BadRequest(fmt.Errorf("Network driver %q does not support IP address management", n.Type()))
+	}
+
+	rangeName, err := url.PathUnescape(mux.Vars(r)["rangeName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var ipRange *api.NetworkIPRange
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, ipRange, err = tx.GetNetworkIPRange(ctx, n.ID(), rangeName)
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponseETag(true, ipRange, ipRange.Etag())
+}
+
+// swagger:operation PATCH /1.0/networks/{networkName}/ip-ranges/{rangeName} network-ipam network_ip_range_patch
+//
+//  Partially update the network IP range
+//
+//  Updates a subset of the network IP range configuration.
+//
+//  ---
+//  consumes:
+//    - application/json
+//  produces:
+//    - application/json
+//  parameters:
+//    - in: query
+//      name: project
+//      description: Project name
+//      type: string
+//      example: default
+//    - in: body
+//      name: range
+//      description: IP range configuration
+//      required: true
+//      schema:
+//        $ref: "#/definitions/NetworkIPRangePut"
+//  responses:
+//    "200":
+//      $ref: "#/responses/EmptySyncResponse"
+//    "400":
+//      $ref: "#/responses/BadRequest"
+//    "403":
+//      $ref: "#/responses/Forbidden"
+//    "412":
+//      $ref: "#/responses/PreconditionFailed"
+//    "500":
+//      $ref: "#/responses/InternalServerError"
+
+// swagger:operation PUT /1.0/networks/{networkName}/ip-ranges/{rangeName} network-ipam network_ip_range_put
+//
+//	Update the network IP range
+//
+//	Updates the entire network IP range configuration.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: range
+//	    description: IP range configuration
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/NetworkIPRangePut"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkIPRangePut(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	effectiveProjectName, err := request.GetCtxValue[string](r.Context(), request.CtxEffectiveProjectName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	details, err := request.GetCtxValue[networkDetails](r.Context(), ctxNetworkDetails)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	n, err := network.LoadByName(s, effectiveProjectName, details.networkName)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading network: %w", err))
+	}
+
+	// Check if project allows access to network.
+	if !project.NetworkAllowed(details.requestProject.Config, details.networkName, n.IsManaged()) {
+		return response.SmartError(api.StatusErrorf(http.StatusNotFound, "Network not found"))
+	}
+
+	if !n.Info().IPAM {
+		return response.BadRequest(fmt.Errorf("Network driver %q does not support IP address management", n.Type()))
+	}
+
+	rangeName, err := url.PathUnescape(mux.Vars(r)["rangeName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var ipRange *api.NetworkIPRange
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, ipRange, err = tx.GetNetworkIPRange(ctx, n.ID(), rangeName)
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Validate the ETag.
+	err = util.EtagCheck(r, ipRange.Etag())
+	if err != nil {
+		return response.PreconditionFailed(err)
+	}
+
+	// Decode the request. If the range is being updated via "patch" method, then the fields that aren't
+	// present in the request are kept.
+	req := api.NetworkIPRangePut{}
+	if r.Method == http.MethodPatch {
+		req = ipRange.Writable()
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	req.Normalise() // So we handle the request in normalised/canonical form.
+
+	err = n.IPRangeUpdate(rangeName, req)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed updating IP range: %w", err))
+	}
+
+	s.Events.SendLifecycle(effectiveProjectName, lifecycle.NetworkIPRangeUpdated.Event(n, rangeName, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation GET /1.0/networks/{networkName}/ip-allocations network-ipam network_ip_allocations_get
+//
+//  Get the network IP allocations
+//
+//  Returns a list of the addresses allocated to the project in the network (URLs).
+//
+//  ---
+//  produces:
+//    - application/json
+//  parameters:
+//    - in: query
+//      name: project
+//      description: Project name
+//      type: string
+//      example: default
+//  responses:
+//    "200":
+//      description: API endpoints
+//      schema:
+//        type: object
+//        description: Sync response
+//        properties:
+//          type:
+//            type: string
+//            description: Response type
+//            example: sync
+//          status:
+//            type: string
+//            description: Status description
+//            example: Success
+//          status_code:
+//            type: integer
+//            description: Status code
+//            example: 200
+//          metadata:
+//            type: array
+//            description: List of endpoints
+//            items:
+//              type: string
+//            example: |-
+//              [
+//                "/1.0/networks/lxdbr0/ip-allocations/192.0.2.100",
+//                "/1.0/networks/lxdbr0/ip-allocations/192.0.2.101"
+//              ]
+//    "403":
+//      $ref: "#/responses/Forbidden"
+//    "500":
+//      $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/networks/{networkName}/ip-allocations?recursion=1 network-ipam network_ip_allocations_get_recursion1
+//
+//	Get the network IP allocations
+//
+//	Returns a list of the addresses allocated to the project in the network (structs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of network IP allocations
+//	          items:
+//	            $ref: "#/definitions/NetworkIPAllocation"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkIPAllocationsGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	effectiveProjectName, err := request.GetCtxValue[string](r.Context(), request.CtxEffectiveProjectName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	details, err := request.GetCtxValue[networkDetails](r.Context(), ctxNetworkDetails)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	n, err := network.LoadByName(s, effectiveProjectName, details.networkName)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading network: %w", err))
+	}
+
+	// Check if project allows access to network.
+	if !project.NetworkAllowed(details.requestProject.Config, details.networkName, n.IsManaged()) {
+		return response.SmartError(api.StatusErrorf(http.StatusNotFound, "Network not found"))
+	}
+
+	if !n.Info().IPAM {
+		return response.BadRequest(fmt.Errorf("Network driver %q does not support IP address management", n.Type()))
+	}
+
+	allocations, err := n.IPAllocations(details.requestProject.Name)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading network IP allocations: %w", err))
+	}
+
+	if util.IsRecursionRequest(r) {
+		return response.SyncResponse(true, allocations)
+	}
+
+	allocationURLs := make([]string, 0, len(allocations))
+	for _, allocation := range allocations {
+		allocationURLs = append(allocationURLs, fmt.Sprintf("/%s/networks/%s/ip-allocations/%s", version.APIVersion, url.PathEscape(n.Name()), url.PathEscape(allocation.Address)))
+	}
+
+	return response.SyncResponse(true, allocationURLs)
+}
+
+// swagger:operation POST /1.0/networks/{networkName}/ip-allocations network-ipam network_ip_allocations_post
+//
+//	Allocate an address in the network
+//
+//	Allocates an address in the network to the project.
+//	If no address is specified, the first free address of the ranges delegated to the project is allocated.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: allocation
+//	    description: IP allocation
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/NetworkIPAllocationsPost"
+//	responses:
+//	  "200":
+//	    description: Allocated address
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/NetworkIPAllocation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkIPAllocationsPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	effectiveProjectName, err := request.GetCtxValue[string](r.Context(), request.CtxEffectiveProjectName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	details, err := request.GetCtxValue[networkDetails](r.Context(), ctxNetworkDetails)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Parse the request into a record.
+	req := api.NetworkIPAllocationsPost{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	req.Normalise() // So we handle the request in normalised/canonical form.
+
+	n, err := network.LoadByName(s, effectiveProjectName, details.networkName)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading network: %w", err))
+	}
+
+	// Check if project allows access to network.
+	if !project.NetworkAllowed(details.requestProject.Config, details.networkName, n.IsManaged()) {
+		return response.SmartError(api.StatusErrorf(http.StatusNotFound, "Network not found"))
+	}
+
+	if !n.Info().IPAM {
+		return response.BadRequest(fmt.Errorf("Network driver %q does not support IP address management", n.Type()))
+	}
+
+	address, err := n.IPAllocate(details.requestProject.Name, req)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed allocating address: %w", err))
+	}
+
+	lc := lifecycle.NetworkIPAllocationCreated.Event(n, address.String(), request.CreateRequestor(r), map[string]any{"project": details.requestProject.Name})
+	s.Events.SendLifecycle(effectiveProjectName, lc)
+
+	allocation := api.NetworkIPAllocation{
+		Address:     address.String(),
+		Description: req.Description,
+		Project:     details.requestProject.Name,
+	}
+
+	return response.SyncResponseLocation(true, allocation, lc.Source)
+}
+
+// swagger:operation DELETE /1.0/networks/{networkName}/ip-allocations/{address} network-ipam network_ip_allocation_delete
+//
+//	Release the network IP allocation
+//
+//	Releases an address allocated to the project in the network.
+//	Addresses allocated to instance NICs are released when the NIC is removed.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkIPAllocationDelete(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	effectiveProjectName, err := request.GetCtxValue[string](r.Context(), request.CtxEffectiveProjectName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	details, err := request.GetCtxValue[networkDetails](r.Context(), ctxNetworkDetails)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	n, err := network.LoadByName(s, effectiveProjectName, details.networkName)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading network: %w", err))
+	}
+
+	// Check if project allows access to network.
+	if !project.NetworkAllowed(details.requestProject.Config, details.networkName, n.IsManaged()) {
+		return response.SmartError(api.StatusErrorf(http.StatusNotFound, "Network not found"))
+	}
+
+	if !n.Info().IPAM {
+		return response.BadRequest(fmt.Errorf("Network driver %q does not support IP address management", n.Type()))
+	}
+
+	address, err := url.PathUnescape(mux.Vars(r)["address"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = n.IPRelease(details.requestProject.Name, address)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed releasing address: %w", err))
+	}
+
+	s.Events.SendLifecycle(effectiveProjectName, lifecycle.NetworkIPAllocationDeleted.Event(n, address, request.CreateRequestor(r), map[string]any{"project": details.requestProject.Name}))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation GET /1.0/networks/{networkName}/ip-allocations/{address} network-ipam network_ip_allocation_get
+//
+//	Get the network IP allocation
+//
+//	Gets a specific address allocated to the project in the network.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: IP allocation
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/NetworkIPAllocation"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkIPAllocationGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	effectiveProjectName, err := request.GetCtxValue[string](r.Context(), request.CtxEffectiveProjectName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	details, err := request.GetCtxValue[networkDetails](r.Context(), ctxNetworkDetails)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	n, err := network.LoadByName(s, effectiveProjectName, details.networkName)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading network: %w", err))
+	}
+
+	// Check if project allows access to network.
+	if !project.NetworkAllowed(details.requestProject.Config, details.networkName, n.IsManaged()) {
+		return response.SmartError(api.StatusErrorf(http.StatusNotFound, "Network not found"))
+	}
+
+	if !n.Info().IPAM {
+		return response.BadRequest(fmt.Errorf("Network driver %q does not support IP address management", n.Type()))
+	}
+
+	address, err := url.PathUnescape(mux.Vars(r)["address"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	allocations, err := n.IPAllocations(details.requestProject.Name)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading network IP allocations: %w", err))
+	}
+
+	ip := net.ParseIP(address)
+	for _, allocation := range allocations {
+		if ip != nil && ip.Equal(net.ParseIP(allocation.Address)) {
+			return response.SyncResponse(true, allocation)
+		}
+	}
+
+	return response.NotFound(fmt.Errorf("Network IP allocation not found"))
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -18,9 +19,11 @@ import (
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
 	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
 	"github.com/canonical/lxd/lxd/util"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
 	"github.com/canonical/lxd/shared/version"
 )
 
@@ -565,3 +568,36 @@ func networkZonePut(d *Daemon, r *http.Request) response.Response {
 
 	return response.EmptySyncResponse
 }
+
+// refreshNetworkZones renders the network zones again in the built-in DNS server so that the peers of the zones
+// whose content changed (for example following instance address changes) get notified.
+func refreshNetworkZones(ctx context.Context, s *state.State) {
+	var zoneNames map[string]string
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		zoneNames, err = tx.GetNetworkZones(ctx)
+
+		return err
+	})
+	if err != nil {
+		logger.Error("Failed loading network zones", logger.Ctx{"err": err})
+		return
+	}
+
+	for zoneName := range zoneNames {
+		err := s.DNS.RefreshZone(zoneName)
+		if err != nil {
+			logger.Warn("Failed refreshing DNS zone", logger.Ctx{"zone": zoneName, "err": err})
+		}
+	}
+}
+
+func refreshNetworkZonesTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		refreshNetworkZones(ctx, d.State())
+	}
+
+	return f, task.Every(time.Minute)
+}
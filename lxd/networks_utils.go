@@ -75,6 +75,41 @@ func networkUpdateForkdnsServersTask(s *state.State, heartbeatData *cluster.APIH
 	return nil
 }
 
+// networkUpdateWireguardPeersTask runs on each heartbeat and refreshes the peers of the WireGuard networks, so that
+// they pick up the subnets of the members that joined or left the cluster.
+func networkUpdateWireguardPeersTask(s *state.State, heartbeatData *cluster.APIHeartbeat) error {
+	// Use api.ProjectDefaultName here as WireGuard networks don't support projects.
+	projectName := api.ProjectDefaultName
+
+	var networks []string
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, c *db.ClusterTx) error {
+		var err error
+		networks, err = c.GetCreatedNetworkNamesByProject(ctx, projectName)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, name := range networks {
+		n, err := network.LoadByName(s, projectName, name)
+		if err != nil {
+			logger.Errorf("Failed to load network %q from project %q for heartbeat", name, projectName)
+			continue
+		}
+
+		if n.Type() == "wireguard" {
+			err := n.HandleHeartbeat(heartbeatData)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 // networkUpdateOVNChassis gets called on heartbeats to check if OVN needs reconfiguring.
 func networkUpdateOVNChassis(s *state.State, heartbeatData *cluster.APIHeartbeat, localAddress string) error {
 	// Check if we have at least one active OVN chassis.
@@ -94,6 +94,7 @@ var patches = []patch{
 	{name: "config_remove_core_trust_password", stage: patchPreLoadClusterConfig, run: patchRemoveCoreTrustPassword},
 	{name: "entity_type_instance_snapshot_on_delete_trigger_typo_fix", stage: patchPreLoadClusterConfig, run: patchEntityTypeInstanceSnapshotOnDeleteTriggerTypoFix},
 	{name: "instance_remove_volatile_last_state_ip_addresses", stage: patchPostDaemonStorage, run: patchInstanceRemoveVolatileLastStateIPAddresses},
+	{name: "storage_set_encryption_key", stage: patchPreDaemonStorage, run: patchStorageSetEncryptionKey},
 }
 
 type patch struct {
@@ -1436,4 +1437,29 @@ func patchInstanceRemoveVolatileLastStateIPAddresses(_ string, d *Daemon) error
 	return nil
 }
 
+// patchStorageSetEncryptionKey generates the key used to encrypt volumes for existing storage pools that support
+// encryption. The key is stored as a cluster wide config key so that it is shared by all cluster members.
+func patchStorageSetEncryptionKey(_ string, d *Daemon) error {
+	s := d.State()
+	err := s.DB.Cluster.Transaction(s.ShutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
+		_, err := tx.Tx().Exec(`
+INSERT INTO storage_pools_config(storage_pool_id, node_id, key, value)
+SELECT storage_pools.id, NULL, 'volatile.encryption.key', lower(hex(randomblob(32)))
+FROM storage_pools
+WHERE storage_pools.driver IN ('ceph', 'lvm', 'zfs')
+AND NOT EXISTS (
+	SELECT 1 FROM storage_pools_config
+	WHERE storage_pools_config.storage_pool_id = storage_pools.id
+	AND storage_pools_config.key = 'volatile.encryption.key'
+)
+`)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed setting storage pool encryption keys: %w", err)
+	}
+
+	return nil
+}
+
 // Patches end here
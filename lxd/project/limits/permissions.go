@@ -27,6 +27,27 @@ import (
 // projectLimitDiskPool is the prefix used for pool-specific disk limits.
 var projectLimitDiskPool = "limits.disk.pool."
 
+// projectLimitVolumeKeys maps the project limits on the I/O of custom volumes to the volume config key they sum up.
+var projectLimitVolumeKeys = map[string]string{
+	"limits.disk.iops":      "limits.iops",
+	"limits.disk.bandwidth": "limits.bandwidth",
+}
+
+// projectHasVolumeLimits returns true if the project has a limit set that applies to custom volumes.
+func projectHasVolumeLimits(project api.Project) bool {
+	if project.Config["limits.disk"] != "" {
+		return true
+	}
+
+	for key := range projectLimitVolumeKeys {
+		if project.Config[key] != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
 // HiddenStoragePools returns a list of storage pools that should be hidden from users of the project.
 func HiddenStoragePools(ctx context.Context, tx *db.ClusterTx, projectName string) ([]string, error) {
 	dbProject, err := cluster.GetProject(ctx, tx.Tx(), projectName)
@@ -280,8 +301,8 @@ func AllowVolumeCreation(globalConfig *clusterConfig.Config, tx *db.ClusterTx, p
 		return nil
 	}
 
-	// If "limits.disk" is not set, there's nothing to do.
-	if info.Project.Config["limits.disk"] == "" {
+	// If no volume limits are set, there's nothing to do.
+	if !projectHasVolumeLimits(info.Project) {
 		return nil
 	}
 
@@ -827,6 +848,8 @@ func checkRestrictions(proj api.Project, instances []api.Instance, profiles []ap
 var allAggregateLimits = []string{
 	"limits.cpu",
 	"limits.disk",
+	"limits.disk.bandwidth",
+	"limits.disk.iops",
 	"limits.memory",
 	"limits.processes",
 }
@@ -972,8 +995,8 @@ func AllowVolumeUpdate(globalConfig *clusterConfig.Config, tx *db.ClusterTx, pro
 		return nil
 	}
 
-	// If "limits.disk" is not set, there's nothing to do.
-	if info.Project.Config["limits.disk"] == "" {
+	// If no volume limits are set, there's nothing to do.
+	if !projectHasVolumeLimits(info.Project) {
 		return nil
 	}
 
@@ -1086,6 +1109,10 @@ func AllowProjectUpdate(globalConfig *clusterConfig.Config, tx *db.ClusterTx, pr
 			fallthrough
 		case "limits.memory":
 			fallthrough
+		case "limits.disk.iops":
+			fallthrough
+		case "limits.disk.bandwidth":
+			fallthrough
 		case "limits.disk":
 			aggregateKeys = append(aggregateKeys, key)
 		}
@@ -1378,6 +1405,29 @@ func getTotalsAcrossProjectEntities(info *projectInfo, keys []string, skipUnset
 				totals[key] += limit
 			}
 		}
+
+		volumeKey, ok := projectLimitVolumeKeys[key]
+		if ok {
+			parser := aggregateLimitConfigValueParsers[key]
+
+			for _, volume := range info.Volumes {
+				value, ok := volume.Config[volumeKey]
+				if !ok || value == "" {
+					if skipUnset {
+						continue
+					}
+
+					return nil, fmt.Errorf("Custom volume %q in project %q has no %q config set", volume.Name, info.Project.Name, volumeKey)
+				}
+
+				limit, err := parser(value)
+				if err != nil {
+					return nil, fmt.Errorf("Parse %q for custom volume %q in project %q: %w", volumeKey, volume.Name, info.Project.Name, err)
+				}
+
+				totals[key] += limit
+			}
+		}
 	}
 
 	for _, instance := range info.Instances {
@@ -1400,6 +1450,12 @@ func getInstanceLimits(instance api.Instance, keys []string, skipUnset bool, sto
 	limits := map[string]int64{}
 
 	for _, key := range keys {
+		// Custom volume I/O limits don't apply to instances.
+		_, ok := projectLimitVolumeKeys[key]
+		if ok {
+			continue
+		}
+
 		var limit int64
 		keyName := key
 
@@ -1532,6 +1588,12 @@ var aggregateLimitConfigValueParsers = map[string]func(string) (int64, error){
 	"limits.disk": func(value string) (int64, error) {
 		return units.ParseByteSizeString(value)
 	},
+	"limits.disk.iops": func(value string) (int64, error) {
+		return strconv.ParseInt(value, 10, 64)
+	},
+	"limits.disk.bandwidth": func(value string) (int64, error) {
+		return units.ParseByteSizeString(value)
+	},
 }
 
 var aggregateLimitConfigValuePrinters = map[string]func(int64) string{
@@ -1547,6 +1609,12 @@ var aggregateLimitConfigValuePrinters = map[string]func(int64) string{
 	"limits.disk": func(limit int64) string {
 		return units.GetByteSizeStringIEC(limit, 1)
 	},
+	"limits.disk.iops": func(limit int64) string {
+		return fmt.Sprintf("%d", limit)
+	},
+	"limits.disk.bandwidth": func(limit int64) string {
+		return units.GetByteSizeStringIEC(limit, 1)
+	},
 }
 
 // Return true if particular restriction in project is violated.
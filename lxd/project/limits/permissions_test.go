@@ -159,6 +159,38 @@ func TestAllowInstanceCreation_AboveInstances(t *testing.T) {
 	assert.EqualError(t, err, `Reached maximum number of instances in project "p1"`)
 }
 
+// If I/O limits are configured, custom volumes must set their own limits within them.
+func TestAllowVolumeCreation_IOLimits(t *testing.T) {
+	tx, cleanup := db.NewTestClusterTx(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	id, err := cluster.CreateProject(ctx, tx.Tx(), cluster.Project{Name: "p1"})
+	require.NoError(t, err)
+
+	err = cluster.CreateProjectConfig(ctx, tx.Tx(), id, map[string]string{"limits.disk.iops": "1000", "limits.disk.bandwidth": "100MiB"})
+	require.NoError(t, err)
+
+	req := api.StorageVolumesPost{
+		Name: "vol1",
+		Type: "custom",
+		StorageVolumePut: api.StorageVolumePut{
+			Config: map[string]string{"limits.iops": "500"},
+		},
+	}
+
+	err = limits.AllowVolumeCreation(nil, tx, "p1", "pool1", req)
+	assert.EqualError(t, err, `Failed checking if volume creation allowed: Failed getting usage of project entities: Custom volume "vol1" in project "p1" has no "limits.bandwidth" config set`)
+
+	req.Config["limits.bandwidth"] = "200MiB"
+	err = limits.AllowVolumeCreation(nil, tx, "p1", "pool1", req)
+	assert.EqualError(t, err, `Failed checking if volume creation allowed: Reached maximum aggregate value "100MiB" for "limits.disk.bandwidth" in project "p1"`)
+
+	req.Config["limits.bandwidth"] = "50MiB"
+	err = limits.AllowVolumeCreation(nil, tx, "p1", "pool1", req)
+	assert.NoError(t, err)
+}
+
 // If a direct targeting is blocked, the check fails.
 func TestCheckClusterTargetRestriction_RestrictedTrue(t *testing.T) {
 	tx, cleanup := db.NewTestClusterTx(t)
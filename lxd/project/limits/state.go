@@ -48,6 +48,8 @@ func GetCurrentAllocations(globalConfig map[string]any, ctx context.Context, tx
 
 	result["cpu"] = raw["limits.cpu"]
 	result["disk"] = raw["limits.disk"]
+	result["disk-bandwidth"] = raw["limits.disk.bandwidth"]
+	result["disk-iops"] = raw["limits.disk.iops"]
 	result["memory"] = raw["limits.memory"]
 	result["networks"] = raw["limits.networks"]
 	result["processes"] = raw["limits.processes"]
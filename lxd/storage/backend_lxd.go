@@ -15,6 +15,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -150,8 +151,12 @@ func (b *lxdBackend) isStatusReady() error {
 }
 
 // ToAPI returns the storage pool as an API representation.
+// Internal config keys, such as the pool encryption key, are omitted.
 func (b *lxdBackend) ToAPI() api.StoragePool {
-	return b.db
+	pool := b.db
+	pool.Config = publicPoolConfig(b.db.Config)
+
+	return pool
 }
 
 // Driver returns the storage pool driver.
@@ -170,7 +175,7 @@ func (b *lxdBackend) MigrationTypes(contentType drivers.ContentType, refresh boo
 // Create creates the storage pool layout on the storage device.
 // localOnly is used for clustering where only a single node should do remote storage setup.
 func (b *lxdBackend) Create(clientType request.ClientType, op *operations.Operation) error {
-	l := b.logger.AddContext(logger.Ctx{"config": b.db.Config, "description": b.db.Description, "clientType": clientType})
+	l := b.logger.AddContext(logger.Ctx{"config": publicPoolConfig(b.db.Config), "description": b.db.Description, "clientType": clientType})
 	l.Debug("Create started")
 	defer l.Debug("Create finished")
 
@@ -269,7 +274,175 @@ func (b *lxdBackend) GetResources() (*api.ResourcesStoragePool, error) {
 	l.Debug("GetResources started")
 	defer l.Debug("GetResources finished")
 
-	return b.driver.GetResources()
+	res, err := b.driver.GetResources()
+	if err != nil {
+		return nil, err
+	}
+
+	// The allocated space is only informational, so don't fail if it can't be worked out.
+	res.Space.Allocated, _, err = b.allocatedSpace("", "", "")
+	if err != nil {
+		l.Warn("Failed getting allocated space", logger.Ctx{"err": err})
+	}
+
+	return res, nil
+}
+
+// allocatedSpace returns the total size of the volumes on the pool (excluding snapshots) as seen by this member.
+// The volume matching the excludeProject, excludeType and excludeName arguments is not counted, its size is
+// returned separately instead.
+// Instance volumes use the size of their root disk device, other volumes use their size property.
+// Volumes without a size use the pool's default volume size, or for block volumes the default block size.
+// Volumes without any size limit aren't counted, as getting their current usage is too expensive to do whenever
+// a volume is created or resized. Their usage is accounted for by the pool's used space instead.
+func (b *lxdBackend) allocatedSpace(excludeProject string, excludeType drivers.VolumeType, excludeName string) (uint64, uint64, error) {
+	var dbVolumes []*db.StorageVolume
+	rootDiskSizes := map[string]string{}
+
+	err := b.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		poolID := b.ID() // Create local variable to get the pointer.
+		dbVolumes, err = tx.GetStorageVolumes(ctx, !b.driver.Info().Remote, db.StorageVolumeFilter{PoolID: &poolID})
+		if err != nil {
+			return fmt.Errorf("Failed loading storage volumes: %w", err)
+		}
+
+		// Only load the instances that have their root volume on the pool.
+		var filters []cluster.InstanceFilter
+		for _, dbVol := range dbVolumes {
+			if shared.IsSnapshot(dbVol.Name) {
+				continue
+			}
+
+			var instType instancetype.Type
+			switch dbVol.Type {
+			case cluster.StoragePoolVolumeTypeNameContainer:
+				instType = instancetype.Container
+			case cluster.StoragePoolVolumeTypeNameVM:
+				instType = instancetype.VM
+			default:
+				continue
+			}
+
+			filters = append(filters, cluster.InstanceFilter{Project: &dbVol.Project, Name: &dbVol.Name, Type: &instType})
+		}
+
+		if len(filters) == 0 {
+			return nil
+		}
+
+		return tx.InstanceList(ctx, func(inst db.InstanceArgs, p api.Project) error {
+			devices := instancetype.ExpandInstanceDevices(inst.Devices.Clone(), inst.Profiles)
+			_, rootDiskConf, err := instancetype.GetRootDiskDevice(devices.CloneNative())
+			if err != nil {
+				// Skip instances without a root disk.
+				return nil
+			}
+
+			if rootDiskConf["pool"] != b.name {
+				return nil
+			}
+
+			rootDiskSizes[project.Instance(inst.Project, inst.Name)] = rootDiskConf["size"]
+			return nil
+		}, filters...)
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var allocated, excluded uint64
+	for _, dbVol := range dbVolumes {
+		if shared.IsSnapshot(dbVol.Name) {
+			continue
+		}
+
+		volDBType, err := VolumeTypeNameToDBType(dbVol.Type)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		volType, err := VolumeDBTypeToType(volDBType)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		vol := b.GetVolume(volType, drivers.ContentType(dbVol.ContentType), project.StorageVolume(dbVol.Project, dbVol.Name), dbVol.Config)
+		if volType == drivers.VolumeTypeContainer || volType == drivers.VolumeTypeVM {
+			rootDiskSize := rootDiskSizes[project.Instance(dbVol.Project, dbVol.Name)]
+			if rootDiskSize != "" {
+				vol.SetConfigSize(rootDiskSize)
+			}
+		}
+
+		size := vol.ConfigSize()
+		if size == "" {
+			continue
+		}
+
+		sizeBytes, err := units.ParseByteSizeString(size)
+		if err != nil {
+			return 0, 0, fmt.Errorf("Failed parsing size of volume %q in project %q: %w", dbVol.Name, dbVol.Project, err)
+		}
+
+		if sizeBytes <= 0 {
+			continue
+		}
+
+		if dbVol.Project == excludeProject && volType == excludeType && dbVol.Name == excludeName {
+			excluded = uint64(sizeBytes)
+			continue
+		}
+
+		allocated += uint64(sizeBytes)
+	}
+
+	return allocated, excluded, nil
+}
+
+// checkOvercommit checks that allocating the given size to a volume doesn't take the pool's allocated space
+// beyond the limit set by volume.overcommit_ratio. Any size currently allocated to the volume is disregarded and
+// reducing the size of a volume is always allowed.
+func (b *lxdBackend) checkOvercommit(projectName string, volType drivers.VolumeType, volName string, size string) error {
+	if b.db.Config["volume.overcommit_ratio"] == "" {
+		return nil
+	}
+
+	ratio, err := strconv.ParseFloat(b.db.Config["volume.overcommit_ratio"], 64)
+	if err != nil {
+		return fmt.Errorf("Invalid volume.overcommit_ratio: %w", err)
+	}
+
+	sizeBytes, err := units.ParseByteSizeString(size)
+	if err != nil || sizeBytes <= 0 {
+		return nil
+	}
+
+	res, err := b.driver.GetResources()
+	if err != nil {
+		if errors.Is(err, drivers.ErrNotSupported) {
+			return nil
+		}
+
+		return fmt.Errorf("Failed getting storage pool resources: %w", err)
+	}
+
+	allocated, curSize, err := b.allocatedSpace(projectName, volType, volName)
+	if err != nil {
+		return fmt.Errorf("Failed getting allocated space: %w", err)
+	}
+
+	if uint64(sizeBytes) <= curSize {
+		return nil
+	}
+
+	limit := uint64(float64(res.Space.Total) * ratio)
+	if allocated+uint64(sizeBytes) > limit {
+		return api.StatusErrorf(http.StatusInsufficientStorage, "Allocating %s would exceed the storage pool's overcommit limit (%s allocated of %s allowed)", units.GetByteSizeStringIEC(sizeBytes, 2), units.GetByteSizeStringIEC(int64(allocated), 2), units.GetByteSizeStringIEC(int64(limit), 2))
+	}
+
+	return nil
 }
 
 // IsUsed returns whether the storage pool is used by any volumes or profiles (excluding image volumes).
@@ -284,7 +457,7 @@ func (b *lxdBackend) IsUsed() (bool, error) {
 
 // Update updates the pool config.
 func (b *lxdBackend) Update(clientType request.ClientType, newDesc string, newConfig map[string]string, op *operations.Operation) error {
-	l := b.logger.AddContext(logger.Ctx{"newDesc": newDesc, "newConfig": newConfig})
+	l := b.logger.AddContext(logger.Ctx{"newDesc": newDesc, "newConfig": publicPoolConfig(newConfig)})
 	l.Debug("Update started")
 	defer l.Debug("Update finished")
 
@@ -692,6 +865,11 @@ func (b *lxdBackend) CreateInstance(inst instance.Instance, op *operations.Opera
 		return err
 	}
 
+	err = b.checkOvercommit(inst.Project().Name, volType, inst.Name(), vol.ConfigSize())
+	if err != nil {
+		return err
+	}
+
 	err = b.driver.CreateVolume(vol, nil, op)
 	if err != nil {
 		return err
@@ -1012,6 +1190,146 @@ func (b *lxdBackend) CreateInstanceFromBackup(srcBackup backup.Info, srcData io.
 	return postHook, revertHook, nil
 }
 
+// RefreshInstanceFromBackup applies an incremental backup to the existing volume of an instance.
+// The snapshot the backup is incremental from must be the latest snapshot of the instance. The database
+// records of the storage volume snapshots are created, but those of the instance snapshots are not.
+// Returns a revert hook that can be used to undo the changes should a subsequent step fail.
+func (b *lxdBackend) RefreshInstanceFromBackup(inst instance.Instance, srcBackup backup.Info, srcData io.ReadSeeker, op *operations.Operation) (revert.Hook, error) {
+	l := b.logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "snapshots": srcBackup.Snapshots, "incrementalFrom": srcBackup.IncrementalFrom})
+	l.Debug("RefreshInstanceFromBackup started")
+	defer l.Debug("RefreshInstanceFromBackup finished")
+
+	if srcBackup.IncrementalFrom == "" {
+		return nil, fmt.Errorf("Backup isn't incremental")
+	}
+
+	if srcBackup.Config == nil || len(srcBackup.Snapshots) != len(srcBackup.Config.VolumeSnapshots) {
+		return nil, fmt.Errorf("Valid volume snapshot config not found in index")
+	}
+
+	// Validate the names in the backup.yaml file as these could be malicious.
+	for _, snapName := range append([]string{srcBackup.IncrementalFrom}, srcBackup.Snapshots...) {
+		snapInstName := fmt.Sprintf("%s%s%s", inst.Name(), shared.SnapshotDelimiter, snapName)
+		err := instancetype.ValidName(snapInstName, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if inst.IsRunning() {
+		return nil, fmt.Errorf("Cannot apply incremental backup to running instance")
+	}
+
+	volType, err := InstanceTypeToVolumeType(inst.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	if string(srcBackup.Type) != inst.Type().String() {
+		return nil, fmt.Errorf("Backup of type %q cannot be applied to instance of type %q", srcBackup.Type, inst.Type().String())
+	}
+
+	contentType := InstanceContentType(inst)
+
+	// Check the snapshot the backup is incremental from is the latest snapshot of the instance.
+	instSnapshots, err := inst.Snapshots()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(instSnapshots) == 0 || instSnapshots[len(instSnapshots)-1].Name() != drivers.GetSnapshotVolumeName(inst.Name(), srcBackup.IncrementalFrom) {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "Snapshot %q the backup is incremental from must be the latest snapshot of the instance", srcBackup.IncrementalFrom)
+	}
+
+	// Load storage volume from database.
+	dbVol, err := VolumeDBGet(b, inst.Project().Name, inst.Name(), volType)
+	if err != nil {
+		return nil, err
+	}
+
+	baseSnapVol, err := VolumeDBGet(b, inst.Project().Name, instSnapshots[len(instSnapshots)-1].Name(), volType)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the snapshot is the one of the parent backup and not just one with the same name.
+	if srcBackup.IncrementalFromCreatedAt != nil && baseSnapVol.CreatedAt.Unix() != srcBackup.IncrementalFromCreatedAt.Unix() {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "Snapshot %q of the instance wasn't restored from the parent backup %q", srcBackup.IncrementalFrom, srcBackup.IncrementalParent)
+	}
+
+	// Generate the effective root device volume for instance.
+	volStorageName := project.Instance(inst.Project().Name, inst.Name())
+	vol := b.GetVolume(volType, contentType, volStorageName, dbVol.Config)
+	err = b.applyInstanceRootDiskOverrides(inst, &vol)
+	if err != nil {
+		return nil, err
+	}
+
+	baseSnapStorageName := project.Instance(inst.Project().Name, baseSnapVol.Name)
+	sourceSnapshots := []drivers.Volume{b.GetVolume(volType, contentType, baseSnapStorageName, baseSnapVol.Config)}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Create database entries for new storage volume snapshots.
+	for i, snapName := range srcBackup.Snapshots {
+		volSnap := srcBackup.Config.VolumeSnapshots[i]
+		if volSnap == nil || volSnap.Name != snapName {
+			return nil, fmt.Errorf("Valid volume snapshot config not found in index for snapshot %q", snapName)
+		}
+
+		var expiryDate time.Time
+		if volSnap.ExpiresAt != nil {
+			expiryDate = *volSnap.ExpiresAt
+		}
+
+		newSnapshotName := drivers.GetSnapshotVolumeName(inst.Name(), snapName)
+		snapVol := b.GetVolume(volType, contentType, project.Instance(inst.Project().Name, newSnapshotName), volSnap.Config)
+
+		// Validate config and create database entry for new storage volume.
+		// Strip unsupported config keys (in case the export was made from a different type of storage pool).
+		err = VolumeDBCreate(b, inst.Project().Name, newSnapshotName, volSnap.Description, volType, true, snapVol.Config(), volSnap.CreatedAt, expiryDate, contentType, true, true)
+		if err != nil {
+			return nil, err
+		}
+
+		revert.Add(func() { _ = VolumeDBDelete(b, inst.Project().Name, newSnapshotName, volType) })
+
+		sourceSnapshots = append(sourceSnapshots, snapVol)
+	}
+
+	volCopy := drivers.NewVolumeCopy(vol, sourceSnapshots...)
+
+	// Apply the backup to the existing storage volume(s).
+	volPostHook, revertHook, err := b.driver.CreateVolumeFromBackup(volCopy, srcBackup, srcData, op)
+	if err != nil {
+		return nil, err
+	}
+
+	if revertHook != nil {
+		revert.Add(revertHook)
+	}
+
+	if len(srcBackup.Snapshots) > 0 {
+		err = b.ensureInstanceSnapshotSymlink(inst.Type(), inst.Project().Name, inst.Name())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Run the driver's post hook now as the instance already exists.
+	if volPostHook != nil {
+		err = volPostHook(vol)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cleanup := revert.Clone().Fail
+	revert.Success()
+	return cleanup, nil
+}
+
 // CreateInstanceFromCopy copies an instance volume and optionally its snapshots to new volume(s).
 func (b *lxdBackend) CreateInstanceFromCopy(inst instance.Instance, src instance.Instance, snapshots bool, allowInconsistent bool, op *operations.Operation) error {
 	l := b.logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "src": src.Name(), "snapshots": snapshots})
@@ -1149,6 +1467,11 @@ func (b *lxdBackend) CreateInstanceFromCopy(inst instance.Instance, src instance
 			return err
 		}
 
+		err = b.checkOvercommit(inst.Project().Name, volType, inst.Name(), vol.ConfigSize())
+		if err != nil {
+			return err
+		}
+
 		// Get the src volume name on storage.
 		srcVolStorageName := project.Instance(src.Project().Name, src.Name())
 		srcVol := b.GetVolume(volType, contentType, srcVolStorageName, srcConfig.Volume.Config)
@@ -1166,7 +1489,7 @@ func (b *lxdBackend) CreateInstanceFromCopy(inst instance.Instance, src instance
 		l.Debug("CreateInstanceFromCopy cross-pool mode detected")
 
 		// Negotiate the migration type to use.
-		offeredTypes := srcPool.MigrationTypes(contentType, false, snapshots)
+		offeredTypes := EncryptedMigrationTypes(srcPool.MigrationTypes(contentType, false, snapshots), srcConfig.Volume.Config, vol.Config())
 		offerHeader := migration.TypesToHeader(offeredTypes...)
 		migrationTypes, err := migration.MatchTypes(offerHeader, FallbackMigrationType(contentType), b.MigrationTypes(contentType, false, snapshots))
 		if err != nil {
@@ -1200,6 +1523,7 @@ func (b *lxdBackend) CreateInstanceFromCopy(inst instance.Instance, src instance
 				Name:               src.Name(),
 				Snapshots:          snapshotNames,
 				MigrationType:      migrationTypes[0],
+				BlockDelta:         true, // Only transfer the changes between snapshots of block volumes.
 				TrackProgress:      true, // Do use a progress tracker on sender.
 				AllowInconsistent:  allowInconsistent,
 				VolumeOnly:         !snapshots,
@@ -1213,6 +1537,7 @@ func (b *lxdBackend) CreateInstanceFromCopy(inst instance.Instance, src instance
 				Name:               inst.Name(),
 				Snapshots:          snapshotNames,
 				MigrationType:      migrationTypes[0],
+				BlockDelta:         true,          // Only transfer the changes between snapshots of block volumes.
 				VolumeSize:         srcVolumeSize, // Block size setting override.
 				TrackProgress:      false,         // Do not use a progress tracker on receiver.
 				VolumeOnly:         !snapshots,
@@ -1442,7 +1767,7 @@ func (b *lxdBackend) RefreshCustomVolume(projectName string, srcProjectName stri
 		l.Debug("RefreshCustomVolume cross-pool mode detected")
 
 		// Negotiate the migration type to use.
-		offeredTypes := srcPool.MigrationTypes(contentType, true, snapshots)
+		offeredTypes := EncryptedMigrationTypes(srcPool.MigrationTypes(contentType, true, snapshots), srcConfig.Volume.Config, vol.Config())
 		offerHeader := migration.TypesToHeader(offeredTypes...)
 		migrationTypes, err := migration.MatchTypes(offerHeader, FallbackMigrationType(contentType), b.MigrationTypes(contentType, true, snapshots))
 		if err != nil {
@@ -1489,6 +1814,7 @@ func (b *lxdBackend) RefreshCustomVolume(projectName string, srcProjectName stri
 				Name:               srcConfig.Volume.Name,
 				Snapshots:          snapshotNames,
 				MigrationType:      migrationTypes[0],
+				BlockDelta:         true, // Only transfer the changes between snapshots of block volumes.
 				TrackProgress:      true, // Do use a progress tracker on sender.
 				ContentType:        string(contentType),
 				Info:               &migration.Info{Config: srcConfig},
@@ -1509,6 +1835,7 @@ func (b *lxdBackend) RefreshCustomVolume(projectName string, srcProjectName stri
 				Config:             config,
 				Snapshots:          snapshotNames,
 				MigrationType:      migrationTypes[0],
+				BlockDelta:         true,  // Only transfer the changes between snapshots of block volumes.
 				TrackProgress:      false, // Do not use a progress tracker on receiver.
 				ContentType:        string(contentType),
 				VolumeSize:         volSize, // Block size setting override.
@@ -1715,7 +2042,7 @@ func (b *lxdBackend) RefreshInstance(inst instance.Instance, src instance.Instan
 		l.Debug("RefreshInstance cross-pool mode detected")
 
 		// Negotiate the migration type to use.
-		offeredTypes := srcPool.MigrationTypes(contentType, true, snapshots)
+		offeredTypes := EncryptedMigrationTypes(srcPool.MigrationTypes(contentType, true, snapshots), srcConfig.Volume.Config, vol.Config())
 		offerHeader := migration.TypesToHeader(offeredTypes...)
 		migrationTypes, err := migration.MatchTypes(offerHeader, FallbackMigrationType(contentType), b.MigrationTypes(contentType, true, snapshots))
 		if err != nil {
@@ -1739,6 +2066,7 @@ func (b *lxdBackend) RefreshInstance(inst instance.Instance, src instance.Instan
 				Name:               src.Name(),
 				Snapshots:          snapshotNames,
 				MigrationType:      migrationTypes[0],
+				BlockDelta:         true, // Only transfer the changes between snapshots of block volumes.
 				TrackProgress:      true, // Do use a progress tracker on sender.
 				AllowInconsistent:  allowInconsistent,
 				Refresh:            true, // Indicate to sender to use incremental streams.
@@ -1753,6 +2081,7 @@ func (b *lxdBackend) RefreshInstance(inst instance.Instance, src instance.Instan
 				Name:               inst.Name(),
 				Snapshots:          snapshotNames,
 				MigrationType:      migrationTypes[0],
+				BlockDelta:         true,  // Only transfer the changes between snapshots of block volumes.
 				Refresh:            true,  // Indicate to receiver volume should exist.
 				TrackProgress:      false, // Do not use a progress tracker on receiver.
 				VolumeOnly:         !snapshots,
@@ -2014,6 +2343,11 @@ func (b *lxdBackend) CreateInstanceFromImage(inst instance.Instance, fingerprint
 		return err
 	}
 
+	err = b.checkOvercommit(inst.Project().Name, volType, inst.Name(), vol.ConfigSize())
+	if err != nil {
+		return err
+	}
+
 	// Leave reverting on failure to caller, they are expected to call DeleteInstance().
 
 	// If the driver doesn't support optimized image volumes or the optimized image volume should not be used,
@@ -2270,6 +2604,11 @@ func (b *lxdBackend) CreateInstanceFromMigration(inst instance.Instance, conn io
 		return err
 	}
 
+	err = b.checkOvercommit(inst.Project().Name, volType, inst.Name(), vol.ConfigSize())
+	if err != nil {
+		return err
+	}
+
 	// Override args.Name and args.Config to ensure volume is created based on instance.
 	args.Config = vol.Config()
 	args.Name = inst.Name()
@@ -3152,8 +3491,9 @@ func (b *lxdBackend) CleanupInstancePaths(inst instance.Instance, op *operations
 }
 
 // BackupInstance creates an instance backup.
-func (b *lxdBackend) BackupInstance(inst instance.Instance, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots bool, op *operations.Operation) error {
-	l := b.logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "optimized": optimized, "snapshots": snapshots})
+// If incrementalFrom is set, only the changes since that snapshot are included in the backup.
+func (b *lxdBackend) BackupInstance(inst instance.Instance, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots bool, incrementalFrom string, op *operations.Operation) error {
+	l := b.logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "optimized": optimized, "snapshots": snapshots, "incrementalFrom": incrementalFrom})
 	l.Debug("BackupInstance started")
 	defer l.Debug("BackupInstance finished")
 
@@ -3186,13 +3526,17 @@ func (b *lxdBackend) BackupInstance(inst instance.Instance, tarWriter *instancew
 
 	var snapNames []string
 	var sourceSnapshots []drivers.Volume
-	if snapshots {
+	if snapshots || incrementalFrom != "" {
 		// Get snapshots in age order, oldest first, and pass names to storage driver.
 		instSnapshots, err := inst.Snapshots()
 		if err != nil {
 			return err
 		}
 
+		// For incremental backups, only the snapshots taken after the one the backup is incremental from
+		// are included, but the storage driver still needs all of them to find the parents.
+		foundBase := incrementalFrom == ""
+
 		snapNames = make([]string, 0, len(instSnapshots))
 		sourceSnapshots = make([]drivers.Volume, 0, len(instSnapshots))
 		for _, instSnapshot := range instSnapshots {
@@ -3203,15 +3547,24 @@ func (b *lxdBackend) BackupInstance(inst instance.Instance, tarWriter *instancew
 			}
 
 			_, snapName, _ := api.GetParentAndSnapshotName(instSnapshot.Name())
-			snapNames = append(snapNames, snapName)
 			snapshotStorageName := project.Instance(inst.Project().Name, instSnapshot.Name())
 			sourceSnapshots = append(sourceSnapshots, b.GetVolume(volType, contentType, snapshotStorageName, snapVol.Config))
+
+			if snapName == incrementalFrom {
+				foundBase = true
+			} else if snapshots && foundBase {
+				snapNames = append(snapNames, snapName)
+			}
+		}
+
+		if !foundBase {
+			return api.StatusErrorf(http.StatusNotFound, "Snapshot %q to create incremental backup from not found", incrementalFrom)
 		}
 	}
 
 	volCopy := drivers.NewVolumeCopy(vol, sourceSnapshots...)
 
-	err = b.driver.BackupVolume(volCopy, tarWriter, optimized, snapNames, op)
+	err = b.driver.BackupVolume(volCopy, tarWriter, optimized, snapNames, incrementalFrom, op)
 	if err != nil {
 		return err
 	}
@@ -3276,6 +3629,54 @@ func (b *lxdBackend) GetInstanceUsage(inst instance.Instance) (*VolumeUsage, err
 	return &val, nil
 }
 
+// VerifyInstance checks the integrity of the instance's root volume.
+func (b *lxdBackend) VerifyInstance(inst instance.Instance, op *operations.Operation) (*drivers.VolumeVerifyResult, error) {
+	l := b.logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+	l.Debug("VerifyInstance started")
+	defer l.Debug("VerifyInstance finished")
+
+	err := b.isStatusReady()
+	if err != nil {
+		return nil, err
+	}
+
+	volType, err := InstanceTypeToVolumeType(inst.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := InstanceContentType(inst)
+
+	// Load storage volume from database.
+	dbVol, err := VolumeDBGet(b, inst.Project().Name, inst.Name(), volType)
+	if err != nil {
+		return nil, err
+	}
+
+	volStorageName := project.Instance(inst.Project().Name, inst.Name())
+	vol := b.GetVolume(volType, contentType, volStorageName, dbVol.Config)
+
+	return b.verifyVolume(vol, op)
+}
+
+// verifyVolume checks the integrity of a volume using the storage driver.
+// If the storage driver doesn't support verifying volumes, the volume is reported as unverified.
+func (b *lxdBackend) verifyVolume(vol drivers.Volume, op *operations.Operation) (*drivers.VolumeVerifyResult, error) {
+	result, err := b.driver.VerifyVolume(vol, op)
+	if err != nil {
+		if errors.Is(err, drivers.ErrNotSupported) {
+			return &drivers.VolumeVerifyResult{
+				Problems:   []string{},
+				Unverified: []string{fmt.Sprintf("Storage driver %q doesn't support checking volumes", b.driver.Info().Name)},
+			}, nil
+		}
+
+		return nil, err
+	}
+
+	return result, nil
+}
+
 // SetInstanceQuota sets the quota on the instance's root volume.
 // Returns ErrInUse if the instance is running and the storage driver doesn't support online resizing.
 func (b *lxdBackend) SetInstanceQuota(inst instance.Instance, size string, vmStateSize string, op *operations.Operation) error {
@@ -3298,6 +3699,11 @@ func (b *lxdBackend) SetInstanceQuota(inst instance.Instance, size string, vmSta
 		return err
 	}
 
+	err = b.checkOvercommit(inst.Project().Name, volType, inst.Name(), size)
+	if err != nil {
+		return err
+	}
+
 	// Apply the main volume quota.
 	vol := b.GetVolume(volType, contentVolume, volStorageName, dbVol.Config)
 	err = b.driver.SetVolumeQuota(vol, size, false, op)
@@ -4025,11 +4431,16 @@ func (b *lxdBackend) EnsureImage(fingerprint string, op *operations.Operation) e
 		// setting for new volumes.
 		blockFSChanged := imgVol.IsBlockBacked() && imgVol.Config()["block.filesystem"] != tmpImgVol.Config()["block.filesystem"]
 
+		// Check if the volume's encryption differs from the pool's current setting for new volumes.
+		encryptionChanged := shared.IsTrue(imgVol.Config()["encryption"]) != shared.IsTrue(tmpImgVol.Config()["encryption"])
+
 		// If the existing image volume no longer matches the pool's settings for new volumes then we need
 		// to delete and re-create it.
-		if blockModeChanged || blockFSChanged {
+		if blockModeChanged || blockFSChanged || encryptionChanged {
 			if blockModeChanged {
 				l.Debug("Block mode has changed, regenerating image volume")
+			} else if encryptionChanged {
+				l.Debug("Encryption of pool has changed since cached image volume created, regenerating image volume")
 			} else {
 				l.Debug("Block volume filesystem of pool has changed since cached image volume created, regenerating image volume")
 			}
@@ -4191,8 +4602,8 @@ func (b *lxdBackend) shouldUseOptimizedImage(fingerprint string, contentType dri
 	return true, nil
 }
 
-// volumeConfigsMatch checks if the block-backed modes of two volumes match, and if they are block-backed, ensures
-// their filesystem configurations are also identical.
+// volumeConfigsMatch checks if the block-backed modes and encryption of two volumes match, and if they are
+// block-backed, ensures their filesystem configurations are also identical.
 func volumeConfigsMatch(vol1, vol2 drivers.Volume) bool {
 	blockModeChanged := vol1.IsBlockBacked() != vol2.IsBlockBacked()
 	blockFSChanged := vol1.IsBlockBacked() && vol1.Config()["block.filesystem"] != vol2.Config()["block.filesystem"]
@@ -4205,7 +4616,9 @@ func volumeConfigsMatch(vol1, vol2 drivers.Volume) bool {
 	// they're considered unequal ("" != "8KiB"), preventing the use of a matching optimized image.
 	blockSizeChanged := vol1.IsBlockBacked() && vol1.Config()["zfs.blocksize"] != vol2.Config()["zfs.blocksize"]
 
-	return !blockModeChanged && !blockFSChanged && !blockSizeChanged
+	encryptionChanged := shared.IsTrue(vol1.Config()["encryption"]) != shared.IsTrue(vol2.Config()["encryption"])
+
+	return !blockModeChanged && !blockFSChanged && !blockSizeChanged && !encryptionChanged
 }
 
 // DeleteImage removes an image from the database and underlying storage device if needed.
@@ -5182,6 +5595,11 @@ func (b *lxdBackend) CreateCustomVolume(projectName string, volName string, desc
 
 	revert.Add(func() { _ = VolumeDBDelete(b, projectName, volName, vol.Type()) })
 
+	err = b.checkOvercommit(projectName, vol.Type(), volName, vol.ConfigSize())
+	if err != nil {
+		return err
+	}
+
 	// Create the empty custom volume on the storage device.
 	err = b.driver.CreateVolume(vol, nil, op)
 	if err != nil {
@@ -5314,6 +5732,11 @@ func (b *lxdBackend) CreateCustomVolumeFromCopy(projectName string, srcProjectNa
 
 		revert.Add(func() { _ = VolumeDBDelete(b, projectName, volName, vol.Type()) })
 
+		err = b.checkOvercommit(projectName, vol.Type(), volName, vol.ConfigSize())
+		if err != nil {
+			return err
+		}
+
 		targetSnapshots := make([]drivers.Volume, 0, len(snapshotNames))
 
 		// Create database entries for new storage volume snapshots.
@@ -5363,7 +5786,7 @@ func (b *lxdBackend) CreateCustomVolumeFromCopy(projectName string, srcProjectNa
 	l.Debug("CreateCustomVolumeFromCopy cross-pool mode detected")
 
 	// Negotiate the migration type to use.
-	offeredTypes := srcPool.MigrationTypes(contentType, false, snapshots)
+	offeredTypes := EncryptedMigrationTypes(srcPool.MigrationTypes(contentType, false, snapshots), srcConfig.Volume.Config, config)
 	offerHeader := migration.TypesToHeader(offeredTypes...)
 	migrationTypes, err := migration.MatchTypes(offerHeader, FallbackMigrationType(contentType), b.MigrationTypes(contentType, false, snapshots))
 	if err != nil {
@@ -5413,6 +5836,7 @@ func (b *lxdBackend) CreateCustomVolumeFromCopy(projectName string, srcProjectNa
 			Name:               srcConfig.Volume.Name,
 			Snapshots:          snapshotNames,
 			MigrationType:      migrationTypes[0],
+			BlockDelta:         true, // Only transfer the changes between snapshots of block volumes.
 			TrackProgress:      true, // Do use a progress tracker on sender.
 			ContentType:        string(contentType),
 			Info:               &migration.Info{Config: srcConfig},
@@ -5434,6 +5858,7 @@ func (b *lxdBackend) CreateCustomVolumeFromCopy(projectName string, srcProjectNa
 			Config:             config,
 			Snapshots:          snapshotNames,
 			MigrationType:      migrationTypes[0],
+			BlockDelta:         true,  // Only transfer the changes between snapshots of block volumes.
 			TrackProgress:      false, // Do not use a progress tracker on receiver.
 			ContentType:        string(contentType),
 			VolumeSize:         volSize, // Block size setting override.
@@ -5855,7 +6280,7 @@ func (b *lxdBackend) RenameCustomVolume(projectName string, volName string, newV
 		backupRow := br // Local var for revert.
 		_, backupName, _ := api.GetParentAndSnapshotName(backupRow.Name)
 		newVolBackupName := drivers.GetSnapshotVolumeName(newVolName, backupName)
-		volBackup := backup.NewVolumeBackup(b.state, projectName, b.name, volName, backupRow.ID, backupRow.Name, backupRow.CreationDate, backupRow.ExpiryDate, backupRow.VolumeOnly, backupRow.OptimizedStorage)
+		volBackup := backup.NewVolumeBackup(b.state, projectName, b.name, volName, backupRow.ID, backupRow.Name, backupRow.CreationDate, backupRow.ExpiryDate, backupRow.VolumeOnly, backupRow.OptimizedStorage, backupRow.IncrementalFrom)
 		err = volBackup.Rename(newVolBackupName)
 		if err != nil {
 			return fmt.Errorf("Failed renaming backup %q to %q: %w", backupRow.Name, newVolBackupName, err)
@@ -6039,6 +6464,13 @@ func (b *lxdBackend) UpdateCustomVolume(projectName string, volName string, newD
 			}
 		}
 
+		if changedConfig["size"] != "" {
+			err = b.checkOvercommit(projectName, drivers.VolumeTypeCustom, volName, newVol.ConfigSize())
+			if err != nil {
+				return err
+			}
+		}
+
 		curVol := b.GetVolume(drivers.VolumeTypeCustom, contentType, volStorageName, curVol.Config)
 		if !userOnly {
 			err = b.driver.UpdateVolume(curVol, changedConfig)
@@ -6690,6 +7122,43 @@ func (b *lxdBackend) RestoreCustomVolume(projectName, volName string, snapshotNa
 	return nil
 }
 
+// VerifyCustomVolume checks the integrity of a custom volume.
+func (b *lxdBackend) VerifyCustomVolume(projectName string, volName string, op *operations.Operation) (*drivers.VolumeVerifyResult, error) {
+	l := b.logger.AddContext(logger.Ctx{"project": projectName, "volName": volName})
+	l.Debug("VerifyCustomVolume started")
+	defer l.Debug("VerifyCustomVolume finished")
+
+	err := b.isStatusReady()
+	if err != nil {
+		return nil, err
+	}
+
+	if shared.IsSnapshot(volName) {
+		return nil, fmt.Errorf("Volume cannot be snapshot")
+	}
+
+	// Load storage volume from database.
+	dbVol, err := VolumeDBGet(b, projectName, volName, drivers.VolumeTypeCustom)
+	if err != nil {
+		return nil, err
+	}
+
+	dbContentType, err := VolumeContentTypeNameToContentType(dbVol.ContentType)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType, err := VolumeDBContentTypeToContentType(dbContentType)
+	if err != nil {
+		return nil, err
+	}
+
+	volStorageName := project.StorageVolume(projectName, volName)
+	vol := b.GetVolume(drivers.VolumeTypeCustom, contentType, volStorageName, dbVol.Config)
+
+	return b.verifyVolume(vol, op)
+}
+
 func (b *lxdBackend) createStorageStructure(path string) error {
 	for _, volType := range b.driver.Info().VolumeTypes {
 		for _, name := range drivers.BaseDirectories[volType] {
@@ -6764,8 +7233,11 @@ func (b *lxdBackend) GenerateInstanceBackupConfig(inst instance.Instance, snapsh
 		return nil, err
 	}
 
+	// Use the API representation of the pool so that its internal config isn't written to the backup file.
+	pool := b.ToAPI()
+
 	config := &backupConfig.Config{
-		Pool:   &b.db,
+		Pool:   &pool,
 		Volume: &volume.StorageVolume,
 	}
 
@@ -7496,8 +7968,9 @@ func (b *lxdBackend) ImportInstance(inst instance.Instance, poolVol *backupConfi
 }
 
 // BackupCustomVolume creates a backup of an existing custom volume.
-func (b *lxdBackend) BackupCustomVolume(projectName string, volName string, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots bool, op *operations.Operation) error {
-	l := b.logger.AddContext(logger.Ctx{"project": projectName, "volume": volName, "optimized": optimized, "snapshots": snapshots})
+// If incrementalFrom is set, only the changes since that snapshot are included in the backup.
+func (b *lxdBackend) BackupCustomVolume(projectName string, volName string, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots bool, incrementalFrom string, op *operations.Operation) error {
+	l := b.logger.AddContext(logger.Ctx{"project": projectName, "volume": volName, "optimized": optimized, "snapshots": snapshots, "incrementalFrom": incrementalFrom})
 	l.Debug("BackupCustomVolume started")
 	defer l.Debug("BackupCustomVolume finished")
 
@@ -7525,21 +7998,34 @@ func (b *lxdBackend) BackupCustomVolume(projectName string, volName string, tarW
 
 	var snapNames []string
 	var sourceSnapshots []drivers.Volume
-	if snapshots {
+	if snapshots || incrementalFrom != "" {
 		// Get snapshots in age order, oldest first, and pass names to storage driver.
 		volSnaps, err := VolumeDBSnapshotsGet(b, projectName, volName, drivers.VolumeTypeCustom)
 		if err != nil {
 			return err
 		}
 
+		// For incremental backups, only the snapshots taken after the one the backup is incremental from
+		// are included, but the storage driver still needs all of them to find the parents.
+		foundBase := incrementalFrom == ""
+
 		snapNames = make([]string, 0, len(volSnaps))
 		sourceSnapshots = make([]drivers.Volume, 0, len(volSnaps))
 		for _, volSnap := range volSnaps {
 			_, snapName, _ := api.GetParentAndSnapshotName(volSnap.Name)
-			snapNames = append(snapNames, snapName)
 
 			snapshotStorageName := project.StorageVolume(projectName, volSnap.Name)
 			sourceSnapshots = append(sourceSnapshots, b.GetVolume(drivers.VolumeTypeCustom, contentType, snapshotStorageName, volSnap.Config))
+
+			if snapName == incrementalFrom {
+				foundBase = true
+			} else if snapshots && foundBase {
+				snapNames = append(snapNames, snapName)
+			}
+		}
+
+		if !foundBase {
+			return api.StatusErrorf(http.StatusNotFound, "Snapshot %q to create incremental backup from not found", incrementalFrom)
 		}
 	}
 
@@ -7547,7 +8033,7 @@ func (b *lxdBackend) BackupCustomVolume(projectName string, volName string, tarW
 
 	volCopy := drivers.NewVolumeCopy(vol, sourceSnapshots...)
 
-	err = b.driver.BackupVolume(volCopy, tarWriter, optimized, snapNames, op)
+	err = b.driver.BackupVolume(volCopy, tarWriter, optimized, snapNames, incrementalFrom, op)
 	if err != nil {
 		return err
 	}
@@ -7758,3 +8244,164 @@ func (b *lxdBackend) CreateCustomVolumeFromBackup(srcBackup backup.Info, srcData
 	revert.Success()
 	return nil
 }
+
+// RefreshCustomVolumeFromBackup applies an incremental backup to an existing custom volume.
+// The snapshot the backup is incremental from must be the latest snapshot of the volume.
+func (b *lxdBackend) RefreshCustomVolumeFromBackup(srcBackup backup.Info, srcData io.ReadSeeker, op *operations.Operation) error {
+	l := b.logger.AddContext(logger.Ctx{"project": srcBackup.Project, "volume": srcBackup.Name, "snapshots": srcBackup.Snapshots, "incrementalFrom": srcBackup.IncrementalFrom})
+	l.Debug("RefreshCustomVolumeFromBackup started")
+	defer l.Debug("RefreshCustomVolumeFromBackup finished")
+
+	if srcBackup.IncrementalFrom == "" {
+		return fmt.Errorf("Backup isn't incremental")
+	}
+
+	if srcBackup.Config == nil || srcBackup.Config.Volume == nil {
+		return fmt.Errorf("Valid volume config not found in index")
+	}
+
+	if len(srcBackup.Snapshots) != len(srcBackup.Config.VolumeSnapshots) {
+		return fmt.Errorf("Valid volume snapshot config not found in index")
+	}
+
+	// Validate the names in the index.yaml file as these could be malicious.
+	err := ValidVolumeName(srcBackup.Name)
+	if err != nil {
+		return err
+	}
+
+	err = ValidVolumeName(srcBackup.IncrementalFrom)
+	if err != nil {
+		return err
+	}
+
+	for _, snapName := range srcBackup.Snapshots {
+		err = ValidVolumeName(snapName)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Get current volume.
+	curVol, err := VolumeDBGet(b, srcBackup.Project, srcBackup.Name, drivers.VolumeTypeCustom)
+	if err != nil {
+		return err
+	}
+
+	if curVol.ContentType != srcBackup.Config.Volume.ContentType {
+		return fmt.Errorf("Content type of backup %q doesn't match volume content type %q", srcBackup.Config.Volume.ContentType, curVol.ContentType)
+	}
+
+	// Check that the volume isn't in use by running instances.
+	err = VolumeUsedByInstanceDevices(b.state, b.Name(), srcBackup.Project, &curVol.StorageVolume, true, func(dbInst db.InstanceArgs, project api.Project, usedByDevices []string) error {
+		inst, err := instance.Load(b.state, dbInst, project)
+		if err != nil {
+			return err
+		}
+
+		if inst.IsRunning() {
+			return fmt.Errorf("Cannot apply incremental backup to custom volume used by running instances")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	dbContentType, err := VolumeContentTypeNameToContentType(curVol.ContentType)
+	if err != nil {
+		return err
+	}
+
+	contentType, err := VolumeDBContentTypeToContentType(dbContentType)
+	if err != nil {
+		return err
+	}
+
+	// Check the snapshot the backup is incremental from is the latest snapshot of the volume.
+	volSnaps, err := VolumeDBSnapshotsGet(b, srcBackup.Project, srcBackup.Name, drivers.VolumeTypeCustom)
+	if err != nil {
+		return err
+	}
+
+	if len(volSnaps) == 0 || volSnaps[len(volSnaps)-1].Name != drivers.GetSnapshotVolumeName(srcBackup.Name, srcBackup.IncrementalFrom) {
+		return api.StatusErrorf(http.StatusBadRequest, "Snapshot %q the backup is incremental from must be the latest snapshot of the volume", srcBackup.IncrementalFrom)
+	}
+
+	baseSnap := volSnaps[len(volSnaps)-1]
+
+	// Check the snapshot is the one of the parent backup and not just one with the same name.
+	if srcBackup.IncrementalFromCreatedAt != nil && baseSnap.CreationDate.Unix() != srcBackup.IncrementalFromCreatedAt.Unix() {
+		return api.StatusErrorf(http.StatusBadRequest, "Snapshot %q of the volume wasn't restored from the parent backup %q", srcBackup.IncrementalFrom, srcBackup.IncrementalParent)
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Get the volume name on storage.
+	volStorageName := project.StorageVolume(srcBackup.Project, srcBackup.Name)
+	vol := b.GetVolume(drivers.VolumeTypeCustom, contentType, volStorageName, curVol.Config)
+
+	baseSnapStorageName := project.StorageVolume(srcBackup.Project, baseSnap.Name)
+	sourceSnapshots := []drivers.Volume{b.GetVolume(drivers.VolumeTypeCustom, contentType, baseSnapStorageName, baseSnap.Config)}
+
+	// Create database entries for new storage volume snapshots.
+	for i, snapName := range srcBackup.Snapshots {
+		snapshot := srcBackup.Config.VolumeSnapshots[i]
+		if snapshot == nil {
+			return fmt.Errorf("Valid volume snapshot config not found in index for snapshot %q", snapName)
+		}
+
+		// The snapshot names in the index were validated above, so only use the config with a matching name.
+		configSnapName := snapshot.Name
+		if shared.IsSnapshot(snapshot.Name) {
+			_, configSnapName, _ = api.GetParentAndSnapshotName(snapshot.Name)
+		}
+
+		if configSnapName != snapName {
+			return fmt.Errorf("Valid volume snapshot config not found in index for snapshot %q", snapName)
+		}
+
+		var expiryDate time.Time
+		if snapshot.ExpiresAt != nil {
+			expiryDate = *snapshot.ExpiresAt
+		}
+
+		fullSnapName := drivers.GetSnapshotVolumeName(srcBackup.Name, snapName)
+		snapVolStorageName := project.StorageVolume(srcBackup.Project, fullSnapName)
+		snapVol := b.GetNewVolume(drivers.VolumeTypeCustom, contentType, snapVolStorageName, snapshot.Config)
+
+		// Validate config and create database entry for new storage volume.
+		// Strip unsupported config keys (in case the export was made from a different type of storage pool).
+		err = VolumeDBCreate(b, srcBackup.Project, fullSnapName, snapshot.Description, snapVol.Type(), true, snapVol.Config(), snapshot.CreatedAt, expiryDate, snapVol.ContentType(), true, true)
+		if err != nil {
+			return err
+		}
+
+		revert.Add(func() { _ = VolumeDBDelete(b, srcBackup.Project, fullSnapName, snapVol.Type()) })
+
+		sourceSnapshots = append(sourceSnapshots, snapVol)
+	}
+
+	volCopy := drivers.NewVolumeCopy(vol, sourceSnapshots...)
+
+	// Apply the backup to the existing storage volume(s).
+	volPostHook, revertHook, err := b.driver.CreateVolumeFromBackup(volCopy, srcBackup, srcData, op)
+	if err != nil {
+		return err
+	}
+
+	if revertHook != nil {
+		revert.Add(revertHook)
+	}
+
+	if volPostHook != nil {
+		return fmt.Errorf("Custom volume restore doesn't support post hooks")
+	}
+
+	b.state.Events.SendLifecycle(srcBackup.Project, lifecycle.StorageVolumeRestored.Event(vol, string(vol.Type()), srcBackup.Project, op, logger.Ctx{"incrementalFrom": srcBackup.IncrementalFrom}))
+
+	revert.Success()
+	return nil
+}
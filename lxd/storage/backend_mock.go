@@ -223,10 +223,15 @@ func (b *mockBackend) RefreshInstance(inst instance.Instance, src instance.Insta
 }
 
 // BackupInstance ...
-func (b *mockBackend) BackupInstance(inst instance.Instance, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots bool, op *operations.Operation) error {
+func (b *mockBackend) BackupInstance(inst instance.Instance, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots bool, incrementalFrom string, op *operations.Operation) error {
 	return nil
 }
 
+// RefreshInstanceFromBackup ...
+func (b *mockBackend) RefreshInstanceFromBackup(inst instance.Instance, srcBackup backup.Info, srcData io.ReadSeeker, op *operations.Operation) (revert.Hook, error) {
+	return nil, nil
+}
+
 // GetInstanceUsage ...
 func (b *mockBackend) GetInstanceUsage(inst instance.Instance) (*VolumeUsage, error) {
 	return nil, nil
@@ -237,6 +242,11 @@ func (b *mockBackend) SetInstanceQuota(inst instance.Instance, size string, vmSt
 	return nil
 }
 
+// VerifyInstance ...
+func (b *mockBackend) VerifyInstance(inst instance.Instance, op *operations.Operation) (*drivers.VolumeVerifyResult, error) {
+	return &drivers.VolumeVerifyResult{}, nil
+}
+
 // MountInstance ...
 func (b *mockBackend) MountInstance(inst instance.Instance, op *operations.Operation) (*MountInfo, error) {
 	return &MountInfo{}, nil
@@ -428,7 +438,7 @@ func (b *mockBackend) RestoreCustomVolume(projectName string, volName string, sn
 }
 
 // BackupCustomVolume ...
-func (b *mockBackend) BackupCustomVolume(projectName string, volName string, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots bool, op *operations.Operation) error {
+func (b *mockBackend) BackupCustomVolume(projectName string, volName string, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots bool, incrementalFrom string, op *operations.Operation) error {
 	return nil
 }
 
@@ -437,7 +447,17 @@ func (b *mockBackend) CreateCustomVolumeFromBackup(srcBackup backup.Info, srcDat
 	return nil
 }
 
+// RefreshCustomVolumeFromBackup ...
+func (b *mockBackend) RefreshCustomVolumeFromBackup(srcBackup backup.Info, srcData io.ReadSeeker, op *operations.Operation) error {
+	return nil
+}
+
 // CreateCustomVolumeFromISO ...
 func (b *mockBackend) CreateCustomVolumeFromISO(projectName string, volName string, srcData io.ReadSeeker, size int64, op *operations.Operation) error {
 	return nil
 }
+
+// VerifyCustomVolume ...
+func (b *mockBackend) VerifyCustomVolume(projectName string, volName string, op *operations.Operation) (*drivers.VolumeVerifyResult, error) {
+	return &drivers.VolumeVerifyResult{}, nil
+}
@@ -151,7 +151,7 @@ func (d *btrfs) CreateVolume(vol Volume, filler *VolumeFiller, op *operations.Op
 func (d *btrfs) CreateVolumeFromBackup(vol VolumeCopy, srcBackup backup.Info, srcData io.ReadSeeker, op *operations.Operation) (VolumePostHook, revert.Hook, error) {
 	// Handle the non-optimized tarballs through the generic unpacker.
 	if !*srcBackup.OptimizedStorage {
-		return genericVFSBackupUnpack(d, d.state.OS, vol, srcBackup.Snapshots, srcData, op)
+		return genericVFSBackupUnpack(d, d.state.OS, vol, srcBackup.Snapshots, srcBackup.IncrementalFrom, srcData, op)
 	}
 
 	volExists, err := d.HasVolume(vol.Volume)
@@ -159,7 +159,10 @@ func (d *btrfs) CreateVolumeFromBackup(vol VolumeCopy, srcBackup backup.Info, sr
 		return nil, nil, err
 	}
 
-	if volExists {
+	// Incremental backups are received on top of the existing volume.
+	if srcBackup.IncrementalFrom != "" && !volExists {
+		return nil, nil, fmt.Errorf("Cannot apply incremental backup, volume doesn't exist on target")
+	} else if srcBackup.IncrementalFrom == "" && volExists {
 		return nil, nil, fmt.Errorf("Cannot restore volume, already exists on target")
 	}
 
@@ -175,7 +178,17 @@ func (d *btrfs) CreateVolumeFromBackup(vol VolumeCopy, srcBackup backup.Info, sr
 			_ = d.DeleteVolumeSnapshot(snapVol, op)
 		}
 
-		// And lastly the main volume.
+		// And lastly the main volume, which for incremental backups is reset to the snapshot the
+		// backup is incremental from instead.
+		if srcBackup.IncrementalFrom != "" {
+			baseSnapVol, err := vol.NewSnapshot(srcBackup.IncrementalFrom)
+			if err == nil {
+				_ = d.RestoreVolume(vol.Volume, baseSnapVol, op)
+			}
+
+			return
+		}
+
 		_ = d.DeleteVolume(vol.Volume, op)
 	}
 	// Only execute the revert function if we have had an error internally.
@@ -267,8 +280,9 @@ func (d *btrfs) CreateVolumeFromBackup(vol VolumeCopy, srcBackup backup.Info, sr
 	}
 
 	type btrfsCopyOp struct {
-		src  string
-		dest string
+		src          string
+		dest         string
+		receivedUUID string
 	}
 
 	var copyOps []btrfsCopyOp
@@ -308,9 +322,20 @@ func (d *btrfs) CreateVolumeFromBackup(vol VolumeCopy, srcBackup backup.Info, sr
 				return err
 			}
 
+			receivedVol := Volume{
+				pool:            d.name,
+				mountCustomPath: unpackedSubVolPath,
+			}
+
+			UUID, err := d.getSubVolumeReceivedUUID(receivedVol)
+			if err != nil {
+				return fmt.Errorf("Failed getting UUID: %w", err)
+			}
+
 			copyOps = append(copyOps, btrfsCopyOp{
-				src:  unpackedSubVolPath,
-				dest: subVolTargetPath,
+				src:          unpackedSubVolPath,
+				dest:         subVolTargetPath,
+				receivedUUID: UUID,
 			})
 		}
 
@@ -363,6 +388,14 @@ func (d *btrfs) CreateVolumeFromBackup(vol VolumeCopy, srcBackup backup.Info, sr
 		return nil, nil, err
 	}
 
+	if srcBackup.IncrementalFrom != "" {
+		// Delete main volume after receiving it.
+		err = d.deleteSubvolume(vol.MountPath(), true)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
 	for _, copyOp := range copyOps {
 		err = d.setSubvolumeReadonlyProperty(copyOp.src, false)
 		if err != nil {
@@ -377,6 +410,15 @@ func (d *btrfs) CreateVolumeFromBackup(vol VolumeCopy, srcBackup backup.Info, sr
 		if err != nil {
 			return nil, nil, err
 		}
+
+		// Preserve the "Received UUID" field lost when making the subvolume read-write so that the
+		// subvolume can be used as the parent of later incremental backups.
+		if copyOp.receivedUUID != "" {
+			err = setReceivedUUID(copyOp.dest, copyOp.receivedUUID)
+			if err != nil {
+				return nil, nil, fmt.Errorf("Failed setting received UUID: %w", err)
+			}
+		}
 	}
 
 	// Restore readonly property on subvolumes that need it.
@@ -1400,7 +1442,7 @@ func (d *btrfs) migrateVolumeOptimized(vol Volume, conn io.ReadWriteCloser, volS
 
 // BackupVolume copies a volume (and optionally its snapshots) to a specified target path.
 // This driver does not support optimized backups.
-func (d *btrfs) BackupVolume(vol VolumeCopy, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots []string, op *operations.Operation) error {
+func (d *btrfs) BackupVolume(vol VolumeCopy, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots []string, incrementalFrom string, op *operations.Operation) error {
 	// Handle the non-optimized tarballs through the generic packer.
 	if !optimized {
 		// Because the generic backup method will not take a consistent backup if files are being modified
@@ -1419,12 +1461,12 @@ func (d *btrfs) BackupVolume(vol VolumeCopy, tarWriter *instancewriter.InstanceT
 			vol.mountCustomPath = snapshotPath
 		}
 
-		return genericVFSBackupVolume(d, vol, tarWriter, snapshots, op)
+		return genericVFSBackupVolume(d, vol, tarWriter, snapshots, incrementalFrom, op)
 	}
 
 	// Optimized backup.
 
-	if len(snapshots) > 0 {
+	if len(snapshots) > 0 || incrementalFrom != "" {
 		// Check requested snapshot match those in storage.
 		err := d.CheckVolumeSnapshots(vol.Volume, vol.Snapshots, op)
 		if err != nil {
@@ -1574,6 +1616,17 @@ func (d *btrfs) BackupVolume(vol VolumeCopy, tarWriter *instancewriter.InstanceT
 
 	// Backup snapshots if populated.
 	lastVolPath := "" // Used as parent for differential exports.
+	if incrementalFrom != "" {
+		// For incremental backups, the first subvolume is sent relative to the snapshot the backup is
+		// incremental from.
+		baseSnapVol, err := vol.NewSnapshot(incrementalFrom)
+		if err != nil {
+			return err
+		}
+
+		lastVolPath = baseSnapVol.MountPath()
+	}
+
 	for _, snapName := range snapshots {
 		snapVol, _ := vol.NewSnapshot(snapName)
 
@@ -1869,6 +1922,30 @@ func (d *btrfs) RestoreVolume(vol Volume, snapVol Volume, op *operations.Operati
 	return d.deleteSubvolume(backupSubvolume, true)
 }
 
+// VerifyVolume runs a read-only scrub of the pool and reports any checksum errors it found.
+// Btrfs scrubs the whole filesystem so errors can't be attributed to a single volume.
+func (d *btrfs) VerifyVolume(vol Volume, op *operations.Operation) (*VolumeVerifyResult, error) {
+	out, err := shared.RunCommandCLocale("btrfs", "scrub", "start", "-B", "-r", GetPoolMountPath(d.name))
+	if err != nil && !strings.Contains(out, "Error summary:") {
+		return nil, err
+	}
+
+	result := &VolumeVerifyResult{Problems: []string{}}
+	for _, line := range strings.Split(out, "\n") {
+		summary, found := strings.CutPrefix(strings.TrimSpace(line), "Error summary:")
+		if !found {
+			continue
+		}
+
+		summary = strings.TrimSpace(summary)
+		if summary != "no errors found" {
+			result.Problems = append(result.Problems, fmt.Sprintf("Scrub of pool %q found errors: %s", d.name, summary))
+		}
+	}
+
+	return result, nil
+}
+
 // RenameVolumeSnapshot renames a volume snapshot.
 func (d *btrfs) RenameVolumeSnapshot(snapVol Volume, newSnapshotName string, op *operations.Operation) error {
 	return genericVFSRenameVolumeSnapshot(d, snapVol, newSnapshotName, op)
@@ -112,7 +112,7 @@ func (d *ceph) FillConfig() error {
 		d.config["ceph.osd.pg_num"] = "32"
 	}
 
-	return nil
+	return luksFillConfig(d.config)
 }
 
 // Create is called during pool creation and is effectively using an empty driver struct.
@@ -353,11 +353,20 @@ func (d *ceph) Validate(config map[string]string) error {
 		"volatile.pool.pristine": validate.IsAny,
 	}
 
+	for k, v := range luksPoolRules() {
+		rules[k] = v
+	}
+
 	return d.validatePool(config, rules, d.commonVolumeRules())
 }
 
 // Update applies any driver changes required from a configuration change.
 func (d *ceph) Update(changedConfig map[string]string) error {
+	_, changed := changedConfig["volatile.encryption.key"]
+	if changed {
+		return fmt.Errorf("volatile.encryption.key cannot be changed")
+	}
+
 	return nil
 }
 
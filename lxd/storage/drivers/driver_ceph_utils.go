@@ -110,6 +110,9 @@ func (d *ceph) rbdCreateVolume(vol Volume, size string) error {
 		return err
 	}
 
+	// Reserve space for the encryption header.
+	sizeBytes = d.luksSizeBytes(vol, sizeBytes)
+
 	cmd := []string{
 		"--id", d.config["ceph.user.name"],
 		"--cluster", d.config["ceph.cluster_name"],
@@ -192,8 +195,14 @@ func (d *ceph) rbdUnmapVolume(vol Volume, unmapUntilEINVAL bool) error {
 
 	ourDeactivate := false
 
+	// Close the encrypted volume (if open) so that the RBD device isn't held open.
+	_, err := d.luksClose(vol)
+	if err != nil {
+		return err
+	}
+
 again:
-	_, err := shared.RunCommand(
+	_, err = shared.RunCommand(
 		"rbd",
 		"--id", d.config["ceph.user.name"],
 		"--cluster", d.config["ceph.cluster_name"],
@@ -540,6 +549,52 @@ func (d *ceph) rbdDeleteVolumeSnapshot(vol Volume, snapshotName string) error {
 	return nil
 }
 
+// blockVolumeDiff returns the extents of an RBD storage volume (or snapshot) that changed since the parent
+// snapshot, as reported by "rbd diff". This is not supported for encrypted volumes as the extents of the
+// underlying RBD image don't match those of the decrypted block device.
+func (d *ceph) blockVolumeDiff(vol Volume, parentVol Volume) ([]blockDeltaExtent, error) {
+	if d.volumeEncrypted(vol) {
+		return nil, ErrNotSupported
+	}
+
+	_, parentSnapName, _ := api.GetParentAndSnapshotName(parentVol.name)
+
+	msg, err := shared.RunCommand(
+		"rbd",
+		"--id", d.config["ceph.user.name"],
+		"--cluster", d.config["ceph.cluster_name"],
+		"--pool", d.config["ceph.osd.pool_name"],
+		"--format", "json",
+		"diff",
+		"--from-snap", fmt.Sprintf("snapshot_%s", parentSnapName),
+		d.getRBDVolumeName(vol, "", false, false))
+	if err != nil {
+		return nil, err
+	}
+
+	var data []struct {
+		Offset int64  `json:"offset"`
+		Length int64  `json:"length"`
+		Exists string `json:"exists"`
+	}
+
+	err = json.Unmarshal([]byte(msg), &data)
+	if err != nil {
+		return nil, err
+	}
+
+	extents := make([]blockDeltaExtent, 0, len(data))
+	for _, entry := range data {
+		extents = append(extents, blockDeltaExtent{
+			offset: entry.Offset,
+			length: entry.Length,
+			zero:   entry.Exists != "true",
+		})
+	}
+
+	return extents, nil
+}
+
 // rbdListVolumeSnapshots retrieves the snapshots of an RBD storage volume.
 // The format of the snapshot names is simply the part after the @. So given a
 // valid RBD path relative to a pool
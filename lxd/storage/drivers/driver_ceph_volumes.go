@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -90,6 +91,8 @@ func (d *ceph) CreateVolume(vol Volume, filler *VolumeFiller, op *operations.Ope
 				return err
 			}
 
+			poolVolSizeBytes = d.luksSizeBytes(vol, poolVolSizeBytes)
+
 			// If the cached volume size is different than the pool volume size, then we can't use the
 			// deleted cached image volume and instead we will rename it to a random UUID so it can't
 			// be restored in the future and a new cached image volume will be created instead.
@@ -153,6 +156,21 @@ func (d *ceph) CreateVolume(vol Volume, filler *VolumeFiller, op *operations.Ope
 
 	revert.Add(func() { _ = d.rbdUnmapVolume(vol, true) })
 
+	// Format and open the encrypted volume if needed and use its decrypted device from here on.
+	if d.volumeEncrypted(vol) {
+		err = d.luksFormat(vol, devPath)
+		if err != nil {
+			return err
+		}
+
+		_, err = d.luksOpen(vol, devPath, false)
+		if err != nil {
+			return err
+		}
+
+		devPath = d.luksDevPath(vol)
+	}
+
 	// Get filesystem.
 	RBDFilesystem := vol.ConfigBlockFilesystem()
 
@@ -316,7 +334,7 @@ func (d *ceph) getVolumeSize(volumeName string) (int64, error) {
 
 // CreateVolumeFromBackup re-creates a volume from its exported state.
 func (d *ceph) CreateVolumeFromBackup(vol VolumeCopy, srcBackup backup.Info, srcData io.ReadSeeker, op *operations.Operation) (VolumePostHook, revert.Hook, error) {
-	return genericVFSBackupUnpack(d, d.state.OS, vol, srcBackup.Snapshots, srcData, op)
+	return genericVFSBackupUnpack(d, d.state.OS, vol, srcBackup.Snapshots, srcBackup.IncrementalFrom, srcData, op)
 }
 
 // CreateVolumeFromCopy provides same-pool volume copying functionality.
@@ -325,6 +343,28 @@ func (d *ceph) CreateVolumeFromCopy(vol VolumeCopy, srcVol VolumeCopy, allowInco
 	revert := revert.New()
 	defer revert.Fail()
 
+	// Copies of RBD volumes keep the encryption of their source, so use the generic copy if the encryption
+	// requested for the new volume differs.
+	if d.volumeEncrypted(vol.Volume) != d.volumeEncrypted(srcVol.Volume) {
+		var srcSnapshots []string
+
+		if len(vol.Snapshots) > 0 && !srcVol.IsSnapshot() {
+			// Get the list of snapshots from the source.
+			allSrcSnapshots, err := srcVol.Volume.Snapshots(op)
+			if err != nil {
+				return err
+			}
+
+			for _, srcSnapshot := range allSrcSnapshots {
+				_, snapshotName, _ := api.GetParentAndSnapshotName(srcSnapshot.name)
+				srcSnapshots = append(srcSnapshots, snapshotName)
+			}
+		}
+
+		_, err = genericVFSCopyVolume(d, nil, vol, srcVol, srcSnapshots, false, allowInconsistent, op)
+		return err
+	}
+
 	// Function to run once the volume is created, which will regenerate the filesystem UUID (if needed),
 	// ensure permissions on mount path inside the volume are correct, and resize the volume to specified size.
 	postCreateTasks := func(v Volume) error {
@@ -339,7 +379,9 @@ func (d *ceph) CreateVolumeFromCopy(vol VolumeCopy, srcVol VolumeCopy, allowInco
 		if vol.contentType == ContentTypeFS {
 			// Re-generate the UUID. Do this first as ensuring permissions and setting quota can
 			// rely on being able to mount the volume.
-			err = d.generateUUID(v.ConfigBlockFilesystem(), devPath)
+			err = d.luksTask(v, devPath, func(devPath string) error {
+				return d.generateUUID(v.ConfigBlockFilesystem(), devPath)
+			})
 			if err != nil {
 				return err
 			}
@@ -751,8 +793,9 @@ func (d *ceph) CreateVolumeFromMigration(vol VolumeCopy, conn io.ReadWriteCloser
 // refreshVolume updates an existing volume to match the state of another.
 // It returns the cleanup hooks required to revert any changes made during the refresh.
 func (d *ceph) refreshVolume(vol VolumeCopy, srcVol VolumeCopy, refreshSnapshots []string, allowInconsistent bool, op *operations.Operation) (revert.Hook, error) {
-	// Copy volumes with content type filesystem using the generic approach.
-	if vol.contentType == ContentTypeFS {
+	// Copy volumes with content type filesystem or whose encryption differs from the source using the
+	// generic approach.
+	if vol.contentType == ContentTypeFS || d.volumeEncrypted(vol.Volume) != d.volumeEncrypted(srcVol.Volume) {
 		return genericVFSCopyVolume(d, nil, vol, srcVol, refreshSnapshots, true, allowInconsistent, op)
 	}
 
@@ -1120,7 +1163,14 @@ func (d *ceph) FillVolumeConfig(vol Volume) error {
 	// Copy volume.* configuration options from pool.
 	// Exclude 'block.filesystem' and 'block.mount_options'
 	// as this ones are handled below in this function and depends from volume type
-	err := d.fillVolumeConfig(&vol, "block.filesystem", "block.mount_options")
+	excludedKeys := []string{"block.filesystem", "block.mount_options"}
+
+	// Volumes created from a source keep the encryption of their source.
+	if vol.hasSource {
+		excludedKeys = append(excludedKeys, "encryption")
+	}
+
+	err := d.fillVolumeConfig(&vol, excludedKeys...)
 	if err != nil {
 		return err
 	}
@@ -1179,6 +1229,15 @@ func (d *ceph) commonVolumeRules() map[string]func(value string) error {
 		//  defaultdesc: same as `volume.block.mount_options`
 		//  shortdesc: Mount options for block-backed file system volumes
 		"block.mount_options": validate.IsAny,
+		// lxdmeta:generate(entities=storage-ceph,storage-lvm; group=volume-conf; key=encryption)
+		// When enabled, the volume is encrypted with LUKS using a key that is held by LXD in the storage
+		// pool configuration (see `encryption.project_keys`).
+		// This option cannot be changed after the volume has been created.
+		// ---
+		//  type: bool
+		//  defaultdesc: same as `volume.encryption` or `false`
+		//  shortdesc: Whether to encrypt the storage volume
+		"encryption": validate.Optional(validate.IsBool),
 	}
 }
 
@@ -1200,6 +1259,11 @@ func (d *ceph) ValidateVolume(vol Volume, removeUnknownKeys bool) error {
 
 // UpdateVolume applies config changes to the volume.
 func (d *ceph) UpdateVolume(vol Volume, changedConfig map[string]string) error {
+	_, changed := changedConfig["encryption"]
+	if changed {
+		return fmt.Errorf("encryption cannot be changed")
+	}
+
 	newSize, sizeChanged := changedConfig["size"]
 	if sizeChanged {
 		err := d.SetVolumeQuota(vol, newSize, false, nil)
@@ -1324,8 +1388,11 @@ func (d *ceph) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, o
 		return fmt.Errorf("Error getting current size: %w", err)
 	}
 
+	// Encrypted volumes need room for the encryption header on top of the requested size.
+	rbdSizeBytes := d.luksSizeBytes(vol, sizeBytes)
+
 	// Do nothing if volume is already specified size (+/- 512 bytes).
-	if oldSizeBytes+512 > sizeBytes && oldSizeBytes-512 < sizeBytes {
+	if oldSizeBytes+512 > rbdSizeBytes && oldSizeBytes-512 < rbdSizeBytes {
 		return nil
 	}
 
@@ -1342,7 +1409,7 @@ func (d *ceph) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, o
 	if vol.contentType == ContentTypeFS {
 		fsType := vol.ConfigBlockFilesystem()
 
-		if sizeBytes < oldSizeBytes {
+		if rbdSizeBytes < oldSizeBytes {
 			if !filesystemTypeCanBeShrunk(fsType) {
 				return fmt.Errorf("Filesystem %q cannot be shrunk: %w", fsType, ErrCannotBeShrunk)
 			}
@@ -1353,25 +1420,41 @@ func (d *ceph) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, o
 
 			// Shrink filesystem first. Pass allowUnsafeResize to allow disabling of filesystem
 			// resize safety checks.
-			err = shrinkFileSystem(fsType, devPath, vol, sizeBytes, allowUnsafeResize)
+			err = d.luksTask(vol, devPath, func(devPath string) error {
+				return shrinkFileSystem(fsType, devPath, vol, sizeBytes, allowUnsafeResize)
+			})
+			if err != nil {
+				return err
+			}
+
+			// Shrink the encrypted volume if open so it still fits on the block device.
+			err = d.luksResize(vol, sizeBytes)
 			if err != nil {
 				return err
 			}
 
 			// Shrink the block device.
-			err = d.resizeVolume(vol, sizeBytes, true)
+			err = d.resizeVolume(vol, rbdSizeBytes, true)
 			if err != nil {
 				return err
 			}
-		} else if sizeBytes > oldSizeBytes {
+		} else if rbdSizeBytes > oldSizeBytes {
 			// Grow block device first.
-			err = d.resizeVolume(vol, sizeBytes, false)
+			err = d.resizeVolume(vol, rbdSizeBytes, false)
+			if err != nil {
+				return err
+			}
+
+			// Grow the encrypted volume to fill the block device if open.
+			err = d.luksResize(vol, 0)
 			if err != nil {
 				return err
 			}
 
 			// Grow the filesystem to fill block device.
-			err = growFileSystem(fsType, devPath, vol)
+			err = d.luksTask(vol, devPath, func(devPath string) error {
+				return growFileSystem(fsType, devPath, vol)
+			})
 			if err != nil {
 				return err
 			}
@@ -1380,7 +1463,7 @@ func (d *ceph) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, o
 		// Only perform pre-resize checks if we are not in "unsafe" mode.
 		// In unsafe mode we expect the caller to know what they are doing and understand the risks.
 		if !allowUnsafeResize {
-			if sizeBytes < oldSizeBytes {
+			if rbdSizeBytes < oldSizeBytes {
 				return fmt.Errorf("Block volumes cannot be shrunk: %w", ErrCannotBeShrunk)
 			}
 
@@ -1389,16 +1472,32 @@ func (d *ceph) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, o
 			}
 		}
 
+		// Shrink the encrypted volume first if open so it still fits on the block device.
+		if rbdSizeBytes < oldSizeBytes {
+			err = d.luksResize(vol, sizeBytes)
+			if err != nil {
+				return err
+			}
+		}
+
 		// Resize block device.
-		err = d.resizeVolume(vol, sizeBytes, allowUnsafeResize)
+		err = d.resizeVolume(vol, rbdSizeBytes, allowUnsafeResize)
 		if err != nil {
 			return err
 		}
 
+		// Grow the encrypted volume to fill the block device if open.
+		if rbdSizeBytes > oldSizeBytes {
+			err = d.luksResize(vol, 0)
+			if err != nil {
+				return err
+			}
+		}
+
 		// Move the VM GPT alt header to end of disk if needed (not needed in unsafe resize mode as it is
 		// expected the caller will do all necessary post resize actions themselves).
 		if vol.IsVMBlock() && !allowUnsafeResize {
-			err = d.moveGPTAltHeader(devPath)
+			err = d.luksTask(vol, devPath, d.moveGPTAltHeader)
 			if err != nil {
 				return err
 			}
@@ -1411,6 +1510,10 @@ func (d *ceph) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, o
 // GetVolumeDiskPath returns the location of a root disk block device.
 func (d *ceph) GetVolumeDiskPath(vol Volume) (string, error) {
 	if vol.IsVMBlock() || (vol.volType == VolumeTypeCustom && IsContentBlock(vol.contentType)) {
+		if d.volumeEncrypted(vol) {
+			return d.luksDevPath(vol), nil
+		}
+
 		_, devPath, err := d.getRBDMappedDevPath(vol, false)
 		return devPath, err
 	}
@@ -1543,6 +1646,20 @@ func (d *ceph) MountVolume(vol Volume, op *operations.Operation) error {
 		revert.Add(func() { _ = d.rbdUnmapVolume(vol, true) })
 	}
 
+	// Open the encrypted volume if needed and use its decrypted device from here on.
+	if d.volumeEncrypted(vol) {
+		opened, err := d.luksOpen(vol, volDevPath, false)
+		if err != nil {
+			return err
+		}
+
+		if opened {
+			revert.Add(func() { _, _ = d.luksClose(vol) })
+		}
+
+		volDevPath = d.luksDevPath(vol)
+	}
+
 	if vol.contentType == ContentTypeFS {
 		mountPath := vol.MountPath()
 		if !filesystem.IsMountPoint(mountPath) {
@@ -1862,8 +1979,8 @@ func (d *ceph) MigrateVolume(vol VolumeCopy, conn io.ReadWriteCloser, volSrcArgs
 }
 
 // BackupVolume creates an exported version of a volume.
-func (d *ceph) BackupVolume(vol VolumeCopy, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots []string, op *operations.Operation) error {
-	return genericVFSBackupVolume(d, vol, tarWriter, snapshots, op)
+func (d *ceph) BackupVolume(vol VolumeCopy, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots []string, incrementalFrom string, op *operations.Operation) error {
+	return genericVFSBackupVolume(d, vol, tarWriter, snapshots, incrementalFrom, op)
 }
 
 // CreateVolumeSnapshot creates a snapshot of a volume.
@@ -2029,6 +2146,16 @@ func (d *ceph) MountVolumeSnapshot(snapVol Volume, op *operations.Operation) err
 
 		revert.Add(func() { _ = d.rbdUnmapVolume(cloneVol, true) })
 
+		// Open the encrypted clone if needed. It is closed again when the clone is unmapped.
+		if d.volumeEncrypted(snapVol) {
+			_, err = d.luksOpen(cloneVol, rbdDevPath, false)
+			if err != nil {
+				return err
+			}
+
+			rbdDevPath = d.luksDevPath(cloneVol)
+		}
+
 		RBDFilesystem := snapVol.ConfigBlockFilesystem()
 		mountFlags, mountOptions := filesystem.ResolveMountOptions(strings.Split(snapVol.ConfigBlockMountOptions(), ","))
 
@@ -2054,11 +2181,23 @@ func (d *ceph) MountVolumeSnapshot(snapVol Volume, op *operations.Operation) err
 		d.logger.Debug("Mounted RBD volume snapshot", logger.Ctx{"dev": rbdDevPath, "path": mountPath, "options": mountOptions})
 	} else if snapVol.contentType == ContentTypeBlock {
 		// Activate RBD volume if needed.
-		_, _, err := d.getRBDMappedDevPath(snapVol, true)
+		activated, devPath, err := d.getRBDMappedDevPath(snapVol, true)
 		if err != nil {
 			return err
 		}
 
+		if activated {
+			revert.Add(func() { _ = d.rbdUnmapVolume(snapVol, true) })
+		}
+
+		// Open the encrypted snapshot if needed.
+		if d.volumeEncrypted(snapVol) {
+			_, err = d.luksOpen(snapVol, devPath, true)
+			if err != nil {
+				return err
+			}
+		}
+
 		// For VMs, mount the filesystem volume.
 		if snapVol.IsVMBlock() {
 			fsVol := snapVol.NewVMBlockFilesystemVolume()
@@ -2217,7 +2356,9 @@ func (d *ceph) restoreVolume(vol Volume, snapVol Volume, op *operations.Operatio
 
 	// Re-generate the UUID.
 	if vol.contentType == ContentTypeFS {
-		err = d.generateUUID(vol.ConfigBlockFilesystem(), devPath)
+		err = d.luksTask(vol, devPath, func(devPath string) error {
+			return d.generateUUID(vol.ConfigBlockFilesystem(), devPath)
+		})
 		if err != nil {
 			return err
 		}
@@ -2247,6 +2388,93 @@ func (d *ceph) RestoreVolume(vol Volume, snapVol Volume, op *operations.Operatio
 	return nil
 }
 
+// VerifyVolume runs a read-only check of the filesystem of the volume.
+// The volume must not be mounted while being checked. Snapshots and the disk image of virtual machines can't be
+// checked, so they are reported as unverified.
+func (d *ceph) VerifyVolume(vol Volume, op *operations.Operation) (*VolumeVerifyResult, error) {
+	result := &VolumeVerifyResult{Problems: []string{}}
+
+	snapshots, err := d.VolumeSnapshots(vol, op)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, snapshot := range snapshots {
+		result.Unverified = append(result.Unverified, fmt.Sprintf("Snapshot %q wasn't checked", snapshot))
+	}
+
+	fsVol := vol
+	if vol.IsVMBlock() {
+		// Only the filesystem volume of VMs can be checked as the block volume holds a disk image.
+		result.Unverified = append(result.Unverified, "The disk image of the virtual machine wasn't checked")
+		fsVol = vol.NewVMBlockFilesystemVolume()
+	} else if vol.contentType != ContentTypeFS {
+		result.Unverified = append(result.Unverified, fmt.Sprintf("Volumes with content type %q can't be checked", vol.contentType))
+		return result, nil
+	}
+
+	problem, err := d.verifyFilesystem(fsVol)
+	if err != nil {
+		if !errors.Is(err, ErrNotSupported) {
+			return nil, err
+		}
+
+		result.Unverified = append(result.Unverified, fmt.Sprintf("The filesystem of the volume wasn't checked: %v", err))
+	} else if problem != "" {
+		result.Problems = append(result.Problems, problem)
+	}
+
+	return result, nil
+}
+
+// verifyFilesystem runs a read-only check of the filesystem of the volume and returns the problem found, if any.
+func (d *ceph) verifyFilesystem(vol Volume) (string, error) {
+	unlock, err := vol.MountLock()
+	if err != nil {
+		return "", err
+	}
+
+	defer unlock()
+
+	if filesystem.IsMountPoint(vol.MountPath()) {
+		return "", fmt.Errorf("Cannot check the filesystem of a mounted volume: %w", ErrInUse)
+	}
+
+	// Activate RBD volume if needed.
+	activated, volDevPath, err := d.getRBDMappedDevPath(vol, true)
+	if err != nil {
+		return "", err
+	}
+
+	if activated {
+		defer func() { _ = d.rbdUnmapVolume(vol, true) }()
+	}
+
+	// Open the encrypted volume if needed and use its decrypted device from here on.
+	if d.volumeEncrypted(vol) {
+		opened, err := d.luksOpen(vol, volDevPath, true)
+		if err != nil {
+			return "", err
+		}
+
+		if opened {
+			defer func() { _, _ = d.luksClose(vol) }()
+		}
+
+		volDevPath = d.luksDevPath(vol)
+	}
+
+	fsType := vol.ConfigBlockFilesystem()
+	if vol.mountFilesystemProbe {
+		fsType, err = fsProbe(volDevPath)
+		if err != nil {
+			return "", fmt.Errorf("Failed probing filesystem: %w", err)
+		}
+	}
+
+	return fsCheck(volDevPath, fsType)
+}
+
 // RenameVolumeSnapshot renames a volume snapshot.
 func (d *ceph) RenameVolumeSnapshot(snapVol Volume, newSnapshotName string, op *operations.Operation) error {
 	revert := revert.New()
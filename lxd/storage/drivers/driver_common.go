@@ -120,6 +120,11 @@ func (d *common) fillVolumeConfig(vol *Volume, excludedKeys ...string) error {
 			continue
 		}
 
+		// The overcommit ratio applies to the pool as a whole rather than to individual volumes.
+		if volKey == "overcommit_ratio" {
+			continue
+		}
+
 		// If volume type is not custom or bucket, don't copy "size" property to volume config.
 		if (vol.volType != VolumeTypeCustom && vol.volType != VolumeTypeBucket) && volKey == "size" {
 			continue
@@ -421,7 +426,7 @@ func (d *common) MigrateVolume(vol VolumeCopy, conn io.ReadWriteCloser, volSrcAr
 }
 
 // BackupVolume creates an exported version of a volume.
-func (d *common) BackupVolume(vol VolumeCopy, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots []string, op *operations.Operation) error {
+func (d *common) BackupVolume(vol VolumeCopy, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots []string, incrementalFrom string, op *operations.Operation) error {
 	return ErrNotSupported
 }
 
@@ -450,6 +455,11 @@ func (d *common) VolumeSnapshots(vol Volume, op *operations.Operation) ([]string
 	return nil, ErrNotSupported
 }
 
+// VerifyVolume checks the integrity of a volume and its snapshots.
+func (d *common) VerifyVolume(vol Volume, op *operations.Operation) (*VolumeVerifyResult, error) {
+	return nil, ErrNotSupported
+}
+
 // CheckVolumeSnapshots checks that the volume's snapshots, according to the storage driver, match those provided.
 func (d *common) CheckVolumeSnapshots(vol Volume, snapVols []Volume, op *operations.Operation) error {
 	// Use the volume's driver reference to pick the actual method as implemented by the driver.
@@ -7,6 +7,8 @@ import (
 	"os"
 	"path/filepath"
 
+	"golang.org/x/sys/unix"
+
 	"github.com/canonical/lxd/lxd/backup"
 	"github.com/canonical/lxd/lxd/instancewriter"
 	"github.com/canonical/lxd/lxd/migration"
@@ -101,7 +103,7 @@ func (d *dir) CreateVolume(vol Volume, filler *VolumeFiller, op *operations.Oper
 // CreateVolumeFromBackup restores a backup tarball onto the storage device.
 func (d *dir) CreateVolumeFromBackup(vol VolumeCopy, srcBackup backup.Info, srcData io.ReadSeeker, op *operations.Operation) (VolumePostHook, revert.Hook, error) {
 	// Run the generic backup unpacker
-	postHook, revertHook, err := genericVFSBackupUnpack(d.withoutGetVolID(), d.state.OS, vol, srcBackup.Snapshots, srcData, op)
+	postHook, revertHook, err := genericVFSBackupUnpack(d.withoutGetVolID(), d.state.OS, vol, srcBackup.Snapshots, srcBackup.IncrementalFrom, srcData, op)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -272,15 +274,33 @@ func (d *dir) UpdateVolume(vol Volume, changedConfig map[string]string) error {
 
 // GetVolumeUsage returns the disk space used by the volume.
 func (d *dir) GetVolumeUsage(vol Volume) (int64, error) {
-	// Snapshot usage not supported for Dir.
-	if vol.IsSnapshot() {
-		return -1, ErrNotSupported
+	// For block volumes, report the space allocated to the (usually sparse) disk image file.
+	if IsContentBlock(vol.contentType) {
+		rootBlockPath, err := d.GetVolumeDiskPath(vol)
+		if err != nil {
+			return -1, err
+		}
+
+		var stat unix.Stat_t
+		err = unix.Stat(rootBlockPath, &stat)
+		if err != nil {
+			return -1, err
+		}
+
+		return stat.Blocks * 512, nil
 	}
 
 	volPath := vol.MountPath()
+
+	// Snapshots don't have their own project quota, so walk the snapshot's files to get its usage.
+	if vol.IsSnapshot() {
+		return directoryUsage(volPath)
+	}
+
+	// Fallback to walking the volume's files if the underlying filesystem doesn't support project quotas.
 	ok, err := quota.Supported(volPath)
 	if err != nil || !ok {
-		return -1, ErrNotSupported
+		return directoryUsage(volPath)
 	}
 
 	// Get the volume ID for the volume to access quota.
@@ -427,8 +447,8 @@ func (d *dir) MigrateVolume(vol VolumeCopy, conn io.ReadWriteCloser, volSrcArgs
 
 // BackupVolume copies a volume (and optionally its snapshots) to a specified target path.
 // This driver does not support optimized backups.
-func (d *dir) BackupVolume(vol VolumeCopy, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots []string, op *operations.Operation) error {
-	return genericVFSBackupVolume(d, vol, tarWriter, snapshots, op)
+func (d *dir) BackupVolume(vol VolumeCopy, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots []string, incrementalFrom string, op *operations.Operation) error {
+	return genericVFSBackupVolume(d, vol, tarWriter, snapshots, incrementalFrom, op)
 }
 
 // CreateVolumeSnapshot creates a snapshot of a volume.
@@ -107,7 +107,7 @@ func (d *lvm) FillConfig() error {
 		d.config["lvm.thinpool_name"] = lvmThinpoolDefaultName
 	}
 
-	return nil
+	return luksFillConfig(d.config)
 }
 
 // Create creates the storage pool on the storage device.
@@ -544,6 +544,10 @@ func (d *lvm) Validate(config map[string]string) error {
 		"lvm.vg.force_reuse": validate.Optional(validate.IsBool),
 	}
 
+	for k, v := range luksPoolRules() {
+		rules[k] = v
+	}
+
 	err := d.validatePool(config, rules, d.commonVolumeRules())
 	if err != nil {
 		return err
@@ -574,6 +578,11 @@ func (d *lvm) Update(changedConfig map[string]string) error {
 		return fmt.Errorf("lvm.thinpool_metadata_size cannot be changed")
 	}
 
+	_, changed = changedConfig["volatile.encryption.key"]
+	if changed {
+		return fmt.Errorf("volatile.encryption.key cannot be changed")
+	}
+
 	_, changed = changedConfig["volume.lvm.stripes"]
 	if changed && d.usesThinpool() {
 		return fmt.Errorf("volume.lvm.stripes cannot be changed when using thin pool")
@@ -737,7 +746,7 @@ func (d *lvm) GetResources() (*api.ResourcesStoragePool, error) {
 	// used space using the thinpool logical volume allocated (data and meta) percentages.
 	if d.usesThinpool() {
 		volDevPath := d.lvmDevPath(d.config["lvm.vg_name"], "", "", d.thinpoolName())
-		totalSize, usedSize, err := d.thinPoolVolumeUsage(volDevPath)
+		totalSize, usedSize, err := d.logicalVolumeUsage(volDevPath)
 		if err != nil {
 			return nil, err
 		}
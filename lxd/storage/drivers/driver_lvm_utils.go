@@ -324,6 +324,9 @@ func (d *lvm) createLogicalVolume(vgName, thinPoolName string, vol Volume, makeT
 		return err
 	}
 
+	// Encrypted volumes need room for the encryption header on top of the requested size.
+	lvSizeBytes = d.luksSizeBytes(vol, lvSizeBytes)
+
 	lvFullName := d.lvmFullVolumeName(vol.volType, vol.contentType, vol.name)
 
 	args := []string{
@@ -369,8 +372,18 @@ func (d *lvm) createLogicalVolume(vgName, thinPoolName string, vol Volume, makeT
 
 	volDevPath := d.lvmDevPath(vgName, vol.volType, vol.contentType, vol.name)
 
+	if d.volumeEncrypted(vol) {
+		err = d.luksFormat(vol, volDevPath)
+		if err != nil {
+			return err
+		}
+	}
+
 	if vol.contentType == ContentTypeFS {
-		_, err = makeFSType(volDevPath, vol.ConfigBlockFilesystem(), nil)
+		err = d.luksTask(vol, volDevPath, func(devPath string) error {
+			_, err := makeFSType(devPath, vol.ConfigBlockFilesystem(), nil)
+			return err
+		})
 		if err != nil {
 			return fmt.Errorf("Error making filesystem on LVM logical volume: %w", err)
 		}
@@ -626,7 +639,9 @@ func (d *lvm) copyThinpoolVolume(vol, srcVol Volume, srcSnapshots []string, refr
 			}
 
 			d.logger.Debug("Regenerating filesystem UUID", logger.Ctx{"dev": volDevPath, "fs": vol.ConfigBlockFilesystem()})
-			err = regenerateFilesystemUUID(vol.ConfigBlockFilesystem(), volDevPath)
+			err = d.luksTask(vol, volDevPath, func(devPath string) error {
+				return regenerateFilesystemUUID(vol.ConfigBlockFilesystem(), devPath)
+			})
 			if err != nil {
 				return err
 			}
@@ -675,7 +690,10 @@ func (d *lvm) logicalVolumeSize(volDevPath string) (int64, error) {
 	return strconv.ParseInt(output, 10, 64)
 }
 
-func (d *lvm) thinPoolVolumeUsage(volDevPath string) (uint64, uint64, error) {
+// logicalVolumeUsage returns the total and used size in bytes of a thin pool, a thin volume or a non-thin
+// snapshot volume (in which case the sizes are those of the snapshot's copy-on-write area).
+// Returns ErrNotSupported if the logical volume isn't activated.
+func (d *lvm) logicalVolumeUsage(volDevPath string) (uint64, uint64, error) {
 	args := []string{
 		volDevPath,
 		"--noheadings",
@@ -697,19 +715,19 @@ func (d *lvm) thinPoolVolumeUsage(volDevPath string) (uint64, uint64, error) {
 
 	total, err := strconv.ParseUint(parts[0], 10, 64)
 	if err != nil {
-		return 0, 0, fmt.Errorf("Failed parsing thin volume total size (%q): %w", parts[0], err)
+		return 0, 0, fmt.Errorf("Failed parsing logical volume total size (%q): %w", parts[0], err)
 	}
 
 	totalSize := total
 
-	// Used percentage is not available if thin volume isn't activated.
+	// Used percentage is not available if the logical volume isn't activated.
 	if parts[1] == "" {
 		return 0, 0, ErrNotSupported
 	}
 
 	dataPerc, err := strconv.ParseFloat(parts[1], 64)
 	if err != nil {
-		return 0, 0, fmt.Errorf("Failed parsing thin volume used percentage (%q): %w", parts[1], err)
+		return 0, 0, fmt.Errorf("Failed parsing logical volume used percentage (%q): %w", parts[1], err)
 	}
 
 	metaPerc := float64(0)
@@ -727,6 +745,26 @@ func (d *lvm) thinPoolVolumeUsage(volDevPath string) (uint64, uint64, error) {
 	return totalSize, usedSize, nil
 }
 
+// activatedLogicalVolumeUsage returns the used size in bytes of a volume's logical volume, activating the
+// volume temporarily if needed so that its usage can be queried.
+func (d *lvm) activatedLogicalVolumeUsage(vol Volume, volDevPath string) (int64, error) {
+	activated, err := d.activateVolume(vol)
+	if err != nil {
+		return -1, err
+	}
+
+	if activated {
+		defer func() { _, _ = d.deactivateVolume(vol) }()
+	}
+
+	_, usedSize, err := d.logicalVolumeUsage(volDevPath)
+	if err != nil {
+		return -1, err
+	}
+
+	return int64(usedSize), nil
+}
+
 // parseLogicalVolumeSnapshot parses a raw logical volume name (from lvs command) and checks whether it is a
 // snapshot of the supplied parent volume. Returns unescaped parsed snapshot name if snapshot volume recognised,
 // empty string if not. The parent is required due to limitations in the naming scheme that LXD has historically
@@ -789,9 +827,15 @@ func (d *lvm) activateVolume(vol Volume) (bool, error) {
 }
 
 // deactivateVolume deactivates an LVM logical volume if present. Returns true if deactivated, false if not.
+// The encrypted volume stored on the logical volume is closed first if open.
 func (d *lvm) deactivateVolume(vol Volume) (bool, error) {
 	var volDevPath string
 
+	_, err := d.luksClose(vol)
+	if err != nil {
+		return false, err
+	}
+
 	if d.usesThinpool() {
 		volDevPath = d.lvmDevPath(d.config["lvm.vg_name"], vol.volType, vol.contentType, vol.name)
 	} else {
@@ -811,7 +855,6 @@ func (d *lvm) deactivateVolume(vol Volume) (bool, error) {
 
 	if shared.PathExists(volDevPath) {
 		// Keep trying to deactivate a few times in case the device is still being flushed.
-		var err error
 		for i := 0; i < 20; i++ {
 			_, err = shared.RunCommand("lvchange", "--activate", "n", "--ignoreactivationskip", volDevPath)
 			if err == nil {
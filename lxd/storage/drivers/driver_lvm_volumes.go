@@ -2,6 +2,7 @@ package drivers
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -120,7 +121,7 @@ func (d *lvm) CreateVolume(vol Volume, filler *VolumeFiller, op *operations.Oper
 
 // CreateVolumeFromBackup restores a backup tarball onto the storage device.
 func (d *lvm) CreateVolumeFromBackup(vol VolumeCopy, srcBackup backup.Info, srcData io.ReadSeeker, op *operations.Operation) (VolumePostHook, revert.Hook, error) {
-	return genericVFSBackupUnpack(d, d.state.OS, vol, srcBackup.Snapshots, srcData, op)
+	return genericVFSBackupUnpack(d, d.state.OS, vol, srcBackup.Snapshots, srcBackup.IncrementalFrom, srcData, op)
 }
 
 // CreateVolumeFromCopy provides same-pool volume copying functionality.
@@ -141,8 +142,9 @@ func (d *lvm) CreateVolumeFromCopy(vol VolumeCopy, srcVol VolumeCopy, allowIncon
 		}
 	}
 
-	// We can use optimised copying when the pool is backed by an LVM thinpool.
-	if d.usesThinpool() {
+	// We can use optimised copying when the pool is backed by an LVM thinpool and the encryption of the
+	// source matches the one requested for the new volume.
+	if d.usesThinpool() && d.volumeEncrypted(vol.Volume) == d.volumeEncrypted(srcVol.Volume) {
 		err = d.copyThinpoolVolume(vol.Volume, srcVol.Volume, srcSnapshots, false)
 		if err != nil {
 			return err
@@ -171,8 +173,9 @@ func (d *lvm) CreateVolumeFromMigration(vol VolumeCopy, conn io.ReadWriteCloser,
 
 // RefreshVolume provides same-pool volume and specific snapshots syncing functionality.
 func (d *lvm) RefreshVolume(vol VolumeCopy, srcVol VolumeCopy, refreshSnapshots []string, allowInconsistent bool, op *operations.Operation) error {
-	// We can use optimised copying when the pool is backed by an LVM thinpool.
-	if d.usesThinpool() {
+	// We can use optimised copying when the pool is backed by an LVM thinpool and the encryption of the
+	// source matches the one of the target volume.
+	if d.usesThinpool() && d.volumeEncrypted(vol.Volume) == d.volumeEncrypted(srcVol.Volume) {
 		return d.copyThinpoolVolume(vol.Volume, srcVol.Volume, refreshSnapshots, true)
 	}
 
@@ -207,6 +210,11 @@ func (d *lvm) DeleteVolume(vol Volume, op *operations.Operation) error {
 			}
 		}
 
+		_, err = d.luksClose(vol)
+		if err != nil {
+			return err
+		}
+
 		err = d.removeLogicalVolume(d.lvmDevPath(d.config["lvm.vg_name"], vol.volType, vol.contentType, vol.name))
 		if err != nil {
 			return fmt.Errorf("Error removing LVM logical volume: %w", err)
@@ -252,7 +260,14 @@ func (d *lvm) FillVolumeConfig(vol Volume) error {
 	// Copy volume.* configuration options from pool.
 	// Exclude "block.filesystem" and "block.mount_options" as they depend on volume type (handled below).
 	// Exclude "lvm.stripes", "lvm.stripes.size" as they only work on non-thin storage pools (handled below).
-	err := d.fillVolumeConfig(&vol, "block.filesystem", "block.mount_options", "lvm.stripes", "lvm.stripes.size")
+	excludedKeys := []string{"block.filesystem", "block.mount_options", "lvm.stripes", "lvm.stripes.size"}
+
+	// Volumes created from a source keep the encryption of their source.
+	if vol.hasSource {
+		excludedKeys = append(excludedKeys, "encryption")
+	}
+
+	err := d.fillVolumeConfig(&vol, excludedKeys...)
 	if err != nil {
 		return err
 	}
@@ -307,6 +322,7 @@ func (d *lvm) commonVolumeRules() map[string]func(value string) error {
 	return map[string]func(value string) error{
 		"block.mount_options": validate.IsAny,
 		"block.filesystem":    validate.Optional(validate.IsOneOf(blockBackedAllowedFilesystems...)),
+		"encryption":          validate.Optional(validate.IsBool),
 		// lxdmeta:generate(entities=storage-lvm; group=volume-conf; key=lvm.stripes)
 		//
 		// ---
@@ -368,6 +384,11 @@ func (d *lvm) UpdateVolume(vol Volume, changedConfig map[string]string) error {
 		return fmt.Errorf("lvm.stripes cannot be changed")
 	}
 
+	_, changed = changedConfig["encryption"]
+	if changed {
+		return fmt.Errorf("encryption cannot be changed")
+	}
+
 	_, changed = changedConfig["lvm.stripes.size"]
 	if changed {
 		return fmt.Errorf("lvm.stripes.size cannot be changed")
@@ -376,38 +397,47 @@ func (d *lvm) UpdateVolume(vol Volume, changedConfig map[string]string) error {
 	return nil
 }
 
-// GetVolumeUsage returns the disk space used by the volume (this is not currently supported).
+// GetVolumeUsage returns the disk space used by the volume.
 func (d *lvm) GetVolumeUsage(vol Volume) (int64, error) {
-	// Snapshot usage not supported for LVM.
+	volDevPath := d.lvmDevPath(d.config["lvm.vg_name"], vol.volType, vol.contentType, vol.name)
+
+	// For snapshots we report the space allocated to the snapshot, either from the thin pool or from the
+	// snapshot's copy-on-write area for non-thin snapshots. Snapshots are never mounted to get their usage as
+	// mounting a snapshot can trigger a very expensive filesystem UUID regeneration.
 	if vol.IsSnapshot() {
-		return -1, ErrNotSupported
+		return d.activatedLogicalVolumeUsage(vol, volDevPath)
 	}
 
-	// For non-snapshot filesystem volumes, we only return usage when the volume is mounted.
-	// This is because to get an accurate value we cannot use blocks allocated, as the filesystem will likely
-	// consume blocks and not free them when files are deleted in the volume. This avoids returning different
-	// values depending on whether the volume is mounted or not.
-	if vol.contentType == ContentTypeFS && filesystem.IsMountPoint(vol.MountPath()) {
-		var stat unix.Statfs_t
-		err := unix.Statfs(vol.MountPath(), &stat)
-		if err != nil {
-			return -1, err
+	// For non-snapshot filesystem volumes, we use the filesystem stats to get the usage when the volume is
+	// mounted. This is because to get an accurate value we cannot use blocks allocated, as the filesystem will
+	// likely consume blocks and not free them when files are deleted in the volume. Volumes aren't mounted just
+	// to get their usage, so unmounted thin volumes report the space allocated from the thin pool instead.
+	if vol.contentType == ContentTypeFS {
+		if filesystem.IsMountPoint(vol.MountPath()) {
+			var stat unix.Statfs_t
+			err := unix.Statfs(vol.MountPath(), &stat)
+			if err != nil {
+				return -1, err
+			}
+
+			return int64(stat.Blocks-stat.Bfree) * int64(stat.Bsize), nil
 		}
 
-		return int64(stat.Blocks-stat.Bfree) * int64(stat.Bsize), nil
-	} else if vol.contentType == ContentTypeBlock && d.usesThinpool() {
-		// For non-snapshot thin pool block volumes we can calculate an approximate usage using the space
-		// allocated to the volume from the thin pool.
-		volDevPath := d.lvmDevPath(d.config["lvm.vg_name"], vol.volType, vol.contentType, vol.name)
-		_, usedSize, err := d.thinPoolVolumeUsage(volDevPath)
-		if err != nil {
-			return -1, err
+		if d.usesThinpool() {
+			return d.activatedLogicalVolumeUsage(vol, volDevPath)
 		}
 
-		return int64(usedSize), nil
+		return -1, ErrNotSupported
+	}
+
+	// For non-snapshot thin pool block volumes we can calculate an approximate usage using the space
+	// allocated to the volume from the thin pool.
+	if d.usesThinpool() {
+		return d.activatedLogicalVolumeUsage(vol, volDevPath)
 	}
 
-	return -1, ErrNotSupported
+	// Non-thin block volumes are fully allocated when created, so their usage is their size.
+	return d.logicalVolumeSize(volDevPath)
 }
 
 // SetVolumeQuota applies a size limit on volume.
@@ -423,6 +453,9 @@ func (d *lvm) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op
 		return err
 	}
 
+	// Encrypted volumes need room for the encryption header on top of the requested size.
+	lvSizeBytes := d.luksSizeBytes(vol, sizeBytes)
+
 	// Read actual size of current volume.
 	volDevPath := d.lvmDevPath(d.config["lvm.vg_name"], vol.volType, vol.contentType, vol.name)
 	oldSizeBytes, err := d.logicalVolumeSize(volDevPath)
@@ -438,7 +471,7 @@ func (d *lvm) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op
 	}
 
 	// Round up the number of extents required for new quota size, as this is what the lvresize tool will do.
-	newNumExtents := math.Ceil(float64(sizeBytes) / float64(vgExtentSize))
+	newNumExtents := math.Ceil(float64(lvSizeBytes) / float64(vgExtentSize))
 	oldNumExtents := math.Ceil(float64(oldSizeBytes) / float64(vgExtentSize))
 	extentDiff := int(newNumExtents - oldNumExtents)
 
@@ -455,7 +488,7 @@ func (d *lvm) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op
 	if vol.contentType == ContentTypeFS {
 		fsType := vol.ConfigBlockFilesystem()
 
-		if sizeBytes < oldSizeBytes {
+		if lvSizeBytes < oldSizeBytes {
 			if !filesystemTypeCanBeShrunk(fsType) {
 				return fmt.Errorf("Filesystem %q cannot be shrunk: %w", fsType, ErrCannotBeShrunk)
 			}
@@ -482,7 +515,9 @@ func (d *lvm) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op
 			// so that we can have more control over when we trigger unsafe filesystem resize mode,
 			// otherwise by passing -f to lvresize (required for other reasons) this would then pass
 			// -f onto resize2fs as well.
-			err = shrinkFileSystem(fsType, volDevPath, vol, sizeBytes, allowUnsafeResize)
+			err = d.luksTask(vol, volDevPath, func(devPath string) error {
+				return shrinkFileSystem(fsType, devPath, vol, sizeBytes, allowUnsafeResize)
+			})
 			if err != nil {
 				_, _ = d.deactivateVolume(vol)
 				return err
@@ -497,13 +532,13 @@ func (d *lvm) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op
 			l.Debug("Logical volume filesystem shrunk")
 
 			// Shrink the block device.
-			err = d.resizeLogicalVolume(volDevPath, sizeBytes)
+			err = d.resizeLogicalVolume(volDevPath, lvSizeBytes)
 			if err != nil {
 				return err
 			}
-		} else if sizeBytes > oldSizeBytes {
+		} else if lvSizeBytes > oldSizeBytes {
 			// Grow block device first.
-			err = d.resizeLogicalVolume(volDevPath, sizeBytes)
+			err = d.resizeLogicalVolume(volDevPath, lvSizeBytes)
 			if err != nil {
 				return err
 			}
@@ -520,8 +555,16 @@ func (d *lvm) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op
 				}()
 			}
 
+			// Grow the encrypted volume to fill the block device if already open.
+			err = d.luksResize(vol, 0)
+			if err != nil {
+				return err
+			}
+
 			// Grow the filesystem to fill block device.
-			err = growFileSystem(fsType, volDevPath, vol)
+			err = d.luksTask(vol, volDevPath, func(devPath string) error {
+				return growFileSystem(fsType, devPath, vol)
+			})
 			if err != nil {
 				return err
 			}
@@ -532,7 +575,7 @@ func (d *lvm) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op
 		// Only perform pre-resize checks if we are not in "unsafe" mode.
 		// In unsafe mode we expect the caller to know what they are doing and understand the risks.
 		if !allowUnsafeResize {
-			if sizeBytes < oldSizeBytes {
+			if lvSizeBytes < oldSizeBytes {
 				return fmt.Errorf("Block volumes cannot be shrunk: %w", ErrCannotBeShrunk)
 			}
 
@@ -541,11 +584,27 @@ func (d *lvm) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op
 			}
 		}
 
-		err = d.resizeLogicalVolume(volDevPath, sizeBytes)
+		// Shrink the encrypted volume first if open so it still fits on the block device.
+		if lvSizeBytes < oldSizeBytes {
+			err = d.luksResize(vol, sizeBytes)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = d.resizeLogicalVolume(volDevPath, lvSizeBytes)
 		if err != nil {
 			return err
 		}
 
+		// Grow the encrypted volume to fill the block device if open.
+		if lvSizeBytes > oldSizeBytes {
+			err = d.luksResize(vol, 0)
+			if err != nil {
+				return err
+			}
+		}
+
 		// Move the VM GPT alt header to end of disk if needed (not needed in unsafe resize mode as it is
 		// expected the caller will do all necessary post resize actions themselves).
 		if vol.IsVMBlock() && !allowUnsafeResize {
@@ -561,7 +620,7 @@ func (d *lvm) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op
 				}()
 			}
 
-			err = d.moveGPTAltHeader(volDevPath)
+			err = d.luksTask(vol, volDevPath, d.moveGPTAltHeader)
 			if err != nil {
 				return err
 			}
@@ -574,6 +633,10 @@ func (d *lvm) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op
 // GetVolumeDiskPath returns the location of a disk volume.
 func (d *lvm) GetVolumeDiskPath(vol Volume) (string, error) {
 	if vol.IsVMBlock() || (vol.volType == VolumeTypeCustom && IsContentBlock(vol.contentType)) {
+		if d.volumeEncrypted(vol) {
+			return d.luksDevPath(vol), nil
+		}
+
 		volDevPath := d.lvmDevPath(d.config["lvm.vg_name"], vol.volType, vol.contentType, vol.name)
 		return volDevPath, nil
 	}
@@ -706,12 +769,27 @@ func (d *lvm) MountVolume(vol Volume, op *operations.Operation) error {
 		revert.Add(func() { _, _ = d.deactivateVolume(vol) })
 	}
 
+	volDevPath := d.lvmDevPath(d.config["lvm.vg_name"], vol.volType, vol.contentType, vol.name)
+
+	// Open the encrypted volume if needed and use its decrypted device from here on.
+	if d.volumeEncrypted(vol) {
+		opened, err := d.luksOpen(vol, volDevPath, false)
+		if err != nil {
+			return err
+		}
+
+		if opened {
+			revert.Add(func() { _, _ = d.luksClose(vol) })
+		}
+
+		volDevPath = d.luksDevPath(vol)
+	}
+
 	if vol.contentType == ContentTypeFS {
 		// Check if already mounted.
 		mountPath := vol.MountPath()
 		if !filesystem.IsMountPoint(mountPath) {
 			fsType := vol.ConfigBlockFilesystem()
-			volDevPath := d.lvmDevPath(d.config["lvm.vg_name"], vol.volType, vol.contentType, vol.name)
 
 			if vol.mountFilesystemProbe {
 				fsType, err = fsProbe(volDevPath)
@@ -900,8 +978,8 @@ func (d *lvm) MigrateVolume(vol VolumeCopy, conn io.ReadWriteCloser, volSrcArgs
 
 // BackupVolume copies a volume (and optionally its snapshots) to a specified target path.
 // This driver does not support optimized backups.
-func (d *lvm) BackupVolume(vol VolumeCopy, tarWriter *instancewriter.InstanceTarWriter, _ bool, snapshots []string, op *operations.Operation) error {
-	return genericVFSBackupVolume(d, vol, tarWriter, snapshots, op)
+func (d *lvm) BackupVolume(vol VolumeCopy, tarWriter *instancewriter.InstanceTarWriter, _ bool, snapshots []string, incrementalFrom string, op *operations.Operation) error {
+	return genericVFSBackupVolume(d, vol, tarWriter, snapshots, incrementalFrom, op)
 }
 
 // CreateVolumeSnapshot creates a snapshot of a volume.
@@ -1062,6 +1140,20 @@ func (d *lvm) MountVolumeSnapshot(snapVol Volume, op *operations.Operation) erro
 			return err
 		}
 
+		// Open the encrypted volume if needed. Only the temporary snapshot is writable.
+		if d.volumeEncrypted(mountVol) {
+			opened, err := d.luksOpen(mountVol, volDevPath, !regenerateFSUUID)
+			if err != nil {
+				return err
+			}
+
+			if opened {
+				revert.Add(func() { _, _ = d.luksClose(mountVol) })
+			}
+
+			volDevPath = d.luksDevPath(mountVol)
+		}
+
 		if regenerateFSUUID {
 			tmpVolFsType := mountVol.ConfigBlockFilesystem()
 
@@ -1095,6 +1187,19 @@ func (d *lvm) MountVolumeSnapshot(snapVol Volume, op *operations.Operation) erro
 			return err
 		}
 
+		if d.volumeEncrypted(snapVol) {
+			volDevPath := d.lvmDevPath(d.config["lvm.vg_name"], snapVol.volType, snapVol.contentType, snapVol.name)
+
+			opened, err := d.luksOpen(snapVol, volDevPath, true)
+			if err != nil {
+				return err
+			}
+
+			if opened {
+				revert.Add(func() { _, _ = d.luksClose(snapVol) })
+			}
+		}
+
 		// For VMs, mount the filesystem volume.
 		if snapVol.IsVMBlock() {
 			fsVol := snapVol.NewVMBlockFilesystemVolume()
@@ -1148,6 +1253,12 @@ func (d *lvm) UnmountVolumeSnapshot(snapVol Volume, op *operations.Operation) (b
 		}
 
 		if exists {
+			tmpVol := NewVolume(d, d.name, snapVol.volType, snapVol.contentType, tmpVolName, snapVol.config, snapVol.poolConfig)
+			_, err = d.luksClose(tmpVol)
+			if err != nil {
+				return true, err
+			}
+
 			err = d.removeLogicalVolume(tmpVolDevPath)
 			if err != nil {
 				return true, fmt.Errorf("Failed to remove temporary LVM snapshot volume %q: %w", tmpVolDevPath, err)
@@ -1253,6 +1364,11 @@ func (d *lvm) RestoreVolume(vol Volume, snapVol Volume, op *operations.Operation
 			return nil, fmt.Errorf("Error unmounting LVM logical volume: %w", err)
 		}
 
+		_, err = d.luksClose(restoreVol)
+		if err != nil {
+			return nil, err
+		}
+
 		originalVolDevPath := d.lvmDevPath(d.config["lvm.vg_name"], restoreVol.volType, restoreVol.contentType, restoreVol.name)
 		tmpVolName := fmt.Sprintf("%s%s", restoreVol.name, tmpVolSuffix)
 		tmpVolDevPath := d.lvmDevPath(d.config["lvm.vg_name"], restoreVol.volType, restoreVol.contentType, tmpVolName)
@@ -1291,7 +1407,9 @@ func (d *lvm) RestoreVolume(vol Volume, snapVol Volume, op *operations.Operation
 			}
 
 			d.logger.Debug("Regenerating filesystem UUID", logger.Ctx{"dev": volDevPath, "fs": restoreVol.ConfigBlockFilesystem()})
-			err = regenerateFilesystemUUID(restoreVol.ConfigBlockFilesystem(), volDevPath)
+			err = d.luksTask(restoreVol, volDevPath, func(devPath string) error {
+				return regenerateFilesystemUUID(restoreVol.ConfigBlockFilesystem(), devPath)
+			})
 			if err != nil {
 				return nil, err
 			}
@@ -1436,6 +1554,95 @@ func (d *lvm) RestoreVolume(vol Volume, snapVol Volume, op *operations.Operation
 	return nil
 }
 
+// VerifyVolume runs a read-only check of the filesystem of the volume.
+// The volume must not be mounted while being checked. Snapshots and the disk image of virtual machines can't be
+// checked, so they are reported as unverified.
+func (d *lvm) VerifyVolume(vol Volume, op *operations.Operation) (*VolumeVerifyResult, error) {
+	result := &VolumeVerifyResult{Problems: []string{}}
+
+	snapshots, err := d.VolumeSnapshots(vol, op)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, snapshot := range snapshots {
+		result.Unverified = append(result.Unverified, fmt.Sprintf("Snapshot %q wasn't checked", snapshot))
+	}
+
+	fsVol := vol
+	if vol.IsVMBlock() {
+		// Only the filesystem volume of VMs can be checked as the block volume holds a disk image.
+		result.Unverified = append(result.Unverified, "The disk image of the virtual machine wasn't checked")
+		fsVol = vol.NewVMBlockFilesystemVolume()
+	} else if vol.contentType != ContentTypeFS {
+		result.Unverified = append(result.Unverified, fmt.Sprintf("Volumes with content type %q can't be checked", vol.contentType))
+		return result, nil
+	}
+
+	problem, err := d.verifyFilesystem(fsVol)
+	if err != nil {
+		if !errors.Is(err, ErrNotSupported) {
+			return nil, err
+		}
+
+		result.Unverified = append(result.Unverified, fmt.Sprintf("The filesystem of the volume wasn't checked: %v", err))
+	} else if problem != "" {
+		result.Problems = append(result.Problems, problem)
+	}
+
+	return result, nil
+}
+
+// verifyFilesystem runs a read-only check of the filesystem of the volume and returns the problem found, if any.
+func (d *lvm) verifyFilesystem(vol Volume) (string, error) {
+	unlock, err := vol.MountLock()
+	if err != nil {
+		return "", err
+	}
+
+	defer unlock()
+
+	if filesystem.IsMountPoint(vol.MountPath()) {
+		return "", fmt.Errorf("Cannot check the filesystem of a mounted volume: %w", ErrInUse)
+	}
+
+	// Activate LVM volume if needed.
+	activated, err := d.activateVolume(vol)
+	if err != nil {
+		return "", err
+	}
+
+	if activated {
+		defer func() { _, _ = d.deactivateVolume(vol) }()
+	}
+
+	volDevPath := d.lvmDevPath(d.config["lvm.vg_name"], vol.volType, vol.contentType, vol.name)
+
+	// Open the encrypted volume if needed and use its decrypted device from here on.
+	if d.volumeEncrypted(vol) {
+		opened, err := d.luksOpen(vol, volDevPath, true)
+		if err != nil {
+			return "", err
+		}
+
+		if opened {
+			defer func() { _, _ = d.luksClose(vol) }()
+		}
+
+		volDevPath = d.luksDevPath(vol)
+	}
+
+	fsType := vol.ConfigBlockFilesystem()
+	if vol.mountFilesystemProbe {
+		fsType, err = fsProbe(volDevPath)
+		if err != nil {
+			return "", fmt.Errorf("Failed probing filesystem: %w", err)
+		}
+	}
+
+	return fsCheck(volDevPath, fsType)
+}
+
 // RenameVolumeSnapshot renames a volume snapshot.
 func (d *lvm) RenameVolumeSnapshot(snapVol Volume, newSnapshotName string, op *operations.Operation) error {
 	volDevPath := d.lvmDevPath(d.config["lvm.vg_name"], snapVol.volType, snapVol.contentType, snapVol.name)
@@ -0,0 +1,227 @@
+package drivers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/migration"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/storage/filesystem"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/validate"
+)
+
+type nfs struct {
+	common
+}
+
+// load is used to run one-time action per-driver rather than per-pool.
+func (d *nfs) load() error {
+	// Register the patches.
+	d.patches = map[string]func() error{
+		"storage_lvm_skipactivation":                         nil,
+		"storage_missing_snapshot_records":                   nil,
+		"storage_delete_old_snapshot_records":                nil,
+		"storage_zfs_drop_block_volume_filesystem_extension": nil,
+		"storage_prefix_bucket_names_with_project":           nil,
+	}
+
+	return nil
+}
+
+// isRemote returns true indicating this driver uses remote storage.
+func (d *nfs) isRemote() bool {
+	return true
+}
+
+// Info returns info about the driver and its environment.
+func (d *nfs) Info() Info {
+	return Info{
+		Name:                         "nfs",
+		Version:                      "1",
+		DefaultVMBlockFilesystemSize: d.defaultVMBlockFilesystemSize(),
+		OptimizedImages:              false,
+		PreservesInodes:              false,
+		Remote:                       d.isRemote(),
+		VolumeTypes:                  []VolumeType{VolumeTypeCustom, VolumeTypeContainer},
+		VolumeMultiNode:              true,
+		BlockBacking:                 false,
+		RunningCopyFreeze:            true,
+		DirectIO:                     true,
+		MountedRoot:                  true,
+	}
+}
+
+// FillConfig populates the storage pool's configuration file with the default values.
+func (d *nfs) FillConfig() error {
+	return nil
+}
+
+// Create is called during pool creation and is effectively using an empty driver struct.
+// WARNING: The Create() function cannot rely on any of the struct attributes being set.
+func (d *nfs) Create() error {
+	err := d.FillConfig()
+	if err != nil {
+		return err
+	}
+
+	// Config validation.
+	if d.config["source"] == "" {
+		return fmt.Errorf("Missing required source name/path")
+	}
+
+	if d.sourceIsPath() {
+		sourcePath := shared.HostPath(d.config["source"])
+
+		// Check that the source isn't within LXD_DIR, as it is expected to be shared with other systems.
+		if strings.HasPrefix(filepath.Clean(sourcePath), shared.VarPath()) {
+			return fmt.Errorf("Source path %q is within the LXD directory", sourcePath)
+		}
+	}
+
+	// Create a temporary mountpoint.
+	mountPath, err := os.MkdirTemp("", "lxd_nfs_")
+	if err != nil {
+		return fmt.Errorf("Failed to create temporary directory under: %w", err)
+	}
+
+	defer func() { _ = os.RemoveAll(mountPath) }()
+
+	err = os.Chmod(mountPath, 0700)
+	if err != nil {
+		return fmt.Errorf("Failed to chmod '%s': %w", mountPath, err)
+	}
+
+	mountPoint := filepath.Join(mountPath, "mount")
+
+	err = os.Mkdir(mountPoint, 0700)
+	if err != nil {
+		return fmt.Errorf("Failed to create directory '%s': %w", mountPoint, err)
+	}
+
+	// Mount the source to check that it is usable.
+	err = d.mountSource(mountPoint)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _, _ = forceUnmount(mountPoint) }()
+
+	// Check that the source is currently empty.
+	isEmpty, err := shared.PathIsEmpty(mountPoint)
+	if err != nil {
+		return err
+	}
+
+	if !isEmpty {
+		return fmt.Errorf("Source %q isn't empty", d.config["source"])
+	}
+
+	return nil
+}
+
+// Delete removes the storage pool from the storage device.
+func (d *nfs) Delete(op *operations.Operation) error {
+	// Ensure the pool is mounted so that the shared data can be removed.
+	_, err := d.Mount()
+	if err != nil {
+		return err
+	}
+
+	// On delete, wipe everything in the directory.
+	err = wipeDirectory(GetPoolMountPath(d.name))
+	if err != nil {
+		return err
+	}
+
+	// Unmount the path.
+	_, err = d.Unmount()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Validate checks that all provide keys are supported and that no conflicting or missing configuration is present.
+func (d *nfs) Validate(config map[string]string) error {
+	rules := map[string]func(value string) error{
+		// lxdmeta:generate(entities=storage-nfs; group=pool-conf; key=nfs.mount_options)
+		// These options are passed to the NFS mount helper when mounting the export specified in `source`.
+		// They are not used if `source` is a path to an already mounted shared file system.
+		// Changes take effect the next time the storage pool is mounted.
+		// ---
+		//  type: string
+		//  shortdesc: Mount options for the NFS export
+		"nfs.mount_options": validate.IsAny,
+	}
+
+	return d.validatePool(config, rules, nil)
+}
+
+// Update applies any driver changes required from a configuration change.
+// Changes to the mount options only take effect the next time the pool is mounted.
+func (d *nfs) Update(changedConfig map[string]string) error {
+	return nil
+}
+
+// Mount mounts the storage pool.
+func (d *nfs) Mount() (bool, error) {
+	path := GetPoolMountPath(d.name)
+
+	// Check if already mounted.
+	if d.sourceIsPath() {
+		if sameMount(shared.HostPath(d.config["source"]), path) {
+			return false, nil
+		}
+	} else if filesystem.IsMountPoint(path) {
+		return false, nil
+	}
+
+	err := d.mountSource(path)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Unmount unmounts the storage pool.
+func (d *nfs) Unmount() (bool, error) {
+	// Unmount until nothing is left mounted.
+	return forceUnmount(GetPoolMountPath(d.name))
+}
+
+// GetResources returns the pool resource usage information.
+func (d *nfs) GetResources() (*api.ResourcesStoragePool, error) {
+	return genericVFSGetResources(d)
+}
+
+// MigrationTypes returns the type of transfer methods to be used when doing migrations between pools
+// in preference order.
+func (d *nfs) MigrationTypes(contentType ContentType, refresh bool, copySnapshots bool) []migration.Type {
+	var rsyncFeatures []string
+
+	// Do not pass compression argument to rsync if the associated
+	// config key, that is rsync.compression, is set to false.
+	if shared.IsFalse(d.Config()["rsync.compression"]) {
+		rsyncFeatures = []string{"delete", "bidirectional"}
+	} else {
+		rsyncFeatures = []string{"delete", "compress", "bidirectional"}
+	}
+
+	if contentType != ContentTypeFS {
+		return nil
+	}
+
+	// Do not support xattr transfer as NFS doesn't support the security xattrs.
+	return []migration.Type{
+		{
+			FSType:   migration.MigrationFSType_RSYNC,
+			Features: rsyncFeatures,
+		},
+	}
+}
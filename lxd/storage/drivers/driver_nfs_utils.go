@@ -0,0 +1,46 @@
+package drivers
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/canonical/lxd/shared"
+)
+
+// sourceIsPath returns true if the pool's source is a path to an already mounted shared filesystem rather
+// than an NFS export in the "host:/path" form.
+func (d *nfs) sourceIsPath() bool {
+	return filepath.IsAbs(d.config["source"])
+}
+
+// mountSource mounts the pool's source onto the target path.
+// NFS exports are mounted using the NFS mount helper, while paths to already mounted shared filesystems are
+// bind-mounted.
+func (d *nfs) mountSource(target string) error {
+	source := d.config["source"]
+
+	if d.sourceIsPath() {
+		sourcePath := shared.HostPath(source)
+		if !shared.PathExists(sourcePath) {
+			return fmt.Errorf("Source path %q doesn't exist", sourcePath)
+		}
+
+		return TryMount(sourcePath, target, "none", unix.MS_BIND, "")
+	}
+
+	args := []string{"-t", "nfs"}
+	if d.config["nfs.mount_options"] != "" {
+		args = append(args, "-o", d.config["nfs.mount_options"])
+	}
+
+	args = append(args, source, target)
+
+	_, err := shared.RunCommand("mount", args...)
+	if err != nil {
+		return fmt.Errorf("Failed to mount NFS export %q: %w", source, err)
+	}
+
+	return nil
+}
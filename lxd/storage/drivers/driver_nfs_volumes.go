@@ -0,0 +1,381 @@
+package drivers
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/canonical/lxd/lxd/backup"
+	"github.com/canonical/lxd/lxd/instancewriter"
+	"github.com/canonical/lxd/lxd/migration"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/rsync"
+	"github.com/canonical/lxd/lxd/storage/filesystem"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/revert"
+	"github.com/canonical/lxd/shared/units"
+)
+
+// CreateVolume creates an empty volume and can optionally fill it by executing the supplied
+// filler function.
+func (d *nfs) CreateVolume(vol Volume, filler *VolumeFiller, op *operations.Operation) error {
+	if vol.contentType != ContentTypeFS {
+		return ErrNotSupported
+	}
+
+	volPath := vol.MountPath()
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	if shared.PathExists(volPath) {
+		return fmt.Errorf("Volume path %q already exists", volPath)
+	}
+
+	// Create the volume itself.
+	err := vol.EnsureMountPath()
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() { _ = os.RemoveAll(volPath) })
+
+	// Run the volume filler function if supplied.
+	err = d.runFiller(vol, "", filler, false)
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+	return nil
+}
+
+// CreateVolumeFromBackup restores a backup tarball onto the storage device.
+func (d *nfs) CreateVolumeFromBackup(vol VolumeCopy, srcBackup backup.Info, srcData io.ReadSeeker, op *operations.Operation) (VolumePostHook, revert.Hook, error) {
+	return genericVFSBackupUnpack(d, d.state.OS, vol, srcBackup.Snapshots, srcBackup.IncrementalFrom, srcData, op)
+}
+
+// CreateVolumeFromCopy provides same-pool volume copying functionality.
+func (d *nfs) CreateVolumeFromCopy(vol VolumeCopy, srcVol VolumeCopy, allowInconsistent bool, op *operations.Operation) error {
+	var srcSnapshots []string
+
+	if len(vol.Snapshots) > 0 && !srcVol.IsSnapshot() {
+		// Get the list of snapshots from the source.
+		allSrcSnapshots, err := srcVol.Volume.Snapshots(op)
+		if err != nil {
+			return err
+		}
+
+		for _, srcSnapshot := range allSrcSnapshots {
+			_, snapshotName, _ := api.GetParentAndSnapshotName(srcSnapshot.name)
+			srcSnapshots = append(srcSnapshots, snapshotName)
+		}
+	}
+
+	// Run the generic copy.
+	_, err := genericVFSCopyVolume(d, nil, vol, srcVol, srcSnapshots, false, allowInconsistent, op)
+	return err
+}
+
+// CreateVolumeFromMigration creates a volume being sent via a migration.
+func (d *nfs) CreateVolumeFromMigration(vol VolumeCopy, conn io.ReadWriteCloser, volTargetArgs migration.VolumeTargetArgs, preFiller *VolumeFiller, op *operations.Operation) error {
+	_, err := genericVFSCreateVolumeFromMigration(d, nil, vol, conn, volTargetArgs, preFiller, op)
+	return err
+}
+
+// RefreshVolume provides same-pool volume and specific snapshots syncing functionality.
+func (d *nfs) RefreshVolume(vol VolumeCopy, srcVol VolumeCopy, refreshSnapshots []string, allowInconsistent bool, op *operations.Operation) error {
+	_, err := genericVFSCopyVolume(d, nil, vol, srcVol, refreshSnapshots, true, allowInconsistent, op)
+	return err
+}
+
+// DeleteVolume deletes a volume of the storage device. If any snapshots of the volume remain then
+// this function will return an error.
+func (d *nfs) DeleteVolume(vol Volume, op *operations.Operation) error {
+	snapshots, err := d.VolumeSnapshots(vol, op)
+	if err != nil {
+		return err
+	}
+
+	if len(snapshots) > 0 {
+		return fmt.Errorf("Cannot remove a volume that has snapshots")
+	}
+
+	volPath := vol.MountPath()
+
+	// If the volume doesn't exist, then nothing more to do.
+	if !shared.PathExists(volPath) {
+		return nil
+	}
+
+	// Remove the volume from the storage device.
+	err = forceRemoveAll(volPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("Failed to remove '%s': %w", volPath, err)
+	}
+
+	// Although the volume snapshot directory should already be removed, lets remove it here
+	// to just in case the top-level directory is left.
+	err = deleteParentSnapshotDirIfEmpty(d.name, vol.volType, vol.name)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// HasVolume indicates whether a specific volume exists on the storage pool.
+func (d *nfs) HasVolume(vol Volume) (bool, error) {
+	return genericVFSHasVolume(vol)
+}
+
+// ValidateVolume validates the supplied volume config. Optionally removes invalid keys from the volume's config.
+func (d *nfs) ValidateVolume(vol Volume, removeUnknownKeys bool) error {
+	// Only filesystem volumes can be stored on NFS, so reject block and ISO volumes before trying to create them.
+	if vol.contentType != ContentTypeFS {
+		return fmt.Errorf("Volumes of content type %q are not supported by the nfs driver", vol.contentType)
+	}
+
+	return d.validateVolume(vol, nil, removeUnknownKeys)
+}
+
+// UpdateVolume applies config changes to the volume.
+func (d *nfs) UpdateVolume(vol Volume, changedConfig map[string]string) error {
+	newSize, sizeChanged := changedConfig["size"]
+	if sizeChanged {
+		err := d.SetVolumeQuota(vol, newSize, false, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetVolumeUsage returns the disk space used by the volume.
+func (d *nfs) GetVolumeUsage(vol Volume) (int64, error) {
+	return directoryUsage(vol.MountPath())
+}
+
+// SetVolumeQuota applies a size limit on volume.
+// As NFS doesn't support quotas this only logs a warning if a size is specified.
+func (d *nfs) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op *operations.Operation) error {
+	// Convert to bytes.
+	sizeBytes, err := units.ParseByteSizeString(size)
+	if err != nil {
+		return err
+	}
+
+	if sizeBytes > 0 {
+		d.logger.Warn("The NFS driver doesn't support quotas, skipping set quota", logger.Ctx{"volName": vol.name, "size": sizeBytes})
+	}
+
+	return nil
+}
+
+// GetVolumeDiskPath returns the location of a root disk block device.
+func (d *nfs) GetVolumeDiskPath(vol Volume) (string, error) {
+	return "", ErrNotSupported
+}
+
+// ListVolumes returns a list of LXD volumes in storage pool.
+func (d *nfs) ListVolumes() ([]Volume, error) {
+	return genericVFSListVolumes(d)
+}
+
+// MountVolume simulates mounting a volume.
+func (d *nfs) MountVolume(vol Volume, op *operations.Operation) error {
+	unlock, err := vol.MountLock()
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	// Don't attempt to modify the permission of an existing custom volume root.
+	// A user inside the instance may have modified this and we don't want to reset it on restart.
+	if !shared.PathExists(vol.MountPath()) || vol.volType != VolumeTypeCustom {
+		err := vol.EnsureMountPath()
+		if err != nil {
+			return err
+		}
+	}
+
+	vol.MountRefCountIncrement() // From here on it is up to caller to call UnmountVolume() when done.
+	return nil
+}
+
+// UnmountVolume simulates unmounting a volume.
+// As driver doesn't have volumes to unmount it returns false indicating the volume was already unmounted.
+func (d *nfs) UnmountVolume(vol Volume, keepBlockDev bool, op *operations.Operation) (bool, error) {
+	unlock, err := vol.MountLock()
+	if err != nil {
+		return false, err
+	}
+
+	defer unlock()
+
+	refCount := vol.MountRefCountDecrement()
+	if refCount > 0 {
+		d.logger.Debug("Skipping unmount as in use", logger.Ctx{"volName": vol.name, "refCount": refCount})
+		return false, ErrInUse
+	}
+
+	return false, nil
+}
+
+// RenameVolume renames a volume and its snapshots.
+func (d *nfs) RenameVolume(vol Volume, newVolName string, op *operations.Operation) error {
+	return genericVFSRenameVolume(d, vol, newVolName, op)
+}
+
+// MigrateVolume sends a volume for migration.
+func (d *nfs) MigrateVolume(vol VolumeCopy, conn io.ReadWriteCloser, volSrcArgs *migration.VolumeSourceArgs, op *operations.Operation) error {
+	return genericVFSMigrateVolume(d, d.state, vol, conn, volSrcArgs, op)
+}
+
+// BackupVolume copies a volume (and optionally its snapshots) to a specified target path.
+// This driver does not support optimized backups.
+func (d *nfs) BackupVolume(vol VolumeCopy, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots []string, incrementalFrom string, op *operations.Operation) error {
+	return genericVFSBackupVolume(d, vol, tarWriter, snapshots, incrementalFrom, op)
+}
+
+// CreateVolumeSnapshot creates a snapshot of a volume.
+func (d *nfs) CreateVolumeSnapshot(snapVol Volume, op *operations.Operation) error {
+	parentName, _, _ := api.GetParentAndSnapshotName(snapVol.name)
+
+	// Create snapshot directory.
+	err := snapVol.EnsureMountPath()
+	if err != nil {
+		return err
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	snapPath := snapVol.MountPath()
+	revert.Add(func() { _ = os.RemoveAll(snapPath) })
+
+	bwlimit := d.config["rsync.bwlimit"]
+	srcPath := GetVolumeMountPath(d.name, snapVol.volType, parentName)
+	d.Logger().Debug("Copying filesystem volume", logger.Ctx{"sourcePath": srcPath, "targetPath": snapPath, "bwlimit": bwlimit})
+
+	// Copy filesystem volume into snapshot directory.
+	_, err = rsync.LocalCopy(srcPath, snapPath, bwlimit, false)
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+	return nil
+}
+
+// DeleteVolumeSnapshot removes a snapshot from the storage device. The volName and snapshotName
+// must be bare names and should not be in the format "volume/snapshot".
+func (d *nfs) DeleteVolumeSnapshot(snapVol Volume, op *operations.Operation) error {
+	snapPath := snapVol.MountPath()
+
+	// Remove the snapshot from the storage device.
+	err := forceRemoveAll(snapPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("Failed to remove '%s': %w", snapPath, err)
+	}
+
+	parentName, _, _ := api.GetParentAndSnapshotName(snapVol.name)
+
+	// Remove the parent snapshot directory if this is the last snapshot being removed.
+	err = deleteParentSnapshotDirIfEmpty(d.name, snapVol.volType, parentName)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MountVolumeSnapshot sets up a read-only mount on top of the snapshot to avoid accidental modifications.
+func (d *nfs) MountVolumeSnapshot(snapVol Volume, op *operations.Operation) error {
+	unlock, err := snapVol.MountLock()
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	snapPath := snapVol.MountPath()
+
+	// Don't attempt to modify the permission of an existing custom volume root.
+	// A user inside the instance may have modified this and we don't want to reset it on restart.
+	if !shared.PathExists(snapPath) || snapVol.volType != VolumeTypeCustom {
+		err := snapVol.EnsureMountPath()
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = mountReadOnly(snapPath, snapPath)
+	if err != nil {
+		return err
+	}
+
+	snapVol.MountRefCountIncrement() // From here on it is up to caller to call UnmountVolumeSnapshot() when done.
+	return nil
+}
+
+// UnmountVolumeSnapshot removes the read-only mount placed on top of a snapshot.
+func (d *nfs) UnmountVolumeSnapshot(snapVol Volume, op *operations.Operation) (bool, error) {
+	unlock, err := snapVol.MountLock()
+	if err != nil {
+		return false, err
+	}
+
+	defer unlock()
+
+	mountPath := snapVol.MountPath()
+
+	refCount := snapVol.MountRefCountDecrement()
+
+	if filesystem.IsMountPoint(mountPath) {
+		if refCount > 0 {
+			d.logger.Debug("Skipping unmount as in use", logger.Ctx{"volName": snapVol.name, "refCount": refCount})
+			return false, ErrInUse
+		}
+
+		return forceUnmount(mountPath)
+	}
+
+	return false, nil
+}
+
+// VolumeSnapshots returns a list of snapshots for the volume (in no particular order).
+func (d *nfs) VolumeSnapshots(vol Volume, op *operations.Operation) ([]string, error) {
+	return genericVFSVolumeSnapshots(d, vol, op)
+}
+
+// RestoreVolume restores a volume from a snapshot.
+func (d *nfs) RestoreVolume(vol Volume, snapVol Volume, op *operations.Operation) error {
+	_, snapshotName, _ := api.GetParentAndSnapshotName(snapVol.name)
+	snapVol, err := vol.NewSnapshot(snapshotName)
+	if err != nil {
+		return err
+	}
+
+	srcPath := snapVol.MountPath()
+	if !shared.PathExists(srcPath) {
+		return fmt.Errorf("Snapshot not found")
+	}
+
+	bwlimit := d.config["rsync.bwlimit"]
+	_, err = rsync.LocalCopy(srcPath, vol.MountPath(), bwlimit, false)
+	if err != nil {
+		return fmt.Errorf("Failed to rsync volume: %w", err)
+	}
+
+	return nil
+}
+
+// RenameVolumeSnapshot renames a volume snapshot.
+func (d *nfs) RenameVolumeSnapshot(snapVol Volume, newSnapshotName string, op *operations.Operation) error {
+	return genericVFSRenameVolumeSnapshot(d, snapVol, newSnapshotName, op)
+}
@@ -20,6 +20,12 @@ type Info struct {
 	MountedRoot                  bool         // Whether the pool directory itself is a mount.
 }
 
+// VolumeVerifyResult represents the result of checking the integrity of a volume.
+type VolumeVerifyResult struct {
+	Problems   []string // Problems found with the volume.
+	Unverified []string // Parts of the volume that couldn't be checked.
+}
+
 // VolumeFiller provides a struct for filling a volume.
 type VolumeFiller struct {
 	Fill func(vol Volume, rootBlockPath string, allowUnsafeResize bool) (int64, error) // Function to fill the volume.
@@ -237,7 +237,7 @@ func (d *zfs) FillConfig() error {
 		d.config["size"] = ""
 	}
 
-	return nil
+	return luksFillConfig(d.config)
 }
 
 // Create is called during pool creation and is effectively using an empty driver struct.
@@ -476,6 +476,10 @@ func (d *zfs) Validate(config map[string]string) error {
 		"zfs.export": validate.Optional(validate.IsBool),
 	}
 
+	for k, v := range luksPoolRules() {
+		rules[k] = v
+	}
+
 	return d.validatePool(config, rules, d.commonVolumeRules())
 }
 
@@ -486,6 +490,11 @@ func (d *zfs) Update(changedConfig map[string]string) error {
 		return fmt.Errorf("zfs.pool_name cannot be modified")
 	}
 
+	_, ok = changedConfig["volatile.encryption.key"]
+	if ok {
+		return fmt.Errorf("volatile.encryption.key cannot be modified")
+	}
+
 	size, ok := changedConfig["size"]
 	if ok {
 		// Figure out loop path
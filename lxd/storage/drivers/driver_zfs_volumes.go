@@ -83,8 +83,8 @@ func (d *zfs) CreateVolume(vol Volume, filler *VolumeFiller, op *operations.Oper
 					return err
 				}
 
-				// Round to block boundary.
-				poolVolSizeBytes = d.roundVolumeBlockSizeBytes(vol, poolVolSizeBytes)
+				// Round to block boundary and reserve space for the encryption header.
+				poolVolSizeBytes = d.luksSizeBytes(vol, d.roundVolumeBlockSizeBytes(vol, poolVolSizeBytes))
 
 				// If the cached volume size is different than the pool volume size, then we can't use the
 				// deleted cached image volume and instead we will rename it to a random UUID so it can't
@@ -161,8 +161,8 @@ func (d *zfs) CreateVolume(vol Volume, filler *VolumeFiller, op *operations.Oper
 	} else {
 		var opts []string
 
-		if vol.contentType == ContentTypeFS {
-			// Use volmode=dev so volume is visible as we need to run makeFSType.
+		if vol.contentType == ContentTypeFS || d.volumeEncrypted(vol) {
+			// Use volmode=dev so volume is visible as we need to run makeFSType or luksFormat.
 			opts = []string{"volmode=dev"}
 		} else {
 			// Use volmode=none so volume is invisible until mounted.
@@ -207,7 +207,7 @@ func (d *zfs) CreateVolume(vol Volume, filler *VolumeFiller, op *operations.Oper
 			return err
 		}
 
-		sizeBytes = d.roundVolumeBlockSizeBytes(vol, sizeBytes)
+		sizeBytes = d.luksSizeBytes(vol, d.roundVolumeBlockSizeBytes(vol, sizeBytes))
 
 		// Create the volume dataset.
 		err = d.createVolume(d.dataset(vol, false), sizeBytes, opts...)
@@ -215,17 +215,29 @@ func (d *zfs) CreateVolume(vol Volume, filler *VolumeFiller, op *operations.Oper
 			return err
 		}
 
-		if vol.contentType == ContentTypeFS {
-			devPath, err := d.GetVolumeDiskPath(vol)
+		if vol.contentType == ContentTypeFS || d.volumeEncrypted(vol) {
+			devPath, err := d.zvolDevPath(vol)
 			if err != nil {
 				return err
 			}
 
-			zfsFilesystem := vol.ConfigBlockFilesystem()
+			if d.volumeEncrypted(vol) {
+				err = d.luksFormat(vol, devPath)
+				if err != nil {
+					return err
+				}
+			}
+
+			if vol.contentType == ContentTypeFS {
+				zfsFilesystem := vol.ConfigBlockFilesystem()
 
-			_, err = makeFSType(devPath, zfsFilesystem, nil)
-			if err != nil {
-				return err
+				err = d.luksTask(vol, devPath, func(devPath string) error {
+					_, err := makeFSType(devPath, zfsFilesystem, nil)
+					return err
+				})
+				if err != nil {
+					return err
+				}
 			}
 
 			err = d.setDatasetProperties(d.dataset(vol, false), "volmode=none")
@@ -340,7 +352,7 @@ func (d *zfs) CreateVolume(vol Volume, filler *VolumeFiller, op *operations.Oper
 func (d *zfs) CreateVolumeFromBackup(vol VolumeCopy, srcBackup backup.Info, srcData io.ReadSeeker, op *operations.Operation) (VolumePostHook, revert.Hook, error) {
 	// Handle the non-optimized tarballs through the generic unpacker.
 	if !*srcBackup.OptimizedStorage {
-		return genericVFSBackupUnpack(d, d.state.OS, vol, srcBackup.Snapshots, srcData, op)
+		return genericVFSBackupUnpack(d, d.state.OS, vol, srcBackup.Snapshots, srcBackup.IncrementalFrom, srcData, op)
 	}
 
 	volExists, err := d.HasVolume(vol.Volume)
@@ -348,7 +360,10 @@ func (d *zfs) CreateVolumeFromBackup(vol VolumeCopy, srcBackup backup.Info, srcD
 		return nil, nil, err
 	}
 
-	if volExists {
+	// Incremental backups are received on top of the existing volume.
+	if srcBackup.IncrementalFrom != "" && !volExists {
+		return nil, nil, fmt.Errorf("Cannot apply incremental backup, volume doesn't exist on target")
+	} else if srcBackup.IncrementalFrom == "" && volExists {
 		return nil, nil, fmt.Errorf("Cannot restore volume, already exists on target")
 	}
 
@@ -364,7 +379,17 @@ func (d *zfs) CreateVolumeFromBackup(vol VolumeCopy, srcBackup backup.Info, srcD
 			_ = d.DeleteVolumeSnapshot(snapVol, op)
 		}
 
-		// And lastly the main volume.
+		// And lastly the main volume, which for incremental backups is reset to the snapshot the
+		// backup is incremental from instead.
+		if srcBackup.IncrementalFrom != "" {
+			baseSnapVol, err := vol.NewSnapshot(srcBackup.IncrementalFrom)
+			if err == nil {
+				_ = d.RestoreVolume(vol.Volume, baseSnapVol, op)
+			}
+
+			return
+		}
+
 		_ = d.DeleteVolume(vol.Volume, op)
 	}
 
@@ -553,6 +578,28 @@ func (d *zfs) CreateVolumeFromCopy(vol VolumeCopy, srcVol VolumeCopy, allowIncon
 	revert := revert.New()
 	defer revert.Fail()
 
+	// Copies of zvols keep the encryption of their source, so use the generic copy if the encryption
+	// requested for the new volume differs.
+	if d.volumeEncrypted(vol.Volume) != d.volumeEncrypted(srcVol.Volume) {
+		var srcSnapshots []string
+
+		if len(vol.Snapshots) > 0 && !srcVol.IsSnapshot() {
+			// Get the list of snapshots from the source.
+			allSrcSnapshots, err := srcVol.Volume.Snapshots(op)
+			if err != nil {
+				return err
+			}
+
+			for _, srcSnapshot := range allSrcSnapshots {
+				_, snapshotName, _ := api.GetParentAndSnapshotName(srcSnapshot.name)
+				srcSnapshots = append(srcSnapshots, snapshotName)
+			}
+		}
+
+		_, err = genericVFSCopyVolume(d, nil, vol, srcVol, srcSnapshots, false, allowInconsistent, op)
+		return err
+	}
+
 	if vol.contentType == ContentTypeFS {
 		// Create mountpoint.
 		err = vol.EnsureMountPath()
@@ -1223,6 +1270,12 @@ func (d *zfs) RefreshVolume(vol VolumeCopy, srcVol VolumeCopy, refreshSnapshots
 	var targetSnapshots []Volume
 	var srcSnapshotsAll []Volume
 
+	// Use the generic refresh if the encryption of the target differs from the source.
+	if d.volumeEncrypted(vol.Volume) != d.volumeEncrypted(srcVol.Volume) {
+		_, err = genericVFSCopyVolume(d, nil, vol, srcVol, refreshSnapshots, true, allowInconsistent, op)
+		return err
+	}
+
 	if !srcVol.IsSnapshot() {
 		// Get target snapshots
 		targetSnapshots, err = vol.Volume.Snapshots(op)
@@ -1499,6 +1552,12 @@ func (d *zfs) deleteVolume(vol Volume, op *operations.Operation) error {
 	}
 
 	if exists {
+		// Close the encrypted volume (if open) so that the zvol isn't held open.
+		_, err = d.luksClose(vol)
+		if err != nil {
+			return err
+		}
+
 		// Handle clones.
 		clones, err := d.getClones(d.dataset(vol, false))
 		if err != nil {
@@ -1571,6 +1630,16 @@ func (d *zfs) commonVolumeRules() map[string]func(value string) error {
 		//  defaultdesc: same as `volume.block.mount_options`
 		//  shortdesc: Mount options for block-backed file system volumes
 		"block.mount_options": validate.IsAny,
+		// lxdmeta:generate(entities=storage-zfs; group=volume-conf; key=encryption)
+		// When enabled, the volume is encrypted with LUKS using a key that is held by LXD in the storage
+		// pool configuration (see `encryption.project_keys`).
+		// This option cannot be changed after the volume has been created.
+		// ---
+		//  type: bool
+		//  condition: block-based volume (`zfs.block_mode` enabled or content type `block`)
+		//  defaultdesc: same as `volume.encryption` or `false`
+		//  shortdesc: Whether to encrypt the storage volume
+		"encryption": validate.Optional(validate.IsBool),
 		// lxdmeta:generate(entities=storage-zfs; group=volume-conf; key=zfs.block_mode)
 		// `zfs.block_mode` can be set only for custom storage volumes.
 		// To enable ZFS block mode for all storage volumes in the pool, including instance volumes,
@@ -1641,11 +1710,21 @@ func (d *zfs) ValidateVolume(vol Volume, removeUnknownKeys bool) error {
 		delete(commonRules, "block.mount_options")
 	}
 
+	// Only volumes backed by a zvol can be encrypted.
+	if vol.contentType == ContentTypeFS && shared.IsFalseOrEmpty(vol.ExpandedConfig("zfs.block_mode")) {
+		delete(commonRules, "encryption")
+	}
+
 	return d.validateVolume(vol, commonRules, removeUnknownKeys)
 }
 
 // UpdateVolume applies config changes to the volume.
 func (d *zfs) UpdateVolume(vol Volume, changedConfig map[string]string) error {
+	_, changed := changedConfig["encryption"]
+	if changed {
+		return fmt.Errorf("encryption cannot be changed")
+	}
+
 	// Mangle the current volume to its old values.
 	old := make(map[string]string)
 	for k, v := range changedConfig {
@@ -1745,6 +1824,9 @@ func (d *zfs) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op
 
 		sizeBytes = d.roundVolumeBlockSizeBytes(vol, sizeBytes)
 
+		// Encrypted volumes need room for the encryption header on top of the requested size.
+		volSizeBytes := d.luksSizeBytes(vol, sizeBytes)
+
 		oldSizeBytesStr, err := d.getDatasetProperty(d.dataset(vol, false), "volsize")
 		if err != nil {
 			return err
@@ -1757,7 +1839,7 @@ func (d *zfs) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op
 
 		oldVolSizeBytes := int64(oldVolSizeBytesInt)
 
-		if oldVolSizeBytes == sizeBytes {
+		if oldVolSizeBytes == volSizeBytes {
 			return nil
 		}
 
@@ -1785,7 +1867,7 @@ func (d *zfs) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op
 
 			l := d.logger.AddContext(logger.Ctx{"dev": volDevPath, "size": fmt.Sprintf("%db", sizeBytes)})
 
-			if sizeBytes < oldVolSizeBytes {
+			if volSizeBytes < oldVolSizeBytes {
 				if !filesystemTypeCanBeShrunk(fsType) {
 					return fmt.Errorf("Filesystem %q cannot be shrunk: %w", fsType, ErrCannotBeShrunk)
 				}
@@ -1803,14 +1885,26 @@ func (d *zfs) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op
 
 				l.Debug("ZFS volume filesystem shrunk")
 
+				// Shrink the encrypted volume if open so it still fits on the block device.
+				err = d.luksResize(vol, sizeBytes)
+				if err != nil {
+					return err
+				}
+
 				// Shrink the block device.
-				err = d.setDatasetProperties(d.dataset(vol, false), fmt.Sprintf("volsize=%d", sizeBytes))
+				err = d.setDatasetProperties(d.dataset(vol, false), fmt.Sprintf("volsize=%d", volSizeBytes))
 				if err != nil {
 					return err
 				}
-			} else if sizeBytes > oldVolSizeBytes {
+			} else if volSizeBytes > oldVolSizeBytes {
 				// Grow block device first.
-				err = d.setDatasetProperties(d.dataset(vol, false), fmt.Sprintf("volsize=%d", sizeBytes))
+				err = d.setDatasetProperties(d.dataset(vol, false), fmt.Sprintf("volsize=%d", volSizeBytes))
+				if err != nil {
+					return err
+				}
+
+				// Grow the encrypted volume to fill the block device if open.
+				err = d.luksResize(vol, 0)
 				if err != nil {
 					return err
 				}
@@ -1834,7 +1928,7 @@ func (d *zfs) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op
 			// Only perform pre-resize checks if we are not in "unsafe" mode.
 			// In unsafe mode we expect the caller to know what they are doing and understand the risks.
 			if !allowUnsafeResize {
-				if sizeBytes < oldVolSizeBytes {
+				if volSizeBytes < oldVolSizeBytes {
 					return fmt.Errorf("Block volumes cannot be shrunk: %w", ErrCannotBeShrunk)
 				}
 
@@ -1843,10 +1937,26 @@ func (d *zfs) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op
 				}
 			}
 
-			err = d.setDatasetProperties(d.dataset(vol, false), fmt.Sprintf("volsize=%d", sizeBytes))
+			// Shrink the encrypted volume first if open so it still fits on the block device.
+			if volSizeBytes < oldVolSizeBytes {
+				err = d.luksResize(vol, sizeBytes)
+				if err != nil {
+					return err
+				}
+			}
+
+			err = d.setDatasetProperties(d.dataset(vol, false), fmt.Sprintf("volsize=%d", volSizeBytes))
 			if err != nil {
 				return err
 			}
+
+			// Grow the encrypted volume to fill the block device if open.
+			if volSizeBytes > oldVolSizeBytes {
+				err = d.luksResize(vol, 0)
+				if err != nil {
+					return err
+				}
+			}
 		}
 
 		// Move the VM GPT alt header to end of disk if needed (not needed in unsafe resize mode as
@@ -1973,6 +2083,15 @@ func (d *zfs) getVolumeDiskPathFromDataset(dataset string) (string, error) {
 
 // GetVolumeDiskPath returns the location of a root disk block device.
 func (d *zfs) GetVolumeDiskPath(vol Volume) (string, error) {
+	if d.volumeEncrypted(vol) {
+		return d.luksDevPath(vol), nil
+	}
+
+	return d.zvolDevPath(vol)
+}
+
+// zvolDevPath returns the location of the zvol block device of the volume.
+func (d *zfs) zvolDevPath(vol Volume) (string, error) {
 	// Wait up to 30 seconds for the device to appear.
 	// Don't use d.state.ShutdownCtx here as this is used during instance stop during LXD shutdown after it is
 	// canceled.
@@ -2111,6 +2230,7 @@ func (d *zfs) activateVolume(vol Volume) (bool, error) {
 	defer revert.Fail()
 
 	dataset := d.dataset(vol, false)
+	activated := false
 
 	// Check if already active.
 	current, err := d.getDatasetProperty(dataset, "volmode")
@@ -2127,18 +2247,35 @@ func (d *zfs) activateVolume(vol Volume) (bool, error) {
 
 		revert.Add(func() { _ = d.setDatasetProperties(dataset, fmt.Sprintf("volmode=%s", current)) })
 
-		_, err := d.GetVolumeDiskPath(vol)
+		_, err := d.zvolDevPath(vol)
 		if err != nil {
 			return false, fmt.Errorf("Failed to activate volume: %v", err)
 		}
 
 		d.logger.Debug("Activated ZFS volume", logger.Ctx{"volName": vol.Name(), "dev": dataset})
 
-		revert.Success()
-		return true, nil
+		activated = true
 	}
 
-	return false, nil
+	// Open the encrypted volume if needed.
+	if d.volumeEncrypted(vol) {
+		devPath, err := d.zvolDevPath(vol)
+		if err != nil {
+			return false, err
+		}
+
+		opened, err := d.luksOpen(vol, devPath, false)
+		if err != nil {
+			return false, err
+		}
+
+		if opened {
+			activated = true
+		}
+	}
+
+	revert.Success()
+	return activated, nil
 }
 
 // deactivateVolume deactivates a ZFS volume if activate. Returns true if deactivated, false if not.
@@ -2156,11 +2293,17 @@ func (d *zfs) deactivateVolume(vol Volume) (bool, error) {
 	}
 
 	if current == "dev" {
-		devPath, err := d.GetVolumeDiskPath(vol)
+		devPath, err := d.zvolDevPath(vol)
 		if err != nil {
 			return false, fmt.Errorf("Failed locating zvol for deactivation: %w", err)
 		}
 
+		// Close the encrypted volume (if open) so that the zvol isn't held open.
+		_, err = d.luksClose(vol)
+		if err != nil {
+			return false, err
+		}
+
 		// We cannot wait longer than the operationlock.TimeoutShutdown to avoid continuing
 		// the unmount process beyond the ongoing request.
 		waitDuration := time.Minute * 5
@@ -2754,7 +2897,7 @@ func (d *zfs) readonlySnapshot(vol Volume) (string, revert.Hook, error) {
 }
 
 // BackupVolume creates an exported version of a volume.
-func (d *zfs) BackupVolume(vol VolumeCopy, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots []string, op *operations.Operation) error {
+func (d *zfs) BackupVolume(vol VolumeCopy, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots []string, incrementalFrom string, op *operations.Operation) error {
 	// Handle the non-optimized tarballs through the generic packer.
 	if !optimized {
 		// Because the generic backup method will not take a consistent backup if files are being modified
@@ -2773,12 +2916,12 @@ func (d *zfs) BackupVolume(vol VolumeCopy, tarWriter *instancewriter.InstanceTar
 			vol.mountCustomPath = snapshotPath
 		}
 
-		return genericVFSBackupVolume(d, vol, tarWriter, snapshots, op)
+		return genericVFSBackupVolume(d, vol, tarWriter, snapshots, incrementalFrom, op)
 	}
 
 	// Optimized backup.
 
-	if len(snapshots) > 0 {
+	if len(snapshots) > 0 || incrementalFrom != "" {
 		// Check requested snapshot match those in storage.
 		err := d.CheckVolumeSnapshots(vol.Volume, vol.Snapshots, op)
 		if err != nil {
@@ -2789,7 +2932,7 @@ func (d *zfs) BackupVolume(vol VolumeCopy, tarWriter *instancewriter.InstanceTar
 	// Backup VM config volumes first.
 	if vol.IsVMBlock() {
 		fsVol := NewVolumeCopy(vol.NewVMBlockFilesystemVolume())
-		err := d.BackupVolume(fsVol, tarWriter, optimized, snapshots, op)
+		err := d.BackupVolume(fsVol, tarWriter, optimized, snapshots, incrementalFrom, op)
 		if err != nil {
 			return err
 		}
@@ -2848,14 +2991,26 @@ func (d *zfs) BackupVolume(vol VolumeCopy, tarWriter *instancewriter.InstanceTar
 		return tmpFile.Close()
 	}
 
+	// For incremental backups, the first stream is sent relative to the snapshot the backup is
+	// incremental from.
+	baseParent := ""
+	if incrementalFrom != "" {
+		baseSnapshot, err := vol.NewSnapshot(incrementalFrom)
+		if err != nil {
+			return err
+		}
+
+		baseParent = d.dataset(baseSnapshot, false)
+	}
+
 	// Handle snapshots.
-	finalParent := ""
+	finalParent := baseParent
 	if len(snapshots) > 0 {
 		for i, snapName := range snapshots {
 			snapshot, _ := vol.NewSnapshot(snapName)
 
 			// Figure out parent and current subvolumes.
-			parent := ""
+			parent := baseParent
 			if i > 0 {
 				oldSnapshot, _ := vol.NewSnapshot(snapshots[i-1])
 				parent = d.dataset(oldSnapshot, false)
@@ -3153,6 +3308,20 @@ func (d *zfs) mountVolumeSnapshot(snapVol Volume, snapshotDataset string, mountP
 				return nil, err
 			}
 
+			// Open the encrypted volume if needed. Only the temporary snapshot is writable.
+			if d.volumeEncrypted(mountVol) {
+				opened, err := d.luksOpen(mountVol, volPath, !regenerateFSUUID)
+				if err != nil {
+					return nil, err
+				}
+
+				if opened {
+					revert.Add(func() { _, _ = d.luksClose(mountVol) })
+				}
+
+				volPath = d.luksDevPath(mountVol)
+			}
+
 			tmpVolFsType := mountVol.ConfigBlockFilesystem()
 
 			if regenerateFSUUID {
@@ -3189,6 +3358,23 @@ func (d *zfs) mountVolumeSnapshot(snapVol Volume, snapshotDataset string, mountP
 			}
 		}
 
+		// Open the encrypted block snapshot if needed.
+		if snapVol.contentType == ContentTypeBlock && d.volumeEncrypted(snapVol) {
+			volPath, err := d.getVolumeDiskPathFromDataset(snapshotDataset)
+			if err != nil {
+				return nil, err
+			}
+
+			opened, err := d.luksOpen(snapVol, volPath, true)
+			if err != nil {
+				return nil, err
+			}
+
+			if opened {
+				revert.Add(func() { _, _ = d.luksClose(snapVol) })
+			}
+		}
+
 		if snapVol.IsVMBlock() {
 			// For VMs, also mount the filesystem dataset.
 			fsVol := snapVol.NewVMBlockFilesystemVolume()
@@ -3255,6 +3441,15 @@ func (d *zfs) UnmountVolumeSnapshot(snapVol Volume, op *operations.Operation) (b
 			d.logger.Debug("Unmounted ZFS snapshot dataset", logger.Ctx{"dev": snapshotDataset, "path": mountPath})
 			ourUnmount = true
 
+			// Close the encrypted snapshot or its temporary writable clone if open.
+			tmpVol := NewVolume(d, d.name, snapVol.volType, snapVol.contentType, fmt.Sprintf("%s%s", snapVol.name, tmpVolSuffix), snapVol.config, snapVol.poolConfig)
+			for _, v := range []Volume{snapVol, tmpVol} {
+				_, err = d.luksClose(v)
+				if err != nil {
+					return true, err
+				}
+			}
+
 			parent, snapshotOnlyName, _ := api.GetParentAndSnapshotName(snapVol.Name())
 			parentVol := NewVolume(d, d.Name(), snapVol.volType, snapVol.contentType, parent, snapVol.config, snapVol.poolConfig)
 			parentDataset := d.dataset(parentVol, false)
@@ -3288,7 +3483,13 @@ func (d *zfs) UnmountVolumeSnapshot(snapVol Volume, op *operations.Operation) (b
 				return false, ErrInUse
 			}
 
-			err := d.setDatasetProperties(parentDataset, "snapdev=hidden")
+			// Close the encrypted block snapshot if open.
+			_, err = d.luksClose(snapVol)
+			if err != nil {
+				return false, err
+			}
+
+			err = d.setDatasetProperties(parentDataset, "snapdev=hidden")
 			if err != nil {
 				return false, err
 			}
@@ -3441,6 +3642,54 @@ func (d *zfs) restoreVolume(vol Volume, snapVol Volume, migration bool, op *oper
 	return nil
 }
 
+// VerifyVolume checks the zpool status for errors affecting the volume or its snapshots.
+// This relies on the results of the most recent scrub of the zpool and on errors found while reading data.
+func (d *zfs) VerifyVolume(vol Volume, op *operations.Operation) (*VolumeVerifyResult, error) {
+	poolName, _, _ := strings.Cut(d.config["zfs.pool_name"], "/")
+
+	out, err := shared.RunCommandCLocale("zpool", "status", "-v", poolName)
+	if err != nil {
+		return nil, err
+	}
+
+	datasets := []string{d.dataset(vol, false)}
+	if vol.IsVMBlock() {
+		fsVol := vol.NewVMBlockFilesystemVolume()
+		datasets = append(datasets, d.dataset(fsVol, false))
+	}
+
+	result := &VolumeVerifyResult{Problems: []string{}}
+	inErrors := false
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+
+		key, value, found := strings.Cut(line, ":")
+		if found && key == "state" && strings.TrimSpace(value) != "ONLINE" {
+			result.Problems = append(result.Problems, fmt.Sprintf("Pool %q is in state %q", poolName, strings.TrimSpace(value)))
+			continue
+		}
+
+		if found && key == "errors" {
+			inErrors = true
+			continue
+		}
+
+		if !inErrors || line == "" {
+			continue
+		}
+
+		// Permanent errors are listed as either a file path or "<dataset>[@<snapshot>]:<path>".
+		for _, dataset := range datasets {
+			if strings.HasPrefix(line, dataset+":") || strings.HasPrefix(line, dataset+"@") || strings.HasPrefix(line, vol.MountPath()+"/") {
+				result.Problems = append(result.Problems, fmt.Sprintf("Permanent error in %q", line))
+				break
+			}
+		}
+	}
+
+	return result, nil
+}
+
 // RenameVolumeSnapshot renames a volume snapshot.
 func (d *zfs) RenameVolumeSnapshot(vol Volume, newSnapshotName string, op *operations.Operation) error {
 	parentName, _, _ := api.GetParentAndSnapshotName(vol.name)
@@ -3502,11 +3751,23 @@ func (d *zfs) FillVolumeConfig(vol Volume) error {
 		excludedKeys = []string{"block.filesystem", "block.mount_options"}
 	}
 
+	// Volumes created from a source keep the encryption of their source.
+	if vol.hasSource {
+		excludedKeys = append(excludedKeys, "encryption")
+	}
+
+	_, encryptionSet := vol.config["encryption"]
+
 	err := d.fillVolumeConfig(&vol, excludedKeys...)
 	if err != nil {
 		return err
 	}
 
+	// Don't inherit encryption for volumes that aren't backed by a zvol.
+	if !encryptionSet && vol.contentType == ContentTypeFS && !d.isBlockBacked(vol) {
+		delete(vol.config, "encryption")
+	}
+
 	// Only validate filesystem config keys for filesystem volumes.
 	if d.isBlockBacked(vol) && vol.ContentType() == ContentTypeFS {
 		// Inherit block mode from pool if not set.
@@ -1,6 +1,7 @@
 package drivers
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -191,7 +192,8 @@ func genericVFSMigrateVolume(d Driver, s *state.State, vol VolumeCopy, conn io.R
 	}
 
 	// Define function to send a block volume.
-	sendBlockVol := func(vol Volume, conn io.ReadWriteCloser) error {
+	// If parentVol is set, only the changes since the parent volume are sent.
+	sendBlockVol := func(vol Volume, parentVol *Volume, conn io.ReadWriteCloser) error {
 		// Close when done to indicate to target side we are finished sending this volume.
 		defer func() { _ = conn.Close() }()
 
@@ -212,6 +214,40 @@ func genericVFSMigrateVolume(d Driver, s *state.State, vol VolumeCopy, conn io.R
 
 		defer func() { _ = from.Close() }()
 
+		if parentVol != nil {
+			parentPath, err := d.GetVolumeDiskPath(*parentVol)
+			if err != nil {
+				return fmt.Errorf("Error getting parent block volume disk path: %w", err)
+			}
+
+			size, err := block.DiskSizeBytes(path)
+			if err != nil {
+				return fmt.Errorf("Error getting block device size %q: %w", path, err)
+			}
+
+			extents, err := blockVolumeDeltaExtents(d, vol, path, size, *parentVol, parentPath)
+			if err != nil {
+				return err
+			}
+
+			// Setup progress tracker.
+			toPipe := io.WriteCloser(conn)
+			if wrapper != nil {
+				toPipe = &ioprogress.ProgressWriter{
+					WriteCloser: toPipe,
+					Tracker:     wrapper,
+				}
+			}
+
+			d.Logger().Debug("Sending block volume changes", logger.Ctx{"volName": vol.name, "path": path, "parentPath": parentPath, "size": blockDeltaSize(extents)})
+			err = writeBlockDelta(toPipe, from, size, extents)
+			if err != nil {
+				return fmt.Errorf("Error copying changes of %q to migration connection: %w", path, err)
+			}
+
+			return from.Close()
+		}
+
 		// Setup progress tracker.
 		fromPipe := io.ReadCloser(from)
 		if wrapper != nil {
@@ -235,6 +271,20 @@ func genericVFSMigrateVolume(d Driver, s *state.State, vol VolumeCopy, conn io.R
 		return nil
 	}
 
+	// Define function to run f with the parent volume (if any) mounted, so that block deltas can be generated.
+	parentMountTask := func(parentVol *Volume, f func() error) error {
+		if parentVol == nil {
+			return f()
+		}
+
+		return parentVol.MountTask(func(_ string, _ *operations.Operation) error {
+			return f()
+		}, op)
+	}
+
+	// The previously sent snapshot, which block deltas are generated against.
+	var parentVol *Volume
+
 	// Send all snapshots to target.
 	for _, snapName := range volSrcArgs.Snapshots {
 		found := false
@@ -253,46 +303,54 @@ func genericVFSMigrateVolume(d Driver, s *state.State, vol VolumeCopy, conn io.R
 		}
 
 		// Send snapshot to target (ensure local snapshot volume is mounted if needed).
-		err := snapVol.MountTask(func(mountPath string, op *operations.Operation) error {
-			if vol.contentType != ContentTypeBlock || vol.volType != VolumeTypeCustom {
-				err := sendFSVol(snapVol, conn, mountPath)
-				if err != nil {
-					return err
+		err := parentMountTask(parentVol, func() error {
+			return snapVol.MountTask(func(mountPath string, op *operations.Operation) error {
+				if vol.contentType != ContentTypeBlock || vol.volType != VolumeTypeCustom {
+					err := sendFSVol(snapVol, conn, mountPath)
+					if err != nil {
+						return err
+					}
 				}
-			}
 
-			if vol.IsVMBlock() || (vol.contentType == ContentTypeBlock && vol.volType == VolumeTypeCustom) {
-				err := sendBlockVol(snapVol, conn)
-				if err != nil {
-					return err
+				if vol.IsVMBlock() || (vol.contentType == ContentTypeBlock && vol.volType == VolumeTypeCustom) {
+					err := sendBlockVol(snapVol, parentVol, conn)
+					if err != nil {
+						return err
+					}
 				}
-			}
 
-			return nil
-		}, op)
+				return nil
+			}, op)
+		})
 		if err != nil {
 			return err
 		}
+
+		if volSrcArgs.BlockDelta {
+			parentVol = &snapVol
+		}
 	}
 
 	// Send volume to target (ensure local volume is mounted if needed).
-	return vol.MountTask(func(mountPath string, op *operations.Operation) error {
-		if !IsContentBlock(vol.contentType) || vol.volType != VolumeTypeCustom {
-			err := sendFSVol(vol.Volume, conn, mountPath)
-			if err != nil {
-				return err
+	return parentMountTask(parentVol, func() error {
+		return vol.MountTask(func(mountPath string, op *operations.Operation) error {
+			if !IsContentBlock(vol.contentType) || vol.volType != VolumeTypeCustom {
+				err := sendFSVol(vol.Volume, conn, mountPath)
+				if err != nil {
+					return err
+				}
 			}
-		}
 
-		if vol.IsVMBlock() || (IsContentBlock(vol.contentType) && vol.volType == VolumeTypeCustom) {
-			err := sendBlockVol(vol.Volume, conn)
-			if err != nil {
-				return err
+			if vol.IsVMBlock() || (IsContentBlock(vol.contentType) && vol.volType == VolumeTypeCustom) {
+				err := sendBlockVol(vol.Volume, parentVol, conn)
+				if err != nil {
+					return err
+				}
 			}
-		}
 
-		return nil
-	}, op)
+			return nil
+		}, op)
+	})
 }
 
 // genericVFSCreateVolumeFromMigration receives a volume and its snapshots over a non-optimized method.
@@ -332,13 +390,25 @@ func genericVFSCreateVolumeFromMigration(d Driver, initVolume func(vol Volume) (
 		return rsync.Recv(path, conn, wrapper, volTargetArgs.MigrationType.Features)
 	}
 
+	// Whether a block volume was received already, which the next ones are deltas against if BlockDelta is set.
+	blockReceived := false
+
 	recvBlockVol := func(volName string, conn io.ReadWriteCloser, path string) error {
 		var wrapper *ioprogress.ProgressTracker
 		if volTargetArgs.TrackProgress {
 			wrapper = migration.ProgressTracker(op, "block_progress", volName)
 		}
 
-		to, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0)
+		delta := volTargetArgs.BlockDelta && blockReceived
+		blockReceived = true
+
+		flags := os.O_WRONLY | os.O_TRUNC
+		if delta {
+			// Only the changed blocks are written, leaving the others shared with the previous snapshot.
+			flags = os.O_WRONLY
+		}
+
+		to, err := os.OpenFile(path, flags, 0)
 		if err != nil {
 			return fmt.Errorf("Error opening file for writing %q: %w", path, err)
 		}
@@ -354,6 +424,35 @@ func genericVFSCreateVolumeFromMigration(d Driver, initVolume func(vol Volume) (
 			}
 		}
 
+		if delta {
+			d.Logger().Debug("Receiving block volume changes started", logger.Ctx{"volName": volName, "path": path})
+			defer d.Logger().Debug("Receiving block volume changes stopped", logger.Ctx{"volName": volName, "path": path})
+
+			err = applyBlockDelta(fromPipe, to, func(size int64) error {
+				curSize, err := block.DiskSizeBytes(path)
+				if err != nil {
+					return err
+				}
+
+				if size > curSize {
+					return fmt.Errorf("Block volume %q is smaller than the received volume (%d < %d bytes)", path, curSize, size)
+				}
+
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("Error applying changes from migration connection to %q: %w", path, err)
+			}
+
+			// Wait for the sender to indicate the end of the volume.
+			_, err = io.Copy(io.Discard, fromPipe)
+			if err != nil {
+				return err
+			}
+
+			return to.Close()
+		}
+
 		d.Logger().Debug("Receiving block volume started", logger.Ctx{"volName": volName, "path": path})
 		defer d.Logger().Debug("Receiving block volume stopped", logger.Ctx{"volName": volName, "path": path})
 
@@ -501,8 +600,8 @@ func genericVFSGetVolumeDiskPath(vol Volume) (string, error) {
 }
 
 // genericVFSBackupVolume is a generic BackupVolume implementation for VFS-only drivers.
-func genericVFSBackupVolume(d Driver, vol VolumeCopy, tarWriter *instancewriter.InstanceTarWriter, snapshots []string, op *operations.Operation) error {
-	if len(snapshots) > 0 {
+func genericVFSBackupVolume(d Driver, vol VolumeCopy, tarWriter *instancewriter.InstanceTarWriter, snapshots []string, incrementalFrom string, op *operations.Operation) error {
+	if len(snapshots) > 0 || incrementalFrom != "" {
 		// Check requested snapshot match those in storage.
 		err := d.CheckVolumeSnapshots(vol.Volume, vol.Snapshots, op)
 		if err != nil {
@@ -510,9 +609,132 @@ func genericVFSBackupVolume(d Driver, vol VolumeCopy, tarWriter *instancewriter.
 		}
 	}
 
+	// Define a function that finds a snapshot volume by name.
+	findSnapshot := func(snapName string) (Volume, error) {
+		for _, snapshot := range vol.Snapshots {
+			_, snapshotName, _ := api.GetParentAndSnapshotName(snapshot.name)
+			if snapshotName == snapName {
+				return snapshot, nil
+			}
+		}
+
+		return Volume{}, fmt.Errorf("Snapshot %q missing in volume's list", snapName)
+	}
+
+	// Define a function that can copy a filesystem into the backup target location.
+	// If parentMountPath is set, only the files that changed since the parent are copied and the list of
+	// removed files is written alongside.
+	backupFilesystem := func(mountPath string, parentMountPath string, prefix string, exclude []string, ignoreGrowth bool) error {
+		err := filepath.Walk(mountPath, func(srcPath string, fi os.FileInfo, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					logger.Warnf("File vanished during export: %q, skipping", srcPath)
+					return nil
+				}
+
+				return fmt.Errorf("Error walking file during export: %q: %w", srcPath, err)
+			}
+
+			// Skip any exluded files.
+			if shared.StringHasPrefix(srcPath, exclude...) {
+				return nil
+			}
+
+			relPath := strings.TrimPrefix(srcPath, mountPath)
+
+			// Skip files that didn't change since the parent (the root directory is always included).
+			if parentMountPath != "" && srcPath != mountPath {
+				parentPath := filepath.Join(parentMountPath, relPath)
+				parentFi, err := os.Lstat(parentPath)
+				if err == nil && !deltaFileChanged(srcPath, fi, parentPath, parentFi) {
+					return nil
+				}
+			}
+
+			name := filepath.Join(prefix, relPath)
+
+			// Write the file to the tarball with ignoreGrowth enabled so that if the
+			// source file grows during copy we only copy up to the original size.
+			// This means that the file in the tarball may be inconsistent.
+			err = tarWriter.WriteFile(name, srcPath, fi, ignoreGrowth)
+			if err != nil {
+				return fmt.Errorf("Error adding %q as %q to tarball: %w", srcPath, name, err)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if parentMountPath == "" {
+			return nil
+		}
+
+		deleted, err := deltaDeletedPaths(mountPath, parentMountPath, exclude)
+		if err != nil {
+			return fmt.Errorf("Error listing files removed since parent snapshot: %w", err)
+		}
+
+		deletedList := deltaDeletedList(deleted)
+		fi := instancewriter.FileInfo{
+			FileName:    fmt.Sprintf("%s.%s", prefix, genericVolumeDeletedExtension),
+			FileSize:    int64(len(deletedList)),
+			FileMode:    0600,
+			FileModTime: time.Now(),
+		}
+
+		err = tarWriter.WriteFileFromReader(bytes.NewReader(deletedList), &fi)
+		if err != nil {
+			return fmt.Errorf("Error adding %q to tarball: %w", fi.FileName, err)
+		}
+
+		return nil
+	}
+
+	// Define a function that can copy the changes of a block volume since its parent into the backup target
+	// location.
+	backupBlockDelta := func(v Volume, blockPath string, blockDiskSize int64, parentVol Volume, parentBlockPath string, prefix string) error {
+		extents, err := blockVolumeDeltaExtents(d, v, blockPath, blockDiskSize, parentVol, parentBlockPath)
+		if err != nil {
+			return err
+		}
+
+		from, err := os.Open(blockPath)
+		if err != nil {
+			return fmt.Errorf("Error opening file for reading %q: %w", blockPath, err)
+		}
+
+		defer func() { _ = from.Close() }()
+
+		name := fmt.Sprintf("%s.%s", prefix, genericVolumeDeltaExtension)
+		fi := instancewriter.FileInfo{
+			FileName:    name,
+			FileSize:    blockDeltaSize(extents),
+			FileMode:    0600,
+			FileModTime: time.Now(),
+		}
+
+		d.Logger().Debug("Copying block volume changes", logger.Ctx{"sourcePath": blockPath, "parentPath": parentBlockPath, "file": name, "size": fi.FileSize})
+
+		pipeReader, pipeWriter := io.Pipe()
+		go func() {
+			_ = pipeWriter.CloseWithError(writeBlockDelta(pipeWriter, from, blockDiskSize, extents))
+		}()
+
+		err = tarWriter.WriteFileFromReader(pipeReader, &fi)
+		_ = pipeReader.Close()
+		if err != nil {
+			return fmt.Errorf("Error copying %q as %q to tarball: %w", blockPath, name, err)
+		}
+
+		return nil
+	}
+
 	// Define a function that can copy a volume into the backup target location.
-	backupVolume := func(v Volume, prefix string) error {
-		return v.MountTask(func(mountPath string, op *operations.Operation) error {
+	// If parentVol is set, only the changes since the parent are copied.
+	backupVolume := func(v Volume, parentVol *Volume, prefix string) error {
+		copyVolume := func(mountPath string, parentMountPath string) error {
 			// Reset hard link cache as we are copying a new volume (instance or snapshot).
 			tarWriter.ResetHardLinkMap()
 
@@ -522,41 +744,16 @@ func genericVFSBackupVolume(d Driver, vol VolumeCopy, tarWriter *instancewriter.
 					logMsg = "Copying custom filesystem volume"
 				}
 
-				d.Logger().Debug(logMsg, logger.Ctx{"sourcePath": mountPath, "prefix": prefix})
+				d.Logger().Debug(logMsg, logger.Ctx{"sourcePath": mountPath, "prefix": prefix, "parentPath": parentMountPath})
 
 				// Follow the target if mountPath is a symlink.
 				// Functions like filepath.Walk() won't list any directory content otherwise.
-				target, err := os.Readlink(mountPath)
-				if err == nil {
-					// Make sure the target is valid before return it.
-					_, err = os.Stat(target)
-					if err == nil {
-						mountPath = target
-					}
+				mountPath = resolveMountPath(mountPath)
+				if parentMountPath != "" {
+					parentMountPath = resolveMountPath(parentMountPath)
 				}
 
-				return filepath.Walk(mountPath, func(srcPath string, fi os.FileInfo, err error) error {
-					if err != nil {
-						if os.IsNotExist(err) {
-							logger.Warnf("File vanished during export: %q, skipping", srcPath)
-							return nil
-						}
-
-						return fmt.Errorf("Error walking file during export: %q: %w", srcPath, err)
-					}
-
-					name := filepath.Join(prefix, strings.TrimPrefix(srcPath, mountPath))
-
-					// Write the file to the tarball with ignoreGrowth enabled so that if the
-					// source file grows during copy we only copy up to the original size.
-					// This means that the file in the tarball may be inconsistent.
-					err = tarWriter.WriteFile(name, srcPath, fi, true)
-					if err != nil {
-						return fmt.Errorf("Error adding %q as %q to tarball: %w", srcPath, name, err)
-					}
-
-					return nil
-				})
+				return backupFilesystem(mountPath, parentMountPath, prefix, nil, true)
 			}
 
 			blockPath, err := d.GetVolumeDiskPath(v)
@@ -582,33 +779,32 @@ func genericVFSBackupVolume(d Driver, vol VolumeCopy, tarWriter *instancewriter.
 				exclude = append(exclude, blockPath)
 			}
 
-			if v.IsVMBlock() {
-				logMsg := "Copying virtual machine config volume"
-
-				d.Logger().Debug(logMsg, logger.Ctx{"sourcePath": mountPath, "prefix": prefix})
-				err = filepath.Walk(mountPath, func(srcPath string, fi os.FileInfo, err error) error {
-					if err != nil {
-						return err
-					}
+			var parentBlockPath string
+			if parentVol != nil {
+				parentBlockPath, err = d.GetVolumeDiskPath(*parentVol)
+				if err != nil {
+					return fmt.Errorf("Error getting parent block volume disk path: %w", err)
+				}
 
-					// Skip any exluded files.
-					if shared.StringHasPrefix(srcPath, exclude...) {
-						return nil
-					}
+				if !shared.IsBlockdevPath(parentBlockPath) {
+					exclude = append(exclude, parentBlockPath)
+				}
+			}
 
-					name := filepath.Join(prefix, strings.TrimPrefix(srcPath, mountPath))
-					err = tarWriter.WriteFile(name, srcPath, fi, false)
-					if err != nil {
-						return fmt.Errorf("Error adding %q as %q to tarball: %w", srcPath, name, err)
-					}
+			if v.IsVMBlock() {
+				logMsg := "Copying virtual machine config volume"
 
-					return nil
-				})
+				d.Logger().Debug(logMsg, logger.Ctx{"sourcePath": mountPath, "prefix": prefix, "parentPath": parentMountPath})
+				err = backupFilesystem(mountPath, parentMountPath, prefix, exclude, false)
 				if err != nil {
 					return err
 				}
 			}
 
+			if parentVol != nil {
+				return backupBlockDelta(v, blockPath, blockDiskSize, *parentVol, parentBlockPath, prefix)
+			}
+
 			name := fmt.Sprintf("%s.%s", prefix, genericVolumeBlockExtension)
 
 			logMsg := "Copying virtual machine block volume"
@@ -642,9 +838,32 @@ func genericVFSBackupVolume(d Driver, vol VolumeCopy, tarWriter *instancewriter.
 			}
 
 			return nil
+		}
+
+		return v.MountTask(func(mountPath string, op *operations.Operation) error {
+			if parentVol == nil {
+				return copyVolume(mountPath, "")
+			}
+
+			// Mount the parent volume alongside to compare against it.
+			return parentVol.MountTask(func(parentMountPath string, op *operations.Operation) error {
+				return copyVolume(mountPath, parentMountPath)
+			}, op)
 		}, op)
 	}
 
+	// For incremental backups, each volume is copied as the changes since the one before it, starting with
+	// the snapshot the backup is incremental from.
+	var parentVol *Volume
+	if incrementalFrom != "" {
+		baseSnapVol, err := findSnapshot(incrementalFrom)
+		if err != nil {
+			return err
+		}
+
+		parentVol = &baseSnapVol
+	}
+
 	// Handle snapshots.
 	if len(snapshots) > 0 {
 		snapshotsPrefix := "backup/snapshots"
@@ -655,26 +874,20 @@ func genericVFSBackupVolume(d Driver, vol VolumeCopy, tarWriter *instancewriter.
 		}
 
 		for _, snapName := range snapshots {
-			found := false
-			var snapVol Volume
-			for _, snapshot := range vol.Snapshots {
-				_, snapshotName, _ := api.GetParentAndSnapshotName(snapshot.name)
-				if snapshotName == snapName {
-					snapVol = snapshot
-					found = true
-					break
-				}
-			}
-
-			if !found {
-				return fmt.Errorf("Snapshot %q missing in volume's list", snapName)
+			snapVol, err := findSnapshot(snapName)
+			if err != nil {
+				return err
 			}
 
 			prefix := filepath.Join(snapshotsPrefix, snapName)
-			err := backupVolume(snapVol, prefix)
+			err = backupVolume(snapVol, parentVol, prefix)
 			if err != nil {
 				return err
 			}
+
+			if parentVol != nil {
+				parentVol = &snapVol
+			}
 		}
 	}
 
@@ -686,7 +899,7 @@ func genericVFSBackupVolume(d Driver, vol VolumeCopy, tarWriter *instancewriter.
 		prefix = "backup/volume"
 	}
 
-	err := backupVolume(vol.Volume, prefix)
+	err := backupVolume(vol.Volume, parentVol, prefix)
 	if err != nil {
 		return err
 	}
@@ -694,13 +907,55 @@ func genericVFSBackupVolume(d Driver, vol VolumeCopy, tarWriter *instancewriter.
 	return nil
 }
 
+// resolveMountPath returns the target of mountPath if it is a valid symlink, or mountPath otherwise.
+func resolveMountPath(mountPath string) string {
+	target, err := os.Readlink(mountPath)
+	if err == nil {
+		// Make sure the target is valid before return it.
+		_, err = os.Stat(target)
+		if err == nil {
+			return target
+		}
+	}
+
+	return mountPath
+}
+
 // genericVFSBackupUnpack unpacks a non-optimized backup tarball through a storage driver.
 // Returns a post hook function that should be called once the database entries for the restored backup have been
 // created and a revert function that can be used to undo the actions this function performs should something
 // subsequently fail. For VolumeTypeCustom volumes, a nil post hook is returned as it is expected that the DB
 // record be created before the volume is unpacked due to differences in the archive format that allows this.
-func genericVFSBackupUnpack(d Driver, sysOS *sys.OS, vol VolumeCopy, snapshots []string, srcData io.ReadSeeker, op *operations.Operation) (VolumePostHook, revert.Hook, error) {
+func genericVFSBackupUnpack(d Driver, sysOS *sys.OS, vol VolumeCopy, snapshots []string, incrementalFrom string, srcData io.ReadSeeker, op *operations.Operation) (VolumePostHook, revert.Hook, error) {
+	// Define function to run a task against a file from a backup tarball file.
+	readBackupFile := func(r io.ReadSeeker, unpacker []string, mountPath string, fileName string, task func(tr io.Reader) error) error {
+		tr, cancelFunc, err := archive.CompressedTarReader(context.Background(), r, unpacker, sysOS, mountPath)
+		if err != nil {
+			return err
+		}
+
+		defer cancelFunc()
+
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break // End of archive.
+			}
+
+			if err != nil {
+				return err
+			}
+
+			if hdr.Name == fileName {
+				return task(tr)
+			}
+		}
+
+		return fmt.Errorf("Could not find %q", fileName)
+	}
+
 	// Define function to unpack a volume from a backup tarball file.
+	// For incremental backups the changes are applied on top of the current content of the volume.
 	unpackVolume := func(r io.ReadSeeker, tarArgs []string, unpacker []string, srcPrefix string, mountPath string) error {
 		volTypeName := "container"
 		if vol.IsVMBlock() {
@@ -709,10 +964,21 @@ func genericVFSBackupUnpack(d Driver, sysOS *sys.OS, vol VolumeCopy, snapshots [
 			volTypeName = "custom"
 		}
 
-		// Clear the volume ready for unpack.
-		err := wipeDirectory(mountPath)
-		if err != nil {
-			return fmt.Errorf("Error clearing volume before unpack: %w", err)
+		if incrementalFrom == "" {
+			// Clear the volume ready for unpack.
+			err := wipeDirectory(mountPath)
+			if err != nil {
+				return fmt.Errorf("Error clearing volume before unpack: %w", err)
+			}
+		} else if !vol.IsCustomBlock() {
+			// Remove the files that were deleted since the parent snapshot.
+			deletedFile := fmt.Sprintf("%s.%s", srcPrefix, genericVolumeDeletedExtension)
+			err := readBackupFile(r, unpacker, mountPath, deletedFile, func(tr io.Reader) error {
+				return removeDeltaPaths(mountPath, tr)
+			})
+			if err != nil {
+				return fmt.Errorf("Error removing deleted files before unpack: %w", err)
+			}
 		}
 
 		// Unpack the filesystem parts of the volume (for containers and custom filesystem volumes that is
@@ -777,6 +1043,40 @@ func genericVFSBackupUnpack(d Driver, sysOS *sys.OS, vol VolumeCopy, snapshots [
 				return err
 			}
 
+			if incrementalFrom != "" {
+				deltaFile := fmt.Sprintf("%s.%s", srcPrefix, genericVolumeDeltaExtension)
+
+				return readBackupFile(r, unpacker, mountPath, deltaFile, func(tr io.Reader) error {
+					to, err := os.OpenFile(targetPath, os.O_WRONLY, 0)
+					if err != nil {
+						return fmt.Errorf("Error opening file for writing %q: %w", targetPath, err)
+					}
+
+					defer func() { _ = to.Close() }()
+
+					d.Logger().Debug("Applying block volume changes", logger.Ctx{"source": deltaFile, "target": targetPath})
+					err = applyBlockDelta(tr, to, func(size int64) error {
+						curSize, err := block.DiskSizeBytes(targetPath)
+						if err != nil {
+							return err
+						}
+
+						if curSize == size {
+							return nil
+						}
+
+						// Allow potentially destructive resize of volume as the delta contains
+						// the full content of the volume beyond the size of the parent.
+						return d.SetVolumeQuota(vol.Volume, fmt.Sprintf("%d", size), true, op)
+					})
+					if err != nil {
+						return err
+					}
+
+					return to.Close()
+				})
+			}
+
 			srcFile := fmt.Sprintf("%s.%s", srcPrefix, genericVolumeBlockExtension)
 
 			tr, cancelFunc, err := archive.CompressedTarReader(context.Background(), r, unpacker, sysOS, mountPath)
@@ -795,7 +1095,7 @@ func genericVFSBackupUnpack(d Driver, sysOS *sys.OS, vol VolumeCopy, snapshots [
 					return fmt.Errorf("Error opening file for writing %q: %w", targetPath, err)
 				}
 
-				defer to.Close()
+				defer func() { _ = to.Close() }()
 
 				// Restore original size of volume from raw block backup file size.
 				d.Logger().Debug("Setting volume size from source", logger.Ctx{"source": srcFile, "target": targetPath, "size": size})
@@ -863,17 +1163,46 @@ func genericVFSBackupUnpack(d Driver, sysOS *sys.OS, vol VolumeCopy, snapshots [
 		return nil, nil, err
 	}
 
-	if volExists {
-		return nil, nil, fmt.Errorf("Cannot restore volume, already exists on target")
-	}
+	if incrementalFrom != "" {
+		if !volExists {
+			return nil, nil, fmt.Errorf("Cannot apply incremental backup, volume doesn't exist on target")
+		}
 
-	// Create new empty volume.
-	err = d.CreateVolume(vol.Volume, nil, nil)
-	if err != nil {
-		return nil, nil, err
-	}
+		var baseSnapVol Volume
+		found := false
+		for _, snapshot := range vol.Snapshots {
+			_, snapshotName, _ := api.GetParentAndSnapshotName(snapshot.name)
+			if snapshotName == incrementalFrom {
+				baseSnapVol = snapshot
+				found = true
+				break
+			}
+		}
 
-	revert.Add(func() { _ = d.DeleteVolume(vol.Volume, op) })
+		if !found {
+			return nil, nil, fmt.Errorf("Snapshot %q missing in volume's list", incrementalFrom)
+		}
+
+		// Reset the volume to the snapshot the backup is incremental from so the changes apply cleanly.
+		err = d.RestoreVolume(vol.Volume, baseSnapVol, op)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		revert.Add(func() { _ = d.RestoreVolume(vol.Volume, baseSnapVol, op) })
+	} else {
+		if volExists {
+			return nil, nil, fmt.Errorf("Cannot restore volume, already exists on target")
+		}
+
+		// Create new empty volume.
+		err = d.CreateVolume(vol.Volume, nil, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		revert.Add(func() { _ = d.DeleteVolume(vol.Volume, op) })
+	}
 
 	if len(snapshots) > 0 {
 		// Create new snapshots directory.
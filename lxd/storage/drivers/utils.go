@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/sys/unix"
@@ -31,6 +32,17 @@ const MinBlockBoundary = 8192
 // blockBackedAllowedFilesystems allowed filesystems for block volumes.
 var blockBackedAllowedFilesystems = []string{"btrfs", "ext4", "xfs"}
 
+// directoryUsageCacheTTL is how long the result of a directory usage walk is reused.
+const directoryUsageCacheTTL = time.Minute
+
+type directoryUsageCacheEntry struct {
+	usage   int64
+	expires time.Time
+}
+
+var directoryUsageCache = map[string]directoryUsageCacheEntry{}
+var directoryUsageCacheMu sync.Mutex
+
 // wipeDirectory empties the contents of a directory, but leaves it in place.
 func wipeDirectory(path string) error {
 	// List all entries.
@@ -55,6 +67,89 @@ func wipeDirectory(path string) error {
 	return nil
 }
 
+// directoryUsage returns the disk space used by the files under path, in the same way as "du -sx" does.
+// Files with multiple hard links are only counted once and other filesystems mounted under path are skipped.
+// As walking a large directory tree is expensive the result is cached for a short time.
+func directoryUsage(path string) (int64, error) {
+	directoryUsageCacheMu.Lock()
+	entry, ok := directoryUsageCache[path]
+	directoryUsageCacheMu.Unlock()
+
+	if ok && time.Now().Before(entry.expires) {
+		return entry.usage, nil
+	}
+
+	var rootStat unix.Stat_t
+	err := unix.Lstat(path, &rootStat)
+	if err != nil {
+		return -1, err
+	}
+
+	var usage int64
+	seenInodes := map[uint64]struct{}{}
+
+	err = filepath.WalkDir(path, func(entryPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Ignore files removed during the walk.
+			if os.IsNotExist(err) {
+				return nil
+			}
+
+			return err
+		}
+
+		var stat unix.Stat_t
+		err = unix.Lstat(entryPath, &stat)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+
+			return err
+		}
+
+		// Don't cross into other filesystems.
+		if stat.Dev != rootStat.Dev {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !d.IsDir() && stat.Nlink > 1 {
+			_, seen := seenInodes[stat.Ino]
+			if seen {
+				return nil
+			}
+
+			seenInodes[stat.Ino] = struct{}{}
+		}
+
+		// Blocks are always reported in 512 byte units.
+		usage += stat.Blocks * 512
+
+		return nil
+	})
+	if err != nil {
+		return -1, fmt.Errorf("Failed getting disk usage of %q: %w", path, err)
+	}
+
+	now := time.Now()
+
+	directoryUsageCacheMu.Lock()
+	for cachedPath, cachedEntry := range directoryUsageCache {
+		if now.After(cachedEntry.expires) {
+			delete(directoryUsageCache, cachedPath)
+		}
+	}
+
+	directoryUsageCache[path] = directoryUsageCacheEntry{usage: usage, expires: now.Add(directoryUsageCacheTTL)}
+	directoryUsageCacheMu.Unlock()
+
+	return usage, nil
+}
+
 // forceRemoveAll wipes a path including any immutable/non-append files.
 func forceRemoveAll(path string) error {
 	err := os.RemoveAll(path)
@@ -262,6 +357,47 @@ func fsProbe(path string) (string, error) {
 	return strings.TrimSpace(val), nil
 }
 
+// fsCheck runs a read-only check of the filesystem of the given type on the given block path.
+// It returns the output of the check if it found problems with the filesystem.
+func fsCheck(path string, fsType string) (string, error) {
+	var cmd []string
+	var problemExitCode int
+
+	switch fsType {
+	case "ext4":
+		cmd = []string{"e2fsck", "-f", "-n", path}
+		problemExitCode = 4
+	case "xfs":
+		cmd = []string{"xfs_repair", "-n", path}
+		problemExitCode = 1
+	case "btrfs":
+		cmd = []string{"btrfs", "check", "--readonly", path}
+		problemExitCode = 1
+	default:
+		return "", fmt.Errorf("Checking %q filesystems isn't supported: %w", fsType, ErrNotSupported)
+	}
+
+	_, err := shared.RunCommand(cmd[0], cmd[1:]...)
+	if err != nil {
+		runErr, ok := err.(shared.RunError)
+		if ok {
+			exitError, ok := runErr.Unwrap().(*exec.ExitError)
+			if ok && exitError.ExitCode() == problemExitCode {
+				output := strings.TrimSpace(runErr.StdErr().String())
+				if output == "" {
+					output = strings.TrimSpace(runErr.StdOut().String())
+				}
+
+				return fmt.Sprintf("Filesystem check found problems: %s", output), nil
+			}
+		}
+
+		return "", err
+	}
+
+	return "", nil
+}
+
 // GetPoolMountPath returns the mountpoint of the given pool.
 // {LXD_DIR}/storage-pools/<pool>.
 func GetPoolMountPath(poolName string) string {
@@ -0,0 +1,437 @@
+package drivers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/canonical/lxd/lxd/storage/block"
+	"github.com/canonical/lxd/shared"
+)
+
+// blockDeltaHeader is the header of the block volume delta files used in incremental backups.
+// The format is the same as the one used by "rbd export-diff" so that the records can be produced directly
+// from the changed extents reported by Ceph.
+const blockDeltaHeader = "rbd diff v1\n"
+
+// blockDeltaChunkSize is the granularity at which block volumes are compared when generating a delta.
+const blockDeltaChunkSize = 64 * 1024
+
+// Block volume delta record types.
+const (
+	blockDeltaRecordSize  = 's'
+	blockDeltaRecordWrite = 'w'
+	blockDeltaRecordZero  = 'z'
+	blockDeltaRecordEnd   = 'e'
+)
+
+// genericVolumeDeletedExtension is the extension of the file listing the paths removed since the parent
+// snapshot in an incremental backup.
+const genericVolumeDeletedExtension = "deleted"
+
+// genericVolumeDeltaExtension is the extension of the file containing the block volume delta in an
+// incremental backup.
+const genericVolumeDeltaExtension = "delta"
+
+// blockDeltaExtent is a range of a block volume that changed since the parent snapshot.
+// Extents with zero set only contain zeroes and have no data in the delta.
+type blockDeltaExtent struct {
+	offset int64
+	length int64
+	zero   bool
+}
+
+// blockVolumeDiffer is implemented by drivers that can report the changed extents of a block volume
+// without having to read and compare both volumes.
+type blockVolumeDiffer interface {
+	blockVolumeDiff(vol Volume, parentVol Volume) ([]blockDeltaExtent, error)
+}
+
+// deltaFileChanged returns whether the file at path differs from the one at refPath.
+// Like rsync, only the file type, mode, ownership, size and modification time are compared and not the content.
+func deltaFileChanged(path string, fi os.FileInfo, refPath string, refFi os.FileInfo) bool {
+	if fi.Mode() != refFi.Mode() {
+		return true
+	}
+
+	stat, ok := fi.Sys().(*syscall.Stat_t)
+	refStat, refOk := refFi.Sys().(*syscall.Stat_t)
+	if !ok || !refOk {
+		return true
+	}
+
+	if stat.Uid != refStat.Uid || stat.Gid != refStat.Gid {
+		return true
+	}
+
+	switch {
+	case fi.Mode().IsRegular():
+		if fi.Size() != refFi.Size() {
+			return true
+		}
+
+	case fi.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(path)
+		if err != nil {
+			return true
+		}
+
+		refTarget, err := os.Readlink(refPath)
+		if err != nil || target != refTarget {
+			return true
+		}
+
+	case fi.Mode()&(os.ModeDevice|os.ModeCharDevice) != 0:
+		if stat.Rdev != refStat.Rdev {
+			return true
+		}
+	}
+
+	return !fi.ModTime().Equal(refFi.ModTime())
+}
+
+// deltaDeletedPaths returns the paths (relative to refPath) that exist in refPath but not in path, or whose
+// file type changed. Children of returned directories are not listed. Paths matching exclude are skipped.
+func deltaDeletedPaths(path string, refPath string, exclude []string) ([]string, error) {
+	var deleted []string
+
+	err := filepath.Walk(refPath, func(srcPath string, refFi os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+
+			return err
+		}
+
+		if srcPath == refPath || shared.StringHasPrefix(srcPath, exclude...) {
+			return nil
+		}
+
+		relPath := strings.TrimPrefix(srcPath, refPath+"/")
+
+		fi, err := os.Lstat(filepath.Join(path, relPath))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		if err == nil && fi.Mode().Type() == refFi.Mode().Type() {
+			return nil
+		}
+
+		deleted = append(deleted, relPath)
+
+		if refFi.IsDir() {
+			return filepath.SkipDir
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deleted, nil
+}
+
+// removeDeltaPaths removes the NUL separated relative paths read from r inside rootPath.
+// Paths that would escape rootPath, either directly or by traversing a symlink, are rejected.
+func removeDeltaPaths(rootPath string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	for _, relPath := range strings.Split(string(data), "\x00") {
+		if relPath == "" {
+			continue
+		}
+
+		cleanPath := filepath.Clean(relPath)
+		if filepath.IsAbs(cleanPath) || cleanPath == "." || cleanPath == ".." || strings.HasPrefix(cleanPath, "../") {
+			return fmt.Errorf("Invalid deleted path %q", relPath)
+		}
+
+		// Check that none of the parent directories are symlinks.
+		parts := strings.Split(cleanPath, "/")
+		parentPath := rootPath
+		for _, part := range parts[:len(parts)-1] {
+			parentPath = filepath.Join(parentPath, part)
+
+			fi, err := os.Lstat(parentPath)
+			if err != nil {
+				if os.IsNotExist(err) {
+					break
+				}
+
+				return err
+			}
+
+			if !fi.IsDir() {
+				return fmt.Errorf("Invalid deleted path %q: %q is not a directory", relPath, parentPath)
+			}
+		}
+
+		err = os.RemoveAll(filepath.Join(rootPath, cleanPath))
+		if err != nil {
+			return fmt.Errorf("Failed removing %q: %w", relPath, err)
+		}
+	}
+
+	return nil
+}
+
+// blockDeltaExtents compares the block volume src of size srcSize with its parent of size parentSize and
+// returns the changed extents. Adjacent extents of the same kind are merged.
+func blockDeltaExtents(src io.ReaderAt, srcSize int64, parent io.ReaderAt, parentSize int64) ([]blockDeltaExtent, error) {
+	var extents []blockDeltaExtent
+
+	buf := make([]byte, blockDeltaChunkSize)
+	parentBuf := make([]byte, blockDeltaChunkSize)
+
+	for offset := int64(0); offset < srcSize; offset += blockDeltaChunkSize {
+		length := min(int64(blockDeltaChunkSize), srcSize-offset)
+
+		_, err := src.ReadAt(buf[:length], offset)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+
+		if offset+length <= parentSize {
+			_, err = parent.ReadAt(parentBuf[:length], offset)
+			if err != nil && !errors.Is(err, io.EOF) {
+				return nil, err
+			}
+
+			if bytes.Equal(buf[:length], parentBuf[:length]) {
+				continue
+			}
+		}
+
+		zero := isZeroBlock(buf[:length])
+
+		last := len(extents) - 1
+		if last >= 0 && extents[last].zero == zero && extents[last].offset+extents[last].length == offset {
+			extents[last].length += length
+		} else {
+			extents = append(extents, blockDeltaExtent{offset: offset, length: length, zero: zero})
+		}
+	}
+
+	return extents, nil
+}
+
+// blockVolumeDeltaExtents returns the extents of the block volume vol of size volSize at volPath that changed
+// since parentVol at parentPath. The extents are reported by the driver if it supports it, and are found by
+// comparing both volumes otherwise.
+func blockVolumeDeltaExtents(d Driver, vol Volume, volPath string, volSize int64, parentVol Volume, parentPath string) ([]blockDeltaExtent, error) {
+	differ, ok := d.(blockVolumeDiffer)
+	if ok {
+		extents, err := differ.blockVolumeDiff(vol, parentVol)
+		if err == nil {
+			return extents, nil
+		}
+
+		if !errors.Is(err, ErrNotSupported) {
+			return nil, fmt.Errorf("Error getting changed extents of %q: %w", volPath, err)
+		}
+	}
+
+	from, err := os.Open(volPath)
+	if err != nil {
+		return nil, fmt.Errorf("Error opening file for reading %q: %w", volPath, err)
+	}
+
+	defer func() { _ = from.Close() }()
+
+	parent, err := os.Open(parentPath)
+	if err != nil {
+		return nil, fmt.Errorf("Error opening file for reading %q: %w", parentPath, err)
+	}
+
+	defer func() { _ = parent.Close() }()
+
+	parentSize, err := block.DiskSizeBytes(parentPath)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting block device size %q: %w", parentPath, err)
+	}
+
+	extents, err := blockDeltaExtents(from, volSize, parent, parentSize)
+	if err != nil {
+		return nil, fmt.Errorf("Error comparing %q with %q: %w", volPath, parentPath, err)
+	}
+
+	return extents, nil
+}
+
+// isZeroBlock returns whether buf only contains zeroes.
+func isZeroBlock(buf []byte) bool {
+	for _, b := range buf {
+		if b != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// blockDeltaSize returns the size of the delta that writeBlockDelta generates for the given extents.
+func blockDeltaSize(extents []blockDeltaExtent) int64 {
+	// Header, size record and end record.
+	size := int64(len(blockDeltaHeader)) + 9 + 1
+
+	for _, extent := range extents {
+		size += 17
+		if !extent.zero {
+			size += extent.length
+		}
+	}
+
+	return size
+}
+
+// writeBlockDelta writes the delta for the given extents of src, a block volume of the given size, to w.
+func writeBlockDelta(w io.Writer, src io.ReaderAt, size int64, extents []blockDeltaExtent) error {
+	_, err := io.WriteString(w, blockDeltaHeader)
+	if err != nil {
+		return err
+	}
+
+	record := make([]byte, 17)
+
+	record[0] = blockDeltaRecordSize
+	binary.LittleEndian.PutUint64(record[1:9], uint64(size))
+	_, err = w.Write(record[:9])
+	if err != nil {
+		return err
+	}
+
+	for _, extent := range extents {
+		record[0] = blockDeltaRecordWrite
+		if extent.zero {
+			record[0] = blockDeltaRecordZero
+		}
+
+		binary.LittleEndian.PutUint64(record[1:9], uint64(extent.offset))
+		binary.LittleEndian.PutUint64(record[9:17], uint64(extent.length))
+		_, err = w.Write(record)
+		if err != nil {
+			return err
+		}
+
+		if extent.zero {
+			continue
+		}
+
+		n, err := io.Copy(w, io.NewSectionReader(src, extent.offset, extent.length))
+		if err != nil {
+			return err
+		}
+
+		if n != extent.length {
+			return fmt.Errorf("Short read at offset %d: expected %d bytes, got %d", extent.offset, extent.length, n)
+		}
+	}
+
+	_, err = w.Write([]byte{blockDeltaRecordEnd})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// applyBlockDelta applies the delta read from r to target.
+// The resize function is called with the size recorded in the delta before any data is written.
+func applyBlockDelta(r io.Reader, target io.WriterAt, resize func(size int64) error) error {
+	header := make([]byte, len(blockDeltaHeader))
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		return fmt.Errorf("Failed reading delta header: %w", err)
+	}
+
+	if string(header) != blockDeltaHeader {
+		return fmt.Errorf("Invalid delta header")
+	}
+
+	record := make([]byte, 17)
+	zeroes := make([]byte, blockDeltaChunkSize)
+
+	for {
+		_, err = io.ReadFull(r, record[:1])
+		if err != nil {
+			return fmt.Errorf("Failed reading delta record: %w", err)
+		}
+
+		switch record[0] {
+		case blockDeltaRecordEnd:
+			return nil
+
+		case blockDeltaRecordSize:
+			_, err = io.ReadFull(r, record[1:9])
+			if err != nil {
+				return fmt.Errorf("Failed reading delta size record: %w", err)
+			}
+
+			err = resize(int64(binary.LittleEndian.Uint64(record[1:9])))
+			if err != nil {
+				return err
+			}
+
+		case blockDeltaRecordWrite, blockDeltaRecordZero:
+			_, err = io.ReadFull(r, record[1:17])
+			if err != nil {
+				return fmt.Errorf("Failed reading delta extent record: %w", err)
+			}
+
+			offset := int64(binary.LittleEndian.Uint64(record[1:9]))
+			length := int64(binary.LittleEndian.Uint64(record[9:17]))
+			if offset < 0 || length < 0 {
+				return fmt.Errorf("Invalid delta extent at offset %d", offset)
+			}
+
+			w := io.NewOffsetWriter(target, offset)
+
+			if record[0] == blockDeltaRecordWrite {
+				_, err = io.CopyN(w, r, length)
+				if err != nil {
+					return fmt.Errorf("Failed writing delta extent at offset %d: %w", offset, err)
+				}
+
+				continue
+			}
+
+			for length > 0 {
+				n := min(length, int64(len(zeroes)))
+
+				_, err = w.Write(zeroes[:n])
+				if err != nil {
+					return fmt.Errorf("Failed zeroing delta extent at offset %d: %w", offset, err)
+				}
+
+				length -= n
+			}
+
+		default:
+			return fmt.Errorf("Unknown delta record type %q", record[0])
+		}
+	}
+}
+
+// deltaDeletedList returns the NUL separated list of paths used in the deleted file of incremental backups.
+func deltaDeletedList(paths []string) []byte {
+	var buf bytes.Buffer
+
+	for _, path := range paths {
+		buf.WriteString(path)
+		buf.WriteByte(0)
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,173 @@
+package drivers
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test deltaFileChanged.
+func TestDeltaFileChanged(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "file")
+	refPath := filepath.Join(dir, "ref")
+
+	mtime := time.Unix(1700000000, 0)
+	for _, p := range []string{path, refPath} {
+		require.NoError(t, os.WriteFile(p, []byte("data"), 0644))
+		require.NoError(t, os.Chtimes(p, mtime, mtime))
+	}
+
+	changed := func() bool {
+		fi, err := os.Lstat(path)
+		require.NoError(t, err)
+
+		refFi, err := os.Lstat(refPath)
+		require.NoError(t, err)
+
+		return deltaFileChanged(path, fi, refPath, refFi)
+	}
+
+	assert.False(t, changed())
+
+	// Test same size and modification time but different content isn't detected, like rsync.
+	require.NoError(t, os.WriteFile(path, []byte("DATA"), 0644))
+	require.NoError(t, os.Chtimes(path, mtime, mtime))
+	assert.False(t, changed())
+
+	// Test modification time change.
+	require.NoError(t, os.Chtimes(path, mtime, mtime.Add(time.Second)))
+	assert.True(t, changed())
+
+	// Test size change.
+	require.NoError(t, os.WriteFile(path, []byte("more data"), 0644))
+	require.NoError(t, os.Chtimes(path, mtime, mtime))
+	assert.True(t, changed())
+
+	// Test mode change.
+	require.NoError(t, os.WriteFile(path, []byte("data"), 0644))
+	require.NoError(t, os.Chtimes(path, mtime, mtime))
+	require.NoError(t, os.Chmod(path, 0600))
+	assert.True(t, changed())
+
+	// Test symlink target change.
+	require.NoError(t, os.Remove(path))
+	require.NoError(t, os.Remove(refPath))
+	require.NoError(t, os.Symlink("target1", path))
+	require.NoError(t, os.Symlink("target2", refPath))
+	assert.True(t, changed())
+}
+
+// Test deltaDeletedPaths and removeDeltaPaths.
+func TestDeltaDeletedPaths(t *testing.T) {
+	refPath := t.TempDir()
+	path := t.TempDir()
+
+	for _, dir := range []string{refPath, path} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "keep"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "keep", "file"), nil, 0644))
+	}
+
+	// Removed in path.
+	require.NoError(t, os.MkdirAll(filepath.Join(refPath, "removed", "sub"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(refPath, "removed", "sub", "file"), nil, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(refPath, "keep", "removed"), nil, 0644))
+
+	// File type changed in path.
+	require.NoError(t, os.WriteFile(filepath.Join(refPath, "typechange"), nil, 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(path, "typechange"), 0755))
+
+	// Excluded.
+	require.NoError(t, os.WriteFile(filepath.Join(refPath, "excluded"), nil, 0644))
+
+	deleted, err := deltaDeletedPaths(path, refPath, []string{filepath.Join(refPath, "excluded")})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"keep/removed", "removed", "typechange"}, deleted)
+
+	// Apply the list to a copy of the reference.
+	require.NoError(t, removeDeltaPaths(refPath, bytes.NewReader(deltaDeletedList(deleted))))
+
+	for _, p := range deleted {
+		assert.NoFileExists(t, filepath.Join(refPath, p))
+		assert.NoDirExists(t, filepath.Join(refPath, p))
+	}
+
+	assert.FileExists(t, filepath.Join(refPath, "keep", "file"))
+	assert.FileExists(t, filepath.Join(refPath, "excluded"))
+
+	// Test paths escaping the root are rejected.
+	for _, p := range []string{"../outside", "/etc/passwd", ".."} {
+		assert.Error(t, removeDeltaPaths(refPath, bytes.NewReader(deltaDeletedList([]string{p}))))
+	}
+
+	// Test paths traversing a symlink are rejected.
+	require.NoError(t, os.Symlink(path, filepath.Join(refPath, "link")))
+	assert.Error(t, removeDeltaPaths(refPath, bytes.NewReader(deltaDeletedList([]string{"link/keep"}))))
+	assert.DirExists(t, filepath.Join(path, "keep"))
+}
+
+// memBlock is an in-memory block device used to test block deltas.
+type memBlock struct {
+	data []byte
+}
+
+func (m *memBlock) ReadAt(p []byte, off int64) (int, error) {
+	return bytes.NewReader(m.data).ReadAt(p, off)
+}
+
+func (m *memBlock) WriteAt(p []byte, off int64) (int, error) {
+	if int(off)+len(p) > len(m.data) {
+		m.data = append(m.data, make([]byte, int(off)+len(p)-len(m.data))...)
+	}
+
+	return copy(m.data[off:], p), nil
+}
+
+// Test blockDeltaExtents, writeBlockDelta and applyBlockDelta.
+func TestBlockDelta(t *testing.T) {
+	parent := make([]byte, 8*blockDeltaChunkSize)
+	for i := range parent {
+		parent[i] = byte(i % 251)
+	}
+
+	src := append([]byte{}, parent...)
+
+	// Change two adjacent chunks, zero another one and grow the volume.
+	copy(src[blockDeltaChunkSize:], bytes.Repeat([]byte{0xff}, 2*blockDeltaChunkSize))
+	copy(src[5*blockDeltaChunkSize:], make([]byte, blockDeltaChunkSize))
+	src = append(src, bytes.Repeat([]byte{0x01}, blockDeltaChunkSize/2)...)
+
+	srcSize := int64(len(src))
+
+	extents, err := blockDeltaExtents(bytes.NewReader(src), srcSize, bytes.NewReader(parent), int64(len(parent)))
+	require.NoError(t, err)
+	assert.Equal(t, []blockDeltaExtent{
+		{offset: blockDeltaChunkSize, length: 2 * blockDeltaChunkSize},
+		{offset: 5 * blockDeltaChunkSize, length: blockDeltaChunkSize, zero: true},
+		{offset: 8 * blockDeltaChunkSize, length: blockDeltaChunkSize / 2},
+	}, extents)
+
+	var delta bytes.Buffer
+	require.NoError(t, writeBlockDelta(&delta, bytes.NewReader(src), srcSize, extents))
+	assert.Equal(t, blockDeltaSize(extents), int64(delta.Len()))
+
+	target := &memBlock{data: append([]byte{}, parent...)}
+	var resized int64
+	err = applyBlockDelta(&delta, target, func(size int64) error {
+		resized = size
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, srcSize, resized)
+	assert.Equal(t, src, target.data)
+
+	// Test invalid deltas.
+	assert.Error(t, applyBlockDelta(bytes.NewReader([]byte("invalid")), target, func(int64) error { return nil }))
+	assert.Error(t, applyBlockDelta(bytes.NewReader([]byte(blockDeltaHeader+"x")), target, func(int64) error { return nil }))
+}
@@ -0,0 +1,371 @@
+package drivers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/validate"
+)
+
+// luksHeaderSize is the space reserved for the LUKS2 header at the start of encrypted volumes.
+// The underlying block device of an encrypted volume is grown by this amount so that the usable size of the
+// volume matches its configured size.
+const luksHeaderSize = 16 * 1024 * 1024
+
+// luksPoolKeyLabel is the LUKS label used for volumes that are unlocked using the pool key.
+const luksPoolKeyLabel = "lxd"
+
+// luksProjectKeyLabelPrefix is the LUKS label prefix used for volumes that are unlocked using a project key.
+// The project name follows the prefix.
+const luksProjectKeyLabelPrefix = "lxd-project:"
+
+// luksPoolRules returns the pool config rules related to volume encryption.
+func luksPoolRules() map[string]func(value string) error {
+	return map[string]func(value string) error{
+		// lxdmeta:generate(entities=storage-ceph,storage-lvm,storage-zfs; group=pool-conf; key=encryption.project_keys)
+		// When enabled, new encrypted volumes of instances and custom volumes are unlocked using a key that
+		// is derived from the pool key and the name of the project the volume belongs to, rather than with
+		// the pool key itself.
+		// Existing volumes are switched over to the key of their project the next time they are opened.
+		// ---
+		//  type: bool
+		//  defaultdesc: `false`
+		//  shortdesc: Whether to use a separate encryption key for each project
+		"encryption.project_keys": validate.Optional(validate.IsBool),
+		"volatile.encryption.key": validate.Optional(func(value string) error {
+			key, err := hex.DecodeString(value)
+			if err != nil || len(key) != 32 {
+				return fmt.Errorf("Invalid encryption key, must be 32 bytes encoded as hex")
+			}
+
+			return nil
+		}),
+	}
+}
+
+// luksFillConfig generates the pool key used to encrypt volumes if not already set.
+func luksFillConfig(config map[string]string) error {
+	if config["volatile.encryption.key"] != "" {
+		return nil
+	}
+
+	key, err := shared.RandomCryptoString()
+	if err != nil {
+		return fmt.Errorf("Failed generating pool encryption key: %w", err)
+	}
+
+	config["volatile.encryption.key"] = key
+
+	return nil
+}
+
+// volumeEncrypted returns whether the volume is encrypted.
+// Only the volume's own config is considered so that changing the pool's default doesn't affect existing volumes.
+func (d *common) volumeEncrypted(vol Volume) bool {
+	return shared.IsTrue(vol.config["encryption"])
+}
+
+// luksSizeBytes returns the size of the block device needed to store an encrypted volume of the given size.
+func (d *common) luksSizeBytes(vol Volume, sizeBytes int64) int64 {
+	if !d.volumeEncrypted(vol) || sizeBytes <= 0 {
+		return sizeBytes
+	}
+
+	return sizeBytes + luksHeaderSize
+}
+
+// luksMapperName returns the device mapper name used for the opened volume.
+// A hash of the volume's identity is used as volume names can be longer than what device mapper allows.
+func (d *common) luksMapperName(vol Volume) string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s/%s/%s/%s", d.name, vol.volType, vol.contentType, vol.name)))
+
+	return fmt.Sprintf("lxd_crypt_%x", hash[:12])
+}
+
+// luksDevPath returns the path of the decrypted device of an opened volume.
+func (d *common) luksDevPath(vol Volume) string {
+	return fmt.Sprintf("/dev/mapper/%s", d.luksMapperName(vol))
+}
+
+// luksLabel returns the label that indicates which key should be used to unlock the volume.
+func (d *common) luksLabel(vol Volume) string {
+	if shared.IsFalseOrEmpty(d.config["encryption.project_keys"]) {
+		return luksPoolKeyLabel
+	}
+
+	parentName, _, _ := api.GetParentAndSnapshotName(vol.name)
+
+	switch vol.volType {
+	case VolumeTypeContainer, VolumeTypeVM:
+		projectName, _ := project.InstanceParts(parentName)
+		return luksProjectKeyLabelPrefix + projectName
+	case VolumeTypeCustom:
+		projectName, _ := project.StorageVolumeParts(parentName)
+		return luksProjectKeyLabelPrefix + projectName
+	}
+
+	// Image volumes are shared between projects.
+	return luksPoolKeyLabel
+}
+
+// luksKey returns the key matching the given LUKS label.
+func (d *common) luksKey(label string) ([]byte, error) {
+	poolKey := d.config["volatile.encryption.key"]
+	if poolKey == "" {
+		return nil, fmt.Errorf("Storage pool %q has no encryption key", d.name)
+	}
+
+	if label == luksPoolKeyLabel {
+		return []byte(poolKey), nil
+	}
+
+	projectName, found := strings.CutPrefix(label, luksProjectKeyLabelPrefix)
+	if !found || projectName == "" {
+		return nil, fmt.Errorf("Unknown encryption key label %q", label)
+	}
+
+	mac := hmac.New(sha256.New, []byte(poolKey))
+	_, _ = mac.Write([]byte(projectName))
+
+	return []byte(hex.EncodeToString(mac.Sum(nil))), nil
+}
+
+// luksFormat formats the block device as a LUKS2 volume.
+// As the pool and project keys are random, a low PBKDF2 iteration count is used rather than the memory hard
+// default to keep opening volumes fast.
+func (d *common) luksFormat(vol Volume, devPath string) error {
+	label := d.luksLabel(vol)
+
+	key, err := d.luksKey(label)
+	if err != nil {
+		return err
+	}
+
+	err = shared.RunCommandWithFds(context.TODO(), bytes.NewReader(key), nil, "cryptsetup", "luksFormat",
+		"--batch-mode",
+		"--type", "luks2",
+		"--pbkdf", "pbkdf2",
+		"--pbkdf-force-iterations", "1000",
+		"--offset", fmt.Sprintf("%d", luksHeaderSize/512),
+		"--label", label,
+		"--key-file", "-",
+		devPath)
+	if err != nil {
+		return fmt.Errorf("Failed formatting encrypted volume %q: %w", vol.name, err)
+	}
+
+	d.logger.Debug("Formatted encrypted volume", logger.Ctx{"volName": vol.name, "dev": devPath, "label": label})
+
+	return nil
+}
+
+// luksHeaderLabel reads the label from the LUKS header of the block device.
+func (d *common) luksHeaderLabel(devPath string) (string, error) {
+	out, err := shared.RunCommandCLocale("cryptsetup", "luksDump", devPath)
+	if err != nil {
+		return "", fmt.Errorf("Failed reading encryption header of %q: %w", devPath, err)
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		label, found := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "Label:")
+		if found {
+			return strings.TrimSpace(label), nil
+		}
+	}
+
+	return "", fmt.Errorf("Encryption header of %q has no label", devPath)
+}
+
+// luksOpen opens the encrypted volume stored on the block device. Returns true if opened, false if already open.
+// The key is picked based on the label of the header, which allows volumes cloned from a volume using another
+// key to be opened. Writable non-snapshot volumes are then switched over to the key they are expected to use.
+func (d *common) luksOpen(vol Volume, devPath string, readonly bool) (bool, error) {
+	if shared.PathExists(d.luksDevPath(vol)) {
+		return false, nil
+	}
+
+	label, err := d.luksHeaderLabel(devPath)
+	if err != nil {
+		return false, err
+	}
+
+	key, err := d.luksKey(label)
+	if err != nil {
+		return false, err
+	}
+
+	args := []string{"open", "--type", "luks2", "--key-file", "-"}
+	if readonly {
+		args = append(args, "--readonly")
+	}
+
+	args = append(args, devPath, d.luksMapperName(vol))
+
+	err = shared.RunCommandWithFds(context.TODO(), bytes.NewReader(key), nil, "cryptsetup", args...)
+	if err != nil {
+		return false, fmt.Errorf("Failed opening encrypted volume %q: %w", vol.name, err)
+	}
+
+	d.logger.Debug("Opened encrypted volume", logger.Ctx{"volName": vol.name, "dev": devPath, "path": d.luksDevPath(vol)})
+
+	newLabel := d.luksLabel(vol)
+	if !readonly && !vol.IsSnapshot() && newLabel != label {
+		err = d.luksChangeKey(vol, devPath, key, newLabel)
+		if err != nil {
+			_, _ = d.luksClose(vol)
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// luksChangeKey replaces the key of the encrypted volume stored on the block device by the one matching newLabel.
+func (d *common) luksChangeKey(vol Volume, devPath string, oldKey []byte, newLabel string) error {
+	newKey, err := d.luksKey(newLabel)
+	if err != nil {
+		return err
+	}
+
+	// Pass both keys through pipes so they never touch the disk.
+	var files []*os.File
+	defer func() {
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}()
+
+	for _, key := range [][]byte{oldKey, newKey} {
+		r, w, err := os.Pipe()
+		if err != nil {
+			return err
+		}
+
+		files = append(files, r, w)
+
+		_, err = w.Write(key)
+		if err != nil {
+			return err
+		}
+
+		_ = w.Close()
+	}
+
+	_, err = shared.RunCommandInheritFds(context.TODO(), []*os.File{files[0], files[2]}, "cryptsetup", "luksChangeKey",
+		"--batch-mode",
+		"--pbkdf", "pbkdf2",
+		"--pbkdf-force-iterations", "1000",
+		"--key-file", "/dev/fd/3",
+		devPath, "/dev/fd/4")
+	if err != nil {
+		return fmt.Errorf("Failed changing key of encrypted volume %q: %w", vol.name, err)
+	}
+
+	_, err = shared.RunCommand("cryptsetup", "config", "--label", newLabel, devPath)
+	if err != nil {
+		return fmt.Errorf("Failed setting label of encrypted volume %q: %w", vol.name, err)
+	}
+
+	d.logger.Debug("Changed key of encrypted volume", logger.Ctx{"volName": vol.name, "dev": devPath, "label": newLabel})
+
+	return nil
+}
+
+// luksClose closes the encrypted volume if open. Returns true if closed, false if it wasn't open.
+func (d *common) luksClose(vol Volume) (bool, error) {
+	if !shared.PathExists(d.luksDevPath(vol)) {
+		return false, nil
+	}
+
+	_, err := shared.TryRunCommand("cryptsetup", "close", d.luksMapperName(vol))
+	if err != nil {
+		return false, fmt.Errorf("Failed closing encrypted volume %q: %w", vol.name, err)
+	}
+
+	d.logger.Debug("Closed encrypted volume", logger.Ctx{"volName": vol.name})
+
+	return true, nil
+}
+
+// luksResize resizes the opened encrypted volume to the given size, or to fill the underlying block device if
+// sizeBytes is 0. Does nothing if the volume isn't open.
+func (d *common) luksResize(vol Volume, sizeBytes int64) error {
+	if !shared.PathExists(d.luksDevPath(vol)) {
+		return nil
+	}
+
+	// Find the underlying block device to read the label from.
+	out, err := shared.RunCommandCLocale("cryptsetup", "status", d.luksMapperName(vol))
+	if err != nil {
+		return fmt.Errorf("Failed getting status of encrypted volume %q: %w", vol.name, err)
+	}
+
+	devPath := ""
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		device, found := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "device:")
+		if found {
+			devPath = strings.TrimSpace(device)
+			break
+		}
+	}
+
+	if devPath == "" {
+		return fmt.Errorf("Failed finding underlying device of encrypted volume %q", vol.name)
+	}
+
+	label, err := d.luksHeaderLabel(devPath)
+	if err != nil {
+		return err
+	}
+
+	key, err := d.luksKey(label)
+	if err != nil {
+		return err
+	}
+
+	args := []string{"resize", "--key-file", "-"}
+	if sizeBytes > 0 {
+		args = append(args, "--size", fmt.Sprintf("%d", sizeBytes/512))
+	}
+
+	args = append(args, d.luksMapperName(vol))
+
+	err = shared.RunCommandWithFds(context.TODO(), bytes.NewReader(key), nil, "cryptsetup", args...)
+	if err != nil {
+		return fmt.Errorf("Failed resizing encrypted volume %q: %w", vol.name, err)
+	}
+
+	return nil
+}
+
+// luksTask runs f against the decrypted device of the volume stored on devPath, opening the encrypted volume
+// for the duration of the task if not already open. For volumes that aren't encrypted f is run against devPath.
+func (d *common) luksTask(vol Volume, devPath string, f func(devPath string) error) error {
+	if !d.volumeEncrypted(vol) {
+		return f(devPath)
+	}
+
+	opened, err := d.luksOpen(vol, devPath, false)
+	if err != nil {
+		return err
+	}
+
+	if opened {
+		defer func() { _, _ = d.luksClose(vol) }()
+	}
+
+	return f(d.luksDevPath(vol))
+}
@@ -0,0 +1,81 @@
+package drivers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test luksLabel.
+func TestLuksLabel(t *testing.T) {
+	d := &common{name: "testpool", config: map[string]string{}}
+
+	ctVol := Volume{volType: VolumeTypeContainer, contentType: ContentTypeFS, name: "proj_c1"}
+	ctSnapVol := Volume{volType: VolumeTypeContainer, contentType: ContentTypeFS, name: "proj_c1/snap0"}
+	customVol := Volume{volType: VolumeTypeCustom, contentType: ContentTypeBlock, name: "proj_vol1"}
+	imgVol := Volume{volType: VolumeTypeImage, contentType: ContentTypeBlock, name: "fingerprint"}
+
+	// Test pool key.
+	for _, vol := range []Volume{ctVol, ctSnapVol, customVol, imgVol} {
+		assert.Equal(t, luksPoolKeyLabel, d.luksLabel(vol))
+	}
+
+	// Test project keys.
+	d.config["encryption.project_keys"] = "true"
+	assert.Equal(t, luksProjectKeyLabelPrefix+"proj", d.luksLabel(ctVol))
+	assert.Equal(t, luksProjectKeyLabelPrefix+"proj", d.luksLabel(ctSnapVol))
+	assert.Equal(t, luksProjectKeyLabelPrefix+"proj", d.luksLabel(customVol))
+	assert.Equal(t, luksPoolKeyLabel, d.luksLabel(imgVol))
+}
+
+// Test luksKey.
+func TestLuksKey(t *testing.T) {
+	d := &common{name: "testpool", config: map[string]string{}}
+
+	// Test missing pool key.
+	_, err := d.luksKey(luksPoolKeyLabel)
+	assert.Error(t, err)
+
+	d.config["volatile.encryption.key"] = strings.Repeat("ab", 32)
+
+	poolKey, err := d.luksKey(luksPoolKeyLabel)
+	assert.NoError(t, err)
+	assert.Equal(t, d.config["volatile.encryption.key"], string(poolKey))
+
+	// Test project keys are derived from the pool key and differ between projects.
+	projKey1, err := d.luksKey(luksProjectKeyLabelPrefix + "proj1")
+	assert.NoError(t, err)
+	assert.Len(t, projKey1, 64)
+	assert.NotEqual(t, poolKey, projKey1)
+
+	projKey2, err := d.luksKey(luksProjectKeyLabelPrefix + "proj2")
+	assert.NoError(t, err)
+	assert.NotEqual(t, projKey1, projKey2)
+
+	// Test unknown labels.
+	_, err = d.luksKey("other")
+	assert.Error(t, err)
+
+	_, err = d.luksKey(luksProjectKeyLabelPrefix)
+	assert.Error(t, err)
+}
+
+// Test luksSizeBytes and luksMapperName.
+func TestLuksVolumeHelpers(t *testing.T) {
+	d := &common{name: "testpool", config: map[string]string{}}
+
+	vol := Volume{volType: VolumeTypeCustom, contentType: ContentTypeBlock, name: "proj_vol1", config: map[string]string{}}
+	assert.Equal(t, int64(1024), d.luksSizeBytes(vol, 1024))
+
+	vol.config["encryption"] = "true"
+	assert.Equal(t, int64(1024+luksHeaderSize), d.luksSizeBytes(vol, 1024))
+	assert.Equal(t, int64(0), d.luksSizeBytes(vol, 0))
+
+	// Test mapper names are stable, unique per volume and short enough for device mapper.
+	snapVol := Volume{volType: VolumeTypeCustom, contentType: ContentTypeBlock, name: "proj_vol1/snap0"}
+	assert.Equal(t, d.luksMapperName(vol), d.luksMapperName(vol))
+	assert.NotEqual(t, d.luksMapperName(vol), d.luksMapperName(snapVol))
+	assert.LessOrEqual(t, len(d.luksMapperName(vol)), 127)
+	assert.Equal(t, "/dev/mapper/"+d.luksMapperName(vol), d.luksDevPath(vol))
+}
@@ -1,6 +1,8 @@
 package drivers
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -35,3 +37,35 @@ func TestGetVolumeMountPath(t *testing.T) {
 	expected = GetPoolMountPath(poolName) + "/virtual-machines/testvol"
 	assert.Equal(t, expected, path)
 }
+
+// Test directoryUsage.
+func TestDirectoryUsage(t *testing.T) {
+	dir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(dir, "file"), make([]byte, 1024*1024), 0600)
+	assert.NoError(t, err)
+
+	usage, err := directoryUsage(dir)
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, usage, int64(1024*1024))
+
+	// Test hard linked files are only counted once.
+	err = os.Link(filepath.Join(dir, "file"), filepath.Join(dir, "link"))
+	assert.NoError(t, err)
+
+	directoryUsageCacheMu.Lock()
+	delete(directoryUsageCache, dir)
+	directoryUsageCacheMu.Unlock()
+
+	linkedUsage, err := directoryUsage(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, usage, linkedUsage)
+
+	// Test the cached usage is returned.
+	err = os.WriteFile(filepath.Join(dir, "other"), make([]byte, 1024*1024), 0600)
+	assert.NoError(t, err)
+
+	cachedUsage, err := directoryUsage(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, usage, cachedUsage)
+}
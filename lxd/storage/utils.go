@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 
 	"github.com/canonical/lxd/lxd/apparmor"
 	"github.com/canonical/lxd/lxd/archive"
+	backupConfig "github.com/canonical/lxd/lxd/backup/config"
 	"github.com/canonical/lxd/lxd/db"
 	"github.com/canonical/lxd/lxd/db/cluster"
 	"github.com/canonical/lxd/lxd/instance"
@@ -31,6 +33,7 @@ import (
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/ioprogress"
 	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/units"
 	"github.com/canonical/lxd/shared/validate"
 )
 
@@ -71,6 +74,20 @@ func ConfigDiff(oldConfig map[string]string, newConfig map[string]string) ([]str
 	return changedConfig, userOnly
 }
 
+// publicPoolConfig returns a copy of the storage pool config without the internal keys which mustn't be exposed.
+func publicPoolConfig(config map[string]string) map[string]string {
+	publicConfig := make(map[string]string, len(config))
+	for k, v := range config {
+		if shared.ValueInSlice(k, db.InternalStorageConfig) {
+			continue
+		}
+
+		publicConfig[k] = v
+	}
+
+	return publicConfig
+}
+
 // VolumeTypeNameToDBType converts a volume type string to internal volume type DB code.
 func VolumeTypeNameToDBType(volumeTypeName string) (int, error) {
 	switch volumeTypeName {
@@ -485,7 +502,7 @@ func poolAndVolumeCommonRules(vol *drivers.Volume) map[string]func(string) error
 		//  defaultdesc: same as `volume.size`
 		//  shortdesc: Size/quota of the storage bucket
 		"size": validate.Optional(validate.IsSize),
-		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex; group=volume-conf; key=snapshots.expiry)
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex,storage-nfs; group=volume-conf; key=snapshots.expiry)
 		// Specify an expression like `1M 2H 3d 4w 5m 6y`.
 		// ---
 		//  type: string
@@ -497,7 +514,7 @@ func poolAndVolumeCommonRules(vol *drivers.Volume) map[string]func(string) error
 			_, err := shared.GetExpiry(time.Time{}, value)
 			return err
 		},
-		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex; group=volume-conf; key=snapshots.schedule)
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex,storage-nfs; group=volume-conf; key=snapshots.schedule)
 		// Specify either a cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`), or leave empty to disable automatic snapshots (the default).
 		// ---
 		//  type: string
@@ -505,7 +522,7 @@ func poolAndVolumeCommonRules(vol *drivers.Volume) map[string]func(string) error
 		//  defaultdesc: same as `snapshots.schedule`
 		//  shortdesc: Schedule for automatic volume snapshots
 		"snapshots.schedule": validate.Optional(validate.IsCron([]string{"@hourly", "@daily", "@midnight", "@weekly", "@monthly", "@annually", "@yearly"})),
-		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex; group=volume-conf; key=snapshots.pattern)
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex,storage-nfs; group=volume-conf; key=snapshots.pattern)
 		// You can specify a naming template that is used for scheduled snapshots and unnamed snapshots.
 		//
 		// {{snapshot_pattern_detail}}
@@ -517,9 +534,58 @@ func poolAndVolumeCommonRules(vol *drivers.Volume) map[string]func(string) error
 		"snapshots.pattern": validate.IsAny,
 	}
 
+	// Scheduled backups and I/O limits are only supported for custom volumes.
+	if vol != nil && vol.Type() == drivers.VolumeTypeCustom {
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex,storage-nfs; group=volume-conf; key=backups.schedule)
+		// Specify either a cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`), or `@never` or an empty value to disable automatic backups (the default).
+		// ---
+		//  type: string
+		//  condition: custom volume
+		//  shortdesc: Schedule for automatic volume backups
+		rules["backups.schedule"] = validate.Optional(validate.IsCron([]string{"@hourly", "@daily", "@midnight", "@weekly", "@monthly", "@annually", "@yearly", "@never"}))
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex,storage-nfs; group=volume-conf; key=backups.expiry)
+		// Specify an expression like `1M 2H 3d 4w 5m 6y`.
+		// ---
+		//  type: string
+		//  condition: custom volume
+		//  shortdesc: When scheduled backups are to be deleted
+		rules["backups.expiry"] = func(value string) error {
+			// Validate expression
+			_, err := shared.GetExpiry(time.Time{}, value)
+			return err
+		}
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex,storage-nfs; group=volume-conf; key=backups.target)
+		// Specify `volume:<pool>/<volume>` to write scheduled backups to a custom storage volume, or `bucket:<pool>/<bucket>` to write them to a storage bucket.
+		// Leave empty to keep scheduled backups with the other backups of the volume.
+		// ---
+		//  type: string
+		//  condition: custom volume
+		//  shortdesc: Where to write scheduled backups to
+		rules["backups.target"] = validate.Optional(backupConfig.ValidateTarget)
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex,storage-nfs; group=volume-conf; key=limits.iops)
+		// The limit applies to both reads and writes, and is enforced on every instance the volume is attached to.
+		// It can't be set on filesystem volumes that are attached to virtual machines.
+		// If the disk device also sets an I/O operations limit, the lowest one applies.
+		// ---
+		//  type: integer
+		//  condition: custom volume
+		//  shortdesc: Maximum number of I/O operations per second
+		rules["limits.iops"] = validate.Optional(validate.IsUint32)
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex,storage-nfs; group=volume-conf; key=limits.bandwidth)
+		// Specify the value in bytes per second ({ref}`suffixes <instances-limit-units>` are supported).
+		// The limit applies to both reads and writes, and is enforced on every instance the volume is attached to.
+		// It can't be set on filesystem volumes that are attached to virtual machines.
+		// If the disk device also sets a bandwidth limit, the lowest one applies.
+		// ---
+		//  type: string
+		//  condition: custom volume
+		//  shortdesc: Maximum I/O bandwidth
+		rules["limits.bandwidth"] = validate.Optional(validate.IsSize)
+	}
+
 	// security.shifted and security.unmapped are only relevant for custom filesystem volumes.
 	if vol == nil || (vol.Type() == drivers.VolumeTypeCustom && vol.ContentType() == drivers.ContentTypeFS) {
-		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex; group=volume-conf; key=security.shifted)
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex,storage-nfs; group=volume-conf; key=security.shifted)
 		// Enabling this option allows attaching the volume to multiple isolated instances.
 		// ---
 		//  type: bool
@@ -527,7 +593,7 @@ func poolAndVolumeCommonRules(vol *drivers.Volume) map[string]func(string) error
 		//  defaultdesc: same as `volume.security.shifted` or `false`
 		//  shortdesc: Enable ID shifting overlay
 		rules["security.shifted"] = validate.Optional(validate.IsBool)
-		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex; group=volume-conf; key=security.unmapped)
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex,storage-nfs; group=volume-conf; key=security.unmapped)
 		//
 		// ---
 		//  type: bool
@@ -552,7 +618,7 @@ func poolAndVolumeCommonRules(vol *drivers.Volume) map[string]func(string) error
 
 	// Those keys are only valid for volumes.
 	if vol != nil {
-		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex; group=volume-conf; key=volatile.uuid)
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex,storage-nfs; group=volume-conf; key=volatile.uuid)
 		//
 		// ---
 		//  type: string
@@ -597,6 +663,13 @@ func validatePoolCommonRules() map[string]func(string) error {
 		//  type: string
 		//  shortdesc: Path to an existing block device, loop file, or LVM volume group
 
+		// lxdmeta:generate(entities=storage-nfs; group=pool-conf; key=source)
+		// Specify either an NFS export in the `<host>:<path>` form, or the path to an existing directory on a
+		// shared file system that is already mounted on all cluster members.
+		// ---
+		//  type: string
+		//  shortdesc: NFS export or path to an already mounted shared file system
+
 		// lxdmeta:generate(entities=storage-zfs; group=pool-conf; key=source)
 		//
 		// ---
@@ -612,7 +685,7 @@ func validatePoolCommonRules() map[string]func(string) error {
 		//  shortdesc: Whether to wipe the block device before creating the pool
 		"source.wipe":             validate.Optional(validate.IsBool),
 		"volatile.initial_source": validate.IsAny,
-		// lxdmeta:generate(entities=storage-dir,storage-lvm,storage-powerflex; group=pool-conf; key=rsync.bwlimit)
+		// lxdmeta:generate(entities=storage-dir,storage-lvm,storage-powerflex,storage-nfs; group=pool-conf; key=rsync.bwlimit)
 		// When `rsync` must be used to transfer storage entities, this option specifies the upper limit
 		// to be placed on the socket I/O.
 		// ---
@@ -620,13 +693,40 @@ func validatePoolCommonRules() map[string]func(string) error {
 		//  defaultdesc: `0` (no limit)
 		//  shortdesc: Upper limit on the socket I/O for `rsync`
 		"rsync.bwlimit": validate.Optional(validate.IsSize),
-		// lxdmeta:generate(entities=storage-dir,storage-lvm,storage-powerflex; group=pool-conf; key=rsync.compression)
+		// lxdmeta:generate(entities=storage-dir,storage-lvm,storage-powerflex,storage-nfs; group=pool-conf; key=rsync.compression)
 		//
 		// ---
 		//  type: bool
 		//  defaultdesc: `true`
 		//  shortdesc: Whether to use compression while migrating storage pools
 		"rsync.compression": validate.Optional(validate.IsBool),
+		// lxdmeta:generate(entities=storage-btrfs,storage-ceph,storage-cephfs,storage-dir,storage-lvm,storage-nfs,storage-powerflex,storage-zfs; group=pool-conf; key=volume.overcommit_ratio)
+		// The total size allocated to the volumes on the pool can't exceed the pool's capacity multiplied by
+		// this ratio. Creating or growing a volume that would exceed this limit fails.
+		// For example, set it to `1.5` to allow allocating up to 150% of the pool's capacity.
+		// ---
+		//  type: string
+		//  defaultdesc: no limit
+		//  shortdesc: Maximum ratio of allocated space to pool capacity
+		"volume.overcommit_ratio": validate.Optional(func(value string) error {
+			ratio, err := strconv.ParseFloat(value, 64)
+			if err != nil || ratio <= 0 {
+				return fmt.Errorf("Must be a positive number")
+			}
+
+			return nil
+		}),
+		// lxdmeta:generate(entities=storage-btrfs,storage-ceph,storage-cephfs,storage-dir,storage-lvm,storage-nfs,storage-powerflex,storage-zfs; group=pool-conf; key=space.warning_threshold)
+		// Specify either a percentage of the pool's capacity (for example, `10%`) or a size (for example, `20GiB`).
+		// A warning is raised when the free space on the pool falls below this threshold.
+		// ---
+		//  type: string
+		//  defaultdesc: no warning
+		//  shortdesc: Free space below which a warning is raised
+		"space.warning_threshold": validate.Optional(func(value string) error {
+			_, err := ParseSpaceWarningThreshold(value, 0)
+			return err
+		}),
 	}
 
 	// Add to pool config rules (prefixed with volume.*) which are common for pool and volume.
@@ -637,6 +737,31 @@ func validatePoolCommonRules() map[string]func(string) error {
 	return rules
 }
 
+// ParseSpaceWarningThreshold returns the free space (in bytes) below which a warning should be raised for a pool
+// of the given total size. The threshold is either a percentage of the total size or a size.
+func ParseSpaceWarningThreshold(value string, total uint64) (uint64, error) {
+	percentage, isPercentage := strings.CutSuffix(value, "%")
+	if isPercentage {
+		ratio, err := strconv.ParseFloat(percentage, 64)
+		if err != nil || ratio < 0 || ratio > 100 {
+			return 0, fmt.Errorf("Invalid percentage %q", value)
+		}
+
+		return uint64(float64(total) * ratio / 100), nil
+	}
+
+	size, err := units.ParseByteSizeString(value)
+	if err != nil {
+		return 0, err
+	}
+
+	if size < 0 {
+		return 0, fmt.Errorf("Invalid size %q", value)
+	}
+
+	return uint64(size), nil
+}
+
 // validateVolumeCommonRules returns a map of volume config rules common to all drivers.
 func validateVolumeCommonRules(vol drivers.Volume) map[string]func(string) error {
 	rules := poolAndVolumeCommonRules(&vol)
@@ -1143,6 +1268,32 @@ func FallbackMigrationType(contentType drivers.ContentType) migration.MigrationF
 	return migration.MigrationFSType_RSYNC
 }
 
+// EncryptedMigrationTypes returns the migration types that can be used to transfer volumes with the given configs.
+// If any of the volumes is encrypted only the generic transfer modes are returned, as the optimized ones would copy
+// the encrypted data which can only be opened using the keys of the source storage pool.
+func EncryptedMigrationTypes(types []migration.Type, volConfigs ...map[string]string) []migration.Type {
+	encrypted := false
+	for _, volConfig := range volConfigs {
+		if shared.IsTrue(volConfig["encryption"]) {
+			encrypted = true
+			break
+		}
+	}
+
+	if !encrypted {
+		return types
+	}
+
+	genericTypes := make([]migration.Type, 0, len(types))
+	for _, t := range types {
+		if t.FSType == migration.MigrationFSType_RSYNC || t.FSType == migration.MigrationFSType_BLOCK_AND_RSYNC {
+			genericTypes = append(genericTypes, t)
+		}
+	}
+
+	return genericTypes
+}
+
 // RenderSnapshotUsage can be used as an optional argument to Instance.Render() to return snapshot usage.
 // As this is a relatively expensive operation it is provided as an optional feature rather than on by default.
 func RenderSnapshotUsage(s *state.State, snapInst instance.Instance) func(response any) error {
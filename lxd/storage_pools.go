@@ -298,6 +298,15 @@ func storagePoolsPost(d *Daemon, r *http.Request) response.Response {
 		req.Config = map[string]string{}
 	}
 
+	// Internal config keys are generated by LXD and only forwarded between cluster members.
+	if !isClusterNotification(r) {
+		for k := range req.Config {
+			if shared.ValueInSlice(k, db.InternalStorageConfig) {
+				return response.BadRequest(fmt.Errorf("Config key %q is internal and cannot be set", k))
+			}
+		}
+	}
+
 	ctx := logger.Ctx{}
 
 	targetNode := request.QueryParam(r, "target")
@@ -770,6 +779,11 @@ func storagePoolPut(d *Daemon, r *http.Request) response.Response {
 		}
 	}
 
+	// The internal keys are never returned by the GET request either.
+	for _, key := range db.InternalStorageConfig {
+		delete(etagConfig, key)
+	}
+
 	// Validate the ETag.
 	etag := []any{pool.Name(), pool.Driver().Info().Name, pool.Description(), etagConfig}
 
@@ -785,6 +799,13 @@ func storagePoolPut(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
+	// Internal config keys are managed by LXD and can't be set through the API.
+	for k := range req.Config {
+		if shared.ValueInSlice(k, db.InternalStorageConfig) {
+			return response.BadRequest(fmt.Errorf("Config key %q is internal and cannot be set", k))
+		}
+	}
+
 	// In clustered mode, we differentiate between node specific and non-node specific config keys based on
 	// whether the user has specified a target to apply the config to.
 	if s.ServerClustered {
@@ -873,6 +894,13 @@ func doStoragePoolUpdate(s *state.State, pool storagePools.Pool, req api.Storage
 		req.Config = map[string]string{}
 	}
 
+	// The internal config keys aren't part of the request, so always carry over their current values.
+	for k, v := range pool.Driver().Config() {
+		if shared.ValueInSlice(k, db.InternalStorageConfig) {
+			req.Config[k] = v
+		}
+	}
+
 	// Normally a "put" request will replace all existing config, however when clustered, we need to account
 	// for the node specific config keys and not replace them when the request doesn't specify a specific node.
 	if targetNode == "" && httpMethod != http.MethodPatch && clustered {
@@ -912,7 +940,8 @@ func doStoragePoolUpdate(s *state.State, pool storagePools.Pool, req api.Storage
 		sendPool.Config = make(map[string]string)
 		for k, v := range req.Config {
 			// Don't forward node specific keys (these will be merged in on recipient node).
-			if shared.ValueInSlice(k, db.NodeSpecificStorageConfig) {
+			// The internal keys are shared through the database and are also merged in on recipient node.
+			if shared.ValueInSlice(k, db.NodeSpecificStorageConfig) || shared.ValueInSlice(k, db.InternalStorageConfig) {
 				continue
 			}
 
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/db/warningtype"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	storagePools "github.com/canonical/lxd/lxd/storage"
+	storageDrivers "github.com/canonical/lxd/lxd/storage/drivers"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/lxd/warnings"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/units"
+)
+
+// storagePoolSpaceCacheVal holds the space usage of the storage pools (map[string]api.ResourcesStoragePoolSpace)
+// as of the last run of the storage pool space check, for use in metrics.
+var storagePoolSpaceCacheVal atomic.Value
+
+// readStoragePoolSpaceCache returns the space usage of the storage pools keyed by pool name.
+func readStoragePoolSpaceCache() map[string]api.ResourcesStoragePoolSpace {
+	poolSpace, _ := storagePoolSpaceCacheVal.Load().(map[string]api.ResourcesStoragePoolSpace)
+	return poolSpace
+}
+
+// storagePoolLowSpace records whether the low space warning of each storage pool (keyed by pool ID) was raised
+// by the last storage pool space check, so that the warning is only updated when its status changes.
+var storagePoolLowSpace = map[int64]bool{}
+var storagePoolLowSpaceMu sync.Mutex
+
+func storagePoolSpaceCheckTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		storagePoolSpaceCheck(ctx, d.State())
+	}
+
+	return f, task.Every(5 * time.Minute)
+}
+
+// storagePoolSpaceCheck refreshes the storage pool space cache and raises a warning for each storage pool whose
+// free space is below its space.warning_threshold (resolving it once the free space is back above the threshold).
+func storagePoolSpaceCheck(ctx context.Context, s *state.State) {
+	var poolNames []string
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		poolNames, err = tx.GetCreatedStoragePoolNames(ctx)
+
+		return err
+	})
+	if err != nil && !response.IsNotFoundError(err) {
+		logger.Error("Failed loading storage pools for space check", logger.Ctx{"err": err})
+		return
+	}
+
+	poolSpace := make(map[string]api.ResourcesStoragePoolSpace, len(poolNames))
+	for _, poolName := range poolNames {
+		pool, err := storagePools.LoadByName(s, poolName)
+		if err != nil {
+			logger.Warn("Failed loading storage pool for space check", logger.Ctx{"pool": poolName, "err": err})
+			continue
+		}
+
+		res, err := pool.GetResources()
+		if err != nil {
+			if !errors.Is(err, storageDrivers.ErrNotSupported) {
+				logger.Warn("Failed getting storage pool resources", logger.Ctx{"pool": poolName, "err": err})
+			}
+
+			continue
+		}
+
+		poolSpace[poolName] = res.Space
+
+		err = storagePoolUpdateLowSpaceWarning(s, pool, res.Space)
+		if err != nil {
+			logger.Warn("Failed updating storage pool low space warning", logger.Ctx{"pool": poolName, "err": err})
+		}
+	}
+
+	storagePoolSpaceCacheVal.Store(poolSpace)
+}
+
+// storagePoolUpdateLowSpaceWarning raises or resolves the low space warning of a storage pool.
+// The warning is only updated when it changes from being raised to resolved or the other way round.
+func storagePoolUpdateLowSpaceWarning(s *state.State, pool storagePools.Pool, space api.ResourcesStoragePoolSpace) error {
+	var lowSpaceMsg string
+
+	thresholdConfig := pool.Driver().Config()["space.warning_threshold"]
+	if thresholdConfig != "" && space.Total > 0 {
+		threshold, err := storagePools.ParseSpaceWarningThreshold(thresholdConfig, space.Total)
+		if err != nil {
+			return err
+		}
+
+		var free uint64
+		if space.Used < space.Total {
+			free = space.Total - space.Used
+		}
+
+		if free < threshold {
+			lowSpaceMsg = fmt.Sprintf("Free space %s is below the warning threshold of %s", units.GetByteSizeStringIEC(int64(free), 2), units.GetByteSizeStringIEC(int64(threshold), 2))
+		}
+	}
+
+	lowSpace := lowSpaceMsg != ""
+
+	storagePoolLowSpaceMu.Lock()
+	defer storagePoolLowSpaceMu.Unlock()
+
+	wasLowSpace, known := storagePoolLowSpace[pool.ID()]
+	if known && wasLowSpace == lowSpace {
+		return nil
+	}
+
+	var err error
+	if lowSpace {
+		err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.UpsertWarningLocalNode(ctx, "", entity.TypeStoragePool, int(pool.ID()), warningtype.StoragePoolLowSpace, lowSpaceMsg)
+		})
+	} else {
+		err = warnings.ResolveWarningsByLocalNodeAndProjectAndTypeAndEntity(s.DB.Cluster, "", warningtype.StoragePoolLowSpace, entity.TypeStoragePool, int(pool.ID()))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	storagePoolLowSpace[pool.ID()] = lowSpace
+
+	return nil
+}
@@ -2159,10 +2159,23 @@ func storagePoolVolumePut(d *Daemon, r *http.Request) response.Response {
 				return response.SmartError(err)
 			}
 
+			err = storagePoolVolumeValidateLimits(s, effectiveProjectName, details.pool.Name(), &dbVolume.StorageVolume, req.Config)
+			if err != nil {
+				return response.SmartError(err)
+			}
+
 			err = details.pool.UpdateCustomVolume(effectiveProjectName, dbVolume.Name, req.Description, req.Config, op)
 			if err != nil {
 				return response.SmartError(err)
 			}
+
+			// Apply the I/O limits to the running instances using the volume if they changed.
+			if req.Config["limits.iops"] != dbVolume.Config["limits.iops"] || req.Config["limits.bandwidth"] != dbVolume.Config["limits.bandwidth"] {
+				err = storagePoolVolumeUpdateLimits(s, effectiveProjectName, details.pool.Name(), &dbVolume.StorageVolume)
+				if err != nil {
+					return response.SmartError(err)
+				}
+			}
 		}
 	} else if details.volumeType == cluster.StoragePoolVolumeTypeContainer || details.volumeType == cluster.StoragePoolVolumeTypeVM {
 		inst, err := instance.LoadByProjectAndName(s, effectiveProjectName, dbVolume.Name)
@@ -2299,11 +2312,32 @@ func storagePoolVolumePatch(d *Daemon, r *http.Request) response.Response {
 	op := &operations.Operation{}
 	op.SetRequestor(r)
 
+	// Possibly check if project limits are honored.
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return limits.AllowVolumeUpdate(s.GlobalConfig, tx, effectiveProjectName, details.volumeName, req, dbVolume.Config)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = storagePoolVolumeValidateLimits(s, effectiveProjectName, details.pool.Name(), &dbVolume.StorageVolume, req.Config)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	err = details.pool.UpdateCustomVolume(effectiveProjectName, dbVolume.Name, req.Description, req.Config, op)
 	if err != nil {
 		return response.SmartError(err)
 	}
 
+	// Apply the I/O limits to the running instances using the volume if they changed.
+	if req.Config["limits.iops"] != dbVolume.Config["limits.iops"] || req.Config["limits.bandwidth"] != dbVolume.Config["limits.bandwidth"] {
+		err = storagePoolVolumeUpdateLimits(s, effectiveProjectName, details.pool.Name(), &dbVolume.StorageVolume)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	return response.EmptySyncResponse
 }
 
@@ -2571,13 +2605,14 @@ func createStoragePoolVolumeFromBackup(s *state.State, r *http.Request, requestP
 	}
 
 	logger.Debug("Backup file info loaded", logger.Ctx{
-		"type":      bInfo.Type,
-		"name":      bInfo.Name,
-		"project":   bInfo.Project,
-		"backend":   bInfo.Backend,
-		"pool":      bInfo.Pool,
-		"optimized": *bInfo.OptimizedStorage,
-		"snapshots": bInfo.Snapshots,
+		"type":            bInfo.Type,
+		"name":            bInfo.Name,
+		"project":         bInfo.Project,
+		"backend":         bInfo.Backend,
+		"pool":            bInfo.Pool,
+		"optimized":       *bInfo.OptimizedStorage,
+		"snapshots":       bInfo.Snapshots,
+		"incrementalFrom": bInfo.IncrementalFrom,
 	})
 
 	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
@@ -2588,9 +2623,10 @@ func createStoragePoolVolumeFromBackup(s *state.State, r *http.Request, requestP
 	})
 	if response.IsNotFoundError(err) {
 		// The storage pool doesn't exist. If backup is in binary format (so we cannot alter
-		// the backup.yaml) or the pool has been specified directly from the user restoring
-		// the backup then we cannot proceed so return an error.
-		if *bInfo.OptimizedStorage || pool != "" {
+		// the backup.yaml), the pool has been specified directly from the user restoring
+		// the backup or the backup must be applied to an existing volume then we cannot
+		// proceed so return an error.
+		if *bInfo.OptimizedStorage || pool != "" || bInfo.IncrementalFrom != "" {
 			return response.InternalError(fmt.Errorf("Storage pool not found: %w", err))
 		}
 
@@ -2634,6 +2670,17 @@ func createStoragePoolVolumeFromBackup(s *state.State, r *http.Request, requestP
 			return fmt.Errorf("Optimized backup storage driver %q differs from the target storage pool driver %q", bInfo.Backend, pool.Driver().Info().Name)
 		}
 
+		// Apply incremental backups on top of the existing volume.
+		if bInfo.IncrementalFrom != "" {
+			err = pool.RefreshCustomVolumeFromBackup(*bInfo, backupFile, nil)
+			if err != nil {
+				return fmt.Errorf("Refresh custom volume from backup: %w", err)
+			}
+
+			runRevert.Success()
+			return nil
+		}
+
 		// Dump tarball to storage.
 		err = pool.CreateCustomVolumeFromBackup(*bInfo, backupFile, nil)
 		if err != nil {
@@ -2,12 +2,17 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 	"strings"
 
 	"github.com/canonical/lxd/lxd/backup"
+	lxdCluster "github.com/canonical/lxd/lxd/cluster"
 	"github.com/canonical/lxd/lxd/db"
 	"github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/device"
 	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/state"
 	storagePools "github.com/canonical/lxd/lxd/storage"
 	"github.com/canonical/lxd/shared"
@@ -84,6 +89,93 @@ func storagePoolVolumeUpdateUsers(s *state.State, projectName string, oldPoolNam
 	return nil
 }
 
+// storagePoolVolumeValidateLimits checks that I/O limits aren't set on a custom filesystem volume that is attached
+// to a virtual machine, as filesystem volumes are shared with virtual machines through virtiofs which can't be
+// throttled.
+func storagePoolVolumeValidateLimits(s *state.State, projectName string, poolName string, vol *api.StorageVolume, config map[string]string) error {
+	if vol.ContentType != cluster.StoragePoolVolumeContentTypeNameFS || (config["limits.iops"] == "" && config["limits.bandwidth"] == "") {
+		return nil
+	}
+
+	return storagePools.VolumeUsedByInstanceDevices(s, poolName, projectName, vol, true, func(dbInst db.InstanceArgs, project api.Project, usedByDevices []string) error {
+		if dbInst.Type == instancetype.VM {
+			return api.StatusErrorf(http.StatusBadRequest, "I/O limits can't be set on custom filesystem volume %q as it is attached to virtual machine %q in project %q", vol.Name, dbInst.Name, project.Name)
+		}
+
+		return nil
+	})
+}
+
+// storagePoolVolumeUpdateLimits applies the I/O limits of a custom volume to the running instances that use it.
+// The instances on other cluster members are handled by notifying their members.
+func storagePoolVolumeUpdateLimits(s *state.State, projectName string, poolName string, vol *api.StorageVolume) error {
+	members, err := storagePoolVolumeApplyLimits(s, projectName, poolName, vol)
+	if err != nil {
+		return err
+	}
+
+	for _, memberName := range members {
+		var member db.NodeInfo
+		err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			member, err = tx.GetNodeByName(ctx, memberName)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("Failed getting cluster member %q: %w", memberName, err)
+		}
+
+		client, err := lxdCluster.Connect(member.Address, s.Endpoints.NetworkCert(), s.ServerCert(), nil, true)
+		if err != nil {
+			return fmt.Errorf("Failed connecting to cluster member %q: %w", memberName, err)
+		}
+
+		u := api.NewURL().Path("internal", "storage-pools", poolName, "volumes", "custom", vol.Name, "limits").Project(projectName)
+		_, _, err = client.RawQuery(http.MethodPost, u.String(), nil, "")
+		if err != nil {
+			return fmt.Errorf("Failed applying limits on cluster member %q: %w", memberName, err)
+		}
+	}
+
+	return nil
+}
+
+// storagePoolVolumeApplyLimits applies the I/O limits of a custom volume to the running instances on this server
+// that use it. It returns the names of the other cluster members with instances using the volume.
+func storagePoolVolumeApplyLimits(s *state.State, projectName string, poolName string, vol *api.StorageVolume) ([]string, error) {
+	var members []string
+
+	err := storagePools.VolumeUsedByInstanceDevices(s, poolName, projectName, vol, true, func(dbInst db.InstanceArgs, project api.Project, usedByDevices []string) error {
+		if s.ServerClustered && dbInst.Node != s.ServerName {
+			if !shared.ValueInSlice(dbInst.Node, members) {
+				members = append(members, dbInst.Node)
+			}
+
+			return nil
+		}
+
+		inst, err := instance.Load(s, dbInst, project)
+		if err != nil {
+			return err
+		}
+
+		if !inst.IsRunning() {
+			return nil
+		}
+
+		err = device.DiskApplyLimits(s, inst, usedByDevices)
+		if err != nil {
+			return fmt.Errorf("Failed applying limits to instance %q in project %q: %w", inst.Name(), project.Name, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
 // storagePoolVolumeUsedByGet returns a list of URL resources that use the volume.
 func storagePoolVolumeUsedByGet(s *state.State, requestProjectName string, vol *db.StorageVolume) ([]string, error) {
 	// Handle instance volumes.
@@ -148,7 +240,7 @@ func storagePoolVolumeBackupLoadByName(s *state.State, projectName, poolName, ba
 	}
 
 	volumeName := strings.Split(backupName, "/")[0]
-	backup := backup.NewVolumeBackup(s, projectName, poolName, volumeName, b.ID, b.Name, b.CreationDate, b.ExpiryDate, b.VolumeOnly, b.OptimizedStorage)
+	backup := backup.NewVolumeBackup(s, projectName, poolName, volumeName, b.ID, b.Name, b.CreationDate, b.ExpiryDate, b.VolumeOnly, b.OptimizedStorage, b.IncrementalFrom)
 
 	return backup, nil
 }
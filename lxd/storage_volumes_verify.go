@@ -0,0 +1,260 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/backup"
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/db/warningtype"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	storageDrivers "github.com/canonical/lxd/lxd/storage/drivers"
+	"github.com/canonical/lxd/lxd/warnings"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/version"
+)
+
+var storagePoolVolumeTypeVerifyCmd = APIEndpoint{
+	Path: "storage-pools/{poolName}/volumes/{type}/{volumeName}/verify",
+
+	Post: APIEndpointAction{Handler: storagePoolVolumeTypeVerifyPost, AccessHandler: storagePoolVolumeTypeAccessHandler(auth.EntitlementCanEdit)},
+}
+
+// swagger:operation POST /1.0/storage-pools/{poolName}/volumes/{type}/{volumeName}/verify storage storage_pool_volume_type_verify_post
+//
+//	Verify the storage volume
+//
+//	Checks the integrity of the storage volume, its snapshots and its backups.
+//	Problems found are recorded as a warning on the volume and returned in the operation metadata.
+//	The parts of the volume that couldn't be checked are returned in the "unverified" key of the operation metadata.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: target
+//	    description: Cluster member name
+//	    type: string
+//	    example: lxd01
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func storagePoolVolumeTypeVerifyPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	details, err := request.GetCtxValue[storageVolumeDetails](r.Context(), ctxStorageVolumeDetails)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Check that the storage volume type is valid.
+	if !shared.ValueInSlice(details.volumeType, []int{cluster.StoragePoolVolumeTypeCustom, cluster.StoragePoolVolumeTypeContainer, cluster.StoragePoolVolumeTypeVM}) {
+		return response.BadRequest(fmt.Errorf("Invalid storage volume type %q", details.volumeTypeName))
+	}
+
+	if shared.IsSnapshot(details.volumeName) {
+		return response.BadRequest(fmt.Errorf("Storage volume snapshots are verified along with their parent volume"))
+	}
+
+	requestProjectName := request.ProjectParam(r)
+	effectiveProjectName, err := request.GetCtxValue[string](r.Context(), request.CtxEffectiveProjectName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Forward if needed.
+	resp := forwardedResponseIfTargetIsRemote(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	resp = forwardedResponseIfVolumeIsRemote(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	var inst instance.Instance
+	if details.volumeType != cluster.StoragePoolVolumeTypeCustom {
+		inst, err = instance.LoadByProjectAndName(s, effectiveProjectName, details.volumeName)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
+	var dbVolume *db.StorageVolume
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		dbVolume, err = tx.GetStoragePoolVolume(ctx, details.pool.ID(), effectiveProjectName, details.volumeType, details.volumeName, true)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	verify := func(op *operations.Operation) error {
+		var err error
+		var result *storageDrivers.VolumeVerifyResult
+		if inst != nil {
+			result, err = details.pool.VerifyInstance(inst, op)
+		} else {
+			result, err = details.pool.VerifyCustomVolume(effectiveProjectName, details.volumeName, op)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		backupProblems, err := storagePoolVolumeVerifyBackups(s, effectiveProjectName, details.pool.Name(), details.volumeType, details.volumeName, details.pool.ID())
+		if err != nil {
+			return err
+		}
+
+		problems := append([]string{}, result.Problems...)
+		problems = append(problems, backupProblems...)
+
+		unverified := result.Unverified
+		if unverified == nil {
+			unverified = []string{}
+		}
+
+		err = storagePoolVolumeUpdateVerifyWarning(s, effectiveProjectName, int(dbVolume.ID), problems)
+		if err != nil {
+			logger.Warn("Failed updating storage volume verification warning", logger.Ctx{"project": effectiveProjectName, "pool": details.pool.Name(), "volume": details.volumeName, "err": err})
+		}
+
+		return op.UpdateMetadata(map[string]any{"problems": problems, "unverified": unverified})
+	}
+
+	resources := map[string][]api.URL{}
+	resources["storage_volumes"] = []api.URL{*api.NewURL().Path(version.APIVersion, "storage-pools", details.pool.Name(), "volumes", details.volumeTypeName, details.volumeName)}
+
+	op, err := operations.OperationCreate(s, requestProjectName, operations.OperationClassTask, operationtype.VolumeVerify, resources, nil, verify, nil, nil, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
+
+// storagePoolVolumeVerifyBackups compares the backups of a volume stored on this server against their recorded
+// checksums and returns the backups that don't match.
+func storagePoolVolumeVerifyBackups(s *state.State, projectName string, poolName string, volumeType int, volumeName string, poolID int64) ([]string, error) {
+	backupPaths := map[string]string{}
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		if volumeType == cluster.StoragePoolVolumeTypeCustom {
+			backups, err := tx.GetStoragePoolVolumeBackupsNames(ctx, projectName, volumeName, poolID)
+			if err != nil {
+				return err
+			}
+
+			for _, backupName := range backups {
+				backupPaths[backupName] = shared.VarPath("backups", "custom", poolName, project.StorageVolume(projectName, backupName))
+			}
+
+			return nil
+		}
+
+		backups, err := tx.GetInstanceBackups(ctx, projectName, volumeName)
+		if err != nil {
+			return err
+		}
+
+		for _, backupName := range backups {
+			backupPaths[backupName] = shared.VarPath("backups", "instances", project.Instance(projectName, backupName))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	problems := []string{}
+	for backupName, backupPath := range backupPaths {
+		// Backups are only stored on the server that created them.
+		if !shared.PathExists(backupPath) {
+			continue
+		}
+
+		err := backup.VerifyChecksum(backupPath)
+		if err != nil {
+			_, name, _ := api.GetParentAndSnapshotName(backupName)
+			problems = append(problems, fmt.Sprintf("Backup %q failed verification: %v", name, err))
+		}
+	}
+
+	return problems, nil
+}
+
+// storagePoolVolumeUpdateVerifyWarning records the problems found while verifying a volume as a warning on the
+// volume, or resolves the existing warning if no problems were found.
+func storagePoolVolumeUpdateVerifyWarning(s *state.State, projectName string, volumeID int, problems []string) error {
+	if len(problems) == 0 {
+		return warnings.ResolveWarningsByLocalNodeAndProjectAndTypeAndEntity(s.DB.Cluster, projectName, warningtype.StorageVolumeVerificationFailure, entity.TypeStorageVolume, volumeID)
+	}
+
+	return s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.UpsertWarningLocalNode(ctx, projectName, entity.TypeStorageVolume, volumeID, warningtype.StorageVolumeVerificationFailure, strings.Join(problems, "\n"))
+	})
+}
+
+// storagePoolVolumeVerifyProblems returns the problems found by the last verification of a volume.
+func storagePoolVolumeVerifyProblems(s *state.State, projectName string, volumeID int) ([]string, error) {
+	var problems []string
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		typeCode := warningtype.StorageVolumeVerificationFailure
+		entityTypeCode := cluster.EntityType(entity.TypeStorageVolume)
+		filter := cluster.WarningFilter{
+			TypeCode:   &typeCode,
+			Project:    &projectName,
+			EntityType: &entityTypeCode,
+			EntityID:   &volumeID,
+		}
+
+		dbWarnings, err := cluster.GetWarnings(ctx, tx.Tx(), filter)
+		if err != nil {
+			return err
+		}
+
+		for _, w := range dbWarnings {
+			if w.Status == warningtype.StatusResolved {
+				continue
+			}
+
+			problems = append(problems, strings.Split(w.LastMessage, "\n")...)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return problems, nil
+}
@@ -10,14 +10,15 @@ import (
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sys/unix"
 
-	"github.com/canonical/lxd/lxd/events"
+	"github.com/canonical/lxd/lxd/network/acl"
+	"github.com/canonical/lxd/lxd/state"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/revert"
 )
 
 // StartSyslogListener starts the log monitor.
-func StartSyslogListener(ctx context.Context, eventServer *events.Server) error {
+func StartSyslogListener(ctx context.Context, s *state.State) error {
 	var listenConfig net.ListenConfig
 
 	sockFile := shared.VarPath("syslog.socket")
@@ -129,10 +130,18 @@ func StartSyslogListener(ctx context.Context, eventServer *events.Server) error
 				event.Context["application"] = applicationName
 			}
 
-			err = eventServer.Send("", api.EventTypeOVN, event)
+			err = s.Events.Send("", api.EventTypeOVN, event)
 			if err != nil {
 				continue
 			}
+
+			// Send the entries of the logged network ACL rules as network ACL events too.
+			if strings.HasPrefix(moduleName, "acl_log") {
+				projectName, aclEvent := acl.OVNLogEvent(s, message)
+				if aclEvent != nil {
+					_ = s.Events.Send(projectName, api.EventTypeNetworkACL, aclEvent)
+				}
+			}
 		}
 	}()
 
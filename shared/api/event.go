@@ -8,10 +8,11 @@ import (
 
 // LXD event types.
 const (
-	EventTypeLifecycle = "lifecycle"
-	EventTypeLogging   = "logging"
-	EventTypeOperation = "operation"
-	EventTypeOVN       = "ovn"
+	EventTypeLifecycle  = "lifecycle"
+	EventTypeLogging    = "logging"
+	EventTypeNetworkACL = "network-acl"
+	EventTypeOperation  = "operation"
+	EventTypeOVN        = "ovn"
 )
 
 // Event represents an event entry (over websocket)
@@ -45,7 +46,7 @@ type Event struct {
 
 // ToLogging creates log record for the event.
 func (event *Event) ToLogging() (EventLogRecord, error) {
-	if event.Type == EventTypeLogging || event.Type == EventTypeOVN {
+	if event.Type == EventTypeLogging || event.Type == EventTypeOVN || event.Type == EventTypeNetworkACL {
 		e := &EventLogging{}
 		err := json.Unmarshal(event.Metadata, &e)
 		if err != nil {
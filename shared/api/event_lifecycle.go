@@ -30,6 +30,7 @@ const (
 	EventLifecycleImageUpdated                      = "image-updated"
 	EventLifecycleInstanceBackupCreated             = "instance-backup-created"
 	EventLifecycleInstanceBackupDeleted             = "instance-backup-deleted"
+	EventLifecycleInstanceBackupFailed              = "instance-backup-failed"
 	EventLifecycleInstanceBackupRenamed             = "instance-backup-renamed"
 	EventLifecycleInstanceBackupRetrieved           = "instance-backup-retrieved"
 	EventLifecycleInstanceConsole                   = "instance-console"
@@ -66,11 +67,22 @@ const (
 	EventLifecycleNetworkACLDeleted                 = "network-acl-deleted"
 	EventLifecycleNetworkACLRenamed                 = "network-acl-renamed"
 	EventLifecycleNetworkACLUpdated                 = "network-acl-updated"
+	EventLifecycleNetworkAddressGroupCreated        = "network-address-group-created"
+	EventLifecycleNetworkAddressGroupDeleted        = "network-address-group-deleted"
+	EventLifecycleNetworkAddressGroupRenamed        = "network-address-group-renamed"
+	EventLifecycleNetworkAddressGroupUpdated        = "network-address-group-updated"
 	EventLifecycleNetworkCreated                    = "network-created"
 	EventLifecycleNetworkDeleted                    = "network-deleted"
 	EventLifecycleNetworkForwardCreated             = "network-forward-created"
 	EventLifecycleNetworkForwardDeleted             = "network-forward-deleted"
 	EventLifecycleNetworkForwardUpdated             = "network-forward-updated"
+	EventLifecycleNetworkIPAllocationCreated        = "network-ip-allocation-created"
+	EventLifecycleNetworkIPAllocationDeleted        = "network-ip-allocation-deleted"
+	EventLifecycleNetworkIPRangeCreated             = "network-ip-range-created"
+	EventLifecycleNetworkIPRangeDeleted             = "network-ip-range-deleted"
+	EventLifecycleNetworkIPRangeUpdated             = "network-ip-range-updated"
+	EventLifecycleNetworkLeaseCreated               = "network-lease-created"
+	EventLifecycleNetworkLeaseDeleted               = "network-lease-deleted"
 	EventLifecycleNetworkLoadBalancerCreated        = "network-load-balancer-created"
 	EventLifecycleNetworkLoadBalancerDeleted        = "network-load-balancer-deleted"
 	EventLifecycleNetworkLoadBalancerUpdated        = "network-load-balancer-updated"
@@ -106,6 +118,7 @@ const (
 	EventLifecycleStorageVolumeCreated              = "storage-volume-created"
 	EventLifecycleStorageVolumeBackupCreated        = "storage-volume-backup-created"
 	EventLifecycleStorageVolumeBackupDeleted        = "storage-volume-backup-deleted"
+	EventLifecycleStorageVolumeBackupFailed         = "storage-volume-backup-failed"
 	EventLifecycleStorageVolumeBackupRenamed        = "storage-volume-backup-renamed"
 	EventLifecycleStorageVolumeBackupRetrieved      = "storage-volume-backup-retrieved"
 	EventLifecycleStorageVolumeDeleted              = "storage-volume-deleted"
@@ -437,6 +437,11 @@ type InstanceSource struct {
 	//
 	// API extension: instance_import_conversion
 	ConversionOptions []string `json:"conversion_options" yaml:"conversion_options"`
+
+	// S3 object containing the backup to import (for backup)
+	//
+	// API extension: backup_s3
+	Backup *BackupS3 `json:"backup,omitempty" yaml:"backup,omitempty"`
 }
 
 // InstanceUEFIVars represents the UEFI variables of a LXD virtual machine.
@@ -35,6 +35,17 @@ type InstanceBackupsPost struct {
 	//
 	// API extension: backup_compression_algorithm
 	CompressionAlgorithm string `json:"compression_algorithm" yaml:"compression_algorithm"`
+
+	// Name of the parent backup the backup only contains the changes since
+	// Example: backup0
+	//
+	// API extension: backup_incremental
+	IncrementalFrom string `json:"incremental_from" yaml:"incremental_from"`
+
+	// S3 object to stream the backup to instead of storing it on the server
+	//
+	// API extension: backup_s3
+	Target *BackupS3 `json:"target,omitempty" yaml:"target,omitempty"`
 }
 
 // InstanceBackup represents a LXD instance backup.
@@ -66,6 +77,12 @@ type InstanceBackup struct {
 	// Whether to use a pool-optimized binary format (instead of plain tarball)
 	// Example: true
 	OptimizedStorage bool `json:"optimized_storage" yaml:"optimized_storage"`
+
+	// Name of the parent backup the backup only contains the changes since
+	// Example: backup0
+	//
+	// API extension: backup_incremental
+	IncrementalFrom string `json:"incremental_from" yaml:"incremental_from"`
 }
 
 // InstanceBackupPost represents the fields available for the renaming of a instance backup.
@@ -78,3 +95,22 @@ type InstanceBackupPost struct {
 	// Example: backup1
 	Name string `json:"name" yaml:"name"`
 }
+
+// BackupS3 represents an S3 object that a backup is streamed to or imported from.
+//
+// swagger:model
+//
+// API extension: backup_s3.
+type BackupS3 struct {
+	// URL of the object, in the path-style form of https://<host>/<bucket>/<object>
+	// Example: https://s3.example.com/backups/c1.tar.gz
+	URL string `json:"url" yaml:"url"`
+
+	// S3 access key
+	// Example: 33UgkaIBLBIxb7O1
+	AccessKey string `json:"access_key" yaml:"access_key"`
+
+	// S3 secret key
+	// Example: kDQD6AOgwHgaQI1UIJBJpPaiLgZuJbq0
+	SecretKey string `json:"secret_key" yaml:"secret_key"`
+}
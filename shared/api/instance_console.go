@@ -27,4 +27,10 @@ type InstanceConsolePost struct {
 	//
 	// API extension: console_vga_type
 	Type string `json:"type" yaml:"type"`
+
+	// Protocol of the VGA console (spice or vnc, vga type only)
+	// Example: vnc
+	//
+	// API extension: instance_console_vnc
+	Protocol string `json:"protocol" yaml:"protocol"`
 }
@@ -210,4 +210,28 @@ type InstanceStateNetworkCounters struct {
 	// Number of inbound packets dropped
 	// Example: 179
 	PacketsDroppedInbound int64 `json:"packets_dropped_inbound" yaml:"packets_dropped_inbound"`
+
+	// Number of inbound packets dropped by traffic shaping
+	// Example: 12
+	//
+	// API extension: instance_nic_shaping
+	ShapingPacketsDroppedInbound int64 `json:"shaping_packets_dropped_inbound" yaml:"shaping_packets_dropped_inbound"`
+
+	// Number of outbound packets dropped by traffic shaping
+	// Example: 37
+	//
+	// API extension: instance_nic_shaping
+	ShapingPacketsDroppedOutbound int64 `json:"shaping_packets_dropped_outbound" yaml:"shaping_packets_dropped_outbound"`
+
+	// Number of times inbound traffic exceeded its shaping rate
+	// Example: 153
+	//
+	// API extension: instance_nic_shaping
+	ShapingOverlimitsInbound int64 `json:"shaping_overlimits_inbound" yaml:"shaping_overlimits_inbound"`
+
+	// Number of times outbound traffic exceeded its shaping rate
+	// Example: 482
+	//
+	// API extension: instance_nic_shaping
+	ShapingOverlimitsOutbound int64 `json:"shaping_overlimits_outbound" yaml:"shaping_overlimits_outbound"`
 }
@@ -24,6 +24,7 @@ type NetworkACLRule struct {
 
 	// lxdmeta:generate(entities=network-acl; group=rule-properties; key=source)
 	// Sources can be specified as CIDR or IP ranges, source subject name selectors (for ingress rules), or be left empty for any.
+	// A network address group can be referenced instead with `$<group>`.
 	// ---
 	//  type: string
 	//  required: no
@@ -35,6 +36,7 @@ type NetworkACLRule struct {
 
 	// lxdmeta:generate(entities=network-acl; group=rule-properties; key=destination)
 	// Destinations can be specified as CIDR or IP ranges, destination subject name selectors (for egress rules), or be left empty for any.
+	// A network address group can be referenced instead with `$<group>`.
 	// ---
 	//  type: string
 	//  required: no
@@ -59,6 +61,7 @@ type NetworkACLRule struct {
 	// lxdmeta:generate(entities=network-acl; group=rule-properties; key=source_port)
 	// This option is valid only if the protocol is `udp` or `tcp`.
 	// Specify a comma-separated list of ports or port ranges (start-end inclusive), or leave the value empty for any.
+	// The ports of a network address group can be referenced instead with `$<group>`.
 	// ---
 	//  type: string
 	//  required: no
@@ -71,6 +74,7 @@ type NetworkACLRule struct {
 	// lxdmeta:generate(entities=network-acl; group=rule-properties; key=destination_port)
 	// This option is valid only if the protocol is `udp` or `tcp`.
 	// Specify a comma-separated list of ports or port ranges (start-end inclusive), or leave the value empty for any.
+	// The ports of a network address group can be referenced instead with `$<group>`.
 	// ---
 	//  type: string
 	//  required: no
@@ -294,3 +298,31 @@ type NetworkACLsPost struct {
 	NetworkACLPost `yaml:",inline"`
 	NetworkACLPut  `yaml:",inline"`
 }
+
+// NetworkACLRuleState represents the counters of the traffic matched by an ACL rule.
+//
+// swagger:model
+//
+// API extension: network_acl_state.
+type NetworkACLRuleState struct {
+	// Number of packets matched by the rule
+	// Example: 1024
+	Packets uint64 `json:"packets" yaml:"packets"`
+
+	// Number of bytes matched by the rule
+	// Example: 65536
+	Bytes uint64 `json:"bytes" yaml:"bytes"`
+}
+
+// NetworkACLState represents the state of an ACL.
+//
+// swagger:model
+//
+// API extension: network_acl_state.
+type NetworkACLState struct {
+	// Counters of the egress rules (in the same order as the rules)
+	Egress []NetworkACLRuleState `json:"egress" yaml:"egress"`
+
+	// Counters of the ingress rules (in the same order as the rules)
+	Ingress []NetworkACLRuleState `json:"ingress" yaml:"ingress"`
+}
@@ -0,0 +1,95 @@
+package api
+
+// NetworkAddressGroupPost used for renaming an address group.
+//
+// swagger:model
+//
+// API extension: network_address_groups.
+type NetworkAddressGroupPost struct {
+	// The new name for the address group
+	// Example: web
+	Name string `json:"name" yaml:"name"` // Name of address group.
+}
+
+// NetworkAddressGroupPut used for updating an address group.
+//
+// swagger:model
+//
+// API extension: network_address_groups.
+type NetworkAddressGroupPut struct {
+	// Description of the address group
+	// Example: Web servers
+	Description string `json:"description" yaml:"description"`
+
+	// List of IP addresses and subnets (CIDR) in the group
+	// Example: ["192.0.2.10", "198.51.100.0/24", "2001:db8::/64"]
+	Addresses []string `json:"addresses" yaml:"addresses"`
+
+	// List of ports and port ranges in the group
+	// Example: ["80", "443", "8000-8080"]
+	Ports []string `json:"ports" yaml:"ports"`
+
+	// Address group configuration map (refer to doc/howto/network_acls.md)
+	// Example: {"user.mykey": "foo"}
+	Config map[string]string `json:"config" yaml:"config"`
+}
+
+// NetworkAddressGroup used for displaying an address group.
+//
+// swagger:model
+//
+// API extension: network_address_groups.
+type NetworkAddressGroup struct {
+	// The name of the address group
+	// Example: web
+	Name string `json:"name" yaml:"name"` // Name of address group.
+
+	// Description of the address group
+	// Example: Web servers
+	Description string `json:"description" yaml:"description"`
+
+	// List of IP addresses and subnets (CIDR) in the group
+	// Example: ["192.0.2.10", "198.51.100.0/24", "2001:db8::/64"]
+	Addresses []string `json:"addresses" yaml:"addresses"`
+
+	// List of ports and port ranges in the group
+	// Example: ["80", "443", "8000-8080"]
+	Ports []string `json:"ports" yaml:"ports"`
+
+	// Address group configuration map (refer to doc/howto/network_acls.md)
+	// Example: {"user.mykey": "foo"}
+	Config map[string]string `json:"config" yaml:"config"`
+
+	// List of URLs of objects using this address group
+	// Read only: true
+	// Example: ["/1.0/network-acls/foo"]
+	UsedBy []string `json:"used_by" yaml:"used_by"` // Resources that use the address group.
+}
+
+// Writable converts a full NetworkAddressGroup struct into a NetworkAddressGroupPut struct (filters read-only fields).
+func (group *NetworkAddressGroup) Writable() NetworkAddressGroupPut {
+	return NetworkAddressGroupPut{
+		Description: group.Description,
+		Addresses:   group.Addresses,
+		Ports:       group.Ports,
+		Config:      group.Config,
+	}
+}
+
+// SetWritable sets applicable values from NetworkAddressGroupPut struct to NetworkAddressGroup struct.
+func (group *NetworkAddressGroup) SetWritable(put NetworkAddressGroupPut) {
+	group.Description = put.Description
+	group.Addresses = put.Addresses
+	group.Ports = put.Ports
+	group.Config = put.Config
+}
+
+// NetworkAddressGroupsPost used for creating an address group.
+//
+// swagger:model
+//
+// API extension: network_address_groups.
+type NetworkAddressGroupsPost struct {
+	NetworkAddressGroupPost `yaml:",inline"`
+	NetworkAddressGroupPut  `yaml:",inline"`
+}
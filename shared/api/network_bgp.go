@@ -0,0 +1,46 @@
+package api
+
+// NetworkBGPState represents the BGP state of a network on a cluster member
+//
+// swagger:model
+//
+// API extension: network_bgp_policy.
+type NetworkBGPState struct {
+	// List of BGP peers
+	Peers []NetworkBGPPeerState `json:"peers" yaml:"peers"`
+}
+
+// NetworkBGPPeerState represents the state of a BGP peer
+//
+// swagger:model
+//
+// API extension: network_bgp_policy.
+type NetworkBGPPeerState struct {
+	// Name of the peer
+	// Example: router1
+	Name string `json:"name" yaml:"name"`
+
+	// Address of the peer
+	// Example: 192.0.2.254
+	Address string `json:"address" yaml:"address"`
+
+	// AS number of the peer
+	// Example: 65000
+	ASN uint32 `json:"asn" yaml:"asn"`
+
+	// Session state
+	// Example: established
+	State string `json:"state" yaml:"state"`
+
+	// Prefixes advertised to the peer
+	// Example: ["198.51.100.0/24"]
+	AdvertisedPrefixes []string `json:"advertised_prefixes" yaml:"advertised_prefixes"`
+
+	// Prefixes received from the peer
+	// Example: ["0.0.0.0/0"]
+	ReceivedPrefixes []string `json:"received_prefixes" yaml:"received_prefixes"`
+
+	// Received prefixes installed in the host routing table
+	// Example: ["0.0.0.0/0"]
+	InstalledPrefixes []string `json:"installed_prefixes" yaml:"installed_prefixes"`
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"net"
+	"strings"
+)
+
+// NetworkIPRangePut used for updating a network IP range.
+//
+// swagger:model
+//
+// API extension: network_ipam.
+type NetworkIPRangePut struct {
+	// Description of the IP range
+	// Example: Addresses of the web servers
+	Description string `json:"description" yaml:"description"`
+
+	// First address of the range
+	// Example: 192.0.2.100
+	Start string `json:"start" yaml:"start"`
+
+	// Last address of the range
+	// Example: 192.0.2.149
+	End string `json:"end" yaml:"end"`
+
+	// Project the range is delegated to (reserved range if empty)
+	// Example: web
+	Project string `json:"project" yaml:"project"`
+}
+
+// Normalise normalises the fields of the IP range so that they are comparable with ones stored.
+func (r *NetworkIPRangePut) Normalise() {
+	r.Description = strings.TrimSpace(r.Description)
+
+	start := net.ParseIP(r.Start)
+	if start != nil {
+		r.Start = start.String() // Replace with canonical form if specified.
+	}
+
+	end := net.ParseIP(r.End)
+	if end != nil {
+		r.End = end.String() // Replace with canonical form if specified.
+	}
+}
+
+// NetworkIPRange used for displaying a network IP range.
+//
+// swagger:model
+//
+// API extension: network_ipam.
+type NetworkIPRange struct {
+	// The name of the IP range
+	// Example: web
+	Name string `json:"name" yaml:"name"`
+
+	// Description of the IP range
+	// Example: Addresses of the web servers
+	Description string `json:"description" yaml:"description"`
+
+	// First address of the range
+	// Example: 192.0.2.100
+	Start string `json:"start" yaml:"start"`
+
+	// Last address of the range
+	// Example: 192.0.2.149
+	End string `json:"end" yaml:"end"`
+
+	// Project the range is delegated to (reserved range if empty)
+	// Example: web
+	Project string `json:"project" yaml:"project"`
+}
+
+// Etag returns the values used for etag generation.
+func (r *NetworkIPRange) Etag() []any {
+	return []any{r.Name, r.Description, r.Start, r.End, r.Project}
+}
+
+// Writable converts a full NetworkIPRange struct into a NetworkIPRangePut struct (filters read-only fields).
+func (r *NetworkIPRange) Writable() NetworkIPRangePut {
+	return NetworkIPRangePut{
+		Description: r.Description,
+		Start:       r.Start,
+		End:         r.End,
+		Project:     r.Project,
+	}
+}
+
+// SetWritable sets applicable values from NetworkIPRangePut struct to NetworkIPRange struct.
+func (r *NetworkIPRange) SetWritable(put NetworkIPRangePut) {
+	r.Description = put.Description
+	r.Start = put.Start
+	r.End = put.End
+	r.Project = put.Project
+}
+
+// NetworkIPRangesPost used for creating a network IP range.
+//
+// swagger:model
+//
+// API extension: network_ipam.
+type NetworkIPRangesPost struct {
+	NetworkIPRangePut `yaml:",inline"`
+
+	// The name of the IP range
+	// Example: web
+	Name string `json:"name" yaml:"name"`
+}
+
+// NetworkIPAllocationsPost used for allocating an address in a network.
+//
+// swagger:model
+//
+// API extension: network_ipam.
+type NetworkIPAllocationsPost struct {
+	// Address to allocate (picked from the ranges delegated to the project if empty)
+	// Example: 192.0.2.100
+	Address string `json:"address" yaml:"address"`
+
+	// Family of the address to pick when no address is specified (inet or inet6)
+	// Example: inet
+	Family string `json:"family" yaml:"family"`
+
+	// Description of the allocation
+	// Example: Virtual IP of the web servers
+	Description string `json:"description" yaml:"description"`
+}
+
+// Normalise normalises the fields of the allocation so that they are comparable with ones stored.
+func (a *NetworkIPAllocationsPost) Normalise() {
+	a.Description = strings.TrimSpace(a.Description)
+
+	ip := net.ParseIP(a.Address)
+	if ip != nil {
+		a.Address = ip.String() // Replace with canonical form if specified.
+	}
+}
+
+// NetworkIPAllocation used for displaying an address allocated in a network.
+//
+// swagger:model
+//
+// API extension: network_ipam.
+type NetworkIPAllocation struct {
+	// The allocated address
+	// Example: 192.0.2.100
+	Address string `json:"address" yaml:"address"`
+
+	// Description of the allocation
+	// Example: Virtual IP of the web servers
+	Description string `json:"description" yaml:"description"`
+
+	// Project the address is allocated to
+	// Example: web
+	Project string `json:"project" yaml:"project"`
+
+	// Instance the address is allocated to (if any)
+	// Example: c1
+	Instance string `json:"instance" yaml:"instance"`
+
+	// Instance NIC the address is allocated to (if any)
+	// Example: eth0
+	Device string `json:"device" yaml:"device"`
+}
@@ -179,7 +179,7 @@ type NetworkLoadBalancerPut struct {
 	Description string `json:"description" yaml:"description"`
 
 	// lxdmeta:generate(entities=network-load-balancer; group=load-balancer-properties; key=config)
-	// The only supported keys are `user.*` custom keys.
+	// Besides `user.*` custom keys, bridge networks support the keys listed in {ref}`network-load-balancers-bridge-options`.
 	// ---
 	//  type: string set
 	//  required: no
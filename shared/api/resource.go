@@ -798,6 +798,12 @@ type ResourcesStoragePoolSpace struct {
 	// Total disk space (bytes)
 	// Example: 420100937728
 	Total uint64 `json:"total" yaml:"total"`
+
+	// Disk space allocated to the volumes on the pool (bytes)
+	// Example: 536870912000
+	//
+	// API extension: storage_pool_overcommit
+	Allocated uint64 `json:"allocated,omitempty" yaml:"allocated,omitempty"`
 }
 
 // ResourcesStoragePoolInodes represents the inodes available to a given storage pool
@@ -29,6 +29,12 @@ type StoragePoolVolumeBackup struct {
 	// Whether to use a pool-optimized binary format (instead of plain tarball)
 	// Example: true
 	OptimizedStorage bool `json:"optimized_storage" yaml:"optimized_storage"`
+
+	// Name of the parent backup the backup only contains the changes since
+	// Example: backup0
+	//
+	// API extension: backup_incremental
+	IncrementalFrom string `json:"incremental_from" yaml:"incremental_from"`
 }
 
 // StoragePoolVolumeBackupsPost represents the fields available for a new LXD volume backup
@@ -56,6 +62,17 @@ type StoragePoolVolumeBackupsPost struct {
 	// What compression algorithm to use
 	// Example: gzip
 	CompressionAlgorithm string `json:"compression_algorithm" yaml:"compression_algorithm"`
+
+	// Name of the parent backup the backup only contains the changes since
+	// Example: backup0
+	//
+	// API extension: backup_incremental
+	IncrementalFrom string `json:"incremental_from" yaml:"incremental_from"`
+
+	// S3 object to stream the backup to instead of storing it on the server
+	//
+	// API extension: backup_s3
+	Target *BackupS3 `json:"target,omitempty" yaml:"target,omitempty"`
 }
 
 // StoragePoolVolumeBackupPost represents the fields available for the renaming of a volume backup
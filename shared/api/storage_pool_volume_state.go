@@ -8,6 +8,12 @@ package api
 type StorageVolumeState struct {
 	// Volume usage
 	Usage *StorageVolumeStateUsage `json:"usage" yaml:"usage"`
+
+	// Problems found by the last verification of the volume
+	// Example: ["Backup \"backup0\" failed verification: Checksum mismatch"]
+	//
+	// API extension: storage_volume_verify
+	Problems []string `json:"problems,omitempty" yaml:"problems,omitempty"`
 }
 
 // StorageVolumeStateUsage represents the disk usage of a volume
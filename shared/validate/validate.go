@@ -215,6 +215,16 @@ func IsSize(value string) error {
 	return nil
 }
 
+// IsBitSize checks if string is valid bit rate according to units.ParseBitSizeString.
+func IsBitSize(value string) error {
+	_, err := units.ParseBitSizeString(value)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // IsDeviceID validates string is four lowercase hex characters suitable as Vendor or Device ID.
 func IsDeviceID(value string) error {
 	match, _ := regexp.MatchString(`^[0-9a-f]{4}$`, value)
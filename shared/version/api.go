@@ -417,6 +417,28 @@ var APIExtensions = []string{
 	"metrics_api_requests",
 	"projects_limits_disk_pool",
 	"ubuntu_pro_guest_attach",
+	"storage_driver_nfs",
+	"storage_volume_encryption",
+	"backup_incremental",
+	"backups_schedule",
+	"backup_s3",
+	"storage_volume_limits_io",
+	"storage_volume_verify",
+	"storage_pool_overcommit",
+	"network_zones_dns_queries",
+	"network_bridge_load_balancers_peering",
+	"network_type_wireguard",
+	"network_bgp_policy",
+	"network_dhcp_native",
+	"network_acl_state",
+	"network_address_groups",
+	"instance_nic_shaping",
+	"network_ipam",
+	"instance_console_vnc",
+	"instance_live_migration_local_volumes",
+	"instance_memory_hotplug",
+	"instance_qemu_guest_agent",
+	"instance_snapshot_consistency",
 }
 
 // APIExtensionsCount returns the number of available API extensions.
@@ -289,6 +289,7 @@ if [ "${1:-"all"}" != "cluster" ]; then
     run_test test_container_devices_nic_bridged "container devices - nic - bridged"
     run_test test_container_devices_nic_bridged_acl "container devices - nic - bridged - acl"
     run_test test_container_devices_nic_bridged_filtering "container devices - nic - bridged - filtering"
+    run_test test_container_devices_nic_bridged_shaping "container devices - nic - bridged - shaping"
     run_test test_container_devices_nic_bridged_vlan "container devices - nic - bridged - vlan"
     run_test test_container_devices_nic_physical "container devices - nic - physical"
     run_test test_container_devices_nic_macvlan "container devices - nic - macvlan"
@@ -325,6 +326,7 @@ if [ "${1:-"all"}" != "cluster" ]; then
     run_test test_snap_schedule "snapshot scheduling"
     run_test test_snap_volume_db_recovery "snapshot volume database record recovery"
     run_test test_snap_fail "snapshot creation failure"
+    run_test test_snap_consistency "snapshot consistency"
     run_test test_config_profiles "profiles and configuration"
     run_test test_config_edit "container configuration edit"
     run_test test_property "container property"
@@ -335,7 +337,14 @@ if [ "${1:-"all"}" != "cluster" ]; then
     run_test test_filemanip "file manipulations"
     run_test test_network "network management"
     run_test test_network_acl "network ACL management"
+    run_test test_network_address_group "network address groups"
+    run_test test_network_firewall_drift "network firewall drift detection"
     run_test test_network_forward "network address forwards"
+    run_test test_network_ipam "network IPAM"
+    run_test test_network_load_balancer "network load balancers and peers"
+    run_test test_network_wireguard "network wireguard"
+    run_test test_network_bgp "network BGP policies"
+    run_test test_network_dhcp_native "network native DHCP server"
     run_test test_network_zone "network DNS zones"
     run_test test_idmap "id mapping"
     run_test test_template "file templating"
@@ -360,10 +369,13 @@ if [ "${1:-"all"}" != "cluster" ]; then
     run_test test_storage_driver_ceph "ceph storage driver"
     run_test test_storage_driver_cephfs "cephfs storage driver"
     run_test test_storage_driver_dir "dir storage driver"
+    run_test test_storage_driver_nfs "nfs storage driver"
     run_test test_storage_driver_zfs "zfs storage driver"
     run_test test_storage_buckets "storage buckets"
     run_test test_storage_volume_import "storage volume import"
     run_test test_storage_volume_initial_config "storage volume initial configuration"
+    run_test test_storage_volume_encryption "storage volume encryption"
+    run_test test_storage_pool_overcommit "storage pool overcommit"
     run_test test_resources "resources"
     run_test test_kernel_limits "kernel limits"
     run_test test_console "console"
@@ -375,9 +387,13 @@ if [ "${1:-"all"}" != "cluster" ]; then
     run_test test_backup_volume_export "backup volume export"
     run_test test_backup_export_import_instance_only "backup export and import instance only"
     run_test test_backup_volume_rename_delete "backup volume rename and delete"
+    run_test test_backup_volume_verify "backup volume verification"
     run_test test_backup_different_instance_uuid "backup instance and check instance UUIDs"
     run_test test_backup_volume_expiry "backup volume expiry"
     run_test test_backup_export_import_recover "backup export, import, and recovery"
+    run_test test_backup_incremental "backup incremental export and import"
+    run_test test_backup_schedule "backup scheduling"
+    run_test test_backup_s3 "backup export and import with S3"
     run_test test_container_local_cross_pool_handling "container local cross pool handling"
     run_test test_incremental_copy "incremental container copy"
     run_test test_profiles_project_default "profiles in default project"
@@ -954,6 +954,44 @@ test_backup_volume_rename_delete() {
   ! stat "${LXD_DIR}"/backups/custom/"${pool}"/default_vol2 || false
 }
 
+test_backup_volume_verify() {
+  ensure_has_localhost_remote "${LXD_ADDR}"
+
+  pool="lxdtest-$(basename "${LXD_DIR}")"
+
+  # Create test volume and backup.
+  lxc storage volume create "${pool}" vol1
+  lxc query -X POST --wait -d '{\"name\":\"foo\"}' /1.0/storage-pools/"${pool}"/volumes/custom/vol1/backups
+
+  # The checksum of the backup is recorded next to it.
+  stat "${LXD_DIR}"/backups/custom/"${pool}"/default_vol1/foo.sha256
+
+  # A healthy volume passes the check.
+  lxc storage volume check "${pool}" vol1 | grep -F "No problems found"
+
+  # Drivers that can't check volumes report them as unverified rather than healthy.
+  if [ "$(storage_backend "$LXD_DIR")" = "dir" ]; then
+    lxc storage volume check "${pool}" vol1 | grep -F "Not checked:"
+    [ "$(lxc query -X POST --wait /1.0/storage-pools/"${pool}"/volumes/custom/vol1/verify | jq '.metadata.unverified | length')" = "1" ]
+  fi
+  [ "$(lxc query /1.0/storage-pools/"${pool}"/volumes/custom/vol1/state | jq '.problems | length')" = "0" ]
+
+  # Corrupt the backup and check that the problem is reported.
+  echo "corrupted" >> "${LXD_DIR}"/backups/custom/"${pool}"/default_vol1/foo
+  ! lxc storage volume check "${pool}" vol1 || false
+  lxc storage volume info "${pool}" vol1 | grep -F 'Backup "foo" failed verification'
+  lxc warning list --format csv | grep -F "Storage volume integrity problems found"
+
+  # Removing the corrupted backup resolves the warning on the next check.
+  lxc query -X DELETE --wait /1.0/storage-pools/"${pool}"/volumes/custom/vol1/backups/foo
+  ! stat "${LXD_DIR}"/backups/custom/"${pool}"/default_vol1/foo.sha256 || false
+  lxc storage volume check "${pool}" vol1
+  ! lxc storage volume info "${pool}" vol1 | grep -F "Problems:" || false
+
+  lxc warning delete --all
+  lxc storage volume delete "${pool}" vol1
+}
+
 test_backup_different_instance_uuid() {
   ensure_import_testimage
   ensure_has_localhost_remote "${LXD_ADDR}"
@@ -1064,3 +1102,198 @@ test_backup_export_import_instance_only() {
   rm "${LXD_DIR}/c1.tar.gz"
   lxc delete -f c1
 }
+
+test_backup_incremental() {
+  poolName=$(lxc profile device get default root pool)
+
+  ensure_import_testimage
+  ensure_has_localhost_remote "${LXD_ADDR}"
+
+  # Create an instance with a snapshot and keep a backup of it on the server.
+  lxc init testimage c1
+  lxc snapshot c1 snap0
+  lxc query -X POST --wait -d '{\"name\":\"backup0\"}' /1.0/instances/c1/backups
+  my_curl -f "https://${LXD_ADDR}/1.0/instances/c1/backups/backup0/export" > "${LXD_DIR}/c1-full.tar.gz"
+
+  # Change the instance, create a new snapshot and export the changes since the parent backup.
+  echo "incremental" > "${LXD_DIR}/incremental.txt"
+  lxc file push "${LXD_DIR}/incremental.txt" c1/root/incremental.txt
+  lxc file delete c1/bin/sh
+  lxc snapshot c1 snap1
+  lxc export c1 "${LXD_DIR}/c1-incremental.tar.gz" --incremental-from=backup0
+
+  # Check that the parent backup must exist.
+  ! lxc export c1 "${LXD_DIR}/c1-invalid.tar.gz" --incremental-from=backup1 || false
+
+  # Check that the parent backup must contain a snapshot.
+  lxc query -X POST --wait -d '{\"name\":\"backup1\",\"instance_only\":true}' /1.0/instances/c1/backups
+  ! lxc export c1 "${LXD_DIR}/c1-invalid.tar.gz" --incremental-from=backup1 || false
+  lxc delete -f c1
+
+  # Check that an incremental backup can't be imported without the instance.
+  ! lxc import "${LXD_DIR}/c1-incremental.tar.gz" || false
+
+  # Check that an incremental backup can't be applied to an instance with an unrelated snapshot of the same name.
+  lxc init testimage c1
+  lxc snapshot c1 snap0
+  ! lxc import "${LXD_DIR}/c1-incremental.tar.gz" || false
+  lxc delete -f c1
+
+  # Import the backup chain.
+  lxc import "${LXD_DIR}/c1-full.tar.gz"
+  lxc import "${LXD_DIR}/c1-incremental.tar.gz"
+
+  [ "$(lxc query "/1.0/instances/c1/snapshots" | jq "length")" = "2" ]
+  [ "$(lxc file pull c1/root/incremental.txt -)" = "incremental" ]
+  ! lxc file pull c1/bin/sh - || false
+
+  # Check that the incremental backup can only be applied on top of its parent backup.
+  ! lxc import "${LXD_DIR}/c1-incremental.tar.gz" || false
+
+  lxc delete -f c1
+  rm "${LXD_DIR}/c1-full.tar.gz" "${LXD_DIR}/c1-incremental.tar.gz"
+
+  # Create a custom volume with a snapshot and keep a backup of it on the server.
+  lxc storage volume create "${poolName}" vol1
+  lxc storage volume snapshot "${poolName}" vol1 snap0
+  lxc query -X POST --wait -d '{\"name\":\"backup0\"}' "/1.0/storage-pools/${poolName}/volumes/custom/vol1/backups"
+  my_curl -f "https://${LXD_ADDR}/1.0/storage-pools/${poolName}/volumes/custom/vol1/backups/backup0/export" > "${LXD_DIR}/vol1-full.tar.gz"
+
+  # Change the volume, create a new snapshot and export the changes since the parent backup.
+  lxc init testimage c1 -s "${poolName}"
+  lxc storage volume attach "${poolName}" vol1 c1 /mnt
+  lxc file push "${LXD_DIR}/incremental.txt" c1/mnt/incremental.txt
+  lxc storage volume detach "${poolName}" vol1 c1
+  lxc storage volume snapshot "${poolName}" vol1 snap1
+  lxc storage volume export "${poolName}" vol1 "${LXD_DIR}/vol1-incremental.tar.gz" --incremental-from=backup0
+  lxc storage volume delete "${poolName}" vol1
+
+  # Import the backup chain.
+  lxc storage volume import "${poolName}" "${LXD_DIR}/vol1-full.tar.gz"
+  lxc storage volume import "${poolName}" "${LXD_DIR}/vol1-incremental.tar.gz"
+
+  [ "$(lxc query "/1.0/storage-pools/${poolName}/volumes/custom/vol1/snapshots" | jq "length")" = "2" ]
+  lxc storage volume attach "${poolName}" vol1 c1 /mnt
+  [ "$(lxc file pull c1/mnt/incremental.txt -)" = "incremental" ]
+
+  lxc delete -f c1
+  lxc storage volume delete "${poolName}" vol1
+  rm "${LXD_DIR}/vol1-full.tar.gz" "${LXD_DIR}/vol1-incremental.tar.gz" "${LXD_DIR}/incremental.txt"
+}
+
+test_backup_schedule() {
+  ensure_import_testimage
+
+  poolName=$(lxc profile device get default root pool)
+
+  # Check backup target validation.
+  ! lxc launch testimage c1 -c backups.target=invalid || false
+  ! lxc launch testimage c1 -c backups.target=foo:"${poolName}"/target || false
+  ! lxc launch testimage c1 -c backups.target=volume:"${poolName}" || false
+  lxc launch testimage c1
+  lxc storage volume create "${poolName}" target
+
+  lxc config set c1 backups.schedule "* * * * *"
+  lxc config set c1 backups.expiry 1d
+  lxc config set c1 backups.target volume:"${poolName}"/target
+
+  lxc storage volume create "${poolName}" vol1
+
+  # Check that scheduled backups can be disabled with @never, like for instances.
+  lxc config set c1 backups.schedule @never
+  lxc storage volume set "${poolName}" vol1 backups.schedule @never
+  lxc config set c1 backups.schedule "* * * * *"
+
+  lxc storage volume set "${poolName}" vol1 backups.schedule "* * * * *"
+  lxc storage volume set "${poolName}" vol1 backups.target volume:"${poolName}"/target
+  ! lxc storage volume set "${poolName}" vol1 backups.target invalid || false
+
+  # Wait for the scheduled backups to be written to the target volume.
+  lxc launch testimage reader -s "${poolName}"
+  lxc storage volume attach "${poolName}" target reader /mnt
+
+  found=0
+  for _ in $(seq 90); do
+    if lxc exec reader -- ls /mnt/instances/default_c1/ | grep -q '^scheduled-.*\.backup$' && lxc exec reader -- ls /mnt/custom/"${poolName}"/default_vol1/ | grep -q '^scheduled-.*\.backup$'; then
+      found=1
+      break
+    fi
+
+    sleep 1
+  done
+
+  [ "${found}" = "1" ]
+
+  # Check that no local copies are kept when a target is set.
+  [ "$(lxc query /1.0/instances/c1/backups | jq '.[]' | wc -l)" -eq 0 ]
+  [ "$(lxc query /1.0/storage-pools/"${poolName}"/volumes/custom/vol1/backups | jq '.[]' | wc -l)" -eq 0 ]
+
+  # Cleanup.
+  lxc delete -f reader c1
+  lxc storage volume delete "${poolName}" vol1
+  lxc storage volume delete "${poolName}" target
+}
+
+test_backup_s3() {
+  local lxd_backend
+  lxd_backend=$(storage_backend "$LXD_DIR")
+
+  if [ "$lxd_backend" = "ceph" ]; then
+    export TEST_UNMET_REQUIREMENT="S3 backups are tested against local storage buckets"
+    return
+  elif ! command -v minio ; then
+    export TEST_UNMET_REQUIREMENT="minio command not found"
+    return
+  fi
+
+  ensure_import_testimage
+
+  poolName=$(lxc profile device get default root pool)
+  bucketPool="s3backups"
+
+  create_object_storage_pool "${bucketPool}"
+  s3Endpoint="https://$(lxc config get core.storage_buckets_address)"
+
+  creds=$(lxc storage bucket create "${bucketPool}" backups)
+  accessKey=$(echo "${creds}" | awk '{ if ($2 == "access" && $3 == "key:") {print $4}}')
+  secretKey=$(echo "${creds}" | awk '{ if ($2 == "secret" && $3 == "key:") {print $4}}')
+
+  lxc launch testimage c1
+  lxc exec c1 -- touch /root/foo
+  lxc snapshot c1
+
+  # Check that invalid targets are rejected.
+  ! lxc query -X POST -d "{\"target\": {\"url\": \"${s3Endpoint}/backups\", \"access_key\": \"${accessKey}\", \"secret_key\": \"${secretKey}\"}}" /1.0/instances/c1/backups || false
+  ! lxc query -X POST -d "{\"target\": {\"url\": \"${s3Endpoint}/backups/c1.tar.gz\"}}" /1.0/instances/c1/backups || false
+
+  # Export the instance to the bucket, no backup is kept on the server.
+  lxc query -X POST -d "{\"target\": {\"url\": \"${s3Endpoint}/backups/c1.tar.gz\", \"access_key\": \"${accessKey}\", \"secret_key\": \"${secretKey}\"}}" /1.0/instances/c1/backups
+  [ "$(lxc query /1.0/instances/c1/backups | jq '.[]' | wc -l)" -eq 0 ]
+  s3cmdrun "${lxd_backend}" "${accessKey}" "${secretKey}" ls s3://backups/ | grep -F c1.tar.gz
+
+  # Import the instance from the bucket.
+  lxc query -X POST -d "{\"name\": \"c2\", \"source\": {\"type\": \"backup\", \"backup\": {\"url\": \"${s3Endpoint}/backups/c1.tar.gz\", \"access_key\": \"${accessKey}\", \"secret_key\": \"${secretKey}\"}}}" /1.0/instances
+  lxc info c2 | grep -F snap0
+  lxc start c2
+  lxc exec c2 -- test -f /root/foo
+
+  # Check that endpoints on loopback or link-local addresses other than the storage buckets endpoint are rejected.
+  lxc query -X POST -d "{\"name\": \"c3\", \"source\": {\"type\": \"backup\", \"backup\": {\"url\": \"http://127.0.0.1:8443/backups/c1.tar.gz\", \"access_key\": \"${accessKey}\", \"secret_key\": \"${secretKey}\"}}}" /1.0/instances 2>&1 | grep -F "isn't allowed"
+  lxc query -X POST -d "{\"name\": \"c3\", \"source\": {\"type\": \"backup\", \"backup\": {\"url\": \"http://169.254.169.254/backups/c1.tar.gz\", \"access_key\": \"${accessKey}\", \"secret_key\": \"${secretKey}\"}}}" /1.0/instances 2>&1 | grep -F "isn't allowed"
+
+  # Check that importing a missing object fails.
+  ! lxc query -X POST -d "{\"name\": \"c3\", \"source\": {\"type\": \"backup\", \"backup\": {\"url\": \"${s3Endpoint}/backups/missing.tar.gz\", \"access_key\": \"${accessKey}\", \"secret_key\": \"${secretKey}\"}}}" /1.0/instances || false
+
+  # Export a custom volume to the bucket.
+  lxc storage volume create "${poolName}" vol1
+  lxc query -X POST -d "{\"target\": {\"url\": \"${s3Endpoint}/backups/vol1.tar.gz\", \"access_key\": \"${accessKey}\", \"secret_key\": \"${secretKey}\"}}" /1.0/storage-pools/"${poolName}"/volumes/custom/vol1/backups
+  [ "$(lxc query /1.0/storage-pools/"${poolName}"/volumes/custom/vol1/backups | jq '.[]' | wc -l)" -eq 0 ]
+  s3cmdrun "${lxd_backend}" "${accessKey}" "${secretKey}" ls s3://backups/ | grep -F vol1.tar.gz
+
+  # Cleanup.
+  lxc delete -f c1 c2
+  lxc storage volume delete "${poolName}" vol1
+  s3cmdrun "${lxd_backend}" "${accessKey}" "${secretKey}" del s3://backups/c1.tar.gz s3://backups/vol1.tar.gz
+  lxc storage bucket delete "${bucketPool}" backups
+  delete_object_storage_pool "${bucketPool}"
+}
@@ -343,6 +343,16 @@ test_basic_usage() {
 
     lxc config unset autostart snapshots.schedule --force-local
 
+    # Check for scheduled instance backups
+    lxc config set autostart backups.schedule "* * * * *" --force-local
+    shutdown_lxd "${LXD_DIR}"
+    lxd activateifneeded --debug 2>&1 | grep -qF "Daemon has scheduled instance backups, activating..."
+
+    # shellcheck disable=SC2031
+    respawn_lxd "${LXD_DIR}" true
+
+    lxc config unset autostart backups.schedule --force-local
+
     # Check for scheduled volume snapshots
     storage_pool="lxdtest-$(basename "${LXD_DIR}")"
 
@@ -96,11 +96,11 @@ test_container_devices_nic_bridged() {
 
   # Check that limits.priority was correctly configured in the firewall.
   if [ "$firewallDriver" = "xtables" ]; then
-    iptables -t mangle -S | grep -c "${ctName} (${vethHostName}) netprio" | grep 1
-    iptables -t mangle -S | grep "${ctName} (${vethHostName}) netprio" | grep "0000:0005"
+    iptables -t mangle -S | grep -c "${ctName} (eth0) netprio" | grep 1
+    iptables -t mangle -S | grep "${ctName} (eth0) netprio" | grep "0000:0005"
   else
-    nft -nn list chain netdev lxd "egress.netprio.${ctName}.${vethHostName}" | grep -c "meta priority set" | grep 1
-    nft -nn list chain netdev lxd "egress.netprio.${ctName}.${vethHostName}" | grep "meta priority set 0:5"
+    nft -nn list chain netdev lxd "egress.netprio.${ctName}.eth0" | grep -c "meta priority set" | grep 1
+    nft -nn list chain netdev lxd "egress.netprio.${ctName}.eth0" | grep "meta priority set 0:5"
   fi
 
   # Check profile custom MTU is applied in container on boot.
@@ -187,11 +187,11 @@ test_container_devices_nic_bridged() {
 
   # Check that limits.priority was correctly configured in the firewall.
   if [ "$firewallDriver" = "xtables" ]; then
-    iptables -t mangle -S | grep -c "${ctName} (${vethHostName}) netprio" | grep 1
-    iptables -t mangle -S | grep "${ctName} (${vethHostName}) netprio" | grep "0000:0006"
+    iptables -t mangle -S | grep -c "${ctName} (eth0) netprio" | grep 1
+    iptables -t mangle -S | grep "${ctName} (eth0) netprio" | grep "0000:0006"
   else
-    nft -nn list chain netdev lxd "egress.netprio.${ctName}.${vethHostName}" | grep -c "meta priority set" | grep 1
-    nft -nn list chain netdev lxd "egress.netprio.${ctName}.${vethHostName}" | grep "meta priority set 0:6"
+    nft -nn list chain netdev lxd "egress.netprio.${ctName}.eth0" | grep -c "meta priority set" | grep 1
+    nft -nn list chain netdev lxd "egress.netprio.${ctName}.eth0" | grep "meta priority set 0:6"
   fi
 
   # Check custom MTU is applied on hot-plug.
@@ -247,11 +247,11 @@ test_container_devices_nic_bridged() {
 
   # Check that limits.priority was correctly configured in the firewall.
   if [ "$firewallDriver" = "xtables" ]; then
-    iptables -t mangle -S | grep -c "${ctName} (${vethHostName}) netprio" | grep 1
-    iptables -t mangle -S | grep "${ctName} (${vethHostName}) netprio" | grep "0000:0005"
+    iptables -t mangle -S | grep -c "${ctName} (eth0) netprio" | grep 1
+    iptables -t mangle -S | grep "${ctName} (eth0) netprio" | grep "0000:0005"
   else
-    nft -nn list chain netdev lxd "egress.netprio.${ctName}.${vethHostName}" | grep -c "meta priority set" | grep 1
-    nft -nn list chain netdev lxd "egress.netprio.${ctName}.${vethHostName}" | grep "meta priority set 0:5"
+    nft -nn list chain netdev lxd "egress.netprio.${ctName}.eth0" | grep -c "meta priority set" | grep 1
+    nft -nn list chain netdev lxd "egress.netprio.${ctName}.eth0" | grep "meta priority set 0:5"
   fi
 
   # Check profile custom MTU is applied on hot-removal.
@@ -339,11 +339,11 @@ test_container_devices_nic_bridged() {
 
   # Check that limits.priority was correctly configured in the firewall.
   if [ "$firewallDriver" = "xtables" ]; then
-    iptables -t mangle -S | grep -c "${ctName} (${vethHostName}) netprio" | grep 1
-    iptables -t mangle -S | grep "${ctName} (${vethHostName}) netprio" | grep "0000:0006"
+    iptables -t mangle -S | grep -c "${ctName} (eth0) netprio" | grep 1
+    iptables -t mangle -S | grep "${ctName} (eth0) netprio" | grep "0000:0006"
   else
-    nft -nn list chain netdev lxd "egress.netprio.${ctName}.${vethHostName}" | grep -c "meta priority set" | grep 1
-    nft -nn list chain netdev lxd "egress.netprio.${ctName}.${vethHostName}" | grep "meta priority set 0:6"
+    nft -nn list chain netdev lxd "egress.netprio.${ctName}.eth0" | grep -c "meta priority set" | grep 1
+    nft -nn list chain netdev lxd "egress.netprio.${ctName}.eth0" | grep "meta priority set 0:6"
   fi
 
   # Check custom MTU is applied update.
@@ -182,6 +182,27 @@ test_container_devices_nic_bridged_acl() {
   lxc exec "${ctPrefix}A" --disable-stdin -- nc -w2 192.0.2.1 8080
   lxc exec "${ctPrefix}A" --disable-stdin -- nc -w2 2001:db8::1 8080
 
+  # Check the rules count the traffic they matched.
+  lxc query "/1.0/network-acls/${brName}A/state" | jq -e '.ingress[0].packets > 0'
+  lxc query "/1.0/network-acls/${brName}B/state" | jq -e '.egress[0].packets > 0 and .egress[0].bytes > 0'
+  lxc query /1.0/metrics | grep -F "lxd_network_acl_rule_packets_total" | grep -F "name=\"${brName}B\"" | grep -F "direction=\"egress\""
+
+  # Check the logged rules send network ACL events.
+  lxc monitor --type=network-acl > "${TEST_DIR}/network_acl.log" &
+  monitorPID=$!
+  sleep 1
+
+  lxc network acl rule remove "${brName}A" ingress destination=192.0.2.2/32
+  lxc network acl rule add "${brName}A" ingress action=allow destination=192.0.2.2/32 protocol=icmp4 icmp_type=8 state=logged
+  ping -c1 -4 192.0.2.2
+  sleep 1
+
+  kill -9 "${monitorPID}" || true
+  grep -F "acl: ${brName}A" "${TEST_DIR}/network_acl.log"
+  grep -F "network: ${brName}" "${TEST_DIR}/network_acl.log"
+  grep -F "destination: 192.0.2.2" "${TEST_DIR}/network_acl.log"
+  rm "${TEST_DIR}/network_acl.log"
+
   # Check can't delete ACL that is in use.
   ! lxc network acl delete "${brName}A" || false
 
@@ -0,0 +1,105 @@
+test_container_devices_nic_bridged_shaping() {
+  ensure_import_testimage
+  ensure_has_localhost_remote "${LXD_ADDR}"
+
+  firewallDriver=$(lxc info | awk -F ":" '/firewall:/{gsub(/ /, "", $0); print $2}')
+
+  if [ "$firewallDriver" != "xtables" ] && [ "$firewallDriver" != "nftables" ]; then
+    echo "Unrecognised firewall driver: ${firewallDriver}"
+    false
+  fi
+
+  vethHostName="veth$$"
+  ctName="nt$$"
+  brName="lxdt$$"
+  projectName="shp$$"
+
+  lxc network create "${brName}" ipv4.address=192.0.2.1/24 ipv6.address=none
+
+  # Record how many nics we started with.
+  startNicCount=$(find /sys/class/net | wc -l)
+
+  # Check the project limits are validated.
+  lxc project create "${projectName}" -c features.images=false -c features.profiles=false
+  ! lxc project set "${projectName}" limits.network.ingress foo || false
+  lxc project set "${projectName}" limits.network.ingress 10Mbit
+  lxc project set "${projectName}" limits.network.egress 20Mbit
+
+  # Check the guaranteed bandwidth can't exceed the NIC limit.
+  lxc init testimage "${ctName}" --project "${projectName}" -s "lxdtest-$(basename "${LXD_DIR}")"
+  ! lxc config device add "${ctName}" eth0 nic network="${brName}" limits.ingress=1Mbit limits.ingress.guaranteed=2Mbit --project "${projectName}" || false
+  ! lxc config device add "${ctName}" eth0 nic network="${brName}" limits.dscp=64 --project "${projectName}" || false
+  lxc config device add "${ctName}" eth0 nic \
+    network="${brName}" \
+    host_name="${vethHostName}" \
+    limits.ingress=5Mbit \
+    limits.ingress.guaranteed=1Mbit \
+    --project "${projectName}"
+
+  if [ "$firewallDriver" = "nftables" ]; then
+    lxc config device set "${ctName}" eth0 limits.dscp 10 --project "${projectName}"
+  else
+    # Marking the traffic of bridged NICs isn't supported by xtables.
+    lxc config device set "${ctName}" eth0 limits.dscp 10 --project "${projectName}"
+    ! lxc start "${ctName}" --project "${projectName}" || false
+    lxc config device unset "${ctName}" eth0 limits.dscp --project "${projectName}"
+  fi
+
+  lxc start "${ctName}" --project "${projectName}"
+
+  # Check the traffic of the NIC is redirected to the project's shaping devices.
+  tc qdisc show dev "${vethHostName}" | grep clsact
+  ingressIFB=$(ip -o link show type ifb | awk -F ': ' '{print $2}' | grep '^lxdshi')
+  egressIFB=$(ip -o link show type ifb | awk -F ': ' '{print $2}' | grep '^lxdshe')
+  tc filter show dev "${vethHostName}" egress | grep "to device ${ingressIFB}"
+  tc filter show dev "${vethHostName}" ingress | grep "to device ${egressIFB}"
+
+  # Check the project limits and the NIC guaranteed rates and ceilings are applied.
+  tc class show dev "${ingressIFB}" | grep "rate 10Mbit ceil 10Mbit"
+  tc class show dev "${ingressIFB}" | grep "rate 1Mbit ceil 5Mbit"
+  tc class show dev "${egressIFB}" | grep "rate 20Mbit ceil 20Mbit"
+  tc class show dev "${egressIFB}" | grep "rate 8Kbit ceil 20Mbit"
+
+  # Check the DSCP marking was configured in the firewall.
+  if [ "$firewallDriver" = "nftables" ]; then
+    nft -nn list chain netdev lxd "ingress.netprio.${projectName}_${ctName}.eth0" | grep -c "dscp set" | grep 2
+  fi
+
+  # Check the guaranteed rates can be updated while running.
+  lxc config device set "${ctName}" eth0 limits.egress.guaranteed 2Mbit --project "${projectName}"
+  tc class show dev "${egressIFB}" | grep "rate 2Mbit ceil 20Mbit"
+
+  # Check the shaping counters are reported in the instance state.
+  lxc query "/1.0/instances/${ctName}/state?project=${projectName}" | jq --exit-status '.network.eth0.counters.shaping_packets_dropped_inbound >= 0'
+  lxc query "/1.0/instances/${ctName}/state?project=${projectName}" | jq --exit-status '.network.eth0.counters.shaping_overlimits_outbound >= 0'
+
+  # Check changing the project limits updates the running instances.
+  lxc project set "${projectName}" limits.network.ingress 15Mbit
+  tc class show dev "${ingressIFB}" | grep "rate 15Mbit ceil 15Mbit"
+  tc class show dev "${ingressIFB}" | grep "rate 1Mbit ceil 5Mbit"
+
+  # Check the NIC falls back to per-NIC limits without project limits.
+  lxc project unset "${projectName}" limits.network.ingress
+  ! ip link show "${ingressIFB}" || false
+  ! tc filter show dev "${vethHostName}" egress | grep "to device" || false
+  tc class show dev "${vethHostName}" | grep "rate 5Mbit"
+  tc filter show dev "${vethHostName}" ingress | grep "to device ${egressIFB}"
+  lxc project unset "${projectName}" limits.network.egress
+  ! tc qdisc show dev "${vethHostName}" | grep clsact || false
+  tc class show dev "${vethHostName}" | grep "rate 5Mbit"
+
+  # Check the shaping devices are removed once unused.
+  ! ip -o link show type ifb | grep lxdsh || false
+
+  lxc delete -f "${ctName}" --project "${projectName}"
+
+  # Check we haven't left any NICS lying around.
+  endNicCount=$(find /sys/class/net | wc -l)
+  if [ "$startNicCount" != "$endNicCount" ]; then
+    echo "leftover NICS detected"
+    false
+  fi
+
+  lxc project delete "${projectName}"
+  lxc network delete "${brName}"
+}
@@ -0,0 +1,106 @@
+test_network_address_group() {
+  ensure_has_localhost_remote "${LXD_ADDR}"
+
+  firewallDriver=$(lxc info | awk -F ":" '/firewall:/{gsub(/ /, "", $0); print $2}')
+
+  if [ "$firewallDriver" != "xtables" ] && [ "$firewallDriver" != "nftables" ]; then
+    echo "Unrecognised firewall driver: ${firewallDriver}"
+    false
+  fi
+
+  brName="lxdt$$"
+  groupName="web$$"
+  aclName="${brName}A"
+
+  # Check basic address group creation, validation and listing.
+  ! lxc network address-group create 192.168.1.1 || false # Don't allow non-hostname compatible names.
+  ! lxc network address-group create "${groupName}" --address 192.0.2.1-192.0.2.10 || false # Ranges aren't allowed.
+  ! lxc network address-group create "${groupName}" --address 192.0.2.1/24 || false # Host bits aren't allowed.
+  ! lxc network address-group create "${groupName}" --port 80,80 || false # Duplicates aren't allowed.
+  ! lxc network address-group create "${groupName}" foo=bar || false # Only user keys are allowed.
+  lxc network address-group create "${groupName}" --address 192.0.2.10,198.51.100.0/24,2001:db8::/64 --port 80,8000-8080 user.mykey=foo
+  lxc network address-group ls | grep "${groupName}"
+  lxc network address-group show "${groupName}" | grep "198.51.100.0/24"
+  lxc network address-group show "${groupName}" | grep "8000-8080"
+  [ "$(lxc network address-group get "${groupName}" user.mykey)" = "foo" ]
+
+  # Check adding and removing addresses and ports.
+  lxc network address-group add "${groupName}" 192.0.2.11
+  ! lxc network address-group add "${groupName}" 192.0.2.11 || false
+  lxc network address-group add "${groupName}" --port 443
+  lxc network address-group show "${groupName}" | grep "192.0.2.11"
+  lxc network address-group show "${groupName}" | grep '"443"'
+  lxc network address-group remove "${groupName}" 192.0.2.11
+  ! lxc network address-group show "${groupName}" | grep "192.0.2.11" || false
+
+  # Address group Patch. Check that the addresses and ports are kept.
+  lxc query -X PATCH -d "{\\\"config\\\": {\\\"user.myotherkey\\\": \\\"bah\\\"}}" "/1.0/network-address-groups/${groupName}"
+  lxc network address-group show "${groupName}" | grep "user.mykey: foo"
+  lxc network address-group show "${groupName}" | grep "user.myotherkey: bah"
+  lxc network address-group show "${groupName}" | grep "192.0.2.10"
+
+  # Check ACL rules referencing the address group.
+  lxc network acl create "${aclName}"
+  ! lxc network acl rule add "${aclName}" ingress action=allow source="\$missing" || false
+  ! lxc network acl rule add "${aclName}" ingress action=allow source="\$${groupName},192.0.2.1" || false
+  lxc network acl rule add "${aclName}" ingress action=allow source="\$${groupName}" protocol=tcp destination_port="\$${groupName}"
+  lxc network address-group show "${groupName}" | grep "/1.0/network-acls/${aclName}"
+
+  # Address groups in use can't be renamed or deleted.
+  ! lxc network address-group rename "${groupName}" "${groupName}2" || false
+  ! lxc network address-group delete "${groupName}" || false
+
+  # Check the address group is applied to the firewall of a bridge network.
+  lxc network create "${brName}" \
+        ipv4.address=192.0.2.1/24 \
+        ipv6.address=2001:db8:1::1/64 \
+        security.acls="${aclName}"
+
+  if [ "$firewallDriver" = "xtables" ]; then
+      ipset4=$(ipset list -n | grep "^lxd_${brName}_.*_4$")
+      ipset6=$(ipset list -n | grep "^lxd_${brName}_.*_6$")
+      ipset list "${ipset4}" | grep "198.51.100.0/24"
+      ipset list "${ipset6}" | grep "2001:db8::/64"
+      ipset list "$(ipset list -n | grep "^lxd_${brName}_.*_p$")" | grep "8000-8080"
+      iptables -S "lxd_acl_${brName}" | grep -- "--match-set ${ipset4} src"
+      ip6tables -S "lxd_acl_${brName}" | grep -- "--match-set ${ipset6} src"
+  else
+      nft -nn list set inet lxd "aclset.${brName}.${groupName}.ipv4" | grep "198.51.100.0/24"
+      nft -nn list set inet lxd "aclset.${brName}.${groupName}.ipv6" | grep "2001:db8::/64"
+      nft -nn list set inet lxd "aclset.${brName}.${groupName}.ports" | grep "8000-8080"
+      nft -nn list chain inet lxd "acl.${brName}" | grep "@aclset.${brName}.${groupName}.ipv4"
+  fi
+
+  # Check updating the address group updates the firewall.
+  lxc network address-group add "${groupName}" 203.0.113.0/24
+
+  if [ "$firewallDriver" = "xtables" ]; then
+      ipset list "${ipset4}" | grep "203.0.113.0/24"
+  else
+      nft -nn list set inet lxd "aclset.${brName}.${groupName}.ipv4" | grep "203.0.113.0/24"
+  fi
+
+  # Check the address sets are cleaned up once the ACL is removed from the network.
+  lxc network unset "${brName}" security.acls
+
+  if [ "$firewallDriver" = "xtables" ]; then
+      ! ipset list -n | grep "^lxd_${brName}_" || false
+  else
+      ! nft -nn list set inet lxd "aclset.${brName}.${groupName}.ipv4" || false
+  fi
+
+  # Check renaming and deleting the address group once unused.
+  lxc network acl delete "${aclName}"
+  lxc network address-group rename "${groupName}" "${groupName}2"
+  lxc network address-group delete "${groupName}2"
+  ! lxc network address-group ls | grep "${groupName}" || false
+
+  # Check project namespacing support.
+  lxc project create testproj -c features.networks=true
+  lxc network address-group create "${groupName}" --project testproj
+  ! lxc network address-group ls | grep "${groupName}" || false
+  lxc network address-group delete "${groupName}" --project testproj
+  lxc project delete testproj
+
+  lxc network delete "${brName}"
+}
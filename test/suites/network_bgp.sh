@@ -0,0 +1,49 @@
+test_network_bgp() {
+  ensure_has_localhost_remote "${LXD_ADDR}"
+
+  netName=lxdt$$
+
+  lxc network create "${netName}" \
+        ipv4.address=192.0.2.1/24 \
+        ipv6.address=none
+
+  # Check the peer policy keys are validated.
+  ! lxc network set "${netName}" bgp.peers.p1.import.prefixes=foo || false
+  ! lxc network set "${netName}" bgp.peers.p1.import.prefixes=10.0.0.0/16..8 || false
+  ! lxc network set "${netName}" bgp.peers.p1.export.prefixes=10.0.0.0/8..33 || false
+  ! lxc network set "${netName}" bgp.peers.p1.export.communities=65000 || false
+  ! lxc network set "${netName}" bgp.peers.p1.import.communities=65000:70000 || false
+  ! lxc network set "${netName}" bgp.peers.p1.export.med=-1 || false
+  ! lxc network set "${netName}" bgp.peers.p1.install_routes=maybe || false
+
+  # Check a peer with policies can be added.
+  lxc network set "${netName}" \
+        bgp.peers.p1.address=198.51.100.254 \
+        bgp.peers.p1.asn=65000 \
+        bgp.peers.p1.import.prefixes=0.0.0.0/0,203.0.113.0/24..32 \
+        bgp.peers.p1.import.communities=65000:100 \
+        bgp.peers.p1.import.local_pref=200 \
+        bgp.peers.p1.export.prefixes=192.0.2.0/24 \
+        bgp.peers.p1.export.communities=65001:1,65001:2 \
+        bgp.peers.p1.export.med=50 \
+        bgp.peers.p1.install_routes=true \
+        bgp.peers.p1.graceful_restart=false
+  lxc query /internal/testing/bgp | jq -e '.peers[] | select(.address == "198.51.100.254")'
+
+  # Check the BGP state endpoint reports the peer.
+  lxc query "/1.0/networks/${netName}/bgp" | jq -e '.peers[0].name == "p1"'
+  lxc query "/1.0/networks/${netName}/bgp" | jq -e '.peers[0].address == "198.51.100.254"'
+  lxc query "/1.0/networks/${netName}/bgp" | jq -e '.peers[0].asn == 65000'
+  lxc query "/1.0/networks/${netName}/bgp" | jq -e '.peers[0].installed_prefixes == []'
+
+  # Check changing the policy keeps the peer.
+  lxc network set "${netName}" bgp.peers.p1.export.med=100
+  lxc query /internal/testing/bgp | jq -e '.peers[] | select(.address == "198.51.100.254")'
+
+  # Check removing the peer removes it from the BGP server.
+  lxc network unset "${netName}" bgp.peers.p1.address
+  ! lxc query /internal/testing/bgp | jq -e '.peers[] | select(.address == "198.51.100.254")' || false
+  lxc query "/1.0/networks/${netName}/bgp" | jq -e '.peers == []'
+
+  lxc network delete "${netName}"
+}
@@ -0,0 +1,69 @@
+test_network_dhcp_native() {
+  ensure_import_testimage
+
+  netName=lxdtd$$
+
+  # Check the config is validated.
+  ! lxc network create "${netName}" dhcp.backend=invalid || false
+  ! lxc network create "${netName}" dhcp.backend=native raw.dnsmasq="no-resolv" || false
+  ! lxc network create "${netName}" dhcp.backend=native ipv4.address=192.0.2.1/24 ipv4.dhcp.expiry=30s || false
+
+  lxc network create "${netName}" dhcp.backend=native ipv4.address=192.0.2.1/24 ipv6.address=fd42:4242:4242:1020::1/64 ipv6.dhcp.stateful=true dns.domain=test
+
+  # Check dnsmasq isn't used and the native server listens instead.
+  ! pgrep -f "dnsmasq.*--interface=${netName}" || false
+  [ ! -e "${LXD_DIR}/networks/${netName}/dnsmasq.pid" ]
+  ss -ulnp | grep -F "192.0.2.1:53"
+
+  # Check the DHCP options are only accepted with the native server.
+  lxc network create "${netName}-dnsmasq" ipv4.address=192.0.3.1/24 ipv6.address=none
+  lxc init testimage c1 -n "${netName}-dnsmasq"
+  ! lxc config device set c1 eth0 ipv4.dhcp.options="42=192.0.2.1" || false
+  lxc delete c1
+  lxc network delete "${netName}-dnsmasq"
+
+  # Check static reservations and DHCP options.
+  lxc init testimage c1 -n "${netName}"
+  ! lxc config device set c1 eth0 ipv4.dhcp.options="53=1" || false
+  ! lxc config device set c1 eth0 ipv4.dhcp.options="42=1.2.3.4,42=5.6.7.8" || false
+  lxc config device set c1 eth0 ipv4.address=192.0.2.10 ipv4.dhcp.options="42=192.0.2.1"
+  lxc start c1
+
+  lxc exec c1 -- udhcpc -f -i eth0 -n -q -t5
+  lxc network list-leases "${netName}" | grep STATIC | grep -F "192.0.2.10"
+  lxd sql global "SELECT address FROM networks_leases" | grep -F "192.0.2.10"
+
+  # Check the leases are recorded in the database and sent as lifecycle events.
+  lxc init testimage c2 -n "${netName}"
+  lxc start c2
+  stdbuf -oL lxc monitor --type=lifecycle > "${TEST_DIR}/dhcp_native.log" &
+  monitorPID=$!
+  sleep 1
+
+  lxc exec c2 -- udhcpc -f -i eth0 -n -q -t5
+  lxc network list-leases "${netName}" | grep DYNAMIC | grep -F "c2"
+  lxd sql global "SELECT hostname FROM networks_leases" | grep -F "c2"
+  grep -F "network-lease-created" "${TEST_DIR}/dhcp_native.log"
+
+  # Check the names of the instances are resolved.
+  lxc exec c1 -- nslookup c2.test 192.0.2.1
+
+  # Check the leases survive a restart of the network.
+  lxc network set "${netName}" ipv4.dhcp.expiry=2h
+  lxc network list-leases "${netName}" | grep DYNAMIC | grep -F "c2"
+
+  # Check the leases are removed along with the instance.
+  lxc delete -f c2
+  ! lxc network list-leases "${netName}" | grep -F "c2" || false
+  grep -F "network-lease-deleted" "${TEST_DIR}/dhcp_native.log"
+
+  kill -9 "${monitorPID}" || true
+
+  # Check switching back to dnsmasq removes the leases.
+  lxc network unset "${netName}" dhcp.backend
+  ! lxd sql global "SELECT address FROM networks_leases" | grep -F "192.0.2.10" || false
+
+  lxc delete -f c1
+
+  lxc network delete "${netName}"
+}
@@ -0,0 +1,50 @@
+test_network_firewall_drift() {
+  firewallDriver=$(lxc info | awk -F ":" '/firewall:/{gsub(/ /, "", $0); print $2}')
+
+  if [ "$firewallDriver" != "xtables" ] && [ "$firewallDriver" != "nftables" ]; then
+    echo "Unrecognised firewall driver: ${firewallDriver}"
+    false
+  fi
+
+  brName="lxdt$$"
+  lxc network create "${brName}" ipv4.address=192.0.2.1/24 ipv4.nat=true ipv6.address=none
+
+  # Check that no rules are reported missing after the network has been set up.
+  lxd firewall check | grep "No missing firewall rules"
+
+  # Remove the outbound NAT rules of the network behind LXD's back.
+  if [ "$firewallDriver" = "xtables" ]; then
+    iptables -w -t nat -S POSTROUTING | grep "generated for LXD network ${brName}\"" | sed 's/^-A/-D/' | while read -r rule; do
+      eval "iptables -w -t nat ${rule}"
+    done
+
+    ! iptables -w -t nat -S POSTROUTING | grep "generated for LXD network ${brName}\"" || false
+  else
+    nft flush chain inet lxd "pstrt.${brName}"
+  fi
+
+  # Check the drift is detected.
+  ! lxd firewall check || false
+  lxd firewall check 2>&1 | grep "Missing firewall rules of network \"${brName}\""
+  lxd firewall check 2>&1 | grep -i "masquerade"
+
+  # Check the missing rules are reapplied and a warning is raised.
+  lxd firewall check --reapply | grep "Missing firewall rules have been reapplied"
+  lxd firewall check | grep "No missing firewall rules"
+  lxc warning list --format csv | grep "Firewall rules drift detected"
+
+  # Check the warning is resolved once no drift is found anymore.
+  lxd firewall check --reapply | grep "No missing firewall rules"
+  ! lxc warning list --format csv | grep "Firewall rules drift detected" || false
+  lxc warning list --all --format csv | grep "Firewall rules drift detected" | grep -i "resolved"
+
+  if [ "$firewallDriver" = "xtables" ]; then
+    iptables -w -t nat -S POSTROUTING | grep "generated for LXD network ${brName}\"" | grep "MASQUERADE"
+  else
+    nft -nn list chain inet lxd "pstrt.${brName}" | grep "masquerade"
+  fi
+
+  # Check the rules aren't tracked anymore once the network is gone.
+  lxc network delete "${brName}"
+  ! lxd firewall check 2>&1 | grep "${brName}" || false
+}
@@ -0,0 +1,84 @@
+test_network_ipam() {
+  ensure_import_testimage
+  ensure_has_localhost_remote "${LXD_ADDR}"
+
+  brName="lxdt$$"
+  projectName="ipam$$"
+  ctName="nt$$"
+
+  lxc network create "${brName}" ipv4.address=192.0.2.1/24 ipv4.dhcp.ranges=192.0.2.200-192.0.2.250 ipv6.address=none
+
+  # Check that overlapping subnets are detected.
+  ! lxc network create "${brName}B" ipv4.address=192.0.2.129/25 ipv6.address=none || false
+  ! lxc network create "${brName}B" ipv4.address=192.0.0.1/16 ipv6.address=none || false
+  lxc network create "${brName}B" ipv4.address=198.51.100.1/24 ipv6.address=none
+  ! lxc network set "${brName}B" ipv4.address=192.0.2.129/25 || false
+  lxc network delete "${brName}B"
+
+  # Check range validation.
+  lxc project create "${projectName}" -c features.images=false -c features.profiles=false
+  ! lxc network ip-range create "${brName}" bad 198.51.100.10 198.51.100.20 || false # Outside of the subnet.
+  ! lxc network ip-range create "${brName}" bad 192.0.2.20 192.0.2.10 || false # Start after end.
+  ! lxc network ip-range create "${brName}" bad 192.0.2.190 192.0.2.210 || false # Overlaps with the DHCP ranges.
+  ! lxc network ip-range create "${brName}" bad 192.0.2.10 192.0.2.20 --delegate unknown || false # Unknown project.
+  lxc network ip-range create "${brName}" routers 192.0.2.2 192.0.2.9 --description "Routers"
+  lxc network ip-range create "${brName}" web 192.0.2.100 192.0.2.101 --delegate "${projectName}"
+  ! lxc network ip-range create "${brName}" web 192.0.2.110 192.0.2.120 || false # Duplicate name.
+  ! lxc network ip-range create "${brName}" other 192.0.2.5 192.0.2.15 || false # Overlaps with another range.
+  lxc network ip-range list "${brName}" | grep "routers"
+  lxc network ip-range show "${brName}" web | grep "project: ${projectName}"
+  lxc network ip-range set "${brName}" routers description="Edge routers"
+  lxc network ip-range show "${brName}" routers | grep "description: Edge routers"
+
+  # Check the subnet can't be changed if the ranges wouldn't be within it anymore.
+  ! lxc network set "${brName}" ipv4.address=192.0.2.1/25 || false
+
+  # Check addresses of reserved ranges can't be used.
+  lxc init testimage "${ctName}" --project "${projectName}" -s "lxdtest-$(basename "${LXD_DIR}")"
+  ! lxc config device add "${ctName}" eth0 nic network="${brName}" ipv4.address=192.0.2.5 --project "${projectName}" || false
+
+  # Check static addresses must be within the ranges delegated to the project.
+  ! lxc config device add "${ctName}" eth0 nic network="${brName}" ipv4.address=192.0.2.50 --project "${projectName}" || false
+
+  # Check NICs get an address from the ranges delegated to the project.
+  lxc config device add "${ctName}" eth0 nic network="${brName}" --project "${projectName}"
+  [ "$(lxc config get "${ctName}" volatile.eth0.ipv4.address --project "${projectName}")" = "192.0.2.100" ]
+  lxc network ip-allocation list "${brName}" --project "${projectName}" | grep "${ctName}"
+  [ "$(lxc query "/1.0/networks/${brName}/ip-allocations/192.0.2.100?project=${projectName}" | jq -r .device)" = "eth0" ]
+
+  # Check the address is kept across restarts and recorded in the DHCP configuration.
+  lxc start "${ctName}" --project "${projectName}"
+  [ "$(lxc config get "${ctName}" volatile.eth0.ipv4.address --project "${projectName}")" = "192.0.2.100" ]
+  grep -F "192.0.2.100" "${LXD_DIR}/networks/${brName}/dnsmasq.hosts/${projectName}_${ctName}.eth0"
+
+  # Check addresses can be allocated to the project and that ranges get exhausted.
+  lxc network ip-allocation create "${brName}" --description "VIP" --project "${projectName}" | grep "192.0.2.101"
+  lxc network ip-allocation show "${brName}" 192.0.2.101 --project "${projectName}" | grep "description: VIP"
+  ! lxc network ip-allocation create "${brName}" --project "${projectName}" || false
+  ! lxc network ip-allocation create "${brName}" 192.0.2.101 --project "${projectName}" || false
+  ! lxc network ip-allocation delete "${brName}" 192.0.2.100 --project "${projectName}" || false # Used by a NIC.
+  lxc network ip-allocation delete "${brName}" 192.0.2.101 --project "${projectName}"
+  ! lxc network ip-allocation show "${brName}" 192.0.2.101 --project "${projectName}" || false
+
+  # Check that projects can't see each other's allocations.
+  ! lxc network ip-allocation list "${brName}" | grep "192.0.2.100" || false
+
+  # Check a static address replaces the picked one.
+  lxc network ip-range set "${brName}" web end=192.0.2.110
+  lxc config device set "${ctName}" eth0 ipv4.address=192.0.2.105 --project "${projectName}"
+  [ "$(lxc config get "${ctName}" volatile.eth0.ipv4.address --project "${projectName}")" = "" ]
+  ! lxc network ip-allocation list "${brName}" --project "${projectName}" | grep "192.0.2.100" || false
+  lxc network ip-allocation list "${brName}" --project "${projectName}" | grep "192.0.2.105"
+
+  # Check the addresses are released with the NIC.
+  lxc stop -f "${ctName}" --project "${projectName}"
+  lxc config device remove "${ctName}" eth0 --project "${projectName}"
+  ! lxc network ip-allocation list "${brName}" --project "${projectName}" | grep "192.0.2.105" || false
+
+  lxc delete -f "${ctName}" --project "${projectName}"
+  lxc network ip-range delete "${brName}" web
+  lxc network ip-range delete "${brName}" routers
+  [ "$(lxc network ip-range list "${brName}" -f csv | wc -l)" = "0" ]
+  lxc project delete "${projectName}"
+  lxc network delete "${brName}"
+}
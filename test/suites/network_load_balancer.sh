@@ -0,0 +1,119 @@
+test_network_load_balancer() {
+  ensure_has_localhost_remote "${LXD_ADDR}"
+
+  firewallDriver=$(lxc info | awk -F ":" '/firewall:/{gsub(/ /, "", $0); print $2}')
+  netName=lxdt$$
+
+  lxc network create "${netName}" \
+        ipv4.address=192.0.2.1/24 \
+        ipv6.address=fd42:4242:4242:1010::1/64
+
+  # Check automatic listen address allocation isn't supported.
+  ! lxc network load-balancer create "${netName}" --allocate=ipv4 || false
+
+  # Check an empty load balancer doesn't create any firewall rules.
+  lxc network load-balancer create "${netName}" 198.51.100.1
+  if [ "$firewallDriver" = "xtables" ]; then
+    ! iptables -w -t nat -S | grep -c "generated for LXD network-load-balancer ${netName}" || false
+  else
+    ! nft -nn list chain inet lxd "lbprert.${netName}" || false
+  fi
+
+  # Check the load balancer is exported via BGP prefixes.
+  lxc query /internal/testing/bgp | grep "198.51.100.1/32"
+
+  # Check the bridge specific config keys are validated.
+  ! lxc network load-balancer set "${netName}" 198.51.100.1 selection=random || false
+  ! lxc network load-balancer set "${netName}" 198.51.100.1 healthcheck.interval=0 || false
+  ! lxc network load-balancer set "${netName}" 198.51.100.1 foo=bar || false
+
+  # Check backends outside of the network subnet are refused.
+  ! lxc network load-balancer backend add "${netName}" 198.51.100.1 out 198.51.100.2 || false
+
+  # Check round-robin rules are created for the backends.
+  lxc network load-balancer backend add "${netName}" 198.51.100.1 b1 192.0.2.2
+  lxc network load-balancer backend add "${netName}" 198.51.100.1 b2 192.0.2.3 8080
+  lxc network load-balancer port add "${netName}" 198.51.100.1 tcp 80 b1,b2
+  if [ "$firewallDriver" = "xtables" ]; then
+    iptables -w -t nat -S | grep -- "-A PREROUTING -d 198.51.100.1/32 -p tcp -m tcp --dport 80 .*generated for LXD network-load-balancer ${netName}.* -j DNAT --to-destination 192.0.2.2:80"
+    iptables -w -t nat -S | grep -- "-A PREROUTING -d 198.51.100.1/32 -p tcp -m tcp --dport 80 .*generated for LXD network-load-balancer ${netName}.* -j DNAT --to-destination 192.0.2.3:8080"
+  else
+    nft -nn list chain inet lxd "lbprert.${netName}" | grep "ip daddr 198.51.100.1 tcp dport 80 dnat ip to numgen inc mod 2 map"
+    nft -nn list chain inet lxd "lbout.${netName}" | grep "192.0.2.3 . 8080"
+  fi
+
+  # Check hash based selection.
+  lxc network load-balancer set "${netName}" 198.51.100.1 selection=hash
+  if [ "$firewallDriver" = "xtables" ]; then
+    iptables -w -t nat -S | grep -- "-m cluster .*--cluster-total-nodes 2 .*-j DNAT --to-destination 192.0.2.3:8080"
+    ! iptables -w -t mangle -S | grep -F "generated for LXD network-load-balancer ${netName}" || false
+  else
+    nft -nn list chain inet lxd "lbprert.${netName}" | grep "jhash ip saddr mod 2"
+  fi
+
+  # Check health checks can be enabled. Nothing listens on the backends so they all fail their health check,
+  # in which case the traffic is still sent to all of them.
+  lxc network load-balancer set "${netName}" 198.51.100.1 healthcheck=true healthcheck.interval=1 healthcheck.timeout=1 healthcheck.failure_count=1
+  sleep 3
+  if [ "$firewallDriver" = "nftables" ]; then
+    nft -nn list chain inet lxd "lbprert.${netName}" | grep "jhash ip saddr mod 2"
+  fi
+
+  lxc network load-balancer delete "${netName}" 198.51.100.1
+
+  # Check deleting the load balancer removes its firewall rules and BGP prefix.
+  if [ "$firewallDriver" = "xtables" ]; then
+    ! iptables -w -t nat -S | grep -c "generated for LXD network-load-balancer ${netName}" || false
+  else
+    ! nft -nn list chain inet lxd "lbprert.${netName}" || false
+  fi
+
+  ! lxc query /internal/testing/bgp | grep "198.51.100.1/32" || false
+
+  # Check peering with another bridge network.
+  lxc network create "${netName}p" \
+        ipv4.address=198.18.0.1/24 \
+        ipv4.nat=true \
+        ipv6.address=none
+
+  lxc network set "${netName}" ipv4.nat=true
+  lxc network peer create "${netName}" peer1 "${netName}p"
+  lxc network peer show "${netName}" peer1 | grep "status: Pending"
+  if [ "$firewallDriver" = "nftables" ]; then
+    ! nft -nn list chain inet lxd "peernat.${netName}" | grep -F "198.18.0.0/24" || false
+  fi
+
+  lxc network peer create "${netName}p" peer1 "${netName}"
+  lxc network peer show "${netName}" peer1 | grep "status: Created"
+  if [ "$firewallDriver" = "xtables" ]; then
+    iptables -w -t nat -S | grep -- "-A POSTROUTING -s 192.0.2.0/24 -d 198.18.0.0/24 -o ${netName}p .*generated for LXD network-peer ${netName}.* -j ACCEPT"
+  else
+    nft -nn list chain inet lxd "peernat.${netName}" | grep "oifname \"${netName}p\" ip saddr 192.0.2.0/24 ip daddr 198.18.0.0/24 accept"
+    nft -nn list chain inet lxd "peernat.${netName}p" | grep "oifname \"${netName}\" ip saddr 198.18.0.0/24 ip daddr 192.0.2.0/24 accept"
+  fi
+
+  # Check the peer can be used in ACL rules and can't be deleted while in use.
+  lxc network acl create "${netName}acl"
+  lxc network acl rule add "${netName}acl" ingress action=allow source="@${netName}/peer1"
+  lxc network set "${netName}" security.acls="${netName}acl"
+  if [ "$firewallDriver" = "nftables" ]; then
+    nft -nn list chain inet lxd "acl.${netName}" | grep "198.18.0.0/24"
+  fi
+
+  ! lxc network peer delete "${netName}" peer1 || false
+  lxc network unset "${netName}" security.acls
+  lxc network acl delete "${netName}acl"
+
+  # Check deleting one side of the peering removes the rules on both sides.
+  lxc network peer delete "${netName}" peer1
+  if [ "$firewallDriver" = "xtables" ]; then
+    ! iptables -w -t nat -S | grep -c "generated for LXD network-peer ${netName}" || false
+  else
+    ! nft -nn list chain inet lxd "peernat.${netName}" | grep -F "accept" || false
+    ! nft -nn list chain inet lxd "peernat.${netName}p" | grep -F "accept" || false
+  fi
+
+  lxc network peer delete "${netName}p" peer1
+  lxc network delete "${netName}p"
+  lxc network delete "${netName}"
+}
@@ -0,0 +1,50 @@
+test_network_wireguard() {
+  if ! command -v wg >/dev/null 2>&1 || ! modprobe -n wireguard >/dev/null 2>&1; then
+    echo "==> SKIP: WireGuard networks need the wireguard kernel module and the wg command"
+    return
+  fi
+
+  ensure_import_testimage
+
+  firewallDriver=$(lxc info | awk -F ":" '/firewall:/{gsub(/ /, "", $0); print $2}')
+  netName=lxdtwg$$
+
+  # Check the config is validated.
+  ! lxc network create "${netName}" --type=wireguard || false
+  ! lxc network create "${netName}" --type=wireguard wireguard.range=10.100.0.0/16 ipv4.address=10.100.0.1/24 || false
+  ! lxc network create "${netName}" --type=wireguard wireguard.range=10.100.0.0/16 ipv6.address=fd42:4242:4242:1010::1/64 || false
+  ! lxc network create "${netName}" --type=wireguard wireguard.range=10.100.0.0/16 wireguard.member_prefix=16 || false
+  ! lxc network create lxdtwireguard1 --type=wireguard wireguard.range=10.100.0.0/16 || false
+
+  lxc network create "${netName}" --type=wireguard wireguard.range=10.100.0.0/16 wireguard.member_prefix=26
+
+  # Check a subnet and a key were allocated to the member.
+  [ "$(lxc network get "${netName}" volatile.wireguard.subnet)" = "10.100.0.0/26" ]
+  [ -n "$(lxc network get "${netName}" volatile.wireguard.public_key)" ]
+  [ "$(lxc network get "${netName}" bridge.mtu)" = "1420" ]
+  ip -4 addr show dev "${netName}" | grep -F "10.100.0.1/26"
+  wg show "${netName}-wg" listen-port | grep -x "51820"
+
+  # Check traffic within the range is exempted from NAT.
+  if [ "$firewallDriver" = "nftables" ]; then
+    nft -nn list chain inet lxd "peernat.${netName}" | grep "oifname \"${netName}-wg\" ip saddr 10.100.0.0/26 ip daddr 10.100.0.0/16 accept"
+  fi
+
+  # Check instances get an address from the member subnet.
+  lxc init testimage c1 -n "${netName}"
+  lxc config device set c1 eth0 ipv4.address=10.100.0.10
+  ! lxc config device set c1 eth0 ipv4.address=10.100.0.100 || false
+  lxc delete c1
+
+  # Check the tunnel survives config changes.
+  lxc network set "${netName}" wireguard.port=51821
+  wg show "${netName}-wg" listen-port | grep -x "51821"
+
+  # Check a new subnet is allocated when the range changes.
+  lxc network set "${netName}" wireguard.range=10.101.0.0/16
+  [ "$(lxc network get "${netName}" volatile.wireguard.subnet)" = "10.101.0.0/26" ]
+  ip -4 addr show dev "${netName}" | grep -F "10.101.0.1/26"
+
+  lxc network delete "${netName}"
+  ! ip link show "${netName}-wg" || false
+}
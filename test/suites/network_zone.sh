@@ -113,6 +113,33 @@ test_network_zone() {
   dig "@${DNS_ADDR}" -p "${DNS_PORT}" axfr 0.1.0.1.2.4.2.4.2.4.2.4.2.4.d.f.ip6.arpa | grep "300\s\+IN\s\+PTR\s\+c1.lxd.example.net."
   dig "@${DNS_ADDR}" -p "${DNS_PORT}" axfr 0.1.0.1.2.4.2.4.2.4.2.4.2.4.d.f.ip6.arpa | grep "300\s\+IN\s\+PTR\s\+c2.lxdfoo.example.net."
 
+  # Check ordinary queries are answered
+  [ -n "$(dig "@${DNS_ADDR}" -p "${DNS_PORT}" +short c1.lxd.example.net A)" ]
+  [ -n "$(dig "@${DNS_ADDR}" -p "${DNS_PORT}" +short c1.lxd.example.net AAAA)" ]
+  dig "@${DNS_ADDR}" -p "${DNS_PORT}" -x "$(lxc list -c4 --format=csv c1 | cut -d' ' -f1)" | grep -F "c1.lxd.example.net."
+  dig "@${DNS_ADDR}" -p "${DNS_PORT}" missing.lxd.example.net A | grep -F "status: NXDOMAIN"
+  dig "@${DNS_ADDR}" -p "${DNS_PORT}" c1.lxd.example.net TXT | grep -F "status: NOERROR"
+  dig "@${DNS_ADDR}" -p "${DNS_PORT}" example.com A | grep -F "status: REFUSED"
+
+  # Check the serial only changes with the zone content and IXFR only transfers the changes
+  serial="$(dig "@${DNS_ADDR}" -p "${DNS_PORT}" +short lxd.example.net SOA | cut -d' ' -f3)"
+  [ "$(dig "@${DNS_ADDR}" -p "${DNS_PORT}" +short lxd.example.net SOA | cut -d' ' -f3)" = "${serial}" ]
+  lxc network zone record create lxd.example.net ixfr
+  lxc network zone record entry add lxd.example.net ixfr TXT '"foo"'
+  [ "$(dig "@${DNS_ADDR}" -p "${DNS_PORT}" +short lxd.example.net SOA | cut -d' ' -f3)" -gt "${serial}" ]
+  dig "@${DNS_ADDR}" -p "${DNS_PORT}" "ixfr=${serial}" lxd.example.net | grep -F 'ixfr.lxd.example.net.'
+  ! dig "@${DNS_ADDR}" -p "${DNS_PORT}" "ixfr=${serial}" lxd.example.net | grep -F 'c1.lxd.example.net.' || false
+  [ "$(dig "@${DNS_ADDR}" -p "${DNS_PORT}" +short ixfr.lxd.example.net TXT)" = '"foo"' ]
+  lxc network zone record delete lxd.example.net ixfr
+
+  # Check clients which aren't peers need to be allowed
+  lxc network zone unset lxd.example.net peers.test.address
+  dig "@${DNS_ADDR}" -p "${DNS_PORT}" c1.lxd.example.net A | grep -F "status: REFUSED"
+  lxc network zone set lxd.example.net dns.query.allowed_subnets=192.0.2.0/24
+  [ -n "$(dig "@${DNS_ADDR}" -p "${DNS_PORT}" +short c1.lxd.example.net A)" ]
+  lxc network zone unset lxd.example.net dns.query.allowed_subnets
+  lxc network zone set lxd.example.net peers.test.address=192.0.2.1
+
   # Test extra records
   lxc network zone record create lxd.example.net demo user.foo=bar
   ! lxc network zone record create lxd.example.net demo user.foo=bar || false
@@ -598,6 +598,24 @@ test_projects_limits() {
   lxc storage delete limit1
   lxc storage delete limit2
 
+  # Test custom volume I/O limits.
+  ! lxc storage volume create "${pool}" foo limits.iops=xxx || false
+  ! lxc storage volume create "${pool}" foo limits.bandwidth=xxx || false
+  lxc storage volume create "${pool}" foo limits.iops=100 limits.bandwidth=10MiB
+  lxc project set p1 limits.disk.iops=150
+  lxc project set p1 limits.disk.bandwidth=15MiB
+  ! lxc project set p1 limits.disk.iops=50 || false
+  ! lxc storage volume set "${pool}" foo limits.iops=200 || false
+  ! lxc storage volume create "${pool}" bar || false
+  ! lxc storage volume create "${pool}" bar limits.iops=50 limits.bandwidth=10MiB || false
+  lxc storage volume create "${pool}" bar limits.iops=50 limits.bandwidth=5MiB
+  lxc query /1.0/projects/p1/state | jq -e '.resources["disk-iops"] == {"limit": 150, "usage": 150}'
+
+  lxc storage volume delete "${pool}" foo
+  lxc storage volume delete "${pool}" bar
+  lxc project unset p1 limits.disk.iops
+  lxc project unset p1 limits.disk.bandwidth
+
   # Create a couple of containers in the project.
   lxc init testimage c1
   lxc init testimage c2
@@ -445,3 +445,43 @@ test_snap_fail() {
     lxc delete --force c1
   fi
 }
+
+test_snap_consistency() {
+  ensure_import_testimage
+
+  lxc launch testimage c1
+
+  # Crash-consistent snapshots don't record their consistency.
+  lxc snapshot c1
+  ! lxc config show c1/snap0 | grep -F volatile.snapshot.consistency || false
+
+  # The pre-snapshot hook runs before the snapshot is taken and the post-snapshot hook after it.
+  lxc config set c1 snapshots.consistency=fsfreeze snapshots.consistency.pre_hook="touch /root/pre_hook" snapshots.consistency.post_hook="touch /root/post_hook"
+  lxc snapshot c1
+  lxc config show c1/snap1 | grep -xF "  volatile.snapshot.consistency: hooks"
+  lxc exec c1 -- test -e /root/post_hook
+
+  lxc copy c1/snap1 c2
+  ! lxc config show c2 | grep -F volatile.snapshot.consistency || false
+  lxc file pull c2/root/pre_hook -
+  ! lxc file pull c2/root/post_hook - || false
+  lxc delete c2
+
+  # Failing and timed out pre-snapshot hooks result in crash-consistent snapshots.
+  lxc config set c1 snapshots.consistency.pre_hook=false
+  lxc snapshot c1
+  lxc config show c1/snap2 | grep -xF "  volatile.snapshot.consistency: crash"
+
+  lxc config set c1 snapshots.consistency.pre_hook="sleep 60" snapshots.consistency.timeout=1
+  lxc snapshot c1
+  lxc config show c1/snap3 | grep -xF "  volatile.snapshot.consistency: crash"
+
+  # Invalid hooks are rejected.
+  ! lxc config set c1 snapshots.consistency.pre_hook="'unterminated" || false
+
+  # Restoring a snapshot doesn't restore its consistency.
+  lxc restore c1 snap1
+  ! lxc config show c1 | grep -F volatile.snapshot.consistency || false
+
+  lxc delete -f c1
+}
@@ -0,0 +1,67 @@
+test_storage_driver_nfs() {
+  local lxd_backend source
+
+  lxd_backend=$(storage_backend "$LXD_DIR")
+  if [ "$lxd_backend" != "dir" ]; then
+    return
+  fi
+
+  # Use the NFS export if provided, otherwise a directory stands in for an already mounted shared file system.
+  if [ -n "${LXD_NFS_EXPORT:-}" ]; then
+    source="${LXD_NFS_EXPORT}"
+  else
+    source=$(mktemp -d -p "${TEST_DIR}" XXX)
+  fi
+
+  # Simple create/delete attempt
+  lxc storage create nfs nfs source="${source}"
+  lxc storage delete nfs
+
+  # Creating a pool from a non-empty source should fail.
+  if [ -z "${LXD_NFS_EXPORT:-}" ]; then
+    touch "${source}/foo"
+    ! lxc storage create nfs nfs source="${source}" || false
+    rm "${source}/foo"
+  fi
+
+  lxc storage create nfs nfs source="${source}"
+  [ "$(lxc query /1.0/storage-pools/nfs | jq -r .driver)" = "nfs" ]
+
+  # Creation, rename and deletion
+  lxc storage volume create nfs vol1
+  lxc storage volume rename nfs vol1 vol2
+  lxc storage volume copy nfs/vol2 nfs/vol1
+  lxc storage volume delete nfs vol1
+  lxc storage volume delete nfs vol2
+
+  # Block volumes aren't supported
+  lxc storage volume create nfs vol1 --type=block 2>&1 | grep -F 'Volumes of content type "block" are not supported by the nfs driver'
+
+  # Snapshots
+  lxc storage volume create nfs vol1
+  lxc storage volume snapshot nfs vol1
+  lxc storage volume snapshot nfs vol1 blah1
+  lxc storage volume rename nfs vol1/blah1 vol1/blah2
+  lxc storage volume delete nfs vol1/snap0
+  lxc storage volume restore nfs vol1 blah2
+  lxc storage volume delete nfs vol1/blah2
+  lxc storage volume delete nfs vol1
+
+  # Containers
+  ensure_import_testimage
+  lxc launch testimage c1 -s nfs
+  lxc snapshot c1
+  lxc exec c1 -- touch /foo
+  lxc stop -f c1
+  lxc restore c1 snap0
+  lxc start c1
+  ! lxc exec c1 -- stat /foo || false
+  lxc delete -f c1
+
+  # Cleanup
+  lxc storage delete nfs
+
+  if [ -z "${LXD_NFS_EXPORT:-}" ]; then
+    rmdir "${source}"
+  fi
+}
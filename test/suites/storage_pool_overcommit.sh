@@ -0,0 +1,43 @@
+test_storage_pool_overcommit() {
+  local lxd_backend
+  lxd_backend=$(storage_backend "$LXD_DIR")
+  if [ "${lxd_backend}" != "zfs" ] && [ "${lxd_backend}" != "lvm" ]; then
+    echo "==> SKIP: storage pool overcommit tests need a thin provisioned storage pool"
+    return
+  fi
+
+  pool="lxdtest-$(basename "${LXD_DIR}")-overcommit"
+  lxc storage create "${pool}" "${lxd_backend}" size=1GiB
+
+  # Check the allocated space is reported.
+  lxc storage volume create "${pool}" vol1 size=512MiB
+  lxc storage volume create "${pool}" vol2 size=512MiB
+  [ "$(lxc query "/1.0/storage-pools/${pool}/resources" | jq -r '.space.allocated')" = "1073741824" ]
+  lxc storage info "${pool}" | grep -xF "  space allocated: 1.00GiB"
+
+  # Check the config keys are validated.
+  ! lxc storage set "${pool}" volume.overcommit_ratio=0 || false
+  ! lxc storage set "${pool}" volume.overcommit_ratio=foo || false
+  ! lxc storage set "${pool}" space.warning_threshold=200% || false
+  ! lxc storage set "${pool}" space.warning_threshold=foo || false
+  lxc storage set "${pool}" space.warning_threshold=10%
+  lxc storage set "${pool}" space.warning_threshold=100MiB
+
+  # Check volumes can't be created or grown beyond the overcommit limit.
+  lxc storage set "${pool}" volume.overcommit_ratio=2
+  [ "$(lxc storage volume get "${pool}" vol1 overcommit_ratio)" = "" ]
+  lxc storage volume create "${pool}" vol3 size=512MiB
+  ! lxc storage volume create "${pool}" vol4 size=2GiB || false
+  ! lxc storage volume set "${pool}" vol1 size=2GiB || false
+  [ "$(lxc storage volume get "${pool}" vol1 size)" = "512MiB" ]
+
+  # Check the limit no longer applies once unset.
+  lxc storage unset "${pool}" volume.overcommit_ratio
+  lxc storage volume create "${pool}" vol4 size=2GiB
+
+  lxc storage volume delete "${pool}" vol1
+  lxc storage volume delete "${pool}" vol2
+  lxc storage volume delete "${pool}" vol3
+  lxc storage volume delete "${pool}" vol4
+  lxc storage delete "${pool}"
+}
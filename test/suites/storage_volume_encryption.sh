@@ -0,0 +1,78 @@
+test_storage_volume_encryption() {
+  lxd_backend=$(storage_backend "$LXD_DIR")
+  if [ "${lxd_backend}" != "zfs" ] && [ "${lxd_backend}" != "lvm" ] && [ "${lxd_backend}" != "ceph" ]; then
+    return
+  fi
+
+  if ! command -v cryptsetup >/dev/null; then
+    echo "==> SKIP: cryptsetup is required for volume encryption"
+    return
+  fi
+
+  ensure_import_testimage
+
+  pool="lxdtest-$(basename "${LXD_DIR}")-encrypted"
+  if [ "${lxd_backend}" = "ceph" ]; then
+    lxc storage create "${pool}" ceph volume.size=25MiB ceph.osd.pg_num=16
+  else
+    lxc storage create "${pool}" "${lxd_backend}" size=1GiB
+  fi
+
+  if [ "${lxd_backend}" = "zfs" ]; then
+    lxc storage set "${pool}" volume.zfs.block_mode=true
+  fi
+
+  # The pool key is generated on creation, isn't exposed through the API and cannot be set.
+  lxd sql global "SELECT value FROM storage_pools_config JOIN storage_pools ON storage_pools.id = storage_pools_config.storage_pool_id WHERE storage_pools.name = '${pool}' AND key = 'volatile.encryption.key'" | grep -qE '[0-9a-f]{64}'
+  ! lxc storage show "${pool}" | grep -F volatile.encryption.key || false
+  [ -z "$(lxc storage get "${pool}" volatile.encryption.key)" ]
+  ! lxc storage set "${pool}" volatile.encryption.key="$(printf '%064d' 0)" || false
+  lxc storage set "${pool}" volume.encryption=false
+  lxc storage unset "${pool}" volume.encryption
+
+  # Encrypted custom volumes.
+  lxc storage volume create "${pool}" vol1 encryption=true size=32MiB
+  lxc storage volume create "${pool}" vol2 --type=block encryption=true size=32MiB
+  [ "$(lxc storage volume get "${pool}" vol1 encryption)" = "true" ]
+  ! lxc storage volume set "${pool}" vol1 encryption=false || false
+
+  # Encrypted volumes are opened when in use and closed afterwards.
+  lxc launch testimage c1 -s "${pool}" --device root,initial.encryption=true
+  lxc storage volume attach "${pool}" vol1 c1 /mnt
+  [ "$(lxc storage volume get "${pool}" container/c1 encryption)" = "true" ]
+  [ "$(find /dev/mapper -name 'lxd_crypt_*' | wc -l)" = "2" ]
+  lxc exec c1 -- sh -c "echo foo > /mnt/foo"
+
+  # Snapshots and copies keep the encryption of their source.
+  lxc snapshot c1 snap0
+  lxc copy c1 c2
+  [ "$(lxc storage volume get "${pool}" container/c2 encryption)" = "true" ]
+  lxc storage volume snapshot "${pool}" vol1 snap0
+  lxc storage volume copy "${pool}/vol1" "${pool}/vol3"
+  [ "$(lxc storage volume get "${pool}" vol3 encryption)" = "true" ]
+
+  # Growing encrypted volumes.
+  lxc stop -f c1
+  lxc storage volume set "${pool}" vol1 size=48MiB
+  lxc start c1
+  [ "$(lxc exec c1 -- cat /mnt/foo)" = "foo" ]
+
+  # Backups are exported decrypted and encrypted again on import.
+  lxc storage volume export "${pool}" vol1 "${LXD_DIR}/vol1.tar.gz" --optimized-storage
+  lxc storage volume import "${pool}" "${LXD_DIR}/vol1.tar.gz" vol4
+  [ "$(lxc storage volume get "${pool}" vol4 encryption)" = "true" ]
+  rm "${LXD_DIR}/vol1.tar.gz"
+
+  lxc delete -f c1 c2
+  [ "$(find /dev/mapper -name 'lxd_crypt_*' | wc -l)" = "0" ]
+
+  # Project keys.
+  lxc storage set "${pool}" encryption.project_keys=true
+  lxc storage volume create "${pool}" vol5 encryption=true size=32MiB
+
+  for vol in vol1 vol2 vol3 vol4 vol5; do
+    lxc storage volume delete "${pool}" "${vol}"
+  done
+
+  lxc storage delete "${pool}"
+}
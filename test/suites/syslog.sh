@@ -11,5 +11,21 @@ test_syslog_socket() {
   grep -qF "type: ovn" "${TEST_DIR}/ovn.log"
   grep -qF "unix:/var/run/openvswitch/br-int.mgmt: connected" "${TEST_DIR}/ovn.log"
 
+  # Check the OVN ACL log entries are also sent as network ACL events.
+  lxc network acl create syslogacl
+  aclID="$(lxd sql global "SELECT id FROM networks_acls WHERE name='syslogacl'" | grep -oE '[0-9]+' | head -n1)"
+  lxc monitor --type=network-acl > "${TEST_DIR}/network_acl.log" &
+  monitorACLPID=$!
+
+  sleep 1
+  echo "<29> ovs|ovn-controller|00018|acl_log(ovn_pinctrl0)|INFO|name=\"lxd_acl${aclID}-ingress-0\", verdict=drop, severity=info, direction=to-lport: icmp,vlan_tci=0x0000,nw_src=192.0.2.1,nw_dst=192.0.2.2,icmp_type=8,icmp_code=0" | socat - unix-sendto:"${LXD_DIR}/syslog.socket"
+  sleep 1
+
+  kill -9 ${monitorACLPID} || true
+  grep -qF "type: network-acl" "${TEST_DIR}/network_acl.log"
+  grep -qF "acl: syslogacl" "${TEST_DIR}/network_acl.log"
+  grep -qF "action: drop" "${TEST_DIR}/network_acl.log"
+  lxc network acl delete syslogacl
+
   lxc config unset core.syslog_socket
 }